@@ -2,11 +2,15 @@ package main
 
 import (
 	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
 	"avito-backend-trainee-assignment-spring-2025/internal/services"
 	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/hasher"
 	"avito-backend-trainee-assignment-spring-2025/pkg/logger"
 	"avito-backend-trainee-assignment-spring-2025/pkg/metrics"
+	"avito-backend-trainee-assignment-spring-2025/pkg/notifier"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
 	"context"
 	"errors"
 	"fmt"
@@ -16,6 +20,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
 )
 
@@ -32,6 +37,31 @@ func main() {
 		Str("port", cfg.Server.Port).
 		Msg("Starting application")
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up tracing")
+	}
+
+	argonParams := hasher.Argon2idParams{
+		Memory:      cfg.Password.Argon2Memory,
+		Iterations:  cfg.Password.Argon2Iterations,
+		Parallelism: cfg.Password.Argon2Parallelism,
+	}
+	passwordHasher, err := hasher.New(cfg.Password.HashAlgorithm, argonParams, cfg.Password.BcryptCost)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create password hasher")
+	}
+	hasher.SetDefault(passwordHasher)
+
+	var blocklist []string
+	if cfg.Password.BlocklistFile != "" {
+		blocklist, err = models.LoadPasswordBlocklist(cfg.Password.BlocklistFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load password blocklist")
+		}
+	}
+	models.SetPasswordPolicy(models.NewPasswordPolicy(cfg.Password.MinLength, cfg.Password.MaxLength, blocklist))
+
 	db, err := postgres.New(&cfg.Postgres)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to database")
@@ -42,18 +72,73 @@ func main() {
 	pvzRepo := postgres.NewPVZRepository(db)
 	receptionRepo := postgres.NewReceptionRepository(db)
 	productRepo := postgres.NewProductRepository(db)
+	passwordResetRepo := postgres.NewPasswordResetRepository(db)
+	mfaRepo := postgres.NewMFARepository(db)
+	serviceAccountRepo := postgres.NewServiceAccountRepository(db)
+	auditRepo := postgres.NewAuditRepository(db)
+	reportRepo := postgres.NewReportRepository(db)
+	exportRepo := postgres.NewExportRepository(db)
+	exportJobRepo := postgres.NewExportJobRepository(db)
+	importRepo := postgres.NewImportRepository(db)
+	eventRepo := postgres.NewEventRepository(db)
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
 	txManager := postgres.NewTxManager(db)
 
-	userService := services.NewUserService(userRepo, cfg.JWT, txManager)
-	pvzService := services.NewPVZService(pvzRepo, txManager)
-	receptionService := services.NewReceptionService(receptionRepo, pvzRepo, txManager)
-	productService := services.NewProductService(productRepo, receptionRepo, txManager)
+	mailNotifier, err := notifier.New(cfg.Notifier)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create notifier")
+	}
+
+	auditService := services.NewAuditService(auditRepo)
+	passwordChanges := services.NewPasswordChangeCache(cfg.JWT.RevocationCacheTTL)
+	userService := services.NewUserService(userRepo, cfg.JWT, cfg.MFA, passwordChanges, auditService, txManager)
+	pvzService := services.NewPVZService(pvzRepo, cfg.Capacity, auditService, txManager)
+	receptionService := services.NewReceptionService(receptionRepo, pvzRepo, reportRepo, auditService, txManager)
+	productService := services.NewProductService(productRepo, receptionRepo, pvzRepo, cfg.Capacity, auditService, txManager)
+	passwordService := services.NewPasswordService(userRepo, passwordResetRepo, mailNotifier, cfg.PasswordReset, passwordChanges, auditService, txManager)
+	mfaService := services.NewMFAService(userRepo, mfaRepo, cfg.JWT, cfg.MFA, auditService, txManager)
+	serviceAccountService := services.NewServiceAccountService(serviceAccountRepo, auditService, txManager)
+	exportService := services.NewExportService(exportRepo, exportJobRepo, cfg.Export, txManager)
+	importService := services.NewImportService(pvzRepo, receptionRepo, productRepo, importRepo, auditService, cfg.Import, txManager)
+
+	eventListener, err := postgres.NewEventListener(&cfg.Postgres)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to listen for PVZ events")
+	}
+	defer eventListener.Close()
+
+	eventService := services.NewEventService(eventRepo, eventListener.Wakeups(), cfg.Events)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, cfg.Idempotency)
+
+	if err := exportService.Start(context.Background()); err != nil {
+		log.Fatal().Err(err).Msg("Failed to start export service")
+	}
+
+	if err := eventService.Start(context.Background()); err != nil {
+		log.Fatal().Err(err).Msg("Failed to start event service")
+	}
+
+	idempotencyService.Start()
+
+	if cfg.Admin.Email != "" {
+		if err := userService.EnsureAdmin(context.Background(), cfg.Admin.Email, cfg.Admin.Password); err != nil {
+			log.Fatal().Err(err).Msg("Failed to create admin user")
+		}
+	}
 
 	handler := handlers.NewHandler(
 		userService,
 		pvzService,
 		receptionService,
 		productService,
+		passwordService,
+		mfaService,
+		serviceAccountService,
+		auditService,
+		exportService,
+		importService,
+		eventService,
+		idempotencyService,
 		cfg,
 	)
 
@@ -67,6 +152,7 @@ func main() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	prometheus.MustRegister(metrics.NewCapacityCollector(pvzService.CapacityMetrics))
 	metricsServer := metrics.NewServer(cfg.Prometheus.Port)
 	go func() {
 		if err := metricsServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -90,15 +176,29 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Ленты событий открыты, пока клиент не отключится, поэтому закрываются до остановки
+	// сервера, иначе он ждал бы их до таймаута
+	eventService.Shutdown()
+	log.Info().Msg("Event streams closed")
+
 	if err := server.Shutdown(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to stop HTTP server")
 	}
 	log.Info().Msg("HTTP server stopped")
 
+	exportService.Shutdown()
+	log.Info().Msg("Export jobs stopped")
+
+	idempotencyService.Shutdown()
+
 	if err := metricsServer.Stop(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to stop metrics server")
 	}
 	log.Info().Msg("Metrics server stopped")
 
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	log.Info().Msg("Application shutdown complete")
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/errcode"
+	"avito-backend-trainee-assignment-spring-2025/internal/i18n"
+	"context"
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain - домен кодов ошибок в ErrorInfo.
+const errorDomain = "pvz.v1"
+
+// grpcCodes сопоставляет HTTP-статусам ошибок коды gRPC. Истекший ресурс (410) для
+// клиента gRPC уже не существует, поэтому это NotFound.
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusGone:                  codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusPreconditionFailed:    codes.FailedPrecondition,
+	http.StatusPreconditionRequired:  codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+}
+
+// grpcCodesByError уточняет код для ошибок, которым код по HTTP-статусу не подходит:
+// в REST 409 означает и «уже существует», и конфликт с состоянием ресурса.
+var grpcCodesByError = map[string]codes.Code{
+	// Ресурс занят другим запросом, вызов можно повторить позже
+	"idempotency_key_in_use":  codes.Aborted,
+	"active_reception_exists": codes.Aborted,
+
+	// Состояние ресурса не допускает операцию
+	"pvz_decommissioned":   codes.FailedPrecondition,
+	"capacity_exceeded":    codes.FailedPrecondition,
+	"export_job_not_ready": codes.FailedPrecondition,
+	"export_job_failed":    codes.FailedPrecondition,
+}
+
+// grpcError превращает ошибку в статус gRPC с тем же кодом ошибки, что и в REST API.
+// Сообщение статуса - на английском, а в деталях передаются ErrorInfo с кодом и
+// LocalizedMessage на языке вызова. Неизвестные ошибки не раскрываются клиенту.
+func grpcError(ctx context.Context, err error) error {
+	descriptor, known := errcode.Describe(err)
+	if !known {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unexpected error in GRPC handler")
+	}
+
+	code, ok := grpcCodesByError[descriptor.Code]
+	if !ok {
+		code, ok = grpcCodes[descriptor.Status]
+	}
+	if !ok {
+		code = codes.Internal
+	}
+
+	lang := i18n.FromContext(ctx)
+	st := status.New(code, i18n.Message(i18n.English, descriptor.Code))
+	detailed, detailErr := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: descriptor.Code, Domain: errorDomain},
+		&errdetails.LocalizedMessage{Locale: lang, Message: i18n.Message(lang, descriptor.Code)},
+	)
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// withLanguage выбирает язык сообщений об ошибках по метаданным accept-language.
+func withLanguage(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var acceptLanguage string
+	if values := md.Get("accept-language"); len(values) > 0 {
+		acceptLanguage = values[0]
+	}
+	return i18n.WithLanguage(ctx, i18n.Negotiate(acceptLanguage))
+}
@@ -2,38 +2,87 @@ package main
 
 import (
 	"avito-backend-trainee-assignment-spring-2025/cmd/grpc/pvz_v1"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
+	"avito-backend-trainee-assignment-spring-2025/internal/services"
 	"avito-backend-trainee-assignment-spring-2025/pkg/config"
 	"avito-backend-trainee-assignment-spring-2025/pkg/logger"
+	"avito-backend-trainee-assignment-spring-2025/pkg/metrics"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
 	"context"
+	"errors"
 	"fmt"
 	"google.golang.org/grpc/reflection"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type PVZGrpcServer struct {
 	pvz_v1.UnimplementedPVZServiceServer
 	pvzRepo interfaces.TxPVZRepository
+	events  eventSubscriber
 }
 
+type eventSubscriber interface {
+	Subscribe(ctx context.Context, filter models.EventFilter, lastEventID int64) (<-chan *models.PVZEvent, error)
+}
+
+// maxGRPCPageSize ограничивает размер страницы, если клиент запросил постраничную выдачу.
+const maxGRPCPageSize = 1000
+
 func (s *PVZGrpcServer) GetPVZList(ctx context.Context, req *pvz_v1.GetPVZListRequest) (*pvz_v1.GetPVZListResponse, error) {
-	log.Info().Msg("GRPC request: GetPVZList")
+	zerolog.Ctx(ctx).Info().
+		Int32("limit", req.GetLimit()).
+		Bool("cursor", req.GetCursor() != "").
+		Msg("GRPC request: GetPVZList")
 
 	filter := models.PVZFilter{
-		Page:  1,
-		Limit: 1000000000,
+		Page:      1,
+		Limit:     1000000000,
+		WithTotal: req.GetWithTotal(),
+	}
+
+	if req.GetLimit() > 0 {
+		filter.Limit = min(int(req.GetLimit()), maxGRPCPageSize)
+	}
+
+	if req.GetCursor() != "" {
+		cursor, err := models.DecodePVZCursor(req.GetCursor())
+		if err != nil {
+			return nil, grpcError(ctx, err)
+		}
+		filter.Cursor = cursor
+		filter.Page = 0
+	}
+
+	if key, hasKey := apiKeyFromContext(ctx); hasKey {
+		filter.PVZIDs = key.PVZIDs
 	}
 
-	pvzList, _, err := s.pvzRepo.GetAll(ctx, filter)
+	if err := filter.Validate(); err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	pvzList, page, err := s.pvzRepo.GetAll(ctx, filter)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get PVZ list in GRPC handler")
-		return nil, err
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to get PVZ list in GRPC handler")
+		return nil, grpcError(ctx, err)
 	}
 
 	var protoPVZs []*pvz_v1.PVZ
@@ -46,26 +95,252 @@ func (s *PVZGrpcServer) GetPVZList(ctx context.Context, req *pvz_v1.GetPVZListRe
 		protoPVZs = append(protoPVZs, protoPVZ)
 	}
 
-	return &pvz_v1.GetPVZListResponse{
-		Pvzs: protoPVZs,
-	}, nil
+	response := &pvz_v1.GetPVZListResponse{
+		Pvzs:       protoPVZs,
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+	if page.Total != nil {
+		total := int64(*page.Total)
+		response.TotalCount = &total
+	}
+
+	return response, nil
 }
 
-func StartGRPCServer(cfg *config.Config, pvzRepo interfaces.TxPVZRepository) error {
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPC.Port))
+func (s *PVZGrpcServer) GetNearbyPVZ(ctx context.Context, req *pvz_v1.GetNearbyPVZRequest) (*pvz_v1.GetNearbyPVZResponse, error) {
+	zerolog.Ctx(ctx).Info().
+		Float64("radius_meters", req.GetRadiusMeters()).
+		Int32("limit", req.GetLimit()).
+		Bool("open_now", req.GetOpenNow()).
+		Msg("GRPC request: GetNearbyPVZ")
+
+	filter := models.NearbyFilter{
+		Latitude:     req.GetLatitude(),
+		Longitude:    req.GetLongitude(),
+		RadiusMeters: models.DefaultNearbyRadiusMeters,
+		Limit:        models.DefaultNearbyLimit,
+	}
+
+	if req.GetRadiusMeters() > 0 {
+		filter.RadiusMeters = req.GetRadiusMeters()
+	}
+
+	if req.GetLimit() > 0 {
+		filter.Limit = min(int(req.GetLimit()), models.MaxNearbyLimit)
+	}
+
+	if req.GetOpenNow() {
+		now := time.Now()
+		filter.OpenAt = &now
+	}
+
+	if key, hasKey := apiKeyFromContext(ctx); hasKey {
+		filter.PVZIDs = key.PVZIDs
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	nearby, err := s.pvzRepo.FindNearby(ctx, filter)
 	if err != nil {
-		return fmt.Errorf("failed to listen: %v", err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to find nearby PVZ in GRPC handler")
+		return nil, grpcError(ctx, err)
+	}
+
+	response := &pvz_v1.GetNearbyPVZResponse{}
+	for _, item := range nearby {
+		response.Pvzs = append(response.Pvzs, &pvz_v1.NearbyPVZ{
+			Pvz: &pvz_v1.PVZ{
+				Id:               item.PVZ.ID.String(),
+				RegistrationDate: timestamppb.New(item.PVZ.RegistrationDate),
+				City:             item.PVZ.City,
+			},
+			DistanceMeters: item.DistanceMeters,
+		})
+	}
+
+	return response, nil
+}
+
+// WatchReceptions отправляет клиенту события ленты изменений, пока он не отключится.
+// Если клиент читает медленнее, чем появляются события, Send ждет его, а EventService
+// тем временем копит события в буфере подписчика и при переполнении дочитывает их из
+// БД, так что поток ничего не теряет и не занимает лишнюю память.
+func (s *PVZGrpcServer) WatchReceptions(req *pvz_v1.WatchReceptionsRequest, stream grpc.ServerStreamingServer[pvz_v1.ReceptionEvent]) error {
+	ctx := stream.Context()
+
+	zerolog.Ctx(ctx).Info().
+		Int("pvz_ids", len(req.GetPvzIds())).
+		Strs("cities", req.GetCities()).
+		Bool("resume", req.GetResumeToken() != "").
+		Msg("GRPC request: WatchReceptions")
+
+	filter, err := watchFilter(ctx, req)
+	if err != nil {
+		return err
 	}
 
-	grpcServer := grpc.NewServer()
+	lastEventID, err := parseResumeToken(req.GetResumeToken())
+	if err != nil {
+		return grpcError(ctx, err)
+	}
+
+	events, err := s.events.Subscribe(ctx, filter, lastEventID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to subscribe to PVZ events in GRPC handler")
+		return status.Error(codes.Unavailable, "event stream is unavailable")
+	}
+
+	startTime := time.Now()
+	sent := 0
+
+	defer func() {
+		zerolog.Ctx(ctx).Debug().
+			Int("events", sent).
+			Dur("duration", time.Since(startTime)).
+			Msg("GRPC WatchReceptions stream closed")
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case event, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return status.FromContextError(ctx.Err()).Err()
+				}
+				// Сервер останавливается или БД недоступна: клиент переподключится с
+				// resume_token последнего события
+				return status.Error(codes.Unavailable, "event stream closed, resume with the last resume_token")
+			}
+
+			if err := stream.Send(toProtoReceptionEvent(event)); err != nil {
+				zerolog.Ctx(ctx).Debug().Err(err).Msg("Failed to send GRPC reception event")
+				return err
+			}
+			sent++
+		}
+	}
+}
+
+// watchFilter собирает фильтр ленты из запроса. Ключу, которому доступны только
+// отдельные ПВЗ, нельзя запросить другие ПВЗ, а без pvz_ids он получает события своих ПВЗ.
+func watchFilter(ctx context.Context, req *pvz_v1.WatchReceptionsRequest) (models.EventFilter, error) {
+	key, hasKey := apiKeyFromContext(ctx)
+
+	filter := models.EventFilter{Cities: req.GetCities()}
+
+	for _, value := range req.GetPvzIds() {
+		pvzID, err := uuid.Parse(value)
+		if err != nil {
+			return models.EventFilter{}, grpcError(ctx, apperrors.ErrInvalidPVZID)
+		}
+		if hasKey && !key.CanAccessPVZ(pvzID) {
+			return models.EventFilter{}, grpcError(ctx, apperrors.ErrPVZAccessForbidden)
+		}
+		filter.PVZIDs = append(filter.PVZIDs, pvzID)
+	}
+	if hasKey && len(key.PVZIDs) > 0 && len(filter.PVZIDs) == 0 {
+		filter.PVZIDs = key.PVZIDs
+	}
+
+	if err := filter.Validate(); err != nil {
+		return models.EventFilter{}, grpcError(ctx, err)
+	}
+
+	return filter, nil
+}
+
+// parseResumeToken возвращает ID события, после которого продолжается лента. Токен -
+// десятичный ID события, тот же, что id в SSE.
+func parseResumeToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || id < 0 {
+		return 0, apperrors.ErrInvalidResumeToken
+	}
+
+	return id, nil
+}
+
+func toProtoReceptionEvent(event *models.PVZEvent) *pvz_v1.ReceptionEvent {
+	result := &pvz_v1.ReceptionEvent{
+		ResumeToken:     strconv.FormatInt(event.ID, 10),
+		CreatedAt:       timestamppb.New(event.CreatedAt),
+		PvzId:           event.PVZID.String(),
+		City:            event.City,
+		ReceptionId:     event.ReceptionID.String(),
+		ReceptionStatus: pvz_v1.ReceptionStatus_RECEPTION_STATUS_IN_PROGRESS,
+	}
+
+	var productID string
+	if event.ProductID != nil {
+		productID = event.ProductID.String()
+	}
+
+	switch event.Type {
+	case models.EventTypeReceptionOpened:
+		result.Event = &pvz_v1.ReceptionEvent_ReceptionOpened{ReceptionOpened: &pvz_v1.ReceptionOpened{}}
+	case models.EventTypeReceptionClosed:
+		result.ReceptionStatus = pvz_v1.ReceptionStatus_RECEPTION_STATUS_CLOSED
+		result.Event = &pvz_v1.ReceptionEvent_ReceptionClosed{ReceptionClosed: &pvz_v1.ReceptionClosed{}}
+	case models.EventTypeProductAdded:
+		result.Event = &pvz_v1.ReceptionEvent_ProductAdded{ProductAdded: &pvz_v1.ProductAdded{
+			ProductId:   productID,
+			ProductType: event.ProductType,
+		}}
+	case models.EventTypeProductRemoved:
+		result.Event = &pvz_v1.ReceptionEvent_ProductRemoved{ProductRemoved: &pvz_v1.ProductRemoved{
+			ProductId:   productID,
+			ProductType: event.ProductType,
+		}}
+	}
+
+	return result
+}
+
+// newGRPCServer создает сервер с цепочками перехватчиков (трассировка, метрики, журнал
+// вызовов, проверка учетных данных и ключей идемпотентности) и keepalive, который
+// закрывает соединения отключившихся клиентов.
+func newGRPCServer(cfg *config.Config, pvzRepo interfaces.TxPVZRepository, events eventSubscriber, keys apiKeyAuthenticator, tokens accessTokenChecker, idempotency idempotencyKeeper) *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(tracingInterceptor(), metricsInterceptor(), loggingInterceptor(), authInterceptor(keys, tokens, cfg), idempotencyInterceptor(idempotency)),
+		grpc.ChainStreamInterceptor(tracingStreamInterceptor(), metricsStreamInterceptor(), loggingStreamInterceptor(), authStreamInterceptor(keys, tokens, cfg)),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    cfg.GRPC.KeepaliveTime,
+			Timeout: cfg.GRPC.KeepaliveTimeout,
+		}),
+		// Клиентам разрешено пинговать сервер не чаще KeepaliveTimeout, в том числе без
+		// открытых потоков
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.GRPC.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	)
 	pvzService := &PVZGrpcServer{
 		pvzRepo: pvzRepo,
+		events:  events,
 	}
 
 	reflection.Register(grpcServer)
 
 	pvz_v1.RegisterPVZServiceServer(grpcServer, pvzService)
 
+	return grpcServer
+}
+
+func StartGRPCServer(cfg *config.Config, grpcServer *grpc.Server) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPC.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %v", err)
+	}
+
 	log.Info().Str("port", cfg.GRPC.Port).Msg("Starting gRPC server")
 	if err := grpcServer.Serve(lis); err != nil {
 		return fmt.Errorf("failed to serve: %v", err)
@@ -83,6 +358,11 @@ func main() {
 
 	logger.Setup(cfg.Logger)
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up tracing")
+	}
+
 	db, err := postgres.New(&cfg.Postgres)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to database")
@@ -90,8 +370,71 @@ func main() {
 	defer db.Close()
 
 	pvzRepo := postgres.NewPVZRepository(db)
+	auditService := services.NewAuditService(postgres.NewAuditRepository(db))
+	txManager := postgres.NewTxManager(db)
+	serviceAccountService := services.NewServiceAccountService(postgres.NewServiceAccountRepository(db), auditService, txManager)
+	userService := services.NewUserService(postgres.NewUserRepository(db), cfg.JWT, cfg.MFA, services.NewPasswordChangeCache(cfg.JWT.RevocationCacheTTL), auditService, txManager)
+	// Истекшие ключи идемпотентности удаляет HTTP API
+	idempotencyService := services.NewIdempotencyService(postgres.NewIdempotencyRepository(db), cfg.Idempotency)
+
+	eventListener, err := postgres.NewEventListener(&cfg.Postgres)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to listen for PVZ events")
+	}
+	defer eventListener.Close()
+
+	eventService := services.NewEventService(postgres.NewEventRepository(db), eventListener.Wakeups(), cfg.Events)
+	if err := eventService.Start(context.Background()); err != nil {
+		log.Fatal().Err(err).Msg("Failed to start event service")
+	}
+
+	grpcServer := newGRPCServer(cfg, pvzRepo, eventService, serviceAccountService, userService, idempotencyService)
+
+	go func() {
+		if err := StartGRPCServer(cfg, grpcServer); err != nil {
+			log.Fatal().Err(err).Msg("Failed to start gRPC server")
+		}
+	}()
+
+	metricsServer := metrics.NewServer(cfg.Prometheus.Port)
+	go func() {
+		if err := metricsServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("Failed to start metrics server")
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	<-quit
+	log.Info().Msg("Shutting down gRPC server")
+
+	// GracefulStop ждет завершения потоков, поэтому сначала закрываются ленты событий:
+	// клиенты WatchReceptions получают UNAVAILABLE и переподключаются к другому экземпляру
+	eventService.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		grpcServer.Stop()
+	}
+
+	log.Info().Msg("gRPC server stopped")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := metricsServer.Stop(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to stop metrics server")
+	}
 
-	if err := StartGRPCServer(cfg, pvzRepo); err != nil {
-		log.Fatal().Err(err).Msg("Failed to start gRPC server")
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
 	}
 }
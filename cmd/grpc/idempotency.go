@@ -0,0 +1,152 @@
+package main
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// idempotencyKeyMetadata - метаданные с ключом идемпотентности, как заголовок
+	// Idempotency-Key в HTTP API.
+	idempotencyKeyMetadata = "idempotency-key"
+	// idempotentReplayedMetadata отмечает ответ, сохраненный при первом вызове.
+	idempotentReplayedMetadata = "idempotent-replayed"
+
+	// idempotentResponseType - Content-Type сохраненного ответа gRPC: сообщение,
+	// упакованное в google.protobuf.Any.
+	idempotentResponseType = "application/grpc+proto"
+)
+
+// idempotentMethods - изменяющие унарные методы, которые принимают idempotency-key.
+// Сейчас все унарные методы только читают: повтор чтения должен получать свежие
+// данные, а не ответ, сохраненный на IDEMPOTENCY_TTL.
+var idempotentMethods = map[string]bool{}
+
+// idempotencyKeeper сохраняет ответы на вызовы с ключом идемпотентности.
+type idempotencyKeeper interface {
+	Begin(ctx context.Context, actorID uuid.UUID, key, fingerprint string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *models.IdempotencyRecord, statusCode int, header http.Header, body []byte) error
+	Release(ctx context.Context, record *models.IdempotencyRecord) error
+}
+
+// idempotencyInterceptor выполняет вызов метода из idempotentMethods с метаданными
+// idempotency-key один раз, как Idempotency-Key в HTTP API: повтор с тем же ключом и
+// запросом получает сохраненный ответ. Сохраняются только успешные ответы, ошибка
+// освобождает ключ. Ключи принадлежат тому, кто выполняет вызов, поэтому вызов без
+// учетных данных с ключом отклоняется. Остальные методы ключ не учитывают. Ставится
+// после authInterceptor.
+func idempotencyInterceptor(keeper idempotencyKeeper) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !idempotentMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(idempotencyKeyMetadata)
+		if len(values) == 0 || values[0] == "" {
+			return handler(ctx, req)
+		}
+		key := values[0]
+
+		actorID, ok := actorFromContext(ctx)
+		if !ok {
+			return nil, grpcError(ctx, apperrors.ErrUnauthenticated)
+		}
+
+		message, ok := req.(proto.Message)
+		if !ok {
+			return nil, grpcError(ctx, fmt.Errorf("unexpected request type %T", req))
+		}
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		if err != nil {
+			return nil, grpcError(ctx, fmt.Errorf("failed to encode request: %w", err))
+		}
+		fingerprint := models.IdempotencyFingerprint("GRPC", info.FullMethod, body)
+
+		record, err := keeper.Begin(ctx, actorID, key, fingerprint)
+		if err != nil {
+			zerolog.Ctx(ctx).Debug().Err(err).Str("idempotency_key", key).Msg("Idempotency key rejected")
+			return nil, grpcError(ctx, err)
+		}
+
+		if record.IsCompleted() {
+			zerolog.Ctx(ctx).Debug().Str("idempotency_key", key).Msg("Replaying idempotent response")
+
+			resp, err := decodeIdempotentResponse(record.Body)
+			if err != nil {
+				return nil, grpcError(ctx, err)
+			}
+			_ = grpc.SetHeader(ctx, metadata.Pairs(idempotentReplayedMetadata, "true"))
+			return resp, nil
+		}
+
+		// Ответ сохраняется и после отключения клиента: повтор должен его получить
+		saveCtx := context.WithoutCancel(ctx)
+		completed := false
+
+		defer func() {
+			if completed {
+				return
+			}
+			if err := keeper.Release(saveCtx, record); err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Str("idempotency_key", key).Msg("Failed to release idempotency key")
+			}
+		}()
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+
+		stored, err := encodeIdempotentResponse(resp)
+		if err == nil {
+			header := http.Header{"Content-Type": {idempotentResponseType}}
+			err = keeper.Complete(saveCtx, record, http.StatusOK, header, stored)
+		}
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("idempotency_key", key).Msg("Failed to save idempotent response")
+			return resp, nil
+		}
+		completed = true
+
+		return resp, nil
+	}
+}
+
+func encodeIdempotentResponse(resp any) ([]byte, error) {
+	message, ok := resp.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", resp)
+	}
+
+	packed, err := anypb.New(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack response: %w", err)
+	}
+
+	return proto.Marshal(packed)
+}
+
+func decodeIdempotentResponse(body []byte) (proto.Message, error) {
+	var packed anypb.Any
+	if err := proto.Unmarshal(body, &packed); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotent response: %w", err)
+	}
+
+	resp, err := packed.UnmarshalNew()
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack idempotent response: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/auth"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/i18n"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodScopes - области API-ключа, необходимые для вызова метода.
+var methodScopes = map[string]string{
+	getPVZListMethod:      models.ScopePVZRead,
+	getNearbyPVZMethod:    models.ScopePVZRead,
+	watchReceptionsMethod: models.ScopePVZRead,
+}
+
+// requestIDMetadata - метаданные с идентификатором запроса, как заголовок X-Request-ID в HTTP API.
+const requestIDMetadata = "x-request-id"
+
+const (
+	getPVZListMethod      = "/pvz.v1.PVZService/GetPVZList"
+	getNearbyPVZMethod    = "/pvz.v1.PVZService/GetNearbyPVZ"
+	watchReceptionsMethod = "/pvz.v1.PVZService/WatchReceptions"
+)
+
+type apiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error)
+}
+
+// accessTokenChecker проверяет, что JWT не отозван, так же как в HTTP API.
+type accessTokenChecker interface {
+	CheckAccessToken(ctx context.Context, claims *auth.Claims) error
+}
+
+type apiKeyContextKey struct{}
+
+type actorContextKey struct{}
+
+// apiKeyFromContext возвращает API-ключ, по которому выполнен вызов.
+func apiKeyFromContext(ctx context.Context) (*models.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(*models.APIKey)
+	return key, ok
+}
+
+// actorFromContext возвращает пользователя или сервисную учетную запись, выполняющую вызов.
+func actorFromContext(ctx context.Context) (uuid.UUID, bool) {
+	actorID, ok := ctx.Value(actorContextKey{}).(uuid.UUID)
+	return actorID, ok
+}
+
+// authInterceptor принимает API-ключ в метаданных x-api-key или JWT в authorization.
+// Вызовы без учетных данных пропускаются, если GRPC_AUTH_REQUIRED не установлен.
+func authInterceptor(keys apiKeyAuthenticator, tokens accessTokenChecker, cfg *config.Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, keys, tokens, cfg, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// authStreamInterceptor проверяет учетные данные потоковых вызовов так же, как
+// authInterceptor.
+func authStreamInterceptor(keys apiKeyAuthenticator, tokens accessTokenChecker, cfg *config.Config) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), keys, tokens, cfg, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextStream подменяет контекст потока, чтобы обработчик видел API-ключ и логгер вызова.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// loggingInterceptor принимает идентификатор запроса из метаданных x-request-id или
+// создает новый, кладет в контекст логгер с ним и пишет по строке на каждый вызов.
+func loggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = withRequestLogger(ctx, info.FullMethod)
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		logCall(ctx, start, err)
+		return resp, err
+	}
+}
+
+// loggingStreamInterceptor - loggingInterceptor для потоковых вызовов. Строка пишется
+// при завершении потока.
+func loggingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := withRequestLogger(ss.Context(), info.FullMethod)
+		start := time.Now()
+
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+
+		logCall(ctx, start, err)
+		return err
+	}
+}
+
+func withRequestLogger(ctx context.Context, fullMethod string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var requestID string
+	if values := md.Get(requestIDMetadata); len(values) > 0 && len(values[0]) <= 64 {
+		requestID = values[0]
+	}
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, requestID))
+
+	logContext := log.With().
+		Str("request_id", requestID).
+		Str("method", fullMethod)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		logContext = logContext.Str("trace_id", spanContext.TraceID().String())
+	}
+
+	logger := logContext.Logger()
+	return logger.WithContext(ctx)
+}
+
+func logCall(ctx context.Context, start time.Time, err error) {
+	code := status.Code(err)
+
+	event := zerolog.Ctx(ctx).Info()
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		event = zerolog.Ctx(ctx).Error()
+	}
+
+	event.
+		Str("code", code.String()).
+		Dur("duration", time.Since(start)).
+		Msg("GRPC request")
+}
+
+// authenticate проверяет учетные данные вызова fullMethod и возвращает контекст с
+// API-ключом, если вызов выполнен по нему, и языком сообщений об ошибках. JWT проходит
+// те же проверки, что и в HTTP API: отзыв после смены пароля и политику 2FA.
+func authenticate(ctx context.Context, keys apiKeyAuthenticator, tokens accessTokenChecker, cfg *config.Config, fullMethod string) (context.Context, error) {
+	ctx = withLanguage(ctx)
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get("x-api-key"); len(values) > 0 && values[0] != "" {
+		key, err := keys.Authenticate(ctx, values[0])
+		if err != nil {
+			return nil, grpcError(ctx, err)
+		}
+
+		scope, restricted := methodScopes[fullMethod]
+		if !restricted || !key.HasScope(scope) {
+			return nil, grpcError(ctx, apperrors.ErrInsufficientScope)
+		}
+
+		addLogFields(ctx, "service_account_id", key.ServiceAccountID.String(), models.RoleServiceAccount)
+		ctx = context.WithValue(ctx, actorContextKey{}, key.ServiceAccountID)
+		return context.WithValue(ctx, apiKeyContextKey{}, key), nil
+	}
+
+	if values := md.Get("authorization"); len(values) > 0 && values[0] != "" {
+		token, found := strings.CutPrefix(values[0], "Bearer ")
+		if !found {
+			return nil, grpcError(ctx, apperrors.ErrInvalidAuthorization)
+		}
+		claims, err := auth.ValidateToken(token, cfg.JWT.Secret)
+		if err != nil {
+			zerolog.Ctx(ctx).Debug().Err(err).Msg("Invalid gRPC token")
+			return nil, grpcError(ctx, apperrors.ErrInvalidToken)
+		}
+		if claims.Dummy && !cfg.DummyLogin.AcceptTokens {
+			return nil, grpcError(ctx, apperrors.ErrDummyTokenRejected)
+		}
+		if err := tokens.CheckAccessToken(ctx, claims); err != nil {
+			return nil, grpcError(ctx, err)
+		}
+		// В gRPC API нет методов управления 2FA, поэтому политика действует на все вызовы
+		if cfg.MFA.RequiredForPrivileged && models.IsPrivilegedRole(claims.Role) && !claims.MFA {
+			return nil, grpcError(ctx, apperrors.ErrMFARequired)
+		}
+
+		// Язык из профиля пользователя важнее метаданных accept-language
+		if i18n.Supported(claims.Locale) {
+			ctx = i18n.WithLanguage(ctx, claims.Locale)
+		}
+
+		addLogFields(ctx, "user_id", claims.UserID.String(), claims.Role)
+		return context.WithValue(ctx, actorContextKey{}, claims.UserID), nil
+	}
+
+	if cfg.GRPC.AuthRequired {
+		return nil, grpcError(ctx, apperrors.ErrUnauthenticated)
+	}
+
+	return ctx, nil
+}
+
+// addLogFields добавляет к логгеру вызова того, кто его выполняет. Логгер, который создал
+// loggingInterceptor, меняется на месте, чтобы эти поля попали и в строку о вызове.
+func addLogFields(ctx context.Context, idField, id, role string) {
+	zerolog.Ctx(ctx).UpdateContext(func(c zerolog.Context) zerolog.Context {
+		return c.Str(idField, id).Str("role", role)
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/pkg/metrics"
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// metricsInterceptor считает вызовы, их длительность и размер сообщений так же, как
+// metricsMiddleware в HTTP API. Вместо класса статуса используется код gRPC.
+func metricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		inFlight := metrics.GRPCRequestsInFlight.WithLabelValues(info.FullMethod)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		observeMessageSize(metrics.GRPCRequestSize, info.FullMethod, req)
+
+		resp, err := handler(ctx, req)
+
+		if err == nil {
+			observeMessageSize(metrics.GRPCResponseSize, info.FullMethod, resp)
+		}
+		observeCall(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// metricsStreamInterceptor - metricsInterceptor для потоковых вызовов. Длительность
+// вызова - время жизни потока, размеры считаются для каждого сообщения.
+func metricsStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		inFlight := metrics.GRPCRequestsInFlight.WithLabelValues(info.FullMethod)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+
+		err := handler(srv, &measuredStream{ServerStream: ss, method: info.FullMethod})
+
+		observeCall(info.FullMethod, start, err)
+		return err
+	}
+}
+
+func observeCall(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	metrics.GRPCRequestsTotal.WithLabelValues(method, code).Inc()
+	metrics.GRPCRequestDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
+
+// observeMessageSize записывает размер сообщения protobuf; другие сообщения пропускаются.
+func observeMessageSize(histogram *prometheus.HistogramVec, method string, msg any) {
+	if message, ok := msg.(proto.Message); ok {
+		histogram.WithLabelValues(method).Observe(float64(proto.Size(message)))
+	}
+}
+
+// measuredStream записывает размеры сообщений потока.
+type measuredStream struct {
+	grpc.ServerStream
+	method string
+}
+
+func (s *measuredStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		observeMessageSize(metrics.GRPCResponseSize, s.method, m)
+	}
+	return err
+}
+
+func (s *measuredStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		observeMessageSize(metrics.GRPCRequestSize, s.method, m)
+	}
+	return err
+}
@@ -128,8 +128,13 @@ func (x *PVZ) GetCity() string {
 	return ""
 }
 
+// Без limit возвращаются все ПВЗ. С limit список отдается страницами, упорядоченными
+// по (registration_date, id); cursor берется из next_cursor или prev_cursor ответа.
 type GetPVZListRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	WithTotal     bool                   `protobuf:"varint,3,opt,name=with_total,json=withTotal,proto3" json:"with_total,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -164,9 +169,33 @@ func (*GetPVZListRequest) Descriptor() ([]byte, []int) {
 	return file_pvz_proto_rawDescGZIP(), []int{1}
 }
 
+func (x *GetPVZListRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetPVZListRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetPVZListRequest) GetWithTotal() bool {
+	if x != nil {
+		return x.WithTotal
+	}
+	return false
+}
+
 type GetPVZListResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Pvzs          []*PVZ                 `protobuf:"bytes,1,rep,name=pvzs,proto3" json:"pvzs,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	PrevCursor    string                 `protobuf:"bytes,3,opt,name=prev_cursor,json=prevCursor,proto3" json:"prev_cursor,omitempty"`
+	TotalCount    *int64                 `protobuf:"varint,4,opt,name=total_count,json=totalCount,proto3,oneof" json:"total_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -208,6 +237,606 @@ func (x *GetPVZListResponse) GetPvzs() []*PVZ {
 	return nil
 }
 
+func (x *GetPVZListResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *GetPVZListResponse) GetPrevCursor() string {
+	if x != nil {
+		return x.PrevCursor
+	}
+	return ""
+}
+
+func (x *GetPVZListResponse) GetTotalCount() int64 {
+	if x != nil && x.TotalCount != nil {
+		return *x.TotalCount
+	}
+	return 0
+}
+
+// Ближайшие ПВЗ к точке по возрастанию расстояния. radius_meters по умолчанию 5000
+// (не больше 50000), limit по умолчанию 10 (не больше 100). open_now оставляет только
+// ПВЗ, работающие сейчас по расписанию.
+type GetNearbyPVZRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	RadiusMeters  float64                `protobuf:"fixed64,3,opt,name=radius_meters,json=radiusMeters,proto3" json:"radius_meters,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	OpenNow       bool                   `protobuf:"varint,5,opt,name=open_now,json=openNow,proto3" json:"open_now,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNearbyPVZRequest) Reset() {
+	*x = GetNearbyPVZRequest{}
+	mi := &file_pvz_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNearbyPVZRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNearbyPVZRequest) ProtoMessage() {}
+
+func (x *GetNearbyPVZRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNearbyPVZRequest.ProtoReflect.Descriptor instead.
+func (*GetNearbyPVZRequest) Descriptor() ([]byte, []int) {
+	return file_pvz_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetNearbyPVZRequest) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *GetNearbyPVZRequest) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *GetNearbyPVZRequest) GetRadiusMeters() float64 {
+	if x != nil {
+		return x.RadiusMeters
+	}
+	return 0
+}
+
+func (x *GetNearbyPVZRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetNearbyPVZRequest) GetOpenNow() bool {
+	if x != nil {
+		return x.OpenNow
+	}
+	return false
+}
+
+type NearbyPVZ struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Pvz            *PVZ                   `protobuf:"bytes,1,opt,name=pvz,proto3" json:"pvz,omitempty"`
+	DistanceMeters float64                `protobuf:"fixed64,2,opt,name=distance_meters,json=distanceMeters,proto3" json:"distance_meters,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *NearbyPVZ) Reset() {
+	*x = NearbyPVZ{}
+	mi := &file_pvz_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NearbyPVZ) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NearbyPVZ) ProtoMessage() {}
+
+func (x *NearbyPVZ) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NearbyPVZ.ProtoReflect.Descriptor instead.
+func (*NearbyPVZ) Descriptor() ([]byte, []int) {
+	return file_pvz_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NearbyPVZ) GetPvz() *PVZ {
+	if x != nil {
+		return x.Pvz
+	}
+	return nil
+}
+
+func (x *NearbyPVZ) GetDistanceMeters() float64 {
+	if x != nil {
+		return x.DistanceMeters
+	}
+	return 0
+}
+
+type GetNearbyPVZResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pvzs          []*NearbyPVZ           `protobuf:"bytes,1,rep,name=pvzs,proto3" json:"pvzs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNearbyPVZResponse) Reset() {
+	*x = GetNearbyPVZResponse{}
+	mi := &file_pvz_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNearbyPVZResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNearbyPVZResponse) ProtoMessage() {}
+
+func (x *GetNearbyPVZResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNearbyPVZResponse.ProtoReflect.Descriptor instead.
+func (*GetNearbyPVZResponse) Descriptor() ([]byte, []int) {
+	return file_pvz_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetNearbyPVZResponse) GetPvzs() []*NearbyPVZ {
+	if x != nil {
+		return x.Pvzs
+	}
+	return nil
+}
+
+// Лента изменений приемок: открытие и закрытие приемок, добавление и удаление товаров
+// по мере их фиксации. Пустые pvz_ids и cities - все ПВЗ. Без resume_token приходят
+// только новые события; чтобы после переподключения ничего не пропустить, передайте
+// resume_token последнего полученного события. Токен совпадает с id события в SSE
+// (GET /events/stream), поэтому ленту можно продолжить и через HTTP.
+type WatchReceptionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PvzIds        []string               `protobuf:"bytes,1,rep,name=pvz_ids,json=pvzIds,proto3" json:"pvz_ids,omitempty"`
+	Cities        []string               `protobuf:"bytes,2,rep,name=cities,proto3" json:"cities,omitempty"`
+	ResumeToken   string                 `protobuf:"bytes,3,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchReceptionsRequest) Reset() {
+	*x = WatchReceptionsRequest{}
+	mi := &file_pvz_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchReceptionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchReceptionsRequest) ProtoMessage() {}
+
+func (x *WatchReceptionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchReceptionsRequest.ProtoReflect.Descriptor instead.
+func (*WatchReceptionsRequest) Descriptor() ([]byte, []int) {
+	return file_pvz_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchReceptionsRequest) GetPvzIds() []string {
+	if x != nil {
+		return x.PvzIds
+	}
+	return nil
+}
+
+func (x *WatchReceptionsRequest) GetCities() []string {
+	if x != nil {
+		return x.Cities
+	}
+	return nil
+}
+
+func (x *WatchReceptionsRequest) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+type ReceptionEvent struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ResumeToken string                 `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	PvzId       string                 `protobuf:"bytes,3,opt,name=pvz_id,json=pvzId,proto3" json:"pvz_id,omitempty"`
+	City        string                 `protobuf:"bytes,4,opt,name=city,proto3" json:"city,omitempty"`
+	ReceptionId string                 `protobuf:"bytes,5,opt,name=reception_id,json=receptionId,proto3" json:"reception_id,omitempty"`
+	// Статус приемки после события
+	ReceptionStatus ReceptionStatus `protobuf:"varint,6,opt,name=reception_status,json=receptionStatus,proto3,enum=pvz.v1.ReceptionStatus" json:"reception_status,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*ReceptionEvent_ReceptionOpened
+	//	*ReceptionEvent_ReceptionClosed
+	//	*ReceptionEvent_ProductAdded
+	//	*ReceptionEvent_ProductRemoved
+	Event         isReceptionEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceptionEvent) Reset() {
+	*x = ReceptionEvent{}
+	mi := &file_pvz_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceptionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceptionEvent) ProtoMessage() {}
+
+func (x *ReceptionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceptionEvent.ProtoReflect.Descriptor instead.
+func (*ReceptionEvent) Descriptor() ([]byte, []int) {
+	return file_pvz_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ReceptionEvent) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *ReceptionEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ReceptionEvent) GetPvzId() string {
+	if x != nil {
+		return x.PvzId
+	}
+	return ""
+}
+
+func (x *ReceptionEvent) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *ReceptionEvent) GetReceptionId() string {
+	if x != nil {
+		return x.ReceptionId
+	}
+	return ""
+}
+
+func (x *ReceptionEvent) GetReceptionStatus() ReceptionStatus {
+	if x != nil {
+		return x.ReceptionStatus
+	}
+	return ReceptionStatus_RECEPTION_STATUS_IN_PROGRESS
+}
+
+func (x *ReceptionEvent) GetEvent() isReceptionEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ReceptionEvent) GetReceptionOpened() *ReceptionOpened {
+	if x != nil {
+		if x, ok := x.Event.(*ReceptionEvent_ReceptionOpened); ok {
+			return x.ReceptionOpened
+		}
+	}
+	return nil
+}
+
+func (x *ReceptionEvent) GetReceptionClosed() *ReceptionClosed {
+	if x != nil {
+		if x, ok := x.Event.(*ReceptionEvent_ReceptionClosed); ok {
+			return x.ReceptionClosed
+		}
+	}
+	return nil
+}
+
+func (x *ReceptionEvent) GetProductAdded() *ProductAdded {
+	if x != nil {
+		if x, ok := x.Event.(*ReceptionEvent_ProductAdded); ok {
+			return x.ProductAdded
+		}
+	}
+	return nil
+}
+
+func (x *ReceptionEvent) GetProductRemoved() *ProductRemoved {
+	if x != nil {
+		if x, ok := x.Event.(*ReceptionEvent_ProductRemoved); ok {
+			return x.ProductRemoved
+		}
+	}
+	return nil
+}
+
+type isReceptionEvent_Event interface {
+	isReceptionEvent_Event()
+}
+
+type ReceptionEvent_ReceptionOpened struct {
+	ReceptionOpened *ReceptionOpened `protobuf:"bytes,10,opt,name=reception_opened,json=receptionOpened,proto3,oneof"`
+}
+
+type ReceptionEvent_ReceptionClosed struct {
+	ReceptionClosed *ReceptionClosed `protobuf:"bytes,11,opt,name=reception_closed,json=receptionClosed,proto3,oneof"`
+}
+
+type ReceptionEvent_ProductAdded struct {
+	ProductAdded *ProductAdded `protobuf:"bytes,12,opt,name=product_added,json=productAdded,proto3,oneof"`
+}
+
+type ReceptionEvent_ProductRemoved struct {
+	ProductRemoved *ProductRemoved `protobuf:"bytes,13,opt,name=product_removed,json=productRemoved,proto3,oneof"`
+}
+
+func (*ReceptionEvent_ReceptionOpened) isReceptionEvent_Event() {}
+
+func (*ReceptionEvent_ReceptionClosed) isReceptionEvent_Event() {}
+
+func (*ReceptionEvent_ProductAdded) isReceptionEvent_Event() {}
+
+func (*ReceptionEvent_ProductRemoved) isReceptionEvent_Event() {}
+
+type ReceptionOpened struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceptionOpened) Reset() {
+	*x = ReceptionOpened{}
+	mi := &file_pvz_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceptionOpened) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceptionOpened) ProtoMessage() {}
+
+func (x *ReceptionOpened) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceptionOpened.ProtoReflect.Descriptor instead.
+func (*ReceptionOpened) Descriptor() ([]byte, []int) {
+	return file_pvz_proto_rawDescGZIP(), []int{8}
+}
+
+type ReceptionClosed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceptionClosed) Reset() {
+	*x = ReceptionClosed{}
+	mi := &file_pvz_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceptionClosed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceptionClosed) ProtoMessage() {}
+
+func (x *ReceptionClosed) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceptionClosed.ProtoReflect.Descriptor instead.
+func (*ReceptionClosed) Descriptor() ([]byte, []int) {
+	return file_pvz_proto_rawDescGZIP(), []int{9}
+}
+
+type ProductAdded struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ProductType   string                 `protobuf:"bytes,2,opt,name=product_type,json=productType,proto3" json:"product_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductAdded) Reset() {
+	*x = ProductAdded{}
+	mi := &file_pvz_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductAdded) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductAdded) ProtoMessage() {}
+
+func (x *ProductAdded) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductAdded.ProtoReflect.Descriptor instead.
+func (*ProductAdded) Descriptor() ([]byte, []int) {
+	return file_pvz_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ProductAdded) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *ProductAdded) GetProductType() string {
+	if x != nil {
+		return x.ProductType
+	}
+	return ""
+}
+
+type ProductRemoved struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ProductType   string                 `protobuf:"bytes,2,opt,name=product_type,json=productType,proto3" json:"product_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductRemoved) Reset() {
+	*x = ProductRemoved{}
+	mi := &file_pvz_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductRemoved) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductRemoved) ProtoMessage() {}
+
+func (x *ProductRemoved) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductRemoved.ProtoReflect.Descriptor instead.
+func (*ProductRemoved) Descriptor() ([]byte, []int) {
+	return file_pvz_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ProductRemoved) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *ProductRemoved) GetProductType() string {
+	if x != nil {
+		return x.ProductType
+	}
+	return ""
+}
+
 var File_pvz_proto protoreflect.FileDescriptor
 
 const file_pvz_proto_rawDesc = "" +
@@ -216,17 +845,69 @@ const file_pvz_proto_rawDesc = "" +
 	"\x03PVZ\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12G\n" +
 	"\x11registration_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x10registrationDate\x12\x12\n" +
-	"\x04city\x18\x03 \x01(\tR\x04city\"\x13\n" +
-	"\x11GetPVZListRequest\"5\n" +
+	"\x04city\x18\x03 \x01(\tR\x04city\"`\n" +
+	"\x11GetPVZListRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x1d\n" +
+	"\n" +
+	"with_total\x18\x03 \x01(\bR\twithTotal\"\xad\x01\n" +
 	"\x12GetPVZListResponse\x12\x1f\n" +
-	"\x04pvzs\x18\x01 \x03(\v2\v.pvz.v1.PVZR\x04pvzs*P\n" +
+	"\x04pvzs\x18\x01 \x03(\v2\v.pvz.v1.PVZR\x04pvzs\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x12\x1f\n" +
+	"\vprev_cursor\x18\x03 \x01(\tR\n" +
+	"prevCursor\x12$\n" +
+	"\vtotal_count\x18\x04 \x01(\x03H\x00R\n" +
+	"totalCount\x88\x01\x01B\x0e\n" +
+	"\f_total_count\"\xa5\x01\n" +
+	"\x13GetNearbyPVZRequest\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12#\n" +
+	"\rradius_meters\x18\x03 \x01(\x01R\fradiusMeters\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x19\n" +
+	"\bopen_now\x18\x05 \x01(\bR\aopenNow\"S\n" +
+	"\tNearbyPVZ\x12\x1d\n" +
+	"\x03pvz\x18\x01 \x01(\v2\v.pvz.v1.PVZR\x03pvz\x12'\n" +
+	"\x0fdistance_meters\x18\x02 \x01(\x01R\x0edistanceMeters\"=\n" +
+	"\x14GetNearbyPVZResponse\x12%\n" +
+	"\x04pvzs\x18\x01 \x03(\v2\x11.pvz.v1.NearbyPVZR\x04pvzs\"l\n" +
+	"\x16WatchReceptionsRequest\x12\x17\n" +
+	"\apvz_ids\x18\x01 \x03(\tR\x06pvzIds\x12\x16\n" +
+	"\x06cities\x18\x02 \x03(\tR\x06cities\x12!\n" +
+	"\fresume_token\x18\x03 \x01(\tR\vresumeToken\"\x95\x04\n" +
+	"\x0eReceptionEvent\x12!\n" +
+	"\fresume_token\x18\x01 \x01(\tR\vresumeToken\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x15\n" +
+	"\x06pvz_id\x18\x03 \x01(\tR\x05pvzId\x12\x12\n" +
+	"\x04city\x18\x04 \x01(\tR\x04city\x12!\n" +
+	"\freception_id\x18\x05 \x01(\tR\vreceptionId\x12B\n" +
+	"\x10reception_status\x18\x06 \x01(\x0e2\x17.pvz.v1.ReceptionStatusR\x0freceptionStatus\x12D\n" +
+	"\x10reception_opened\x18\n" +
+	" \x01(\v2\x17.pvz.v1.ReceptionOpenedH\x00R\x0freceptionOpened\x12D\n" +
+	"\x10reception_closed\x18\v \x01(\v2\x17.pvz.v1.ReceptionClosedH\x00R\x0freceptionClosed\x12;\n" +
+	"\rproduct_added\x18\f \x01(\v2\x14.pvz.v1.ProductAddedH\x00R\fproductAdded\x12A\n" +
+	"\x0fproduct_removed\x18\r \x01(\v2\x16.pvz.v1.ProductRemovedH\x00R\x0eproductRemovedB\a\n" +
+	"\x05event\"\x11\n" +
+	"\x0fReceptionOpened\"\x11\n" +
+	"\x0fReceptionClosed\"P\n" +
+	"\fProductAdded\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12!\n" +
+	"\fproduct_type\x18\x02 \x01(\tR\vproductType\"R\n" +
+	"\x0eProductRemoved\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12!\n" +
+	"\fproduct_type\x18\x02 \x01(\tR\vproductType*P\n" +
 	"\x0fReceptionStatus\x12 \n" +
 	"\x1cRECEPTION_STATUS_IN_PROGRESS\x10\x00\x12\x1b\n" +
-	"\x17RECEPTION_STATUS_CLOSED\x10\x012Q\n" +
+	"\x17RECEPTION_STATUS_CLOSED\x10\x012\xe9\x01\n" +
 	"\n" +
 	"PVZService\x12C\n" +
 	"\n" +
-	"GetPVZList\x12\x19.pvz.v1.GetPVZListRequest\x1a\x1a.pvz.v1.GetPVZListResponseB[ZYgithub.com/mihailpestrikov/avito-backend-trainee-assignment-spring-2025/pvz/pvz_v1;pvz_v1b\x06proto3"
+	"GetPVZList\x12\x19.pvz.v1.GetPVZListRequest\x1a\x1a.pvz.v1.GetPVZListResponse\x12I\n" +
+	"\fGetNearbyPVZ\x12\x1b.pvz.v1.GetNearbyPVZRequest\x1a\x1c.pvz.v1.GetNearbyPVZResponse\x12K\n" +
+	"\x0fWatchReceptions\x12\x1e.pvz.v1.WatchReceptionsRequest\x1a\x16.pvz.v1.ReceptionEvent0\x01B[ZYgithub.com/mihailpestrikov/avito-backend-trainee-assignment-spring-2025/pvz/pvz_v1;pvz_v1b\x06proto3"
 
 var (
 	file_pvz_proto_rawDescOnce sync.Once
@@ -241,24 +922,45 @@ func file_pvz_proto_rawDescGZIP() []byte {
 }
 
 var file_pvz_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_pvz_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_pvz_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_pvz_proto_goTypes = []any{
-	(ReceptionStatus)(0),          // 0: pvz.v1.ReceptionStatus
-	(*PVZ)(nil),                   // 1: pvz.v1.PVZ
-	(*GetPVZListRequest)(nil),     // 2: pvz.v1.GetPVZListRequest
-	(*GetPVZListResponse)(nil),    // 3: pvz.v1.GetPVZListResponse
-	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+	(ReceptionStatus)(0),           // 0: pvz.v1.ReceptionStatus
+	(*PVZ)(nil),                    // 1: pvz.v1.PVZ
+	(*GetPVZListRequest)(nil),      // 2: pvz.v1.GetPVZListRequest
+	(*GetPVZListResponse)(nil),     // 3: pvz.v1.GetPVZListResponse
+	(*GetNearbyPVZRequest)(nil),    // 4: pvz.v1.GetNearbyPVZRequest
+	(*NearbyPVZ)(nil),              // 5: pvz.v1.NearbyPVZ
+	(*GetNearbyPVZResponse)(nil),   // 6: pvz.v1.GetNearbyPVZResponse
+	(*WatchReceptionsRequest)(nil), // 7: pvz.v1.WatchReceptionsRequest
+	(*ReceptionEvent)(nil),         // 8: pvz.v1.ReceptionEvent
+	(*ReceptionOpened)(nil),        // 9: pvz.v1.ReceptionOpened
+	(*ReceptionClosed)(nil),        // 10: pvz.v1.ReceptionClosed
+	(*ProductAdded)(nil),           // 11: pvz.v1.ProductAdded
+	(*ProductRemoved)(nil),         // 12: pvz.v1.ProductRemoved
+	(*timestamppb.Timestamp)(nil),  // 13: google.protobuf.Timestamp
 }
 var file_pvz_proto_depIdxs = []int32{
-	4, // 0: pvz.v1.PVZ.registration_date:type_name -> google.protobuf.Timestamp
-	1, // 1: pvz.v1.GetPVZListResponse.pvzs:type_name -> pvz.v1.PVZ
-	2, // 2: pvz.v1.PVZService.GetPVZList:input_type -> pvz.v1.GetPVZListRequest
-	3, // 3: pvz.v1.PVZService.GetPVZList:output_type -> pvz.v1.GetPVZListResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	13, // 0: pvz.v1.PVZ.registration_date:type_name -> google.protobuf.Timestamp
+	1,  // 1: pvz.v1.GetPVZListResponse.pvzs:type_name -> pvz.v1.PVZ
+	1,  // 2: pvz.v1.NearbyPVZ.pvz:type_name -> pvz.v1.PVZ
+	5,  // 3: pvz.v1.GetNearbyPVZResponse.pvzs:type_name -> pvz.v1.NearbyPVZ
+	13, // 4: pvz.v1.ReceptionEvent.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 5: pvz.v1.ReceptionEvent.reception_status:type_name -> pvz.v1.ReceptionStatus
+	9,  // 6: pvz.v1.ReceptionEvent.reception_opened:type_name -> pvz.v1.ReceptionOpened
+	10, // 7: pvz.v1.ReceptionEvent.reception_closed:type_name -> pvz.v1.ReceptionClosed
+	11, // 8: pvz.v1.ReceptionEvent.product_added:type_name -> pvz.v1.ProductAdded
+	12, // 9: pvz.v1.ReceptionEvent.product_removed:type_name -> pvz.v1.ProductRemoved
+	2,  // 10: pvz.v1.PVZService.GetPVZList:input_type -> pvz.v1.GetPVZListRequest
+	4,  // 11: pvz.v1.PVZService.GetNearbyPVZ:input_type -> pvz.v1.GetNearbyPVZRequest
+	7,  // 12: pvz.v1.PVZService.WatchReceptions:input_type -> pvz.v1.WatchReceptionsRequest
+	3,  // 13: pvz.v1.PVZService.GetPVZList:output_type -> pvz.v1.GetPVZListResponse
+	6,  // 14: pvz.v1.PVZService.GetNearbyPVZ:output_type -> pvz.v1.GetNearbyPVZResponse
+	8,  // 15: pvz.v1.PVZService.WatchReceptions:output_type -> pvz.v1.ReceptionEvent
+	13, // [13:16] is the sub-list for method output_type
+	10, // [10:13] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_pvz_proto_init() }
@@ -266,13 +968,20 @@ func file_pvz_proto_init() {
 	if File_pvz_proto != nil {
 		return
 	}
+	file_pvz_proto_msgTypes[2].OneofWrappers = []any{}
+	file_pvz_proto_msgTypes[7].OneofWrappers = []any{
+		(*ReceptionEvent_ReceptionOpened)(nil),
+		(*ReceptionEvent_ReceptionClosed)(nil),
+		(*ReceptionEvent_ProductAdded)(nil),
+		(*ReceptionEvent_ProductRemoved)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pvz_proto_rawDesc), len(file_pvz_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   3,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PVZService_GetPVZList_FullMethodName = "/pvz.v1.PVZService/GetPVZList"
+	PVZService_GetPVZList_FullMethodName      = "/pvz.v1.PVZService/GetPVZList"
+	PVZService_GetNearbyPVZ_FullMethodName    = "/pvz.v1.PVZService/GetNearbyPVZ"
+	PVZService_WatchReceptions_FullMethodName = "/pvz.v1.PVZService/WatchReceptions"
 )
 
 // PVZServiceClient is the client API for PVZService service.
@@ -27,6 +29,8 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type PVZServiceClient interface {
 	GetPVZList(ctx context.Context, in *GetPVZListRequest, opts ...grpc.CallOption) (*GetPVZListResponse, error)
+	GetNearbyPVZ(ctx context.Context, in *GetNearbyPVZRequest, opts ...grpc.CallOption) (*GetNearbyPVZResponse, error)
+	WatchReceptions(ctx context.Context, in *WatchReceptionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReceptionEvent], error)
 }
 
 type pVZServiceClient struct {
@@ -47,11 +51,42 @@ func (c *pVZServiceClient) GetPVZList(ctx context.Context, in *GetPVZListRequest
 	return out, nil
 }
 
+func (c *pVZServiceClient) GetNearbyPVZ(ctx context.Context, in *GetNearbyPVZRequest, opts ...grpc.CallOption) (*GetNearbyPVZResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNearbyPVZResponse)
+	err := c.cc.Invoke(ctx, PVZService_GetNearbyPVZ_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pVZServiceClient) WatchReceptions(ctx context.Context, in *WatchReceptionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReceptionEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PVZService_ServiceDesc.Streams[0], PVZService_WatchReceptions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchReceptionsRequest, ReceptionEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PVZService_WatchReceptionsClient = grpc.ServerStreamingClient[ReceptionEvent]
+
 // PVZServiceServer is the server API for PVZService service.
 // All implementations must embed UnimplementedPVZServiceServer
 // for forward compatibility.
 type PVZServiceServer interface {
 	GetPVZList(context.Context, *GetPVZListRequest) (*GetPVZListResponse, error)
+	GetNearbyPVZ(context.Context, *GetNearbyPVZRequest) (*GetNearbyPVZResponse, error)
+	WatchReceptions(*WatchReceptionsRequest, grpc.ServerStreamingServer[ReceptionEvent]) error
 	mustEmbedUnimplementedPVZServiceServer()
 }
 
@@ -65,6 +100,12 @@ type UnimplementedPVZServiceServer struct{}
 func (UnimplementedPVZServiceServer) GetPVZList(context.Context, *GetPVZListRequest) (*GetPVZListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPVZList not implemented")
 }
+func (UnimplementedPVZServiceServer) GetNearbyPVZ(context.Context, *GetNearbyPVZRequest) (*GetNearbyPVZResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNearbyPVZ not implemented")
+}
+func (UnimplementedPVZServiceServer) WatchReceptions(*WatchReceptionsRequest, grpc.ServerStreamingServer[ReceptionEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchReceptions not implemented")
+}
 func (UnimplementedPVZServiceServer) mustEmbedUnimplementedPVZServiceServer() {}
 func (UnimplementedPVZServiceServer) testEmbeddedByValue()                    {}
 
@@ -104,6 +145,35 @@ func _PVZService_GetPVZList_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PVZService_GetNearbyPVZ_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNearbyPVZRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PVZServiceServer).GetNearbyPVZ(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PVZService_GetNearbyPVZ_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PVZServiceServer).GetNearbyPVZ(ctx, req.(*GetNearbyPVZRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PVZService_WatchReceptions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchReceptionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PVZServiceServer).WatchReceptions(m, &grpc.GenericServerStream[WatchReceptionsRequest, ReceptionEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PVZService_WatchReceptionsServer = grpc.ServerStreamingServer[ReceptionEvent]
+
 // PVZService_ServiceDesc is the grpc.ServiceDesc for PVZService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +185,17 @@ var PVZService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPVZList",
 			Handler:    _PVZService_GetPVZList_Handler,
 		},
+		{
+			MethodName: "GetNearbyPVZ",
+			Handler:    _PVZService_GetNearbyPVZ_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchReceptions",
+			Handler:       _PVZService_WatchReceptions_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pvz.proto",
 }
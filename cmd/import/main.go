@@ -0,0 +1,142 @@
+// Команда import загружает ПВЗ, приёмки или товары из CSV или NDJSON напрямую в БД,
+// так же как POST /import/{dataset}:
+//
+//	go run ./cmd/import -dataset pvz -file pvz.csv -dry-run
+//	go run ./cmd/import -dataset receptions -file receptions.ndjson
+//
+// Код выхода 1 означает, что часть строк не загружена, 2 - что загрузка прервана.
+package main
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
+	"avito-backend-trainee-assignment-spring-2025/internal/services"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/logger"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	dataset := flag.String("dataset", "", "набор данных: pvz, receptions или products")
+	file := flag.String("file", "", "путь к файлу, - для стандартного ввода")
+	format := flag.String("format", "", "формат файла: csv или ndjson; по умолчанию по расширению файла")
+	dryRun := flag.Bool("dry-run", false, "только проверить файл, ничего не записывая")
+	chunkSize := flag.Int("chunk-size", 0, "сколько строк загружать в одной транзакции; по умолчанию IMPORT_CHUNK_SIZE")
+	flag.Parse()
+
+	if *dataset == "" || *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+	logger.Setup(cfg.Logger)
+
+	if *chunkSize > 0 {
+		cfg.Import.ChunkSize = *chunkSize
+	}
+
+	req := models.ImportRequest{
+		Dataset: *dataset,
+		Format:  *format,
+		DryRun:  *dryRun,
+	}
+	if req.Format == "" {
+		req.Format = formatFromFileName(*file)
+	}
+
+	input, closeInput, err := openInput(*file)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open import file")
+	}
+	defer closeInput()
+
+	db, err := postgres.New(&cfg.Postgres)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer db.Close()
+
+	auditService := services.NewAuditService(postgres.NewAuditRepository(db))
+	importService := services.NewImportService(
+		postgres.NewPVZRepository(db),
+		postgres.NewReceptionRepository(db),
+		postgres.NewProductRepository(db),
+		postgres.NewImportRepository(db),
+		auditService,
+		cfg.Import,
+		postgres.NewTxManager(db),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := importService.Import(ctx, req, input)
+	if err != nil {
+		log.Error().Err(err).Msg("Import aborted")
+		fmt.Fprintln(os.Stderr, "Import aborted; rows loaded before the error are kept, the file can be imported again")
+		os.Exit(2)
+	}
+
+	printReport(os.Stdout, report)
+	if report.Failed() > 0 {
+		os.Exit(1)
+	}
+}
+
+// formatFromFileName выбирает ndjson для файлов .ndjson и .jsonl, иначе csv.
+func formatFromFileName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".ndjson", ".jsonl":
+		return models.ImportFormatNDJSON
+	default:
+		return models.ImportFormatCSV
+	}
+}
+
+func openInput(name string) (io.Reader, func(), error) {
+	if name == "-" {
+		return os.Stdin, func() {}, nil
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, func() { _ = file.Close() }, nil
+}
+
+func printReport(w io.Writer, report *models.ImportReport) {
+	mode := "imported"
+	if report.DryRun {
+		mode = "would be imported (dry run)"
+	}
+
+	fmt.Fprintf(w, "%s: %d rows, %d %s, %d skipped, %d failed\n",
+		report.Dataset, report.Total, report.Created, mode, report.Skipped, report.Failed())
+
+	for _, rowErr := range report.Errors {
+		location := fmt.Sprintf("line %d", rowErr.Line)
+		if rowErr.ExternalID != "" {
+			location += fmt.Sprintf(" (%s)", rowErr.ExternalID)
+		}
+		if rowErr.Column != "" {
+			location += ", " + rowErr.Column
+		}
+		fmt.Fprintf(w, "  %s: %v\n", location, rowErr.Err)
+	}
+}
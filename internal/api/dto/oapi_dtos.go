@@ -10,14 +10,90 @@ import (
 )
 
 const (
+	ApiKeyAuthScopes = "apiKeyAuth.Scopes"
 	BearerAuthScopes = "bearerAuth.Scopes"
 )
 
+// Defines values for APIKeyScopes.
+const (
+	APIKeyScopesProductsWrite   APIKeyScopes = "products:write"
+	APIKeyScopesPvzRead         APIKeyScopes = "pvz:read"
+	APIKeyScopesReceptionsWrite APIKeyScopes = "receptions:write"
+)
+
+// Defines values for DayHoursDay.
+const (
+	Friday    DayHoursDay = "friday"
+	Monday    DayHoursDay = "monday"
+	Saturday  DayHoursDay = "saturday"
+	Sunday    DayHoursDay = "sunday"
+	Thursday  DayHoursDay = "thursday"
+	Tuesday   DayHoursDay = "tuesday"
+	Wednesday DayHoursDay = "wednesday"
+)
+
+// Defines values for ExportJobDataset.
+const (
+	ExportJobDatasetProducts   ExportJobDataset = "products"
+	ExportJobDatasetPvz        ExportJobDataset = "pvz"
+	ExportJobDatasetReceptions ExportJobDataset = "receptions"
+)
+
+// Defines values for ExportJobFormat.
+const (
+	ExportJobFormatCsv  ExportJobFormat = "csv"
+	ExportJobFormatXlsx ExportJobFormat = "xlsx"
+)
+
+// Defines values for ExportJobStatus.
+const (
+	Done    ExportJobStatus = "done"
+	Failed  ExportJobStatus = "failed"
+	Pending ExportJobStatus = "pending"
+	Running ExportJobStatus = "running"
+)
+
+// Defines values for ImportReportDataset.
+const (
+	ImportReportDatasetProducts   ImportReportDataset = "products"
+	ImportReportDatasetPvz        ImportReportDataset = "pvz"
+	ImportReportDatasetReceptions ImportReportDataset = "receptions"
+)
+
 // Defines values for PVZCity.
 const (
-	Казань         PVZCity = "Казань"
-	Москва         PVZCity = "Москва"
-	СанктПетербург PVZCity = "Санкт-Петербург"
+	PVZCityКазань         PVZCity = "Казань"
+	PVZCityМосква         PVZCity = "Москва"
+	PVZCityСанктПетербург PVZCity = "Санкт-Петербург"
+)
+
+// Defines values for PVZStatus.
+const (
+	PVZStatusActive            PVZStatus = "active"
+	PVZStatusDecommissioned    PVZStatus = "decommissioned"
+	PVZStatusTemporarilyClosed PVZStatus = "temporarily_closed"
+)
+
+// Defines values for PVZEventCity.
+const (
+	PVZEventCityКазань         PVZEventCity = "Казань"
+	PVZEventCityМосква         PVZEventCity = "Москва"
+	PVZEventCityСанктПетербург PVZEventCity = "Санкт-Петербург"
+)
+
+// Defines values for PVZEventProductType.
+const (
+	PVZEventProductTypeОбувь       PVZEventProductType = "обувь"
+	PVZEventProductTypeОдежда      PVZEventProductType = "одежда"
+	PVZEventProductTypeЭлектроника PVZEventProductType = "электроника"
+)
+
+// Defines values for PVZEventType.
+const (
+	ProductAdded    PVZEventType = "product.added"
+	ProductRemoved  PVZEventType = "product.removed"
+	ReceptionClosed PVZEventType = "reception.closed"
+	ReceptionOpened PVZEventType = "reception.opened"
 )
 
 // Defines values for ProductType.
@@ -29,8 +105,8 @@ const (
 
 // Defines values for ReceptionStatus.
 const (
-	Close      ReceptionStatus = "close"
-	InProgress ReceptionStatus = "in_progress"
+	ReceptionStatusClose      ReceptionStatus = "close"
+	ReceptionStatusInProgress ReceptionStatus = "in_progress"
 )
 
 // Defines values for UserRole.
@@ -39,12 +115,115 @@ const (
 	UserRoleModerator UserRole = "moderator"
 )
 
+// Defines values for PostAdminServiceAccountsServiceAccountIdKeysJSONBodyScopes.
+const (
+	PostAdminServiceAccountsServiceAccountIdKeysJSONBodyScopesProductsWrite   PostAdminServiceAccountsServiceAccountIdKeysJSONBodyScopes = "products:write"
+	PostAdminServiceAccountsServiceAccountIdKeysJSONBodyScopesPvzRead         PostAdminServiceAccountsServiceAccountIdKeysJSONBodyScopes = "pvz:read"
+	PostAdminServiceAccountsServiceAccountIdKeysJSONBodyScopesReceptionsWrite PostAdminServiceAccountsServiceAccountIdKeysJSONBodyScopes = "receptions:write"
+)
+
+// Defines values for GetAuditLogParamsEntityType.
+const (
+	GetAuditLogParamsEntityTypeApiKey         GetAuditLogParamsEntityType = "api_key"
+	GetAuditLogParamsEntityTypeProduct        GetAuditLogParamsEntityType = "product"
+	GetAuditLogParamsEntityTypePvz            GetAuditLogParamsEntityType = "pvz"
+	GetAuditLogParamsEntityTypeReception      GetAuditLogParamsEntityType = "reception"
+	GetAuditLogParamsEntityTypeServiceAccount GetAuditLogParamsEntityType = "service_account"
+	GetAuditLogParamsEntityTypeUser           GetAuditLogParamsEntityType = "user"
+)
+
 // Defines values for PostDummyLoginJSONBodyRole.
 const (
 	PostDummyLoginJSONBodyRoleEmployee  PostDummyLoginJSONBodyRole = "employee"
 	PostDummyLoginJSONBodyRoleModerator PostDummyLoginJSONBodyRole = "moderator"
 )
 
+// Defines values for GetEventsStreamParamsCity.
+const (
+	GetEventsStreamParamsCityКазань         GetEventsStreamParamsCity = "Казань"
+	GetEventsStreamParamsCityМосква         GetEventsStreamParamsCity = "Москва"
+	GetEventsStreamParamsCityСанктПетербург GetEventsStreamParamsCity = "Санкт-Петербург"
+)
+
+// Defines values for GetExportDatasetParamsFormat.
+const (
+	GetExportDatasetParamsFormatCsv  GetExportDatasetParamsFormat = "csv"
+	GetExportDatasetParamsFormatXlsx GetExportDatasetParamsFormat = "xlsx"
+)
+
+// Defines values for GetExportDatasetParamsCity.
+const (
+	GetExportDatasetParamsCityКазань         GetExportDatasetParamsCity = "Казань"
+	GetExportDatasetParamsCityМосква         GetExportDatasetParamsCity = "Москва"
+	GetExportDatasetParamsCityСанктПетербург GetExportDatasetParamsCity = "Санкт-Петербург"
+)
+
+// Defines values for GetExportDatasetParamsReceptionStatus.
+const (
+	GetExportDatasetParamsReceptionStatusClose      GetExportDatasetParamsReceptionStatus = "close"
+	GetExportDatasetParamsReceptionStatusInProgress GetExportDatasetParamsReceptionStatus = "in_progress"
+)
+
+// Defines values for GetExportDatasetParamsProductType.
+const (
+	GetExportDatasetParamsProductTypeОбувь       GetExportDatasetParamsProductType = "обувь"
+	GetExportDatasetParamsProductTypeОдежда      GetExportDatasetParamsProductType = "одежда"
+	GetExportDatasetParamsProductTypeЭлектроника GetExportDatasetParamsProductType = "электроника"
+)
+
+// Defines values for GetExportDatasetParamsDataset.
+const (
+	GetExportDatasetParamsDatasetProducts   GetExportDatasetParamsDataset = "products"
+	GetExportDatasetParamsDatasetPvz        GetExportDatasetParamsDataset = "pvz"
+	GetExportDatasetParamsDatasetReceptions GetExportDatasetParamsDataset = "receptions"
+)
+
+// Defines values for PostExportDatasetJobsParamsFormat.
+const (
+	PostExportDatasetJobsParamsFormatCsv  PostExportDatasetJobsParamsFormat = "csv"
+	PostExportDatasetJobsParamsFormatXlsx PostExportDatasetJobsParamsFormat = "xlsx"
+)
+
+// Defines values for PostExportDatasetJobsParamsCity.
+const (
+	PostExportDatasetJobsParamsCityКазань         PostExportDatasetJobsParamsCity = "Казань"
+	PostExportDatasetJobsParamsCityМосква         PostExportDatasetJobsParamsCity = "Москва"
+	PostExportDatasetJobsParamsCityСанктПетербург PostExportDatasetJobsParamsCity = "Санкт-Петербург"
+)
+
+// Defines values for PostExportDatasetJobsParamsReceptionStatus.
+const (
+	PostExportDatasetJobsParamsReceptionStatusClose      PostExportDatasetJobsParamsReceptionStatus = "close"
+	PostExportDatasetJobsParamsReceptionStatusInProgress PostExportDatasetJobsParamsReceptionStatus = "in_progress"
+)
+
+// Defines values for PostExportDatasetJobsParamsProductType.
+const (
+	PostExportDatasetJobsParamsProductTypeОбувь       PostExportDatasetJobsParamsProductType = "обувь"
+	PostExportDatasetJobsParamsProductTypeОдежда      PostExportDatasetJobsParamsProductType = "одежда"
+	PostExportDatasetJobsParamsProductTypeЭлектроника PostExportDatasetJobsParamsProductType = "электроника"
+)
+
+// Defines values for PostExportDatasetJobsParamsDataset.
+const (
+	PostExportDatasetJobsParamsDatasetProducts   PostExportDatasetJobsParamsDataset = "products"
+	PostExportDatasetJobsParamsDatasetPvz        PostExportDatasetJobsParamsDataset = "pvz"
+	PostExportDatasetJobsParamsDatasetReceptions PostExportDatasetJobsParamsDataset = "receptions"
+)
+
+// Defines values for PostImportDatasetParamsFormat.
+const (
+	PostImportDatasetParamsFormatCsv    PostImportDatasetParamsFormat = "csv"
+	PostImportDatasetParamsFormatNdjson PostImportDatasetParamsFormat = "ndjson"
+)
+
+// Defines values for PostImportDatasetParamsDataset.
+const (
+	PostImportDatasetParamsDatasetProducts   PostImportDatasetParamsDataset = "products"
+	PostImportDatasetParamsDatasetPvz        PostImportDatasetParamsDataset = "pvz"
+	PostImportDatasetParamsDatasetReceptions PostImportDatasetParamsDataset = "receptions"
+)
+
 // Defines values for PostProductsJSONBodyType.
 const (
 	PostProductsJSONBodyTypeОбувь       PostProductsJSONBodyType = "обувь"
@@ -52,33 +231,371 @@ const (
 	PostProductsJSONBodyTypeЭлектроника PostProductsJSONBodyType = "электроника"
 )
 
+// Defines values for GetPvzParamsCity.
+const (
+	GetPvzParamsCityКазань         GetPvzParamsCity = "Казань"
+	GetPvzParamsCityМосква         GetPvzParamsCity = "Москва"
+	GetPvzParamsCityСанктПетербург GetPvzParamsCity = "Санкт-Петербург"
+)
+
+// Defines values for GetPvzParamsReceptionStatus.
+const (
+	GetPvzParamsReceptionStatusClose      GetPvzParamsReceptionStatus = "close"
+	GetPvzParamsReceptionStatusInProgress GetPvzParamsReceptionStatus = "in_progress"
+)
+
+// Defines values for GetPvzParamsProductType.
+const (
+	GetPvzParamsProductTypeОбувь       GetPvzParamsProductType = "обувь"
+	GetPvzParamsProductTypeОдежда      GetPvzParamsProductType = "одежда"
+	GetPvzParamsProductTypeЭлектроника GetPvzParamsProductType = "электроника"
+)
+
+// Defines values for GetPvzParamsSortBy.
+const (
+	GetPvzParamsSortByCity             GetPvzParamsSortBy = "city"
+	GetPvzParamsSortByLastReception    GetPvzParamsSortBy = "lastReception"
+	GetPvzParamsSortByRegistrationDate GetPvzParamsSortBy = "registrationDate"
+)
+
+// Defines values for GetPvzParamsSortOrder.
+const (
+	Asc  GetPvzParamsSortOrder = "asc"
+	Desc GetPvzParamsSortOrder = "desc"
+)
+
+// Defines values for PatchPvzPvzIdJSONBodyStatus.
+const (
+	PatchPvzPvzIdJSONBodyStatusActive            PatchPvzPvzIdJSONBodyStatus = "active"
+	PatchPvzPvzIdJSONBodyStatusDecommissioned    PatchPvzPvzIdJSONBodyStatus = "decommissioned"
+	PatchPvzPvzIdJSONBodyStatusTemporarilyClosed PatchPvzPvzIdJSONBodyStatus = "temporarily_closed"
+)
+
+// Defines values for GetPvzPvzIdReceptionsParamsStatus.
+const (
+	Close      GetPvzPvzIdReceptionsParamsStatus = "close"
+	InProgress GetPvzPvzIdReceptionsParamsStatus = "in_progress"
+)
+
 // Defines values for PostRegisterJSONBodyRole.
 const (
 	Employee  PostRegisterJSONBodyRole = "employee"
 	Moderator PostRegisterJSONBodyRole = "moderator"
 )
 
-// Error defines model for Error.
-type Error struct {
+// Defines values for GetReportsReceptionsParamsGroupBy.
+const (
+	GetReportsReceptionsParamsGroupByCity        GetReportsReceptionsParamsGroupBy = "city"
+	GetReportsReceptionsParamsGroupByProductType GetReportsReceptionsParamsGroupBy = "productType"
+	GetReportsReceptionsParamsGroupByPvz         GetReportsReceptionsParamsGroupBy = "pvz"
+)
+
+// Defines values for GetReportsReceptionsParamsPeriod.
+const (
+	Day   GetReportsReceptionsParamsPeriod = "day"
+	Month GetReportsReceptionsParamsPeriod = "month"
+	Week  GetReportsReceptionsParamsPeriod = "week"
+)
+
+// Defines values for GetReportsReceptionsParamsCity.
+const (
+	Казань         GetReportsReceptionsParamsCity = "Казань"
+	Москва         GetReportsReceptionsParamsCity = "Москва"
+	СанктПетербург GetReportsReceptionsParamsCity = "Санкт-Петербург"
+)
+
+// Defines values for GetReportsReceptionsParamsProductType.
+const (
+	Обувь       GetReportsReceptionsParamsProductType = "обувь"
+	Одежда      GetReportsReceptionsParamsProductType = "одежда"
+	Электроника GetReportsReceptionsParamsProductType = "электроника"
+)
+
+// APIKey defines model for APIKey.
+type APIKey struct {
+	CreatedAt        time.Time            `json:"createdAt"`
+	ExpiresAt        *time.Time           `json:"expiresAt,omitempty"`
+	Id               openapi_types.UUID   `json:"id"`
+	LastUsedAt       *time.Time           `json:"lastUsedAt,omitempty"`
+	Prefix           string               `json:"prefix"`
+	PvzIds           []openapi_types.UUID `json:"pvzIds"`
+	RevokedAt        *time.Time           `json:"revokedAt,omitempty"`
+	Scopes           []APIKeyScopes       `json:"scopes"`
+	ServiceAccountId openapi_types.UUID   `json:"serviceAccountId"`
+}
+
+// APIKeyScopes defines model for APIKey.Scopes.
+type APIKeyScopes string
+
+// AuditEntry defines model for AuditEntry.
+type AuditEntry struct {
+	Action     string                  `json:"action"`
+	ActorId    openapi_types.UUID      `json:"actorId"`
+	ActorRole  string                  `json:"actorRole"`
+	After      *map[string]interface{} `json:"after"`
+	Before     *map[string]interface{} `json:"before"`
+	ClientIp   string                  `json:"clientIp"`
+	CreatedAt  time.Time               `json:"createdAt"`
+	EntityId   string                  `json:"entityId"`
+	EntityType string                  `json:"entityType"`
+	Hash       string                  `json:"hash"`
+	Id         int64                   `json:"id"`
+	PrevHash   string                  `json:"prevHash"`
+	RequestId  string                  `json:"requestId"`
+}
+
+// AuditLogPage defines model for AuditLogPage.
+type AuditLogPage struct {
+	Items      []AuditEntry `json:"items"`
+	Limit      int          `json:"limit"`
+	Page       int          `json:"page"`
+	TotalCount int          `json:"totalCount"`
+}
+
+// AuditVerification defines model for AuditVerification.
+type AuditVerification struct {
+	// BrokenAtId ID первой записи, на которой нарушена цепочка хешей
+	BrokenAtId   *int64 `json:"brokenAtId,omitempty"`
+	CheckedCount int    `json:"checkedCount"`
+	Valid        bool   `json:"valid"`
+}
+
+// CapacityUsage defines model for CapacityUsage.
+type CapacityUsage struct {
+	Capacity int `json:"capacity"`
+	Occupied int `json:"occupied"`
+
+	// Scope total для общей вместимости или тип товара
+	Scope string `json:"scope"`
+
+	// Utilization Доля занятой вместимости, больше 1 при переполнении
+	Utilization float64 `json:"utilization"`
+}
+
+// DayHours Часы работы в день недели. Закрытие раньше открытия - работа через полночь.
+type DayHours struct {
+	Close string      `json:"close"`
+	Day   DayHoursDay `json:"day"`
+	Open  string      `json:"open"`
+}
+
+// DayHoursDay defines model for DayHours.Day.
+type DayHoursDay string
+
+// ExportJob defines model for ExportJob.
+type ExportJob struct {
+	CreatedAt time.Time        `json:"createdAt"`
+	Dataset   ExportJobDataset `json:"dataset"`
+
+	// Error Причина неудачи, если status = failed
+	Error *string `json:"error,omitempty"`
+
+	// ExpiresAt До этого момента файл выгрузки можно скачать
+	ExpiresAt  *time.Time         `json:"expiresAt,omitempty"`
+	FinishedAt *time.Time         `json:"finishedAt,omitempty"`
+	Format     ExportJobFormat    `json:"format"`
+	Id         openapi_types.UUID `json:"id"`
+
+	// RowCount Количество выгруженных строк без заголовка
+	RowCount int             `json:"rowCount"`
+	Status   ExportJobStatus `json:"status"`
+}
+
+// ExportJobDataset defines model for ExportJob.Dataset.
+type ExportJobDataset string
+
+// ExportJobFormat defines model for ExportJob.Format.
+type ExportJobFormat string
+
+// ExportJobStatus defines model for ExportJob.Status.
+type ExportJobStatus string
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Code Код ошибки поля - required, invalid_value, invalid_format, invalid_type, out_of_range или invalid
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+
+	// Field Поле тела или параметр запроса
+	Field string `json:"field"`
+}
+
+// HoursException Часы работы в конкретную дату, заменяют недельное расписание
+type HoursException struct {
+	Close  *string `json:"close,omitempty"`
+	Closed bool    `json:"closed"`
+	Date   string  `json:"date"`
+	Open   *string `json:"open,omitempty"`
+}
+
+// ImportReport defines model for ImportReport.
+type ImportReport struct {
+	// Created Сколько строк загружено (при dryRun - было бы загружено)
+	Created int                 `json:"created"`
+	Dataset ImportReportDataset `json:"dataset"`
+	DryRun  bool                `json:"dryRun"`
+	Errors  []ImportRowError    `json:"errors"`
+	Failed  int                 `json:"failed"`
+
+	// Skipped Сколько строк пропущено, потому что их externalId уже загружен
+	Skipped int `json:"skipped"`
+
+	// Total Количество строк в файле без заголовка
+	Total int `json:"total"`
+}
+
+// ImportReportDataset defines model for ImportReport.Dataset.
+type ImportReportDataset string
+
+// ImportRowError defines model for ImportRowError.
+type ImportRowError struct {
+	// Code Стабильный код ошибки, как в Problem
+	Code string `json:"code"`
+
+	// Column Столбец с ошибкой, если ошибка относится к одному столбцу
+	Column     *string `json:"column,omitempty"`
+	ExternalId *string `json:"externalId,omitempty"`
+
+	// Line Номер строки в файле (у CSV первая строка - заголовок)
+	Line    int    `json:"line"`
 	Message string `json:"message"`
 }
 
+// IssuedAPIKey defines model for IssuedAPIKey.
+type IssuedAPIKey struct {
+	ApiKey APIKey `json:"apiKey"`
+	Key    string `json:"key"`
+}
+
+// MFAChallenge defines model for MFAChallenge.
+type MFAChallenge struct {
+	ChallengeToken string    `json:"challengeToken"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	MfaRequired    bool      `json:"mfaRequired"`
+}
+
 // PVZ defines model for PVZ.
 type PVZ struct {
+	Address *string `json:"address,omitempty"`
+
+	// Capacity Вместимость ПВЗ в товарах. 0 или отсутствие значения означает, что ограничения нет.
+	Capacity         *PVZCapacity        `json:"capacity,omitempty"`
 	City             PVZCity             `binding:"required,oneof=Москва Санкт-Петербург Казань" json:"city"`
 	Id               *openapi_types.UUID `json:"id"`
+	Latitude         *float64            `json:"latitude,omitempty"`
+	Longitude        *float64            `json:"longitude,omitempty"`
+	Phone            *string             `json:"phone,omitempty"`
 	RegistrationDate *time.Time          `json:"registrationDate"`
+
+	// Status Заполняется сервером, при создании ПВЗ всегда active
+	Status *PVZStatus `json:"status,omitempty"`
+
+	// WorkingHours Недельное расписание и исключения на даты. Дни, которых нет в weekly, выходные.
+	WorkingHours *WorkingHours `json:"workingHours,omitempty"`
 }
 
 // PVZCity defines model for PVZ.City.
 type PVZCity string
 
+// PVZStatus Заполняется сервером, при создании ПВЗ всегда active
+type PVZStatus string
+
+// PVZCapacity Вместимость ПВЗ в товарах. 0 или отсутствие значения означает, что ограничения нет.
+type PVZCapacity struct {
+	// ByType Вместимость по типам товаров (электроника, одежда, обувь)
+	ByType *map[string]int `json:"byType,omitempty"`
+	Total  *int            `binding:"omitempty,min=0" json:"total,omitempty"`
+}
+
+// PVZEvent Событие ленты изменений ПВЗ. В SSE передается в поле data, а id и тип - в полях id и event
+type PVZEvent struct {
+	City      PVZEventCity `json:"city"`
+	CreatedAt time.Time    `json:"createdAt"`
+
+	// Id Номер события; возрастает, по нему лента продолжается через Last-Event-ID
+	Id int64 `json:"id"`
+
+	// ProductId Только у событий товаров
+	ProductId *openapi_types.UUID `json:"productId,omitempty"`
+
+	// ProductType Только у событий товаров
+	ProductType *PVZEventProductType `json:"productType,omitempty"`
+	PvzId       openapi_types.UUID   `json:"pvzId"`
+	ReceptionId openapi_types.UUID   `json:"receptionId"`
+	Type        PVZEventType         `json:"type"`
+}
+
+// PVZEventCity defines model for PVZEvent.City.
+type PVZEventCity string
+
+// PVZEventProductType Только у событий товаров
+type PVZEventProductType string
+
+// PVZEventType defines model for PVZEvent.Type.
+type PVZEventType string
+
+// PVZNearby defines model for PVZNearby.
+type PVZNearby struct {
+	// DistanceMeters Расстояние от точки поиска в метрах
+	DistanceMeters float64 `json:"distanceMeters"`
+	Pvz            PVZ     `json:"pvz"`
+}
+
+// PVZNearbyList defines model for PVZNearbyList.
+type PVZNearbyList struct {
+	Items []PVZNearby `json:"items"`
+}
+
+// PVZUtilization defines model for PVZUtilization.
+type PVZUtilization struct {
+	// Occupied Количество товаров в ПВЗ
+	Occupied int             `json:"occupied"`
+	Pvz      PVZ             `json:"pvz"`
+	Usage    []CapacityUsage `json:"usage"`
+
+	// Utilization Наибольшая заполненность среди ограничений ПВЗ
+	Utilization float64 `json:"utilization"`
+}
+
+// PVZUtilizationReport defines model for PVZUtilizationReport.
+type PVZUtilizationReport struct {
+	Items     []PVZUtilization `json:"items"`
+	Threshold float64          `json:"threshold"`
+}
+
+// Problem Описание ошибки в формате RFC 7807 (application/problem+json)
+type Problem struct {
+	// Code Стабильный машиночитаемый код ошибки, например pvz_not_found
+	Code string `json:"code"`
+
+	// Detail Понятное пользователю описание ошибки на языке из настройки пользователя
+	// (PUT /me/language) или заголовка Accept-Language (ru, en; по умолчанию en).
+	// Язык ответа указан в заголовке Content-Language
+	Detail string `json:"detail"`
+
+	// Errors Ошибки отдельных полей запроса
+	Errors *[]FieldError `json:"errors,omitempty"`
+
+	// Instance Идентификатор запроса (X-Request-ID)
+	Instance *string `json:"instance,omitempty"`
+	Status   int     `json:"status"`
+
+	// Title Текст HTTP-статуса
+	Title string `json:"title"`
+
+	// Type Всегда about:blank, вид ошибки определяет code
+	Type string `json:"type"`
+}
+
 // Product defines model for Product.
 type Product struct {
-	DateTime    *time.Time          `json:"dateTime"`
-	Id          *openapi_types.UUID `json:"id"`
-	ReceptionId openapi_types.UUID  `binding:"required,uuid4" json:"receptionId"`
-	Type        ProductType         `binding:"required,oneof=электроника одежда обувь" json:"type"`
+	// CapacityWarnings Только в ответе на добавление товара: ограничения вместимости ПВЗ, заполненные на
+	// долю порога или больше
+	CapacityWarnings *[]CapacityUsage    `json:"capacityWarnings,omitempty"`
+	DateTime         *time.Time          `json:"dateTime"`
+	Id               *openapi_types.UUID `json:"id"`
+	ReceptionId      openapi_types.UUID  `binding:"required,uuid4" json:"receptionId"`
+	Type             ProductType         `binding:"required,oneof=электроника одежда обувь" json:"type"`
 }
 
 // ProductType defines model for Product.Type.
@@ -95,6 +612,73 @@ type Reception struct {
 // ReceptionStatus defines model for Reception.Status.
 type ReceptionStatus string
 
+// ReceptionPage defines model for ReceptionPage.
+type ReceptionPage struct {
+	Items      []ReceptionSummary `json:"items"`
+	Limit      int                `json:"limit"`
+	Page       int                `json:"page"`
+	TotalCount int                `json:"totalCount"`
+}
+
+// ReceptionReport defines model for ReceptionReport.
+type ReceptionReport struct {
+	Items []ReceptionReportRow `json:"items"`
+}
+
+// ReceptionReportRow Строка отчета. Поля группировок заполнены, только если группировка выбрана
+type ReceptionReportRow struct {
+	// AvgDurationSeconds Средняя длительность закрытых приемок в секундах
+	AvgDurationSeconds *float64 `json:"avgDurationSeconds,omitempty"`
+	City               *string  `json:"city,omitempty"`
+
+	// PeriodStart Начало периода
+	PeriodStart *time.Time `json:"periodStart,omitempty"`
+	ProductType *string    `json:"productType,omitempty"`
+
+	// Products Количество товаров
+	Products int `json:"products"`
+
+	// ProductsPerReception Среднее количество товаров в приемке
+	ProductsPerReception float64             `json:"productsPerReception"`
+	PvzId                *openapi_types.UUID `json:"pvzId,omitempty"`
+
+	// Receptions Количество приемок
+	Receptions int `json:"receptions"`
+}
+
+// ReceptionSummary defines model for ReceptionSummary.
+type ReceptionSummary struct {
+	// ProductCounts Количество товаров приемки по типам
+	ProductCounts map[string]int `json:"productCounts"`
+	ProductsTotal int            `json:"productsTotal"`
+	Reception     Reception      `json:"reception"`
+}
+
+// ReceptionWithProducts defines model for ReceptionWithProducts.
+type ReceptionWithProducts struct {
+	Products  []Product `json:"products"`
+	Reception Reception `json:"reception"`
+}
+
+// RecoveryCodes defines model for RecoveryCodes.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// ServiceAccount defines model for ServiceAccount.
+type ServiceAccount struct {
+	CreatedAt   *time.Time          `json:"createdAt"`
+	Description *string             `json:"description"`
+	Id          *openapi_types.UUID `json:"id"`
+	Name        string              `binding:"required" json:"name"`
+}
+
+// TOTPEnrollment defines model for TOTPEnrollment.
+type TOTPEnrollment struct {
+	ProvisioningUri string `json:"provisioningUri"`
+	Secret          string `json:"secret"`
+}
+
 // Token defines model for Token.
 type Token = string
 
@@ -108,6 +692,88 @@ type User struct {
 // UserRole defines model for User.Role.
 type UserRole string
 
+// WorkingHours Недельное расписание и исключения на даты. Дни, которых нет в weekly, выходные.
+type WorkingHours struct {
+	Exceptions *[]HoursException `json:"exceptions,omitempty"`
+
+	// Timezone Часовой пояс расписания из базы IANA, по умолчанию Europe/Moscow
+	Timezone *string    `json:"timezone,omitempty"`
+	Weekly   []DayHours `json:"weekly"`
+}
+
+// IdempotencyKey defines model for IdempotencyKey.
+type IdempotencyKey = string
+
+// IfMatch defines model for IfMatch.
+type IfMatch = string
+
+// IfNoneMatch defines model for IfNoneMatch.
+type IfNoneMatch = string
+
+// IdempotencyKeyInUse Описание ошибки в формате RFC 7807 (application/problem+json)
+type IdempotencyKeyInUse = Problem
+
+// IdempotencyKeyReused Описание ошибки в формате RFC 7807 (application/problem+json)
+type IdempotencyKeyReused = Problem
+
+// PreconditionFailed Описание ошибки в формате RFC 7807 (application/problem+json)
+type PreconditionFailed = Problem
+
+// PreconditionRequired Описание ошибки в формате RFC 7807 (application/problem+json)
+type PreconditionRequired = Problem
+
+// DeleteAdminApiKeysKeyIdParams defines parameters for DeleteAdminApiKeysKeyId.
+type DeleteAdminApiKeysKeyIdParams struct {
+	// IdempotencyKey Ключ идемпотентности, например UUID. Повтор запроса с тем же ключом и телом в течение
+	// IDEMPOTENCY_TTL не выполняется заново, а получает сохраненный ответ с заголовком
+	// Idempotent-Replayed: true. Ошибки сервера не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostAdminServiceAccountsJSONBody defines parameters for PostAdminServiceAccounts.
+type PostAdminServiceAccountsJSONBody struct {
+	Description *string `json:"description"`
+	Name        string  `binding:"required" json:"name"`
+}
+
+// PostAdminServiceAccountsParams defines parameters for PostAdminServiceAccounts.
+type PostAdminServiceAccountsParams struct {
+	// IdempotencyKey Ключ идемпотентности, например UUID. Повтор запроса с тем же ключом и телом в течение
+	// IDEMPOTENCY_TTL не выполняется заново, а получает сохраненный ответ с заголовком
+	// Idempotent-Replayed: true. Ошибки сервера не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostAdminServiceAccountsServiceAccountIdKeysJSONBody defines parameters for PostAdminServiceAccountsServiceAccountIdKeys.
+type PostAdminServiceAccountsServiceAccountIdKeysJSONBody struct {
+	ExpiresAt *time.Time `json:"expiresAt"`
+
+	// PvzIds ПВЗ, с которыми может работать ключ. Пустой список - все ПВЗ
+	PvzIds *[]openapi_types.UUID                                        `json:"pvzIds"`
+	Scopes []PostAdminServiceAccountsServiceAccountIdKeysJSONBodyScopes `binding:"required,min=1" json:"scopes"`
+}
+
+// PostAdminServiceAccountsServiceAccountIdKeysJSONBodyScopes defines parameters for PostAdminServiceAccountsServiceAccountIdKeys.
+type PostAdminServiceAccountsServiceAccountIdKeysJSONBodyScopes string
+
+// GetAuditLogParams defines parameters for GetAuditLog.
+type GetAuditLogParams struct {
+	// ActorId UUID пользователя или сервисной учетной записи
+	ActorId *string `binding:"omitempty,uuid" form:"actorId" json:"actorId,omitempty"`
+
+	// Action Действие, например reception.close
+	Action     *string                      `form:"action" json:"action,omitempty"`
+	EntityType *GetAuditLogParamsEntityType `binding:"omitempty,oneof=pvz reception product user service_account api_key" form:"entityType" json:"entityType,omitempty"`
+	EntityId   *string                      `form:"entityId" json:"entityId,omitempty"`
+	StartDate  *time.Time                   `form:"startDate" json:"startDate,omitempty"`
+	EndDate    *time.Time                   `binding:"omitempty,gtfield=StartDate" form:"endDate" json:"endDate,omitempty"`
+	Page       *int                         `binding:"omitempty,min=1" form:"page" json:"page,omitempty"`
+	Limit      *int                         `binding:"omitempty,min=1,max=100" form:"limit" json:"limit,omitempty"`
+}
+
+// GetAuditLogParamsEntityType defines parameters for GetAuditLog.
+type GetAuditLogParamsEntityType string
+
 // PostDummyLoginJSONBody defines parameters for PostDummyLogin.
 type PostDummyLoginJSONBody struct {
 	Role PostDummyLoginJSONBodyRole `binding:"required,oneof=employee moderator" json:"role"`
@@ -116,34 +782,364 @@ type PostDummyLoginJSONBody struct {
 // PostDummyLoginJSONBodyRole defines parameters for PostDummyLogin.
 type PostDummyLoginJSONBodyRole string
 
+// GetEventsStreamParams defines parameters for GetEventsStream.
+type GetEventsStreamParams struct {
+	// PvzId Только события этих ПВЗ (UUID), можно указать несколько раз
+	PvzId *[]string `binding:"omitempty,dive,uuid" form:"pvzId" json:"pvzId,omitempty"`
+
+	// City Только события ПВЗ этих городов, можно указать несколько раз
+	City *[]GetEventsStreamParamsCity `binding:"omitempty,dive,oneof=Москва Санкт-Петербург Казань" form:"city" json:"city,omitempty"`
+
+	// LastEventId ID последнего полученного события, если клиент не может передать заголовок Last-Event-ID
+	LastEventId *int64 `binding:"omitempty,min=0" form:"lastEventId" json:"lastEventId,omitempty"`
+
+	// LastEventID ID последнего полученного события
+	LastEventID *string `json:"Last-Event-ID,omitempty"`
+}
+
+// GetEventsStreamParamsCity defines parameters for GetEventsStream.
+type GetEventsStreamParamsCity string
+
+// GetExportDatasetParams defines parameters for GetExportDataset.
+type GetExportDatasetParams struct {
+	// Format Формат файла, по умолчанию csv
+	Format *GetExportDatasetParamsFormat `binding:"omitempty,oneof=csv xlsx" form:"format" json:"format,omitempty"`
+
+	// StartDate Начальная дата диапазона приемок
+	StartDate *time.Time `form:"startDate" json:"startDate,omitempty"`
+
+	// EndDate Конечная дата диапазона приемок
+	EndDate *time.Time `binding:"omitempty,gtfield=StartDate" form:"endDate" json:"endDate,omitempty"`
+
+	// City Город ПВЗ, можно указать несколько раз
+	City *[]GetExportDatasetParamsCity `binding:"omitempty,dive,oneof=Москва Санкт-Петербург Казань" form:"city" json:"city,omitempty"`
+
+	// RegisteredFrom Начало диапазона дат регистрации ПВЗ
+	RegisteredFrom *time.Time `form:"registeredFrom" json:"registeredFrom,omitempty"`
+
+	// RegisteredTo Конец диапазона дат регистрации ПВЗ
+	RegisteredTo *time.Time `binding:"omitempty,gtfield=RegisteredFrom" form:"registeredTo" json:"registeredTo,omitempty"`
+
+	// ReceptionStatus Только приемки в этом статусе и их ПВЗ и товары
+	ReceptionStatus *GetExportDatasetParamsReceptionStatus `binding:"omitempty,oneof=in_progress close" form:"receptionStatus" json:"receptionStatus,omitempty"`
+
+	// ProductType Только приемки с товаром этого типа, для товаров - только товары этого типа
+	ProductType *GetExportDatasetParamsProductType `binding:"omitempty,oneof=электроника одежда обувь" form:"productType" json:"productType,omitempty"`
+
+	// HasOpenReception Только ПВЗ с открытой приемкой (true) или без нее (false)
+	HasOpenReception *bool `form:"hasOpenReception" json:"hasOpenReception,omitempty"`
+}
+
+// GetExportDatasetParamsFormat defines parameters for GetExportDataset.
+type GetExportDatasetParamsFormat string
+
+// GetExportDatasetParamsCity defines parameters for GetExportDataset.
+type GetExportDatasetParamsCity string
+
+// GetExportDatasetParamsReceptionStatus defines parameters for GetExportDataset.
+type GetExportDatasetParamsReceptionStatus string
+
+// GetExportDatasetParamsProductType defines parameters for GetExportDataset.
+type GetExportDatasetParamsProductType string
+
+// GetExportDatasetParamsDataset defines parameters for GetExportDataset.
+type GetExportDatasetParamsDataset string
+
+// PostExportDatasetJobsParams defines parameters for PostExportDatasetJobs.
+type PostExportDatasetJobsParams struct {
+	// Format Формат файла, по умолчанию csv
+	Format *PostExportDatasetJobsParamsFormat `binding:"omitempty,oneof=csv xlsx" form:"format" json:"format,omitempty"`
+
+	// StartDate Начальная дата диапазона приемок
+	StartDate *time.Time `form:"startDate" json:"startDate,omitempty"`
+
+	// EndDate Конечная дата диапазона приемок
+	EndDate *time.Time `binding:"omitempty,gtfield=StartDate" form:"endDate" json:"endDate,omitempty"`
+
+	// City Город ПВЗ, можно указать несколько раз
+	City *[]PostExportDatasetJobsParamsCity `binding:"omitempty,dive,oneof=Москва Санкт-Петербург Казань" form:"city" json:"city,omitempty"`
+
+	// RegisteredFrom Начало диапазона дат регистрации ПВЗ
+	RegisteredFrom *time.Time `form:"registeredFrom" json:"registeredFrom,omitempty"`
+
+	// RegisteredTo Конец диапазона дат регистрации ПВЗ
+	RegisteredTo *time.Time `binding:"omitempty,gtfield=RegisteredFrom" form:"registeredTo" json:"registeredTo,omitempty"`
+
+	// ReceptionStatus Только приемки в этом статусе и их ПВЗ и товары
+	ReceptionStatus *PostExportDatasetJobsParamsReceptionStatus `binding:"omitempty,oneof=in_progress close" form:"receptionStatus" json:"receptionStatus,omitempty"`
+
+	// ProductType Только приемки с товаром этого типа, для товаров - только товары этого типа
+	ProductType *PostExportDatasetJobsParamsProductType `binding:"omitempty,oneof=электроника одежда обувь" form:"productType" json:"productType,omitempty"`
+
+	// HasOpenReception Только ПВЗ с открытой приемкой (true) или без нее (false)
+	HasOpenReception *bool `form:"hasOpenReception" json:"hasOpenReception,omitempty"`
+
+	// IdempotencyKey Ключ идемпотентности, например UUID. Повтор запроса с тем же ключом и телом в течение
+	// IDEMPOTENCY_TTL не выполняется заново, а получает сохраненный ответ с заголовком
+	// Idempotent-Replayed: true. Ошибки сервера не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostExportDatasetJobsParamsFormat defines parameters for PostExportDatasetJobs.
+type PostExportDatasetJobsParamsFormat string
+
+// PostExportDatasetJobsParamsCity defines parameters for PostExportDatasetJobs.
+type PostExportDatasetJobsParamsCity string
+
+// PostExportDatasetJobsParamsReceptionStatus defines parameters for PostExportDatasetJobs.
+type PostExportDatasetJobsParamsReceptionStatus string
+
+// PostExportDatasetJobsParamsProductType defines parameters for PostExportDatasetJobs.
+type PostExportDatasetJobsParamsProductType string
+
+// PostExportDatasetJobsParamsDataset defines parameters for PostExportDatasetJobs.
+type PostExportDatasetJobsParamsDataset string
+
+// PostImportDatasetParams defines parameters for PostImportDataset.
+type PostImportDatasetParams struct {
+	// Format Формат файла; по умолчанию ndjson для Content-Type application/x-ndjson, иначе csv
+	Format *PostImportDatasetParamsFormat `binding:"omitempty,oneof=csv ndjson" form:"format" json:"format,omitempty"`
+
+	// DryRun Только проверить файл, ничего не записывая
+	DryRun *bool `form:"dryRun" json:"dryRun,omitempty"`
+}
+
+// PostImportDatasetParamsFormat defines parameters for PostImportDataset.
+type PostImportDatasetParamsFormat string
+
+// PostImportDatasetParamsDataset defines parameters for PostImportDataset.
+type PostImportDatasetParamsDataset string
+
 // PostLoginJSONBody defines parameters for PostLogin.
 type PostLoginJSONBody struct {
 	Email    openapi_types.Email `binding:"required,email" json:"email"`
 	Password string              `binding:"required" json:"password"`
 }
 
+// PostLoginMfaJSONBody defines parameters for PostLoginMfa.
+type PostLoginMfaJSONBody struct {
+	ChallengeToken string `binding:"required" json:"challengeToken"`
+	Code           string `binding:"required" json:"code"`
+}
+
+// PutMeLanguageJSONBody defines parameters for PutMeLanguage.
+type PutMeLanguageJSONBody struct {
+	Language string `binding:"omitempty,oneof=ru en" json:"language"`
+}
+
+// PostMeMfaTotpConfirmJSONBody defines parameters for PostMeMfaTotpConfirm.
+type PostMeMfaTotpConfirmJSONBody struct {
+	Code string `binding:"required" json:"code"`
+}
+
+// PostMeMfaTotpDisableJSONBody defines parameters for PostMeMfaTotpDisable.
+type PostMeMfaTotpDisableJSONBody struct {
+	Code string `binding:"required" json:"code"`
+}
+
+// PostMePasswordJSONBody defines parameters for PostMePassword.
+type PostMePasswordJSONBody struct {
+	CurrentPassword string `binding:"required" json:"currentPassword"`
+	NewPassword     string `binding:"required" json:"newPassword"`
+}
+
+// PostPasswordResetJSONBody defines parameters for PostPasswordReset.
+type PostPasswordResetJSONBody struct {
+	Email openapi_types.Email `binding:"required,email" json:"email"`
+}
+
+// PostPasswordResetConfirmJSONBody defines parameters for PostPasswordResetConfirm.
+type PostPasswordResetConfirmJSONBody struct {
+	NewPassword string `binding:"required" json:"newPassword"`
+	Token       string `binding:"required" json:"token"`
+}
+
 // PostProductsJSONBody defines parameters for PostProducts.
 type PostProductsJSONBody struct {
 	PvzId openapi_types.UUID       `binding:"required,uuid4" json:"pvzId"`
 	Type  PostProductsJSONBodyType `binding:"required,oneof=электроника одежда обувь" json:"type"`
 }
 
+// PostProductsParams defines parameters for PostProducts.
+type PostProductsParams struct {
+	// IdempotencyKey Ключ идемпотентности, например UUID. Повтор запроса с тем же ключом и телом в течение
+	// IDEMPOTENCY_TTL не выполняется заново, а получает сохраненный ответ с заголовком
+	// Idempotent-Replayed: true. Ошибки сервера не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
 // PostProductsJSONBodyType defines parameters for PostProducts.
 type PostProductsJSONBodyType string
 
 // GetPvzParams defines parameters for GetPvz.
 type GetPvzParams struct {
-	// StartDate Начальная дата диапазона
+	// StartDate Начальная дата диапазона приемок. Границы диапазона можно указывать по отдельности
 	StartDate *time.Time `form:"startDate" json:"startDate,omitempty"`
 
-	// EndDate Конечная дата диапазона
+	// EndDate Конечная дата диапазона приемок
 	EndDate *time.Time `binding:"omitempty,gtfield=StartDate" form:"endDate" json:"endDate,omitempty"`
 
+	// City Город ПВЗ, можно указать несколько раз
+	City *[]GetPvzParamsCity `binding:"omitempty,dive,oneof=Москва Санкт-Петербург Казань" form:"city" json:"city,omitempty"`
+
+	// RegisteredFrom Начало диапазона дат регистрации ПВЗ
+	RegisteredFrom *time.Time `form:"registeredFrom" json:"registeredFrom,omitempty"`
+
+	// RegisteredTo Конец диапазона дат регистрации ПВЗ
+	RegisteredTo *time.Time `binding:"omitempty,gtfield=RegisteredFrom" form:"registeredTo" json:"registeredTo,omitempty"`
+
+	// ReceptionStatus Только ПВЗ с приемкой в этом статусе (с учетом диапазона дат приемок)
+	ReceptionStatus *GetPvzParamsReceptionStatus `binding:"omitempty,oneof=in_progress close" form:"receptionStatus" json:"receptionStatus,omitempty"`
+
+	// ProductType Только ПВЗ с приемкой, в которой есть товар этого типа
+	ProductType *GetPvzParamsProductType `binding:"omitempty,oneof=электроника одежда обувь" form:"productType" json:"productType,omitempty"`
+
+	// HasOpenReception Только ПВЗ с открытой приемкой (true) или без нее (false)
+	HasOpenReception *bool `form:"hasOpenReception" json:"hasOpenReception,omitempty"`
+
+	// SortBy Поле сортировки. lastReception - время последней приемки, ПВЗ без приемок считаются самыми старыми
+	SortBy    *GetPvzParamsSortBy    `binding:"omitempty,oneof=registrationDate city lastReception" form:"sortBy" json:"sortBy,omitempty"`
+	SortOrder *GetPvzParamsSortOrder `binding:"omitempty,oneof=asc desc" form:"sortOrder" json:"sortOrder,omitempty"`
+
 	// Page Номер страницы
 	Page *int `binding:"omitempty,min=1" form:"page" json:"page,omitempty"`
 
 	// Limit Количество элементов на странице
 	Limit *int `binding:"omitempty,min=1,max=30" form:"limit" json:"limit,omitempty"`
+
+	// Cursor Курсор из nextCursor или prevCursor предыдущего ответа. Курсор действителен только
+	// с той же сортировкой; с курсором параметр page не учитывается.
+	Cursor *string `form:"cursor" json:"cursor,omitempty"`
+
+	// WithTotal Считать ли общее количество ПВЗ (totalCount). Для обхода больших списков лучше отключить.
+	WithTotal *bool `form:"withTotal" json:"withTotal,omitempty"`
+
+	// ProductsLimit Сколько последних товаров каждой приемки вернуть. Полное количество по типам есть в productCounts.
+	ProductsLimit *int `binding:"omitempty,min=1,max=1000" form:"productsLimit" json:"productsLimit,omitempty"`
+
+	// ProductCountsOnly Вернуть только количество товаров по типам, без списков товаров
+	ProductCountsOnly *bool `form:"productCountsOnly" json:"productCountsOnly,omitempty"`
+}
+
+// GetPvzParamsCity defines parameters for GetPvz.
+type GetPvzParamsCity string
+
+// GetPvzParamsReceptionStatus defines parameters for GetPvz.
+type GetPvzParamsReceptionStatus string
+
+// GetPvzParamsProductType defines parameters for GetPvz.
+type GetPvzParamsProductType string
+
+// GetPvzParamsSortBy defines parameters for GetPvz.
+type GetPvzParamsSortBy string
+
+// GetPvzParamsSortOrder defines parameters for GetPvz.
+type GetPvzParamsSortOrder string
+
+// PostPvzParams defines parameters for PostPvz.
+type PostPvzParams struct {
+	// IdempotencyKey Ключ идемпотентности, например UUID. Повтор запроса с тем же ключом и телом в течение
+	// IDEMPOTENCY_TTL не выполняется заново, а получает сохраненный ответ с заголовком
+	// Idempotent-Replayed: true. Ошибки сервера не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetPvzNearbyParams defines parameters for GetPvzNearby.
+type GetPvzNearbyParams struct {
+	// Lat Широта точки поиска
+	Lat float64 `form:"lat" json:"lat"`
+
+	// Lon Долгота точки поиска
+	Lon float64 `form:"lon" json:"lon"`
+
+	// Radius Радиус поиска в метрах
+	Radius *float64 `form:"radius" json:"radius,omitempty"`
+
+	// Limit Максимальное количество ПВЗ в ответе
+	Limit *int `binding:"omitempty,min=1,max=100" form:"limit" json:"limit,omitempty"`
+
+	// OpenNow Вернуть только ПВЗ, работающие в текущий момент
+	OpenNow *bool `form:"openNow" json:"openNow,omitempty"`
+}
+
+// GetPvzUtilizationParams defines parameters for GetPvzUtilization.
+type GetPvzUtilizationParams struct {
+	// Threshold Порог заполненности, по умолчанию CAPACITY_UTILIZATION_THRESHOLD
+	Threshold *float64 `form:"threshold" json:"threshold,omitempty"`
+}
+
+// GetPvzPvzIdParams defines parameters for GetPvzPvzId.
+type GetPvzPvzIdParams struct {
+	// IfNoneMatch ETag из предыдущего ответа. Если ресурс не изменился, возвращается 304 без тела.
+	IfNoneMatch *IfNoneMatch `json:"If-None-Match,omitempty"`
+}
+
+// PatchPvzPvzIdJSONBody defines parameters for PatchPvzPvzId.
+type PatchPvzPvzIdJSONBody struct {
+	Address *string `binding:"omitempty,max=500" json:"address"`
+
+	// Capacity Вместимость ПВЗ в товарах. 0 или отсутствие значения означает, что ограничения нет.
+	Capacity  *PVZCapacity `json:"capacity,omitempty"`
+	Latitude  *float64     `json:"latitude"`
+	Longitude *float64     `json:"longitude"`
+
+	// Phone Телефон в международном формате, пустая строка удаляет телефон
+	Phone  *string                      `json:"phone"`
+	Status *PatchPvzPvzIdJSONBodyStatus `binding:"omitempty,oneof=active temporarily_closed decommissioned" json:"status"`
+
+	// WorkingHours Недельное расписание и исключения на даты. Дни, которых нет в weekly, выходные.
+	WorkingHours *WorkingHours `json:"workingHours,omitempty"`
+}
+
+// PatchPvzPvzIdParams defines parameters for PatchPvzPvzId.
+type PatchPvzPvzIdParams struct {
+	// IdempotencyKey Ключ идемпотентности, например UUID. Повтор запроса с тем же ключом и телом в течение
+	// IDEMPOTENCY_TTL не выполняется заново, а получает сохраненный ответ с заголовком
+	// Idempotent-Replayed: true. Ошибки сервера не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+
+	// IfMatch ETag изменяемого ресурса из последнего ответа на его чтение. Если ресурс с тех пор
+	// изменился, запрос отклоняется с 412; `*` снимает проверку.
+	IfMatch IfMatch `json:"If-Match"`
+}
+
+// PatchPvzPvzIdJSONBodyStatus defines parameters for PatchPvzPvzId.
+type PatchPvzPvzIdJSONBodyStatus string
+
+// PostPvzPvzIdCloseLastReceptionParams defines parameters for PostPvzPvzIdCloseLastReception.
+type PostPvzPvzIdCloseLastReceptionParams struct {
+	// IdempotencyKey Ключ идемпотентности, например UUID. Повтор запроса с тем же ключом и телом в течение
+	// IDEMPOTENCY_TTL не выполняется заново, а получает сохраненный ответ с заголовком
+	// Idempotent-Replayed: true. Ошибки сервера не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+
+	// IfMatch ETag изменяемого ресурса из последнего ответа на его чтение. Если ресурс с тех пор
+	// изменился, запрос отклоняется с 412; `*` снимает проверку.
+	IfMatch IfMatch `json:"If-Match"`
+}
+
+// PostPvzPvzIdDeleteLastProductParams defines parameters for PostPvzPvzIdDeleteLastProduct.
+type PostPvzPvzIdDeleteLastProductParams struct {
+	// IdempotencyKey Ключ идемпотентности, например UUID. Повтор запроса с тем же ключом и телом в течение
+	// IDEMPOTENCY_TTL не выполняется заново, а получает сохраненный ответ с заголовком
+	// Idempotent-Replayed: true. Ошибки сервера не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetPvzPvzIdReceptionsParams defines parameters for GetPvzPvzIdReceptions.
+type GetPvzPvzIdReceptionsParams struct {
+	Status *GetPvzPvzIdReceptionsParamsStatus `binding:"omitempty,oneof=in_progress close" form:"status" json:"status,omitempty"`
+	Page   *int                               `binding:"omitempty,min=1" form:"page" json:"page,omitempty"`
+	Limit  *int                               `binding:"omitempty,min=1,max=100" form:"limit" json:"limit,omitempty"`
+}
+
+// GetPvzPvzIdReceptionsParamsStatus defines parameters for GetPvzPvzIdReceptions.
+type GetPvzPvzIdReceptionsParamsStatus string
+
+// GetPvzPvzIdReceptionsCurrentParams defines parameters for GetPvzPvzIdReceptionsCurrent.
+type GetPvzPvzIdReceptionsCurrentParams struct {
+	// IfNoneMatch ETag из предыдущего ответа. Если ресурс не изменился, возвращается 304 без тела.
+	IfNoneMatch *IfNoneMatch `json:"If-None-Match,omitempty"`
 }
 
 // PostReceptionsJSONBody defines parameters for PostReceptions.
@@ -151,28 +1147,108 @@ type PostReceptionsJSONBody struct {
 	PvzId openapi_types.UUID `binding:"required,uuid4" json:"pvzId"`
 }
 
+// PostReceptionsParams defines parameters for PostReceptions.
+type PostReceptionsParams struct {
+	// IdempotencyKey Ключ идемпотентности, например UUID. Повтор запроса с тем же ключом и телом в течение
+	// IDEMPOTENCY_TTL не выполняется заново, а получает сохраненный ответ с заголовком
+	// Idempotent-Replayed: true. Ошибки сервера не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetReceptionsReceptionIdParams defines parameters for GetReceptionsReceptionId.
+type GetReceptionsReceptionIdParams struct {
+	// IfNoneMatch ETag из предыдущего ответа. Если ресурс не изменился, возвращается 304 без тела.
+	IfNoneMatch *IfNoneMatch `json:"If-None-Match,omitempty"`
+}
+
 // PostRegisterJSONBody defines parameters for PostRegister.
 type PostRegisterJSONBody struct {
 	Email    openapi_types.Email      `binding:"required,email" json:"email"`
-	Password string                   `binding:"required,min=6" json:"password"`
+	Password string                   `binding:"required" json:"password"`
 	Role     PostRegisterJSONBodyRole `binding:"required,oneof=employee moderator" json:"role"`
 }
 
 // PostRegisterJSONBodyRole defines parameters for PostRegister.
 type PostRegisterJSONBodyRole string
 
+// GetReportsReceptionsParams defines parameters for GetReportsReceptions.
+type GetReportsReceptionsParams struct {
+	// GroupBy Группировка, можно указать несколько раз
+	GroupBy *[]GetReportsReceptionsParamsGroupBy `binding:"omitempty,dive,oneof=pvz city productType" form:"groupBy" json:"groupBy,omitempty"`
+
+	// Period Разбивка по периодам
+	Period *GetReportsReceptionsParamsPeriod `binding:"omitempty,oneof=day week month" form:"period" json:"period,omitempty"`
+
+	// From Начало диапазона дат приемок
+	From *time.Time `form:"from" json:"from,omitempty"`
+
+	// To Конец диапазона дат приемок
+	To *time.Time `form:"to" json:"to,omitempty"`
+
+	// City Город ПВЗ, можно указать несколько раз
+	City *[]GetReportsReceptionsParamsCity `binding:"omitempty,dive,oneof=Москва Санкт-Петербург Казань" form:"city" json:"city,omitempty"`
+
+	// PvzId UUID ПВЗ
+	PvzId *string `binding:"omitempty,uuid" form:"pvzId" json:"pvzId,omitempty"`
+
+	// ProductType Учитывать только товары этого типа
+	ProductType *GetReportsReceptionsParamsProductType `binding:"omitempty,oneof=электроника одежда обувь" form:"productType" json:"productType,omitempty"`
+}
+
+// GetReportsReceptionsParamsGroupBy defines parameters for GetReportsReceptions.
+type GetReportsReceptionsParamsGroupBy string
+
+// GetReportsReceptionsParamsPeriod defines parameters for GetReportsReceptions.
+type GetReportsReceptionsParamsPeriod string
+
+// GetReportsReceptionsParamsCity defines parameters for GetReportsReceptions.
+type GetReportsReceptionsParamsCity string
+
+// GetReportsReceptionsParamsProductType defines parameters for GetReportsReceptions.
+type GetReportsReceptionsParamsProductType string
+
+// PostAdminServiceAccountsJSONRequestBody defines body for PostAdminServiceAccounts for application/json ContentType.
+type PostAdminServiceAccountsJSONRequestBody PostAdminServiceAccountsJSONBody
+
+// PostAdminServiceAccountsServiceAccountIdKeysJSONRequestBody defines body for PostAdminServiceAccountsServiceAccountIdKeys for application/json ContentType.
+type PostAdminServiceAccountsServiceAccountIdKeysJSONRequestBody PostAdminServiceAccountsServiceAccountIdKeysJSONBody
+
 // PostDummyLoginJSONRequestBody defines body for PostDummyLogin for application/json ContentType.
 type PostDummyLoginJSONRequestBody PostDummyLoginJSONBody
 
 // PostLoginJSONRequestBody defines body for PostLogin for application/json ContentType.
 type PostLoginJSONRequestBody PostLoginJSONBody
 
+// PostLoginMfaJSONRequestBody defines body for PostLoginMfa for application/json ContentType.
+type PostLoginMfaJSONRequestBody PostLoginMfaJSONBody
+
+// PutMeLanguageJSONRequestBody defines body for PutMeLanguage for application/json ContentType.
+type PutMeLanguageJSONRequestBody PutMeLanguageJSONBody
+
+// PostMeMfaTotpConfirmJSONRequestBody defines body for PostMeMfaTotpConfirm for application/json ContentType.
+type PostMeMfaTotpConfirmJSONRequestBody PostMeMfaTotpConfirmJSONBody
+
+// PostMeMfaTotpDisableJSONRequestBody defines body for PostMeMfaTotpDisable for application/json ContentType.
+type PostMeMfaTotpDisableJSONRequestBody PostMeMfaTotpDisableJSONBody
+
+// PostMePasswordJSONRequestBody defines body for PostMePassword for application/json ContentType.
+type PostMePasswordJSONRequestBody PostMePasswordJSONBody
+
+// PostPasswordResetJSONRequestBody defines body for PostPasswordReset for application/json ContentType.
+type PostPasswordResetJSONRequestBody PostPasswordResetJSONBody
+
+// PostPasswordResetConfirmJSONRequestBody defines body for PostPasswordResetConfirm for application/json ContentType.
+type PostPasswordResetConfirmJSONRequestBody PostPasswordResetConfirmJSONBody
+
 // PostProductsJSONRequestBody defines body for PostProducts for application/json ContentType.
 type PostProductsJSONRequestBody PostProductsJSONBody
 
 // PostPvzJSONRequestBody defines body for PostPvz for application/json ContentType.
 type PostPvzJSONRequestBody = PVZ
 
+// PatchPvzPvzIdJSONRequestBody defines body for PatchPvzPvzId for application/json ContentType.
+type PatchPvzPvzIdJSONRequestBody PatchPvzPvzIdJSONBody
+
 // PostReceptionsJSONRequestBody defines body for PostReceptions for application/json ContentType.
 type PostReceptionsJSONRequestBody PostReceptionsJSONBody
 
@@ -2,9 +2,11 @@ package dto
 
 type PVZListResponseDTO struct {
 	Items      []PVZWithReceptionsResponseDTO `json:"items"`
-	TotalCount int                            `json:"totalCount"`
-	Page       int                            `json:"page"`
+	TotalCount *int                           `json:"totalCount,omitempty"`
+	Page       int                            `json:"page,omitempty"`
 	Limit      int                            `json:"limit"`
+	NextCursor string                         `json:"nextCursor,omitempty"`
+	PrevCursor string                         `json:"prevCursor,omitempty"`
 }
 
 type PVZWithReceptionsResponseDTO struct {
@@ -13,6 +15,8 @@ type PVZWithReceptionsResponseDTO struct {
 }
 
 type ReceptionWithProductsDTO struct {
-	Reception Reception `json:"reception"`
-	Products  []Product `json:"products"`
+	Reception     Reception      `json:"reception"`
+	Products      []Product      `json:"products"`
+	ProductCounts map[string]int `json:"productCounts"`
+	ProductsTotal int            `json:"productsTotal"`
 }
@@ -0,0 +1,219 @@
+// Package errcode сопоставляет ошибкам apperrors и repoerrors стабильные коды API и
+// HTTP-статусы. По коду клиенты различают ошибки, а каталоги i18n хранят их тексты.
+package errcode
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"errors"
+	"net/http"
+)
+
+// Descriptor описывает ошибку для клиента: HTTP-статус и стабильный код.
+type Descriptor struct {
+	Status int
+	Code   string
+}
+
+// Internal описывает ошибки, которых нет в реестре. Их текст может содержать детали
+// хранилища, поэтому клиент получает только общий ответ.
+var Internal = Descriptor{http.StatusInternalServerError, "internal_error"}
+
+// descriptors описывает все ошибки apperrors и repoerrors. Коды - часть API: существующие
+// коды не меняются, новые только добавляются.
+var descriptors = map[error]Descriptor{
+	apperrors.ErrInvalidCredentials:    {http.StatusUnauthorized, "invalid_credentials"},
+	apperrors.ErrInvalidResetToken:     {http.StatusBadRequest, "invalid_reset_token"},
+	apperrors.ErrTokenRevoked:          {http.StatusUnauthorized, "token_revoked"},
+	apperrors.ErrDummyLoginDisabled:    {http.StatusForbidden, "dummy_login_disabled"},
+	apperrors.ErrDummyTokenRejected:    {http.StatusUnauthorized, "dummy_token_rejected"},
+	apperrors.ErrAuthorizationRequired: {http.StatusUnauthorized, "authorization_required"},
+	apperrors.ErrInvalidAuthorization:  {http.StatusUnauthorized, "invalid_authorization"},
+	apperrors.ErrInvalidToken:          {http.StatusUnauthorized, "invalid_token"},
+	apperrors.ErrUnauthenticated:       {http.StatusUnauthorized, "unauthenticated"},
+
+	// Access errors
+	apperrors.ErrInsufficientPermissions: {http.StatusForbidden, "insufficient_permissions"},
+
+	// Two-factor authentication errors
+	apperrors.ErrInvalidMFACode:        {http.StatusUnauthorized, "invalid_mfa_code"},
+	apperrors.ErrInvalidMFAChallenge:   {http.StatusUnauthorized, "invalid_mfa_challenge"},
+	apperrors.ErrMFAAlreadyEnabled:     {http.StatusConflict, "mfa_already_enabled"},
+	apperrors.ErrMFANotEnabled:         {http.StatusBadRequest, "mfa_not_enabled"},
+	apperrors.ErrMFAEnrollmentNotFound: {http.StatusBadRequest, "mfa_enrollment_not_found"},
+	apperrors.ErrMFARequired:           {http.StatusForbidden, "mfa_required"},
+	apperrors.ErrMFACannotBeDisabled:   {http.StatusForbidden, "mfa_cannot_be_disabled"},
+
+	// Service account errors
+	apperrors.ErrInvalidAPIKey:      {http.StatusUnauthorized, "invalid_api_key"},
+	apperrors.ErrInsufficientScope:  {http.StatusForbidden, "insufficient_scope"},
+	apperrors.ErrPVZAccessForbidden: {http.StatusForbidden, "pvz_access_forbidden"},
+
+	// PVZ business errors
+	apperrors.ErrPVZDecommissioned: {http.StatusConflict, "pvz_decommissioned"},
+	apperrors.ErrPVZClosed:         {http.StatusBadRequest, "pvz_closed"},
+	apperrors.ErrCapacityExceeded:  {http.StatusConflict, "capacity_exceeded"},
+
+	// Reception business errors
+	apperrors.ErrReceptionAlreadyClosed:    {http.StatusBadRequest, "reception_already_closed"},
+	apperrors.ErrReceptionCannotBeModified: {http.StatusBadRequest, "reception_cannot_be_modified"},
+	apperrors.ErrActiveReceptionExists:     {http.StatusBadRequest, "active_reception_exists"},
+	apperrors.ErrNoActiveReception:         {http.StatusBadRequest, "no_active_reception"},
+
+	// Product business errors
+	apperrors.ErrNoProductsToDelete: {http.StatusBadRequest, "no_products_to_delete"},
+
+	// Export business errors
+	apperrors.ErrExportJobNotReady: {http.StatusConflict, "export_job_not_ready"},
+	apperrors.ErrExportJobFailed:   {http.StatusConflict, "export_job_failed"},
+	apperrors.ErrExportJobExpired:  {http.StatusGone, "export_job_expired"},
+
+	// Import business errors
+	apperrors.ErrImportReferenceNotFound: {http.StatusBadRequest, "import_reference_not_found"},
+
+	// Idempotency business errors
+	apperrors.ErrIdempotencyKeyInUse:  {http.StatusConflict, "idempotency_key_in_use"},
+	apperrors.ErrIdempotencyKeyReused: {http.StatusUnprocessableEntity, "idempotency_key_reused"},
+
+	// Optimistic concurrency errors
+	apperrors.ErrVersionMismatch: {http.StatusPreconditionFailed, "version_mismatch"},
+
+	// Request validation errors
+	apperrors.ErrInvalidRequestBody:     {http.StatusBadRequest, "invalid_request_body"},
+	apperrors.ErrInvalidQueryParameters: {http.StatusBadRequest, "invalid_query_parameters"},
+	apperrors.ErrRequestBodyTooLarge:    {http.StatusRequestEntityTooLarge, "request_body_too_large"},
+
+	// User validation errors
+	apperrors.ErrEmailRequired:    {http.StatusBadRequest, "email_required"},
+	apperrors.ErrInvalidEmail:     {http.StatusBadRequest, "invalid_email"},
+	apperrors.ErrPasswordRequired: {http.StatusBadRequest, "password_required"},
+	apperrors.ErrInvalidPassword:  {http.StatusBadRequest, "invalid_password"},
+	apperrors.ErrPasswordTooLong:  {http.StatusBadRequest, "password_too_long"},
+	apperrors.ErrPasswordBreached: {http.StatusBadRequest, "password_breached"},
+	apperrors.ErrInvalidRole:      {http.StatusBadRequest, "invalid_role"},
+	apperrors.ErrInvalidLanguage:  {http.StatusBadRequest, "invalid_language"},
+
+	// Service account validation errors
+	apperrors.ErrServiceAccountNameRequired: {http.StatusBadRequest, "service_account_name_required"},
+	apperrors.ErrInvalidScope:               {http.StatusBadRequest, "invalid_scope"},
+	apperrors.ErrInvalidKeyExpiry:           {http.StatusBadRequest, "invalid_key_expiry"},
+	apperrors.ErrInvalidServiceAccountID:    {http.StatusBadRequest, "invalid_service_account_id"},
+	apperrors.ErrInvalidAPIKeyID:            {http.StatusBadRequest, "invalid_api_key_id"},
+
+	// PVZ validation errors
+	apperrors.ErrCityRequired:        {http.StatusBadRequest, "city_required"},
+	apperrors.ErrInvalidCity:         {http.StatusBadRequest, "invalid_city"},
+	apperrors.ErrInvalidPVZID:        {http.StatusBadRequest, "invalid_pvz_id"},
+	apperrors.ErrInvalidCursor:       {http.StatusBadRequest, "invalid_cursor"},
+	apperrors.ErrInvalidSort:         {http.StatusBadRequest, "invalid_sort"},
+	apperrors.ErrInvalidPVZStatus:    {http.StatusBadRequest, "invalid_pvz_status"},
+	apperrors.ErrInvalidCoordinates:  {http.StatusBadRequest, "invalid_coordinates"},
+	apperrors.ErrInvalidPhone:        {http.StatusBadRequest, "invalid_phone"},
+	apperrors.ErrInvalidWorkingHours: {http.StatusBadRequest, "invalid_working_hours"},
+	apperrors.ErrEmptyPVZUpdate:      {http.StatusBadRequest, "empty_pvz_update"},
+	apperrors.ErrInvalidCapacity:     {http.StatusBadRequest, "invalid_capacity"},
+	apperrors.ErrInvalidThreshold:    {http.StatusBadRequest, "invalid_threshold"},
+	apperrors.ErrInvalidNearbyRadius: {http.StatusBadRequest, "invalid_nearby_radius"},
+	apperrors.ErrNearbyPointRequired: {http.StatusBadRequest, "nearby_point_required"},
+
+	// Reception validation errors
+	apperrors.ErrInvalidReceptionID:     {http.StatusBadRequest, "invalid_reception_id"},
+	apperrors.ErrInvalidReceptionStatus: {http.StatusBadRequest, "invalid_reception_status"},
+
+	// Report validation errors
+	apperrors.ErrInvalidReportGroup:  {http.StatusBadRequest, "invalid_report_group"},
+	apperrors.ErrInvalidReportPeriod: {http.StatusBadRequest, "invalid_report_period"},
+	apperrors.ErrInvalidDateRange:    {http.StatusBadRequest, "invalid_date_range"},
+
+	// Product validation errors
+	apperrors.ErrProductTypeRequired: {http.StatusBadRequest, "product_type_required"},
+	apperrors.ErrInvalidProductType:  {http.StatusBadRequest, "invalid_product_type"},
+	apperrors.ErrInvalidProductID:    {http.StatusBadRequest, "invalid_product_id"},
+
+	// Audit validation errors
+	apperrors.ErrInvalidActorID: {http.StatusBadRequest, "invalid_actor_id"},
+
+	// Export validation errors
+	apperrors.ErrInvalidExportDataset: {http.StatusBadRequest, "invalid_export_dataset"},
+	apperrors.ErrInvalidExportFormat:  {http.StatusBadRequest, "invalid_export_format"},
+	apperrors.ErrInvalidExportJobID:   {http.StatusBadRequest, "invalid_export_job_id"},
+
+	// Import validation errors
+	apperrors.ErrInvalidImportDataset:     {http.StatusBadRequest, "invalid_import_dataset"},
+	apperrors.ErrInvalidImportFormat:      {http.StatusBadRequest, "invalid_import_format"},
+	apperrors.ErrMalformedImportRow:       {http.StatusBadRequest, "malformed_import_row"},
+	apperrors.ErrImportExternalIDRequired: {http.StatusBadRequest, "import_external_id_required"},
+	apperrors.ErrDuplicateExternalID:      {http.StatusBadRequest, "duplicate_external_id"},
+	apperrors.ErrImportValueRequired:      {http.StatusBadRequest, "import_value_required"},
+	apperrors.ErrInvalidImportValue:       {http.StatusBadRequest, "invalid_import_value"},
+	apperrors.ErrImportReceptionNotClosed: {http.StatusBadRequest, "import_reception_not_closed"},
+	apperrors.ErrImportClosedBeforeOpen:   {http.StatusBadRequest, "import_closed_before_open"},
+	apperrors.ErrImportFileTooLarge:       {http.StatusRequestEntityTooLarge, "import_file_too_large"},
+
+	// Event stream validation errors
+	apperrors.ErrInvalidLastEventID: {http.StatusBadRequest, "invalid_last_event_id"},
+	apperrors.ErrInvalidResumeToken: {http.StatusBadRequest, "invalid_resume_token"},
+
+	// Idempotency validation errors
+	apperrors.ErrInvalidIdempotencyKey: {http.StatusBadRequest, "invalid_idempotency_key"},
+
+	// Precondition validation errors
+	apperrors.ErrPreconditionRequired: {http.StatusPreconditionRequired, "precondition_required"},
+
+	// User storage errors
+	repoerrors.ErrUserNotFound:      {http.StatusNotFound, "user_not_found"},
+	repoerrors.ErrUserAlreadyExists: {http.StatusConflict, "user_already_exists"},
+
+	// Password reset storage errors
+	repoerrors.ErrResetTokenNotFound: {http.StatusNotFound, "reset_token_not_found"},
+
+	// Two-factor authentication storage errors
+	repoerrors.ErrTOTPNotFound:         {http.StatusNotFound, "totp_not_found"},
+	repoerrors.ErrTOTPStepAlreadyUsed:  {http.StatusUnauthorized, "totp_step_already_used"},
+	repoerrors.ErrRecoveryCodeNotFound: {http.StatusNotFound, "recovery_code_not_found"},
+
+	// Service account storage errors
+	repoerrors.ErrServiceAccountNotFound:      {http.StatusNotFound, "service_account_not_found"},
+	repoerrors.ErrServiceAccountAlreadyExists: {http.StatusConflict, "service_account_already_exists"},
+	repoerrors.ErrAPIKeyNotFound:              {http.StatusNotFound, "api_key_not_found"},
+
+	// Export storage errors
+	repoerrors.ErrExportJobNotFound: {http.StatusNotFound, "export_job_not_found"},
+
+	// PVZ storage errors
+	repoerrors.ErrPVZNotFound:      {http.StatusNotFound, "pvz_not_found"},
+	repoerrors.ErrPVZAlreadyExists: {http.StatusConflict, "pvz_already_exists"},
+
+	// Reception storage errors
+	repoerrors.ErrReceptionNotFound:      {http.StatusNotFound, "reception_not_found"},
+	repoerrors.ErrReceptionAlreadyExists: {http.StatusConflict, "reception_already_exists"},
+
+	// Product storage errors
+	repoerrors.ErrProductNotFound:      {http.StatusNotFound, "product_not_found"},
+	repoerrors.ErrProductAlreadyExists: {http.StatusConflict, "product_already_exists"},
+}
+
+// Describe находит описание ошибки err, в том числе обернутой. Для неизвестных ошибок
+// возвращает Internal и false.
+func Describe(err error) (Descriptor, bool) {
+	if descriptor, exists := descriptors[err]; exists {
+		return descriptor, true
+	}
+
+	for knownErr, descriptor := range descriptors {
+		if errors.Is(err, knownErr) {
+			return descriptor, true
+		}
+	}
+
+	return Internal, false
+}
+
+// Codes возвращает коды всех ошибок реестра.
+func Codes() []string {
+	codes := make([]string, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		codes = append(codes, descriptor.Code)
+	}
+	return codes
+}
@@ -0,0 +1,140 @@
+package errcode
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expected      Descriptor
+		expectedKnown bool
+	}{
+		{
+			name:          "Repository error - PVZ not found",
+			err:           repoerrors.ErrPVZNotFound,
+			expected:      Descriptor{http.StatusNotFound, "pvz_not_found"},
+			expectedKnown: true,
+		},
+		{
+			name:          "App error - Invalid city",
+			err:           apperrors.ErrInvalidCity,
+			expected:      Descriptor{http.StatusBadRequest, "invalid_city"},
+			expectedKnown: true,
+		},
+		{
+			name:          "App error - Active reception exists",
+			err:           apperrors.ErrActiveReceptionExists,
+			expected:      Descriptor{http.StatusBadRequest, "active_reception_exists"},
+			expectedKnown: true,
+		},
+		{
+			name:          "Wrapped error",
+			err:           fmt.Errorf("database error: %w", repoerrors.ErrUserNotFound),
+			expected:      Descriptor{http.StatusNotFound, "user_not_found"},
+			expectedKnown: true,
+		},
+		{
+			name:          "Unknown error",
+			err:           errors.New(`pq: relation "pvz" does not exist`),
+			expected:      Internal,
+			expectedKnown: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			descriptor, known := Describe(tt.err)
+			assert.Equal(t, tt.expectedKnown, known)
+			assert.Equal(t, tt.expected, descriptor)
+		})
+	}
+}
+
+// Каждая ошибка apperrors и repoerrors должна иметь код, иначе клиент получит 500.
+func TestDescriptors_CoverAllErrors(t *testing.T) {
+	declared := declaredErrors(t, "apperrors", "../../domain/apperrors")
+	declared = append(declared, declaredErrors(t, "repoerrors", "../../repository/repoerrors")...)
+
+	registered := registeredErrors(t)
+	for _, name := range declared {
+		assert.Contains(t, registered, name, "%s has no entry in descriptors", name)
+	}
+	assert.Len(t, descriptors, len(declared))
+
+	codes := make(map[string]bool, len(descriptors))
+	for err, descriptor := range descriptors {
+		assert.False(t, codes[descriptor.Code], "duplicate code %s", descriptor.Code)
+		codes[descriptor.Code] = true
+
+		assert.NotZero(t, descriptor.Status, err.Error())
+	}
+	assert.False(t, codes[Internal.Code])
+}
+
+func declaredErrors(t *testing.T, pkg, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, decl := range parsed.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, ident := range spec.(*ast.ValueSpec).Names {
+					if strings.HasPrefix(ident.Name, "Err") {
+						names = append(names, pkg+"."+ident.Name)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+func registeredErrors(t *testing.T) []string {
+	parsed, err := parser.ParseFile(token.NewFileSet(), "errcode.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	ast.Inspect(parsed, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok || spec.Names[0].Name != "descriptors" {
+			return true
+		}
+		for _, elt := range spec.Values[0].(*ast.CompositeLit).Elts {
+			key := elt.(*ast.KeyValueExpr).Key.(*ast.SelectorExpr)
+			names = append(names, key.X.(*ast.Ident).Name+"."+key.Sel.Name)
+		}
+		return false
+	})
+	return names
+}
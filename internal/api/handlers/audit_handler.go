@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+func (h *Handler) getAuditLog(c *gin.Context) {
+	var params dto.GetAuditLogParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid query parameters in getAuditLog")
+		respondBindError(c, apperrors.ErrInvalidQueryParameters, err)
+		return
+	}
+
+	filter := models.AuditFilter{
+		StartDate: params.StartDate,
+		EndDate:   params.EndDate,
+		Page:      1,
+		Limit:     20,
+	}
+
+	if params.ActorId != nil {
+		actorID, err := uuid.Parse(*params.ActorId)
+		if err != nil {
+			zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("actor_id", *params.ActorId).Msg("Invalid actor ID format")
+			respondError(c, apperrors.ErrInvalidActorID)
+			return
+		}
+		filter.ActorID = &actorID
+	}
+	if params.Action != nil {
+		filter.Action = *params.Action
+	}
+	if params.EntityType != nil {
+		filter.EntityType = string(*params.EntityType)
+	}
+	if params.EntityId != nil {
+		filter.EntityID = *params.EntityId
+	}
+	if params.Page != nil {
+		filter.Page = *params.Page
+	}
+	if params.Limit != nil {
+		filter.Limit = *params.Limit
+	}
+
+	entries, total, err := h.auditService.ListAuditLog(c.Request.Context(), filter)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Msg("Failed to get audit log")
+
+		respondError(c, err)
+		return
+	}
+
+	items := make([]dto.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, toAuditEntryDTO(entry))
+	}
+
+	c.JSON(http.StatusOK, dto.AuditLogPage{
+		Items:      items,
+		TotalCount: total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+	})
+}
+
+func (h *Handler) verifyAuditLog(c *gin.Context) {
+	result, err := h.auditService.VerifyAuditChain(c.Request.Context())
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Msg("Failed to verify audit log")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AuditVerification{
+		Valid:        result.Valid,
+		CheckedCount: result.CheckedCount,
+		BrokenAtId:   result.BrokenAtID,
+	})
+}
+
+func toAuditEntryDTO(entry *models.AuditEntry) dto.AuditEntry {
+	return dto.AuditEntry{
+		Id:         entry.ID,
+		ActorId:    entry.ActorID,
+		ActorRole:  entry.ActorRole,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityId:   entry.EntityID,
+		Before:     auditSnapshot(entry.Before),
+		After:      auditSnapshot(entry.After),
+		ClientIp:   entry.ClientIP,
+		RequestId:  entry.RequestID,
+		CreatedAt:  entry.CreatedAt,
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
+	}
+}
+
+func auditSnapshot(raw json.RawMessage) *map[string]interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil
+	}
+	return &snapshot
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_getAuditLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuditService := mocks.NewMockAuditServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, mockAuditService, nil, nil, nil, nil, &config.Config{})
+
+	actorID := uuid.New()
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMocks     func()
+		expectedStatus int
+		expectedCount  int
+	}{
+		{
+			name:  "Filtered by actor and entity",
+			query: "?actorId=" + actorID.String() + "&entityType=reception&entityId=r-1&page=2&limit=5",
+			setupMocks: func() {
+				mockAuditService.EXPECT().ListAuditLog(gomock.Any(), models.AuditFilter{
+					ActorID:    &actorID,
+					EntityType: models.AuditEntityReception,
+					EntityID:   "r-1",
+					Page:       2,
+					Limit:      5,
+				}).Return([]*models.AuditEntry{{
+					ID:         7,
+					ActorID:    actorID,
+					Action:     models.AuditActionReceptionClose,
+					EntityType: models.AuditEntityReception,
+					EntityID:   "r-1",
+					After:      json.RawMessage(`{"status":"close"}`),
+				}}, 6, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:  "Default pagination",
+			query: "",
+			setupMocks: func() {
+				mockAuditService.EXPECT().ListAuditLog(gomock.Any(), models.AuditFilter{Page: 1, Limit: 20}).
+					Return([]*models.AuditEntry{}, 0, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  0,
+		},
+		{
+			name:           "Unknown entity type",
+			query:          "?entityType=warehouse",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Limit too large",
+			query:          "?limit=1000",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/audit-log"+tt.query, nil)
+
+			handler.getAuditLog(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var page struct {
+					Items []map[string]interface{} `json:"items"`
+				}
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &page))
+				assert.Len(t, page.Items, tt.expectedCount)
+			}
+		})
+	}
+}
+
+func TestHandler_verifyAuditLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuditService := mocks.NewMockAuditServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, mockAuditService, nil, nil, nil, nil, &config.Config{})
+
+	brokenAt := int64(12)
+	mockAuditService.EXPECT().VerifyAuditChain(gomock.Any()).
+		Return(&models.AuditVerification{Valid: false, CheckedCount: 12, BrokenAtID: &brokenAt}, nil)
+
+	resp := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(resp)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/audit-log/verify", nil)
+
+	handler.verifyAuditLog(c)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"valid":false,"checkedCount":12,"brokenAtId":12}`, resp.Body.String())
+}
+
+func TestActorMiddleware_RequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	tests := []struct {
+		name      string
+		requestID string
+		keep      bool
+	}{
+		{name: "Request ID is propagated", requestID: "req-123", keep: true},
+		{name: "Request ID is generated", requestID: "", keep: false},
+		{name: "Too long request ID is replaced", requestID: strings.Repeat("x", 100), keep: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(handler.actorMiddleware())
+
+			var actor models.Actor
+			router.GET("/", func(c *gin.Context) {
+				actor = models.ActorFromContext(c.Request.Context())
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			if tt.requestID != "" {
+				req.Header.Set(requestIDHeader, tt.requestID)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			responseID := resp.Header().Get(requestIDHeader)
+			assert.NotEmpty(t, responseID)
+			assert.Equal(t, responseID, actor.RequestID)
+			if tt.keep {
+				assert.Equal(t, tt.requestID, responseID)
+			} else {
+				assert.NotEqual(t, tt.requestID, responseID)
+			}
+		})
+	}
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	var buf bytes.Buffer
+	original := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = original }()
+
+	userID := uuid.New()
+
+	router := gin.New()
+	router.Use(handler.actorMiddleware())
+	router.Use(accessLogMiddleware())
+	router.GET("/pvz/:pvzId", func(c *gin.Context) {
+		setActor(c, userID, models.RoleEmployee)
+		zerolog.Ctx(c.Request.Context()).Info().Msg("Handler log")
+		respondError(c, repoerrors.ErrPVZNotFound)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/pvz/42", nil)
+	req.Header.Set(requestIDHeader, "req-42")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+
+	var handlerLine, accessLine map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &handlerLine))
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &accessLine))
+
+	assert.Equal(t, "req-42", handlerLine["request_id"])
+	assert.Equal(t, userID.String(), handlerLine["user_id"])
+
+	assert.Equal(t, "HTTP request", accessLine["message"])
+	assert.Equal(t, "req-42", accessLine["request_id"])
+	assert.Equal(t, userID.String(), accessLine["user_id"])
+	assert.Equal(t, models.RoleEmployee, accessLine["role"])
+	assert.Equal(t, "/pvz/42", accessLine["path"])
+	assert.Equal(t, "/pvz/:pvzId", accessLine["route"])
+	assert.Equal(t, float64(http.StatusNotFound), accessLine["status"])
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	recorder := tracetest.NewSpanRecorder()
+	originalProvider, originalPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(originalProvider)
+		otel.SetTextMapPropagator(originalPropagator)
+	}()
+
+	var buf bytes.Buffer
+	original := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = original }()
+
+	router := gin.New()
+	router.Use(tracingMiddleware())
+	router.Use(handler.actorMiddleware())
+	router.GET("/pvz/:pvzId", func(c *gin.Context) {
+		zerolog.Ctx(c.Request.Context()).Info().Msg("Handler log")
+		c.Status(http.StatusInternalServerError)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	req, _ := http.NewRequest(http.MethodGet, "/pvz/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest(http.MethodGet, "/unknown", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+
+	assert.Equal(t, "GET /pvz/:pvzId", spans[0].Name())
+	assert.Equal(t, traceID, spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+	assert.Equal(t, "GET unmatched", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+
+	var handlerLine map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(strings.Split(buf.String(), "\n")[0]), &handlerLine))
+	assert.Equal(t, traceID, handlerLine["trace_id"])
+}
@@ -2,29 +2,37 @@ package handlers
 
 import (
 	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/oapi-codegen/runtime/types"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"net/http"
 )
 
 func (h *Handler) dummyLogin(c *gin.Context) {
+	if h.config.Server.AppEnv == "production" {
+		zerolog.Ctx(c.Request.Context()).Warn().Str("client_ip", c.ClientIP()).Msg("Dummy login attempt in production")
+
+		respondError(c, apperrors.ErrDummyLoginDisabled)
+		return
+	}
+
 	var req dto.PostDummyLoginJSONRequestBody
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Debug().Err(err).Msg("Invalid request format in dummyLogin")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request format"})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in dummyLogin")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
 		return
 	}
 
 	role := string(req.Role)
 
-	token, err := h.userService.DummyLogin(role)
+	token, err := h.userService.DummyLogin(c.Request.Context(), role)
 	if err != nil {
-		log.Error().Err(err).Str("role", role).Msg("Dummy login failed")
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("role", role).Msg("Dummy login failed")
 
-		statusCode, message := getErrorResponse(err)
-		c.JSON(statusCode, gin.H{"message": message})
+		respondError(c, err)
 		return
 	}
 
@@ -35,8 +43,8 @@ func (h *Handler) register(c *gin.Context) {
 	var req dto.PostRegisterJSONRequestBody
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Debug().Err(err).Msg("Invalid request format in register")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request format"})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in register")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
 		return
 	}
 
@@ -44,10 +52,9 @@ func (h *Handler) register(c *gin.Context) {
 
 	user, err := h.userService.Register(c.Request.Context(), string(req.Email), req.Password, role)
 	if err != nil {
-		log.Error().Err(err).Str("email", string(req.Email)).Msg("User registration failed")
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("email", string(req.Email)).Msg("User registration failed")
 
-		statusCode, message := getErrorResponse(err)
-		c.JSON(statusCode, gin.H{"message": message})
+		respondError(c, err)
 		return
 	}
 
@@ -64,19 +71,53 @@ func (h *Handler) login(c *gin.Context) {
 	var req dto.PostLoginJSONRequestBody
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Debug().Err(err).Msg("Invalid request format in login")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request format"})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in login")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
 		return
 	}
 
-	token, err := h.userService.Login(c.Request.Context(), string(req.Email), req.Password)
+	result, err := h.userService.Login(c.Request.Context(), string(req.Email), req.Password)
 	if err != nil {
-		log.Error().Err(err).Str("email", string(req.Email)).Msg("Login failed")
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("email", string(req.Email)).Msg("Login failed")
 
-		statusCode, message := getErrorResponse(err)
-		c.JSON(statusCode, gin.H{"message": message})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, token)
+	if result.MFARequired {
+		c.JSON(http.StatusAccepted, dto.MFAChallenge{
+			MfaRequired:    true,
+			ChallengeToken: result.ChallengeToken,
+			ExpiresAt:      result.ChallengeExpiresAt,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result.Token)
+}
+
+// setLanguage сохраняет язык сообщений об ошибках в профиле пользователя.
+func (h *Handler) setLanguage(c *gin.Context) {
+	var req dto.PutMeLanguageJSONRequestBody
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in setLanguage")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+		return
+	}
+
+	userID, ok := c.Get(string(userIDKey))
+	if !ok {
+		respondError(c, apperrors.ErrUnauthenticated)
+		return
+	}
+
+	if err := h.userService.SetLanguage(c.Request.Context(), userID.(uuid.UUID), req.Language); err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("user_id", userID.(uuid.UUID).String()).Msg("Language change failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Language changed"})
 }
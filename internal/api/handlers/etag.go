@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+const (
+	etagHeader        = "ETag"
+	ifMatchHeader     = "If-Match"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+// setETag отдает версию ресурса в заголовке ETag.
+func setETag(c *gin.Context, id uuid.UUID, version int64) {
+	c.Header(etagHeader, models.EntityTag{ID: id, Version: version}.String())
+}
+
+// notModified ставит ETag и, если у клиента уже есть эта версия ресурса (If-None-Match),
+// отвечает 304 без тела.
+func notModified(c *gin.Context, id uuid.UUID, version int64) bool {
+	tag := models.EntityTag{ID: id, Version: version}
+	c.Header(etagHeader, tag.String())
+
+	if !models.MatchesIfNoneMatch(c.GetHeader(ifNoneMatchHeader), tag) {
+		return false
+	}
+
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// requirePrecondition читает обязательный заголовок If-Match изменяющего запроса.
+// Без него изменение могло бы незаметно перезаписать чужое, поэтому запрос
+// отклоняется с 428.
+func requirePrecondition(c *gin.Context) (models.Precondition, bool) {
+	header := c.GetHeader(ifMatchHeader)
+	if header == "" {
+		respondError(c, apperrors.ErrPreconditionRequired)
+		return models.Precondition{}, false
+	}
+
+	return models.ParsePrecondition(header), true
+}
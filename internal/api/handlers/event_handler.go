@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// lastEventIDHeader - заголовок, в котором EventSource при переподключении передает
+	// ID последнего полученного события.
+	lastEventIDHeader = "Last-Event-ID"
+
+	eventStreamContentType = "text/event-stream"
+	ndjsonContentType      = "application/x-ndjson"
+)
+
+func (h *Handler) streamEvents(c *gin.Context) {
+	var params dto.GetEventsStreamParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid query parameters in event stream")
+		respondBindError(c, apperrors.ErrInvalidQueryParameters, err)
+		return
+	}
+
+	filter, ok := h.bindEventFilter(c, params)
+	if !ok {
+		return
+	}
+
+	lastEventID, err := parseLastEventID(c.GetHeader(lastEventIDHeader), params.LastEventId)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("last_event_id", c.GetHeader(lastEventIDHeader)).Msg("Invalid Last-Event-ID")
+
+		respondError(c, err)
+		return
+	}
+
+	events, err := h.eventService.Subscribe(c.Request.Context(), filter, lastEventID)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Msg("Failed to subscribe to PVZ events")
+
+		respondError(c, err)
+		return
+	}
+
+	format := c.NegotiateFormat(eventStreamContentType, ndjsonContentType)
+	if format == "" {
+		format = eventStreamContentType
+	}
+
+	// Лента открыта, пока клиент не отключится, общий WriteTimeout к ней неприменим
+	h.clearWriteDeadline(c)
+
+	c.Header("Content-Type", format)
+	c.Header("Cache-Control", "no-cache")
+	// Иначе nginx буферизует ответ и события доходят пачками
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.config.Events.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	startTime := time.Now()
+	sent := 0
+
+	defer func() {
+		zerolog.Ctx(c.Request.Context()).Debug().
+			Int("events", sent).
+			Dur("duration", time.Since(startTime)).
+			Msg("PVZ event stream closed")
+	}()
+
+	for {
+		var err error
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err = writeEvent(c.Writer, format, toPVZEventDTO(event)); err == nil {
+				sent++
+			}
+		case <-heartbeat.C:
+			err = writeHeartbeat(c.Writer, format)
+		}
+
+		if err != nil {
+			zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Failed to write PVZ event stream")
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// bindEventFilter собирает фильтр ленты из параметров. Ключу, которому доступны только
+// отдельные ПВЗ, нельзя запросить другие ПВЗ, а без pvzId он получает события своих ПВЗ.
+func (h *Handler) bindEventFilter(c *gin.Context, params dto.GetEventsStreamParams) (models.EventFilter, bool) {
+	var filter models.EventFilter
+
+	if params.PvzId != nil {
+		for _, value := range *params.PvzId {
+			// Формат UUID уже проверен при разборе параметров
+			pvzID := uuid.MustParse(value)
+			if !h.authorizePVZ(c, pvzID) {
+				return models.EventFilter{}, false
+			}
+			filter.PVZIDs = append(filter.PVZIDs, pvzID)
+		}
+	}
+	if params.City != nil {
+		for _, city := range *params.City {
+			filter.Cities = append(filter.Cities, string(city))
+		}
+	}
+	if key, ok := getAPIKey(c); ok && len(key.PVZIDs) > 0 && len(filter.PVZIDs) == 0 {
+		filter.PVZIDs = key.PVZIDs
+	}
+
+	if err := filter.Validate(); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid event stream filter")
+
+		respondError(c, err)
+		return models.EventFilter{}, false
+	}
+
+	return filter, true
+}
+
+// parseLastEventID возвращает ID, с которого продолжается лента. Заголовок важнее
+// параметра: при переподключении EventSource передает в нем актуальное значение, а
+// параметр остается от первого запроса.
+func parseLastEventID(header string, param *int64) (int64, error) {
+	if header != "" {
+		id, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || id < 0 {
+			return 0, apperrors.ErrInvalidLastEventID
+		}
+		return id, nil
+	}
+
+	if param != nil {
+		return *param, nil
+	}
+
+	return 0, nil
+}
+
+func writeEvent(w io.Writer, format string, event dto.PVZEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode PVZ event: %w", err)
+	}
+
+	if format == ndjsonContentType {
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+	return err
+}
+
+// writeHeartbeat не дает прокси закрыть соединение без событий. Клиенты игнорируют
+// комментарии SSE и пустые строки NDJSON.
+func writeHeartbeat(w io.Writer, format string) error {
+	if format == ndjsonContentType {
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+
+	_, err := io.WriteString(w, ": ping\n\n")
+	return err
+}
+
+// clearWriteDeadline снимает срок записи ответа для бессрочной ленты.
+func (h *Handler) clearWriteDeadline(c *gin.Context) {
+	err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		zerolog.Ctx(c.Request.Context()).Warn().Err(err).Msg("Failed to clear write deadline for event stream")
+	}
+}
+
+func toPVZEventDTO(event *models.PVZEvent) dto.PVZEvent {
+	result := dto.PVZEvent{
+		Id:          event.ID,
+		Type:        dto.PVZEventType(event.Type),
+		PvzId:       event.PVZID,
+		City:        dto.PVZEventCity(event.City),
+		ReceptionId: event.ReceptionID,
+		ProductId:   event.ProductID,
+		CreatedAt:   event.CreatedAt,
+	}
+
+	if event.ProductType != "" {
+		productType := dto.PVZEventProductType(event.ProductType)
+		result.ProductType = &productType
+	}
+
+	return result
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_streamEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEventService := mocks.NewMockEventServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockEventService, nil,
+		&config.Config{Events: config.EventsConfig{HeartbeatInterval: time.Hour}})
+
+	pvzID := uuid.MustParse("7f1c2b4e-9a3d-4c5e-8f6a-1b2c3d4e5f60")
+	receptionID := uuid.MustParse("0b8f4d2a-6c1e-4f3a-9d5b-2e7c8a1f3b40")
+	createdAt := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	event := &models.PVZEvent{
+		ID:          42,
+		Type:        models.EventTypeReceptionOpened,
+		PVZID:       pvzID,
+		City:        models.CityMoscow,
+		ReceptionID: receptionID,
+		CreatedAt:   createdAt,
+	}
+	eventJSON := `{"city":"Москва","createdAt":"2025-04-10T12:00:00Z","id":42,"pvzId":"7f1c2b4e-9a3d-4c5e-8f6a-1b2c3d4e5f60",` +
+		`"receptionId":"0b8f4d2a-6c1e-4f3a-9d5b-2e7c8a1f3b40","type":"reception.opened"}`
+
+	// streamOf возвращает закрытый канал с событиями, чтобы обработчик завершился
+	streamOf := func(events ...*models.PVZEvent) <-chan *models.PVZEvent {
+		ch := make(chan *models.PVZEvent, len(events))
+		for _, e := range events {
+			ch <- e
+		}
+		close(ch)
+		return ch
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		headers             map[string]string
+		apiKey              *models.APIKey
+		setupMocks          func()
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:    "SSE stream resumed from Last-Event-ID header",
+			query:   "?city=Москва&lastEventId=5",
+			headers: map[string]string{"Last-Event-ID": "41"},
+			setupMocks: func() {
+				mockEventService.EXPECT().Subscribe(gomock.Any(), models.EventFilter{Cities: []string{models.CityMoscow}}, int64(41)).
+					Return(streamOf(event), nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/event-stream",
+			expectedBody:        "id: 42\nevent: reception.opened\ndata: " + eventJSON + "\n\n",
+		},
+		{
+			name:    "NDJSON stream limited to API key PVZ",
+			headers: map[string]string{"Accept": "application/x-ndjson"},
+			apiKey:  &models.APIKey{PVZIDs: []uuid.UUID{pvzID}},
+			setupMocks: func() {
+				mockEventService.EXPECT().Subscribe(gomock.Any(), models.EventFilter{PVZIDs: []uuid.UUID{pvzID}}, int64(0)).
+					Return(streamOf(event), nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedBody:        eventJSON + "\n",
+		},
+		{
+			name:           "API key requests other PVZ",
+			query:          "?pvzId=" + uuid.NewString(),
+			apiKey:         &models.APIKey{ID: uuid.New(), PVZIDs: []uuid.UUID{pvzID}},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Invalid Last-Event-ID",
+			headers:        map[string]string{"Last-Event-ID": "abc"},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Invalid Last-Event-ID specified. Use the id of the last received event.","code":"invalid_last_event_id"}`,
+		},
+		{
+			name:           "Invalid PVZ ID",
+			query:          "?pvzId=not-a-uuid",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/events/stream"+tt.query, nil)
+			for key, value := range tt.headers {
+				c.Request.Header.Set(key, value)
+			}
+			if tt.apiKey != nil {
+				c.Set(string(apiKeyKey), tt.apiKey)
+			}
+
+			handler.streamEvents(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedContentType != "" {
+				assert.Equal(t, tt.expectedContentType, resp.Header().Get("Content-Type"))
+			}
+			if tt.expectedBody != "" {
+				if tt.expectedStatus == http.StatusOK {
+					assert.Equal(t, tt.expectedBody, resp.Body.String())
+				} else {
+					assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/pkg/export"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"net/http"
+	"time"
+)
+
+func (h *Handler) exportData(c *gin.Context) {
+	req, ok := h.bindExportRequest(c)
+	if !ok {
+		return
+	}
+
+	// Общий WriteTimeout сервера рассчитан на обычные ответы, выгрузке нужно больше времени
+	h.extendWriteDeadline(c, h.config.Export.StreamTimeout)
+
+	c.Header("Content-Type", export.ContentType(req.Format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", req.FileName(time.Now())))
+	c.Status(http.StatusOK)
+
+	startTime := time.Now()
+	rowCount, err := h.exportService.Export(c.Request.Context(), req, c.Writer)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("dataset", req.Dataset).Int("rows", rowCount).Msg("Export failed")
+
+		// Пока ничего не отправлено, клиент получает обычную ошибку; после начала
+		// передачи файла остается только оборвать ответ
+		if !c.Writer.Written() {
+			c.Header("Content-Disposition", "")
+			respondError(c, err)
+		}
+		return
+	}
+
+	zerolog.Ctx(c.Request.Context()).Info().
+		Str("dataset", req.Dataset).
+		Str("format", req.Format).
+		Int("rows", rowCount).
+		Dur("duration", time.Since(startTime)).
+		Msg("Export streamed")
+}
+
+func (h *Handler) startExportJob(c *gin.Context) {
+	req, ok := h.bindExportRequest(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.exportService.StartExportJob(c.Request.Context(), req)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("dataset", req.Dataset).Msg("Failed to start export job")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, toExportJobDTO(job))
+}
+
+func (h *Handler) getExportJob(c *gin.Context) {
+	jobID, ok := parseExportJobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.exportService.GetExportJob(c.Request.Context(), jobID)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("job_id", jobID.String()).Msg("Failed to get export job")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toExportJobDTO(job))
+}
+
+func (h *Handler) downloadExportFile(c *gin.Context) {
+	jobID, ok := parseExportJobID(c)
+	if !ok {
+		return
+	}
+
+	job, path, err := h.exportService.GetExportFile(c.Request.Context(), jobID)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("job_id", jobID.String()).Msg("Export file is not available")
+
+		respondError(c, err)
+		return
+	}
+
+	h.extendWriteDeadline(c, h.config.Export.StreamTimeout)
+
+	c.Header("Content-Type", export.ContentType(job.Request.Format))
+	c.FileAttachment(path, job.Request.FileName(job.CreatedAt))
+}
+
+// bindExportRequest собирает запрос выгрузки из пути и параметров. Ключу, которому
+// доступны только отдельные ПВЗ, выгружаются только они. При ошибке отвечает 400.
+func (h *Handler) bindExportRequest(c *gin.Context) (models.ExportRequest, bool) {
+	// У фоновой выгрузки те же параметры, что и у потоковой
+	var params dto.GetExportDatasetParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid query parameters in export")
+		respondBindError(c, apperrors.ErrInvalidQueryParameters, err)
+		return models.ExportRequest{}, false
+	}
+
+	req := models.ExportRequest{
+		Dataset: c.Param("dataset"),
+		Format:  models.ExportFormatCSV,
+		Filter: models.ExportFilter{
+			StartDate:        params.StartDate,
+			EndDate:          params.EndDate,
+			RegisteredFrom:   params.RegisteredFrom,
+			RegisteredTo:     params.RegisteredTo,
+			HasOpenReception: params.HasOpenReception,
+		},
+	}
+
+	if params.Format != nil {
+		req.Format = string(*params.Format)
+	}
+	if params.City != nil {
+		for _, city := range *params.City {
+			req.Filter.Cities = append(req.Filter.Cities, string(city))
+		}
+	}
+	if params.ReceptionStatus != nil {
+		req.Filter.ReceptionStatus = string(*params.ReceptionStatus)
+	}
+	if params.ProductType != nil {
+		req.Filter.ProductType = string(*params.ProductType)
+	}
+	if key, ok := getAPIKey(c); ok && len(key.PVZIDs) > 0 {
+		req.Filter.PVZIDs = key.PVZIDs
+	}
+
+	if err := req.Validate(); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid export request")
+
+		respondError(c, err)
+		return models.ExportRequest{}, false
+	}
+
+	return req, true
+}
+
+func parseExportJobID(c *gin.Context) (uuid.UUID, bool) {
+	jobIDParam := c.Param("jobId")
+	jobID, err := uuid.Parse(jobIDParam)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("job_id", jobIDParam).Msg("Invalid export job ID format")
+
+		respondError(c, apperrors.ErrInvalidExportJobID)
+		return uuid.Nil, false
+	}
+	return jobID, true
+}
+
+// extendWriteDeadline продлевает срок записи ответа для длинных выгрузок.
+func (h *Handler) extendWriteDeadline(c *gin.Context, timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(timeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		zerolog.Ctx(c.Request.Context()).Warn().Err(err).Msg("Failed to extend write deadline for export")
+	}
+}
+
+func toExportJobDTO(job *models.ExportJob) dto.ExportJob {
+	result := dto.ExportJob{
+		Id:         job.ID,
+		Dataset:    dto.ExportJobDataset(job.Request.Dataset),
+		Format:     dto.ExportJobFormat(job.Request.Format),
+		Status:     dto.ExportJobStatus(job.Status),
+		RowCount:   job.RowCount,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+		ExpiresAt:  job.ExpiresAt,
+	}
+
+	if job.Error != "" {
+		result.Error = &job.Error
+	}
+
+	return result
+}
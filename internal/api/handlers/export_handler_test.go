@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_exportData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExportService := mocks.NewMockExportServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, mockExportService, nil, nil, nil, &config.Config{})
+
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name                string
+		dataset             string
+		query               string
+		apiKey              *models.APIKey
+		setupMocks          func()
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+		expectAttachment    bool
+	}{
+		{
+			name:    "CSV stream with filter",
+			dataset: "receptions",
+			query:   "?city=Москва&receptionStatus=close",
+			setupMocks: func() {
+				mockExportService.EXPECT().Export(gomock.Any(), models.ExportRequest{
+					Dataset: models.ExportDatasetReceptions,
+					Format:  models.ExportFormatCSV,
+					Filter:  models.ExportFilter{Cities: []string{"Москва"}, ReceptionStatus: models.ReceptionStatusClosed},
+				}, gomock.Any()).DoAndReturn(func(_ context.Context, _ models.ExportRequest, w io.Writer) (int, error) {
+					_, err := io.WriteString(w, "id,pvzId\n")
+					return 0, err
+				})
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "id,pvzId\n",
+			expectAttachment:    true,
+		},
+		{
+			name:    "XLSX stream limited to API key PVZ",
+			dataset: "pvz",
+			query:   "?format=xlsx",
+			apiKey:  &models.APIKey{PVZIDs: []uuid.UUID{pvzID}},
+			setupMocks: func() {
+				mockExportService.EXPECT().Export(gomock.Any(), models.ExportRequest{
+					Dataset: models.ExportDatasetPVZ,
+					Format:  models.ExportFormatXLSX,
+					Filter:  models.ExportFilter{PVZIDs: []uuid.UUID{pvzID}},
+				}, gomock.Any()).Return(0, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			expectAttachment:    true,
+		},
+		{
+			name:           "Unknown dataset",
+			dataset:        "users",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Invalid export dataset specified. Available datasets: pvz, receptions, products.","code":"invalid_export_dataset"}`,
+		},
+		{
+			name:           "Unknown format",
+			dataset:        "pvz",
+			query:          "?format=pdf",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "Database error before first byte",
+			dataset: "products",
+			setupMocks: func() {
+				mockExportService.EXPECT().Export(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(0, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/export/"+tt.dataset+tt.query, nil)
+			c.Params = gin.Params{{Key: "dataset", Value: tt.dataset}}
+			if tt.apiKey != nil {
+				c.Set(string(apiKeyKey), tt.apiKey)
+			}
+
+			handler.exportData(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedContentType != "" {
+				assert.Equal(t, tt.expectedContentType, resp.Header().Get("Content-Type"))
+			}
+			if tt.expectedBody != "" {
+				if tt.expectedStatus == http.StatusOK {
+					assert.Equal(t, tt.expectedBody, resp.Body.String())
+				} else {
+					assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+				}
+			}
+			assert.Equal(t, tt.expectAttachment, resp.Header().Get("Content-Disposition") != "")
+		})
+	}
+}
+
+func TestHandler_startExportJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExportService := mocks.NewMockExportServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, mockExportService, nil, nil, nil, &config.Config{})
+
+	job := &models.ExportJob{
+		ID:        uuid.New(),
+		Request:   models.ExportRequest{Dataset: models.ExportDatasetProducts, Format: models.ExportFormatXLSX},
+		Status:    models.ExportJobStatusPending,
+		CreatedAt: time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC),
+	}
+
+	mockExportService.EXPECT().StartExportJob(gomock.Any(), models.ExportRequest{
+		Dataset: models.ExportDatasetProducts,
+		Format:  models.ExportFormatXLSX,
+		Filter:  models.ExportFilter{ProductType: models.ProductTypeShoes},
+	}).Return(job, nil)
+
+	resp := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(resp)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/export/products/jobs?format=xlsx&productType=обувь", nil)
+	c.Params = gin.Params{{Key: "dataset", Value: "products"}}
+
+	handler.startExportJob(c)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.JSONEq(t, `{"id":"`+job.ID.String()+`","dataset":"products","format":"xlsx","status":"pending","rowCount":0,"createdAt":"2025-04-10T12:00:00Z"}`,
+		resp.Body.String())
+}
+
+func TestHandler_getExportJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExportService := mocks.NewMockExportServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, mockExportService, nil, nil, nil, &config.Config{})
+
+	jobID := uuid.New()
+	finishedAt := time.Date(2025, 4, 10, 12, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		jobID          string
+		setupMocks     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Failed job with error",
+			jobID: jobID.String(),
+			setupMocks: func() {
+				mockExportService.EXPECT().GetExportJob(gomock.Any(), jobID).Return(&models.ExportJob{
+					ID:         jobID,
+					Request:    models.ExportRequest{Dataset: models.ExportDatasetPVZ, Format: models.ExportFormatCSV},
+					Status:     models.ExportJobStatusFailed,
+					RowCount:   7,
+					Error:      "database error",
+					CreatedAt:  finishedAt.Add(-5 * time.Minute),
+					FinishedAt: &finishedAt,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"id":"` + jobID.String() + `","dataset":"pvz","format":"csv","status":"failed","rowCount":7,` +
+				`"error":"database error","createdAt":"2025-04-10T12:00:00Z","finishedAt":"2025-04-10T12:05:00Z"}`,
+		},
+		{
+			name:  "Job not found",
+			jobID: jobID.String(),
+			setupMocks: func() {
+				mockExportService.EXPECT().GetExportJob(gomock.Any(), jobID).Return(nil, repoerrors.ErrExportJobNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid job ID",
+			jobID:          "not-a-uuid",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/export/jobs/"+tt.jobID, nil)
+			c.Params = gin.Params{{Key: "jobId", Value: tt.jobID}}
+
+			handler.getExportJob(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_downloadExportFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExportService := mocks.NewMockExportServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, mockExportService, nil, nil, nil, &config.Config{})
+
+	jobID := uuid.New()
+	path := filepath.Join(t.TempDir(), jobID.String()+".csv")
+	if err := os.WriteFile(path, []byte("id\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	job := &models.ExportJob{
+		ID:        jobID,
+		Request:   models.ExportRequest{Dataset: models.ExportDatasetPVZ, Format: models.ExportFormatCSV},
+		Status:    models.ExportJobStatusDone,
+		CreatedAt: time.Date(2025, 4, 10, 12, 30, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		setupMocks     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Ready file",
+			setupMocks: func() {
+				mockExportService.EXPECT().GetExportFile(gomock.Any(), jobID).Return(job, path, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "id\n",
+		},
+		{
+			name: "Job still running",
+			setupMocks: func() {
+				mockExportService.EXPECT().GetExportFile(gomock.Any(), jobID).Return(nil, "", apperrors.ErrExportJobNotReady)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "File expired",
+			setupMocks: func() {
+				mockExportService.EXPECT().GetExportFile(gomock.Any(), jobID).Return(nil, "", apperrors.ErrExportJobExpired)
+			},
+			expectedStatus: http.StatusGone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/export/jobs/"+jobID.String()+"/file", nil)
+			c.Params = gin.Params{{Key: "jobId", Value: jobID.String()}}
+
+			handler.downloadExportFile(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, resp.Body.String())
+				assert.Contains(t, resp.Header().Get("Content-Disposition"), "pvz-20250410-123000.csv")
+			}
+		})
+	}
+}
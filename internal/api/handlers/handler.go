@@ -3,11 +3,20 @@ package handlers
 import (
 	"avito-backend-trainee-assignment-spring-2025/internal/auth"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
-	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/i18n"
 	"avito-backend-trainee-assignment-spring-2025/pkg/config"
 	"avito-backend-trainee-assignment-spring-2025/pkg/metrics"
-	"errors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
 	"strings"
 	"time"
@@ -15,68 +24,38 @@ import (
 
 //go:generate oapi-codegen -config ../../../oapi-codegen.yaml ../../../swagger.yaml
 
-var userFriendlyErrors = map[error]string{
-	apperrors.ErrInvalidCredentials:        "Invalid email or password. Please check your credentials.",
-	apperrors.ErrEmailRequired:             "Email is required for registration.",
-	apperrors.ErrInvalidEmail:              "Invalid email format specified.",
-	apperrors.ErrPasswordRequired:          "Password is required for registration.",
-	apperrors.ErrInvalidPassword:           "Password must contain at least 6 characters.",
-	apperrors.ErrInvalidRole:               "Invalid role specified. Available roles: employee, moderator.",
-	apperrors.ErrCityRequired:              "City is required to create a pickup point.",
-	apperrors.ErrInvalidCity:               "Pickup points can only be created in the following cities: Moscow, Saint Petersburg, Kazan.",
-	apperrors.ErrInvalidPVZID:              "Invalid pickup point ID specified.",
-	apperrors.ErrReceptionAlreadyClosed:    "This reception is already closed.",
-	apperrors.ErrReceptionCannotBeModified: "Closed reception cannot be modified.",
-	apperrors.ErrActiveReceptionExists:     "Cannot create a new reception while the previous one is not closed.",
-	apperrors.ErrNoActiveReception:         "No active reception for this pickup point.",
-	apperrors.ErrInvalidReceptionID:        "Invalid reception ID specified.",
-	apperrors.ErrProductTypeRequired:       "Product type is required.",
-	apperrors.ErrInvalidProductType:        "Invalid product type specified. Available types: electronics, clothes, shoes.",
-	apperrors.ErrInvalidProductID:          "Invalid product ID specified.",
-	apperrors.ErrNoProductsToDelete:        "No products to delete in the current reception.",
-	repoerrors.ErrPVZNotFound:              "Pickup point not found.",
-	repoerrors.ErrReceptionNotFound:        "Reception not found.",
-	repoerrors.ErrProductNotFound:          "Product not found.",
-	repoerrors.ErrUserNotFound:             "User not found.",
-	repoerrors.ErrUserAlreadyExists:        "User with this email already exists.",
-	repoerrors.ErrPVZAlreadyExists:         "Pickup point with this ID already exists.",
-}
-
-var errorStatusCodes = map[error]int{
-	repoerrors.ErrPVZNotFound:              http.StatusNotFound,
-	repoerrors.ErrProductNotFound:          http.StatusNotFound,
-	repoerrors.ErrReceptionNotFound:        http.StatusNotFound,
-	repoerrors.ErrUserNotFound:             http.StatusNotFound,
-	apperrors.ErrInvalidEmail:              http.StatusBadRequest,
-	apperrors.ErrInvalidPassword:           http.StatusBadRequest,
-	apperrors.ErrInvalidRole:               http.StatusBadRequest,
-	apperrors.ErrInvalidCity:               http.StatusBadRequest,
-	apperrors.ErrCityRequired:              http.StatusBadRequest,
-	apperrors.ErrInvalidProductType:        http.StatusBadRequest,
-	apperrors.ErrProductTypeRequired:       http.StatusBadRequest,
-	apperrors.ErrActiveReceptionExists:     http.StatusBadRequest,
-	apperrors.ErrNoActiveReception:         http.StatusBadRequest,
-	apperrors.ErrReceptionAlreadyClosed:    http.StatusBadRequest,
-	apperrors.ErrReceptionCannotBeModified: http.StatusBadRequest,
-	apperrors.ErrNoProductsToDelete:        http.StatusBadRequest,
-	apperrors.ErrInvalidCredentials:        http.StatusUnauthorized,
-	repoerrors.ErrUserAlreadyExists:        http.StatusConflict,
-	repoerrors.ErrPVZAlreadyExists:         http.StatusConflict,
-}
-
 type contextKey string
 
 const (
 	userIDKey   contextKey = "user_id"
 	userRoleKey contextKey = "user_role"
+	userMFAKey  contextKey = "user_mfa"
+	apiKeyKey   contextKey = "api_key"
+)
+
+const (
+	// apiKeyHeader - заголовок, в котором внешние системы передают API-ключ вместо JWT.
+	apiKeyHeader = "X-API-Key"
+	// requestIDHeader связывает запрос клиента с записями журнала аудита и логами.
+	requestIDHeader = "X-Request-ID"
+	// unmatchedRoute заменяет шаблон маршрута для запросов, не попавших ни в один маршрут.
+	unmatchedRoute = "unmatched"
 )
 
 type Handler struct {
-	userService      UserServiceInterface
-	pvzService       PVZServiceInterface
-	receptionService ReceptionServiceInterface
-	productService   ProductServiceInterface
-	config           *config.Config
+	userService           UserServiceInterface
+	pvzService            PVZServiceInterface
+	receptionService      ReceptionServiceInterface
+	productService        ProductServiceInterface
+	passwordService       PasswordServiceInterface
+	mfaService            MFAServiceInterface
+	serviceAccountService ServiceAccountServiceInterface
+	auditService          AuditServiceInterface
+	exportService         ExportServiceInterface
+	importService         ImportServiceInterface
+	eventService          EventServiceInterface
+	idempotencyService    IdempotencyServiceInterface
+	config                *config.Config
 }
 
 func NewHandler(
@@ -84,14 +63,30 @@ func NewHandler(
 	pvzService PVZServiceInterface,
 	receptionService ReceptionServiceInterface,
 	productService ProductServiceInterface,
+	passwordService PasswordServiceInterface,
+	mfaService MFAServiceInterface,
+	serviceAccountService ServiceAccountServiceInterface,
+	auditService AuditServiceInterface,
+	exportService ExportServiceInterface,
+	importService ImportServiceInterface,
+	eventService EventServiceInterface,
+	idempotencyService IdempotencyServiceInterface,
 	config *config.Config,
 ) *Handler {
 	return &Handler{
-		userService:      userService,
-		pvzService:       pvzService,
-		receptionService: receptionService,
-		productService:   productService,
-		config:           config,
+		userService:           userService,
+		pvzService:            pvzService,
+		receptionService:      receptionService,
+		productService:        productService,
+		passwordService:       passwordService,
+		mfaService:            mfaService,
+		serviceAccountService: serviceAccountService,
+		auditService:          auditService,
+		exportService:         exportService,
+		importService:         importService,
+		eventService:          eventService,
+		idempotencyService:    idempotencyService,
+		config:                config,
 	}
 }
 
@@ -104,121 +99,396 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
+	router.Use(tracingMiddleware())
+	router.Use(h.actorMiddleware())
+	router.Use(accessLogMiddleware())
 	router.Use(h.metricsMiddleware())
+	router.Use(gin.CustomRecovery(recoverProblem))
+	router.Use(languageMiddleware())
 
-	router.POST("/dummyLogin", h.dummyLogin)
+	if h.dummyLoginAllowed() {
+		router.POST("/dummyLogin", h.dummyLogin)
+	}
 	router.POST("/register", h.register)
 	router.POST("/login", h.login)
+	router.POST("/login/mfa", h.loginMFA)
+	router.POST("/password/reset", h.requestPasswordReset)
+	router.POST("/password/reset/confirm", h.confirmPasswordReset)
 
 	authorized := router.Group("/")
 	authorized.Use(h.authMiddleware())
 
-	moderatorRoutes := authorized.Group("/")
+	authorized.POST("/me/mfa/totp", h.enrollTOTP)
+	authorized.POST("/me/mfa/totp/confirm", h.confirmTOTP)
+	authorized.POST("/me/mfa/totp/disable", h.disableTOTP)
+
+	// Остальные маршруты недоступны привилегированным ролям без 2FA, если этого требует политика
+	protected := authorized.Group("/")
+	protected.Use(h.mfaPolicyMiddleware())
+
+	protected.POST("/me/password", h.changePassword)
+	protected.PUT("/me/language", h.setLanguage)
+
+	// Повтор изменяющего запроса с тем же Idempotency-Key получает первый ответ. Маршруты,
+	// ответы которых содержат секреты (ключи, коды 2FA), ответы не сохраняют
+	idempotent := h.idempotencyMiddleware()
+
+	moderatorRoutes := protected.Group("/")
 	moderatorRoutes.Use(h.roleMiddleware("moderator"))
 	{
-		moderatorRoutes.POST("/pvz", h.createPVZ)
+		moderatorRoutes.POST("/pvz", idempotent, h.createPVZ)
+		moderatorRoutes.PATCH("/pvz/:pvzId", idempotent, h.updatePVZ)
+		moderatorRoutes.GET("/audit-log", h.getAuditLog)
+		moderatorRoutes.GET("/audit-log/verify", h.verifyAuditLog)
+		moderatorRoutes.GET("/reports/receptions", h.getReceptionReport)
+		moderatorRoutes.POST("/import/:dataset", h.importData)
 	}
 
-	authorized.GET("/pvz", h.getPVZList)
+	protected.GET("/pvz", h.scopeMiddleware(models.ScopePVZRead), h.getPVZList)
+	protected.GET("/pvz/nearby", h.scopeMiddleware(models.ScopePVZRead), h.getNearbyPVZ)
+	protected.GET("/pvz/utilization", h.scopeMiddleware(models.ScopePVZRead), h.getPVZUtilization)
+	protected.GET("/pvz/:pvzId", h.scopeMiddleware(models.ScopePVZRead), h.getPVZ)
+	protected.GET("/pvz/:pvzId/receptions", h.scopeMiddleware(models.ScopePVZRead), h.getPVZReceptions)
+	protected.GET("/pvz/:pvzId/receptions/current", h.scopeMiddleware(models.ScopePVZRead), h.getCurrentReception)
+	protected.GET("/receptions/:receptionId", h.scopeMiddleware(models.ScopePVZRead), h.getReception)
+	protected.GET("/products/:productId", h.scopeMiddleware(models.ScopePVZRead), h.getProduct)
+	protected.GET("/export/:dataset", h.scopeMiddleware(models.ScopePVZRead), h.exportData)
+	protected.POST("/export/:dataset/jobs", h.scopeMiddleware(models.ScopePVZRead), idempotent, h.startExportJob)
+	protected.GET("/export/jobs/:jobId", h.scopeMiddleware(models.ScopePVZRead), h.getExportJob)
+	protected.GET("/export/jobs/:jobId/file", h.scopeMiddleware(models.ScopePVZRead), h.downloadExportFile)
+	protected.GET("/events/stream", h.scopeMiddleware(models.ScopePVZRead), h.streamEvents)
+
+	receptionRoutes := protected.Group("/")
+	receptionRoutes.Use(h.roleMiddleware("employee", models.ScopeReceptionsWrite))
+	{
+		receptionRoutes.POST("/receptions", idempotent, h.createReception)
+		receptionRoutes.POST("/pvz/:pvzId/close_last_reception", idempotent, h.closeReception)
+	}
 
-	employeeRoutes := authorized.Group("/")
-	employeeRoutes.Use(h.roleMiddleware("employee"))
+	productRoutes := protected.Group("/")
+	productRoutes.Use(h.roleMiddleware("employee", models.ScopeProductsWrite))
 	{
-		employeeRoutes.POST("/receptions", h.createReception)
-		employeeRoutes.POST("/pvz/:pvzId/close_last_reception", h.closeReception)
-		employeeRoutes.POST("/products", h.addProduct)
-		employeeRoutes.POST("/pvz/:pvzId/delete_last_product", h.deleteLastProduct)
+		productRoutes.POST("/products", idempotent, h.addProduct)
+		productRoutes.POST("/pvz/:pvzId/delete_last_product", idempotent, h.deleteLastProduct)
+	}
+
+	adminRoutes := protected.Group("/admin")
+	adminRoutes.Use(h.roleMiddleware("admin"))
+	{
+		adminRoutes.POST("/service-accounts", idempotent, h.createServiceAccount)
+		adminRoutes.GET("/service-accounts", h.listServiceAccounts)
+		adminRoutes.POST("/service-accounts/:serviceAccountId/keys", h.issueAPIKey)
+		adminRoutes.GET("/service-accounts/:serviceAccountId/keys", h.listAPIKeys)
+		adminRoutes.DELETE("/api-keys/:keyId", idempotent, h.revokeAPIKey)
 	}
 
 	return router
 }
 
-func getUserFriendlyError(err error) string {
-	if friendlyMsg, exists := userFriendlyErrors[err]; exists {
-		return friendlyMsg
+// dummyLoginAllowed сообщает, можно ли выдавать тестовые токены. В production
+// вход запрещен даже при включенном флаге.
+func (h *Handler) dummyLoginAllowed() bool {
+	return h.config.DummyLogin.Enabled && h.config.Server.AppEnv != "production"
+}
+
+// metricsMiddleware считает запросы по шаблону маршрута и классу статуса. Запросы, не
+// попавшие ни в один маршрут, учитываются под unmatchedRoute, чтобы сканеры путей не
+// порождали новые ряды.
+func (h *Handler) metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		method := c.Request.Method
+		route := routeName(c)
+
+		inFlight := metrics.RequestsInFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		c.Next()
+
+		duration := time.Since(start).Seconds()
+		status := metrics.StatusClass(c.Writer.Status())
+
+		metrics.RequestsTotal.WithLabelValues(method, route, status).Inc()
+		metrics.RequestDuration.WithLabelValues(method, route, status).Observe(duration)
+		metrics.RequestSize.WithLabelValues(method, route).Observe(float64(max(c.Request.ContentLength, 0)))
+		metrics.ResponseSize.WithLabelValues(method, route).Observe(float64(max(c.Writer.Size(), 0)))
 	}
+}
 
-	for wrappedErr, msg := range userFriendlyErrors {
-		if errors.Is(err, wrappedErr) {
-			return msg
+// tracingMiddleware начинает серверный спан запроса, продолжая трассу из заголовков
+// traceparent и tracestate. Спан называется по шаблону маршрута, а не по пути, чтобы
+// запросы к разным ПВЗ группировались вместе.
+func tracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := routeName(c)
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
 		}
 	}
+}
 
-	return err.Error()
+// routeName возвращает шаблон маршрута запроса или unmatchedRoute.
+func routeName(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return unmatchedRoute
 }
 
-func getErrorResponse(err error) (int, string) {
-	statusCode := http.StatusInternalServerError
+// actorMiddleware кладет в контекст запроса IP клиента и идентификатор запроса для
+// журнала аудита, а также логгер с идентификаторами запроса и трассы, через который
+// пишут логи сервисы и репозитории. Пользователя добавляет authMiddleware.
+func (h *Handler) actorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = uuid.NewString()
+		}
+		c.Header(requestIDHeader, requestID)
 
-	if code, exists := errorStatusCodes[err]; exists {
-		statusCode = code
-	} else {
-		for knownErr, code := range errorStatusCodes {
-			if errors.Is(err, knownErr) {
-				statusCode = code
-				break
-			}
+		logContext := log.With().Str("request_id", requestID)
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.IsValid() {
+			logContext = logContext.Str("trace_id", spanContext.TraceID().String())
 		}
-	}
 
-	message := getUserFriendlyError(err)
+		logger := logContext.Logger()
+		ctx := models.WithActor(logger.WithContext(c.Request.Context()), models.Actor{
+			ClientIP:  c.ClientIP(),
+			RequestID: requestID,
+		})
+		c.Request = c.Request.WithContext(ctx)
 
-	return statusCode, message
+		c.Next()
+	}
 }
 
-func (h *Handler) metricsMiddleware() gin.HandlerFunc {
+// accessLogMiddleware пишет по строке на каждый запрос. Логгер берется из контекста
+// после обработки, поэтому строка содержит и пользователя, если запрос аутентифицирован.
+func accessLogMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
 		c.Next()
 
-		duration := time.Since(start).Seconds()
 		status := c.Writer.Status()
+		event := zerolog.Ctx(c.Request.Context()).Info()
+		if status >= http.StatusInternalServerError {
+			event = zerolog.Ctx(c.Request.Context()).Error()
+		}
+
+		event.
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Str("route", c.FullPath()).
+			Int("status", status).
+			Int("bytes", c.Writer.Size()).
+			Dur("duration", time.Since(start)).
+			Str("client_ip", c.ClientIP()).
+			Str("user_agent", c.Request.UserAgent()).
+			Msg("HTTP request")
+	}
+}
+
+// languageMiddleware выбирает язык сообщений по заголовку Accept-Language. Язык из
+// профиля пользователя подставляет authMiddleware.
+func languageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), lang))
+
+		c.Next()
+	}
+}
 
-		metrics.RequestsTotal.WithLabelValues(c.Request.Method, c.FullPath(), string(rune(status))).Inc()
-		metrics.RequestDuration.WithLabelValues(c.Request.Method, c.FullPath()).Observe(duration)
+func setActor(c *gin.Context, id uuid.UUID, role string) {
+	actor := models.ActorFromContext(c.Request.Context())
+	actor.ID = id
+	actor.Role = role
+	if actor.ClientIP == "" {
+		actor.ClientIP = c.ClientIP()
 	}
+
+	logger := zerolog.Ctx(c.Request.Context()).With().
+		Str("user_id", id.String()).
+		Str("role", role).
+		Logger()
+	ctx := logger.WithContext(c.Request.Context())
+	c.Request = c.Request.WithContext(models.WithActor(ctx, actor))
 }
 
 func (h *Handler) authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := c.GetHeader(apiKeyHeader); rawKey != "" {
+			h.authenticateAPIKey(c, rawKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Authorization header is required"})
+			abortWithError(c, apperrors.ErrAuthorizationRequired)
 			return
 		}
 
 		bearerToken := strings.Split(authHeader, " ")
 		if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid authorization format"})
+			abortWithError(c, apperrors.ErrInvalidAuthorization)
 			return
 		}
 
 		claims, err := auth.ValidateToken(bearerToken[1], h.config.JWT.Secret)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+			zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid token")
+			abortWithError(c, apperrors.ErrInvalidToken)
+			return
+		}
+
+		if claims.Dummy && !h.config.DummyLogin.AcceptTokens {
+			abortWithError(c, apperrors.ErrDummyTokenRejected)
+			return
+		}
+
+		if err := h.userService.CheckAccessToken(c.Request.Context(), claims); err != nil {
+			abortWithError(c, err)
 			return
 		}
 
 		c.Set(string(userIDKey), claims.UserID)
 		c.Set(string(userRoleKey), claims.Role)
+		c.Set(string(userMFAKey), claims.MFA)
+		setActor(c, claims.UserID, claims.Role)
+		if i18n.Supported(claims.Locale) {
+			c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), claims.Locale))
+		}
 
 		c.Next()
 	}
 }
 
-func (h *Handler) roleMiddleware(requiredRole string) gin.HandlerFunc {
+// authenticateAPIKey пропускает запрос от имени сервисной учетной записи. Права
+// такого запроса определяются областями действия ключа, а не ролью.
+func (h *Handler) authenticateAPIKey(c *gin.Context, rawKey string) {
+	key, err := h.serviceAccountService.Authenticate(c.Request.Context(), rawKey)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Set(string(userIDKey), key.ServiceAccountID)
+	c.Set(string(userRoleKey), models.RoleServiceAccount)
+	c.Set(string(apiKeyKey), key)
+	setActor(c, key.ServiceAccountID, models.RoleServiceAccount)
+
+	c.Next()
+}
+
+func getAPIKey(c *gin.Context) (*models.APIKey, bool) {
+	value, exists := c.Get(string(apiKeyKey))
+	if !exists {
+		return nil, false
+	}
+	key, ok := value.(*models.APIKey)
+	return key, ok
+}
+
+// hasScopes сообщает, выданы ли ключу все перечисленные области. Без областей
+// доступ по ключу запрещен.
+func hasScopes(key *models.APIKey, scopes []string) bool {
+	if len(scopes) == 0 {
+		return false
+	}
+	for _, scope := range scopes {
+		if !key.HasScope(scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// authorizePVZ проверяет, что API-ключ (если запрос выполнен по нему) допускает работу
+// с указанным ПВЗ. При отказе отвечает 403 и возвращает false.
+func (h *Handler) authorizePVZ(c *gin.Context, pvzID uuid.UUID) bool {
+	key, ok := getAPIKey(c)
+	if !ok || key.CanAccessPVZ(pvzID) {
+		return true
+	}
+
+	zerolog.Ctx(c.Request.Context()).Info().
+		Str("api_key_id", key.ID.String()).
+		Str("pvz_id", pvzID.String()).
+		Msg("API key is not allowed to access PVZ")
+
+	respondError(c, apperrors.ErrPVZAccessForbidden)
+	return false
+}
+
+func (h *Handler) mfaPolicyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.config.MFA.RequiredForPrivileged || !models.IsPrivilegedRole(c.GetString(string(userRoleKey))) {
+			c.Next()
+			return
+		}
+
+		if !c.GetBool(string(userMFAKey)) {
+			abortWithError(c, apperrors.ErrMFARequired)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// roleMiddleware пускает пользователей с ролью requiredRole, а запросы по API-ключу -
+// только при наличии у ключа всех областей scopes.
+func (h *Handler) roleMiddleware(requiredRole string, scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if key, ok := getAPIKey(c); ok {
+			if !hasScopes(key, scopes) {
+				abortWithError(c, apperrors.ErrInsufficientScope)
+				return
+			}
+			c.Next()
+			return
+		}
+
 		role, exists := c.Get(string(userRoleKey))
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "User role not found"})
+			abortWithError(c, apperrors.ErrInsufficientPermissions)
 			return
 		}
 
 		if role.(string) != requiredRole {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Insufficient permissions"})
+			abortWithError(c, apperrors.ErrInsufficientPermissions)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// scopeMiddleware ограничивает только запросы по API-ключу, пользователи проходят без проверки.
+func (h *Handler) scopeMiddleware(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key, ok := getAPIKey(c); ok && !hasScopes(key, scopes) {
+			abortWithError(c, apperrors.ErrInsufficientScope)
 			return
 		}
 
@@ -3,20 +3,25 @@ package handlers
 import (
 	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
 	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/auth"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
 	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/metrics"
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	prommodel "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -38,6 +43,14 @@ func TestHandler_addProduct(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
@@ -67,7 +80,7 @@ func TestHandler_addProduct(t *testing.T) {
 						DateTime:    now,
 						Type:        "электроника",
 						ReceptionID: receptionID,
-					}, nil)
+					}, nil, nil)
 			},
 			expectedStatus: http.StatusCreated,
 			expectedBody: map[string]interface{}{
@@ -86,11 +99,53 @@ func TestHandler_addProduct(t *testing.T) {
 			setupMocks: func() {
 				mockProductService.EXPECT().
 					AddProduct(gomock.Any(), "электроника", pvzID).
-					Return(nil, apperrors.ErrNoActiveReception)
+					Return(nil, nil, apperrors.ErrNoActiveReception)
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"message": "No active reception for this pickup point.",
+				"code": "no_active_reception",
+			},
+		},
+		{
+			name: "Capacity exceeded",
+			requestBody: map[string]interface{}{
+				"type":  "электроника",
+				"pvzId": pvzID.String(),
+			},
+			setupMocks: func() {
+				mockProductService.EXPECT().
+					AddProduct(gomock.Any(), "электроника", pvzID).
+					Return(nil, nil, apperrors.ErrCapacityExceeded)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody: map[string]interface{}{
+				"code": "capacity_exceeded",
+			},
+		},
+		{
+			name: "Capacity warning",
+			requestBody: map[string]interface{}{
+				"type":  "электроника",
+				"pvzId": pvzID.String(),
+			},
+			setupMocks: func() {
+				mockProductService.EXPECT().
+					AddProduct(gomock.Any(), "электроника", pvzID).
+					Return(&models.Product{
+						ID:          productID,
+						DateTime:    now,
+						Type:        "электроника",
+						ReceptionID: receptionID,
+					}, []models.CapacityUsage{{Scope: models.CapacityScopeTotal, Occupied: 9, Capacity: 10}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody: map[string]interface{}{
+				"id":          productID.String(),
+				"type":        "электроника",
+				"receptionId": receptionID.String(),
+				"capacityWarnings": []interface{}{map[string]interface{}{
+					"scope": "total", "occupied": float64(9), "capacity": float64(10), "utilization": 0.9,
+				}},
 			},
 		},
 	}
@@ -119,9 +174,11 @@ func TestHandler_addProduct(t *testing.T) {
 				assert.Equal(t, tt.expectedBody["id"], responseBody["id"])
 				assert.Equal(t, tt.expectedBody["type"], responseBody["type"])
 				assert.Equal(t, tt.expectedBody["receptionId"], responseBody["receptionId"])
+				assert.Equal(t, tt.expectedBody["capacityWarnings"], responseBody["capacityWarnings"])
 				assert.NotEmpty(t, responseBody["dateTime"])
 			} else {
 				assert.Equal(t, tt.expectedBody["message"], responseBody["message"])
+				assert.Equal(t, tt.expectedBody["code"], responseBody["code"])
 			}
 		})
 	}
@@ -144,31 +201,45 @@ func TestHandler_closeReception(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 	now := time.Now()
+	ifMatch := models.EntityTag{ID: receptionID, Version: 3}.String()
+	precondition := models.ParsePrecondition(ifMatch)
 
 	tests := []struct {
 		name           string
 		pvzIDParam     string
+		ifMatch        string
 		setupMocks     func()
 		expectedStatus int
 		expectedBody   map[string]interface{}
+		expectedETag   string
 	}{
 		{
 			name:       "Success close reception",
 			pvzIDParam: pvzID.String(),
+			ifMatch:    ifMatch,
 			setupMocks: func() {
 				mockReceptionService.EXPECT().
-					CloseReception(gomock.Any(), pvzID).
+					CloseReception(gomock.Any(), pvzID, precondition).
 					Return(&models.Reception{
 						ID:       receptionID,
 						DateTime: now,
 						PVZID:    pvzID,
 						Status:   models.ReceptionStatusClosed,
+						Version:  4,
 					}, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -178,31 +249,57 @@ func TestHandler_closeReception(t *testing.T) {
 				"pvzId":    pvzID.String(),
 				"status":   "close",
 			},
+			expectedETag: models.EntityTag{ID: receptionID, Version: 4}.String(),
 		},
 		{
 			name:       "Reception already closed",
 			pvzIDParam: pvzID.String(),
+			ifMatch:    ifMatch,
 			setupMocks: func() {
 				mockReceptionService.EXPECT().
-					CloseReception(gomock.Any(), pvzID).
+					CloseReception(gomock.Any(), pvzID, precondition).
 					Return(nil, apperrors.ErrReceptionAlreadyClosed)
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"message": "This reception is already closed.",
+				"code": "reception_already_closed",
 			},
 		},
 		{
 			name:       "No active reception",
 			pvzIDParam: pvzID.String(),
+			ifMatch:    ifMatch,
 			setupMocks: func() {
 				mockReceptionService.EXPECT().
-					CloseReception(gomock.Any(), pvzID).
+					CloseReception(gomock.Any(), pvzID, precondition).
 					Return(nil, apperrors.ErrNoActiveReception)
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"message": "No active reception for this pickup point.",
+				"code": "no_active_reception",
+			},
+		},
+		{
+			name:       "Stale reception version",
+			pvzIDParam: pvzID.String(),
+			ifMatch:    ifMatch,
+			setupMocks: func() {
+				mockReceptionService.EXPECT().
+					CloseReception(gomock.Any(), pvzID, precondition).
+					Return(nil, apperrors.ErrVersionMismatch)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody: map[string]interface{}{
+				"code": "version_mismatch",
+			},
+		},
+		{
+			name:           "Missing If-Match",
+			pvzIDParam:     pvzID.String(),
+			setupMocks:     func() {},
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody: map[string]interface{}{
+				"code": "precondition_required",
 			},
 		},
 		{
@@ -211,7 +308,7 @@ func TestHandler_closeReception(t *testing.T) {
 			setupMocks:     func() {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"message": "Invalid PVZ ID format",
+				"code": "invalid_pvz_id",
 			},
 		},
 	}
@@ -222,6 +319,9 @@ func TestHandler_closeReception(t *testing.T) {
 
 			req, _ := http.NewRequest(http.MethodPost, "/pvz/"+tt.pvzIDParam+"/close_last_reception", nil)
 			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 
 			resp := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(resp)
@@ -232,6 +332,7 @@ func TestHandler_closeReception(t *testing.T) {
 			handler.closeReception(c)
 
 			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedETag, resp.Header().Get("ETag"))
 
 			var responseBody map[string]interface{}
 			json.Unmarshal(resp.Body.Bytes(), &responseBody)
@@ -243,6 +344,7 @@ func TestHandler_closeReception(t *testing.T) {
 				assert.NotEmpty(t, responseBody["dateTime"])
 			} else {
 				assert.Equal(t, tt.expectedBody["message"], responseBody["message"])
+				assert.Equal(t, tt.expectedBody["code"], responseBody["code"])
 			}
 		})
 	}
@@ -265,6 +367,14 @@ func TestHandler_createPVZ(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
@@ -327,6 +437,7 @@ func TestHandler_createPVZ(t *testing.T) {
 				assert.NotEmpty(t, responseBody["registrationDate"])
 			} else {
 				assert.Equal(t, tt.expectedBody["message"], responseBody["message"])
+				assert.Equal(t, tt.expectedBody["code"], responseBody["code"])
 			}
 		})
 	}
@@ -349,6 +460,14 @@ func TestHandler_createReception(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
@@ -398,7 +517,7 @@ func TestHandler_createReception(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"message": "Cannot create a new reception while the previous one is not closed.",
+				"code": "active_reception_exists",
 			},
 		},
 		{
@@ -413,7 +532,7 @@ func TestHandler_createReception(t *testing.T) {
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody: map[string]interface{}{
-				"message": "Pickup point not found.",
+				"code": "pvz_not_found",
 			},
 		},
 	}
@@ -445,6 +564,7 @@ func TestHandler_createReception(t *testing.T) {
 				assert.NotEmpty(t, responseBody["dateTime"])
 			} else {
 				assert.Equal(t, tt.expectedBody["message"], responseBody["message"])
+				assert.Equal(t, tt.expectedBody["code"], responseBody["code"])
 			}
 		})
 	}
@@ -467,6 +587,14 @@ func TestHandler_deleteLastProduct(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
@@ -502,7 +630,7 @@ func TestHandler_deleteLastProduct(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"message": "No products to delete in the current reception.",
+				"code": "no_products_to_delete",
 			},
 		},
 		{
@@ -515,7 +643,7 @@ func TestHandler_deleteLastProduct(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"message": "Closed reception cannot be modified.",
+				"code": "reception_cannot_be_modified",
 			},
 		},
 		{
@@ -528,7 +656,7 @@ func TestHandler_deleteLastProduct(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"message": "No active reception for this pickup point.",
+				"code": "no_active_reception",
 			},
 		},
 		{
@@ -537,7 +665,7 @@ func TestHandler_deleteLastProduct(t *testing.T) {
 			setupMocks:     func() {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"message": "Invalid PVZ ID format",
+				"code": "invalid_pvz_id",
 			},
 		},
 	}
@@ -563,6 +691,7 @@ func TestHandler_deleteLastProduct(t *testing.T) {
 			json.Unmarshal(resp.Body.Bytes(), &responseBody)
 
 			assert.Equal(t, tt.expectedBody["message"], responseBody["message"])
+			assert.Equal(t, tt.expectedBody["code"], responseBody["code"])
 		})
 	}
 }
@@ -584,6 +713,14 @@ func TestHandler_dummyLogin(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
@@ -601,7 +738,7 @@ func TestHandler_dummyLogin(t *testing.T) {
 			},
 			setupMocks: func() {
 				mockUserService.EXPECT().
-					DummyLogin("employee").
+					DummyLogin(gomock.Any(), "employee").
 					Return("dummy-token-employee", nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -614,7 +751,7 @@ func TestHandler_dummyLogin(t *testing.T) {
 			},
 			setupMocks: func() {
 				mockUserService.EXPECT().
-					DummyLogin("moderator").
+					DummyLogin(gomock.Any(), "moderator").
 					Return("dummy-token-moderator", nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -643,7 +780,7 @@ func TestHandler_dummyLogin(t *testing.T) {
 			} else {
 				var responseBody map[string]interface{}
 				json.Unmarshal(resp.Body.Bytes(), &responseBody)
-				assert.Contains(t, responseBody, "message")
+				assert.Contains(t, responseBody, "code")
 			}
 		})
 	}
@@ -666,6 +803,14 @@ func TestHandler_getPVZList(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
@@ -713,7 +858,7 @@ func TestHandler_getPVZList(t *testing.T) {
 			setupMocks: func() {
 				mockPVZService.EXPECT().
 					GetAllPVZWithReceptions(gomock.Any(), gomock.Any()).
-					Return(pvzWithReceptions, 1, nil)
+					Return(pvzWithReceptions, &models.PVZPage{}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedItems:  1,
@@ -724,7 +869,7 @@ func TestHandler_getPVZList(t *testing.T) {
 			setupMocks: func() {
 				mockPVZService.EXPECT().
 					GetAllPVZWithReceptions(gomock.Any(), gomock.Any()).
-					Return([]models.PVZWithReceptions{}, 0, nil)
+					Return([]models.PVZWithReceptions{}, &models.PVZPage{}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedItems:  0,
@@ -735,11 +880,89 @@ func TestHandler_getPVZList(t *testing.T) {
 			setupMocks: func() {
 				mockPVZService.EXPECT().
 					GetAllPVZWithReceptions(gomock.Any(), gomock.Any()).
-					Return(pvzWithReceptions, 1, nil)
+					Return(pvzWithReceptions, &models.PVZPage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedItems:  1,
+		},
+		{
+			name:        "Next page by cursor without total",
+			queryParams: "?withTotal=false&cursor=" + models.PVZCursor{SortBy: models.PVZSortRegistrationDate, Key: now.Format(time.RFC3339Nano), ID: pvzID}.Encode(),
+			setupMocks: func() {
+				mockPVZService.EXPECT().
+					GetAllPVZWithReceptions(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error) {
+						assert.NotNil(t, filter.Cursor)
+						assert.Equal(t, pvzID, filter.Cursor.ID)
+						assert.Equal(t, 0, filter.Page)
+						assert.False(t, filter.WithTotal)
+						return pvzWithReceptions, &models.PVZPage{PrevCursor: "prev"}, nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedItems:  1,
+		},
+		{
+			name:        "Filtered by cities and sorted by last reception",
+			queryParams: "?city=Москва&city=Казань&hasOpenReception=true&productType=обувь&sortBy=lastReception&sortOrder=desc",
+			setupMocks: func() {
+				mockPVZService.EXPECT().
+					GetAllPVZWithReceptions(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error) {
+						assert.Equal(t, []string{models.CityMoscow, models.CityKazan}, filter.Cities)
+						assert.True(t, *filter.HasOpenReception)
+						assert.Equal(t, models.ProductTypeShoes, filter.ProductType)
+						assert.Equal(t, models.PVZSortLastReception, filter.SortBy)
+						assert.True(t, filter.SortDesc)
+						return pvzWithReceptions, &models.PVZPage{}, nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedItems:  1,
+		},
+		{
+			name:        "Product counts only",
+			queryParams: "?productsLimit=5&productCountsOnly=true",
+			setupMocks: func() {
+				mockPVZService.EXPECT().
+					GetAllPVZWithReceptions(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error) {
+						assert.Equal(t, 5, filter.ProductsLimit)
+						assert.True(t, filter.ProductCountsOnly)
+						return pvzWithReceptions, &models.PVZPage{}, nil
+					})
 			},
 			expectedStatus: http.StatusOK,
 			expectedItems:  1,
 		},
+		{
+			name:           "Products limit too large",
+			queryParams:    "?productsLimit=5000",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedItems:  0,
+		},
+		{
+			name:           "Unknown city",
+			queryParams:    "?city=Тверь",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedItems:  0,
+		},
+		{
+			name:           "Cursor issued for another sort",
+			queryParams:    "?sortBy=city&cursor=" + models.PVZCursor{SortBy: models.PVZSortRegistrationDate, ID: pvzID}.Encode(),
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedItems:  0,
+		},
+		{
+			name:           "Malformed cursor",
+			queryParams:    "?cursor=not-a-cursor",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedItems:  0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -783,6 +1006,14 @@ func TestHandler_login(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
@@ -802,11 +1033,25 @@ func TestHandler_login(t *testing.T) {
 			setupMocks: func() {
 				mockUserService.EXPECT().
 					Login(gomock.Any(), "user@example.com", "password123").
-					Return("test-token", nil)
+					Return(&models.LoginResult{Token: "test-token"}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedToken:  true,
 		},
+		{
+			name: "Second factor required",
+			requestBody: map[string]interface{}{
+				"email":    "moderator@example.com",
+				"password": "password123",
+			},
+			setupMocks: func() {
+				mockUserService.EXPECT().
+					Login(gomock.Any(), "moderator@example.com", "password123").
+					Return(&models.LoginResult{MFARequired: true, ChallengeToken: "challenge-token"}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedToken:  true,
+		},
 		{
 			name: "Invalid credentials",
 			requestBody: map[string]interface{}{
@@ -816,7 +1061,7 @@ func TestHandler_login(t *testing.T) {
 			setupMocks: func() {
 				mockUserService.EXPECT().
 					Login(gomock.Any(), "user@example.com", "wrong-password").
-					Return("", apperrors.ErrInvalidCredentials)
+					Return(nil, apperrors.ErrInvalidCredentials)
 			},
 			expectedStatus: http.StatusUnauthorized,
 			expectedToken:  false,
@@ -830,7 +1075,7 @@ func TestHandler_login(t *testing.T) {
 			setupMocks: func() {
 				mockUserService.EXPECT().
 					Login(gomock.Any(), "nonexistent@example.com", "password123").
-					Return("", repoerrors.ErrUserNotFound)
+					Return(nil, repoerrors.ErrUserNotFound)
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedToken:  false,
@@ -858,7 +1103,7 @@ func TestHandler_login(t *testing.T) {
 			} else {
 				var responseBody map[string]interface{}
 				json.Unmarshal(resp.Body.Bytes(), &responseBody)
-				assert.Contains(t, responseBody, "message")
+				assert.Contains(t, responseBody, "code")
 			}
 		})
 	}
@@ -881,6 +1126,14 @@ func TestHandler_register(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
@@ -953,7 +1206,7 @@ func TestHandler_register(t *testing.T) {
 			},
 			expectedStatus: http.StatusConflict,
 			expectedBody: map[string]interface{}{
-				"message": "User with this email already exists.",
+				"code": "user_already_exists",
 			},
 		},
 	}
@@ -983,6 +1236,7 @@ func TestHandler_register(t *testing.T) {
 				assert.Equal(t, tt.expectedBody["role"], responseBody["role"])
 			} else {
 				assert.Equal(t, tt.expectedBody["message"], responseBody["message"])
+				assert.Equal(t, tt.expectedBody["code"], responseBody["code"])
 			}
 		})
 	}
@@ -993,6 +1247,7 @@ func Test_mapPVZListToDTO(t *testing.T) {
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 	productID := uuid.New()
+	one := 1
 
 	pvzWithReceptions := []models.PVZWithReceptions{
 		{
@@ -1023,7 +1278,7 @@ func Test_mapPVZListToDTO(t *testing.T) {
 	tests := []struct {
 		name     string
 		pvzList  []models.PVZWithReceptions
-		total    int
+		pageInfo *models.PVZPage
 		page     int
 		limit    int
 		expected dto.PVZListResponseDTO
@@ -1031,9 +1286,12 @@ func Test_mapPVZListToDTO(t *testing.T) {
 		{
 			name:    "Map PVZ list with receptions and products",
 			pvzList: pvzWithReceptions,
-			total:   1,
-			page:    1,
-			limit:   10,
+			pageInfo: &models.PVZPage{
+				Total:      &one,
+				NextCursor: "next",
+			},
+			page:  1,
+			limit: 10,
 			expected: dto.PVZListResponseDTO{
 				Items: []dto.PVZWithReceptionsResponseDTO{
 					{
@@ -1062,31 +1320,32 @@ func Test_mapPVZListToDTO(t *testing.T) {
 						},
 					},
 				},
-				TotalCount: 1,
+				TotalCount: &one,
 				Page:       1,
 				Limit:      10,
+				NextCursor: "next",
 			},
 		},
 		{
-			name:    "Empty PVZ list",
-			pvzList: []models.PVZWithReceptions{},
-			total:   0,
-			page:    1,
-			limit:   10,
+			name:     "Empty PVZ list",
+			pvzList:  []models.PVZWithReceptions{},
+			pageInfo: &models.PVZPage{},
+			page:     1,
+			limit:    10,
 			expected: dto.PVZListResponseDTO{
-				Items:      []dto.PVZWithReceptionsResponseDTO{},
-				TotalCount: 0,
-				Page:       1,
-				Limit:      10,
+				Items: []dto.PVZWithReceptionsResponseDTO{},
+				Page:  1,
+				Limit: 10,
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := mapPVZListToDTO(tt.pvzList, tt.total, tt.page, tt.limit)
+			result := mapPVZListToDTO(tt.pvzList, tt.pageInfo, tt.page, tt.limit)
 
 			assert.Equal(t, tt.expected.TotalCount, result.TotalCount)
+			assert.Equal(t, tt.expected.NextCursor, result.NextCursor)
 			assert.Equal(t, tt.expected.Page, result.Page)
 			assert.Equal(t, tt.expected.Limit, result.Limit)
 			assert.Equal(t, len(tt.expected.Items), len(result.Items))
@@ -1134,6 +1393,14 @@ func TestAuthMiddleware(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
@@ -1153,19 +1420,19 @@ func TestAuthMiddleware(t *testing.T) {
 			name:           "Missing auth header",
 			authHeader:     "",
 			expectedStatus: http.StatusUnauthorized,
-			expectedError:  "Authorization header is required",
+			expectedError:  "authorization_required",
 		},
 		{
 			name:           "Invalid auth format",
 			authHeader:     "InvalidFormat token123",
 			expectedStatus: http.StatusUnauthorized,
-			expectedError:  "Invalid authorization format",
+			expectedError:  "invalid_authorization",
 		},
 		{
 			name:           "Bearer without token",
 			authHeader:     "Bearer ",
 			expectedStatus: http.StatusUnauthorized,
-			expectedError:  "invalid token",
+			expectedError:  "invalid_token",
 		},
 	}
 
@@ -1194,7 +1461,7 @@ func TestAuthMiddleware(t *testing.T) {
 
 				var response map[string]interface{}
 				json.Unmarshal(w.Body.Bytes(), &response)
-				assert.Equal(t, tt.expectedError, response["message"])
+				assert.Equal(t, tt.expectedError, response["code"])
 			}
 		})
 	}
@@ -1217,6 +1484,14 @@ func TestRoleMiddleware(t *testing.T) {
 		mockPVZService,
 		mockReceptionService,
 		mockProductService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		testConfig,
 	)
 
@@ -1246,21 +1521,21 @@ func TestRoleMiddleware(t *testing.T) {
 			role:           "employee",
 			requiredRole:   "moderator",
 			expectedStatus: http.StatusForbidden,
-			expectedError:  "Insufficient permissions",
+			expectedError:  "insufficient_permissions",
 		},
 		{
 			name:           "Moderator trying to access employee route",
 			role:           "moderator",
 			requiredRole:   "employee",
 			expectedStatus: http.StatusForbidden,
-			expectedError:  "Insufficient permissions",
+			expectedError:  "insufficient_permissions",
 		},
 		{
 			name:           "Missing role",
 			role:           "",
 			requiredRole:   "employee",
 			expectedStatus: http.StatusForbidden,
-			expectedError:  "User role not found",
+			expectedError:  "insufficient_permissions",
 		},
 	}
 
@@ -1288,7 +1563,7 @@ func TestRoleMiddleware(t *testing.T) {
 
 				var response map[string]interface{}
 				json.Unmarshal(w.Body.Bytes(), &response)
-				assert.Equal(t, tt.expectedError, response["message"])
+				assert.Equal(t, tt.expectedError, response["code"])
 			}
 		})
 	}
@@ -1296,101 +1571,288 @@ func TestRoleMiddleware(t *testing.T) {
 
 func TestMetricsMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
 
-	mockUserService := mocks.NewMockUserServiceInterface(ctrl)
-	mockPVZService := mocks.NewMockPVZServiceInterface(ctrl)
-	mockReceptionService := mocks.NewMockReceptionServiceInterface(ctrl)
-	mockProductService := mocks.NewMockProductServiceInterface(ctrl)
+	var inFlight float64
 
-	testConfig := &config.Config{}
-
-	handler := NewHandler(
-		mockUserService,
-		mockPVZService,
-		mockReceptionService,
-		mockProductService,
-		testConfig,
-	)
+	router := gin.New()
+	router.Use(handler.metricsMiddleware())
+	router.Use(gin.CustomRecovery(recoverProblem))
+	router.POST("/pvz/:pvzId/close_last_reception", func(c *gin.Context) {
+		inFlight = testutil.ToFloat64(metrics.RequestsInFlight.WithLabelValues(http.MethodPost, "/pvz/:pvzId/close_last_reception"))
+		c.String(http.StatusOK, "closed")
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
 
 	tests := []struct {
-		name          string
-		setupRequest  func() (*httptest.ResponseRecorder, *gin.Context)
-		handlerStatus int
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedRoute  string
+		expectedStatus string
+		expectedSize   float64
 	}{
 		{
-			name: "Success response",
-			setupRequest: func() (*httptest.ResponseRecorder, *gin.Context) {
-				w := httptest.NewRecorder()
-				c, _ := gin.CreateTestContext(w)
-				req, _ := http.NewRequest(http.MethodGet, "/test", nil)
-				c.Request = req
-				return w, c
-			},
-			handlerStatus: http.StatusOK,
+			name:           "Matched route",
+			method:         http.MethodPost,
+			path:           "/pvz/" + uuid.NewString() + "/close_last_reception",
+			body:           `{"force":true}`,
+			expectedRoute:  "/pvz/:pvzId/close_last_reception",
+			expectedStatus: "2xx",
+			expectedSize:   float64(len("closed")),
+		},
+		{
+			name:           "Unmatched route",
+			method:         http.MethodGet,
+			path:           "/wp-admin/" + uuid.NewString(),
+			expectedRoute:  unmatchedRoute,
+			expectedStatus: "4xx",
+		},
+		{
+			name:           "Panic is counted as server error",
+			method:         http.MethodGet,
+			path:           "/panic",
+			expectedRoute:  "/panic",
+			expectedStatus: "5xx",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			w, c := tt.setupRequest()
+			counter := metrics.RequestsTotal.WithLabelValues(tt.method, tt.expectedRoute, tt.expectedStatus)
+			before := testutil.ToFloat64(counter)
 
-			middleware := handler.metricsMiddleware()
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			router.ServeHTTP(httptest.NewRecorder(), req)
 
-			middleware(c)
+			assert.Equal(t, before+1, testutil.ToFloat64(counter))
+			assert.Zero(t, testutil.ToFloat64(metrics.RequestsInFlight.WithLabelValues(tt.method, tt.expectedRoute)))
+		})
+	}
+
+	assert.Equal(t, float64(1), inFlight, "Request should be counted as in flight while it is served")
+
+	// Гистограммы отдаются одним рядом на сочетание меток, поэтому проверяется сумма наблюдений
+	requestSize, responseSize := &prommodel.Metric{}, &prommodel.Metric{}
+	_ = metrics.RequestSize.WithLabelValues(http.MethodPost, "/pvz/:pvzId/close_last_reception").(prometheus.Histogram).Write(requestSize)
+	_ = metrics.ResponseSize.WithLabelValues(http.MethodPost, "/pvz/:pvzId/close_last_reception").(prometheus.Histogram).Write(responseSize)
+	assert.Equal(t, float64(len(`{"force":true}`)), requestSize.GetHistogram().GetSampleSum())
+	assert.Equal(t, tests[0].expectedSize, responseSize.GetHistogram().GetSampleSum())
+}
 
-			c.Status(tt.handlerStatus)
+func TestAuthMiddleware_DummyToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserServiceInterface(ctrl)
+	token, _ := auth.GenerateDummyToken("moderator", "test-secret", time.Hour)
+
+	tests := []struct {
+		name           string
+		acceptTokens   bool
+		expectedStatus int
+	}{
+		{name: "Dummy tokens accepted", acceptTokens: true, expectedStatus: http.StatusOK},
+		{name: "Dummy tokens rejected", acceptTokens: false, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConfig := &config.Config{
+				JWT:        config.JWTConfig{Secret: "test-secret"},
+				DummyLogin: config.DummyLoginConfig{AcceptTokens: tt.acceptTokens},
+			}
+			handler := NewHandler(mockUserService, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, testConfig)
 
-			assert.Equal(t, tt.handlerStatus, w.Code)
+			if tt.acceptTokens {
+				mockUserService.EXPECT().CheckAccessToken(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			c.Request = req
+
+			handler.authMiddleware()(c)
+
+			if tt.expectedStatus == http.StatusOK {
+				assert.False(t, c.IsAborted())
+			} else {
+				assert.True(t, c.IsAborted())
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			}
 		})
 	}
 }
 
-func TestErrorHandling(t *testing.T) {
+func TestInitRoutes_DummyLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
 	tests := []struct {
-		name         string
-		err          error
-		expectedCode int
-		expectedMsg  string
+		name           string
+		config         config.Config
+		expectedStatus int
 	}{
 		{
-			name:         "Repository error - PVZ not found",
-			err:          repoerrors.ErrPVZNotFound,
-			expectedCode: http.StatusNotFound,
-			expectedMsg:  "Pickup point not found.",
+			name:           "Disabled by default",
+			config:         config.Config{},
+			expectedStatus: http.StatusNotFound,
 		},
 		{
-			name:         "App error - Invalid city",
-			err:          apperrors.ErrInvalidCity,
-			expectedCode: http.StatusBadRequest,
-			expectedMsg:  "Pickup points can only be created in the following cities: Moscow, Saint Petersburg, Kazan.",
+			name: "Enabled in development",
+			config: config.Config{
+				Server:     config.ServerConfig{AppEnv: "development", GinMode: gin.TestMode},
+				DummyLogin: config.DummyLoginConfig{Enabled: true},
+			},
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:         "App error - Active reception exists",
-			err:          apperrors.ErrActiveReceptionExists,
-			expectedCode: http.StatusBadRequest,
-			expectedMsg:  "Cannot create a new reception while the previous one is not closed.",
+			name: "Never registered in production",
+			config: config.Config{
+				Server:     config.ServerConfig{AppEnv: "production", GinMode: gin.TestMode},
+				DummyLogin: config.DummyLoginConfig{Enabled: true},
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.config
+			if cfg.Server.GinMode == "" {
+				cfg.Server.GinMode = gin.TestMode
+			}
+			router := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &cfg).InitRoutes()
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/dummyLogin", bytes.NewBufferString("{}"))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_setLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserServiceInterface(ctrl)
+	handler := NewHandler(mockUserService, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMocks     func()
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:        "Russian",
+			requestBody: `{"language":"ru"}`,
+			setupMocks: func() {
+				mockUserService.EXPECT().SetLanguage(gomock.Any(), userID, "ru").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
 		},
 		{
-			name:         "Unknown error",
-			err:          errors.New("unknown error"),
-			expectedCode: http.StatusInternalServerError,
-			expectedMsg:  "unknown error",
+			name:        "Reset to Accept-Language",
+			requestBody: `{"language":""}`,
+			setupMocks: func() {
+				mockUserService.EXPECT().SetLanguage(gomock.Any(), userID, "").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
 		},
 		{
-			name:         "Wrapped error",
-			err:          fmt.Errorf("database error: %w", repoerrors.ErrUserNotFound),
-			expectedCode: http.StatusNotFound,
-			expectedMsg:  "User not found.",
+			name:           "Unsupported language",
+			requestBody:    `{"language":"de"}`,
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_request_body",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			code, msg := getErrorResponse(tt.err)
-			assert.Equal(t, tt.expectedCode, code)
-			assert.Equal(t, tt.expectedMsg, msg)
+			tt.setupMocks()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodPut, "/me/language", bytes.NewBufferString(tt.requestBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(string(userIDKey), userID)
+
+			handler.setLanguage(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), `"code":"`+tt.expectedCode+`"`)
+			}
+		})
+	}
+}
+
+// Язык из профиля пользователя важнее заголовка Accept-Language.
+func TestLanguageSelection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserServiceInterface(ctrl)
+	mockUserService.EXPECT().CheckAccessToken(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	testConfig := &config.Config{JWT: config.JWTConfig{Secret: "test-secret"}}
+	handler := NewHandler(mockUserService, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, testConfig)
+
+	router := gin.New()
+	router.Use(languageMiddleware())
+	router.GET("/public", func(c *gin.Context) {
+		respondError(c, repoerrors.ErrPVZNotFound)
+	})
+	router.GET("/private", handler.authMiddleware(), func(c *gin.Context) {
+		respondError(c, repoerrors.ErrPVZNotFound)
+	})
+
+	tests := []struct {
+		name             string
+		path             string
+		locale           string
+		acceptLanguage   string
+		expectedLanguage string
+		expectedDetail   string
+	}{
+		{name: "Default", path: "/public", expectedLanguage: "en", expectedDetail: "Pickup point not found."},
+		{name: "Accept-Language", path: "/public", acceptLanguage: "ru-RU,ru;q=0.9", expectedLanguage: "ru", expectedDetail: "ПВЗ не найден."},
+		{name: "Profile without header", path: "/private", locale: "ru", expectedLanguage: "ru", expectedDetail: "ПВЗ не найден."},
+		{name: "Profile over header", path: "/private", locale: "en", acceptLanguage: "ru", expectedLanguage: "en", expectedDetail: "Pickup point not found."},
+		{name: "No profile preference", path: "/private", acceptLanguage: "ru", expectedLanguage: "ru", expectedDetail: "ПВЗ не найден."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			if tt.path == "/private" {
+				token, _ := auth.GenerateToken(uuid.New(), models.RoleEmployee, tt.locale, "test-secret", time.Hour)
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, tt.expectedLanguage, w.Header().Get("Content-Language"))
+			assert.Contains(t, w.Body.String(), tt.expectedDetail)
 		})
 	}
 }
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"io"
+	"net/http"
+	"slices"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader отмечает ответ, сохраненный при первом выполнении запроса.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotentBodySize ограничивает тело запроса с ключом: оно читается целиком
+	// для отпечатка.
+	maxIdempotentBodySize = 1 << 20
+)
+
+// idempotencyMiddleware выполняет запрос с заголовком Idempotency-Key один раз: повтор
+// с тем же ключом и телом получает сохраненный ответ, повтор, пока первый запрос еще
+// выполняется, - 409, а другой запрос с тем же ключом - 422. Запросы без заголовка
+// выполняются как обычно. Ставится после проверки прав, чтобы отказ не сохранялся.
+func (h *Handler) idempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				abortWithError(c, apperrors.ErrRequestBodyTooLarge)
+				return
+			}
+			abortWithError(c, apperrors.ErrInvalidRequestBody)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		actorID, _ := c.Get(string(userIDKey))
+		fingerprint := models.IdempotencyFingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+
+		record, err := h.idempotencyService.Begin(c.Request.Context(), actorID.(uuid.UUID), key, fingerprint)
+		if err != nil {
+			zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("idempotency_key", key).Msg("Idempotency key rejected")
+
+			abortWithError(c, err)
+			return
+		}
+
+		if record.IsCompleted() {
+			zerolog.Ctx(c.Request.Context()).Debug().Str("idempotency_key", key).Int("status", record.StatusCode).Msg("Replaying idempotent response")
+
+			for name, values := range record.Header {
+				c.Writer.Header()[name] = values
+			}
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(record.StatusCode, record.Header.Get("Content-Type"), record.Body)
+			c.Abort()
+			return
+		}
+
+		// Заголовки, выставленные до обработчика (X-Request-ID и т.п.), при повторе
+		// выставятся заново
+		before := c.Writer.Header().Clone()
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// Ответ сохраняется и после отключения клиента: повтор должен его получить
+		ctx := context.WithoutCancel(c.Request.Context())
+		completed := false
+
+		// Если обработчик паникует, ключ освобождается, иначе повторы ждали бы
+		// IDEMPOTENCY_LOCK_TIMEOUT
+		defer func() {
+			if completed {
+				return
+			}
+			if err := h.idempotencyService.Release(ctx, record); err != nil {
+				zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("idempotency_key", key).Msg("Failed to release idempotency key")
+			}
+		}()
+
+		c.Next()
+
+		statusCode := writer.Status()
+		if !models.IsIdempotentResponse(statusCode) {
+			return
+		}
+
+		err = h.idempotencyService.Complete(ctx, record, statusCode, handlerHeader(before, writer.Header()), writer.body.Bytes())
+		if err != nil {
+			zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("idempotency_key", key).Msg("Failed to save idempotent response")
+			return
+		}
+		completed = true
+	}
+}
+
+// handlerHeader возвращает заголовки ответа, которые появились или изменились после before.
+func handlerHeader(before, after http.Header) http.Header {
+	header := http.Header{}
+	for name, values := range after {
+		if name == "Content-Length" || slices.Equal(before[name], values) {
+			continue
+		}
+		header[name] = slices.Clone(values)
+	}
+	return header
+}
+
+// recordingWriter копирует тело ответа для сохранения.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
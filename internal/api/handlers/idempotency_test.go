@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_idempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIdempotencyService := mocks.NewMockIdempotencyServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockIdempotencyService, &config.Config{})
+
+	userID := uuid.New()
+	body := `{"type":"обувь"}`
+	fingerprint := models.IdempotencyFingerprint(http.MethodPost, "/products", []byte(body))
+	acquired := &models.IdempotencyRecord{ActorID: userID, Key: "retry-1", Fingerprint: fingerprint}
+	handlerHeader := http.Header{
+		"Content-Type": {"application/json; charset=utf-8"},
+		"Etag":         {`"created"`},
+		"Location":     {"/products/created"},
+	}
+
+	tests := []struct {
+		name           string
+		key            string
+		handlerStatus  int
+		setupMocks     func()
+		expectedStatus int
+		expectedBody   string
+		expectedCalls  int
+		expectedReplay bool
+		expectedETag   string
+	}{
+		{
+			name:           "Запрос без ключа",
+			handlerStatus:  http.StatusCreated,
+			setupMocks:     func() {},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":"created"}`,
+			expectedCalls:  1,
+			expectedETag:   `"created"`,
+		},
+		{
+			name:          "Первый запрос сохраняет ответ",
+			key:           "retry-1",
+			handlerStatus: http.StatusCreated,
+			setupMocks: func() {
+				mockIdempotencyService.EXPECT().Begin(gomock.Any(), userID, "retry-1", fingerprint).Return(acquired, nil)
+				mockIdempotencyService.EXPECT().
+					Complete(gomock.Any(), acquired, http.StatusCreated, handlerHeader, []byte(`{"id":"created"}`)).
+					Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":"created"}`,
+			expectedCalls:  1,
+			expectedETag:   `"created"`,
+		},
+		{
+			name: "Повтор получает сохраненный ответ",
+			key:  "retry-1",
+			setupMocks: func() {
+				mockIdempotencyService.EXPECT().Begin(gomock.Any(), userID, "retry-1", fingerprint).Return(&models.IdempotencyRecord{
+					StatusCode: http.StatusCreated,
+					Header: http.Header{
+						"Content-Type": {"application/json; charset=utf-8"},
+						"Etag":         {`"first"`},
+						"Location":     {"/products/first"},
+					},
+					Body: []byte(`{"id":"first"}`),
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":"first"}`,
+			expectedReplay: true,
+			expectedETag:   `"first"`,
+		},
+		{
+			name:          "Ошибка сервера освобождает ключ",
+			key:           "retry-1",
+			handlerStatus: http.StatusInternalServerError,
+			setupMocks: func() {
+				mockIdempotencyService.EXPECT().Begin(gomock.Any(), userID, "retry-1", fingerprint).Return(acquired, nil)
+				mockIdempotencyService.EXPECT().Release(gomock.Any(), acquired).Return(nil)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"id":"created"}`,
+			expectedCalls:  1,
+			expectedETag:   `"created"`,
+		},
+		{
+			name: "Повтор во время выполнения",
+			key:  "retry-1",
+			setupMocks: func() {
+				mockIdempotencyService.EXPECT().Begin(gomock.Any(), userID, "retry-1", fingerprint).Return(nil, apperrors.ErrIdempotencyKeyInUse)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"type":"about:blank","title":"Conflict","status":409,"detail":"A request with this Idempotency-Key is still in progress. Retry later.","code":"idempotency_key_in_use"}`,
+		},
+		{
+			name: "Ключ использован с другим запросом",
+			key:  "retry-1",
+			setupMocks: func() {
+				mockIdempotencyService.EXPECT().Begin(gomock.Any(), userID, "retry-1", fingerprint).Return(nil, apperrors.ErrIdempotencyKeyReused)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"This Idempotency-Key has already been used with a different request.","code":"idempotency_key_reused"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			calls := 0
+			router := gin.New()
+			router.POST("/products", func(c *gin.Context) {
+				c.Set(string(userIDKey), userID)
+				c.Header(requestIDHeader, "request-1")
+			}, handler.idempotencyMiddleware(), func(c *gin.Context) {
+				calls++
+				c.Header(etagHeader, `"created"`)
+				c.Header("Location", "/products/created")
+				c.JSON(tt.handlerStatus, gin.H{"id": "created"})
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+			if tt.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tt.key)
+			}
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			assert.Equal(t, tt.expectedCalls, calls)
+			assert.Equal(t, tt.expectedETag, resp.Header().Get(etagHeader))
+			assert.Equal(t, "request-1", resp.Header().Get(requestIDHeader))
+			if tt.expectedReplay {
+				assert.Equal(t, "true", resp.Header().Get(idempotentReplayedHeader))
+			} else {
+				assert.Empty(t, resp.Header().Get(idempotentReplayedHeader))
+			}
+		})
+	}
+}
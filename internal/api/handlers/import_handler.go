@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/api/errcode"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/i18n"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"net/http"
+	"time"
+)
+
+// ndjsonContentTypes - типы содержимого, при которых формат загрузки по умолчанию ndjson.
+var ndjsonContentTypes = map[string]bool{
+	ndjsonContentType:    true,
+	"application/ndjson": true,
+}
+
+func (h *Handler) importData(c *gin.Context) {
+	var params dto.PostImportDatasetParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid query parameters in import")
+		respondBindError(c, apperrors.ErrInvalidQueryParameters, err)
+		return
+	}
+
+	req := models.ImportRequest{
+		Dataset: c.Param("dataset"),
+		Format:  models.ImportFormatCSV,
+	}
+
+	if params.Format != nil {
+		req.Format = string(*params.Format)
+	} else if ndjsonContentTypes[c.ContentType()] {
+		req.Format = models.ImportFormatNDJSON
+	}
+	if params.DryRun != nil {
+		req.DryRun = *params.DryRun
+	}
+
+	if err := req.Validate(); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid import request")
+
+		respondError(c, err)
+		return
+	}
+
+	// Общие таймауты сервера рассчитаны на обычные запросы, а файл загружается дольше
+	h.extendReadDeadline(c, h.config.Import.Timeout)
+	h.extendWriteDeadline(c, h.config.Import.Timeout)
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.config.Import.MaxFileSize)
+
+	report, err := h.importService.Import(c.Request.Context(), req, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			zerolog.Ctx(c.Request.Context()).Debug().Int64("limit", maxBytesErr.Limit).Str("dataset", req.Dataset).Msg("Import file is too large")
+			respondError(c, apperrors.ErrImportFileTooLarge)
+			return
+		}
+
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("dataset", req.Dataset).Msg("Import failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toImportReportDTO(i18n.FromContext(c.Request.Context()), report))
+}
+
+// extendReadDeadline продлевает срок чтения тела запроса для загрузки больших файлов.
+func (h *Handler) extendReadDeadline(c *gin.Context, timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	err := http.NewResponseController(c.Writer).SetReadDeadline(time.Now().Add(timeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		zerolog.Ctx(c.Request.Context()).Warn().Err(err).Msg("Failed to extend read deadline for import")
+	}
+}
+
+func toImportReportDTO(lang string, report *models.ImportReport) dto.ImportReport {
+	result := dto.ImportReport{
+		Dataset: dto.ImportReportDataset(report.Dataset),
+		DryRun:  report.DryRun,
+		Total:   report.Total,
+		Created: report.Created,
+		Skipped: report.Skipped,
+		Failed:  report.Failed(),
+		Errors:  make([]dto.ImportRowError, 0, len(report.Errors)),
+	}
+
+	for _, rowErr := range report.Errors {
+		descriptor, _ := errcode.Describe(rowErr.Err)
+		item := dto.ImportRowError{
+			Line:    rowErr.Line,
+			Code:    descriptor.Code,
+			Message: i18n.Message(lang, descriptor.Code),
+		}
+		if rowErr.ExternalID != "" {
+			externalID := rowErr.ExternalID
+			item.ExternalId = &externalID
+		}
+		if rowErr.Column != "" {
+			column := rowErr.Column
+			item.Column = &column
+		}
+		result.Errors = append(result.Errors, item)
+	}
+
+	return result
+}
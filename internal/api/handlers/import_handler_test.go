@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_importData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockImportService := mocks.NewMockImportServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, mockImportService,
+		nil, nil,
+		&config.Config{Import: config.ImportConfig{MaxFileSize: 1024}})
+
+	tests := []struct {
+		name           string
+		dataset        string
+		query          string
+		contentType    string
+		body           string
+		setupMocks     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "CSV import with row errors",
+			dataset:     "pvz",
+			contentType: "text/csv",
+			body:        "externalId,city\na,Москва\nb,Тверь\n,Казань\n",
+			setupMocks: func() {
+				mockImportService.EXPECT().Import(gomock.Any(), models.ImportRequest{
+					Dataset: models.ImportDatasetPVZ,
+					Format:  models.ImportFormatCSV,
+				}, gomock.Any()).Return(&models.ImportReport{
+					Dataset: models.ImportDatasetPVZ,
+					Total:   3,
+					Created: 1,
+					Errors: []models.ImportRowError{
+						{Line: 3, ExternalID: "b", Column: "city", Err: apperrors.ErrInvalidCity},
+						{Line: 4, Err: fmt.Errorf("%w: wrong number of fields", apperrors.ErrMalformedImportRow)},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"dataset":"pvz","dryRun":false,"total":3,"created":1,"skipped":0,"failed":2,"errors":[` +
+				`{"line":3,"externalId":"b","column":"city","code":"invalid_city","message":"Pickup points can only be created in the following cities: Москва, Санкт-Петербург, Казань."},` +
+				`{"line":4,"code":"malformed_import_row","message":"Row cannot be parsed. Check the number of columns and quoting."}]}`,
+		},
+		{
+			name:        "NDJSON dry run inferred from content type",
+			dataset:     "receptions",
+			query:       "?dryRun=true",
+			contentType: "application/x-ndjson",
+			body:        `{"externalId":"r-1","pvzExternalId":"a","dateTime":"2024-02-01T10:00:00Z"}` + "\n",
+			setupMocks: func() {
+				mockImportService.EXPECT().Import(gomock.Any(), models.ImportRequest{
+					Dataset: models.ImportDatasetReceptions,
+					Format:  models.ImportFormatNDJSON,
+					DryRun:  true,
+				}, gomock.Any()).Return(&models.ImportReport{Dataset: models.ImportDatasetReceptions, DryRun: true, Total: 1, Created: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"dataset":"receptions","dryRun":true,"total":1,"created":1,"skipped":0,"failed":0,"errors":[]}`,
+		},
+		{
+			name:           "Unknown dataset",
+			dataset:        "users",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Invalid import dataset specified. Available datasets: pvz, receptions, products.","code":"invalid_import_dataset"}`,
+		},
+		{
+			name:           "Unknown format",
+			dataset:        "pvz",
+			query:          "?format=xlsx",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "File too large",
+			dataset: "products",
+			body:    strings.Repeat("x", 2048),
+			setupMocks: func() {
+				mockImportService.EXPECT().Import(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ models.ImportRequest, r io.Reader) (*models.ImportReport, error) {
+						_, err := io.ReadAll(r)
+						return nil, fmt.Errorf("failed to read import file: %w", err)
+					})
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"type":"about:blank","title":"Request Entity Too Large","status":413,"detail":"Import file is too large.","code":"import_file_too_large"}`,
+		},
+		{
+			name:    "Database error",
+			dataset: "products",
+			setupMocks: func() {
+				mockImportService.EXPECT().Import(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/import/"+tt.dataset+tt.query, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				c.Request.Header.Set("Content-Type", tt.contentType)
+			}
+			c.Params = gin.Params{{Key: "dataset", Value: tt.dataset}}
+
+			handler.importData(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			}
+		})
+	}
+}
@@ -1,36 +1,95 @@
 package handlers
 
 import (
+	"avito-backend-trainee-assignment-spring-2025/internal/auth"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"context"
 	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"time"
 )
 
 type UserServiceInterface interface {
 	Register(ctx context.Context, email, password, role string) (*models.User, error)
-	Login(ctx context.Context, email, password string) (string, error)
-	DummyLogin(role string) (string, error)
+	Login(ctx context.Context, email, password string) (*models.LoginResult, error)
+	DummyLogin(ctx context.Context, role string) (string, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	CheckAccessToken(ctx context.Context, claims *auth.Claims) error
+	SetLanguage(ctx context.Context, userID uuid.UUID, language string) error
+}
+
+type PasswordServiceInterface interface {
+	ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+type MFAServiceInterface interface {
+	EnrollTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	CompleteLogin(ctx context.Context, challengeToken, code string) (string, error)
+}
+
+type ServiceAccountServiceInterface interface {
+	CreateServiceAccount(ctx context.Context, name, description string, createdBy uuid.UUID) (*models.ServiceAccount, error)
+	ListServiceAccounts(ctx context.Context) ([]*models.ServiceAccount, error)
+	IssueAPIKey(ctx context.Context, serviceAccountID uuid.UUID, scopes []string, pvzIDs []uuid.UUID, expiresAt *time.Time) (*models.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, serviceAccountID uuid.UUID) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID uuid.UUID) error
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error)
+}
+
+type AuditServiceInterface interface {
+	ListAuditLog(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error)
+	VerifyAuditChain(ctx context.Context) (*models.AuditVerification, error)
 }
 
 type PVZServiceInterface interface {
 	CreatePVZ(ctx context.Context, city string) (*models.PVZ, error)
 	GetPVZByID(ctx context.Context, id uuid.UUID) (*models.PVZ, error)
-	GetAllPVZ(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, int, error)
-	GetAllPVZWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, int, error)
+	UpdatePVZ(ctx context.Context, id uuid.UUID, update models.PVZUpdate, precondition models.Precondition) (*models.PVZ, error)
+	GetAllPVZ(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, *models.PVZPage, error)
+	GetAllPVZWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error)
+	FindNearbyPVZ(ctx context.Context, filter models.NearbyFilter) ([]models.PVZWithDistance, error)
+	GetOverloadedPVZ(ctx context.Context, threshold float64, allowedIDs []uuid.UUID) ([]models.PVZUtilization, error)
 }
 
 type ReceptionServiceInterface interface {
 	CreateReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error)
 	GetReceptionByID(ctx context.Context, id uuid.UUID) (*models.Reception, error)
 	GetLastActiveReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error)
-	CloseReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error)
+	CloseReception(ctx context.Context, pvzID uuid.UUID, precondition models.Precondition) (*models.Reception, error)
 	GetLastReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error)
+	ListPVZReceptions(ctx context.Context, pvzID uuid.UUID, filter models.ReceptionFilter) ([]*models.Reception, int, error)
+	ReceptionReport(ctx context.Context, filter models.ReceptionReportFilter) ([]models.ReceptionReportRow, error)
 }
 
 type ProductServiceInterface interface {
-	AddProduct(ctx context.Context, productType string, pvzID uuid.UUID) (*models.Product, error)
+	AddProduct(ctx context.Context, productType string, pvzID uuid.UUID) (*models.Product, []models.CapacityUsage, error)
 	GetProductByID(ctx context.Context, id uuid.UUID) (*models.Product, error)
 	GetProductsByReceptionID(ctx context.Context, receptionID uuid.UUID) ([]models.Product, error)
 	DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error
 }
+
+type ExportServiceInterface interface {
+	Export(ctx context.Context, req models.ExportRequest, w io.Writer) (int, error)
+	StartExportJob(ctx context.Context, req models.ExportRequest) (*models.ExportJob, error)
+	GetExportJob(ctx context.Context, id uuid.UUID) (*models.ExportJob, error)
+	GetExportFile(ctx context.Context, id uuid.UUID) (*models.ExportJob, string, error)
+}
+
+type ImportServiceInterface interface {
+	Import(ctx context.Context, req models.ImportRequest, r io.Reader) (*models.ImportReport, error)
+}
+
+type EventServiceInterface interface {
+	Subscribe(ctx context.Context, filter models.EventFilter, lastEventID int64) (<-chan *models.PVZEvent, error)
+}
+
+type IdempotencyServiceInterface interface {
+	Begin(ctx context.Context, actorID uuid.UUID, key, fingerprint string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *models.IdempotencyRecord, statusCode int, header http.Header, body []byte) error
+	Release(ctx context.Context, record *models.IdempotencyRecord) error
+}
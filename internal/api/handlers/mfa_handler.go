@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+func (h *Handler) loginMFA(c *gin.Context) {
+	var req dto.PostLoginMfaJSONRequestBody
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in loginMFA")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+		return
+	}
+
+	token, err := h.mfaService.CompleteLogin(c.Request.Context(), req.ChallengeToken, req.Code)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Msg("Second factor login failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *Handler) enrollTOTP(c *gin.Context) {
+	userID, ok := c.Get(string(userIDKey))
+	if !ok {
+		respondError(c, apperrors.ErrUnauthenticated)
+		return
+	}
+
+	enrollment, err := h.mfaService.EnrollTOTP(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("user_id", userID.(uuid.UUID).String()).Msg("TOTP enrollment failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.TOTPEnrollment{
+		Secret:          enrollment.Secret,
+		ProvisioningUri: enrollment.ProvisioningURI,
+	})
+}
+
+func (h *Handler) confirmTOTP(c *gin.Context) {
+	var req dto.PostMeMfaTotpConfirmJSONRequestBody
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in confirmTOTP")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+		return
+	}
+
+	userID, ok := c.Get(string(userIDKey))
+	if !ok {
+		respondError(c, apperrors.ErrUnauthenticated)
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTP(c.Request.Context(), userID.(uuid.UUID), req.Code)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("user_id", userID.(uuid.UUID).String()).Msg("TOTP confirmation failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RecoveryCodes{RecoveryCodes: codes})
+}
+
+func (h *Handler) disableTOTP(c *gin.Context) {
+	var req dto.PostMeMfaTotpDisableJSONRequestBody
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in disableTOTP")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+		return
+	}
+
+	userID, ok := c.Get(string(userIDKey))
+	if !ok {
+		respondError(c, apperrors.ErrUnauthenticated)
+		return
+	}
+
+	if err := h.mfaService.DisableTOTP(c.Request.Context(), userID.(uuid.UUID), req.Code); err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("user_id", userID.(uuid.UUID).String()).Msg("TOTP disabling failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_mfa(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMFAService := mocks.NewMockMFAServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, mockMFAService, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		handlerFunc    gin.HandlerFunc
+		requestBody    map[string]interface{}
+		setupMocks     func()
+		expectedStatus int
+		expectedField  string
+	}{
+		{
+			name:        "Second step login succeeded",
+			handlerFunc: handler.loginMFA,
+			requestBody: map[string]interface{}{"challengeToken": "challenge", "code": "123456"},
+			setupMocks: func() {
+				mockMFAService.EXPECT().CompleteLogin(gomock.Any(), "challenge", "123456").Return("token", nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Second step login with invalid code",
+			handlerFunc: handler.loginMFA,
+			requestBody: map[string]interface{}{"challengeToken": "challenge", "code": "000000"},
+			setupMocks: func() {
+				mockMFAService.EXPECT().CompleteLogin(gomock.Any(), "challenge", "000000").
+					Return("", apperrors.ErrInvalidMFACode)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedField:  "code",
+		},
+		{
+			name:           "Second step login without code",
+			handlerFunc:    handler.loginMFA,
+			requestBody:    map[string]interface{}{"challengeToken": "challenge"},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "code",
+		},
+		{
+			name:        "Enrollment started",
+			handlerFunc: handler.enrollTOTP,
+			setupMocks: func() {
+				mockMFAService.EXPECT().EnrollTOTP(gomock.Any(), userID).
+					Return(&models.TOTPEnrollment{Secret: "SECRET", ProvisioningURI: "otpauth://totp/x"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedField:  "provisioningUri",
+		},
+		{
+			name:        "Enrollment when already enabled",
+			handlerFunc: handler.enrollTOTP,
+			setupMocks: func() {
+				mockMFAService.EXPECT().EnrollTOTP(gomock.Any(), userID).Return(nil, apperrors.ErrMFAAlreadyEnabled)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedField:  "code",
+		},
+		{
+			name:        "Enrollment confirmed",
+			handlerFunc: handler.confirmTOTP,
+			requestBody: map[string]interface{}{"code": "123456"},
+			setupMocks: func() {
+				mockMFAService.EXPECT().ConfirmTOTP(gomock.Any(), userID, "123456").Return([]string{"abcde-12345"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedField:  "recoveryCodes",
+		},
+		{
+			name:        "Disabling mandatory MFA",
+			handlerFunc: handler.disableTOTP,
+			requestBody: map[string]interface{}{"code": "123456"},
+			setupMocks: func() {
+				mockMFAService.EXPECT().DisableTOTP(gomock.Any(), userID, "123456").Return(apperrors.ErrMFACannotBeDisabled)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedField:  "code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = req
+			c.Set(string(userIDKey), userID)
+
+			tt.handlerFunc(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+
+			if tt.expectedField != "" {
+				var responseBody map[string]interface{}
+				json.Unmarshal(resp.Body.Bytes(), &responseBody)
+				assert.Contains(t, responseBody, tt.expectedField)
+			}
+		})
+	}
+}
+
+func TestHandler_mfaPolicyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		required    bool
+		role        string
+		mfa         bool
+		wantAborted bool
+	}{
+		{name: "Policy disabled", required: false, role: models.RoleModerator, mfa: false, wantAborted: false},
+		{name: "Moderator without second factor", required: true, role: models.RoleModerator, mfa: false, wantAborted: true},
+		{name: "Moderator with second factor", required: true, role: models.RoleModerator, mfa: true, wantAborted: false},
+		{name: "Employee is not affected", required: true, role: models.RoleEmployee, mfa: false, wantAborted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{
+				MFA: config.MFAConfig{RequiredForPrivileged: tt.required},
+			})
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/pvz", nil)
+			c.Set(string(userRoleKey), tt.role)
+			c.Set(string(userMFAKey), tt.mfa)
+
+			handler.mfaPolicyMiddleware()(c)
+
+			assert.Equal(t, tt.wantAborted, c.IsAborted())
+			if tt.wantAborted {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			}
+		})
+	}
+}
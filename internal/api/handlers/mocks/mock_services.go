@@ -5,9 +5,13 @@
 package mocks
 
 import (
+	auth "avito-backend-trainee-assignment-spring-2025/internal/auth"
 	models "avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	context "context"
+	io "io"
+	http "net/http"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -36,19 +40,33 @@ func (m *MockUserServiceInterface) EXPECT() *MockUserServiceInterfaceMockRecorde
 	return m.recorder
 }
 
+// CheckAccessToken mocks base method.
+func (m *MockUserServiceInterface) CheckAccessToken(ctx context.Context, claims *auth.Claims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckAccessToken", ctx, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckAccessToken indicates an expected call of CheckAccessToken.
+func (mr *MockUserServiceInterfaceMockRecorder) CheckAccessToken(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAccessToken", reflect.TypeOf((*MockUserServiceInterface)(nil).CheckAccessToken), ctx, claims)
+}
+
 // DummyLogin mocks base method.
-func (m *MockUserServiceInterface) DummyLogin(role string) (string, error) {
+func (m *MockUserServiceInterface) DummyLogin(ctx context.Context, role string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DummyLogin", role)
+	ret := m.ctrl.Call(m, "DummyLogin", ctx, role)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DummyLogin indicates an expected call of DummyLogin.
-func (mr *MockUserServiceInterfaceMockRecorder) DummyLogin(role interface{}) *gomock.Call {
+func (mr *MockUserServiceInterfaceMockRecorder) DummyLogin(ctx, role interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DummyLogin", reflect.TypeOf((*MockUserServiceInterface)(nil).DummyLogin), role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DummyLogin", reflect.TypeOf((*MockUserServiceInterface)(nil).DummyLogin), ctx, role)
 }
 
 // GetUserByID mocks base method.
@@ -67,10 +85,10 @@ func (mr *MockUserServiceInterfaceMockRecorder) GetUserByID(ctx, id interface{})
 }
 
 // Login mocks base method.
-func (m *MockUserServiceInterface) Login(ctx context.Context, email, password string) (string, error) {
+func (m *MockUserServiceInterface) Login(ctx context.Context, email, password string) (*models.LoginResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Login", ctx, email, password)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(*models.LoginResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -96,6 +114,334 @@ func (mr *MockUserServiceInterfaceMockRecorder) Register(ctx, email, password, r
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserServiceInterface)(nil).Register), ctx, email, password, role)
 }
 
+// SetLanguage mocks base method.
+func (m *MockUserServiceInterface) SetLanguage(ctx context.Context, userID uuid.UUID, language string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLanguage", ctx, userID, language)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLanguage indicates an expected call of SetLanguage.
+func (mr *MockUserServiceInterfaceMockRecorder) SetLanguage(ctx, userID, language interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLanguage", reflect.TypeOf((*MockUserServiceInterface)(nil).SetLanguage), ctx, userID, language)
+}
+
+// MockPasswordServiceInterface is a mock of PasswordServiceInterface interface.
+type MockPasswordServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordServiceInterfaceMockRecorder
+}
+
+// MockPasswordServiceInterfaceMockRecorder is the mock recorder for MockPasswordServiceInterface.
+type MockPasswordServiceInterfaceMockRecorder struct {
+	mock *MockPasswordServiceInterface
+}
+
+// NewMockPasswordServiceInterface creates a new mock instance.
+func NewMockPasswordServiceInterface(ctrl *gomock.Controller) *MockPasswordServiceInterface {
+	mock := &MockPasswordServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockPasswordServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordServiceInterface) EXPECT() *MockPasswordServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// ChangePassword mocks base method.
+func (m *MockPasswordServiceInterface) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, currentPassword, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockPasswordServiceInterfaceMockRecorder) ChangePassword(ctx, userID, currentPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockPasswordServiceInterface)(nil).ChangePassword), ctx, userID, currentPassword, newPassword)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockPasswordServiceInterface) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockPasswordServiceInterfaceMockRecorder) RequestPasswordReset(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockPasswordServiceInterface)(nil).RequestPasswordReset), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockPasswordServiceInterface) ResetPassword(ctx context.Context, token, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockPasswordServiceInterfaceMockRecorder) ResetPassword(ctx, token, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockPasswordServiceInterface)(nil).ResetPassword), ctx, token, newPassword)
+}
+
+// MockMFAServiceInterface is a mock of MFAServiceInterface interface.
+type MockMFAServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFAServiceInterfaceMockRecorder
+}
+
+// MockMFAServiceInterfaceMockRecorder is the mock recorder for MockMFAServiceInterface.
+type MockMFAServiceInterfaceMockRecorder struct {
+	mock *MockMFAServiceInterface
+}
+
+// NewMockMFAServiceInterface creates a new mock instance.
+func NewMockMFAServiceInterface(ctrl *gomock.Controller) *MockMFAServiceInterface {
+	mock := &MockMFAServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockMFAServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFAServiceInterface) EXPECT() *MockMFAServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CompleteLogin mocks base method.
+func (m *MockMFAServiceInterface) CompleteLogin(ctx context.Context, challengeToken, code string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteLogin", ctx, challengeToken, code)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteLogin indicates an expected call of CompleteLogin.
+func (mr *MockMFAServiceInterfaceMockRecorder) CompleteLogin(ctx, challengeToken, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteLogin", reflect.TypeOf((*MockMFAServiceInterface)(nil).CompleteLogin), ctx, challengeToken, code)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockMFAServiceInterface) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockMFAServiceInterfaceMockRecorder) ConfirmTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockMFAServiceInterface)(nil).ConfirmTOTP), ctx, userID, code)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockMFAServiceInterface) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockMFAServiceInterfaceMockRecorder) DisableTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockMFAServiceInterface)(nil).DisableTOTP), ctx, userID, code)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockMFAServiceInterface) EnrollTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, userID)
+	ret0, _ := ret[0].(*models.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockMFAServiceInterfaceMockRecorder) EnrollTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockMFAServiceInterface)(nil).EnrollTOTP), ctx, userID)
+}
+
+// MockServiceAccountServiceInterface is a mock of ServiceAccountServiceInterface interface.
+type MockServiceAccountServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceAccountServiceInterfaceMockRecorder
+}
+
+// MockServiceAccountServiceInterfaceMockRecorder is the mock recorder for MockServiceAccountServiceInterface.
+type MockServiceAccountServiceInterfaceMockRecorder struct {
+	mock *MockServiceAccountServiceInterface
+}
+
+// NewMockServiceAccountServiceInterface creates a new mock instance.
+func NewMockServiceAccountServiceInterface(ctrl *gomock.Controller) *MockServiceAccountServiceInterface {
+	mock := &MockServiceAccountServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockServiceAccountServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceAccountServiceInterface) EXPECT() *MockServiceAccountServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockServiceAccountServiceInterface) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, rawKey)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockServiceAccountServiceInterfaceMockRecorder) Authenticate(ctx, rawKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockServiceAccountServiceInterface)(nil).Authenticate), ctx, rawKey)
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockServiceAccountServiceInterface) CreateServiceAccount(ctx context.Context, name, description string, createdBy uuid.UUID) (*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServiceAccount", ctx, name, description, createdBy)
+	ret0, _ := ret[0].(*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockServiceAccountServiceInterfaceMockRecorder) CreateServiceAccount(ctx, name, description, createdBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockServiceAccountServiceInterface)(nil).CreateServiceAccount), ctx, name, description, createdBy)
+}
+
+// IssueAPIKey mocks base method.
+func (m *MockServiceAccountServiceInterface) IssueAPIKey(ctx context.Context, serviceAccountID uuid.UUID, scopes []string, pvzIDs []uuid.UUID, expiresAt *time.Time) (*models.APIKey, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueAPIKey", ctx, serviceAccountID, scopes, pvzIDs, expiresAt)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// IssueAPIKey indicates an expected call of IssueAPIKey.
+func (mr *MockServiceAccountServiceInterfaceMockRecorder) IssueAPIKey(ctx, serviceAccountID, scopes, pvzIDs, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueAPIKey", reflect.TypeOf((*MockServiceAccountServiceInterface)(nil).IssueAPIKey), ctx, serviceAccountID, scopes, pvzIDs, expiresAt)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockServiceAccountServiceInterface) ListAPIKeys(ctx context.Context, serviceAccountID uuid.UUID) ([]*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, serviceAccountID)
+	ret0, _ := ret[0].([]*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockServiceAccountServiceInterfaceMockRecorder) ListAPIKeys(ctx, serviceAccountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockServiceAccountServiceInterface)(nil).ListAPIKeys), ctx, serviceAccountID)
+}
+
+// ListServiceAccounts mocks base method.
+func (m *MockServiceAccountServiceInterface) ListServiceAccounts(ctx context.Context) ([]*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServiceAccounts", ctx)
+	ret0, _ := ret[0].([]*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServiceAccounts indicates an expected call of ListServiceAccounts.
+func (mr *MockServiceAccountServiceInterfaceMockRecorder) ListServiceAccounts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServiceAccounts", reflect.TypeOf((*MockServiceAccountServiceInterface)(nil).ListServiceAccounts), ctx)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockServiceAccountServiceInterface) RevokeAPIKey(ctx context.Context, keyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockServiceAccountServiceInterfaceMockRecorder) RevokeAPIKey(ctx, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockServiceAccountServiceInterface)(nil).RevokeAPIKey), ctx, keyID)
+}
+
+// MockAuditServiceInterface is a mock of AuditServiceInterface interface.
+type MockAuditServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditServiceInterfaceMockRecorder
+}
+
+// MockAuditServiceInterfaceMockRecorder is the mock recorder for MockAuditServiceInterface.
+type MockAuditServiceInterfaceMockRecorder struct {
+	mock *MockAuditServiceInterface
+}
+
+// NewMockAuditServiceInterface creates a new mock instance.
+func NewMockAuditServiceInterface(ctrl *gomock.Controller) *MockAuditServiceInterface {
+	mock := &MockAuditServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockAuditServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditServiceInterface) EXPECT() *MockAuditServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// ListAuditLog mocks base method.
+func (m *MockAuditServiceInterface) ListAuditLog(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLog", ctx, filter)
+	ret0, _ := ret[0].([]*models.AuditEntry)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAuditLog indicates an expected call of ListAuditLog.
+func (mr *MockAuditServiceInterfaceMockRecorder) ListAuditLog(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLog", reflect.TypeOf((*MockAuditServiceInterface)(nil).ListAuditLog), ctx, filter)
+}
+
+// VerifyAuditChain mocks base method.
+func (m *MockAuditServiceInterface) VerifyAuditChain(ctx context.Context) (*models.AuditVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAuditChain", ctx)
+	ret0, _ := ret[0].(*models.AuditVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAuditChain indicates an expected call of VerifyAuditChain.
+func (mr *MockAuditServiceInterfaceMockRecorder) VerifyAuditChain(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAuditChain", reflect.TypeOf((*MockAuditServiceInterface)(nil).VerifyAuditChain), ctx)
+}
+
 // MockPVZServiceInterface is a mock of PVZServiceInterface interface.
 type MockPVZServiceInterface struct {
 	ctrl     *gomock.Controller
@@ -134,12 +480,27 @@ func (mr *MockPVZServiceInterfaceMockRecorder) CreatePVZ(ctx, city interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePVZ", reflect.TypeOf((*MockPVZServiceInterface)(nil).CreatePVZ), ctx, city)
 }
 
+// FindNearbyPVZ mocks base method.
+func (m *MockPVZServiceInterface) FindNearbyPVZ(ctx context.Context, filter models.NearbyFilter) ([]models.PVZWithDistance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindNearbyPVZ", ctx, filter)
+	ret0, _ := ret[0].([]models.PVZWithDistance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindNearbyPVZ indicates an expected call of FindNearbyPVZ.
+func (mr *MockPVZServiceInterfaceMockRecorder) FindNearbyPVZ(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindNearbyPVZ", reflect.TypeOf((*MockPVZServiceInterface)(nil).FindNearbyPVZ), ctx, filter)
+}
+
 // GetAllPVZ mocks base method.
-func (m *MockPVZServiceInterface) GetAllPVZ(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, int, error) {
+func (m *MockPVZServiceInterface) GetAllPVZ(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, *models.PVZPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAllPVZ", ctx, filter)
 	ret0, _ := ret[0].([]*models.PVZ)
-	ret1, _ := ret[1].(int)
+	ret1, _ := ret[1].(*models.PVZPage)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
@@ -151,11 +512,11 @@ func (mr *MockPVZServiceInterfaceMockRecorder) GetAllPVZ(ctx, filter interface{}
 }
 
 // GetAllPVZWithReceptions mocks base method.
-func (m *MockPVZServiceInterface) GetAllPVZWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, int, error) {
+func (m *MockPVZServiceInterface) GetAllPVZWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAllPVZWithReceptions", ctx, filter)
 	ret0, _ := ret[0].([]models.PVZWithReceptions)
-	ret1, _ := ret[1].(int)
+	ret1, _ := ret[1].(*models.PVZPage)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
@@ -166,6 +527,21 @@ func (mr *MockPVZServiceInterfaceMockRecorder) GetAllPVZWithReceptions(ctx, filt
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPVZWithReceptions", reflect.TypeOf((*MockPVZServiceInterface)(nil).GetAllPVZWithReceptions), ctx, filter)
 }
 
+// GetOverloadedPVZ mocks base method.
+func (m *MockPVZServiceInterface) GetOverloadedPVZ(ctx context.Context, threshold float64, allowedIDs []uuid.UUID) ([]models.PVZUtilization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverloadedPVZ", ctx, threshold, allowedIDs)
+	ret0, _ := ret[0].([]models.PVZUtilization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverloadedPVZ indicates an expected call of GetOverloadedPVZ.
+func (mr *MockPVZServiceInterfaceMockRecorder) GetOverloadedPVZ(ctx, threshold, allowedIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverloadedPVZ", reflect.TypeOf((*MockPVZServiceInterface)(nil).GetOverloadedPVZ), ctx, threshold, allowedIDs)
+}
+
 // GetPVZByID mocks base method.
 func (m *MockPVZServiceInterface) GetPVZByID(ctx context.Context, id uuid.UUID) (*models.PVZ, error) {
 	m.ctrl.T.Helper()
@@ -181,6 +557,21 @@ func (mr *MockPVZServiceInterfaceMockRecorder) GetPVZByID(ctx, id interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPVZByID", reflect.TypeOf((*MockPVZServiceInterface)(nil).GetPVZByID), ctx, id)
 }
 
+// UpdatePVZ mocks base method.
+func (m *MockPVZServiceInterface) UpdatePVZ(ctx context.Context, id uuid.UUID, update models.PVZUpdate, precondition models.Precondition) (*models.PVZ, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePVZ", ctx, id, update, precondition)
+	ret0, _ := ret[0].(*models.PVZ)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePVZ indicates an expected call of UpdatePVZ.
+func (mr *MockPVZServiceInterfaceMockRecorder) UpdatePVZ(ctx, id, update, precondition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePVZ", reflect.TypeOf((*MockPVZServiceInterface)(nil).UpdatePVZ), ctx, id, update, precondition)
+}
+
 // MockReceptionServiceInterface is a mock of ReceptionServiceInterface interface.
 type MockReceptionServiceInterface struct {
 	ctrl     *gomock.Controller
@@ -205,18 +596,18 @@ func (m *MockReceptionServiceInterface) EXPECT() *MockReceptionServiceInterfaceM
 }
 
 // CloseReception mocks base method.
-func (m *MockReceptionServiceInterface) CloseReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
+func (m *MockReceptionServiceInterface) CloseReception(ctx context.Context, pvzID uuid.UUID, precondition models.Precondition) (*models.Reception, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CloseReception", ctx, pvzID)
+	ret := m.ctrl.Call(m, "CloseReception", ctx, pvzID, precondition)
 	ret0, _ := ret[0].(*models.Reception)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CloseReception indicates an expected call of CloseReception.
-func (mr *MockReceptionServiceInterfaceMockRecorder) CloseReception(ctx, pvzID interface{}) *gomock.Call {
+func (mr *MockReceptionServiceInterfaceMockRecorder) CloseReception(ctx, pvzID, precondition interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReception", reflect.TypeOf((*MockReceptionServiceInterface)(nil).CloseReception), ctx, pvzID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReception", reflect.TypeOf((*MockReceptionServiceInterface)(nil).CloseReception), ctx, pvzID, precondition)
 }
 
 // CreateReception mocks base method.
@@ -279,6 +670,37 @@ func (mr *MockReceptionServiceInterfaceMockRecorder) GetReceptionByID(ctx, id in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceptionByID", reflect.TypeOf((*MockReceptionServiceInterface)(nil).GetReceptionByID), ctx, id)
 }
 
+// ListPVZReceptions mocks base method.
+func (m *MockReceptionServiceInterface) ListPVZReceptions(ctx context.Context, pvzID uuid.UUID, filter models.ReceptionFilter) ([]*models.Reception, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPVZReceptions", ctx, pvzID, filter)
+	ret0, _ := ret[0].([]*models.Reception)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPVZReceptions indicates an expected call of ListPVZReceptions.
+func (mr *MockReceptionServiceInterfaceMockRecorder) ListPVZReceptions(ctx, pvzID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPVZReceptions", reflect.TypeOf((*MockReceptionServiceInterface)(nil).ListPVZReceptions), ctx, pvzID, filter)
+}
+
+// ReceptionReport mocks base method.
+func (m *MockReceptionServiceInterface) ReceptionReport(ctx context.Context, filter models.ReceptionReportFilter) ([]models.ReceptionReportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceptionReport", ctx, filter)
+	ret0, _ := ret[0].([]models.ReceptionReportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceptionReport indicates an expected call of ReceptionReport.
+func (mr *MockReceptionServiceInterfaceMockRecorder) ReceptionReport(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceptionReport", reflect.TypeOf((*MockReceptionServiceInterface)(nil).ReceptionReport), ctx, filter)
+}
+
 // MockProductServiceInterface is a mock of ProductServiceInterface interface.
 type MockProductServiceInterface struct {
 	ctrl     *gomock.Controller
@@ -303,12 +725,13 @@ func (m *MockProductServiceInterface) EXPECT() *MockProductServiceInterfaceMockR
 }
 
 // AddProduct mocks base method.
-func (m *MockProductServiceInterface) AddProduct(ctx context.Context, productType string, pvzID uuid.UUID) (*models.Product, error) {
+func (m *MockProductServiceInterface) AddProduct(ctx context.Context, productType string, pvzID uuid.UUID) (*models.Product, []models.CapacityUsage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "AddProduct", ctx, productType, pvzID)
 	ret0, _ := ret[0].(*models.Product)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].([]models.CapacityUsage)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // AddProduct indicates an expected call of AddProduct.
@@ -360,3 +783,229 @@ func (mr *MockProductServiceInterfaceMockRecorder) GetProductsByReceptionID(ctx,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductsByReceptionID", reflect.TypeOf((*MockProductServiceInterface)(nil).GetProductsByReceptionID), ctx, receptionID)
 }
+
+// MockExportServiceInterface is a mock of ExportServiceInterface interface.
+type MockExportServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportServiceInterfaceMockRecorder
+}
+
+// MockExportServiceInterfaceMockRecorder is the mock recorder for MockExportServiceInterface.
+type MockExportServiceInterfaceMockRecorder struct {
+	mock *MockExportServiceInterface
+}
+
+// NewMockExportServiceInterface creates a new mock instance.
+func NewMockExportServiceInterface(ctrl *gomock.Controller) *MockExportServiceInterface {
+	mock := &MockExportServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockExportServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportServiceInterface) EXPECT() *MockExportServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockExportServiceInterface) Export(ctx context.Context, req models.ExportRequest, w io.Writer) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, req, w)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockExportServiceInterfaceMockRecorder) Export(ctx, req, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockExportServiceInterface)(nil).Export), ctx, req, w)
+}
+
+// GetExportFile mocks base method.
+func (m *MockExportServiceInterface) GetExportFile(ctx context.Context, id uuid.UUID) (*models.ExportJob, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExportFile", ctx, id)
+	ret0, _ := ret[0].(*models.ExportJob)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetExportFile indicates an expected call of GetExportFile.
+func (mr *MockExportServiceInterfaceMockRecorder) GetExportFile(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExportFile", reflect.TypeOf((*MockExportServiceInterface)(nil).GetExportFile), ctx, id)
+}
+
+// GetExportJob mocks base method.
+func (m *MockExportServiceInterface) GetExportJob(ctx context.Context, id uuid.UUID) (*models.ExportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExportJob", ctx, id)
+	ret0, _ := ret[0].(*models.ExportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExportJob indicates an expected call of GetExportJob.
+func (mr *MockExportServiceInterfaceMockRecorder) GetExportJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExportJob", reflect.TypeOf((*MockExportServiceInterface)(nil).GetExportJob), ctx, id)
+}
+
+// StartExportJob mocks base method.
+func (m *MockExportServiceInterface) StartExportJob(ctx context.Context, req models.ExportRequest) (*models.ExportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartExportJob", ctx, req)
+	ret0, _ := ret[0].(*models.ExportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartExportJob indicates an expected call of StartExportJob.
+func (mr *MockExportServiceInterfaceMockRecorder) StartExportJob(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartExportJob", reflect.TypeOf((*MockExportServiceInterface)(nil).StartExportJob), ctx, req)
+}
+
+// MockImportServiceInterface is a mock of ImportServiceInterface interface.
+type MockImportServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportServiceInterfaceMockRecorder
+}
+
+// MockImportServiceInterfaceMockRecorder is the mock recorder for MockImportServiceInterface.
+type MockImportServiceInterfaceMockRecorder struct {
+	mock *MockImportServiceInterface
+}
+
+// NewMockImportServiceInterface creates a new mock instance.
+func NewMockImportServiceInterface(ctrl *gomock.Controller) *MockImportServiceInterface {
+	mock := &MockImportServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockImportServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImportServiceInterface) EXPECT() *MockImportServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Import mocks base method.
+func (m *MockImportServiceInterface) Import(ctx context.Context, req models.ImportRequest, r io.Reader) (*models.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, req, r)
+	ret0, _ := ret[0].(*models.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockImportServiceInterfaceMockRecorder) Import(ctx, req, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockImportServiceInterface)(nil).Import), ctx, req, r)
+}
+
+// MockEventServiceInterface is a mock of EventServiceInterface interface.
+type MockEventServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventServiceInterfaceMockRecorder
+}
+
+// MockEventServiceInterfaceMockRecorder is the mock recorder for MockEventServiceInterface.
+type MockEventServiceInterfaceMockRecorder struct {
+	mock *MockEventServiceInterface
+}
+
+// NewMockEventServiceInterface creates a new mock instance.
+func NewMockEventServiceInterface(ctrl *gomock.Controller) *MockEventServiceInterface {
+	mock := &MockEventServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockEventServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventServiceInterface) EXPECT() *MockEventServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockEventServiceInterface) Subscribe(ctx context.Context, filter models.EventFilter, lastEventID int64) (<-chan *models.PVZEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, filter, lastEventID)
+	ret0, _ := ret[0].(<-chan *models.PVZEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockEventServiceInterfaceMockRecorder) Subscribe(ctx, filter, lastEventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockEventServiceInterface)(nil).Subscribe), ctx, filter, lastEventID)
+}
+
+// MockIdempotencyServiceInterface is a mock of IdempotencyServiceInterface interface.
+type MockIdempotencyServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyServiceInterfaceMockRecorder
+}
+
+// MockIdempotencyServiceInterfaceMockRecorder is the mock recorder for MockIdempotencyServiceInterface.
+type MockIdempotencyServiceInterfaceMockRecorder struct {
+	mock *MockIdempotencyServiceInterface
+}
+
+// NewMockIdempotencyServiceInterface creates a new mock instance.
+func NewMockIdempotencyServiceInterface(ctrl *gomock.Controller) *MockIdempotencyServiceInterface {
+	mock := &MockIdempotencyServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyServiceInterface) EXPECT() *MockIdempotencyServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockIdempotencyServiceInterface) Begin(ctx context.Context, actorID uuid.UUID, key, fingerprint string) (*models.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx, actorID, key, fingerprint)
+	ret0, _ := ret[0].(*models.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIdempotencyServiceInterfaceMockRecorder) Begin(ctx, actorID, key, fingerprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIdempotencyServiceInterface)(nil).Begin), ctx, actorID, key, fingerprint)
+}
+
+// Complete mocks base method.
+func (m *MockIdempotencyServiceInterface) Complete(ctx context.Context, record *models.IdempotencyRecord, statusCode int, header http.Header, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, record, statusCode, header, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyServiceInterfaceMockRecorder) Complete(ctx, record, statusCode, header, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyServiceInterface)(nil).Complete), ctx, record, statusCode, header, body)
+}
+
+// Release mocks base method.
+func (m *MockIdempotencyServiceInterface) Release(ctx context.Context, record *models.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIdempotencyServiceInterfaceMockRecorder) Release(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIdempotencyServiceInterface)(nil).Release), ctx, record)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+func (h *Handler) changePassword(c *gin.Context) {
+	var req dto.PostMePasswordJSONRequestBody
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in changePassword")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+		return
+	}
+
+	userID, ok := c.Get(string(userIDKey))
+	if !ok {
+		respondError(c, apperrors.ErrUnauthenticated)
+		return
+	}
+
+	err := h.passwordService.ChangePassword(c.Request.Context(), userID.(uuid.UUID), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("user_id", userID.(uuid.UUID).String()).Msg("Password change failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+func (h *Handler) requestPasswordReset(c *gin.Context) {
+	var req dto.PostPasswordResetJSONRequestBody
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in requestPasswordReset")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+		return
+	}
+
+	if err := h.passwordService.RequestPasswordReset(c.Request.Context(), string(req.Email)); err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("email", string(req.Email)).Msg("Password reset request failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists, a password reset code has been sent"})
+}
+
+func (h *Handler) confirmPasswordReset(c *gin.Context) {
+	var req dto.PostPasswordResetConfirmJSONRequestBody
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in confirmPasswordReset")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+		return
+	}
+
+	if err := h.passwordService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Msg("Password reset failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset successfully"})
+}
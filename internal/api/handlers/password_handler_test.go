@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/auth"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_changePassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPasswordService := mocks.NewMockPasswordServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, mockPasswordService, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name            string
+		requestBody     map[string]interface{}
+		setupMocks      func()
+		expectedStatus  int
+		expectedMessage string
+		expectedCode    string
+	}{
+		{
+			name: "Success change password",
+			requestBody: map[string]interface{}{
+				"currentPassword": "password123",
+				"newPassword":     "newPassword456",
+			},
+			setupMocks: func() {
+				mockPasswordService.EXPECT().
+					ChangePassword(gomock.Any(), userID, "password123", "newPassword456").
+					Return(nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "Password changed successfully",
+		},
+		{
+			name: "Wrong current password",
+			requestBody: map[string]interface{}{
+				"currentPassword": "wrong",
+				"newPassword":     "newPassword456",
+			},
+			setupMocks: func() {
+				mockPasswordService.EXPECT().
+					ChangePassword(gomock.Any(), userID, "wrong", "newPassword456").
+					Return(apperrors.ErrInvalidCredentials)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "invalid_credentials",
+		},
+		{
+			name: "Short new password",
+			requestBody: map[string]interface{}{
+				"currentPassword": "password123",
+				"newPassword":     "123",
+			},
+			setupMocks: func() {
+				// Длину проверяет политика паролей, а не привязка запроса
+				mockPasswordService.EXPECT().
+					ChangePassword(gomock.Any(), userID, "password123", "123").
+					Return(fmt.Errorf("%w: at least 6 characters required", apperrors.ErrInvalidPassword))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest(http.MethodPost, "/me/password", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = req
+			c.Set(string(userIDKey), userID)
+
+			handler.changePassword(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+
+			var responseBody map[string]interface{}
+			json.Unmarshal(resp.Body.Bytes(), &responseBody)
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, responseBody["code"])
+			} else {
+				assert.Equal(t, tt.expectedMessage, responseBody["message"])
+			}
+		})
+	}
+}
+
+func TestHandler_passwordReset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPasswordService := mocks.NewMockPasswordServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, mockPasswordService, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	tests := []struct {
+		name           string
+		path           string
+		handlerFunc    gin.HandlerFunc
+		requestBody    map[string]interface{}
+		setupMocks     func()
+		expectedStatus int
+	}{
+		{
+			name:        "Reset requested",
+			path:        "/password/reset",
+			handlerFunc: handler.requestPasswordReset,
+			requestBody: map[string]interface{}{"email": "user@example.com"},
+			setupMocks: func() {
+				mockPasswordService.EXPECT().RequestPasswordReset(gomock.Any(), "user@example.com").Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "Reset request with invalid email",
+			path:           "/password/reset",
+			handlerFunc:    handler.requestPasswordReset,
+			requestBody:    map[string]interface{}{"email": "not-an-email"},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Reset confirmed",
+			path:        "/password/reset/confirm",
+			handlerFunc: handler.confirmPasswordReset,
+			requestBody: map[string]interface{}{"token": "token", "newPassword": "newPassword456"},
+			setupMocks: func() {
+				mockPasswordService.EXPECT().ResetPassword(gomock.Any(), "token", "newPassword456").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Reset confirm with expired token",
+			path:        "/password/reset/confirm",
+			handlerFunc: handler.confirmPasswordReset,
+			requestBody: map[string]interface{}{"token": "token", "newPassword": "newPassword456"},
+			setupMocks: func() {
+				mockPasswordService.EXPECT().ResetPassword(gomock.Any(), "token", "newPassword456").
+					Return(apperrors.ErrInvalidResetToken)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest(http.MethodPost, tt.path, bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = req
+
+			tt.handlerFunc(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+		})
+	}
+}
+
+func TestAuthMiddleware_RevokedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserServiceInterface(ctrl)
+	testConfig := &config.Config{JWT: config.JWTConfig{Secret: "test-secret"}}
+	handler := NewHandler(mockUserService, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, testConfig)
+
+	userID := uuid.New()
+	token, _ := auth.GenerateToken(userID, "employee", "", "test-secret", time.Hour)
+
+	tests := []struct {
+		name           string
+		checkErr       error
+		expectedStatus int
+	}{
+		{name: "Active token", checkErr: nil, expectedStatus: http.StatusOK},
+		{name: "Token issued before password change", checkErr: apperrors.ErrTokenRevoked, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserService.EXPECT().
+				CheckAccessToken(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, claims *auth.Claims) error {
+					assert.Equal(t, userID, claims.UserID)
+					return tt.checkErr
+				})
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			c.Request = req
+
+			handler.authMiddleware()(c)
+
+			if tt.expectedStatus == http.StatusOK {
+				assert.False(t, c.IsAborted())
+				assert.Equal(t, userID, c.MustGet(string(userIDKey)))
+			} else {
+				assert.True(t, c.IsAborted())
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/api/errcode"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/i18n"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const (
+	// problemContentType - тип ответа с ошибкой по RFC 7807.
+	problemContentType = "application/problem+json"
+	// problemType - тип ошибки по RFC 7807. Вид ошибки клиенты различают по code.
+	problemType = "about:blank"
+)
+
+// Коды ошибок отдельных полей запроса.
+const (
+	fieldRequired      = "required"
+	fieldInvalidValue  = "invalid_value"
+	fieldInvalidFormat = "invalid_format"
+	fieldInvalidType   = "invalid_type"
+	fieldOutOfRange    = "out_of_range"
+	fieldInvalid       = "invalid"
+)
+
+func init() {
+	// Ошибки полей называют поле так же, как его передает клиент
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(requestFieldName)
+	}
+}
+
+// respondError отвечает описанием ошибки err в формате application/problem+json.
+// Неизвестная ошибка журналируется, а клиент получает общий ответ 500.
+func respondError(c *gin.Context, err error) {
+	descriptor, known := errcode.Describe(err)
+	if !known {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("path", c.FullPath()).Msg("Unexpected error")
+	}
+
+	writeProblem(c, descriptor, nil)
+}
+
+// abortWithError - respondError для middleware: следующие обработчики не вызываются.
+func abortWithError(c *gin.Context, err error) {
+	c.Abort()
+	respondError(c, err)
+}
+
+// respondBindError отвечает ошибкой cause на тело или параметры запроса, которые не
+// удалось разобрать, и перечисляет в errors поля, не прошедшие проверку.
+func respondBindError(c *gin.Context, cause error, err error) {
+	descriptor, _ := errcode.Describe(cause)
+	writeProblem(c, descriptor, fieldErrors(i18n.FromContext(c.Request.Context()), err))
+}
+
+// recoverProblem отвечает на панику обработчика общей ошибкой 500.
+func recoverProblem(c *gin.Context, recovered any) {
+	abortWithError(c, fmt.Errorf("panic: %v", recovered))
+}
+
+// writeProblem отвечает ошибкой с описанием descriptor. Текст ошибки берется из каталога
+// языка запроса.
+func writeProblem(c *gin.Context, descriptor errcode.Descriptor, fields []dto.FieldError) {
+	lang := i18n.FromContext(c.Request.Context())
+	problem := dto.Problem{
+		Type:   problemType,
+		Title:  http.StatusText(descriptor.Status),
+		Status: descriptor.Status,
+		Detail: i18n.Message(lang, descriptor.Code),
+		Code:   descriptor.Code,
+	}
+	if requestID := models.ActorFromContext(c.Request.Context()).RequestID; requestID != "" {
+		problem.Instance = &requestID
+	}
+	if len(fields) > 0 {
+		problem.Errors = &fields
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.Header("Content-Language", lang)
+	c.JSON(descriptor.Status, problem)
+}
+
+// fieldErrors разбирает ошибку привязки запроса по полям. Синтаксические ошибки JSON
+// к полям не относятся, для них возвращается nil.
+func fieldErrors(lang string, err error) []dto.FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]dto.FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			code, detail := describeFieldError(lang, fieldErr)
+			fields = append(fields, dto.FieldError{Field: fieldErr.Field(), Code: code, Detail: detail})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []dto.FieldError{{
+			Field:  typeErr.Field,
+			Code:   fieldInvalidType,
+			Detail: i18n.Message(lang, "field.type", i18n.Message(lang, "type."+jsonTypeName(typeErr.Type))),
+		}}
+	}
+
+	return nil
+}
+
+func describeFieldError(lang string, fieldErr validator.FieldError) (string, string) {
+	switch fieldErr.Tag() {
+	case "required":
+		return fieldRequired, i18n.Message(lang, "field.required")
+	case "oneof":
+		return fieldInvalidValue, i18n.Message(lang, "field.one_of", strings.ReplaceAll(fieldErr.Param(), " ", ", "))
+	case "email":
+		return fieldInvalidFormat, i18n.Message(lang, "field.email")
+	case "uuid", "uuid4":
+		return fieldInvalidFormat, i18n.Message(lang, "field.uuid")
+	case "min", "gte":
+		if fieldErr.Kind() == reflect.String {
+			return fieldOutOfRange, i18n.Message(lang, "field.min_length", fieldErr.Param())
+		}
+		return fieldOutOfRange, i18n.Message(lang, "field.min", fieldErr.Param())
+	case "max", "lte":
+		if fieldErr.Kind() == reflect.String {
+			return fieldOutOfRange, i18n.Message(lang, "field.max_length", fieldErr.Param())
+		}
+		return fieldOutOfRange, i18n.Message(lang, "field.max", fieldErr.Param())
+	case "gtfield":
+		return fieldOutOfRange, i18n.Message(lang, "field.after", lowerFirst(fieldErr.Param()))
+	default:
+		return fieldInvalid, i18n.Message(lang, "field.invalid")
+	}
+}
+
+// requestFieldName возвращает имя поля из тега json, form или uri.
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// jsonTypeName называет тип JSON-значения, которое ожидалось в поле типа t. Название -
+// часть ключа каталога сообщений.
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
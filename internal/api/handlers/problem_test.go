@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/i18n"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRespondError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name             string
+		err              error
+		requestID        string
+		lang             string
+		expectedStatus   int
+		expectedLanguage string
+		expectedProblem  dto.Problem
+	}{
+		{
+			name:             "Known error",
+			err:              repoerrors.ErrPVZNotFound,
+			requestID:        "req-1",
+			expectedStatus:   http.StatusNotFound,
+			expectedLanguage: "en",
+			expectedProblem: dto.Problem{
+				Type:   "about:blank",
+				Title:  "Not Found",
+				Status: http.StatusNotFound,
+				Detail: "Pickup point not found.",
+				Code:   "pvz_not_found",
+			},
+		},
+		{
+			name:             "Unknown error does not leak",
+			err:              errors.New("pq: connection refused"),
+			requestID:        "req-2",
+			expectedStatus:   http.StatusInternalServerError,
+			expectedLanguage: "en",
+			expectedProblem: dto.Problem{
+				Type:   "about:blank",
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+				Detail: "Internal server error. Please try again later.",
+				Code:   "internal_error",
+			},
+		},
+		{
+			name:             "Without request ID",
+			err:              apperrors.ErrVersionMismatch,
+			expectedStatus:   http.StatusPreconditionFailed,
+			expectedLanguage: "en",
+			expectedProblem: dto.Problem{
+				Type:   "about:blank",
+				Title:  "Precondition Failed",
+				Status: http.StatusPreconditionFailed,
+				Detail: "The resource has been modified since you read it. Reload it and retry.",
+				Code:   "version_mismatch",
+			},
+		},
+		{
+			name:             "Russian",
+			err:              apperrors.ErrInvalidCity,
+			lang:             i18n.Russian,
+			expectedStatus:   http.StatusBadRequest,
+			expectedLanguage: "ru",
+			expectedProblem: dto.Problem{
+				Type:   "about:blank",
+				Title:  "Bad Request",
+				Status: http.StatusBadRequest,
+				Detail: "ПВЗ можно создать только в городах: Москва, Санкт-Петербург, Казань.",
+				Code:   "invalid_city",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/pvz", nil)
+			if tt.requestID != "" {
+				c.Request = c.Request.WithContext(models.WithActor(c.Request.Context(), models.Actor{RequestID: tt.requestID}))
+				tt.expectedProblem.Instance = &tt.requestID
+			}
+			if tt.lang != "" {
+				c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), tt.lang))
+			}
+
+			respondError(c, tt.err)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedLanguage, w.Header().Get("Content-Language"))
+
+			var problem dto.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedProblem, problem)
+			assert.NotContains(t, w.Body.String(), "pq:")
+		})
+	}
+}
+
+func TestRespondBindError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		lang           string
+		expectedFields []dto.FieldError
+	}{
+		{
+			name: "Validation errors by field",
+			body: `{"password":"123","role":"admin"}`,
+			expectedFields: []dto.FieldError{
+				{Field: "email", Code: "required", Detail: "Field is required."},
+				{Field: "role", Code: "invalid_value", Detail: "Must be one of: employee, moderator."},
+			},
+		},
+		{
+			name: "Wrong value type",
+			body: `{"email":"user@example.com","password":123456,"role":"employee"}`,
+			expectedFields: []dto.FieldError{
+				{Field: "password", Code: "invalid_type", Detail: "Value must be a string."},
+			},
+		},
+		{
+			name: "Russian field errors",
+			body: `{"email":"user@example.com","password":true,"role":"employee"}`,
+			lang: i18n.Russian,
+			expectedFields: []dto.FieldError{
+				{Field: "password", Code: "invalid_type", Detail: "Значение должно быть строкой."},
+			},
+		},
+		{
+			name: "Russian validation errors",
+			body: `{"password":"123","role":"admin"}`,
+			lang: i18n.Russian,
+			expectedFields: []dto.FieldError{
+				{Field: "email", Code: "required", Detail: "Обязательное поле."},
+				{Field: "role", Code: "invalid_value", Detail: "Допустимые значения: employee, moderator."},
+			},
+		},
+		{
+			name: "Malformed JSON",
+			body: `{"email":`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/register", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.lang != "" {
+				c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), tt.lang))
+			}
+
+			var req dto.PostRegisterJSONRequestBody
+			err := c.ShouldBindJSON(&req)
+			assert.Error(t, err)
+
+			respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var problem dto.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, "invalid_request_body", problem.Code)
+			if tt.expectedFields == nil {
+				assert.Nil(t, problem.Errors)
+				return
+			}
+			if assert.NotNil(t, problem.Errors) {
+				assert.Equal(t, tt.expectedFields, *problem.Errors)
+			}
+		})
+	}
+}
+
+func TestRecoverProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(gin.CustomRecovery(recoverProblem))
+	router.GET("/panic", func(c *gin.Context) {
+		panic("secret internal state")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"code":"internal_error"`)
+	assert.NotContains(t, w.Body.String(), "secret")
+}
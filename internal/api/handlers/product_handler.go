@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"net/http"
 )
 
@@ -12,29 +14,32 @@ func (h *Handler) addProduct(c *gin.Context) {
 	var req dto.PostProductsJSONRequestBody
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Debug().Err(err).Msg("Invalid request format in addProduct")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid product type specified. Available types: electronics, clothes, shoes."})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in addProduct")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
 		return
 	}
 
 	pvzID, err := uuid.Parse(req.PvzId.String())
 	if err != nil {
-		log.Debug().Err(err).Str("pvz_id", req.PvzId.String()).Msg("Invalid PVZ ID format")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid PVZ ID format"})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", req.PvzId.String()).Msg("Invalid PVZ ID format")
+		respondError(c, apperrors.ErrInvalidPVZID)
+		return
+	}
+
+	if !h.authorizePVZ(c, pvzID) {
 		return
 	}
 
 	productType := string(req.Type)
 
-	product, err := h.productService.AddProduct(c.Request.Context(), productType, pvzID)
+	product, warnings, err := h.productService.AddProduct(c.Request.Context(), productType, pvzID)
 	if err != nil {
-		log.Error().Err(err).
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).
 			Str("type", productType).
 			Str("pvz_id", pvzID.String()).
 			Msg("Product addition failed")
 
-		statusCode, message := getErrorResponse(err)
-		c.JSON(statusCode, gin.H{"message": message})
+		respondError(c, err)
 		return
 	}
 
@@ -44,8 +49,12 @@ func (h *Handler) addProduct(c *gin.Context) {
 		Type:        dto.ProductType(product.Type),
 		ReceptionId: product.ReceptionID,
 	}
+	if len(warnings) > 0 {
+		capacityWarnings := toCapacityUsageDTO(warnings)
+		response.CapacityWarnings = &capacityWarnings
+	}
 
-	log.Info().
+	zerolog.Ctx(c.Request.Context()).Info().
 		Str("product_id", product.ID.String()).
 		Str("type", product.Type).
 		Str("reception_id", product.ReceptionID.String()).
@@ -58,23 +67,70 @@ func (h *Handler) deleteLastProduct(c *gin.Context) {
 	pvzIdParam := c.Param("pvzId")
 	pvzID, err := uuid.Parse(pvzIdParam)
 	if err != nil {
-		log.Debug().Err(err).Str("pvz_id", pvzIdParam).Msg("Invalid PVZ ID format")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid PVZ ID format"})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", pvzIdParam).Msg("Invalid PVZ ID format")
+		respondError(c, apperrors.ErrInvalidPVZID)
+		return
+	}
+
+	if !h.authorizePVZ(c, pvzID) {
 		return
 	}
 
 	err = h.productService.DeleteLastProduct(c.Request.Context(), pvzID)
 	if err != nil {
-		log.Error().Err(err).Str("pvz_id", pvzID.String()).Msg("Product deletion failed")
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("pvz_id", pvzID.String()).Msg("Product deletion failed")
 
-		statusCode, message := getErrorResponse(err)
-		c.JSON(statusCode, gin.H{"message": message})
+		respondError(c, err)
 		return
 	}
 
-	log.Info().
+	zerolog.Ctx(c.Request.Context()).Info().
 		Str("pvz_id", pvzID.String()).
 		Msg("Last product deleted successfully")
 
 	c.JSON(http.StatusOK, gin.H{"message": "Last product deleted successfully"})
 }
+
+func (h *Handler) getProduct(c *gin.Context) {
+	productIdParam := c.Param("productId")
+	productID, err := uuid.Parse(productIdParam)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("product_id", productIdParam).Msg("Invalid product ID format")
+		respondError(c, apperrors.ErrInvalidProductID)
+		return
+	}
+
+	product, err := h.productService.GetProductByID(c.Request.Context(), productID)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("product_id", productID.String()).Msg("Failed to get product")
+
+		respondError(c, err)
+		return
+	}
+
+	// Для API-ключа, ограниченного списком ПВЗ, нужно знать ПВЗ приёмки товара.
+	if key, ok := getAPIKey(c); ok && len(key.PVZIDs) > 0 {
+		reception, err := h.receptionService.GetReceptionByID(c.Request.Context(), product.ReceptionID)
+		if err != nil {
+			zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("reception_id", product.ReceptionID.String()).Msg("Failed to get product reception")
+
+			respondError(c, err)
+			return
+		}
+
+		if !h.authorizePVZ(c, reception.PVZID) {
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, toProductDTO(product))
+}
+
+func toProductDTO(product *models.Product) dto.Product {
+	return dto.Product{
+		Id:          &product.ID,
+		DateTime:    &product.DateTime,
+		Type:        dto.ProductType(product.Type),
+		ReceptionId: product.ReceptionID,
+	}
+}
@@ -2,58 +2,261 @@ package handlers
 
 import (
 	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
 	"net/http"
+	"time"
 )
 
 func (h *Handler) createPVZ(c *gin.Context) {
 	var req dto.PostPvzJSONRequestBody
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Debug().Err(err).Msg("Invalid request format in createPVZ")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request format"})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in createPVZ")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
 		return
 	}
 
 	pvz, err := h.pvzService.CreatePVZ(c.Request.Context(), string(req.City))
 	if err != nil {
-		log.Error().Err(err).Str("city", string(req.City)).Msg("PVZ creation failed")
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("city", string(req.City)).Msg("PVZ creation failed")
 
-		statusCode, message := getErrorResponse(err)
-		c.JSON(statusCode, gin.H{"message": message})
+		respondError(c, err)
 		return
 	}
 
-	response := dto.PVZ{
-		Id:               &pvz.ID,
-		RegistrationDate: &pvz.RegistrationDate,
-		City:             dto.PVZCity(pvz.City),
-	}
+	response := toPVZDTO(pvz)
 
-	log.Info().
+	zerolog.Ctx(c.Request.Context()).Info().
 		Str("pvz_id", pvz.ID.String()).
 		Str("city", pvz.City).
 		Msg("PVZ created successfully")
 
+	setETag(c, pvz.ID, pvz.Version)
 	c.JSON(http.StatusCreated, response)
 }
 
+func (h *Handler) getPVZ(c *gin.Context) {
+	pvzIdParam := c.Param("pvzId")
+	pvzID, err := uuid.Parse(pvzIdParam)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", pvzIdParam).Msg("Invalid PVZ ID format")
+		respondError(c, apperrors.ErrInvalidPVZID)
+		return
+	}
+
+	if !h.authorizePVZ(c, pvzID) {
+		return
+	}
+
+	pvz, err := h.pvzService.GetPVZByID(c.Request.Context(), pvzID)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", pvzID.String()).Msg("Failed to get PVZ")
+
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, pvz.ID, pvz.Version) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toPVZDTO(pvz))
+}
+
+func (h *Handler) updatePVZ(c *gin.Context) {
+	pvzIdParam := c.Param("pvzId")
+	pvzID, err := uuid.Parse(pvzIdParam)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", pvzIdParam).Msg("Invalid PVZ ID format")
+		respondError(c, apperrors.ErrInvalidPVZID)
+		return
+	}
+
+	precondition, ok := requirePrecondition(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PatchPvzPvzIdJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in updatePVZ")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+		return
+	}
+
+	update := models.PVZUpdate{
+		Address:      req.Address,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		Phone:        req.Phone,
+		WorkingHours: fromWorkingHoursDTO(req.WorkingHours),
+		Capacity:     fromCapacityDTO(req.Capacity),
+	}
+	if req.Status != nil {
+		status := string(*req.Status)
+		update.Status = &status
+	}
+
+	pvz, err := h.pvzService.UpdatePVZ(c.Request.Context(), pvzID, update, precondition)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Info().Err(err).Str("pvz_id", pvzID.String()).Msg("PVZ update failed")
+
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, pvz.ID, pvz.Version)
+	c.JSON(http.StatusOK, toPVZDTO(pvz))
+}
+
+func (h *Handler) getPVZUtilization(c *gin.Context) {
+	var params dto.GetPvzUtilizationParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid query parameters in getPVZUtilization")
+		respondBindError(c, apperrors.ErrInvalidQueryParameters, err)
+		return
+	}
+
+	threshold := h.config.Capacity.UtilizationThreshold
+	if params.Threshold != nil {
+		threshold = *params.Threshold
+	}
+
+	// Ключ с доступом к отдельным ПВЗ видит в отчете только их
+	var allowedIDs []uuid.UUID
+	if key, ok := getAPIKey(c); ok {
+		allowedIDs = key.PVZIDs
+	}
+
+	overloaded, err := h.pvzService.GetOverloadedPVZ(c.Request.Context(), threshold, allowedIDs)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Float64("threshold", threshold).Msg("Failed to build PVZ utilization report")
+
+		respondError(c, err)
+		return
+	}
+
+	response := dto.PVZUtilizationReport{
+		Threshold: threshold,
+		Items:     make([]dto.PVZUtilization, 0, len(overloaded)),
+	}
+	for _, item := range overloaded {
+		response.Items = append(response.Items, dto.PVZUtilization{
+			Pvz:         toPVZDTO(item.PVZ),
+			Occupied:    item.Occupied(),
+			Utilization: item.MaxUtilization(),
+			Usage:       toCapacityUsageDTO(item.Usage),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) getNearbyPVZ(c *gin.Context) {
+	var params dto.GetPvzNearbyParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid query parameters in getNearbyPVZ")
+		respondBindError(c, apperrors.ErrInvalidQueryParameters, err)
+		return
+	}
+
+	// Нулевые координаты допустимы, поэтому наличие lat и lon проверяется отдельно.
+	_, hasLat := c.GetQuery("lat")
+	_, hasLon := c.GetQuery("lon")
+	if !hasLat || !hasLon {
+		respondError(c, apperrors.ErrNearbyPointRequired)
+		return
+	}
+
+	filter := models.NearbyFilter{
+		Latitude:     params.Lat,
+		Longitude:    params.Lon,
+		RadiusMeters: models.DefaultNearbyRadiusMeters,
+		Limit:        models.DefaultNearbyLimit,
+	}
+
+	if params.Radius != nil {
+		filter.RadiusMeters = *params.Radius
+	}
+
+	if params.Limit != nil {
+		filter.Limit = *params.Limit
+	}
+
+	if params.OpenNow != nil && *params.OpenNow {
+		now := time.Now()
+		filter.OpenAt = &now
+	}
+
+	if key, ok := getAPIKey(c); ok {
+		filter.PVZIDs = key.PVZIDs
+	}
+
+	nearby, err := h.pvzService.FindNearbyPVZ(c.Request.Context(), filter)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Nearby PVZ search failed")
+
+		respondError(c, err)
+		return
+	}
+
+	response := dto.PVZNearbyList{Items: make([]dto.PVZNearby, 0, len(nearby))}
+	for _, item := range nearby {
+		response.Items = append(response.Items, dto.PVZNearby{
+			Pvz:            toPVZDTO(item.PVZ),
+			DistanceMeters: item.DistanceMeters,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *Handler) getPVZList(c *gin.Context) {
 	var filterDTO dto.GetPvzParams
 
 	if err := c.ShouldBindQuery(&filterDTO); err != nil {
-		log.Debug().Err(err).Msg("Invalid query parameters in getPVZList")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid query parameters"})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid query parameters in getPVZList")
+		respondBindError(c, apperrors.ErrInvalidQueryParameters, err)
 		return
 	}
 
 	filter := models.PVZFilter{
-		StartDate: filterDTO.StartDate,
-		EndDate:   filterDTO.EndDate,
-		Page:      1,
-		Limit:     10,
+		StartDate:        filterDTO.StartDate,
+		EndDate:          filterDTO.EndDate,
+		RegisteredFrom:   filterDTO.RegisteredFrom,
+		RegisteredTo:     filterDTO.RegisteredTo,
+		HasOpenReception: filterDTO.HasOpenReception,
+		Page:             1,
+		Limit:            10,
+		WithTotal:        true,
+	}
+
+	if filterDTO.City != nil {
+		for _, city := range *filterDTO.City {
+			filter.Cities = append(filter.Cities, string(city))
+		}
+	}
+
+	if filterDTO.ReceptionStatus != nil {
+		filter.ReceptionStatus = string(*filterDTO.ReceptionStatus)
+	}
+
+	if filterDTO.ProductType != nil {
+		filter.ProductType = string(*filterDTO.ProductType)
+	}
+
+	if filterDTO.SortBy != nil {
+		filter.SortBy = string(*filterDTO.SortBy)
+	}
+
+	if filterDTO.SortOrder != nil {
+		filter.SortDesc = *filterDTO.SortOrder == dto.Desc
 	}
 
 	if filterDTO.Page != nil && *filterDTO.Page > 0 {
@@ -64,28 +267,63 @@ func (h *Handler) getPVZList(c *gin.Context) {
 		filter.Limit = *filterDTO.Limit
 	}
 
-	pvzList, total, err := h.pvzService.GetAllPVZWithReceptions(c.Request.Context(), filter)
+	if filterDTO.WithTotal != nil {
+		filter.WithTotal = *filterDTO.WithTotal
+	}
+
+	if filterDTO.ProductsLimit != nil {
+		filter.ProductsLimit = *filterDTO.ProductsLimit
+	}
+
+	if filterDTO.ProductCountsOnly != nil {
+		filter.ProductCountsOnly = *filterDTO.ProductCountsOnly
+	}
+
+	if filterDTO.Cursor != nil && *filterDTO.Cursor != "" {
+		cursor, err := models.DecodePVZCursor(*filterDTO.Cursor)
+		if err != nil {
+			zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid cursor in getPVZList")
+
+			respondError(c, err)
+			return
+		}
+		filter.Cursor = cursor
+		filter.Page = 0
+	}
+
+	// Ключ с доступом к отдельным ПВЗ видит в списке только их
+	if key, ok := getAPIKey(c); ok {
+		filter.PVZIDs = key.PVZIDs
+	}
+
+	if err := filter.Validate(); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid filter in getPVZList")
+
+		respondError(c, err)
+		return
+	}
+
+	pvzList, page, err := h.pvzService.GetAllPVZWithReceptions(c.Request.Context(), filter)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get PVZ list")
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Msg("Failed to get PVZ list")
 
-		statusCode, message := getErrorResponse(err)
-		c.JSON(statusCode, gin.H{"message": message})
+		respondError(c, err)
 		return
 	}
 
-	response := mapPVZListToDTO(pvzList, total, filter.Page, filter.Limit)
+	response := mapPVZListToDTO(pvzList, page, filter.Page, filter.Limit)
 
-	log.Info().
-		Int("total_count", total).
+	zerolog.Ctx(c.Request.Context()).Info().
 		Int("returned_count", len(pvzList)).
 		Int("page", filter.Page).
 		Int("limit", filter.Limit).
+		Bool("has_next", page.NextCursor != "").
 		Msg("Retrieved PVZ list successfully")
 
 	c.JSON(http.StatusOK, response)
 }
 
-func mapPVZListToDTO(pvzList []models.PVZWithReceptions, total, page, limit int) dto.PVZListResponseDTO {
+func mapPVZListToDTO(pvzList []models.PVZWithReceptions, pageInfo *models.PVZPage, page, limit int) dto.PVZListResponseDTO {
 	items := make([]dto.PVZWithReceptionsResponseDTO, len(pvzList))
 
 	for i, pvz := range pvzList {
@@ -103,6 +341,11 @@ func mapPVZListToDTO(pvzList []models.PVZWithReceptions, total, page, limit int)
 				}
 			}
 
+			productCounts := reception.ProductCounts
+			if productCounts == nil {
+				productCounts = map[string]int{}
+			}
+
 			receptions = append(receptions, dto.ReceptionWithProductsDTO{
 				Reception: dto.Reception{
 					Id:       &reception.ID,
@@ -110,24 +353,158 @@ func mapPVZListToDTO(pvzList []models.PVZWithReceptions, total, page, limit int)
 					PvzId:    reception.PVZID,
 					Status:   dto.ReceptionStatus(reception.Status),
 				},
-				Products: products,
+				Products:      products,
+				ProductCounts: productCounts,
+				ProductsTotal: reception.ProductsTotal(),
 			})
 		}
 
 		items[i] = dto.PVZWithReceptionsResponseDTO{
-			PVZ: dto.PVZ{
-				Id:               &pvz.PVZ.ID,
-				RegistrationDate: &pvz.PVZ.RegistrationDate,
-				City:             dto.PVZCity(pvz.PVZ.City),
-			},
+			PVZ:        toPVZDTO(pvz.PVZ),
 			Receptions: receptions,
 		}
 	}
 
 	return dto.PVZListResponseDTO{
 		Items:      items,
-		TotalCount: total,
+		TotalCount: pageInfo.Total,
 		Page:       page,
 		Limit:      limit,
+		NextCursor: pageInfo.NextCursor,
+		PrevCursor: pageInfo.PrevCursor,
+	}
+}
+
+func toPVZDTO(pvz *models.PVZ) dto.PVZ {
+	response := dto.PVZ{
+		Id:               &pvz.ID,
+		RegistrationDate: &pvz.RegistrationDate,
+		City:             dto.PVZCity(pvz.City),
+		Latitude:         pvz.Latitude,
+		Longitude:        pvz.Longitude,
+		WorkingHours:     toWorkingHoursDTO(pvz.WorkingHours),
+		Capacity:         toCapacityDTO(pvz.Capacity),
+	}
+	if pvz.Address != "" {
+		response.Address = &pvz.Address
+	}
+	if pvz.Phone != "" {
+		response.Phone = &pvz.Phone
+	}
+	if pvz.Status != "" {
+		status := dto.PVZStatus(pvz.Status)
+		response.Status = &status
+	}
+	return response
+}
+
+func toWorkingHoursDTO(workingHours *models.WorkingHours) *dto.WorkingHours {
+	if workingHours == nil {
+		return nil
+	}
+
+	result := &dto.WorkingHours{Weekly: make([]dto.DayHours, 0, len(workingHours.Weekly))}
+	for _, day := range workingHours.Weekly {
+		result.Weekly = append(result.Weekly, dto.DayHours{
+			Day:   dto.DayHoursDay(day.Day),
+			Open:  day.Open,
+			Close: day.Close,
+		})
+	}
+
+	if len(workingHours.Exceptions) > 0 {
+		exceptions := make([]dto.HoursException, 0, len(workingHours.Exceptions))
+		for _, exception := range workingHours.Exceptions {
+			item := dto.HoursException{Date: exception.Date, Closed: exception.Closed}
+			if !exception.Closed {
+				item.Open = &exception.Open
+				item.Close = &exception.Close
+			}
+			exceptions = append(exceptions, item)
+		}
+		result.Exceptions = &exceptions
+	}
+
+	if workingHours.Timezone != "" {
+		result.Timezone = &workingHours.Timezone
+	}
+
+	return result
+}
+
+func fromWorkingHoursDTO(workingHours *dto.WorkingHours) *models.WorkingHours {
+	if workingHours == nil {
+		return nil
+	}
+
+	result := &models.WorkingHours{Weekly: make([]models.DayHours, 0, len(workingHours.Weekly))}
+	for _, day := range workingHours.Weekly {
+		result.Weekly = append(result.Weekly, models.DayHours{
+			Day:   string(day.Day),
+			Open:  day.Open,
+			Close: day.Close,
+		})
+	}
+
+	if workingHours.Exceptions != nil {
+		for _, exception := range *workingHours.Exceptions {
+			item := models.HoursException{Date: exception.Date, Closed: exception.Closed}
+			if exception.Open != nil {
+				item.Open = *exception.Open
+			}
+			if exception.Close != nil {
+				item.Close = *exception.Close
+			}
+			result.Exceptions = append(result.Exceptions, item)
+		}
+	}
+
+	if workingHours.Timezone != nil {
+		result.Timezone = *workingHours.Timezone
+	}
+
+	return result
+}
+
+func toCapacityDTO(capacity *models.PVZCapacity) *dto.PVZCapacity {
+	if capacity == nil {
+		return nil
+	}
+
+	result := &dto.PVZCapacity{}
+	if capacity.Total > 0 {
+		result.Total = &capacity.Total
+	}
+	if len(capacity.ByType) > 0 {
+		result.ByType = &capacity.ByType
+	}
+	return result
+}
+
+func fromCapacityDTO(capacity *dto.PVZCapacity) *models.PVZCapacity {
+	if capacity == nil {
+		return nil
+	}
+
+	result := &models.PVZCapacity{}
+	if capacity.Total != nil {
+		result.Total = *capacity.Total
+	}
+	if capacity.ByType != nil {
+		result.ByType = *capacity.ByType
+	}
+	return result
+}
+
+func toCapacityUsageDTO(usage []models.CapacityUsage) []dto.CapacityUsage {
+	result := make([]dto.CapacityUsage, 0, len(usage))
+	for _, item := range usage {
+		result = append(result, dto.CapacityUsage{
+			Scope:       item.Scope,
+			Occupied:    item.Occupied,
+			Capacity:    item.Capacity,
+			Utilization: item.Utilization(),
+		})
 	}
+	return result
 }
@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_updatePVZ(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZService := mocks.NewMockPVZServiceInterface(ctrl)
+	handler := NewHandler(nil, mockPVZService, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	pvzID := uuid.New()
+	ifMatch := models.EntityTag{ID: pvzID, Version: 1}.String()
+
+	tests := []struct {
+		name           string
+		pvzIDParam     string
+		ifMatch        string
+		body           string
+		setupMocks     func()
+		expectedStatus int
+		expectedBody   map[string]interface{}
+		expectedETag   string
+	}{
+		{
+			name:       "Profile updated",
+			pvzIDParam: pvzID.String(),
+			ifMatch:    ifMatch,
+			body: `{"address":"ул. Тверская, 1","latitude":55.75,"longitude":37.61,"status":"temporarily_closed",` +
+				`"workingHours":{"weekly":[{"day":"monday","open":"09:00","close":"21:00"}],"exceptions":[{"date":"2025-01-01","closed":true}]}}`,
+			setupMocks: func() {
+				mockPVZService.EXPECT().UpdatePVZ(gomock.Any(), pvzID, gomock.Any(), models.ParsePrecondition(ifMatch)).
+					DoAndReturn(func(_ context.Context, _ uuid.UUID, update models.PVZUpdate, _ models.Precondition) (*models.PVZ, error) {
+						assert.Equal(t, "ул. Тверская, 1", *update.Address)
+						assert.Equal(t, 55.75, *update.Latitude)
+						assert.Equal(t, models.PVZStatusTemporarilyClosed, *update.Status)
+						assert.Nil(t, update.Phone)
+						assert.Equal(t, []models.DayHours{{Day: models.Monday, Open: "09:00", Close: "21:00"}}, update.WorkingHours.Weekly)
+						assert.Equal(t, []models.HoursException{{Date: "2025-01-01", Closed: true}}, update.WorkingHours.Exceptions)
+
+						pvz := &models.PVZ{ID: pvzID, City: models.CityMoscow, Status: models.PVZStatusActive, Version: 2}
+						assert.NoError(t, pvz.Apply(update))
+						return pvz, nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"address": "ул. Тверская, 1",
+				"status":  "temporarily_closed",
+			},
+			expectedETag: models.EntityTag{ID: pvzID, Version: 2}.String(),
+		},
+		{
+			name:           "Unknown status",
+			pvzIDParam:     pvzID.String(),
+			ifMatch:        ifMatch,
+			body:           `{"status":"archived"}`,
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Decommissioned PVZ",
+			pvzIDParam: pvzID.String(),
+			ifMatch:    ifMatch,
+			body:       `{"phone":"+74951234567"}`,
+			setupMocks: func() {
+				mockPVZService.EXPECT().UpdatePVZ(gomock.Any(), pvzID, gomock.Any(), gomock.Any()).
+					Return(nil, apperrors.ErrPVZDecommissioned)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody: map[string]interface{}{
+				"code": "pvz_decommissioned",
+			},
+		},
+		{
+			name:       "Stale version",
+			pvzIDParam: pvzID.String(),
+			ifMatch:    ifMatch,
+			body:       `{"phone":"+74951234567"}`,
+			setupMocks: func() {
+				mockPVZService.EXPECT().UpdatePVZ(gomock.Any(), pvzID, gomock.Any(), gomock.Any()).
+					Return(nil, apperrors.ErrVersionMismatch)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody: map[string]interface{}{
+				"code": "version_mismatch",
+			},
+		},
+		{
+			name:           "Missing If-Match",
+			pvzIDParam:     pvzID.String(),
+			body:           `{"phone":"+74951234567"}`,
+			setupMocks:     func() {},
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:           "Invalid PVZ ID",
+			pvzIDParam:     "invalid-uuid",
+			body:           `{}`,
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodPatch, "/pvz/"+tt.pvzIDParam, strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+			c.Params = gin.Params{{Key: "pvzId", Value: tt.pvzIDParam}}
+
+			handler.updatePVZ(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedETag, resp.Header().Get("ETag"))
+
+			var body map[string]interface{}
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+			for key, value := range tt.expectedBody {
+				assert.Equal(t, value, body[key])
+			}
+		})
+	}
+}
+
+func TestHandler_getPVZ(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZService := mocks.NewMockPVZServiceInterface(ctrl)
+	handler := NewHandler(nil, mockPVZService, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	pvzID := uuid.New()
+	etag := models.EntityTag{ID: pvzID, Version: 3}.String()
+
+	tests := []struct {
+		name           string
+		ifNoneMatch    string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Without If-None-Match",
+			expectedStatus: http.StatusOK,
+			expectedBody:   pvzID.String(),
+		},
+		{
+			name:           "Client has current version",
+			ifNoneMatch:    etag,
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "Client has stale version",
+			ifNoneMatch:    models.EntityTag{ID: pvzID, Version: 2}.String(),
+			expectedStatus: http.StatusOK,
+			expectedBody:   pvzID.String(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPVZService.EXPECT().GetPVZByID(gomock.Any(), pvzID).
+				Return(&models.PVZ{ID: pvzID, City: models.CityMoscow, Status: models.PVZStatusActive, Version: 3}, nil)
+
+			router := gin.New()
+			router.GET("/pvz/:pvzId", handler.getPVZ)
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz/"+pvzID.String(), nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, etag, resp.Header().Get("ETag"))
+			if tt.expectedBody == "" {
+				assert.Empty(t, resp.Body.String())
+			} else {
+				assert.Contains(t, resp.Body.String(), tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestHandler_getNearbyPVZ(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZService := mocks.NewMockPVZServiceInterface(ctrl)
+	handler := NewHandler(nil, mockPVZService, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMocks     func()
+		expectedStatus int
+		expectedItems  int
+	}{
+		{
+			name:  "Defaults applied",
+			query: "lat=55.75&lon=37.62",
+			setupMocks: func() {
+				mockPVZService.EXPECT().FindNearbyPVZ(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, filter models.NearbyFilter) ([]models.PVZWithDistance, error) {
+						assert.Equal(t, 55.75, filter.Latitude)
+						assert.Equal(t, 37.62, filter.Longitude)
+						assert.Equal(t, float64(models.DefaultNearbyRadiusMeters), filter.RadiusMeters)
+						assert.Equal(t, models.DefaultNearbyLimit, filter.Limit)
+						assert.Nil(t, filter.OpenAt)
+						return []models.PVZWithDistance{
+							{PVZ: &models.PVZ{ID: pvzID, City: models.CityMoscow, Status: models.PVZStatusActive}, DistanceMeters: 350},
+						}, nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedItems:  1,
+		},
+		{
+			name:  "Zero coordinates, radius, limit and open now",
+			query: "lat=0&lon=0&radius=1000&limit=3&openNow=true",
+			setupMocks: func() {
+				mockPVZService.EXPECT().FindNearbyPVZ(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, filter models.NearbyFilter) ([]models.PVZWithDistance, error) {
+						assert.Equal(t, 1000.0, filter.RadiusMeters)
+						assert.Equal(t, 3, filter.Limit)
+						assert.NotNil(t, filter.OpenAt)
+						return []models.PVZWithDistance{}, nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing longitude",
+			query:          "lat=55.75",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Limit too large",
+			query:          "lat=55.75&lon=37.62&limit=500",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Radius too large",
+			query: "lat=55.75&lon=37.62&radius=100000",
+			setupMocks: func() {
+				mockPVZService.EXPECT().FindNearbyPVZ(gomock.Any(), gomock.Any()).
+					Return(nil, apperrors.ErrInvalidNearbyRadius)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/pvz/nearby?"+tt.query, nil)
+
+			handler.getNearbyPVZ(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var body struct {
+					Items []map[string]interface{} `json:"items"`
+				}
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+				assert.NotNil(t, body.Items)
+				assert.Len(t, body.Items, tt.expectedItems)
+			}
+		})
+	}
+}
+
+func TestHandler_getPVZUtilization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZService := mocks.NewMockPVZServiceInterface(ctrl)
+	handler := NewHandler(nil, mockPVZService, nil, nil, nil, nil, nil, nil, nil, nil,
+		nil, nil, &config.Config{Capacity: config.CapacityConfig{UtilizationThreshold: 0.9}})
+
+	pvz := &models.PVZ{ID: uuid.New(), City: models.CityMoscow, Status: models.PVZStatusActive, Capacity: &models.PVZCapacity{Total: 10}}
+	overloaded := []models.PVZUtilization{{
+		PVZ:       pvz,
+		Occupancy: map[string]int{models.ProductTypeShoes: 9},
+		Usage:     []models.CapacityUsage{{Scope: models.CapacityScopeTotal, Occupied: 9, Capacity: 10}},
+	}}
+
+	t.Run("Default threshold", func(t *testing.T) {
+		mockPVZService.EXPECT().GetOverloadedPVZ(gomock.Any(), 0.9, nil).Return(overloaded, nil)
+
+		resp := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(resp)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/pvz/utilization", nil)
+
+		handler.getPVZUtilization(c)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var body struct {
+			Threshold float64 `json:"threshold"`
+			Items     []struct {
+				Occupied    int                      `json:"occupied"`
+				Utilization float64                  `json:"utilization"`
+				Usage       []map[string]interface{} `json:"usage"`
+				PVZ         map[string]interface{}   `json:"pvz"`
+			} `json:"items"`
+		}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		assert.Equal(t, 0.9, body.Threshold)
+		assert.Len(t, body.Items, 1)
+		assert.Equal(t, 9, body.Items[0].Occupied)
+		assert.Equal(t, 0.9, body.Items[0].Utilization)
+		assert.Equal(t, "total", body.Items[0].Usage[0]["scope"])
+		assert.Equal(t, map[string]interface{}{"total": float64(10)}, body.Items[0].PVZ["capacity"])
+	})
+
+	t.Run("Invalid threshold", func(t *testing.T) {
+		mockPVZService.EXPECT().GetOverloadedPVZ(gomock.Any(), -1.0, nil).Return(nil, apperrors.ErrInvalidThreshold)
+
+		resp := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(resp)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/pvz/utilization?threshold=-1", nil)
+
+		handler.getPVZUtilization(c)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
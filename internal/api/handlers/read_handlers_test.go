@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_readEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZService := mocks.NewMockPVZServiceInterface(ctrl)
+	mockReceptionService := mocks.NewMockReceptionServiceInterface(ctrl)
+	mockProductService := mocks.NewMockProductServiceInterface(ctrl)
+
+	handler := NewHandler(nil, mockPVZService, mockReceptionService, mockProductService, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	productID := uuid.New()
+	now := time.Now()
+
+	product := models.Product{ID: productID, DateTime: now, Type: models.ProductTypeShoes, ReceptionID: receptionID}
+	reception := &models.Reception{
+		ID:       receptionID,
+		DateTime: now,
+		PVZID:    pvzID,
+		Status:   models.ReceptionStatusInProgress,
+		Products: []models.Product{product},
+	}
+	otherPVZKey := &models.APIKey{Scopes: []string{models.ScopePVZRead}, PVZIDs: []uuid.UUID{uuid.New()}}
+
+	tests := []struct {
+		name           string
+		handler        gin.HandlerFunc
+		params         gin.Params
+		query          string
+		apiKey         *models.APIKey
+		setupMocks     func()
+		expectedStatus int
+		expectedKeys   []string
+	}{
+		{
+			name:    "Get PVZ",
+			handler: handler.getPVZ,
+			params:  gin.Params{{Key: "pvzId", Value: pvzID.String()}},
+			setupMocks: func() {
+				mockPVZService.EXPECT().GetPVZByID(gomock.Any(), pvzID).
+					Return(&models.PVZ{ID: pvzID, RegistrationDate: now, City: models.CityKazan}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedKeys:   []string{"id", "registrationDate", "city"},
+		},
+		{
+			name:    "PVZ not found",
+			handler: handler.getPVZ,
+			params:  gin.Params{{Key: "pvzId", Value: pvzID.String()}},
+			setupMocks: func() {
+				mockPVZService.EXPECT().GetPVZByID(gomock.Any(), pvzID).Return(nil, repoerrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid PVZ ID",
+			handler:        handler.getPVZ,
+			params:         gin.Params{{Key: "pvzId", Value: "not-a-uuid"}},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "API key restricted to another PVZ",
+			handler:        handler.getPVZ,
+			params:         gin.Params{{Key: "pvzId", Value: pvzID.String()}},
+			apiKey:         otherPVZKey,
+			setupMocks:     func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:    "Get reception with products",
+			handler: handler.getReception,
+			params:  gin.Params{{Key: "receptionId", Value: receptionID.String()}},
+			setupMocks: func() {
+				mockReceptionService.EXPECT().GetReceptionByID(gomock.Any(), receptionID).Return(reception, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedKeys:   []string{"reception", "products"},
+		},
+		{
+			name:    "Reception of a PVZ not allowed for the API key",
+			handler: handler.getReception,
+			params:  gin.Params{{Key: "receptionId", Value: receptionID.String()}},
+			apiKey:  otherPVZKey,
+			setupMocks: func() {
+				mockReceptionService.EXPECT().GetReceptionByID(gomock.Any(), receptionID).Return(reception, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:    "Get product",
+			handler: handler.getProduct,
+			params:  gin.Params{{Key: "productId", Value: productID.String()}},
+			setupMocks: func() {
+				mockProductService.EXPECT().GetProductByID(gomock.Any(), productID).Return(&product, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedKeys:   []string{"id", "dateTime", "type", "receptionId"},
+		},
+		{
+			name:    "Product of a PVZ not allowed for the API key",
+			handler: handler.getProduct,
+			params:  gin.Params{{Key: "productId", Value: productID.String()}},
+			apiKey:  otherPVZKey,
+			setupMocks: func() {
+				mockProductService.EXPECT().GetProductByID(gomock.Any(), productID).Return(&product, nil)
+				mockReceptionService.EXPECT().GetReceptionByID(gomock.Any(), receptionID).Return(reception, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:    "Product not found",
+			handler: handler.getProduct,
+			params:  gin.Params{{Key: "productId", Value: productID.String()}},
+			setupMocks: func() {
+				mockProductService.EXPECT().GetProductByID(gomock.Any(), productID).Return(nil, repoerrors.ErrProductNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:    "PVZ receptions page",
+			handler: handler.getPVZReceptions,
+			params:  gin.Params{{Key: "pvzId", Value: pvzID.String()}},
+			query:   "?status=close&page=2&limit=5",
+			setupMocks: func() {
+				mockReceptionService.EXPECT().
+					ListPVZReceptions(gomock.Any(), pvzID, models.ReceptionFilter{Status: models.ReceptionStatusClosed, Page: 2, Limit: 5}).
+					Return([]*models.Reception{{
+						ID:            receptionID,
+						DateTime:      now,
+						PVZID:         pvzID,
+						Status:        models.ReceptionStatusClosed,
+						ProductCounts: map[string]int{models.ProductTypeShoes: 3},
+					}}, 6, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedKeys:   []string{"items", "totalCount", "page", "limit"},
+		},
+		{
+			name:           "PVZ receptions with limit too large",
+			handler:        handler.getPVZReceptions,
+			params:         gin.Params{{Key: "pvzId", Value: pvzID.String()}},
+			query:          "?limit=500",
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "Current reception",
+			handler: handler.getCurrentReception,
+			params:  gin.Params{{Key: "pvzId", Value: pvzID.String()}},
+			setupMocks: func() {
+				mockReceptionService.EXPECT().GetLastActiveReception(gomock.Any(), pvzID).Return(reception, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedKeys:   []string{"reception", "products"},
+		},
+		{
+			name:    "No current reception",
+			handler: handler.getCurrentReception,
+			params:  gin.Params{{Key: "pvzId", Value: pvzID.String()}},
+			setupMocks: func() {
+				mockReceptionService.EXPECT().GetLastActiveReception(gomock.Any(), pvzID).Return(nil, apperrors.ErrNoActiveReception)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			c.Params = tt.params
+			if tt.apiKey != nil {
+				c.Set(string(apiKeyKey), tt.apiKey)
+			}
+
+			tt.handler(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+
+			var body map[string]interface{}
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+			for _, key := range tt.expectedKeys {
+				assert.Contains(t, body, key)
+			}
+		})
+	}
+}
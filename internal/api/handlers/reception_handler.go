@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/api/errcode"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"net/http"
 )
 
@@ -12,24 +16,27 @@ func (h *Handler) createReception(c *gin.Context) {
 	var req dto.PostReceptionsJSONRequestBody
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Debug().Err(err).Msg("Invalid request format in createReception")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request format"})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in createReception")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
 		return
 	}
 
 	pvzID, err := uuid.Parse(req.PvzId.String())
 	if err != nil {
-		log.Debug().Err(err).Str("pvz_id", req.PvzId.String()).Msg("Invalid PVZ ID format")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid PVZ ID format"})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", req.PvzId.String()).Msg("Invalid PVZ ID format")
+		respondError(c, apperrors.ErrInvalidPVZID)
+		return
+	}
+
+	if !h.authorizePVZ(c, pvzID) {
 		return
 	}
 
 	reception, err := h.receptionService.CreateReception(c.Request.Context(), pvzID)
 	if err != nil {
-		log.Error().Err(err).Str("pvz_id", pvzID.String()).Msg("Reception creation failed")
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("pvz_id", pvzID.String()).Msg("Reception creation failed")
 
-		statusCode, message := getErrorResponse(err)
-		c.JSON(statusCode, gin.H{"message": message})
+		respondError(c, err)
 		return
 	}
 
@@ -40,11 +47,12 @@ func (h *Handler) createReception(c *gin.Context) {
 		Status:   dto.ReceptionStatus(reception.Status),
 	}
 
-	log.Info().
+	zerolog.Ctx(c.Request.Context()).Info().
 		Str("reception_id", reception.ID.String()).
 		Str("pvz_id", reception.PVZID.String()).
 		Msg("Reception created successfully")
 
+	setETag(c, reception.ID, reception.Version)
 	c.JSON(http.StatusCreated, response)
 }
 
@@ -52,17 +60,25 @@ func (h *Handler) closeReception(c *gin.Context) {
 	pvzIdParam := c.Param("pvzId")
 	pvzID, err := uuid.Parse(pvzIdParam)
 	if err != nil {
-		log.Debug().Err(err).Str("pvz_id", pvzIdParam).Msg("Invalid PVZ ID format")
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid PVZ ID format"})
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", pvzIdParam).Msg("Invalid PVZ ID format")
+		respondError(c, apperrors.ErrInvalidPVZID)
+		return
+	}
+
+	if !h.authorizePVZ(c, pvzID) {
 		return
 	}
 
-	reception, err := h.receptionService.CloseReception(c.Request.Context(), pvzID)
+	precondition, ok := requirePrecondition(c)
+	if !ok {
+		return
+	}
+
+	reception, err := h.receptionService.CloseReception(c.Request.Context(), pvzID, precondition)
 	if err != nil {
-		log.Error().Err(err).Str("pvz_id", pvzID.String()).Msg("Reception closing failed")
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("pvz_id", pvzID.String()).Msg("Reception closing failed")
 
-		statusCode, message := getErrorResponse(err)
-		c.JSON(statusCode, gin.H{"message": message})
+		respondError(c, err)
 		return
 	}
 
@@ -73,10 +89,160 @@ func (h *Handler) closeReception(c *gin.Context) {
 		Status:   dto.ReceptionStatus(reception.Status),
 	}
 
-	log.Info().
+	zerolog.Ctx(c.Request.Context()).Info().
 		Str("reception_id", reception.ID.String()).
 		Str("pvz_id", reception.PVZID.String()).
 		Msg("Reception closed successfully")
 
+	setETag(c, reception.ID, reception.Version)
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *Handler) getReception(c *gin.Context) {
+	receptionIdParam := c.Param("receptionId")
+	receptionID, err := uuid.Parse(receptionIdParam)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("reception_id", receptionIdParam).Msg("Invalid reception ID format")
+		respondError(c, apperrors.ErrInvalidReceptionID)
+		return
+	}
+
+	reception, err := h.receptionService.GetReceptionByID(c.Request.Context(), receptionID)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("reception_id", receptionID.String()).Msg("Failed to get reception")
+
+		respondError(c, err)
+		return
+	}
+
+	if !h.authorizePVZ(c, reception.PVZID) {
+		return
+	}
+
+	if notModified(c, reception.ID, reception.Version) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toReceptionWithProductsDTO(reception))
+}
+
+func (h *Handler) getPVZReceptions(c *gin.Context) {
+	pvzIdParam := c.Param("pvzId")
+	pvzID, err := uuid.Parse(pvzIdParam)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", pvzIdParam).Msg("Invalid PVZ ID format")
+		respondError(c, apperrors.ErrInvalidPVZID)
+		return
+	}
+
+	var params dto.GetPvzPvzIdReceptionsParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid query parameters in getPVZReceptions")
+		respondBindError(c, apperrors.ErrInvalidQueryParameters, err)
+		return
+	}
+
+	if !h.authorizePVZ(c, pvzID) {
+		return
+	}
+
+	filter := models.ReceptionFilter{
+		Page:  1,
+		Limit: 20,
+	}
+	if params.Status != nil {
+		filter.Status = string(*params.Status)
+	}
+	if params.Page != nil {
+		filter.Page = *params.Page
+	}
+	if params.Limit != nil {
+		filter.Limit = *params.Limit
+	}
+
+	receptions, total, err := h.receptionService.ListPVZReceptions(c.Request.Context(), pvzID, filter)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("pvz_id", pvzID.String()).Msg("Failed to get PVZ receptions")
+
+		respondError(c, err)
+		return
+	}
+
+	items := make([]dto.ReceptionSummary, 0, len(receptions))
+	for _, reception := range receptions {
+		productCounts := reception.ProductCounts
+		if productCounts == nil {
+			productCounts = map[string]int{}
+		}
+
+		items = append(items, dto.ReceptionSummary{
+			Reception:     toReceptionDTO(reception),
+			ProductCounts: productCounts,
+			ProductsTotal: reception.ProductsTotal(),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.ReceptionPage{
+		Items:      items,
+		TotalCount: total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+	})
+}
+
+func (h *Handler) getCurrentReception(c *gin.Context) {
+	pvzIdParam := c.Param("pvzId")
+	pvzID, err := uuid.Parse(pvzIdParam)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", pvzIdParam).Msg("Invalid PVZ ID format")
+		respondError(c, apperrors.ErrInvalidPVZID)
+		return
+	}
+
+	if !h.authorizePVZ(c, pvzID) {
+		return
+	}
+
+	reception, err := h.receptionService.GetLastActiveReception(c.Request.Context(), pvzID)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", pvzID.String()).Msg("Failed to get current reception")
+
+		// Для чтения отсутствие открытой приёмки - это отсутствие ресурса, а не ошибка запроса.
+		if errors.Is(err, apperrors.ErrNoActiveReception) {
+			descriptor, _ := errcode.Describe(err)
+			descriptor.Status = http.StatusNotFound
+			writeProblem(c, descriptor, nil)
+			return
+		}
+
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, reception.ID, reception.Version) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toReceptionWithProductsDTO(reception))
+}
+
+func toReceptionDTO(reception *models.Reception) dto.Reception {
+	return dto.Reception{
+		Id:       &reception.ID,
+		DateTime: reception.DateTime,
+		PvzId:    reception.PVZID,
+		Status:   dto.ReceptionStatus(reception.Status),
+	}
+}
+
+func toReceptionWithProductsDTO(reception *models.Reception) dto.ReceptionWithProducts {
+	products := make([]dto.Product, 0, len(reception.Products))
+	for i := range reception.Products {
+		products = append(products, toProductDTO(&reception.Products[i]))
+	}
+
+	return dto.ReceptionWithProducts{
+		Reception: toReceptionDTO(reception),
+		Products:  products,
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+func (h *Handler) getReceptionReport(c *gin.Context) {
+	var params dto.GetReportsReceptionsParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid query parameters in getReceptionReport")
+		respondBindError(c, apperrors.ErrInvalidQueryParameters, err)
+		return
+	}
+
+	filter := models.ReceptionReportFilter{
+		From: params.From,
+		To:   params.To,
+	}
+
+	if params.GroupBy != nil {
+		for _, group := range *params.GroupBy {
+			filter.GroupBy = append(filter.GroupBy, string(group))
+		}
+	}
+	if params.Period != nil {
+		filter.Period = string(*params.Period)
+	}
+	if params.City != nil {
+		for _, city := range *params.City {
+			filter.Cities = append(filter.Cities, string(city))
+		}
+	}
+	if params.PvzId != nil {
+		pvzID, err := uuid.Parse(*params.PvzId)
+		if err != nil {
+			zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("pvz_id", *params.PvzId).Msg("Invalid PVZ ID format")
+			respondError(c, apperrors.ErrInvalidPVZID)
+			return
+		}
+		filter.PVZID = &pvzID
+	}
+	if params.ProductType != nil {
+		filter.ProductType = string(*params.ProductType)
+	}
+
+	rows, err := h.receptionService.ReceptionReport(c.Request.Context(), filter)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Msg("Failed to build reception report")
+
+		respondError(c, err)
+		return
+	}
+
+	items := make([]dto.ReceptionReportRow, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toReceptionReportRowDTO(row))
+	}
+
+	c.JSON(http.StatusOK, dto.ReceptionReport{Items: items})
+}
+
+func toReceptionReportRowDTO(row models.ReceptionReportRow) dto.ReceptionReportRow {
+	result := dto.ReceptionReportRow{
+		PeriodStart:          row.PeriodStart,
+		PvzId:                row.PVZID,
+		Receptions:           row.Receptions,
+		Products:             row.Products,
+		ProductsPerReception: row.ProductsPerReception(),
+	}
+
+	if row.City != "" {
+		result.City = &row.City
+	}
+	if row.ProductType != "" {
+		result.ProductType = &row.ProductType
+	}
+	if row.AvgDuration != nil {
+		seconds := row.AvgDuration.Seconds()
+		result.AvgDurationSeconds = &seconds
+	}
+
+	return result
+}
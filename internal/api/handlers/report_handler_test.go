@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestHandler_getReceptionReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReceptionService := mocks.NewMockReceptionServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, mockReceptionService, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	pvzID := uuid.New()
+	weekStart := time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC)
+	avgDuration := 45 * time.Minute
+
+	tests := []struct {
+		name           string
+		query          url.Values
+		setupMocks     func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Grouped by week, PVZ and product type",
+			query: url.Values{
+				"groupBy":     {"pvz", "productType"},
+				"period":      {"week"},
+				"city":        {"Москва", "Казань"},
+				"pvzId":       {pvzID.String()},
+				"productType": {"обувь"},
+			},
+			setupMocks: func() {
+				mockReceptionService.EXPECT().ReceptionReport(gomock.Any(), models.ReceptionReportFilter{
+					GroupBy:     []string{models.ReportGroupPVZ, models.ReportGroupProductType},
+					Period:      models.ReportPeriodWeek,
+					Cities:      []string{"Москва", "Казань"},
+					PVZID:       &pvzID,
+					ProductType: models.ProductTypeShoes,
+				}).Return([]models.ReceptionReportRow{{
+					PeriodStart: &weekStart,
+					PVZID:       &pvzID,
+					ProductType: models.ProductTypeShoes,
+					Receptions:  2,
+					Products:    5,
+					AvgDuration: &avgDuration,
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"items":[{"periodStart":"2025-04-07T00:00:00Z","pvzId":"` + pvzID.String() +
+				`","productType":"обувь","receptions":2,"products":5,"productsPerReception":2.5,"avgDurationSeconds":2700}]}`,
+		},
+		{
+			name:  "Totals without grouping",
+			query: url.Values{},
+			setupMocks: func() {
+				mockReceptionService.EXPECT().ReceptionReport(gomock.Any(), models.ReceptionReportFilter{}).
+					Return([]models.ReceptionReportRow{{Receptions: 0, Products: 0}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"items":[{"receptions":0,"products":0,"productsPerReception":0}]}`,
+		},
+		{
+			name:           "Unknown grouping",
+			query:          url.Values{"groupBy": {"status"}},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown period",
+			query:          url.Values{"period": {"year"}},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid PVZ ID",
+			query:          url.Values{"pvzId": {"not-a-uuid"}},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Start date after end date",
+			query: url.Values{"from": {"2025-04-10T00:00:00Z"}, "to": {"2025-04-01T00:00:00Z"}},
+			setupMocks: func() {
+				mockReceptionService.EXPECT().ReceptionReport(gomock.Any(), gomock.Any()).
+					Return(nil, apperrors.ErrInvalidDateRange)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Start date must not be later than end date.","code":"invalid_date_range"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/reports/receptions?"+tt.query.Encode(), nil)
+
+			handler.getReceptionReport(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			}
+		})
+	}
+}
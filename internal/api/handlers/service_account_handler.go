@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/dto"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+func (h *Handler) createServiceAccount(c *gin.Context) {
+	var req dto.PostAdminServiceAccountsJSONRequestBody
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in createServiceAccount")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+		return
+	}
+
+	userID, ok := c.Get(string(userIDKey))
+	if !ok {
+		respondError(c, apperrors.ErrUnauthenticated)
+		return
+	}
+
+	var description string
+	if req.Description != nil {
+		description = *req.Description
+	}
+
+	account, err := h.serviceAccountService.CreateServiceAccount(c.Request.Context(), req.Name, description, userID.(uuid.UUID))
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("name", req.Name).Msg("Service account creation failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toServiceAccountDTO(account))
+}
+
+func (h *Handler) listServiceAccounts(c *gin.Context) {
+	accounts, err := h.serviceAccountService.ListServiceAccounts(c.Request.Context())
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Msg("Failed to list service accounts")
+
+		respondError(c, err)
+		return
+	}
+
+	response := make([]dto.ServiceAccount, 0, len(accounts))
+	for _, account := range accounts {
+		response = append(response, toServiceAccountDTO(account))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) issueAPIKey(c *gin.Context) {
+	accountIDParam := c.Param("serviceAccountId")
+	accountID, err := uuid.Parse(accountIDParam)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("service_account_id", accountIDParam).Msg("Invalid service account ID format")
+		respondError(c, apperrors.ErrInvalidServiceAccountID)
+		return
+	}
+
+	var req dto.PostAdminServiceAccountsServiceAccountIdKeysJSONRequestBody
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Msg("Invalid request format in issueAPIKey")
+		respondBindError(c, apperrors.ErrInvalidRequestBody, err)
+		return
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	var pvzIDs []uuid.UUID
+	if req.PvzIds != nil {
+		pvzIDs = *req.PvzIds
+	}
+
+	key, rawKey, err := h.serviceAccountService.IssueAPIKey(c.Request.Context(), accountID, scopes, pvzIDs, req.ExpiresAt)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("service_account_id", accountID.String()).Msg("API key issue failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.IssuedAPIKey{
+		Key:    rawKey,
+		ApiKey: toAPIKeyDTO(key),
+	})
+}
+
+func (h *Handler) listAPIKeys(c *gin.Context) {
+	accountIDParam := c.Param("serviceAccountId")
+	accountID, err := uuid.Parse(accountIDParam)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("service_account_id", accountIDParam).Msg("Invalid service account ID format")
+		respondError(c, apperrors.ErrInvalidServiceAccountID)
+		return
+	}
+
+	keys, err := h.serviceAccountService.ListAPIKeys(c.Request.Context(), accountID)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("service_account_id", accountID.String()).Msg("Failed to list API keys")
+
+		respondError(c, err)
+		return
+	}
+
+	response := make([]dto.APIKey, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, toAPIKeyDTO(key))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) revokeAPIKey(c *gin.Context) {
+	keyIDParam := c.Param("keyId")
+	keyID, err := uuid.Parse(keyIDParam)
+	if err != nil {
+		zerolog.Ctx(c.Request.Context()).Debug().Err(err).Str("api_key_id", keyIDParam).Msg("Invalid API key ID format")
+		respondError(c, apperrors.ErrInvalidAPIKeyID)
+		return
+	}
+
+	if err := h.serviceAccountService.RevokeAPIKey(c.Request.Context(), keyID); err != nil {
+		zerolog.Ctx(c.Request.Context()).Error().Err(err).Str("api_key_id", keyID.String()).Msg("API key revocation failed")
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
+
+func toServiceAccountDTO(account *models.ServiceAccount) dto.ServiceAccount {
+	return dto.ServiceAccount{
+		Id:          &account.ID,
+		Name:        account.Name,
+		Description: &account.Description,
+		CreatedAt:   &account.CreatedAt,
+	}
+}
+
+func toAPIKeyDTO(key *models.APIKey) dto.APIKey {
+	scopes := make([]dto.APIKeyScopes, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, dto.APIKeyScopes(scope))
+	}
+
+	return dto.APIKey{
+		Id:               key.ID,
+		ServiceAccountId: key.ServiceAccountID,
+		Prefix:           key.Prefix,
+		Scopes:           scopes,
+		PvzIds:           key.PVZIDs,
+		ExpiresAt:        key.ExpiresAt,
+		LastUsedAt:       key.LastUsedAt,
+		RevokedAt:        key.RevokedAt,
+		CreatedAt:        key.CreatedAt,
+	}
+}
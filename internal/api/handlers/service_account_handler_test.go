@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/handlers/mocks"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockServiceAccountService := mocks.NewMockServiceAccountServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, mockServiceAccountService, nil, nil, nil, nil, nil, &config.Config{})
+
+	accountID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupMocks     func()
+		expectedStatus int
+	}{
+		{
+			name: "Valid API key",
+			setupMocks: func() {
+				mockServiceAccountService.EXPECT().Authenticate(gomock.Any(), "pvz_key").
+					Return(&models.APIKey{ServiceAccountID: accountID, Scopes: []string{models.ScopePVZRead}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Revoked API key",
+			setupMocks: func() {
+				mockServiceAccountService.EXPECT().Authenticate(gomock.Any(), "pvz_key").
+					Return(nil, apperrors.ErrInvalidAPIKey)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			req, _ := http.NewRequest(http.MethodGet, "/pvz", nil)
+			req.Header.Set("X-API-Key", "pvz_key")
+			c.Request = req
+
+			handler.authMiddleware()(c)
+
+			if tt.expectedStatus == http.StatusOK {
+				assert.False(t, c.IsAborted())
+				assert.Equal(t, accountID, c.MustGet(string(userIDKey)))
+				assert.Equal(t, models.RoleServiceAccount, c.GetString(string(userRoleKey)))
+			} else {
+				assert.True(t, c.IsAborted())
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestRoleMiddleware_APIKeyScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	tests := []struct {
+		name           string
+		middleware     gin.HandlerFunc
+		scopes         []string
+		expectedStatus int
+	}{
+		{
+			name:           "Key with required scope",
+			middleware:     handler.roleMiddleware("employee", models.ScopeReceptionsWrite),
+			scopes:         []string{models.ScopeReceptionsWrite},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Key without required scope",
+			middleware:     handler.roleMiddleware("employee", models.ScopeProductsWrite),
+			scopes:         []string{models.ScopeReceptionsWrite},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Route without scopes is closed for keys",
+			middleware:     handler.roleMiddleware("moderator"),
+			scopes:         []string{models.ScopePVZRead, models.ScopeReceptionsWrite, models.ScopeProductsWrite},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Read-only key on PVZ list",
+			middleware:     handler.scopeMiddleware(models.ScopePVZRead),
+			scopes:         []string{models.ScopePVZRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Write key on PVZ list",
+			middleware:     handler.scopeMiddleware(models.ScopePVZRead),
+			scopes:         []string{models.ScopeProductsWrite},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/test", nil)
+			c.Set(string(userRoleKey), models.RoleServiceAccount)
+			c.Set(string(apiKeyKey), &models.APIKey{Scopes: tt.scopes})
+
+			tt.middleware(c)
+
+			if tt.expectedStatus == http.StatusOK {
+				assert.False(t, c.IsAborted())
+			} else {
+				assert.True(t, c.IsAborted())
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_createReception_APIKeyPVZRestriction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReceptionService := mocks.NewMockReceptionServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, mockReceptionService, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	allowedPVZ := uuid.New()
+	otherPVZ := uuid.New()
+	key := &models.APIKey{Scopes: []string{models.ScopeReceptionsWrite}, PVZIDs: []uuid.UUID{allowedPVZ}}
+
+	tests := []struct {
+		name           string
+		pvzID          uuid.UUID
+		setupMocks     func()
+		expectedStatus int
+	}{
+		{
+			name:  "Allowed PVZ",
+			pvzID: allowedPVZ,
+			setupMocks: func() {
+				mockReceptionService.EXPECT().CreateReception(gomock.Any(), allowedPVZ).
+					Return(&models.Reception{ID: uuid.New(), DateTime: time.Now(), PVZID: allowedPVZ, Status: models.ReceptionStatusInProgress}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Other PVZ",
+			pvzID:          otherPVZ,
+			setupMocks:     func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			body, _ := json.Marshal(map[string]interface{}{"pvzId": tt.pvzID.String()})
+			req, _ := http.NewRequest(http.MethodPost, "/receptions", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = req
+			c.Set(string(apiKeyKey), key)
+
+			handler.createReception(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+		})
+	}
+}
+
+func TestHandler_issueAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockServiceAccountService := mocks.NewMockServiceAccountServiceInterface(ctrl)
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, mockServiceAccountService, nil, nil, nil, nil, nil, &config.Config{})
+
+	accountID := uuid.New()
+
+	tests := []struct {
+		name           string
+		accountID      string
+		requestBody    map[string]interface{}
+		setupMocks     func()
+		expectedStatus int
+	}{
+		{
+			name:        "Key issued",
+			accountID:   accountID.String(),
+			requestBody: map[string]interface{}{"scopes": []string{"pvz:read"}},
+			setupMocks: func() {
+				mockServiceAccountService.EXPECT().
+					IssueAPIKey(gomock.Any(), accountID, []string{models.ScopePVZRead}, nil, nil).
+					Return(&models.APIKey{ID: uuid.New(), ServiceAccountID: accountID, Scopes: []string{models.ScopePVZRead}}, "pvz_key", nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:        "Service account not found",
+			accountID:   accountID.String(),
+			requestBody: map[string]interface{}{"scopes": []string{"pvz:read"}},
+			setupMocks: func() {
+				mockServiceAccountService.EXPECT().
+					IssueAPIKey(gomock.Any(), accountID, []string{models.ScopePVZRead}, nil, nil).
+					Return(nil, "", repoerrors.ErrServiceAccountNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "No scopes",
+			accountID:      accountID.String(),
+			requestBody:    map[string]interface{}{"scopes": []string{}},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid account ID",
+			accountID:      "not-a-uuid",
+			requestBody:    map[string]interface{}{"scopes": []string{"pvz:read"}},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest(http.MethodPost, "/admin/service-accounts/"+tt.accountID+"/keys", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request = req
+			c.Params = gin.Params{{Key: "serviceAccountId", Value: tt.accountID}}
+
+			handler.issueAPIKey(c)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				var response map[string]interface{}
+				json.Unmarshal(resp.Body.Bytes(), &response)
+				assert.Equal(t, "pvz_key", response["key"])
+			}
+		})
+	}
+}
+
+func TestHandler_PVZListEndpoints_APIKeyPVZRestriction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZService := mocks.NewMockPVZServiceInterface(ctrl)
+	handler := NewHandler(nil, mockPVZService, nil, nil, nil, nil, nil, nil, nil, nil,
+		nil, nil, &config.Config{Capacity: config.CapacityConfig{UtilizationThreshold: 0.9}})
+
+	allowedPVZ := uuid.New()
+	key := &models.APIKey{Scopes: []string{models.ScopePVZRead}, PVZIDs: []uuid.UUID{allowedPVZ}}
+
+	tests := []struct {
+		name       string
+		url        string
+		handle     func(c *gin.Context)
+		setupMocks func()
+	}{
+		{
+			name:   "List",
+			url:    "/pvz",
+			handle: handler.getPVZList,
+			setupMocks: func() {
+				mockPVZService.EXPECT().GetAllPVZWithReceptions(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ interface{}, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error) {
+						assert.Equal(t, []uuid.UUID{allowedPVZ}, filter.PVZIDs)
+						return []models.PVZWithReceptions{}, &models.PVZPage{}, nil
+					})
+			},
+		},
+		{
+			name:   "Nearby",
+			url:    "/pvz/nearby?lat=55.75&lon=37.62",
+			handle: handler.getNearbyPVZ,
+			setupMocks: func() {
+				mockPVZService.EXPECT().FindNearbyPVZ(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ interface{}, filter models.NearbyFilter) ([]models.PVZWithDistance, error) {
+						assert.Equal(t, []uuid.UUID{allowedPVZ}, filter.PVZIDs)
+						return []models.PVZWithDistance{}, nil
+					})
+			},
+		},
+		{
+			name:   "Utilization",
+			url:    "/pvz/utilization",
+			handle: handler.getPVZUtilization,
+			setupMocks: func() {
+				mockPVZService.EXPECT().GetOverloadedPVZ(gomock.Any(), 0.9, []uuid.UUID{allowedPVZ}).
+					Return([]models.PVZUtilization{}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			resp := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(resp)
+			c.Request, _ = http.NewRequest(http.MethodGet, tt.url, nil)
+			c.Set(string(apiKeyKey), key)
+
+			tt.handle(c)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+		})
+	}
+}
@@ -13,14 +13,84 @@ var (
 	ErrExpiredToken = errors.New("token expired")
 )
 
+// PurposeMFAChallenge помечает короткоживущий токен второго шага входа.
+// Такой токен не дает доступа к API и принимается только при вводе кода 2FA.
+const PurposeMFAChallenge = "mfa_challenge"
+
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Role   string    `json:"role"`
+	UserID  uuid.UUID `json:"user_id"`
+	Role    string    `json:"role"`
+	MFA     bool      `json:"mfa,omitempty"`
+	Purpose string    `json:"purpose,omitempty"`
+	Dummy   bool      `json:"dummy,omitempty"`
+	// Locale - язык сообщений API, выбранный пользователем. Пусто, если не выбран.
+	Locale string `json:"locale,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uuid.UUID, role, secret string, expiration time.Duration) (string, error) {
-	claims := Claims{
+func GenerateToken(userID uuid.UUID, role, locale, secret string, expiration time.Duration) (string, error) {
+	claims := newClaims(userID, role, expiration)
+	claims.Locale = locale
+	return signClaims(claims, secret)
+}
+
+// GenerateMFAToken выдает токен доступа пользователю, прошедшему второй фактор.
+func GenerateMFAToken(userID uuid.UUID, role, locale, secret string, expiration time.Duration) (string, error) {
+	claims := newClaims(userID, role, expiration)
+	claims.MFA = true
+	claims.Locale = locale
+	return signClaims(claims, secret)
+}
+
+// GenerateMFAChallengeToken выдает токен второго шага входа. Идентификатор токена (jti)
+// нужен, чтобы считать попытки ввода кода и не принимать токен после входа.
+func GenerateMFAChallengeToken(userID uuid.UUID, role, secret string, expiration time.Duration) (string, error) {
+	claims := newClaims(userID, role, expiration)
+	claims.Purpose = PurposeMFAChallenge
+	claims.ID = uuid.NewString()
+	return signClaims(claims, secret)
+}
+
+func ValidateToken(tokenString, secret string) (*Claims, error) {
+	claims, err := parseToken(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func ValidateMFAChallengeToken(tokenString, secret string) (*Claims, error) {
+	claims, err := parseToken(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != PurposeMFAChallenge {
+		return nil, ErrInvalidToken
+	}
+
+	if _, err := uuid.Parse(claims.ID); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// GenerateDummyToken выдает тестовый токен. Он помечен claim dummy, чтобы такие
+// токены можно было отклонять независимо от обычных.
+func GenerateDummyToken(role, secret string, expiration time.Duration) (string, error) {
+	claims := newClaims(uuid.New(), role, expiration)
+	claims.Dummy = true
+	return signClaims(claims, secret)
+}
+
+func newClaims(userID uuid.UUID, role string, expiration time.Duration) Claims {
+	return Claims{
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -28,12 +98,14 @@ func GenerateToken(userID uuid.UUID, role, secret string, expiration time.Durati
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+}
 
+func signClaims(claims Claims, secret string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
 }
 
-func ValidateToken(tokenString, secret string) (*Claims, error) {
+func parseToken(tokenString, secret string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidToken
@@ -55,17 +127,3 @@ func ValidateToken(tokenString, secret string) (*Claims, error) {
 
 	return claims, nil
 }
-
-func GenerateDummyToken(role, secret string, expiration time.Duration) (string, error) {
-	claims := Claims{
-		UserID: uuid.New(),
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
-}
@@ -66,6 +66,9 @@ func TestGenerateDummyToken(t *testing.T) {
 				if claims.Role != tt.args.role {
 					t.Errorf("GenerateDummyToken() role in claims = %v, want %v", claims.Role, tt.args.role)
 				}
+				if !claims.Dummy {
+					t.Errorf("GenerateDummyToken() token is not marked as dummy")
+				}
 			}
 		})
 	}
@@ -118,7 +121,7 @@ func TestGenerateToken(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := GenerateToken(tt.args.userID, tt.args.role, tt.args.secret, tt.args.expiration)
+			got, err := GenerateToken(tt.args.userID, tt.args.role, "", tt.args.secret, tt.args.expiration)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GenerateToken() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -136,6 +139,9 @@ func TestGenerateToken(t *testing.T) {
 				if claims.Role != tt.args.role {
 					t.Errorf("GenerateToken() role in claims = %v, want %v", claims.Role, tt.args.role)
 				}
+				if claims.Dummy {
+					t.Errorf("GenerateToken() token must not be marked as dummy")
+				}
 				if claims.UserID != tt.args.userID {
 					t.Errorf("GenerateToken() userID in claims = %v, want %v", claims.UserID, tt.args.userID)
 				}
@@ -148,11 +154,13 @@ func TestValidateToken(t *testing.T) {
 	userID := uuid.New()
 	secret := "test-secret"
 
-	validToken, _ := GenerateToken(userID, "moderator", secret, time.Hour)
+	validToken, _ := GenerateToken(userID, "moderator", "", secret, time.Hour)
+
+	expiredToken, _ := GenerateToken(userID, "employee", "", secret, -time.Hour)
 
-	expiredToken, _ := GenerateToken(userID, "employee", secret, -time.Hour)
+	tokenWithDifferentSecret, _ := GenerateToken(userID, "moderator", "", "different-secret", time.Hour)
 
-	tokenWithDifferentSecret, _ := GenerateToken(userID, "moderator", "different-secret", time.Hour)
+	challengeToken, _ := GenerateMFAChallengeToken(userID, "moderator", secret, time.Hour)
 
 	type args struct {
 		tokenString string
@@ -225,6 +233,15 @@ func TestValidateToken(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "MFA challenge token is not an access token",
+			args: args{
+				tokenString: challengeToken,
+				secret:      secret,
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -253,3 +270,60 @@ func TestValidateToken(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateMFAChallengeToken(t *testing.T) {
+	userID := uuid.New()
+	secret := "test-secret"
+
+	challengeToken, _ := GenerateMFAChallengeToken(userID, "moderator", secret, time.Minute)
+	expiredChallenge, _ := GenerateMFAChallengeToken(userID, "moderator", secret, -time.Minute)
+	accessToken, _ := GenerateToken(userID, "moderator", "", secret, time.Hour)
+	withoutID := newClaims(userID, "moderator", time.Minute)
+	withoutID.Purpose = PurposeMFAChallenge
+	challengeWithoutID, _ := signClaims(withoutID, secret)
+
+	tests := []struct {
+		name        string
+		tokenString string
+		wantErr     bool
+	}{
+		{name: "Valid challenge token", tokenString: challengeToken, wantErr: false},
+		{name: "Expired challenge token", tokenString: expiredChallenge, wantErr: true},
+		{name: "Access token is not a challenge", tokenString: accessToken, wantErr: true},
+		{name: "Challenge token without jti", tokenString: challengeWithoutID, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateMFAChallengeToken(tt.tokenString, secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMFAChallengeToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.UserID != userID {
+				t.Errorf("ValidateMFAChallengeToken() got UserID = %v, want %v", got.UserID, userID)
+			}
+		})
+	}
+}
+
+func TestGenerateMFAToken(t *testing.T) {
+	userID := uuid.New()
+	secret := "test-secret"
+
+	token, err := GenerateMFAToken(userID, "moderator", "ru", secret, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateMFAToken() error = %v", err)
+	}
+
+	claims, err := ValidateToken(token, secret)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if !claims.MFA {
+		t.Errorf("GenerateMFAToken() token should carry mfa claim")
+	}
+	if claims.Locale != "ru" {
+		t.Errorf("GenerateMFAToken() locale in claims = %v, want ru", claims.Locale)
+	}
+}
@@ -5,6 +5,45 @@ import "errors"
 // Authentication errors
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidResetToken  = errors.New("invalid or expired password reset token")
+	ErrTokenRevoked       = errors.New("token has been revoked")
+	ErrDummyLoginDisabled = errors.New("dummy login is disabled in this environment")
+	ErrDummyTokenRejected = errors.New("dummy tokens are not accepted in this environment")
+
+	ErrAuthorizationRequired = errors.New("authorization header is required")
+	ErrInvalidAuthorization  = errors.New("invalid authorization header format")
+	ErrInvalidToken          = errors.New("invalid or expired token")
+	ErrUnauthenticated       = errors.New("user is not authenticated")
+)
+
+// Access errors
+var (
+	ErrInsufficientPermissions = errors.New("insufficient permissions")
+)
+
+// Two-factor authentication errors
+var (
+	ErrInvalidMFACode        = errors.New("invalid two-factor authentication code")
+	ErrInvalidMFAChallenge   = errors.New("invalid or expired two-factor authentication challenge")
+	ErrMFAAlreadyEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled         = errors.New("two-factor authentication is not enabled")
+	ErrMFAEnrollmentNotFound = errors.New("two-factor authentication enrollment not started")
+	ErrMFARequired           = errors.New("two-factor authentication is required for this role")
+	ErrMFACannotBeDisabled   = errors.New("two-factor authentication is mandatory for this role")
+)
+
+// Service account errors
+var (
+	ErrInvalidAPIKey      = errors.New("invalid, expired or revoked API key")
+	ErrInsufficientScope  = errors.New("API key does not have the required scope")
+	ErrPVZAccessForbidden = errors.New("API key is not allowed to access this pickup point")
+)
+
+// PVZ business errors
+var (
+	ErrPVZDecommissioned = errors.New("decommissioned pickup point cannot be changed")
+	ErrPVZClosed         = errors.New("pickup point is closed and does not accept receptions")
+	ErrCapacityExceeded  = errors.New("pickup point capacity exceeded")
 )
 
 // Reception business errors
@@ -19,3 +58,26 @@ var (
 var (
 	ErrNoProductsToDelete = errors.New("no products to delete in the current reception")
 )
+
+// Export business errors
+var (
+	ErrExportJobNotReady = errors.New("export job has not finished yet")
+	ErrExportJobFailed   = errors.New("export job failed")
+	ErrExportJobExpired  = errors.New("export file has expired")
+)
+
+// Import business errors
+var (
+	ErrImportReferenceNotFound = errors.New("referenced pickup point or reception has not been imported")
+)
+
+// Idempotency business errors
+var (
+	ErrIdempotencyKeyInUse  = errors.New("request with this idempotency key is still in progress")
+	ErrIdempotencyKeyReused = errors.New("idempotency key has already been used with a different request")
+)
+
+// Optimistic concurrency errors
+var (
+	ErrVersionMismatch = errors.New("resource has been modified since it was read")
+)
@@ -2,25 +2,64 @@ package apperrors
 
 import "errors"
 
+// Request validation errors
+var (
+	ErrInvalidRequestBody     = errors.New("invalid request body")
+	ErrInvalidQueryParameters = errors.New("invalid query parameters")
+	ErrRequestBodyTooLarge    = errors.New("request body is too large")
+)
+
 // User validation errors
 var (
 	ErrEmailRequired    = errors.New("email is required")
 	ErrInvalidEmail     = errors.New("invalid email format")
 	ErrPasswordRequired = errors.New("password is required")
-	ErrInvalidPassword  = errors.New("password must be at least 6 characters long")
+	ErrInvalidPassword  = errors.New("password is too short")
+	ErrPasswordTooLong  = errors.New("password is too long")
+	ErrPasswordBreached = errors.New("password appears in a list of breached passwords")
 	ErrInvalidRole      = errors.New("invalid role, must be 'employee' or 'moderator'")
+	ErrInvalidLanguage  = errors.New("invalid language, must be 'ru' or 'en'")
+)
+
+// Service account validation errors
+var (
+	ErrServiceAccountNameRequired = errors.New("service account name is required")
+	ErrInvalidScope               = errors.New("invalid API key scope")
+	ErrInvalidKeyExpiry           = errors.New("API key expiry must be in the future")
+	ErrInvalidServiceAccountID    = errors.New("invalid service account ID")
+	ErrInvalidAPIKeyID            = errors.New("invalid API key ID")
 )
 
 // PVZ validation errors
 var (
-	ErrCityRequired = errors.New("city is a required field")
-	ErrInvalidCity  = errors.New("invalid city, only Moscow, St. Petersburg and Kazan are allowed")
-	ErrInvalidPVZID = errors.New("invalid pickup point ID")
+	ErrCityRequired  = errors.New("city is a required field")
+	ErrInvalidCity   = errors.New("invalid city, only Moscow, St. Petersburg and Kazan are allowed")
+	ErrInvalidPVZID  = errors.New("invalid pickup point ID")
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+	ErrInvalidSort   = errors.New("invalid sort field, only registrationDate, city and lastReception are allowed")
+
+	ErrInvalidPVZStatus    = errors.New("invalid pickup point status, only active, temporarily_closed and decommissioned are allowed")
+	ErrInvalidCoordinates  = errors.New("latitude and longitude must be set together and be within valid ranges")
+	ErrInvalidPhone        = errors.New("phone must be in international format, e.g. +74951234567")
+	ErrInvalidWorkingHours = errors.New("invalid working hours")
+	ErrEmptyPVZUpdate      = errors.New("no pickup point fields to update")
+	ErrInvalidCapacity     = errors.New("capacity limits must be non-negative and use known product types")
+	ErrInvalidThreshold    = errors.New("utilization threshold must be greater than 0")
+	ErrInvalidNearbyRadius = errors.New("search radius must be positive and not exceed 50 km")
+	ErrNearbyPointRequired = errors.New("latitude and longitude of the search point are required")
 )
 
 // Reception validation errors
 var (
-	ErrInvalidReceptionID = errors.New("invalid reception ID")
+	ErrInvalidReceptionID     = errors.New("invalid reception ID")
+	ErrInvalidReceptionStatus = errors.New("invalid reception status, only in_progress and close are allowed")
+)
+
+// Report validation errors
+var (
+	ErrInvalidReportGroup  = errors.New("invalid report grouping, only pvz, city and productType are allowed")
+	ErrInvalidReportPeriod = errors.New("invalid report period, only day, week and month are allowed")
+	ErrInvalidDateRange    = errors.New("start of the period must not be after its end")
 )
 
 // Product validation errors
@@ -29,3 +68,45 @@ var (
 	ErrInvalidProductType  = errors.New("invalid product type, only electronics, clothes and shoes are allowed")
 	ErrInvalidProductID    = errors.New("invalid product ID")
 )
+
+// Audit validation errors
+var (
+	ErrInvalidActorID = errors.New("invalid actor ID")
+)
+
+// Export validation errors
+var (
+	ErrInvalidExportDataset = errors.New("invalid export dataset, only pvz, receptions and products are allowed")
+	ErrInvalidExportFormat  = errors.New("invalid export format, only csv and xlsx are allowed")
+	ErrInvalidExportJobID   = errors.New("invalid export job ID")
+)
+
+// Import validation errors
+var (
+	ErrInvalidImportDataset     = errors.New("invalid import dataset, only pvz, receptions and products are allowed")
+	ErrInvalidImportFormat      = errors.New("invalid import format, only csv and ndjson are allowed")
+	ErrMalformedImportRow       = errors.New("malformed row")
+	ErrImportExternalIDRequired = errors.New("external ID is required")
+	ErrDuplicateExternalID      = errors.New("external ID occurs more than once in the file")
+	ErrImportValueRequired      = errors.New("value is required")
+	ErrInvalidImportValue       = errors.New("invalid value")
+	ErrImportReceptionNotClosed = errors.New("only closed receptions can be imported")
+	ErrImportClosedBeforeOpen   = errors.New("reception cannot be closed before it was opened")
+	ErrImportFileTooLarge       = errors.New("import file is too large")
+)
+
+// Event stream validation errors
+var (
+	ErrInvalidLastEventID = errors.New("invalid last event ID")
+	ErrInvalidResumeToken = errors.New("invalid resume token")
+)
+
+// Idempotency validation errors
+var (
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+)
+
+// Precondition validation errors
+var (
+	ErrPreconditionRequired = errors.New("If-Match header is required")
+)
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"github.com/google/uuid"
+	"time"
 )
 
 /*
@@ -19,6 +20,7 @@ type ProductRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Product, error)
 	GetByReceptionID(ctx context.Context, receptionID uuid.UUID) ([]models.Product, error)
 	DeleteLastFromReception(ctx context.Context, receptionID uuid.UUID) error
+	CountByPVZID(ctx context.Context, pvzID uuid.UUID, since time.Time) (map[string]int, error)
 }
 
 type TxProductRepository interface {
@@ -31,7 +33,9 @@ type ReceptionRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Reception, error)
 	GetLastActiveByPVZID(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error)
 	GetLastReceptionByPVZID(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error)
-	CloseReception(ctx context.Context, id uuid.UUID) error
+	ListByPVZID(ctx context.Context, pvzID uuid.UUID, filter models.ReceptionFilter) ([]*models.Reception, int, error)
+	// CloseReception закрывает приёмку, если ее версия в БД равна version.
+	CloseReception(ctx context.Context, id uuid.UUID, version int64) error
 }
 
 type TxReceptionRepository interface {
@@ -42,8 +46,13 @@ type TxReceptionRepository interface {
 type PVZRepository interface {
 	Create(ctx context.Context, pvz *models.PVZ) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.PVZ, error)
-	GetAll(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, int, error)
-	GetAllWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, int, error)
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.PVZ, error)
+	// Update сохраняет профиль, если версия ПВЗ в БД равна pvz.Version, и увеличивает ее.
+	Update(ctx context.Context, pvz *models.PVZ) error
+	GetAll(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, *models.PVZPage, error)
+	GetAllWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error)
+	FindNearby(ctx context.Context, filter models.NearbyFilter) ([]models.PVZWithDistance, error)
+	ListUtilization(ctx context.Context, since time.Time, allowedIDs []uuid.UUID) ([]models.PVZUtilization, error)
 }
 
 type TxPVZRepository interface {
@@ -55,6 +64,10 @@ type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	UpdatePassword(ctx context.Context, user *models.User) error
+	UpdatePasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error
+	SetMFAEnabled(ctx context.Context, id uuid.UUID, enabled bool) error
+	SetLanguage(ctx context.Context, id uuid.UUID, language string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -62,3 +75,120 @@ type TxUserRepository interface {
 	UserRepository
 	WithTx(tx *sql.Tx) UserRepository
 }
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *models.PasswordResetToken) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+	InvalidateByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type TxPasswordResetRepository interface {
+	PasswordResetRepository
+	WithTx(tx *sql.Tx) PasswordResetRepository
+}
+
+type MFARepository interface {
+	SaveTOTP(ctx context.Context, credential *models.TOTPCredential) error
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPCredential, error)
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64) error
+	UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error
+	DeleteTOTP(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*models.RecoveryCode) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+	LockChallenge(ctx context.Context, challenge *models.MFAChallenge) (*models.MFAChallenge, error)
+	RecordChallengeFailure(ctx context.Context, id uuid.UUID) error
+	UseChallenge(ctx context.Context, id uuid.UUID) error
+}
+
+type TxMFARepository interface {
+	MFARepository
+	WithTx(tx *sql.Tx) MFARepository
+}
+
+type ServiceAccountRepository interface {
+	CreateAccount(ctx context.Context, account *models.ServiceAccount) error
+	GetAccountByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error)
+	ListAccounts(ctx context.Context) ([]*models.ServiceAccount, error)
+	CreateKey(ctx context.Context, key *models.APIKey) error
+	GetKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	ListKeys(ctx context.Context, serviceAccountID uuid.UUID) ([]*models.APIKey, error)
+	RevokeKey(ctx context.Context, id uuid.UUID) error
+	TouchKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+}
+
+type TxServiceAccountRepository interface {
+	ServiceAccountRepository
+	WithTx(tx *sql.Tx) ServiceAccountRepository
+}
+
+type AuditRepository interface {
+	Append(ctx context.Context, entry *models.AuditEntry) error
+	List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error)
+	// ListAfter возвращает до limit записей после after в порядке цепочек.
+	ListAfter(ctx context.Context, after models.AuditChainCursor, limit int) ([]*models.AuditEntry, error)
+}
+
+type TxAuditRepository interface {
+	AuditRepository
+	WithTx(tx *sql.Tx) AuditRepository
+}
+
+type ReportRepository interface {
+	ReceptionReport(ctx context.Context, filter models.ReceptionReportFilter) ([]models.ReceptionReportRow, error)
+}
+
+type ExportRepository interface {
+	// Stream читает строки набора данных курсором БД порциями по fetchSize и передает
+	// каждую в fn. Требует транзакции.
+	Stream(ctx context.Context, dataset string, filter models.ExportFilter, fetchSize int, fn func(row []any) error) error
+}
+
+type TxExportRepository interface {
+	ExportRepository
+	WithTx(tx *sql.Tx) ExportRepository
+}
+
+type ExportJobRepository interface {
+	Create(ctx context.Context, job *models.ExportJob) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ExportJob, error)
+	Update(ctx context.Context, job *models.ExportJob) error
+	FailUnfinished(ctx context.Context, reason string) (int, error)
+	DeleteExpired(ctx context.Context, now time.Time) ([]*models.ExportJob, error)
+}
+
+// ImportRepository хранит соответствие внешних ID загруженных строк и созданных сущностей.
+type ImportRepository interface {
+	FindEntityIDs(ctx context.Context, dataset string, externalIDs []string) (map[string]uuid.UUID, error)
+	SaveEntityID(ctx context.Context, dataset, externalID string, entityID uuid.UUID) error
+}
+
+type TxImportRepository interface {
+	ImportRepository
+	WithTx(tx *sql.Tx) ImportRepository
+}
+
+// EventRepository читает ленту изменений ПВЗ. События записывают триггеры БД в
+// транзакциях изменений приёмок и товаров.
+type EventRepository interface {
+	// ListAfter возвращает до limit событий с ID больше afterID и не больше untilID в
+	// порядке ID.
+	ListAfter(ctx context.Context, afterID, untilID int64, filter models.EventFilter, limit int) ([]*models.PVZEvent, error)
+	LastID(ctx context.Context) (int64, error)
+	// TxSnapshot возвращает границы текущего снимка транзакций: транзакции с xid меньше
+	// xmin завершены, а не меньше xmax - начались после снимка.
+	TxSnapshot(ctx context.Context) (xmin, xmax int64, err error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// IdempotencyRepository хранит ответы на запросы с ключом идемпотентности.
+type IdempotencyRepository interface {
+	// Acquire занимает ключ для выполнения запроса и возвращает nil. Если ключ уже занят
+	// действующей записью, возвращает ее: незавершенную, если запрос еще выполняется, или
+	// с сохраненным ответом. Истекшая запись и запись, заблокированная раньше staleBefore,
+	// занимаются заново.
+	Acquire(ctx context.Context, record *models.IdempotencyRecord, staleBefore time.Time) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *models.IdempotencyRecord) error
+	Release(ctx context.Context, record *models.IdempotencyRecord) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Действия, которые попадают в журнал аудита
+const (
+	AuditActionPVZCreate            = "pvz.create"
+	AuditActionPVZUpdate            = "pvz.update"
+	AuditActionReceptionCreate      = "reception.create"
+	AuditActionReceptionClose       = "reception.close"
+	AuditActionProductAdd           = "product.add"
+	AuditActionProductDelete        = "product.delete"
+	AuditActionUserRegister         = "user.register"
+	AuditActionPasswordChange       = "user.password_change"
+	AuditActionPasswordReset        = "user.password_reset"
+	AuditActionMFAEnable            = "user.mfa_enable"
+	AuditActionMFADisable           = "user.mfa_disable"
+	AuditActionLanguageChange       = "user.language_change"
+	AuditActionServiceAccountCreate = "service_account.create"
+	AuditActionAPIKeyIssue          = "api_key.issue"
+	AuditActionAPIKeyRevoke         = "api_key.revoke"
+)
+
+// Типы сущностей в журнале аудита
+const (
+	AuditEntityPVZ            = "pvz"
+	AuditEntityReception      = "reception"
+	AuditEntityProduct        = "product"
+	AuditEntityUser           = "user"
+	AuditEntityServiceAccount = "service_account"
+	AuditEntityAPIKey         = "api_key"
+)
+
+// AuditEntry - запись журнала аудита. Записи только добавляются; Hash связывает запись
+// с предыдущей (PrevHash), поэтому изменение или удаление любой записи обнаруживается
+// при проверке цепочки.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	ActorID    uuid.UUID       `json:"actorId"`
+	ActorRole  string          `json:"actorRole"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	ClientIP   string          `json:"clientIp"`
+	RequestID  string          `json:"requestId"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	PrevHash   string          `json:"prevHash"`
+	Hash       string          `json:"hash"`
+}
+
+type AuditFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   string
+	StartDate  *time.Time
+	EndDate    *time.Time
+	Page       int
+	Limit      int
+}
+
+// AuditVerification - результат проверки цепочки хешей журнала.
+type AuditVerification struct {
+	Valid        bool
+	CheckedCount int
+	BrokenAtID   *int64
+}
+
+// AuditChainCursor - позиция при обходе журнала по цепочкам: записи упорядочены по
+// сущности, а внутри нее по ID.
+type AuditChainCursor struct {
+	EntityType string
+	EntityID   string
+	ID         int64
+}
+
+// Actor описывает, кто и откуда выполняет операцию. Передается через контекст запроса.
+type Actor struct {
+	ID        uuid.UUID
+	Role      string
+	ClientIP  string
+	RequestID string
+}
+
+type actorContextKey struct{}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext возвращает участника операции или пустое значение для системных вызовов.
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorContextKey{}).(Actor)
+	return actor
+}
+
+// NewAuditEntry создает запись без хеша. Снимки до и после сериализуются в JSON,
+// nil означает отсутствие снимка.
+func NewAuditEntry(actor Actor, action, entityType, entityID string, before, after any) (*AuditEntry, error) {
+	beforeJSON, err := marshalSnapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	afterJSON, err := marshalSnapshot(after)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditEntry{
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		ClientIP:   actor.ClientIP,
+		RequestID:  actor.RequestID,
+		// Точность PostgreSQL - микросекунды, иначе хеш не совпадет после чтения из базы
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}, nil
+}
+
+// Seal связывает запись с предыдущей записью той же сущности и вычисляет ее хеш.
+func (e *AuditEntry) Seal(prevHash string) {
+	e.PrevHash = prevHash
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash считает SHA-256 от предыдущего хеша и всех полей записи, кроме ID.
+func (e *AuditEntry) ComputeHash() string {
+	payload, _ := json.Marshal(struct {
+		PrevHash   string          `json:"prevHash"`
+		ActorID    uuid.UUID       `json:"actorId"`
+		ActorRole  string          `json:"actorRole"`
+		Action     string          `json:"action"`
+		EntityType string          `json:"entityType"`
+		EntityID   string          `json:"entityId"`
+		Before     json.RawMessage `json:"before"`
+		After      json.RawMessage `json:"after"`
+		ClientIP   string          `json:"clientIp"`
+		RequestID  string          `json:"requestId"`
+		CreatedAt  string          `json:"createdAt"`
+	}{
+		PrevHash:   e.PrevHash,
+		ActorID:    e.ActorID,
+		ActorRole:  e.ActorRole,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     nullIfEmpty(e.Before),
+		After:      nullIfEmpty(e.After),
+		ClientIP:   e.ClientIP,
+		RequestID:  e.RequestID,
+		CreatedAt:  e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+func marshalSnapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func nullIfEmpty(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	return raw
+}
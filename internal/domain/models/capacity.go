@@ -0,0 +1,116 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"sort"
+)
+
+// CapacityScopeTotal - ограничение на общее количество товаров в ПВЗ. Ограничения по
+// типам товаров обозначаются самим типом.
+const CapacityScopeTotal = "total"
+
+// Политики переполнения ПВЗ
+const (
+	CapacityOverflowReject = "reject"
+	CapacityOverflowWarn   = "warn"
+)
+
+// PVZCapacity - вместимость ПВЗ: общая и по типам товаров. Нулевое значение означает,
+// что ограничения нет.
+type PVZCapacity struct {
+	Total  int            `json:"total,omitempty"`
+	ByType map[string]int `json:"byType,omitempty"`
+}
+
+func (c *PVZCapacity) Validate() error {
+	if c.Total < 0 {
+		return apperrors.ErrInvalidCapacity
+	}
+	for productType, limit := range c.ByType {
+		if !IsValidProductType(productType) || limit < 0 {
+			return apperrors.ErrInvalidCapacity
+		}
+	}
+	return nil
+}
+
+// IsEmpty сообщает, что вместимость не ограничена ни в целом, ни по типам.
+func (c *PVZCapacity) IsEmpty() bool {
+	if c.Total > 0 {
+		return false
+	}
+	for _, limit := range c.ByType {
+		if limit > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// CapacityUsage - заполненность ПВЗ в пределах одного ограничения.
+type CapacityUsage struct {
+	Scope    string `json:"scope"`
+	Occupied int    `json:"occupied"`
+	Capacity int    `json:"capacity"`
+}
+
+func (u CapacityUsage) Utilization() float64 {
+	return float64(u.Occupied) / float64(u.Capacity)
+}
+
+func (u CapacityUsage) IsExceeded() bool {
+	return u.Occupied > u.Capacity
+}
+
+// Usage возвращает заполненность по каждому заданному ограничению. occupancy -
+// количество товаров в ПВЗ по типам.
+func (c *PVZCapacity) Usage(occupancy map[string]int) []CapacityUsage {
+	var usage []CapacityUsage
+
+	if c.Total > 0 {
+		total := 0
+		for _, count := range occupancy {
+			total += count
+		}
+		usage = append(usage, CapacityUsage{Scope: CapacityScopeTotal, Occupied: total, Capacity: c.Total})
+	}
+
+	types := make([]string, 0, len(c.ByType))
+	for productType, limit := range c.ByType {
+		if limit > 0 {
+			types = append(types, productType)
+		}
+	}
+	sort.Strings(types)
+
+	for _, productType := range types {
+		usage = append(usage, CapacityUsage{Scope: productType, Occupied: occupancy[productType], Capacity: c.ByType[productType]})
+	}
+
+	return usage
+}
+
+// PVZUtilization - заполненность ПВЗ с заданной вместимостью.
+type PVZUtilization struct {
+	PVZ       *PVZ
+	Occupancy map[string]int
+	Usage     []CapacityUsage
+}
+
+// Occupied возвращает общее количество товаров в ПВЗ.
+func (u PVZUtilization) Occupied() int {
+	total := 0
+	for _, count := range u.Occupancy {
+		total += count
+	}
+	return total
+}
+
+// MaxUtilization возвращает наибольшую заполненность среди ограничений ПВЗ.
+func (u PVZUtilization) MaxUtilization() float64 {
+	maxUtilization := 0.0
+	for _, item := range u.Usage {
+		maxUtilization = max(maxUtilization, item.Utilization())
+	}
+	return maxUtilization
+}
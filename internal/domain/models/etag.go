@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// InitialVersion - версия только что созданного ПВЗ или приёмки.
+const InitialVersion = 1
+
+// EntityTag - версия ресурса, которую клиент получает в ETag и передает в If-Match
+// и If-None-Match. В тег входит ID ресурса: текущая приёмка ПВЗ со временем
+// становится другой приёмкой, и одна версия их не различает.
+type EntityTag struct {
+	ID      uuid.UUID
+	Version int64
+}
+
+// String возвращает сильный ETag вида "<id>.<version>".
+func (t EntityTag) String() string {
+	return fmt.Sprintf(`"%s.%d"`, t.ID, t.Version)
+}
+
+// ParseEntityTag разбирает сильный ETag, выданный String.
+func ParseEntityTag(s string) (EntityTag, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return EntityTag{}, false
+	}
+
+	id, version, found := strings.Cut(s[1:len(s)-1], ".")
+	if !found {
+		return EntityTag{}, false
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return EntityTag{}, false
+	}
+	parsedVersion, err := strconv.ParseInt(version, 10, 64)
+	if err != nil || parsedVersion < InitialVersion {
+		return EntityTag{}, false
+	}
+
+	return EntityTag{ID: parsedID, Version: parsedVersion}, true
+}
+
+// Precondition - условие If-Match: изменение выполняется, только если текущая версия
+// ресурса совпадает с одним из тегов. If-Match: * выполняется для любой версии.
+type Precondition struct {
+	Any  bool
+	Tags []EntityTag
+}
+
+// ParsePrecondition разбирает If-Match. Слабые и чужие теги не совпадают ни с одной
+// версией: If-Match сравнивает теги строго.
+func ParsePrecondition(header string) Precondition {
+	var precondition Precondition
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" {
+			return Precondition{Any: true}
+		}
+		if tag, ok := ParseEntityTag(value); ok {
+			precondition.Tags = append(precondition.Tags, tag)
+		}
+	}
+	return precondition
+}
+
+// Matches сообщает, выполнено ли условие для версии version ресурса id.
+func (p Precondition) Matches(id uuid.UUID, version int64) bool {
+	if p.Any {
+		return true
+	}
+	for _, tag := range p.Tags {
+		if tag.ID == id && tag.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesIfNoneMatch сообщает, что у клиента уже есть текущая версия ресурса и ответ
+// можно заменить на 304. If-None-Match сравнивает теги без учета слабости.
+func MatchesIfNoneMatch(header string, current EntityTag) bool {
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" {
+			return true
+		}
+		if tag, ok := ParseEntityTag(strings.TrimPrefix(value, "W/")); ok && tag == current {
+			return true
+		}
+	}
+	return false
+}
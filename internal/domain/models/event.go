@@ -0,0 +1,57 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы событий ленты изменений ПВЗ
+const (
+	EventTypeReceptionOpened = "reception.opened"
+	EventTypeReceptionClosed = "reception.closed"
+	EventTypeProductAdded    = "product.added"
+	EventTypeProductRemoved  = "product.removed"
+)
+
+// PVZEvent - событие ленты изменений ПВЗ. ID возрастает в порядке фиксации изменений,
+// поэтому по последнему полученному ID клиент продолжает ленту без пропусков.
+type PVZEvent struct {
+	ID          int64
+	Type        string
+	PVZID       uuid.UUID
+	City        string
+	ReceptionID uuid.UUID
+	// ProductID и ProductType заполнены у событий товаров.
+	ProductID   *uuid.UUID
+	ProductType string
+	CreatedAt   time.Time
+}
+
+// EventFilter - какие события получает подписчик. Пустой фильтр пропускает все события;
+// PVZIDs ограничивается и для API-ключей, которым доступны только отдельные ПВЗ.
+type EventFilter struct {
+	PVZIDs []uuid.UUID
+	Cities []string
+}
+
+func (f EventFilter) Validate() error {
+	for _, city := range f.Cities {
+		if !IsValidCity(city) {
+			return apperrors.ErrInvalidCity
+		}
+	}
+	return nil
+}
+
+func (f EventFilter) Matches(event *PVZEvent) bool {
+	if len(f.PVZIDs) > 0 && !slices.Contains(f.PVZIDs, event.PVZID) {
+		return false
+	}
+	if len(f.Cities) > 0 && !slices.Contains(f.Cities, event.City) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Наборы данных выгрузки
+const (
+	ExportDatasetPVZ        = "pvz"
+	ExportDatasetReceptions = "receptions"
+	ExportDatasetProducts   = "products"
+)
+
+// Форматы выгрузки
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+// Статусы фоновой выгрузки
+const (
+	ExportJobStatusPending = "pending"
+	ExportJobStatusRunning = "running"
+	ExportJobStatusDone    = "done"
+	ExportJobStatusFailed  = "failed"
+)
+
+// exportColumns - заголовки столбцов выгрузки в порядке значений строки.
+var exportColumns = map[string][]string{
+	ExportDatasetPVZ:        {"id", "registrationDate", "city", "address", "latitude", "longitude", "phone", "status"},
+	ExportDatasetReceptions: {"id", "pvzId", "city", "dateTime", "closedAt", "status", "products"},
+	ExportDatasetProducts:   {"id", "receptionId", "pvzId", "city", "dateTime", "type"},
+}
+
+// ExportColumns возвращает заголовки столбцов набора данных.
+func ExportColumns(dataset string) []string {
+	return exportColumns[dataset]
+}
+
+// ExportFilter - условия выгрузки, те же, что у списка ПВЗ, без сортировки и пагинации.
+// Условия на приёмки ограничивают и ПВЗ, и выгружаемые приёмки с товарами. PVZIDs
+// задается для API-ключей, которым доступны только отдельные ПВЗ.
+type ExportFilter struct {
+	StartDate        *time.Time  `json:"startDate,omitempty"`
+	EndDate          *time.Time  `json:"endDate,omitempty"`
+	Cities           []string    `json:"cities,omitempty"`
+	RegisteredFrom   *time.Time  `json:"registeredFrom,omitempty"`
+	RegisteredTo     *time.Time  `json:"registeredTo,omitempty"`
+	ReceptionStatus  string      `json:"receptionStatus,omitempty"`
+	ProductType      string      `json:"productType,omitempty"`
+	HasOpenReception *bool       `json:"hasOpenReception,omitempty"`
+	PVZIDs           []uuid.UUID `json:"pvzIds,omitempty"`
+}
+
+// PVZFilter возвращает фильтр списка ПВЗ с теми же условиями.
+func (f ExportFilter) PVZFilter() PVZFilter {
+	return PVZFilter{
+		StartDate:        f.StartDate,
+		EndDate:          f.EndDate,
+		Cities:           f.Cities,
+		RegisteredFrom:   f.RegisteredFrom,
+		RegisteredTo:     f.RegisteredTo,
+		ReceptionStatus:  f.ReceptionStatus,
+		ProductType:      f.ProductType,
+		HasOpenReception: f.HasOpenReception,
+	}
+}
+
+type ExportRequest struct {
+	Dataset string
+	Format  string
+	Filter  ExportFilter
+}
+
+func (r ExportRequest) Validate() error {
+	if _, ok := exportColumns[r.Dataset]; !ok {
+		return apperrors.ErrInvalidExportDataset
+	}
+
+	if r.Format != ExportFormatCSV && r.Format != ExportFormatXLSX {
+		return apperrors.ErrInvalidExportFormat
+	}
+
+	return r.Filter.PVZFilter().Validate()
+}
+
+// FileName возвращает имя файла выгрузки, например pvz-20250410-123000.csv.
+func (r ExportRequest) FileName(at time.Time) string {
+	return fmt.Sprintf("%s-%s.%s", r.Dataset, at.UTC().Format("20060102-150405"), r.Format)
+}
+
+// ExportJob - фоновая выгрузка. Файл доступен создателю до ExpiresAt.
+type ExportJob struct {
+	ID         uuid.UUID
+	Request    ExportRequest
+	Status     string
+	RowCount   int
+	Error      string
+	CreatedBy  uuid.UUID
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+	ExpiresAt  *time.Time
+}
+
+func NewExportJob(req ExportRequest, createdBy uuid.UUID) *ExportJob {
+	return &ExportJob{
+		ID:        uuid.New(),
+		Request:   req,
+		Status:    ExportJobStatusPending,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Finish отмечает завершение выгрузки: успешное, если err == nil.
+func (j *ExportJob) Finish(rowCount int, err error, ttl time.Duration) {
+	now := time.Now()
+	j.FinishedAt = &now
+	j.RowCount = rowCount
+
+	if err != nil {
+		j.Status = ExportJobStatusFailed
+		j.Error = err.Error()
+		return
+	}
+
+	expiresAt := now.Add(ttl)
+	j.Status = ExportJobStatusDone
+	j.ExpiresAt = &expiresAt
+}
+
+// IsExpired сообщает, что файл выгрузки уже удален или подлежит удалению.
+func (j *ExportJob) IsExpired(now time.Time) bool {
+	return j.ExpiresAt != nil && !now.Before(*j.ExpiresAt)
+}
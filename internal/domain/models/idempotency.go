@@ -0,0 +1,75 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxIdempotencyKeyLength - предельная длина ключа идемпотентности.
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyRecord - запрос, выполненный с ключом идемпотентности. Пока запрос
+// выполняется, StatusCode равен нулю; после этого запись хранит ответ, который
+// возвращается на повторы до ExpiresAt.
+type IdempotencyRecord struct {
+	// ActorID - пользователь или сервисная учетная запись; ключи разных клиентов не
+	// пересекаются.
+	ActorID     uuid.UUID
+	Key         string
+	Fingerprint string
+	StatusCode  int
+	// Header - заголовки, которые выставил обработчик запроса (Content-Type, ETag,
+	// Location и т.п.); они возвращаются вместе с ответом.
+	Header    http.Header
+	Body      []byte
+	LockedAt  time.Time
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != 0
+}
+
+// ValidateIdempotencyKey допускает ключи из видимых символов ASCII, например UUID.
+func ValidateIdempotencyKey(key string) error {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return apperrors.ErrInvalidIdempotencyKey
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return apperrors.ErrInvalidIdempotencyKey
+		}
+	}
+	return nil
+}
+
+// IdempotencyFingerprint - отпечаток запроса, по которому повтор отличается от другого
+// запроса с тем же ключом.
+func IdempotencyFingerprint(method, uri string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(uri))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// IsIdempotentResponse сообщает, сохраняется ли ответ для повторов. Ошибки сервера и
+// отказы в доступе не сохраняются: повтор выполнит запрос заново.
+func IsIdempotentResponse(statusCode int) bool {
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		return false
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden, statusCode == http.StatusTooManyRequests:
+		return false
+	default:
+		return true
+	}
+}
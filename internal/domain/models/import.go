@@ -0,0 +1,266 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Наборы данных загрузки
+const (
+	ImportDatasetPVZ        = "pvz"
+	ImportDatasetReceptions = "receptions"
+	ImportDatasetProducts   = "products"
+)
+
+// Форматы загрузки
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// ImportColumnExternalID - столбец внешнего ID, по которому повторная загрузка
+// пропускает уже загруженные строки.
+const ImportColumnExternalID = "externalId"
+
+// importParents - на какой набор данных и каким столбцом ссылаются строки набора.
+var importParents = map[string]struct{ dataset, column string }{
+	ImportDatasetPVZ:        {},
+	ImportDatasetReceptions: {ImportDatasetPVZ, "pvzExternalId"},
+	ImportDatasetProducts:   {ImportDatasetReceptions, "receptionExternalId"},
+}
+
+// ImportParentDataset возвращает набор данных, на строки которого ссылаются строки
+// dataset, или пустую строку, если ссылок нет.
+func ImportParentDataset(dataset string) string {
+	return importParents[dataset].dataset
+}
+
+// ImportParentColumn возвращает столбец, в котором строки dataset ссылаются на родителя.
+func ImportParentColumn(dataset string) string {
+	return importParents[dataset].column
+}
+
+type ImportRequest struct {
+	Dataset string
+	Format  string
+	// DryRun - только проверить файл, ничего не записывая.
+	DryRun bool
+}
+
+func (r ImportRequest) Validate() error {
+	if _, ok := importParents[r.Dataset]; !ok {
+		return apperrors.ErrInvalidImportDataset
+	}
+
+	if r.Format != ImportFormatCSV && r.Format != ImportFormatNDJSON {
+		return apperrors.ErrInvalidImportFormat
+	}
+
+	return nil
+}
+
+// ImportFieldError - ошибка в значении столбца строки загрузки.
+type ImportFieldError struct {
+	Column string
+	Err    error
+}
+
+func (e *ImportFieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Column, e.Err)
+}
+
+func (e *ImportFieldError) Unwrap() error {
+	return e.Err
+}
+
+func importFieldError(column string, err error) error {
+	return &ImportFieldError{Column: column, Err: err}
+}
+
+// ImportRow - строка файла загрузки: номер строки в файле и значения по именам столбцов.
+// Сущности строятся теми же конструкторами, что и при создании через API.
+type ImportRow struct {
+	Line   int
+	Fields map[string]string
+}
+
+func (r ImportRow) ExternalID() string {
+	return r.Fields[ImportColumnExternalID]
+}
+
+// ParentExternalID возвращает внешний ID ПВЗ для приёмки или приёмки для товара.
+func (r ImportRow) ParentExternalID(dataset string) string {
+	return r.Fields[ImportParentColumn(dataset)]
+}
+
+// PVZ строит ПВЗ из столбцов city, registrationDate, address, latitude, longitude,
+// phone, status и workingHours (JSON). Без registrationDate ПВЗ регистрируется сейчас.
+func (r ImportRow) PVZ() (*PVZ, error) {
+	pvz, err := NewPVZ(r.Fields["city"])
+	if err != nil {
+		return nil, importFieldError("city", err)
+	}
+
+	if value, ok := r.Fields["registrationDate"]; ok {
+		if pvz.RegistrationDate, err = parseImportTime("registrationDate", value); err != nil {
+			return nil, err
+		}
+	}
+
+	var update PVZUpdate
+	if value, ok := r.Fields["address"]; ok {
+		update.Address = &value
+	}
+	if update.Latitude, err = r.optionalFloat("latitude"); err != nil {
+		return nil, err
+	}
+	if update.Longitude, err = r.optionalFloat("longitude"); err != nil {
+		return nil, err
+	}
+	if value, ok := r.Fields["phone"]; ok {
+		update.Phone = &value
+	}
+	if value, ok := r.Fields["status"]; ok {
+		update.Status = &value
+	}
+	if value, ok := r.Fields["workingHours"]; ok {
+		var hours WorkingHours
+		if err := json.Unmarshal([]byte(value), &hours); err != nil {
+			return nil, importFieldError("workingHours", apperrors.ErrInvalidWorkingHours)
+		}
+		update.WorkingHours = &hours
+	}
+
+	if !update.IsEmpty() {
+		if err := pvz.Apply(update); err != nil {
+			return nil, err
+		}
+	}
+
+	return pvz, nil
+}
+
+// Reception строит закрытую приёмку ПВЗ pvzID из столбцов dateTime, closedAt и status.
+// Загружается только история, поэтому открытые приёмки не принимаются, а время
+// закрытия обязательно и не раньше времени открытия.
+func (r ImportRow) Reception(pvzID uuid.UUID) (*Reception, error) {
+	reception, err := NewReception(pvzID)
+	if err != nil {
+		return nil, importFieldError(ImportParentColumn(ImportDatasetReceptions), err)
+	}
+
+	if reception.DateTime, err = r.requiredTime("dateTime"); err != nil {
+		return nil, err
+	}
+
+	switch status := r.Fields["status"]; status {
+	case "", ReceptionStatusClosed:
+		reception.Status = ReceptionStatusClosed
+	case ReceptionStatusInProgress:
+		return nil, importFieldError("status", apperrors.ErrImportReceptionNotClosed)
+	default:
+		return nil, importFieldError("status", apperrors.ErrInvalidReceptionStatus)
+	}
+
+	closedAt, err := r.requiredTime("closedAt")
+	if err != nil {
+		return nil, err
+	}
+	if closedAt.Before(reception.DateTime) {
+		return nil, importFieldError("closedAt", apperrors.ErrImportClosedBeforeOpen)
+	}
+	reception.ClosedAt = &closedAt
+
+	return reception, nil
+}
+
+// Product строит товар приёмки receptionID из столбцов type и dateTime.
+func (r ImportRow) Product(receptionID uuid.UUID) (*Product, error) {
+	product, err := NewProduct(r.Fields["type"], receptionID)
+	if err != nil {
+		column := "type"
+		if errors.Is(err, apperrors.ErrInvalidReceptionID) {
+			column = ImportParentColumn(ImportDatasetProducts)
+		}
+		return nil, importFieldError(column, err)
+	}
+
+	if product.DateTime, err = r.requiredTime("dateTime"); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (r ImportRow) requiredTime(column string) (time.Time, error) {
+	value, ok := r.Fields[column]
+	if !ok {
+		return time.Time{}, importFieldError(column, apperrors.ErrImportValueRequired)
+	}
+	return parseImportTime(column, value)
+}
+
+func (r ImportRow) optionalFloat(column string) (*float64, error) {
+	value, ok := r.Fields[column]
+	if !ok {
+		return nil, nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, importFieldError(column, apperrors.ErrInvalidImportValue)
+	}
+	return &number, nil
+}
+
+// parseImportTime разбирает время в формате RFC 3339, как его пишет выгрузка.
+func parseImportTime(column, value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, importFieldError(column, apperrors.ErrInvalidImportValue)
+	}
+	return t, nil
+}
+
+// ImportRowError - строка, которая не загружена, и причина.
+type ImportRowError struct {
+	Line       int
+	ExternalID string
+	// Column - столбец с ошибкой, если ошибка относится к одному столбцу.
+	Column string
+	Err    error
+}
+
+// ImportReport - итог загрузки файла. При DryRun Created - сколько строк было бы создано.
+type ImportReport struct {
+	Dataset string
+	DryRun  bool
+	Total   int
+	Created int
+	// Skipped - строки, внешний ID которых уже загружен.
+	Skipped int
+	Errors  []ImportRowError
+}
+
+func (r *ImportReport) AddError(line int, externalID string, err error) {
+	rowErr := ImportRowError{Line: line, ExternalID: externalID, Err: err}
+
+	var fieldErr *ImportFieldError
+	if errors.As(err, &fieldErr) {
+		rowErr.Column = fieldErr.Column
+		rowErr.Err = fieldErr.Err
+	}
+
+	r.Errors = append(r.Errors, rowErr)
+}
+
+func (r *ImportReport) Failed() int {
+	return len(r.Errors)
+}
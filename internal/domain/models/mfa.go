@@ -0,0 +1,102 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/pkg/hasher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RecoveryCodesCount = 10
+	recoveryCodeBytes  = 5
+)
+
+// TOTPCredential хранит секрет аутентификатора пользователя. Пока ConfirmedAt
+// не заполнен, регистрация не завершена и секрет не используется при входе.
+type TOTPCredential struct {
+	UserID       uuid.UUID  `json:"userId"`
+	Secret       string     `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmedAt,omitempty"`
+	LastUsedStep int64      `json:"-"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type RecoveryCode struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"userId"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func NewTOTPCredential(userID uuid.UUID, secret string) *TOTPCredential {
+	return &TOTPCredential{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (c *TOTPCredential) IsConfirmed() bool {
+	return c.ConfirmedAt != nil
+}
+
+// MFAChallenge - попытка входа со вторым фактором по токену второго шага с ID = jti
+// токена. Токен перестает приниматься после входа или исчерпания попыток.
+type MFAChallenge struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	FailedAttempts int
+	UsedAt         *time.Time
+	ExpiresAt      time.Time
+}
+
+// CanAttempt сообщает, можно ли еще ввести код по этому токену.
+func (c *MFAChallenge) CanAttempt(maxAttempts int) bool {
+	return c.UsedAt == nil && c.FailedAttempts < maxAttempts
+}
+
+// NewRecoveryCodes генерирует набор одноразовых кодов восстановления. Возвращает
+// модели с хешами кодов для хранения и сами коды для однократного показа пользователю.
+func NewRecoveryCodes(userID uuid.UUID, count int) ([]*RecoveryCode, []string, error) {
+	now := time.Now()
+	codes := make([]*RecoveryCode, 0, count)
+	plain := make([]string, 0, count)
+
+	for i := 0; i < count; i++ {
+		buf := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		raw := hex.EncodeToString(buf)
+		code := raw[:5] + "-" + raw[5:]
+
+		codes = append(codes, &RecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  HashRecoveryCode(code),
+			CreatedAt: now,
+		})
+		plain = append(plain, code)
+	}
+
+	return codes, plain, nil
+}
+
+// HashRecoveryCode приводит код к каноничному виду, чтобы регистр и дефисы
+// при вводе не влияли на проверку.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	return hasher.HashToken(normalized)
+}
@@ -1,7 +1,12 @@
 package models
 
 import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"errors"
 	"github.com/google/uuid"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -352,7 +357,7 @@ func TestNewUser(t *testing.T) {
 			args: args{
 				email:    "user@example.com",
 				password: "password123",
-				role:     "admin",
+				role:     "superuser",
 			},
 			wantErr: true,
 		},
@@ -658,3 +663,912 @@ func TestUser_IsModerator(t *testing.T) {
 		})
 	}
 }
+
+func TestUser_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "Valid password", password: "newPassword123", wantErr: false},
+		{name: "Short password", password: "123", wantErr: true},
+		{name: "Empty password", password: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &User{ID: uuid.New(), PasswordHash: "old"}
+			err := u.ChangePassword(tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ChangePassword() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				if u.PasswordHash != "old" || u.PasswordChangedAt != nil {
+					t.Errorf("ChangePassword() should not modify user on error")
+				}
+				return
+			}
+			if u.PasswordHash == "old" || u.PasswordHash == tt.password {
+				t.Errorf("ChangePassword() did not hash the new password")
+			}
+			if u.PasswordChangedAt == nil {
+				t.Errorf("ChangePassword() should set PasswordChangedAt")
+			}
+		})
+	}
+}
+
+func TestNewPasswordResetToken(t *testing.T) {
+	userID := uuid.New()
+
+	got, token, err := NewPasswordResetToken(userID, time.Hour)
+	if err != nil {
+		t.Fatalf("NewPasswordResetToken() error = %v", err)
+	}
+	if token == "" || got.TokenHash == "" || got.TokenHash == token {
+		t.Errorf("NewPasswordResetToken() must return raw token and store only its hash")
+	}
+	if got.UserID != userID {
+		t.Errorf("NewPasswordResetToken().UserID = %v, want %v", got.UserID, userID)
+	}
+	if !got.IsUsable(time.Now()) {
+		t.Errorf("fresh token should be usable")
+	}
+	if got.IsUsable(time.Now().Add(2 * time.Hour)) {
+		t.Errorf("expired token should not be usable")
+	}
+
+	usedAt := time.Now()
+	got.UsedAt = &usedAt
+	if got.IsUsable(time.Now()) {
+		t.Errorf("used token should not be usable")
+	}
+
+	_, other, _ := NewPasswordResetToken(userID, time.Hour)
+	if other == token {
+		t.Errorf("NewPasswordResetToken() returned the same token twice")
+	}
+}
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := NewPasswordPolicy(8, 16, []string{"Password123"})
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "Valid password", password: "correct-horse", wantErr: nil},
+		{name: "Empty password", password: "", wantErr: apperrors.ErrPasswordRequired},
+		{name: "Too short", password: "short", wantErr: apperrors.ErrInvalidPassword},
+		{name: "Too long", password: "this-password-is-way-too-long", wantErr: apperrors.ErrPasswordTooLong},
+		{name: "Breached password in another case", password: "PASSWORD123", wantErr: apperrors.ErrPasswordBreached},
+		{name: "Length counts characters, not bytes", password: "пароль12", wantErr: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewUser_PasswordPolicy(t *testing.T) {
+	SetPasswordPolicy(NewPasswordPolicy(6, 128, []string{"qwerty123"}))
+	defer SetPasswordPolicy(DefaultPasswordPolicy)
+
+	if _, err := NewUser("user@example.com", "qwerty123", RoleEmployee); !errors.Is(err, apperrors.ErrPasswordBreached) {
+		t.Errorf("NewUser() error = %v, want %v", err, apperrors.ErrPasswordBreached)
+	}
+}
+
+func TestLoadPasswordBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	content := "# top passwords\n123456\n\n  qwerty  \n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write blocklist: %v", err)
+	}
+
+	got, err := LoadPasswordBlocklist(path)
+	if err != nil {
+		t.Fatalf("LoadPasswordBlocklist() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != "123456" || got[1] != "qwerty" {
+		t.Errorf("LoadPasswordBlocklist() = %v, want [123456 qwerty]", got)
+	}
+
+	if _, err := LoadPasswordBlocklist(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("LoadPasswordBlocklist() expected error for missing file")
+	}
+}
+
+func TestNewAPIKey(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		scopes    []string
+		expiresAt *time.Time
+		wantErr   error
+	}{
+		{name: "Valid key", scopes: []string{ScopePVZRead}, expiresAt: &future},
+		{name: "Without expiry", scopes: []string{ScopeReceptionsWrite, ScopeProductsWrite}},
+		{name: "No scopes", scopes: nil, wantErr: apperrors.ErrInvalidScope},
+		{name: "Unknown scope", scopes: []string{"admin"}, wantErr: apperrors.ErrInvalidScope},
+		{name: "Expiry in the past", scopes: []string{ScopePVZRead}, expiresAt: &past, wantErr: apperrors.ErrInvalidKeyExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, raw, err := NewAPIKey(uuid.New(), tt.scopes, nil, tt.expiresAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if key.Prefix != raw[:len(key.Prefix)] {
+				t.Errorf("NewAPIKey() prefix = %v, key = %v", key.Prefix, raw)
+			}
+			if key.KeyHash == raw {
+				t.Error("NewAPIKey() must not store the raw key")
+			}
+			if !key.IsActive(time.Now()) {
+				t.Error("NewAPIKey() key must be active")
+			}
+		})
+	}
+}
+
+func TestAPIKey_Access(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	pvzID := uuid.New()
+
+	restricted := &APIKey{Scopes: []string{ScopeReceptionsWrite}, PVZIDs: []uuid.UUID{pvzID}}
+	if !restricted.CanAccessPVZ(pvzID) || restricted.CanAccessPVZ(uuid.New()) {
+		t.Error("CanAccessPVZ() must allow only listed PVZs")
+	}
+	if !(&APIKey{}).CanAccessPVZ(uuid.New()) {
+		t.Error("CanAccessPVZ() must allow any PVZ when list is empty")
+	}
+	if !restricted.HasScope(ScopeReceptionsWrite) || restricted.HasScope(ScopeProductsWrite) {
+		t.Error("HasScope() returned unexpected result")
+	}
+	if (&APIKey{RevokedAt: &past}).IsActive(now) {
+		t.Error("IsActive() must be false for revoked key")
+	}
+	if (&APIKey{ExpiresAt: &past}).IsActive(now) {
+		t.Error("IsActive() must be false for expired key")
+	}
+}
+
+func TestPVZCursor_EncodeDecode(t *testing.T) {
+	cursor := PVZCursor{
+		SortBy:   PVZSortCity,
+		SortDesc: true,
+		Key:      CityKazan,
+		ID:       uuid.New(),
+		Backward: true,
+	}
+
+	decoded, err := DecodePVZCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodePVZCursor() error = %v", err)
+	}
+	if *decoded != cursor {
+		t.Errorf("DecodePVZCursor() = %+v, want %+v", decoded, cursor)
+	}
+
+	for _, raw := range []string{"", "not-a-cursor", "e30"} {
+		if _, err := DecodePVZCursor(raw); !errors.Is(err, apperrors.ErrInvalidCursor) {
+			t.Errorf("DecodePVZCursor(%q) error = %v, want %v", raw, err, apperrors.ErrInvalidCursor)
+		}
+	}
+}
+
+func TestDecodePVZCursor_Key(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name    string
+		sortBy  string
+		key     string
+		wantErr bool
+	}{
+		{name: "registration date", sortBy: PVZSortRegistrationDate, key: "2025-04-14T07:00:00.123456Z"},
+		{name: "last reception", sortBy: PVZSortLastReception, key: "0001-01-01T00:00:00Z"},
+		{name: "city", sortBy: PVZSortCity, key: CityMoscow},
+		{name: "date is not a timestamp", sortBy: PVZSortRegistrationDate, key: "yesterday", wantErr: true},
+		{name: "last reception is not a timestamp", sortBy: PVZSortLastReception, key: CityMoscow, wantErr: true},
+		{name: "unknown city", sortBy: PVZSortCity, key: "Атлантида", wantErr: true},
+		{name: "unknown sort field", sortBy: "address", key: "ул. Ленина", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := PVZCursor{SortBy: tt.sortBy, Key: tt.key, ID: id}.Encode()
+
+			_, err := DecodePVZCursor(raw)
+			if tt.wantErr && !errors.Is(err, apperrors.ErrInvalidCursor) {
+				t.Errorf("DecodePVZCursor() error = %v, want %v", err, apperrors.ErrInvalidCursor)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("DecodePVZCursor() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPVZFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  PVZFilter
+		wantErr error
+	}{
+		{
+			name:   "Empty filter",
+			filter: PVZFilter{},
+		},
+		{
+			name: "All filters",
+			filter: PVZFilter{
+				Cities:          []string{CityMoscow, CityKazan},
+				ReceptionStatus: ReceptionStatusClosed,
+				ProductType:     ProductTypeShoes,
+				SortBy:          PVZSortLastReception,
+				SortDesc:        true,
+				Cursor:          &PVZCursor{SortBy: PVZSortLastReception, SortDesc: true, ID: uuid.New()},
+			},
+		},
+		{
+			name:    "Unknown city",
+			filter:  PVZFilter{Cities: []string{CityMoscow, "Тверь"}},
+			wantErr: apperrors.ErrInvalidCity,
+		},
+		{
+			name:    "Unknown reception status",
+			filter:  PVZFilter{ReceptionStatus: "open"},
+			wantErr: apperrors.ErrInvalidReceptionStatus,
+		},
+		{
+			name:    "Unknown product type",
+			filter:  PVZFilter{ProductType: "мебель"},
+			wantErr: apperrors.ErrInvalidProductType,
+		},
+		{
+			name:    "Unknown sort field",
+			filter:  PVZFilter{SortBy: "id"},
+			wantErr: apperrors.ErrInvalidSort,
+		},
+		{
+			name: "Cursor issued for another sort",
+			filter: PVZFilter{
+				SortBy: PVZSortCity,
+				Cursor: &PVZCursor{SortBy: PVZSortRegistrationDate, ID: uuid.New()},
+			},
+			wantErr: apperrors.ErrInvalidCursor,
+		},
+		{
+			name: "Cursor issued for another direction",
+			filter: PVZFilter{
+				SortDesc: true,
+				Cursor:   &PVZCursor{SortBy: PVZSortRegistrationDate, ID: uuid.New()},
+			},
+			wantErr: apperrors.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPVZ_Apply(t *testing.T) {
+	address := "  ул. Тверская, 1 "
+	latitude, longitude := 55.75, 37.61
+	badLatitude := 91.0
+	phone := "+74951234567"
+	badPhone := "8 (495) 123-45-67"
+	closed := PVZStatusTemporarilyClosed
+	unknownStatus := "archived"
+
+	tests := []struct {
+		name    string
+		status  string
+		update  PVZUpdate
+		wantErr error
+	}{
+		{
+			name:   "Full profile",
+			status: PVZStatusActive,
+			update: PVZUpdate{
+				Address:   &address,
+				Latitude:  &latitude,
+				Longitude: &longitude,
+				Phone:     &phone,
+				WorkingHours: &WorkingHours{
+					Weekly:     []DayHours{{Day: Monday, Open: "09:00", Close: "21:00"}},
+					Exceptions: []HoursException{{Date: "2025-01-01", Closed: true}},
+				},
+				Status: &closed,
+			},
+		},
+		{name: "Empty update", status: PVZStatusActive, update: PVZUpdate{}, wantErr: apperrors.ErrEmptyPVZUpdate},
+		{name: "Latitude without longitude", status: PVZStatusActive, update: PVZUpdate{Latitude: &latitude}, wantErr: apperrors.ErrInvalidCoordinates},
+		{name: "Latitude out of range", status: PVZStatusActive, update: PVZUpdate{Latitude: &badLatitude, Longitude: &longitude}, wantErr: apperrors.ErrInvalidCoordinates},
+		{name: "Local phone format", status: PVZStatusActive, update: PVZUpdate{Phone: &badPhone}, wantErr: apperrors.ErrInvalidPhone},
+		{name: "Unknown status", status: PVZStatusActive, update: PVZUpdate{Status: &unknownStatus}, wantErr: apperrors.ErrInvalidPVZStatus},
+		{
+			name:    "Closing at opening time",
+			status:  PVZStatusActive,
+			update:  PVZUpdate{WorkingHours: &WorkingHours{Weekly: []DayHours{{Day: Friday, Open: "09:00", Close: "09:00"}}}},
+			wantErr: apperrors.ErrInvalidWorkingHours,
+		},
+		{
+			name:    "Unknown timezone",
+			status:  PVZStatusActive,
+			update:  PVZUpdate{WorkingHours: &WorkingHours{Weekly: []DayHours{{Day: Friday, Open: "09:00", Close: "21:00"}}, Timezone: "Moscow"}},
+			wantErr: apperrors.ErrInvalidWorkingHours,
+		},
+		{
+			name:   "Duplicate weekday",
+			status: PVZStatusActive,
+			update: PVZUpdate{WorkingHours: &WorkingHours{Weekly: []DayHours{
+				{Day: Friday, Open: "09:00", Close: "12:00"},
+				{Day: Friday, Open: "13:00", Close: "18:00"},
+			}}},
+			wantErr: apperrors.ErrInvalidWorkingHours,
+		},
+		{name: "Decommissioned PVZ", status: PVZStatusDecommissioned, update: PVZUpdate{Address: &address}, wantErr: apperrors.ErrPVZDecommissioned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvz := &PVZ{ID: uuid.New(), City: CityMoscow, Status: tt.status}
+
+			err := pvz.Apply(tt.update)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if pvz.Address != "ул. Тверская, 1" || *pvz.Latitude != latitude || pvz.Phone != phone {
+				t.Errorf("Apply() did not update profile: %+v", pvz)
+			}
+			if !pvz.IsClosed() {
+				t.Error("Apply() must update status")
+			}
+		})
+	}
+}
+
+func TestWorkingHours_IsOpenAt(t *testing.T) {
+	hours := &WorkingHours{
+		Weekly: []DayHours{
+			{Day: Monday, Open: "09:00", Close: "21:00"},
+			{Day: Friday, Open: "20:00", Close: "02:00"},
+			{Day: Saturday, Open: "10:00", Close: "16:00"},
+		},
+		Exceptions: []HoursException{
+			{Date: "2025-03-10", Closed: true},
+			{Date: "2025-03-15", Open: "10:00", Close: "13:00"},
+			{Date: "2025-03-21", Closed: true},
+		},
+	}
+	if err := hours.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, overnight hours must be valid", err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "Monday during hours", at: time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC), want: true},
+		{name: "Monday at closing time", at: time.Date(2025, 3, 3, 21, 0, 0, 0, time.UTC), want: false},
+		{name: "Day off", at: time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC), want: false},
+		{name: "Holiday on Monday", at: time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC), want: false},
+		{name: "Shortened Saturday", at: time.Date(2025, 3, 15, 14, 0, 0, 0, time.UTC), want: false},
+		{name: "Regular Saturday", at: time.Date(2025, 3, 8, 14, 0, 0, 0, time.UTC), want: true},
+		{name: "Friday night shift", at: time.Date(2025, 3, 7, 23, 0, 0, 0, time.UTC), want: true},
+		{name: "Friday night shift after midnight", at: time.Date(2025, 3, 8, 1, 30, 0, 0, time.UTC), want: true},
+		{name: "Friday night shift closed", at: time.Date(2025, 3, 8, 2, 0, 0, 0, time.UTC), want: false},
+		{name: "Night after holiday Friday", at: time.Date(2025, 3, 22, 1, 0, 0, 0, time.UTC), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hours.IsOpenAt(tt.at); got != tt.want {
+				t.Errorf("IsOpenAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPVZ_IsOpenAt(t *testing.T) {
+	hours := &WorkingHours{Weekly: []DayHours{{Day: Monday, Open: "09:00", Close: "21:00"}}}
+	yekaterinburg := &WorkingHours{Weekly: []DayHours{{Day: Monday, Open: "09:00", Close: "21:00"}}, Timezone: "Asia/Yekaterinburg"}
+	// 07:30 UTC - 10:30 по Москве и 12:30 в Екатеринбурге, 19:00 UTC - 22:00 по Москве и
+	// 00:00 вторника в Екатеринбурге
+	morning := time.Date(2025, 3, 3, 7, 30, 0, 0, time.UTC)
+	evening := time.Date(2025, 3, 3, 19, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		pvz  PVZ
+		at   time.Time
+		want bool
+	}{
+		{name: "Open by Moscow time", pvz: PVZ{Status: PVZStatusActive, WorkingHours: hours}, at: morning, want: true},
+		{name: "Closed by Moscow time", pvz: PVZ{Status: PVZStatusActive, WorkingHours: hours}, at: evening, want: false},
+		{name: "Closed by schedule timezone", pvz: PVZ{Status: PVZStatusActive, WorkingHours: yekaterinburg}, at: evening, want: false},
+		{name: "Open by schedule timezone", pvz: PVZ{Status: PVZStatusActive, WorkingHours: yekaterinburg}, at: morning, want: true},
+		{name: "Temporarily closed", pvz: PVZ{Status: PVZStatusTemporarilyClosed, WorkingHours: hours}, at: morning, want: false},
+		{name: "No working hours", pvz: PVZ{Status: PVZStatusActive}, at: morning, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pvz.IsOpenAt(tt.at); got != tt.want {
+				t.Errorf("IsOpenAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNearbyFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  NearbyFilter
+		wantErr error
+	}{
+		{name: "Valid", filter: NearbyFilter{Latitude: 55.75, Longitude: 37.62, RadiusMeters: 5000}},
+		{name: "Latitude out of range", filter: NearbyFilter{Latitude: 91, RadiusMeters: 5000}, wantErr: apperrors.ErrInvalidCoordinates},
+		{name: "Zero radius", filter: NearbyFilter{Latitude: 55.75, Longitude: 37.62}, wantErr: apperrors.ErrInvalidNearbyRadius},
+		{name: "Radius too large", filter: NearbyFilter{RadiusMeters: MaxNearbyRadiusMeters + 1}, wantErr: apperrors.ErrInvalidNearbyRadius},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPVZCapacity_Usage(t *testing.T) {
+	capacity := &PVZCapacity{Total: 10, ByType: map[string]int{ProductTypeShoes: 4, ProductTypeClothes: 0}}
+
+	usage := capacity.Usage(map[string]int{ProductTypeShoes: 3, ProductTypeElectronics: 5})
+
+	want := []CapacityUsage{
+		{Scope: CapacityScopeTotal, Occupied: 8, Capacity: 10},
+		{Scope: ProductTypeShoes, Occupied: 3, Capacity: 4},
+	}
+	if len(usage) != len(want) {
+		t.Fatalf("Usage() = %+v, want %+v", usage, want)
+	}
+	for i := range want {
+		if usage[i] != want[i] {
+			t.Errorf("Usage()[%d] = %+v, want %+v", i, usage[i], want[i])
+		}
+	}
+
+	utilization := PVZUtilization{Usage: usage}
+	if got := utilization.MaxUtilization(); got != 0.8 {
+		t.Errorf("MaxUtilization() = %v, want 0.8", got)
+	}
+}
+
+func TestPVZ_ApplyCapacity(t *testing.T) {
+	pvz := &PVZ{Status: PVZStatusActive}
+
+	if err := pvz.Apply(PVZUpdate{Capacity: &PVZCapacity{ByType: map[string]int{"мебель": 5}}}); !errors.Is(err, apperrors.ErrInvalidCapacity) {
+		t.Errorf("Apply() error = %v, want %v", err, apperrors.ErrInvalidCapacity)
+	}
+	if err := pvz.Apply(PVZUpdate{Capacity: &PVZCapacity{Total: -1}}); !errors.Is(err, apperrors.ErrInvalidCapacity) {
+		t.Errorf("Apply() error = %v, want %v", err, apperrors.ErrInvalidCapacity)
+	}
+
+	if err := pvz.Apply(PVZUpdate{Capacity: &PVZCapacity{Total: 100}}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if pvz.Capacity == nil || pvz.Capacity.Total != 100 {
+		t.Errorf("Apply() capacity = %+v, want total 100", pvz.Capacity)
+	}
+
+	if err := pvz.Apply(PVZUpdate{Capacity: &PVZCapacity{}}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if pvz.Capacity != nil {
+		t.Errorf("Apply() with empty capacity should remove limits, got %+v", pvz.Capacity)
+	}
+}
+
+func TestReceptionReportFilter_Validate(t *testing.T) {
+	from := time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  ReceptionReportFilter
+		wantErr error
+	}{
+		{"empty filter", ReceptionReportFilter{}, nil},
+		{"all groupings", ReceptionReportFilter{GroupBy: []string{ReportGroupPVZ, ReportGroupCity, ReportGroupProductType}, Period: ReportPeriodDay}, nil},
+		{"unknown grouping", ReceptionReportFilter{GroupBy: []string{"status"}}, apperrors.ErrInvalidReportGroup},
+		{"unknown period", ReceptionReportFilter{Period: "year"}, apperrors.ErrInvalidReportPeriod},
+		{"from after to", ReceptionReportFilter{From: &from, To: &to}, apperrors.ErrInvalidDateRange},
+		{"unknown city", ReceptionReportFilter{Cities: []string{"Тверь"}}, apperrors.ErrInvalidCity},
+		{"unknown product type", ReceptionReportFilter{ProductType: "мебель"}, apperrors.ErrInvalidProductType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReceptionReportRow_ProductsPerReception(t *testing.T) {
+	if got := (ReceptionReportRow{Receptions: 4, Products: 10}).ProductsPerReception(); got != 2.5 {
+		t.Errorf("ProductsPerReception() = %v, want 2.5", got)
+	}
+	if got := (ReceptionReportRow{}).ProductsPerReception(); got != 0 {
+		t.Errorf("ProductsPerReception() without receptions = %v, want 0", got)
+	}
+}
+
+func TestExportRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ExportRequest
+		wantErr error
+	}{
+		{"pvz csv", ExportRequest{Dataset: ExportDatasetPVZ, Format: ExportFormatCSV}, nil},
+		{"products xlsx with filter", ExportRequest{Dataset: ExportDatasetProducts, Format: ExportFormatXLSX, Filter: ExportFilter{ProductType: "обувь"}}, nil},
+		{"unknown dataset", ExportRequest{Dataset: "users", Format: ExportFormatCSV}, apperrors.ErrInvalidExportDataset},
+		{"unknown format", ExportRequest{Dataset: ExportDatasetReceptions, Format: "pdf"}, apperrors.ErrInvalidExportFormat},
+		{"invalid filter", ExportRequest{Dataset: ExportDatasetPVZ, Format: ExportFormatCSV, Filter: ExportFilter{Cities: []string{"Тверь"}}}, apperrors.ErrInvalidCity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExportRequest_FileName(t *testing.T) {
+	req := ExportRequest{Dataset: ExportDatasetReceptions, Format: ExportFormatXLSX}
+	at := time.Date(2025, 4, 10, 12, 30, 0, 0, time.UTC)
+
+	if got := req.FileName(at); got != "receptions-20250410-123000.xlsx" {
+		t.Errorf("FileName() = %q, want %q", got, "receptions-20250410-123000.xlsx")
+	}
+}
+
+func TestExportJob_Finish(t *testing.T) {
+	job := NewExportJob(ExportRequest{Dataset: ExportDatasetPVZ, Format: ExportFormatCSV}, uuid.New())
+	job.Finish(10, nil, time.Hour)
+
+	if job.Status != ExportJobStatusDone || job.RowCount != 10 || job.FinishedAt == nil || job.ExpiresAt == nil {
+		t.Fatalf("Finish() job = %+v, want done job with expiration", job)
+	}
+	if job.IsExpired(time.Now()) {
+		t.Error("IsExpired() = true right after finish")
+	}
+	if !job.IsExpired(job.ExpiresAt.Add(time.Second)) {
+		t.Error("IsExpired() = false after expiration")
+	}
+
+	failed := NewExportJob(ExportRequest{Dataset: ExportDatasetPVZ, Format: ExportFormatCSV}, uuid.New())
+	failed.Finish(3, errors.New("database error"), time.Hour)
+
+	if failed.Status != ExportJobStatusFailed || failed.Error != "database error" || failed.ExpiresAt != nil {
+		t.Errorf("Finish() with error job = %+v, want failed job without expiration", failed)
+	}
+	if failed.IsExpired(time.Now().Add(48 * time.Hour)) {
+		t.Error("IsExpired() = true for job without file")
+	}
+}
+
+func TestImportRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ImportRequest
+		wantErr error
+	}{
+		{"pvz csv", ImportRequest{Dataset: ImportDatasetPVZ, Format: ImportFormatCSV}, nil},
+		{"products ndjson dry run", ImportRequest{Dataset: ImportDatasetProducts, Format: ImportFormatNDJSON, DryRun: true}, nil},
+		{"unknown dataset", ImportRequest{Dataset: "users", Format: ImportFormatCSV}, apperrors.ErrInvalidImportDataset},
+		{"unknown format", ImportRequest{Dataset: ImportDatasetReceptions, Format: "xlsx"}, apperrors.ErrInvalidImportFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportRow_PVZ(t *testing.T) {
+	row := ImportRow{Line: 2, Fields: map[string]string{
+		"externalId":       "franchise-1",
+		"city":             "Казань",
+		"registrationDate": "2024-01-15T09:00:00Z",
+		"address":          "ул. Баумана, 5",
+		"latitude":         "55.79",
+		"longitude":        "49.12",
+		"workingHours":     `{"monday":{"open":"09:00","close":"21:00"}}`,
+	}}
+
+	pvz, err := row.PVZ()
+	if err != nil {
+		t.Fatalf("PVZ() error = %v", err)
+	}
+	if pvz.City != CityKazan || pvz.Address != "ул. Баумана, 5" || pvz.Latitude == nil || *pvz.Latitude != 55.79 {
+		t.Errorf("PVZ() = %+v, want imported Kazan PVZ", pvz)
+	}
+	if !pvz.RegistrationDate.Equal(time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("PVZ() registrationDate = %v, want 2024-01-15T09:00:00Z", pvz.RegistrationDate)
+	}
+
+	tests := []struct {
+		name       string
+		fields     map[string]string
+		wantColumn string
+		wantErr    error
+	}{
+		{"invalid city", map[string]string{"city": "Тверь"}, "city", apperrors.ErrInvalidCity},
+		{"invalid date", map[string]string{"city": "Москва", "registrationDate": "15.01.2024"}, "registrationDate", apperrors.ErrInvalidImportValue},
+		{"invalid latitude", map[string]string{"city": "Москва", "latitude": "north"}, "latitude", apperrors.ErrInvalidImportValue},
+		{"invalid working hours", map[string]string{"city": "Москва", "workingHours": "круглосуточно"}, "workingHours", apperrors.ErrInvalidWorkingHours},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportRow{Fields: tt.fields}.PVZ()
+
+			var fieldErr *ImportFieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Column != tt.wantColumn || !errors.Is(err, tt.wantErr) {
+				t.Errorf("PVZ() error = %v, want %s: %v", err, tt.wantColumn, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportRow_Reception(t *testing.T) {
+	pvzID := uuid.New()
+
+	reception, err := ImportRow{Fields: map[string]string{"dateTime": "2024-02-01T10:00:00Z", "closedAt": "2024-02-01T10:30:00Z"}}.Reception(pvzID)
+	if err != nil {
+		t.Fatalf("Reception() error = %v", err)
+	}
+	if reception.PVZID != pvzID || !reception.IsClosed() {
+		t.Errorf("Reception() = %+v, want closed reception of PVZ %s", reception, pvzID)
+	}
+	if reception.ClosedAt == nil || reception.ClosedAt.Sub(reception.DateTime) != 30*time.Minute {
+		t.Errorf("Reception() closedAt = %v, want 30 minutes after %v", reception.ClosedAt, reception.DateTime)
+	}
+
+	tests := []struct {
+		name    string
+		fields  map[string]string
+		wantErr error
+	}{
+		{"missing date", map[string]string{"closedAt": "2024-02-01T10:30:00Z"}, apperrors.ErrImportValueRequired},
+		{"missing closing time", map[string]string{"dateTime": "2024-02-01T10:00:00Z"}, apperrors.ErrImportValueRequired},
+		{"invalid closing time", map[string]string{"dateTime": "2024-02-01T10:00:00Z", "closedAt": "вчера"}, apperrors.ErrInvalidImportValue},
+		{"closed before opened", map[string]string{"dateTime": "2024-02-01T10:00:00Z", "closedAt": "2024-02-01T09:00:00Z"}, apperrors.ErrImportClosedBeforeOpen},
+		{"in progress", map[string]string{"dateTime": "2024-02-01T10:00:00Z", "status": ReceptionStatusInProgress}, apperrors.ErrImportReceptionNotClosed},
+		{"unknown status", map[string]string{"dateTime": "2024-02-01T10:00:00Z", "status": "open"}, apperrors.ErrInvalidReceptionStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (ImportRow{Fields: tt.fields}).Reception(pvzID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Reception() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportRow_Product(t *testing.T) {
+	receptionID := uuid.New()
+
+	product, err := ImportRow{Fields: map[string]string{"type": ProductTypeShoes, "dateTime": "2024-02-01T10:05:00Z"}}.Product(receptionID)
+	if err != nil {
+		t.Fatalf("Product() error = %v", err)
+	}
+	if product.ReceptionID != receptionID || product.Type != ProductTypeShoes {
+		t.Errorf("Product() = %+v, want shoes of reception %s", product, receptionID)
+	}
+
+	if _, err := (ImportRow{Fields: map[string]string{"type": "мебель", "dateTime": "2024-02-01T10:05:00Z"}}).Product(receptionID); !errors.Is(err, apperrors.ErrInvalidProductType) {
+		t.Errorf("Product() error = %v, want %v", err, apperrors.ErrInvalidProductType)
+	}
+	if _, err := (ImportRow{Fields: map[string]string{"type": ProductTypeShoes}}).Product(receptionID); !errors.Is(err, apperrors.ErrImportValueRequired) {
+		t.Errorf("Product() error = %v, want %v", err, apperrors.ErrImportValueRequired)
+	}
+}
+
+func TestImportReport_AddError(t *testing.T) {
+	var report ImportReport
+	report.AddError(3, "a", &ImportFieldError{Column: "city", Err: apperrors.ErrInvalidCity})
+	report.AddError(4, "", apperrors.ErrMalformedImportRow)
+
+	if report.Failed() != 2 {
+		t.Fatalf("Failed() = %d, want 2", report.Failed())
+	}
+	if got := report.Errors[0]; got.Column != "city" || got.Err != apperrors.ErrInvalidCity || got.ExternalID != "a" {
+		t.Errorf("AddError() field error = %+v, want column city", got)
+	}
+	if got := report.Errors[1]; got.Column != "" || got.Err != apperrors.ErrMalformedImportRow {
+		t.Errorf("AddError() row error = %+v, want row without column", got)
+	}
+}
+
+func TestEventFilter_Matches(t *testing.T) {
+	pvzID := uuid.New()
+	event := &PVZEvent{ID: 1, Type: EventTypeProductAdded, PVZID: pvzID, City: CityKazan}
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   bool
+	}{
+		{"empty filter", EventFilter{}, true},
+		{"same PVZ", EventFilter{PVZIDs: []uuid.UUID{uuid.New(), pvzID}}, true},
+		{"other PVZ", EventFilter{PVZIDs: []uuid.UUID{uuid.New()}}, false},
+		{"same city", EventFilter{Cities: []string{CityMoscow, CityKazan}}, true},
+		{"other city", EventFilter{Cities: []string{CityMoscow}}, false},
+		{"same PVZ other city", EventFilter{PVZIDs: []uuid.UUID{pvzID}, Cities: []string{CityMoscow}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(event); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventFilter_Validate(t *testing.T) {
+	if err := (EventFilter{Cities: []string{CityMoscow}}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (EventFilter{Cities: []string{"Тверь"}}).Validate(); !errors.Is(err, apperrors.ErrInvalidCity) {
+		t.Errorf("Validate() error = %v, want %v", err, apperrors.ErrInvalidCity)
+	}
+}
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"uuid", uuid.NewString(), false},
+		{"max length", strings.Repeat("k", MaxIdempotencyKeyLength), false},
+		{"empty", "", true},
+		{"too long", strings.Repeat("k", MaxIdempotencyKeyLength+1), true},
+		{"space", "retry 1", true},
+		{"non-ASCII", "ключ", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIdempotencyKey(tt.key)
+			if tt.wantErr != errors.Is(err, apperrors.ErrInvalidIdempotencyKey) {
+				t.Errorf("ValidateIdempotencyKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIdempotencyFingerprint(t *testing.T) {
+	base := IdempotencyFingerprint("POST", "/products", []byte(`{"type":"обувь"}`))
+
+	if got := IdempotencyFingerprint("POST", "/products", []byte(`{"type":"обувь"}`)); got != base {
+		t.Errorf("IdempotencyFingerprint() = %v, want %v for the same request", got, base)
+	}
+	if got := IdempotencyFingerprint("POST", "/products", []byte(`{"type":"одежда"}`)); got == base {
+		t.Error("IdempotencyFingerprint() must differ for another body")
+	}
+	if got := IdempotencyFingerprint("POST", "/receptions", []byte(`{"type":"обувь"}`)); got == base {
+		t.Error("IdempotencyFingerprint() must differ for another path")
+	}
+}
+
+func TestIsIdempotentResponse(t *testing.T) {
+	for status, want := range map[int]bool{201: true, 400: true, 409: true, 401: false, 403: false, 429: false, 500: false, 503: false} {
+		if got := IsIdempotentResponse(status); got != want {
+			t.Errorf("IsIdempotentResponse(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestParseEntityTag(t *testing.T) {
+	id := uuid.New()
+	tag := EntityTag{ID: id, Version: 3}
+
+	parsed, ok := ParseEntityTag(tag.String())
+	if !ok || parsed != tag {
+		t.Fatalf("ParseEntityTag(%s) = %v, %v, want %v", tag, parsed, ok, tag)
+	}
+
+	for _, value := range []string{"", "*", `W/` + tag.String(), id.String() + ".3", `"` + id.String() + `"`, `"` + id.String() + `.0"`, `"не-uuid.3"`} {
+		if _, ok := ParseEntityTag(value); ok {
+			t.Errorf("ParseEntityTag(%q) should fail", value)
+		}
+	}
+}
+
+func TestPrecondition_Matches(t *testing.T) {
+	id := uuid.New()
+	current := EntityTag{ID: id, Version: 2}
+	stale := EntityTag{ID: id, Version: 1}
+	other := EntityTag{ID: uuid.New(), Version: 2}
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"текущая версия", current.String(), true},
+		{"устаревшая версия", stale.String(), false},
+		{"версия другого ресурса", other.String(), false},
+		{"одна из версий списка", stale.String() + ", " + current.String(), true},
+		{"любая версия", "*", true},
+		{"слабый тег", "W/" + current.String(), false},
+		{"чужой тег", `"abc"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParsePrecondition(tt.header).Matches(id, current.Version); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesIfNoneMatch(t *testing.T) {
+	current := EntityTag{ID: uuid.New(), Version: 2}
+
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{current.String(), true},
+		{"W/" + current.String(), true},
+		{`"abc", ` + current.String(), true},
+		{"*", true},
+		{EntityTag{ID: current.ID, Version: 1}.String(), false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchesIfNoneMatch(tt.header, current); got != tt.want {
+			t.Errorf("MatchesIfNoneMatch(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ограничения поиска ближайших ПВЗ
+const (
+	DefaultNearbyRadiusMeters = 5000
+	MaxNearbyRadiusMeters     = 50000
+	DefaultNearbyLimit        = 10
+	MaxNearbyLimit            = 100
+)
+
+// NearbyFilter - параметры поиска ПВЗ вокруг точки. OpenAt оставляет только ПВЗ,
+// работающие в этот момент по расписанию, PVZIDs - только перечисленные ПВЗ.
+type NearbyFilter struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters float64
+	Limit        int
+	OpenAt       *time.Time
+	PVZIDs       []uuid.UUID
+}
+
+func (f NearbyFilter) Validate() error {
+	if !validCoordinates(f.Latitude, f.Longitude) {
+		return apperrors.ErrInvalidCoordinates
+	}
+	if f.RadiusMeters <= 0 || f.RadiusMeters > MaxNearbyRadiusMeters {
+		return apperrors.ErrInvalidNearbyRadius
+	}
+	return nil
+}
+
+// PVZWithDistance - ПВЗ и расстояние до него от точки поиска.
+type PVZWithDistance struct {
+	PVZ            *PVZ
+	DistanceMeters float64
+}
+
+// IsOpenAt сообщает, работает ли ПВЗ в момент t: он не закрыт и время попадает в
+// его расписание в часовом поясе расписания. ПВЗ без расписания считается закрытым.
+func (p *PVZ) IsOpenAt(t time.Time) bool {
+	if p.IsClosed() || p.WorkingHours == nil {
+		return false
+	}
+
+	location, err := p.WorkingHours.Location()
+	if err != nil {
+		return false
+	}
+	return p.WorkingHours.IsOpenAt(t.In(location))
+}
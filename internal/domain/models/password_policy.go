@@ -0,0 +1,97 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// PasswordPolicy задает требования к паролям. Скомпрометированные пароли
+// сравниваются без учета регистра.
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+	blocklist map[string]struct{}
+}
+
+var DefaultPasswordPolicy = NewPasswordPolicy(6, 128, nil)
+
+var currentPasswordPolicy atomic.Pointer[PasswordPolicy]
+
+func init() {
+	currentPasswordPolicy.Store(DefaultPasswordPolicy)
+}
+
+func NewPasswordPolicy(minLength, maxLength int, blocklist []string) *PasswordPolicy {
+	set := make(map[string]struct{}, len(blocklist))
+	for _, password := range blocklist {
+		set[strings.ToLower(password)] = struct{}{}
+	}
+
+	return &PasswordPolicy{
+		MinLength: minLength,
+		MaxLength: maxLength,
+		blocklist: set,
+	}
+}
+
+// SetPasswordPolicy заменяет политику, которую применяют NewUser и ChangePassword.
+// Вызывается при старте приложения.
+func SetPasswordPolicy(policy *PasswordPolicy) {
+	currentPasswordPolicy.Store(policy)
+}
+
+func CurrentPasswordPolicy() *PasswordPolicy {
+	return currentPasswordPolicy.Load()
+}
+
+func (p *PasswordPolicy) Validate(password string) error {
+	if password == "" {
+		return apperrors.ErrPasswordRequired
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%w: at least %d characters required", apperrors.ErrInvalidPassword, p.MinLength)
+	}
+
+	if length > p.MaxLength {
+		return fmt.Errorf("%w: at most %d characters allowed", apperrors.ErrPasswordTooLong, p.MaxLength)
+	}
+
+	if _, breached := p.blocklist[strings.ToLower(password)]; breached {
+		return apperrors.ErrPasswordBreached
+	}
+
+	return nil
+}
+
+// LoadPasswordBlocklist читает файл со списком скомпрометированных паролей,
+// по одному на строку. Пустые строки и строки, начинающиеся с #, пропускаются.
+func LoadPasswordBlocklist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open password blocklist: %w", err)
+	}
+	defer file.Close()
+
+	var passwords []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords = append(passwords, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read password blocklist: %w", err)
+	}
+
+	return passwords, nil
+}
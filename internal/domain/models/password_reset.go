@@ -0,0 +1,52 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/pkg/hasher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const passwordResetTokenBytes = 32
+
+type PasswordResetToken struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"userId"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// NewPasswordResetToken создает одноразовый токен сброса пароля. Возвращает
+// модель с хешем токена для хранения и сам токен для отправки пользователю.
+func NewPasswordResetToken(userID uuid.UUID, ttl time.Duration) (*PasswordResetToken, string, error) {
+	token, err := GenerateSecureToken(passwordResetTokenBytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	return &PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: hasher.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, token, nil
+}
+
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
+
+func GenerateSecureToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
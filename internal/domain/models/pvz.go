@@ -2,6 +2,10 @@ package models
 
 import (
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,10 +19,47 @@ const (
 
 var AllowedCities = []string{CityMoscow, CitySaintPete, CityKazan}
 
+// Статусы жизненного цикла ПВЗ
+const (
+	PVZStatusActive            = "active"
+	PVZStatusTemporarilyClosed = "temporarily_closed"
+	PVZStatusDecommissioned    = "decommissioned"
+)
+
+var AllowedPVZStatuses = []string{PVZStatusActive, PVZStatusTemporarilyClosed, PVZStatusDecommissioned}
+
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
 type PVZ struct {
-	ID               uuid.UUID `json:"id"`
-	RegistrationDate time.Time `json:"registrationDate"`
-	City             string    `json:"city"`
+	ID               uuid.UUID     `json:"id"`
+	RegistrationDate time.Time     `json:"registrationDate"`
+	City             string        `json:"city"`
+	Address          string        `json:"address,omitempty"`
+	Latitude         *float64      `json:"latitude,omitempty"`
+	Longitude        *float64      `json:"longitude,omitempty"`
+	Phone            string        `json:"phone,omitempty"`
+	WorkingHours     *WorkingHours `json:"workingHours,omitempty"`
+	Status           string        `json:"status"`
+	Capacity         *PVZCapacity  `json:"capacity,omitempty"`
+	// Version увеличивается при каждом изменении профиля (ETag).
+	Version int64 `json:"version"`
+}
+
+// PVZUpdate - изменяемые поля профиля ПВЗ, nil означает "не менять".
+type PVZUpdate struct {
+	Address      *string
+	Latitude     *float64
+	Longitude    *float64
+	Phone        *string
+	WorkingHours *WorkingHours
+	Status       *string
+	// Capacity без ограничений снимает вместимость ПВЗ.
+	Capacity *PVZCapacity
+}
+
+func (u PVZUpdate) IsEmpty() bool {
+	return u.Address == nil && u.Latitude == nil && u.Longitude == nil &&
+		u.Phone == nil && u.WorkingHours == nil && u.Status == nil && u.Capacity == nil
 }
 
 type PVZWithReceptions struct {
@@ -47,9 +88,89 @@ func NewPVZ(city string) (*PVZ, error) {
 		ID:               uuid.New(),
 		RegistrationDate: time.Now(),
 		City:             city,
+		Status:           PVZStatusActive,
+		Version:          InitialVersion,
 	}, nil
 }
 
+// IsClosed сообщает, что ПВЗ временно закрыт или выведен из эксплуатации и не
+// принимает приёмки. ПВЗ без статуса считается работающим.
+func (p *PVZ) IsClosed() bool {
+	return p.Status == PVZStatusTemporarilyClosed || p.Status == PVZStatusDecommissioned
+}
+
+// Apply проверяет изменения профиля и применяет их. Выведенный из эксплуатации
+// ПВЗ изменить нельзя; координаты задаются только парой.
+func (p *PVZ) Apply(update PVZUpdate) error {
+	if update.IsEmpty() {
+		return apperrors.ErrEmptyPVZUpdate
+	}
+	if p.Status == PVZStatusDecommissioned {
+		return apperrors.ErrPVZDecommissioned
+	}
+
+	if update.Status != nil && !IsValidPVZStatus(*update.Status) {
+		return apperrors.ErrInvalidPVZStatus
+	}
+	if (update.Latitude == nil) != (update.Longitude == nil) {
+		return apperrors.ErrInvalidCoordinates
+	}
+	if update.Latitude != nil && !validCoordinates(*update.Latitude, *update.Longitude) {
+		return apperrors.ErrInvalidCoordinates
+	}
+	if update.Phone != nil && *update.Phone != "" && !phonePattern.MatchString(*update.Phone) {
+		return apperrors.ErrInvalidPhone
+	}
+	if update.WorkingHours != nil {
+		if err := update.WorkingHours.Validate(); err != nil {
+			return err
+		}
+	}
+	if update.Capacity != nil {
+		if err := update.Capacity.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if update.Address != nil {
+		p.Address = strings.TrimSpace(*update.Address)
+	}
+	if update.Latitude != nil {
+		p.Latitude = update.Latitude
+		p.Longitude = update.Longitude
+	}
+	if update.Phone != nil {
+		p.Phone = *update.Phone
+	}
+	if update.WorkingHours != nil {
+		p.WorkingHours = update.WorkingHours
+	}
+	if update.Status != nil {
+		p.Status = *update.Status
+	}
+	if update.Capacity != nil {
+		p.Capacity = update.Capacity
+		if update.Capacity.IsEmpty() {
+			p.Capacity = nil
+		}
+	}
+
+	return nil
+}
+
+func IsValidPVZStatus(status string) bool {
+	for _, allowed := range AllowedPVZStatuses {
+		if status == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func validCoordinates(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
+
 func IsValidCity(city string) bool {
 	for _, allowedCity := range AllowedCities {
 		if city == allowedCity {
@@ -59,9 +180,154 @@ func IsValidCity(city string) bool {
 	return false
 }
 
+// Поля сортировки списка ПВЗ
+const (
+	PVZSortRegistrationDate = "registrationDate"
+	PVZSortCity             = "city"
+	PVZSortLastReception    = "lastReception"
+)
+
 type PVZFilter struct {
+	// StartDate и EndDate ограничивают даты приёмок: в выборку попадают ПВЗ, у которых
+	// есть приёмка в диапазоне. Любую из границ можно не указывать.
 	StartDate *time.Time
 	EndDate   *time.Time
-	Page      int
-	Limit     int
+	// Cities - города ПВЗ, пустой список означает все города.
+	Cities         []string
+	RegisteredFrom *time.Time
+	RegisteredTo   *time.Time
+	// ReceptionStatus и ProductType оставляют ПВЗ, у которых есть приёмка с таким
+	// статусом и товаром такого типа, вместе с условием на даты приёмки.
+	ReceptionStatus  string
+	ProductType      string
+	HasOpenReception *bool
+	SortBy           string
+	SortDesc         bool
+	Page             int
+	Limit            int
+	// ProductsLimit - сколько последних товаров каждой приёмки вернуть вместе с ПВЗ.
+	ProductsLimit int
+	// ProductCountsOnly - вернуть только количество товаров по типам, без самих товаров.
+	ProductCountsOnly bool
+	// Cursor включает keyset-пагинацию, Page при этом не учитывается.
+	Cursor *PVZCursor
+	// WithTotal - считать ли общее количество ПВЗ, подходящих под фильтр.
+	WithTotal bool
+	// PVZIDs оставляет только эти ПВЗ, пустой список означает все ПВЗ. Так
+	// ограничиваются API-ключи с доступом к отдельным ПВЗ.
+	PVZIDs []uuid.UUID
+}
+
+// HasReceptionConditions сообщает, ограничивает ли фильтр приёмки ПВЗ.
+func (f PVZFilter) HasReceptionConditions() bool {
+	return f.StartDate != nil || f.EndDate != nil || f.ReceptionStatus != "" || f.ProductType != ""
+}
+
+func (f PVZFilter) Validate() error {
+	for _, city := range f.Cities {
+		if !IsValidCity(city) {
+			return apperrors.ErrInvalidCity
+		}
+	}
+
+	if f.ReceptionStatus != "" && f.ReceptionStatus != ReceptionStatusInProgress && f.ReceptionStatus != ReceptionStatusClosed {
+		return apperrors.ErrInvalidReceptionStatus
+	}
+
+	if f.ProductType != "" && !IsValidProductType(f.ProductType) {
+		return apperrors.ErrInvalidProductType
+	}
+
+	switch f.SortBy {
+	case "", PVZSortRegistrationDate, PVZSortCity, PVZSortLastReception:
+	default:
+		return apperrors.ErrInvalidSort
+	}
+
+	if f.Cursor != nil && (f.Cursor.SortBy != f.SortField() || f.Cursor.SortDesc != f.SortDesc) {
+		return apperrors.ErrInvalidCursor
+	}
+
+	return nil
+}
+
+// SortField возвращает поле сортировки с учетом значения по умолчанию.
+func (f PVZFilter) SortField() string {
+	if f.SortBy == "" {
+		return PVZSortRegistrationDate
+	}
+	return f.SortBy
+}
+
+// PVZCursor - позиция в списке ПВЗ, упорядоченном по ключу сортировки и id. Key -
+// значение ключа сортировки у записи; курсор действителен только для той же сортировки.
+// Backward означает, что нужна страница перед этой позицией.
+type PVZCursor struct {
+	SortBy   string
+	SortDesc bool
+	Key      string
+	ID       uuid.UUID
+	Backward bool
+}
+
+type pvzCursorPayload struct {
+	SortBy   string    `json:"s"`
+	SortDesc bool      `json:"o,omitempty"`
+	Key      string    `json:"k"`
+	ID       uuid.UUID `json:"i"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+// Encode возвращает непрозрачное для клиента представление курсора.
+func (c PVZCursor) Encode() string {
+	payload, _ := json.Marshal(pvzCursorPayload{
+		SortBy:   c.SortBy,
+		SortDesc: c.SortDesc,
+		Key:      c.Key,
+		ID:       c.ID,
+		Backward: c.Backward,
+	})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func DecodePVZCursor(s string) (*PVZCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, apperrors.ErrInvalidCursor
+	}
+
+	var payload pvzCursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.ID == uuid.Nil || !validCursorKey(payload.SortBy, payload.Key) {
+		return nil, apperrors.ErrInvalidCursor
+	}
+
+	return &PVZCursor{
+		SortBy:   payload.SortBy,
+		SortDesc: payload.SortDesc,
+		Key:      payload.Key,
+		ID:       payload.ID,
+		Backward: payload.Backward,
+	}, nil
+}
+
+// validCursorKey проверяет, что ключ курсора подходит по типу к полю сортировки, чтобы
+// измененный клиентом курсор не доходил до запроса к БД.
+func validCursorKey(sortBy, key string) bool {
+	switch sortBy {
+	case PVZSortRegistrationDate, PVZSortLastReception:
+		_, err := time.Parse(time.RFC3339Nano, key)
+		return err == nil
+	case PVZSortCity:
+		return IsValidCity(key)
+	default:
+		return false
+	}
+}
+
+// PVZPage описывает полученную страницу списка ПВЗ. Total равен nil, если подсчет
+// не запрашивался; пустой курсор означает, что в этом направлении страниц нет.
+type PVZPage struct {
+	Total      *int
+	NextCursor string
+	PrevCursor string
 }
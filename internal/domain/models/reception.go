@@ -18,6 +18,21 @@ type Reception struct {
 	PVZID    uuid.UUID `json:"pvzId"`
 	Status   string    `json:"status"`
 	Products []Product `json:"products,omitempty"`
+	// ProductCounts - количество товаров по типам. Заполняется в списках, где Products
+	// содержит только последние товары приёмки или не загружается.
+	ProductCounts map[string]int `json:"productCounts,omitempty"`
+	// Version увеличивается при закрытии приёмки и изменении ее товаров (ETag).
+	Version int64 `json:"version"`
+	// ClosedAt задается при создании только у загружаемых закрытых приёмок. Остальным
+	// время закрытия ставит CloseReception.
+	ClosedAt *time.Time `json:"closedAt,omitempty"`
+}
+
+// ReceptionFilter - параметры списка приёмок одного ПВЗ.
+type ReceptionFilter struct {
+	Status string
+	Page   int
+	Limit  int
 }
 
 func NewReception(pvzID uuid.UUID) (*Reception, error) {
@@ -31,6 +46,7 @@ func NewReception(pvzID uuid.UUID) (*Reception, error) {
 		PVZID:    pvzID,
 		Status:   ReceptionStatusInProgress,
 		Products: []Product{},
+		Version:  InitialVersion,
 	}, nil
 }
 
@@ -45,3 +61,12 @@ func (r *Reception) IsClosed() bool {
 func (r *Reception) ProductCount() int {
 	return len(r.Products)
 }
+
+// ProductsTotal возвращает общее количество товаров приёмки по ProductCounts.
+func (r *Reception) ProductsTotal() int {
+	total := 0
+	for _, count := range r.ProductCounts {
+		total += count
+	}
+	return total
+}
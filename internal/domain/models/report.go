@@ -0,0 +1,96 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Группировки отчета по приёмкам
+const (
+	ReportGroupPVZ         = "pvz"
+	ReportGroupCity        = "city"
+	ReportGroupProductType = "productType"
+)
+
+// Периоды отчета по приёмкам
+const (
+	ReportPeriodDay   = "day"
+	ReportPeriodWeek  = "week"
+	ReportPeriodMonth = "month"
+)
+
+// ReceptionReportFilter - параметры отчета по приёмкам. GroupBy и Period задают
+// разрезы отчета, остальные поля ограничивают приёмки и товары.
+type ReceptionReportFilter struct {
+	GroupBy     []string
+	Period      string
+	From        *time.Time
+	To          *time.Time
+	Cities      []string
+	PVZID       *uuid.UUID
+	ProductType string
+}
+
+func (f ReceptionReportFilter) Validate() error {
+	for _, group := range f.GroupBy {
+		switch group {
+		case ReportGroupPVZ, ReportGroupCity, ReportGroupProductType:
+		default:
+			return apperrors.ErrInvalidReportGroup
+		}
+	}
+
+	switch f.Period {
+	case "", ReportPeriodDay, ReportPeriodWeek, ReportPeriodMonth:
+	default:
+		return apperrors.ErrInvalidReportPeriod
+	}
+
+	if f.From != nil && f.To != nil && f.From.After(*f.To) {
+		return apperrors.ErrInvalidDateRange
+	}
+
+	for _, city := range f.Cities {
+		if !IsValidCity(city) {
+			return apperrors.ErrInvalidCity
+		}
+	}
+
+	if f.ProductType != "" && !IsValidProductType(f.ProductType) {
+		return apperrors.ErrInvalidProductType
+	}
+
+	return nil
+}
+
+// HasGroup сообщает, группируется ли отчет по group.
+func (f ReceptionReportFilter) HasGroup(group string) bool {
+	for _, g := range f.GroupBy {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// ReceptionReportRow - строка отчета. Заполнены только поля выбранных группировок.
+// AvgDuration считается по закрытым приёмкам и равен nil, если таких нет.
+type ReceptionReportRow struct {
+	PeriodStart *time.Time
+	PVZID       *uuid.UUID
+	City        string
+	ProductType string
+	Receptions  int
+	Products    int
+	AvgDuration *time.Duration
+}
+
+// ProductsPerReception возвращает среднее количество товаров в приёмке.
+func (r ReceptionReportRow) ProductsPerReception() float64 {
+	if r.Receptions == 0 {
+		return 0
+	}
+	return float64(r.Products) / float64(r.Receptions)
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/hasher"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Области действия API-ключей
+const (
+	ScopePVZRead         = "pvz:read"
+	ScopeReceptionsWrite = "receptions:write"
+	ScopeProductsWrite   = "products:write"
+)
+
+const (
+	apiKeyPrefix       = "pvz_"
+	apiKeyBytes        = 32
+	apiKeyPrefixLength = 12
+)
+
+var validScopes = map[string]struct{}{
+	ScopePVZRead:         {},
+	ScopeReceptionsWrite: {},
+	ScopeProductsWrite:   {},
+}
+
+// ServiceAccount - учетная запись внешней системы (например, сортировочного центра),
+// которая обращается к API по ключам, а не по паролю.
+type ServiceAccount struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedBy   uuid.UUID `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// APIKey хранится только в виде хеша. Prefix - начало ключа, по которому
+// администратор может узнать ключ в списке. Пустой PVZIDs означает доступ ко всем ПВЗ.
+type APIKey struct {
+	ID               uuid.UUID   `json:"id"`
+	ServiceAccountID uuid.UUID   `json:"serviceAccountId"`
+	Prefix           string      `json:"prefix"`
+	KeyHash          string      `json:"-"`
+	Scopes           []string    `json:"scopes"`
+	PVZIDs           []uuid.UUID `json:"pvzIds"`
+	ExpiresAt        *time.Time  `json:"expiresAt,omitempty"`
+	LastUsedAt       *time.Time  `json:"lastUsedAt,omitempty"`
+	RevokedAt        *time.Time  `json:"revokedAt,omitempty"`
+	CreatedAt        time.Time   `json:"createdAt"`
+}
+
+func NewServiceAccount(name, description string, createdBy uuid.UUID) (*ServiceAccount, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apperrors.ErrServiceAccountNameRequired
+	}
+
+	return &ServiceAccount{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: description,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// NewAPIKey создает ключ сервисной учетной записи. Возвращает модель с хешем ключа
+// для хранения и сам ключ, который показывается администратору один раз.
+func NewAPIKey(serviceAccountID uuid.UUID, scopes []string, pvzIDs []uuid.UUID, expiresAt *time.Time) (*APIKey, string, error) {
+	if len(scopes) == 0 {
+		return nil, "", apperrors.ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !IsValidScope(scope) {
+			return nil, "", apperrors.ErrInvalidScope
+		}
+	}
+
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", apperrors.ErrInvalidKeyExpiry
+	}
+
+	secret, err := GenerateSecureToken(apiKeyBytes)
+	if err != nil {
+		return nil, "", err
+	}
+	key := apiKeyPrefix + secret
+
+	if pvzIDs == nil {
+		pvzIDs = []uuid.UUID{}
+	}
+
+	return &APIKey{
+		ID:               uuid.New(),
+		ServiceAccountID: serviceAccountID,
+		Prefix:           key[:apiKeyPrefixLength],
+		KeyHash:          hasher.HashToken(key),
+		Scopes:           scopes,
+		PVZIDs:           pvzIDs,
+		ExpiresAt:        expiresAt,
+		CreatedAt:        now,
+	}, key, nil
+}
+
+func IsValidScope(scope string) bool {
+	_, ok := validScopes[scope]
+	return ok
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKey) CanAccessPVZ(pvzID uuid.UUID) bool {
+	if len(k.PVZIDs) == 0 {
+		return true
+	}
+	for _, id := range k.PVZIDs {
+		if id == pvzID {
+			return true
+		}
+	}
+	return false
+}
@@ -13,15 +13,30 @@ import (
 const (
 	RoleEmployee  = "employee"
 	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+	// RoleServiceAccount - роль запросов, аутентифицированных API-ключом
+	RoleServiceAccount = "service_account"
 )
 
 type User struct {
-	ID           uuid.UUID `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	Role         string    `json:"role"`
-	CreatedAt    time.Time `json:"created_at,omitempty"`
-	UpdatedAt    time.Time `json:"updated_at,omitempty"`
+	ID                uuid.UUID  `json:"id"`
+	Email             string     `json:"email"`
+	PasswordHash      string     `json:"-"`
+	Role              string     `json:"role"`
+	PasswordChangedAt *time.Time `json:"-"`
+	MFAEnabled        bool       `json:"mfa_enabled"`
+	Language          string     `json:"language,omitempty"` // пусто - язык по Accept-Language
+	CreatedAt         time.Time  `json:"created_at,omitempty"`
+	UpdatedAt         time.Time  `json:"updated_at,omitempty"`
+}
+
+// LoginResult содержит либо итоговый токен, либо токен второго шага входа,
+// если у пользователя включена двухфакторная аутентификация.
+type LoginResult struct {
+	Token              string
+	MFARequired        bool
+	ChallengeToken     string
+	ChallengeExpiresAt time.Time
 }
 
 type UserCredentials struct {
@@ -39,15 +54,11 @@ func NewUser(email, password, role string) (*User, error) {
 		return nil, apperrors.ErrInvalidEmail
 	}
 
-	if password == "" {
-		return nil, apperrors.ErrPasswordRequired
+	if err := ValidatePassword(password); err != nil {
+		return nil, err
 	}
 
-	if len(password) < 6 {
-		return nil, apperrors.ErrInvalidPassword
-	}
-
-	if role != RoleEmployee && role != RoleModerator {
+	if role != RoleEmployee && role != RoleModerator && role != RoleAdmin {
 		return nil, apperrors.ErrInvalidRole
 	}
 
@@ -67,6 +78,30 @@ func NewUser(email, password, role string) (*User, error) {
 	}, nil
 }
 
+func ValidatePassword(password string) error {
+	return CurrentPasswordPolicy().Validate(password)
+}
+
+// ChangePassword проверяет и хеширует новый пароль. PasswordChangedAt используется
+// для отзыва токенов, выданных до смены пароля.
+func (u *User) ChangePassword(password string) error {
+	if err := ValidatePassword(password); err != nil {
+		return err
+	}
+
+	passwordHash, err := hasher.Hash(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := time.Now()
+	u.PasswordHash = passwordHash
+	u.PasswordChangedAt = &now
+	u.UpdatedAt = now
+
+	return nil
+}
+
 func (u *User) IsEmployee() bool {
 	return u.Role == RoleEmployee
 }
@@ -74,3 +109,8 @@ func (u *User) IsEmployee() bool {
 func (u *User) IsModerator() bool {
 	return u.Role == RoleModerator
 }
+
+// IsPrivilegedRole сообщает, относится ли роль к тем, для которых политика может требовать 2FA.
+func IsPrivilegedRole(role string) bool {
+	return role == RoleModerator || role == RoleAdmin
+}
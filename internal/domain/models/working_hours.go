@@ -0,0 +1,152 @@
+package models
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"time"
+	// Часовые пояса расписаний проверяются и там, где в системе нет базы tzdata
+	_ "time/tzdata"
+)
+
+// Дни недели в расписании ПВЗ
+const (
+	Monday    = "monday"
+	Tuesday   = "tuesday"
+	Wednesday = "wednesday"
+	Thursday  = "thursday"
+	Friday    = "friday"
+	Saturday  = "saturday"
+	Sunday    = "sunday"
+)
+
+var weekdays = map[string]time.Weekday{
+	Monday:    time.Monday,
+	Tuesday:   time.Tuesday,
+	Wednesday: time.Wednesday,
+	Thursday:  time.Thursday,
+	Friday:    time.Friday,
+	Saturday:  time.Saturday,
+	Sunday:    time.Sunday,
+}
+
+const (
+	hoursLayout = "15:04"
+	dateLayout  = "2006-01-02"
+)
+
+// DefaultTimezone - часовой пояс расписания, в котором он не указан.
+const DefaultTimezone = "Europe/Moscow"
+
+// WorkingHours - недельное расписание ПВЗ с исключениями на отдельные даты
+// (праздники, сокращенные дни) в часовом поясе Timezone. Дни, которых нет в Weekly,
+// выходные.
+type WorkingHours struct {
+	Weekly     []DayHours       `json:"weekly"`
+	Exceptions []HoursException `json:"exceptions,omitempty"`
+	Timezone   string           `json:"timezone,omitempty"`
+}
+
+// DayHours - часы работы в день недели, время в формате ЧЧ:ММ. Закрытие раньше
+// открытия означает работу через полночь: ПВЗ закрывается на следующий день.
+type DayHours struct {
+	Day   string `json:"day"`
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// HoursException - часы работы в конкретную дату, заменяют недельное расписание.
+type HoursException struct {
+	Date   string `json:"date"`
+	Closed bool   `json:"closed"`
+	Open   string `json:"open,omitempty"`
+	Close  string `json:"close,omitempty"`
+}
+
+func (w *WorkingHours) Validate() error {
+	if _, err := w.Location(); err != nil {
+		return apperrors.ErrInvalidWorkingHours
+	}
+
+	days := make(map[string]bool, len(w.Weekly))
+	for _, day := range w.Weekly {
+		if _, ok := weekdays[day.Day]; !ok || days[day.Day] {
+			return apperrors.ErrInvalidWorkingHours
+		}
+		days[day.Day] = true
+
+		if !validHoursRange(day.Open, day.Close) {
+			return apperrors.ErrInvalidWorkingHours
+		}
+	}
+
+	dates := make(map[string]bool, len(w.Exceptions))
+	for _, exception := range w.Exceptions {
+		if _, err := time.Parse(dateLayout, exception.Date); err != nil || dates[exception.Date] {
+			return apperrors.ErrInvalidWorkingHours
+		}
+		dates[exception.Date] = true
+
+		if exception.Closed {
+			continue
+		}
+		if !validHoursRange(exception.Open, exception.Close) {
+			return apperrors.ErrInvalidWorkingHours
+		}
+	}
+
+	return nil
+}
+
+// Location возвращает часовой пояс расписания.
+func (w *WorkingHours) Location() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.LoadLocation(DefaultTimezone)
+	}
+	return time.LoadLocation(w.Timezone)
+}
+
+// IsOpenAt сообщает, работает ли ПВЗ в момент t по его расписанию. Время t
+// сравнивается с расписанием в своем часовом поясе. Ночная смена предыдущего дня
+// продолжается после полуночи до ее закрытия.
+func (w *WorkingHours) IsOpenAt(t time.Time) bool {
+	clock := t.Format(hoursLayout)
+
+	if open, close, ok := w.hoursOn(t); ok && open <= clock && (clock < close || close < open) {
+		return true
+	}
+
+	open, close, ok := w.hoursOn(t.AddDate(0, 0, -1))
+	return ok && close < open && clock < close
+}
+
+// hoursOn возвращает часы работы в дату t: исключение на эту дату заменяет недельное
+// расписание. ok == false - выходной.
+func (w *WorkingHours) hoursOn(t time.Time) (open, close string, ok bool) {
+	date := t.Format(dateLayout)
+	for _, exception := range w.Exceptions {
+		if exception.Date == date {
+			return exception.Open, exception.Close, !exception.Closed
+		}
+	}
+
+	for _, day := range w.Weekly {
+		if weekdays[day.Day] == t.Weekday() {
+			return day.Open, day.Close, true
+		}
+	}
+
+	return "", "", false
+}
+
+// validHoursRange проверяет формат времени. Открытие и закрытие в одну минуту не
+// задают ни дневную, ни ночную смену.
+func validHoursRange(open, close string) bool {
+	openAt, err := time.Parse(hoursLayout, open)
+	if err != nil {
+		return false
+	}
+	closeAt, err := time.Parse(hoursLayout, close)
+	if err != nil {
+		return false
+	}
+	return !openAt.Equal(closeAt)
+}
@@ -0,0 +1,196 @@
+package i18n
+
+// en содержит сообщения на английском. Ключи - коды errcode и ключи сообщений полей.
+var en = map[string]string{
+	"internal_error": "Internal server error. Please try again later.",
+
+	"invalid_credentials":    "Invalid email or password. Please check your credentials.",
+	"invalid_reset_token":    "Password reset code is invalid, expired or has already been used.",
+	"token_revoked":          "Token has been revoked. Please log in again.",
+	"dummy_login_disabled":   "Dummy login is disabled in this environment.",
+	"dummy_token_rejected":   "Test tokens are not accepted in this environment. Please log in with your credentials.",
+	"authorization_required": "Authorization header is required.",
+	"invalid_authorization":  "Invalid Authorization header. Use the format: Bearer <token>.",
+	"invalid_token":          "Token is invalid or has expired. Please log in again.",
+	"unauthenticated":        "Authentication is required.",
+
+	// Access errors
+	"insufficient_permissions": "You do not have permission to perform this operation.",
+
+	// Two-factor authentication errors
+	"invalid_mfa_code":         "Invalid two-factor authentication code.",
+	"invalid_mfa_challenge":    "Two-factor authentication session is invalid or has expired. Please log in again.",
+	"mfa_already_enabled":      "Two-factor authentication is already enabled.",
+	"mfa_not_enabled":          "Two-factor authentication is not enabled.",
+	"mfa_enrollment_not_found": "Start two-factor authentication enrollment first.",
+	"mfa_required":             "Two-factor authentication is required for your role. Enable it and log in again.",
+	"mfa_cannot_be_disabled":   "Two-factor authentication is mandatory for your role and cannot be disabled.",
+
+	// Service account errors
+	"invalid_api_key":      "API key is invalid, expired or has been revoked.",
+	"insufficient_scope":   "API key does not have the scope required for this operation.",
+	"pvz_access_forbidden": "API key is not allowed to access this pickup point.",
+
+	// PVZ business errors
+	"pvz_decommissioned": "Decommissioned pickup point cannot be changed.",
+	"pvz_closed":         "Pickup point is closed and does not accept receptions.",
+	"capacity_exceeded":  "Pickup point capacity is exhausted. The product cannot be accepted.",
+
+	// Reception business errors
+	"reception_already_closed":     "This reception is already closed.",
+	"reception_cannot_be_modified": "Closed reception cannot be modified.",
+	"active_reception_exists":      "Cannot create a new reception while the previous one is not closed.",
+	"no_active_reception":          "No active reception for this pickup point.",
+
+	// Product business errors
+	"no_products_to_delete": "No products to delete in the current reception.",
+
+	// Export business errors
+	"export_job_not_ready": "Export is not finished yet. Check the job status later.",
+	"export_job_failed":    "Export failed. Start a new export.",
+	"export_job_expired":   "Export file has expired. Start a new export.",
+
+	// Import business errors
+	"import_reference_not_found": "Referenced pickup point or reception has not been imported yet.",
+
+	// Idempotency business errors
+	"idempotency_key_in_use": "A request with this Idempotency-Key is still in progress. Retry later.",
+	"idempotency_key_reused": "This Idempotency-Key has already been used with a different request.",
+
+	// Optimistic concurrency errors
+	"version_mismatch": "The resource has been modified since you read it. Reload it and retry.",
+
+	// Request validation errors
+	"invalid_request_body":     "Invalid request body.",
+	"invalid_query_parameters": "Invalid query parameters.",
+	"request_body_too_large":   "Request body is too large.",
+
+	// User validation errors
+	"email_required":    "Email is required for registration.",
+	"invalid_email":     "Invalid email format specified.",
+	"password_required": "Password is required for registration.",
+	"invalid_password":  "Password is too short.",
+	"password_too_long": "Password is too long.",
+	"password_breached": "This password has appeared in a data breach. Please choose a different one.",
+	"invalid_role":      "Invalid role specified. Available roles: employee, moderator.",
+	"invalid_language":  "Unsupported language. Available languages: ru, en.",
+
+	// Service account validation errors
+	"service_account_name_required": "Service account name is required.",
+	"invalid_scope":                 "Invalid API key scope specified. Available scopes: pvz:read, receptions:write, products:write.",
+	"invalid_key_expiry":            "API key expiry must be in the future.",
+	"invalid_service_account_id":    "Invalid service account ID specified.",
+	"invalid_api_key_id":            "Invalid API key ID specified.",
+
+	// PVZ validation errors
+	"city_required":         "City is required to create a pickup point.",
+	"invalid_city":          "Pickup points can only be created in the following cities: Москва, Санкт-Петербург, Казань.",
+	"invalid_pvz_id":        "Invalid pickup point ID specified.",
+	"invalid_cursor":        "Invalid pagination cursor. Start again from the first page.",
+	"invalid_sort":          "Invalid sort field specified. Available fields: registrationDate, city, lastReception.",
+	"invalid_pvz_status":    "Invalid pickup point status specified. Available statuses: active, temporarily_closed, decommissioned.",
+	"invalid_coordinates":   "Latitude and longitude must be specified together: latitude from -90 to 90, longitude from -180 to 180.",
+	"invalid_phone":         "Phone must be in international format, for example +74951234567.",
+	"invalid_working_hours": "Invalid working hours. Use unique weekdays, HH:MM times with different opening and closing, an IANA time zone and YYYY-MM-DD exception dates.",
+	"empty_pvz_update":      "No pickup point fields to update.",
+	"invalid_capacity":      "Capacity limits must be non-negative and use known product types.",
+	"invalid_threshold":     "Utilization threshold must be greater than 0.",
+	"invalid_nearby_radius": "Search radius must be greater than 0 and not exceed 50000 meters.",
+	"nearby_point_required": "Query parameters lat and lon are required.",
+
+	// Reception validation errors
+	"invalid_reception_id":     "Invalid reception ID specified.",
+	"invalid_reception_status": "Invalid reception status specified. Available statuses: in_progress, close.",
+
+	// Report validation errors
+	"invalid_report_group":  "Invalid report grouping specified. Available groupings: pvz, city, productType.",
+	"invalid_report_period": "Invalid report period specified. Available periods: day, week, month.",
+	"invalid_date_range":    "Start date must not be later than end date.",
+
+	// Product validation errors
+	"product_type_required": "Product type is required.",
+	"invalid_product_type":  "Invalid product type specified. Available types: электроника, одежда, обувь.",
+	"invalid_product_id":    "Invalid product ID specified.",
+
+	// Audit validation errors
+	"invalid_actor_id": "Invalid actor ID specified.",
+
+	// Export validation errors
+	"invalid_export_dataset": "Invalid export dataset specified. Available datasets: pvz, receptions, products.",
+	"invalid_export_format":  "Invalid export format specified. Available formats: csv, xlsx.",
+	"invalid_export_job_id":  "Invalid export job ID specified.",
+
+	// Import validation errors
+	"invalid_import_dataset":      "Invalid import dataset specified. Available datasets: pvz, receptions, products.",
+	"invalid_import_format":       "Invalid import format specified. Available formats: csv, ndjson.",
+	"malformed_import_row":        "Row cannot be parsed. Check the number of columns and quoting.",
+	"import_external_id_required": "External ID is required.",
+	"duplicate_external_id":       "External ID occurs more than once in the file.",
+	"import_value_required":       "Value is required.",
+	"invalid_import_value":        "Invalid value. Use numbers with a dot and RFC 3339 dates, for example 2025-04-10T12:00:00Z.",
+	"import_reception_not_closed": "Only closed receptions can be imported.",
+	"import_closed_before_open":   "A reception cannot be closed before it was opened.",
+	"import_file_too_large":       "Import file is too large.",
+
+	// Event stream validation errors
+	"invalid_last_event_id": "Invalid Last-Event-ID specified. Use the id of the last received event.",
+	"invalid_resume_token":  "Invalid resume token. Start watching from the beginning.",
+
+	// Idempotency validation errors
+	"invalid_idempotency_key": "Idempotency-Key must be 1 to 255 visible ASCII characters, for example a UUID.",
+
+	// Precondition validation errors
+	"precondition_required": "If-Match header is required. Use the ETag of the resource you are changing.",
+
+	// User storage errors
+	"user_not_found":      "User not found.",
+	"user_already_exists": "User with this email already exists.",
+
+	// Password reset storage errors
+	"reset_token_not_found": "Password reset code not found.",
+
+	// Two-factor authentication storage errors
+	"totp_not_found":          "Two-factor authentication is not configured.",
+	"totp_step_already_used":  "This two-factor authentication code has already been used. Wait for the next one.",
+	"recovery_code_not_found": "Recovery code not found.",
+
+	// Service account storage errors
+	"service_account_not_found":      "Service account not found.",
+	"service_account_already_exists": "Service account with this name already exists.",
+	"api_key_not_found":              "API key not found or already revoked.",
+
+	// Export storage errors
+	"export_job_not_found": "Export job not found.",
+
+	// PVZ storage errors
+	"pvz_not_found":      "Pickup point not found.",
+	"pvz_already_exists": "Pickup point with this ID already exists.",
+
+	// Reception storage errors
+	"reception_not_found":      "Reception not found.",
+	"reception_already_exists": "Reception with this ID already exists.",
+
+	// Product storage errors
+	"product_not_found":      "Product not found.",
+	"product_already_exists": "Product with this ID already exists.",
+
+	// Request field errors
+	"field.required":   "Field is required.",
+	"field.one_of":     "Must be one of: %s.",
+	"field.email":      "Must be a valid email address.",
+	"field.uuid":       "Must be a valid UUID.",
+	"field.min_length": "Must be at least %s characters long.",
+	"field.min":        "Must be at least %s.",
+	"field.max_length": "Must be at most %s characters long.",
+	"field.max":        "Must be at most %s.",
+	"field.after":      "Must be later than %s.",
+	"field.invalid":    "Invalid value.",
+	"field.type":       "Value must be %s.",
+
+	// JSON value types
+	"type.string":  "a string",
+	"type.number":  "a number",
+	"type.boolean": "a boolean",
+	"type.array":   "an array",
+	"type.object":  "an object",
+}
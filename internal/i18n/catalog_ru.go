@@ -0,0 +1,196 @@
+package i18n
+
+// ru содержит сообщения на русском. Набор ключей совпадает с en.
+var ru = map[string]string{
+	"internal_error": "Внутренняя ошибка сервера. Повторите попытку позже.",
+
+	"invalid_credentials":    "Неверный email или пароль. Проверьте учетные данные.",
+	"invalid_reset_token":    "Код сброса пароля неверен, истек или уже использован.",
+	"token_revoked":          "Токен отозван. Войдите заново.",
+	"dummy_login_disabled":   "Тестовый вход отключен в этом окружении.",
+	"dummy_token_rejected":   "Тестовые токены не принимаются в этом окружении. Войдите со своими учетными данными.",
+	"authorization_required": "Требуется заголовок Authorization.",
+	"invalid_authorization":  "Неверный заголовок Authorization. Используйте формат: Bearer <токен>.",
+	"invalid_token":          "Токен недействителен или истек. Войдите заново.",
+	"unauthenticated":        "Требуется аутентификация.",
+
+	// Access errors
+	"insufficient_permissions": "Недостаточно прав для этой операции.",
+
+	// Two-factor authentication errors
+	"invalid_mfa_code":         "Неверный код двухфакторной аутентификации.",
+	"invalid_mfa_challenge":    "Сессия двухфакторной аутентификации недействительна или истекла. Войдите заново.",
+	"mfa_already_enabled":      "Двухфакторная аутентификация уже включена.",
+	"mfa_not_enabled":          "Двухфакторная аутентификация не включена.",
+	"mfa_enrollment_not_found": "Сначала начните подключение двухфакторной аутентификации.",
+	"mfa_required":             "Для вашей роли обязательна двухфакторная аутентификация. Включите ее и войдите заново.",
+	"mfa_cannot_be_disabled":   "Для вашей роли двухфакторная аутентификация обязательна, ее нельзя отключить.",
+
+	// Service account errors
+	"invalid_api_key":      "API-ключ недействителен, истек или отозван.",
+	"insufficient_scope":   "У API-ключа нет области действия, нужной для этой операции.",
+	"pvz_access_forbidden": "API-ключу запрещен доступ к этому ПВЗ.",
+
+	// PVZ business errors
+	"pvz_decommissioned": "Выведенный из эксплуатации ПВЗ нельзя изменить.",
+	"pvz_closed":         "ПВЗ закрыт и не принимает приёмки.",
+	"capacity_exceeded":  "Вместимость ПВЗ исчерпана. Товар не может быть принят.",
+
+	// Reception business errors
+	"reception_already_closed":     "Эта приёмка уже закрыта.",
+	"reception_cannot_be_modified": "Закрытую приёмку нельзя изменить.",
+	"active_reception_exists":      "Нельзя создать новую приёмку, пока предыдущая не закрыта.",
+	"no_active_reception":          "У этого ПВЗ нет открытой приёмки.",
+
+	// Product business errors
+	"no_products_to_delete": "В текущей приёмке нет товаров для удаления.",
+
+	// Export business errors
+	"export_job_not_ready": "Выгрузка еще не завершена. Проверьте статус задачи позже.",
+	"export_job_failed":    "Выгрузка завершилась ошибкой. Запустите новую выгрузку.",
+	"export_job_expired":   "Срок хранения файла выгрузки истек. Запустите новую выгрузку.",
+
+	// Import business errors
+	"import_reference_not_found": "ПВЗ или приёмка, на которые ссылается строка, еще не загружены.",
+
+	// Idempotency business errors
+	"idempotency_key_in_use": "Запрос с этим Idempotency-Key еще выполняется. Повторите позже.",
+	"idempotency_key_reused": "Этот Idempotency-Key уже использован с другим запросом.",
+
+	// Optimistic concurrency errors
+	"version_mismatch": "Ресурс изменился с момента чтения. Перечитайте его и повторите.",
+
+	// Request validation errors
+	"invalid_request_body":     "Неверное тело запроса.",
+	"invalid_query_parameters": "Неверные параметры запроса.",
+	"request_body_too_large":   "Тело запроса слишком большое.",
+
+	// User validation errors
+	"email_required":    "Для регистрации нужен email.",
+	"invalid_email":     "Неверный формат email.",
+	"password_required": "Для регистрации нужен пароль.",
+	"invalid_password":  "Пароль слишком короткий.",
+	"password_too_long": "Пароль слишком длинный.",
+	"password_breached": "Этот пароль встречается в утечках данных. Выберите другой.",
+	"invalid_role":      "Неверная роль. Доступные роли: employee, moderator.",
+	"invalid_language":  "Неподдерживаемый язык. Доступные языки: ru, en.",
+
+	// Service account validation errors
+	"service_account_name_required": "Нужно указать имя сервисной учетной записи.",
+	"invalid_scope":                 "Неверная область действия API-ключа. Доступные области: pvz:read, receptions:write, products:write.",
+	"invalid_key_expiry":            "Срок действия API-ключа должен быть в будущем.",
+	"invalid_service_account_id":    "Неверный ID сервисной учетной записи.",
+	"invalid_api_key_id":            "Неверный ID API-ключа.",
+
+	// PVZ validation errors
+	"city_required":         "Для создания ПВЗ нужно указать город.",
+	"invalid_city":          "ПВЗ можно создать только в городах: Москва, Санкт-Петербург, Казань.",
+	"invalid_pvz_id":        "Неверный ID ПВЗ.",
+	"invalid_cursor":        "Неверный курсор пагинации. Начните с первой страницы.",
+	"invalid_sort":          "Неверное поле сортировки. Доступные поля: registrationDate, city, lastReception.",
+	"invalid_pvz_status":    "Неверный статус ПВЗ. Доступные статусы: active, temporarily_closed, decommissioned.",
+	"invalid_coordinates":   "Широта и долгота указываются вместе: широта от -90 до 90, долгота от -180 до 180.",
+	"invalid_phone":         "Телефон должен быть в международном формате, например +74951234567.",
+	"invalid_working_hours": "Неверные часы работы. Дни недели не должны повторяться, время - в формате ЧЧ:ММ, открытие и закрытие в разное время, часовой пояс - из базы IANA, даты исключений - в формате ГГГГ-ММ-ДД.",
+	"empty_pvz_update":      "Нет полей ПВЗ для изменения.",
+	"invalid_capacity":      "Ограничения вместимости должны быть неотрицательными и относиться к известным типам товаров.",
+	"invalid_threshold":     "Порог загрузки должен быть больше 0.",
+	"invalid_nearby_radius": "Радиус поиска должен быть больше 0 и не больше 50000 метров.",
+	"nearby_point_required": "Нужно указать параметры lat и lon.",
+
+	// Reception validation errors
+	"invalid_reception_id":     "Неверный ID приёмки.",
+	"invalid_reception_status": "Неверный статус приёмки. Доступные статусы: in_progress, close.",
+
+	// Report validation errors
+	"invalid_report_group":  "Неверная группировка отчета. Доступные группировки: pvz, city, productType.",
+	"invalid_report_period": "Неверный период отчета. Доступные периоды: day, week, month.",
+	"invalid_date_range":    "Начальная дата не должна быть позже конечной.",
+
+	// Product validation errors
+	"product_type_required": "Нужно указать тип товара.",
+	"invalid_product_type":  "Неверный тип товара. Доступные типы: электроника, одежда, обувь.",
+	"invalid_product_id":    "Неверный ID товара.",
+
+	// Audit validation errors
+	"invalid_actor_id": "Неверный ID автора действия.",
+
+	// Export validation errors
+	"invalid_export_dataset": "Неверный набор данных выгрузки. Доступные наборы: pvz, receptions, products.",
+	"invalid_export_format":  "Неверный формат выгрузки. Доступные форматы: csv, xlsx.",
+	"invalid_export_job_id":  "Неверный ID задачи выгрузки.",
+
+	// Import validation errors
+	"invalid_import_dataset":      "Неверный набор данных загрузки. Доступные наборы: pvz, receptions, products.",
+	"invalid_import_format":       "Неверный формат загрузки. Доступные форматы: csv, ndjson.",
+	"malformed_import_row":        "Строку не удалось разобрать. Проверьте число столбцов и кавычки.",
+	"import_external_id_required": "Нужно указать внешний ID.",
+	"duplicate_external_id":       "Внешний ID встречается в файле несколько раз.",
+	"import_value_required":       "Нужно указать значение.",
+	"invalid_import_value":        "Неверное значение. Числа пишутся через точку, даты - в формате RFC 3339, например 2025-04-10T12:00:00Z.",
+	"import_reception_not_closed": "Загрузить можно только закрытые приёмки.",
+	"import_closed_before_open":   "Приёмка не может быть закрыта раньше, чем открыта.",
+	"import_file_too_large":       "Файл загрузки слишком большой.",
+
+	// Event stream validation errors
+	"invalid_last_event_id": "Неверный Last-Event-ID. Укажите id последнего полученного события.",
+	"invalid_resume_token":  "Неверный resume_token. Начните чтение ленты сначала.",
+
+	// Idempotency validation errors
+	"invalid_idempotency_key": "Idempotency-Key должен состоять из 1-255 видимых ASCII-символов, например UUID.",
+
+	// Precondition validation errors
+	"precondition_required": "Нужен заголовок If-Match. Передайте ETag изменяемого ресурса.",
+
+	// User storage errors
+	"user_not_found":      "Пользователь не найден.",
+	"user_already_exists": "Пользователь с таким email уже существует.",
+
+	// Password reset storage errors
+	"reset_token_not_found": "Код сброса пароля не найден.",
+
+	// Two-factor authentication storage errors
+	"totp_not_found":          "Двухфакторная аутентификация не настроена.",
+	"totp_step_already_used":  "Этот код двухфакторной аутентификации уже использован. Дождитесь следующего.",
+	"recovery_code_not_found": "Код восстановления не найден.",
+
+	// Service account storage errors
+	"service_account_not_found":      "Сервисная учетная запись не найдена.",
+	"service_account_already_exists": "Сервисная учетная запись с таким именем уже существует.",
+	"api_key_not_found":              "API-ключ не найден или уже отозван.",
+
+	// Export storage errors
+	"export_job_not_found": "Задача выгрузки не найдена.",
+
+	// PVZ storage errors
+	"pvz_not_found":      "ПВЗ не найден.",
+	"pvz_already_exists": "ПВЗ с таким ID уже существует.",
+
+	// Reception storage errors
+	"reception_not_found":      "Приёмка не найдена.",
+	"reception_already_exists": "Приёмка с таким ID уже существует.",
+
+	// Product storage errors
+	"product_not_found":      "Товар не найден.",
+	"product_already_exists": "Товар с таким ID уже существует.",
+
+	// Request field errors
+	"field.required":   "Обязательное поле.",
+	"field.one_of":     "Допустимые значения: %s.",
+	"field.email":      "Укажите корректный email.",
+	"field.uuid":       "Укажите корректный UUID.",
+	"field.min_length": "Минимальная длина: %s.",
+	"field.min":        "Значение должно быть не меньше %s.",
+	"field.max_length": "Максимальная длина: %s.",
+	"field.max":        "Значение должно быть не больше %s.",
+	"field.after":      "Значение должно быть позже %s.",
+	"field.invalid":    "Недопустимое значение.",
+	"field.type":       "Значение должно быть %s.",
+
+	// JSON value types
+	"type.string":  "строкой",
+	"type.number":  "числом",
+	"type.boolean": "логическим значением",
+	"type.array":   "массивом",
+	"type.object":  "объектом",
+}
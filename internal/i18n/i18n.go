@@ -0,0 +1,85 @@
+// Package i18n хранит каталоги сообщений API на русском и английском и выбирает язык
+// ответа по предпочтению пользователя или заголовку Accept-Language.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/text/language"
+)
+
+const (
+	Russian = "ru"
+	English = "en"
+
+	// Default используется, когда клиент не указал язык или указал неподдерживаемый.
+	Default = English
+)
+
+var catalogs = map[string]map[string]string{
+	Russian: ru,
+	English: en,
+}
+
+// Порядок тегов задает приоритет при равном качестве совпадения: первый тег - язык
+// по умолчанию.
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Russian})
+
+type languageKey struct{}
+
+// Supported сообщает, есть ли каталог для языка.
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Negotiate выбирает язык по заголовку Accept-Language. Пустой или неразборчивый
+// заголовок дает Default.
+func Negotiate(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return Default
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	if index == 1 {
+		return Russian
+	}
+	return English
+}
+
+// Message возвращает сообщение по ключу на языке lang. Если в каталоге языка ключа нет,
+// используется английский каталог, а если нет и там - сам ключ.
+func Message(lang, key string, args ...any) string {
+	message, ok := catalogs[lang][key]
+	if !ok {
+		message, ok = en[key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// WithLanguage сохраняет язык ответа в контексте.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// FromContext возвращает язык ответа из контекста или Default.
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok && Supported(lang) {
+		return lang
+	}
+	return Default
+}
@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/api/errcode"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCatalogs_SameKeys(t *testing.T) {
+	assert.Len(t, ru, len(en))
+	for key := range en {
+		assert.Contains(t, ru, key, "%s has no Russian message", key)
+	}
+}
+
+// Каждый код errcode должен иметь сообщение в обоих каталогах.
+func TestCatalogs_CoverAllCodes(t *testing.T) {
+	for _, code := range append(errcode.Codes(), errcode.Internal.Code) {
+		assert.NotEmpty(t, en[code], "%s has no English message", code)
+		assert.NotEmpty(t, ru[code], "%s has no Russian message", code)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		expected       string
+	}{
+		{name: "Empty header", acceptLanguage: "", expected: English},
+		{name: "Russian", acceptLanguage: "ru", expected: Russian},
+		{name: "Russian region", acceptLanguage: "ru-RU,ru;q=0.9,en-US;q=0.8", expected: Russian},
+		{name: "English preferred", acceptLanguage: "en-GB,ru;q=0.5", expected: English},
+		{name: "Quality order", acceptLanguage: "en;q=0.3,ru;q=0.8", expected: Russian},
+		{name: "Unsupported language", acceptLanguage: "de-DE", expected: English},
+		{name: "Unsupported with fallback", acceptLanguage: "de, ru;q=0.5", expected: Russian},
+		{name: "Malformed header", acceptLanguage: ";;;", expected: English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Negotiate(tt.acceptLanguage))
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, "ПВЗ не найден.", Message(Russian, "pvz_not_found"))
+	assert.Equal(t, "Pickup point not found.", Message(English, "pvz_not_found"))
+	assert.Equal(t, "Pickup point not found.", Message("de", "pvz_not_found"))
+	assert.Equal(t, "Допустимые значения: a, b.", Message(Russian, "field.one_of", "a, b"))
+	assert.Equal(t, "unknown_key", Message(Russian, "unknown_key"))
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, Default, FromContext(context.Background()))
+	assert.Equal(t, Russian, FromContext(WithLanguage(context.Background(), Russian)))
+	assert.Equal(t, Default, FromContext(WithLanguage(context.Background(), "de")))
+}
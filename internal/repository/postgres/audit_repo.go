@@ -0,0 +1,225 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/rs/zerolog"
+)
+
+// Цепочка хешей ведется отдельно для каждой сущности. Advisory-блокировка по сущности
+// не дает двум транзакциям продолжить ее цепочку от одного и того же хеша, а записи о
+// разных сущностях пишутся параллельно.
+const (
+	auditLogLockQuery     = "SELECT pg_advisory_xact_lock(hashtextextended($1 || '/' || $2, 0))"
+	auditLogLastHashQuery = "SELECT hash FROM audit_log WHERE entity_type = $1 AND entity_id = $2 ORDER BY id DESC LIMIT 1"
+)
+
+var auditLogColumns = []string{
+	"id", "actor_id", "actor_role", "action", "entity_type", "entity_id",
+	"before_state", "after_state", "client_ip", "request_id", "created_at", "prev_hash", "hash",
+}
+
+type AuditRepository struct {
+	db *tracedQuerier
+	sb squirrel.StatementBuilderType
+}
+
+func NewAuditRepository(db Querier) interfaces.TxAuditRepository {
+	return &AuditRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *AuditRepository) WithTx(tx *sql.Tx) interfaces.AuditRepository {
+	return &AuditRepository{
+		db: traced(tx),
+		sb: r.sb,
+	}
+}
+
+// Append продолжает цепочку хешей сущности и сохраняет запись. Должен вызываться в
+// транзакции: блокировка цепочки снимается при ее завершении.
+func (r *AuditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	if _, err := r.db.ExecContext(ctx, auditLogLockQuery, entry.EntityType, entry.EntityID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to lock audit log chain")
+		return fmt.Errorf("failed to lock audit log chain: %w", err)
+	}
+
+	var prevHash string
+	err := r.db.QueryRowContext(ctx, auditLogLastHashQuery, entry.EntityType, entry.EntityID).Scan(&prevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to get last audit log hash")
+		return fmt.Errorf("failed to get last audit log hash: %w", err)
+	}
+
+	entry.Seal(prevHash)
+
+	query := r.sb.Insert("audit_log").
+		Columns(auditLogColumns[1:]...).
+		Values(
+			entry.ActorID,
+			entry.ActorRole,
+			entry.Action,
+			entry.EntityType,
+			entry.EntityID,
+			nullableJSON(entry.Before),
+			nullableJSON(entry.After),
+			entry.ClientIP,
+			entry.RequestID,
+			entry.CreatedAt,
+			entry.PrevHash,
+			entry.Hash,
+		).
+		Suffix("RETURNING id")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for audit log entry")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if err := r.db.QueryRowContext(ctx, sqlQuery, args...).Scan(&entry.ID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("action", entry.Action).
+			Str("entity_id", entry.EntityID).
+			Msg("Database error while appending audit log entry")
+		return fmt.Errorf("failed to append audit log entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	conditions := squirrel.And{}
+	if filter.ActorID != nil {
+		conditions = append(conditions, squirrel.Eq{"actor_id": *filter.ActorID})
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, squirrel.Eq{"action": filter.Action})
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, squirrel.Eq{"entity_type": filter.EntityType})
+	}
+	if filter.EntityID != "" {
+		conditions = append(conditions, squirrel.Eq{"entity_id": filter.EntityID})
+	}
+	if filter.StartDate != nil {
+		conditions = append(conditions, squirrel.GtOrEq{"created_at": *filter.StartDate})
+	}
+	if filter.EndDate != nil {
+		conditions = append(conditions, squirrel.LtOrEq{"created_at": *filter.EndDate})
+	}
+
+	countSql, countArgs, err := r.sb.Select("COUNT(*)").From("audit_log").Where(conditions).ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build count SQL query for audit log")
+		return nil, 0, fmt.Errorf("failed to build count SQL query: %w", err)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countSql, countArgs...).Scan(&total); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while counting audit log entries")
+		return nil, 0, fmt.Errorf("failed to count audit log entries: %w", err)
+	}
+
+	selectQuery := r.sb.Select(auditLogColumns...).
+		From("audit_log").
+		Where(conditions).
+		OrderBy("id DESC").
+		Limit(uint64(filter.Limit)).
+		Offset(uint64((filter.Page - 1) * filter.Limit))
+
+	sqlQuery, args, err := selectQuery.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for audit log")
+		return nil, 0, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	entries, err := r.queryEntries(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// ListAfter возвращает записи после after: цепочка за цепочкой, внутри цепочки в
+// порядке добавления. Используется для постраничной проверки цепочек.
+func (r *AuditRepository) ListAfter(ctx context.Context, after models.AuditChainCursor, limit int) ([]*models.AuditEntry, error) {
+	sqlQuery, args, err := r.sb.Select(auditLogColumns...).
+		From("audit_log").
+		Where(squirrel.Expr("(entity_type, entity_id, id) > (?, ?, ?)", after.EntityType, after.EntityID, after.ID)).
+		OrderBy("entity_type", "entity_id", "id").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for audit log")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	return r.queryEntries(ctx, sqlQuery, args...)
+}
+
+func (r *AuditRepository) queryEntries(ctx context.Context, sqlQuery string, args ...any) ([]*models.AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while querying audit log")
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*models.AuditEntry, 0)
+	for rows.Next() {
+		entry := &models.AuditEntry{}
+		var before, after []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.ActorRole,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&before,
+			&after,
+			&entry.ClientIP,
+			&entry.RequestID,
+			&entry.CreatedAt,
+			&entry.PrevHash,
+			&entry.Hash,
+		)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while scanning audit log row")
+			return nil, fmt.Errorf("failed to scan audit log row: %w", err)
+		}
+
+		if len(before) > 0 {
+			entry.Before = json.RawMessage(before)
+		}
+		if len(after) > 0 {
+			entry.After = json.RawMessage(after)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit log rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+func nullableJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func setupAuditRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *AuditRepository) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+
+	repo := &AuditRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+
+	return db, mock, repo
+}
+
+func TestNewAuditRepository(t *testing.T) {
+	db, _, _ := setupAuditRepoMock(t)
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*interfaces.TxAuditRepository)(nil), repo)
+}
+
+func TestAuditRepository_Append(t *testing.T) {
+	lockQuery := `SELECT pg_advisory_xact_lock(hashtextextended($1 || '/' || $2, 0))`
+	lastHashQuery := `SELECT hash FROM audit_log WHERE entity_type = $1 AND entity_id = $2 ORDER BY id DESC LIMIT 1`
+	insertQuery := `INSERT INTO audit_log (actor_id,actor_role,action,entity_type,entity_id,before_state,after_state,client_ip,request_id,created_at,prev_hash,hash) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING id`
+
+	newEntry := func() *models.AuditEntry {
+		entry, _ := models.NewAuditEntry(
+			models.Actor{ID: uuid.New(), Role: models.RoleModerator, ClientIP: "127.0.0.1", RequestID: "req-1"},
+			models.AuditActionPVZCreate, models.AuditEntityPVZ, uuid.NewString(), nil, map[string]string{"city": "Москва"},
+		)
+		return entry
+	}
+
+	tests := []struct {
+		name         string
+		mockSetup    func(sqlmock.Sqlmock, *models.AuditEntry)
+		wantPrevHash string
+		wantID       int64
+		wantErr      bool
+	}{
+		{
+			name: "first entry starts the chain",
+			mockSetup: func(mock sqlmock.Sqlmock, entry *models.AuditEntry) {
+				mock.ExpectExec(lockQuery).WithArgs(entry.EntityType, entry.EntityID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(lastHashQuery).WithArgs(entry.EntityType, entry.EntityID).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(insertQuery).
+					WithArgs(entry.ActorID, entry.ActorRole, entry.Action, entry.EntityType, entry.EntityID,
+						nil, string(entry.After), entry.ClientIP, entry.RequestID, entry.CreatedAt, "", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			},
+			wantPrevHash: "",
+			wantID:       1,
+		},
+		{
+			name: "entry continues the chain",
+			mockSetup: func(mock sqlmock.Sqlmock, entry *models.AuditEntry) {
+				mock.ExpectExec(lockQuery).WithArgs(entry.EntityType, entry.EntityID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(lastHashQuery).WithArgs(entry.EntityType, entry.EntityID).WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow("abc"))
+				mock.ExpectQuery(insertQuery).
+					WithArgs(entry.ActorID, entry.ActorRole, entry.Action, entry.EntityType, entry.EntityID,
+						nil, string(entry.After), entry.ClientIP, entry.RequestID, entry.CreatedAt, "abc", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+			},
+			wantPrevHash: "abc",
+			wantID:       2,
+		},
+		{
+			name: "lock error",
+			mockSetup: func(mock sqlmock.Sqlmock, entry *models.AuditEntry) {
+				mock.ExpectExec(lockQuery).WithArgs(entry.EntityType, entry.EntityID).WillReturnError(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupAuditRepoMock(t)
+			defer db.Close()
+
+			entry := newEntry()
+			tt.mockSetup(mock, entry)
+
+			err := repo.Append(context.Background(), entry)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantID, entry.ID)
+				assert.Equal(t, tt.wantPrevHash, entry.PrevHash)
+				assert.Equal(t, entry.ComputeHash(), entry.Hash)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuditRepository_ListAfter(t *testing.T) {
+	db, mock, repo := setupAuditRepoMock(t)
+	defer db.Close()
+
+	actorID := uuid.New()
+	entry, _ := models.NewAuditEntry(models.Actor{ID: actorID, Role: models.RoleEmployee},
+		models.AuditActionReceptionClose, models.AuditEntityReception, "r-1",
+		map[string]string{"status": "in_progress"}, map[string]string{"status": "close"})
+	entry.Seal("")
+
+	mock.ExpectQuery(`SELECT id, actor_id, actor_role, action, entity_type, entity_id, before_state, after_state, client_ip, request_id, created_at, prev_hash, hash FROM audit_log WHERE (entity_type, entity_id, id) > ($1, $2, $3) ORDER BY entity_type, entity_id, id LIMIT 10`).
+		WithArgs(models.AuditEntityReception, "r-0", int64(7)).
+		WillReturnRows(sqlmock.NewRows(auditLogColumns).AddRow(
+			int64(1), actorID, entry.ActorRole, entry.Action, entry.EntityType, entry.EntityID,
+			[]byte(entry.Before), []byte(entry.After), "", "", entry.CreatedAt, "", entry.Hash,
+		))
+
+	entries, err := repo.ListAfter(context.Background(), models.AuditChainCursor{EntityType: models.AuditEntityReception, EntityID: "r-0", ID: 7}, 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, entry.Hash, entries[0].ComputeHash(), "Hash must be reproducible after reading back")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -8,6 +8,7 @@ import (
 
 	"avito-backend-trainee-assignment-spring-2025/pkg/config"
 	_ "github.com/lib/pq"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -24,12 +25,7 @@ func New(cfg *config.PostgresConfig) (*DB, error) {
 		Str("user", cfg.User).
 		Msg("Connecting to PostgreSQL")
 
-	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DB, cfg.SSLMode,
-	)
-
-	db, err := sql.Open("postgres", connStr)
+	db, err := sql.Open("postgres", connString(cfg))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to open database connection")
 		return nil, fmt.Errorf("error opening database connection: %w", err)
@@ -60,25 +56,32 @@ func New(cfg *config.PostgresConfig) (*DB, error) {
 	}, nil
 }
 
+func connString(cfg *config.PostgresConfig) string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DB, cfg.SSLMode,
+	)
+}
+
 func (db *DB) Close() error {
 	log.Debug().Msg("Closing database connection")
 	return db.DB.Close()
 }
 
 func (db *DB) BeginTx(ctx context.Context) (*sql.Tx, error) {
-	log.Debug().Msg("Beginning database transaction")
+	zerolog.Ctx(ctx).Debug().Msg("Beginning database transaction")
 	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to begin transaction")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to begin transaction")
 	}
 	return tx, err
 }
 
 func (db *DB) Ping(ctx context.Context) error {
-	log.Debug().Msg("Pinging database")
+	zerolog.Ctx(ctx).Debug().Msg("Pinging database")
 	err := db.PingContext(ctx)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to ping database")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to ping database")
 	}
 	return err
 }
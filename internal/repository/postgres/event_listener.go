@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// EventsChannel - канал NOTIFY, в который триггеры ленты изменений сообщают о новых
+// событиях после фиксации транзакции.
+const EventsChannel = "pvz_events"
+
+// EventListener слушает EventsChannel на отдельном соединении. Уведомления не несут
+// данных: получив сигнал, подписчик сам дочитывает новые события из pvz_event.
+type EventListener struct {
+	listener *pq.Listener
+	wakeups  chan struct{}
+	done     chan struct{}
+}
+
+func NewEventListener(cfg *config.PostgresConfig) (*EventListener, error) {
+	l := &EventListener{
+		wakeups: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	l.listener = pq.NewListener(connString(cfg), time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			log.Warn().Err(err).Msg("PVZ events listener disconnected")
+		case pq.ListenerEventReconnected:
+			// Пока соединения не было, уведомления терялись
+			log.Info().Msg("PVZ events listener reconnected")
+			l.wake()
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Error().Err(err).Msg("PVZ events listener failed to reconnect")
+		}
+	})
+
+	if err := l.listener.Listen(EventsChannel); err != nil {
+		l.listener.Close()
+		return nil, fmt.Errorf("failed to listen for PVZ events: %w", err)
+	}
+
+	go l.run()
+
+	return l, nil
+}
+
+// Wakeups возвращает канал сигналов о новых событиях. Несколько уведомлений подряд
+// сливаются в один сигнал.
+func (l *EventListener) Wakeups() <-chan struct{} {
+	return l.wakeups
+}
+
+func (l *EventListener) Close() error {
+	close(l.done)
+	return l.listener.Close()
+}
+
+func (l *EventListener) run() {
+	for {
+		select {
+		case <-l.done:
+			return
+		case _, ok := <-l.listener.Notify:
+			if !ok {
+				return
+			}
+			l.wake()
+		}
+	}
+}
+
+func (l *EventListener) wake() {
+	select {
+	case l.wakeups <- struct{}{}:
+	default:
+	}
+}
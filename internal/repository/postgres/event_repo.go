@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type EventRepository struct {
+	db *tracedQuerier
+	sb squirrel.StatementBuilderType
+}
+
+// txSnapshotQuery возвращает границы снимка транзакций; xid8 не приводится к bigint
+// напрямую, только через текст.
+const txSnapshotQuery = "SELECT pg_snapshot_xmin(s)::text::bigint, pg_snapshot_xmax(s)::text::bigint FROM pg_current_snapshot() AS s"
+
+func NewEventRepository(db Querier) interfaces.EventRepository {
+	return &EventRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *EventRepository) ListAfter(ctx context.Context, afterID, untilID int64, filter models.EventFilter, limit int) ([]*models.PVZEvent, error) {
+	query := r.sb.Select(
+		"e.id", "e.type", "e.pvz_id", "p.city", "e.reception_id", "e.product_id", "e.product_type", "e.created_at",
+	).
+		From("pvz_event e").
+		Join("pvz p ON p.id = e.pvz_id").
+		Where(squirrel.Gt{"e.id": afterID}).
+		Where(squirrel.LtOrEq{"e.id": untilID})
+
+	if len(filter.PVZIDs) > 0 {
+		query = query.Where(squirrel.Eq{"e.pvz_id": filter.PVZIDs})
+	}
+	if len(filter.Cities) > 0 {
+		query = query.Where(squirrel.Eq{"p.city": filter.Cities})
+	}
+
+	sqlQuery, args, err := query.OrderBy("e.id").Limit(uint64(limit)).ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for listing PVZ events")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Int64("after_id", afterID).Msg("Database error while listing PVZ events")
+		return nil, fmt.Errorf("failed to list PVZ events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.PVZEvent
+	for rows.Next() {
+		var event models.PVZEvent
+		var productID uuid.NullUUID
+		var productType sql.NullString
+
+		err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.PVZID,
+			&event.City,
+			&event.ReceptionID,
+			&productID,
+			&productType,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PVZ event: %w", err)
+		}
+
+		if productID.Valid {
+			event.ProductID = &productID.UUID
+		}
+		event.ProductType = productType.String
+
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through PVZ event rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// LastID возвращает ID последнего события или 0, если событий нет.
+func (r *EventRepository) LastID(ctx context.Context) (int64, error) {
+	var lastID int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(id), 0) FROM pvz_event").Scan(&lastID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while getting last PVZ event ID")
+		return 0, fmt.Errorf("failed to get last PVZ event ID: %w", err)
+	}
+	return lastID, nil
+}
+
+func (r *EventRepository) TxSnapshot(ctx context.Context) (int64, int64, error) {
+	var xmin, xmax int64
+	err := r.db.QueryRowContext(ctx, txSnapshotQuery).Scan(&xmin, &xmax)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while getting transaction snapshot")
+		return 0, 0, fmt.Errorf("failed to get transaction snapshot: %w", err)
+	}
+	return xmin, xmax, nil
+}
+
+// DeleteBefore удаляет события, созданные раньше before, и возвращает их количество.
+func (r *EventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	query, args, err := r.sb.Delete("pvz_event").
+		Where(squirrel.Lt{"created_at": before}).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for deleting old PVZ events")
+		return 0, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while deleting old PVZ events")
+		return 0, fmt.Errorf("failed to delete old PVZ events: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted PVZ events count: %w", err)
+	}
+
+	return deleted, nil
+}
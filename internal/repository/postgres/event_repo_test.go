@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func setupEventRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *EventRepository) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+
+	repo := &EventRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+
+	return db, mock, repo
+}
+
+func TestNewEventRepository(t *testing.T) {
+	db, _, _ := setupEventRepoMock(t)
+	defer db.Close()
+
+	repo := NewEventRepository(db)
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*interfaces.EventRepository)(nil), repo)
+}
+
+func TestEventRepository_ListAfter(t *testing.T) {
+	db, mock, repo := setupEventRepoMock(t)
+	defer db.Close()
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	productID := uuid.New()
+	createdAt := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT e.id, e.type, e.pvz_id, p.city, e.reception_id, e.product_id, e.product_type, e.created_at `+
+		`FROM pvz_event e JOIN pvz p ON p.id = e.pvz_id `+
+		`WHERE e.id > $1 AND e.id <= $2 AND e.pvz_id IN ($3) AND p.city IN ($4) ORDER BY e.id LIMIT 100`).
+		WithArgs(int64(41), int64(50), pvzID, models.CityMoscow).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "pvz_id", "city", "reception_id", "product_id", "product_type", "created_at"}).
+			AddRow(int64(42), models.EventTypeReceptionOpened, pvzID, models.CityMoscow, receptionID, nil, nil, createdAt).
+			AddRow(int64(43), models.EventTypeProductAdded, pvzID, models.CityMoscow, receptionID, productID, models.ProductTypeShoes, createdAt))
+
+	events, err := repo.ListAfter(context.Background(), 41, 50, models.EventFilter{
+		PVZIDs: []uuid.UUID{pvzID},
+		Cities: []string{models.CityMoscow},
+	}, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.PVZEvent{
+		{ID: 42, Type: models.EventTypeReceptionOpened, PVZID: pvzID, City: models.CityMoscow, ReceptionID: receptionID, CreatedAt: createdAt},
+		{ID: 43, Type: models.EventTypeProductAdded, PVZID: pvzID, City: models.CityMoscow, ReceptionID: receptionID,
+			ProductID: &productID, ProductType: models.ProductTypeShoes, CreatedAt: createdAt},
+	}, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEventRepository_ListAfter_Error(t *testing.T) {
+	db, mock, repo := setupEventRepoMock(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT e.id, e.type, e.pvz_id, p.city, e.reception_id, e.product_id, e.product_type, e.created_at `+
+		`FROM pvz_event e JOIN pvz p ON p.id = e.pvz_id WHERE e.id > $1 AND e.id <= $2 ORDER BY e.id LIMIT 10`).
+		WithArgs(int64(0), int64(math.MaxInt64)).
+		WillReturnError(errors.New("database error"))
+
+	events, err := repo.ListAfter(context.Background(), 0, math.MaxInt64, models.EventFilter{}, 10)
+
+	assert.Error(t, err)
+	assert.Nil(t, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEventRepository_LastID(t *testing.T) {
+	db, mock, repo := setupEventRepoMock(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT COALESCE(MAX(id), 0) FROM pvz_event`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(int64(17)))
+
+	lastID, err := repo.LastID(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(17), lastID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEventRepository_TxSnapshot(t *testing.T) {
+	db, mock, repo := setupEventRepoMock(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT pg_snapshot_xmin(s)::text::bigint, pg_snapshot_xmax(s)::text::bigint FROM pg_current_snapshot() AS s`).
+		WillReturnRows(sqlmock.NewRows([]string{"xmin", "xmax"}).AddRow(int64(740), int64(745)))
+
+	xmin, xmax, err := repo.TxSnapshot(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(740), xmin)
+	assert.Equal(t, int64(745), xmax)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEventRepository_DeleteBefore(t *testing.T) {
+	db, mock, repo := setupEventRepoMock(t)
+	defer db.Close()
+
+	before := time.Date(2025, 4, 3, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(`DELETE FROM pvz_event WHERE created_at < $1`).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	deleted, err := repo.DeleteBefore(context.Background(), before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var exportJobColumns = []string{
+	"id", "dataset", "format", "filter", "status", "row_count", "error", "created_by", "created_at", "finished_at", "expires_at",
+}
+
+type ExportJobRepository struct {
+	db *tracedQuerier
+	sb squirrel.StatementBuilderType
+}
+
+func NewExportJobRepository(db Querier) interfaces.ExportJobRepository {
+	return &ExportJobRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func scanExportJob(row rowScanner) (*models.ExportJob, error) {
+	var job models.ExportJob
+	var filter []byte
+	var finishedAt, expiresAt sql.NullTime
+
+	err := row.Scan(
+		&job.ID,
+		&job.Request.Dataset,
+		&job.Request.Format,
+		&filter,
+		&job.Status,
+		&job.RowCount,
+		&job.Error,
+		&job.CreatedBy,
+		&job.CreatedAt,
+		&finishedAt,
+		&expiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(filter, &job.Request.Filter); err != nil {
+		return nil, fmt.Errorf("failed to decode export filter: %w", err)
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	if expiresAt.Valid {
+		job.ExpiresAt = &expiresAt.Time
+	}
+
+	return &job, nil
+}
+
+func (r *ExportJobRepository) Create(ctx context.Context, job *models.ExportJob) error {
+	filter, err := json.Marshal(job.Request.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode export filter: %w", err)
+	}
+
+	query, args, err := r.sb.Insert("export_job").
+		Columns(exportJobColumns...).
+		Values(job.ID, job.Request.Dataset, job.Request.Format, string(filter), job.Status, job.RowCount, job.Error,
+			job.CreatedBy, job.CreatedAt, job.FinishedAt, job.ExpiresAt).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for export job creation")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("job_id", job.ID.String()).Msg("Database error during export job creation")
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ExportJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ExportJob, error) {
+	query, args, err := r.sb.Select(exportJobColumns...).
+		From("export_job").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for getting export job")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	job, err := scanExportJob(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoerrors.ErrExportJobNotFound
+		}
+		zerolog.Ctx(ctx).Error().Err(err).Str("job_id", id.String()).Msg("Database error while getting export job")
+		return nil, fmt.Errorf("failed to get export job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (r *ExportJobRepository) Update(ctx context.Context, job *models.ExportJob) error {
+	query, args, err := r.sb.Update("export_job").
+		Set("status", job.Status).
+		Set("row_count", job.RowCount).
+		Set("error", job.Error).
+		Set("finished_at", job.FinishedAt).
+		Set("expires_at", job.ExpiresAt).
+		Where(squirrel.Eq{"id": job.ID}).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for export job update")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("job_id", job.ID.String()).Msg("Database error during export job update")
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return repoerrors.ErrExportJobNotFound
+	}
+
+	return nil
+}
+
+// FailUnfinished помечает неудачными выгрузки, которые не завершились, например из-за
+// остановки приложения, и возвращает их количество.
+func (r *ExportJobRepository) FailUnfinished(ctx context.Context, reason string) (int, error) {
+	query, args, err := r.sb.Update("export_job").
+		Set("status", models.ExportJobStatusFailed).
+		Set("error", reason).
+		Set("finished_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"status": []string{models.ExportJobStatusPending, models.ExportJobStatusRunning}}).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for failing unfinished export jobs")
+		return 0, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while failing unfinished export jobs")
+		return 0, fmt.Errorf("failed to fail unfinished export jobs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// DeleteExpired удаляет выгрузки, срок хранения которых истек к now, и возвращает их,
+// чтобы можно было удалить файлы.
+func (r *ExportJobRepository) DeleteExpired(ctx context.Context, now time.Time) ([]*models.ExportJob, error) {
+	query, args, err := r.sb.Delete("export_job").
+		Where(squirrel.LtOrEq{"expires_at": now}).
+		Suffix("RETURNING " + strings.Join(exportJobColumns, ", ")).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for deleting expired export jobs")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while deleting expired export jobs")
+		return nil, fmt.Errorf("failed to delete expired export jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*models.ExportJob
+	for rows.Next() {
+		job, err := scanExportJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan export job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through export job rows: %w", err)
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,225 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func setupExportJobRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *ExportJobRepository) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+
+	repo := &ExportJobRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+
+	return db, mock, repo
+}
+
+func TestNewExportJobRepository(t *testing.T) {
+	db, _, _ := setupExportJobRepoMock(t)
+	defer db.Close()
+
+	repo := NewExportJobRepository(db)
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*interfaces.ExportJobRepository)(nil), repo)
+}
+
+func TestExportJobRepository_Create(t *testing.T) {
+	db, mock, repo := setupExportJobRepoMock(t)
+	defer db.Close()
+
+	job := &models.ExportJob{
+		ID:        uuid.New(),
+		Request:   models.ExportRequest{Dataset: models.ExportDatasetPVZ, Format: models.ExportFormatCSV, Filter: models.ExportFilter{Cities: []string{"Москва"}}},
+		Status:    models.ExportJobStatusPending,
+		CreatedBy: uuid.New(),
+		CreatedAt: time.Now(),
+	}
+
+	mock.ExpectExec(`INSERT INTO export_job (id,dataset,format,filter,status,row_count,error,created_by,created_at,finished_at,expires_at) `+
+		`VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`).
+		WithArgs(job.ID, models.ExportDatasetPVZ, models.ExportFormatCSV, `{"cities":["Москва"]}`, models.ExportJobStatusPending, 0, "",
+			job.CreatedBy, job.CreatedAt, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.Create(context.Background(), job))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportJobRepository_GetByID(t *testing.T) {
+	jobID := uuid.New()
+	createdBy := uuid.New()
+	createdAt := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	finishedAt := createdAt.Add(time.Minute)
+	expiresAt := finishedAt.Add(24 * time.Hour)
+
+	query := `SELECT id, dataset, format, filter, status, row_count, error, created_by, created_at, finished_at, expires_at ` +
+		`FROM export_job WHERE id = $1`
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		want      *models.ExportJob
+		wantErr   error
+	}{
+		{
+			name: "done job",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs(jobID).
+					WillReturnRows(sqlmock.NewRows(exportJobColumns).
+						AddRow(jobID, "receptions", "xlsx", []byte(`{"receptionStatus":"close"}`), "done", 42, "",
+							createdBy, createdAt, finishedAt, expiresAt))
+			},
+			want: &models.ExportJob{
+				ID: jobID,
+				Request: models.ExportRequest{
+					Dataset: models.ExportDatasetReceptions,
+					Format:  models.ExportFormatXLSX,
+					Filter:  models.ExportFilter{ReceptionStatus: models.ReceptionStatusClosed},
+				},
+				Status:     models.ExportJobStatusDone,
+				RowCount:   42,
+				CreatedBy:  createdBy,
+				CreatedAt:  createdAt,
+				FinishedAt: &finishedAt,
+				ExpiresAt:  &expiresAt,
+			},
+		},
+		{
+			name: "job not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs(jobID).WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: repoerrors.ErrExportJobNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupExportJobRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			got, err := repo.GetByID(context.Background(), jobID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExportJobRepository_Update(t *testing.T) {
+	finishedAt := time.Now()
+	job := &models.ExportJob{ID: uuid.New(), Status: models.ExportJobStatusFailed, Error: "canceled", FinishedAt: &finishedAt}
+
+	query := `UPDATE export_job SET status = $1, row_count = $2, error = $3, finished_at = $4, expires_at = $5 WHERE id = $6`
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantErr   error
+	}{
+		{
+			name: "success",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(models.ExportJobStatusFailed, 0, "canceled", &finishedAt, nil, job.ID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "job not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: repoerrors.ErrExportJobNotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WillReturnError(errors.New("database error"))
+			},
+			wantErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupExportJobRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.Update(context.Background(), job)
+
+			if tt.wantErr != nil {
+				assert.Error(t, err)
+				if errors.Is(tt.wantErr, repoerrors.ErrExportJobNotFound) {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExportJobRepository_FailUnfinished(t *testing.T) {
+	db, mock, repo := setupExportJobRepoMock(t)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE export_job SET status = $1, error = $2, finished_at = NOW() WHERE status IN ($3,$4)`).
+		WithArgs(models.ExportJobStatusFailed, "interrupted", models.ExportJobStatusPending, models.ExportJobStatusRunning).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	count, err := repo.FailUnfinished(context.Background(), "interrupted")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportJobRepository_DeleteExpired(t *testing.T) {
+	db, mock, repo := setupExportJobRepoMock(t)
+	defer db.Close()
+
+	now := time.Now()
+	jobID := uuid.New()
+	createdBy := uuid.New()
+
+	mock.ExpectQuery(`DELETE FROM export_job WHERE expires_at <= $1 ` +
+		`RETURNING id, dataset, format, filter, status, row_count, error, created_by, created_at, finished_at, expires_at`).
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows(exportJobColumns).
+			AddRow(jobID, "pvz", "csv", []byte(`{}`), "done", 1, "", createdBy, now, now, now))
+
+	jobs, err := repo.DeleteExpired(context.Background(), now)
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, jobID, jobs[0].ID)
+		assert.Equal(t, models.ExportFormatCSV, jobs[0].Request.Format)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
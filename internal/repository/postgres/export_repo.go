@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// exportCursorName - курсор выгрузки; живет до конца транзакции, поэтому имя может быть постоянным.
+const exportCursorName = "export_cursor"
+
+type ExportRepository struct {
+	db *tracedQuerier
+	sb squirrel.StatementBuilderType
+}
+
+func NewExportRepository(db Querier) interfaces.TxExportRepository {
+	return &ExportRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *ExportRepository) WithTx(tx *sql.Tx) interfaces.ExportRepository {
+	return &ExportRepository{
+		db: traced(tx),
+		sb: r.sb,
+	}
+}
+
+// Stream объявляет серверный курсор и выбирает из него по fetchSize строк, так что в
+// памяти одновременно находится не больше одной порции. Значения строки идут в порядке
+// models.ExportColumns.
+func (r *ExportRepository) Stream(ctx context.Context, dataset string, filter models.ExportFilter, fetchSize int, fn func(row []any) error) error {
+	query, scan, err := r.exportQuery(dataset, filter)
+	if err != nil {
+		return err
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for export")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	declare := fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", exportCursorName, sqlQuery)
+	if _, err := r.db.ExecContext(ctx, declare, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("dataset", dataset).Msg("Failed to declare export cursor")
+		return fmt.Errorf("failed to declare export cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", fetchSize, exportCursorName)
+	for {
+		fetched, err := r.fetch(ctx, fetch, scan, fn)
+		if err != nil {
+			return err
+		}
+		if fetched < fetchSize {
+			break
+		}
+	}
+
+	if _, err := r.db.ExecContext(ctx, "CLOSE "+exportCursorName); err != nil {
+		return fmt.Errorf("failed to close export cursor: %w", err)
+	}
+
+	return nil
+}
+
+// fetch читает одну порцию из курсора и возвращает количество прочитанных строк.
+func (r *ExportRepository) fetch(ctx context.Context, fetch string, scan func(*tracedRows) ([]any, error), fn func(row []any) error) (int, error) {
+	rows, err := r.db.QueryContext(ctx, fetch)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to fetch rows from export cursor")
+		return 0, fmt.Errorf("failed to fetch export rows: %w", err)
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		row, err := scan(rows)
+		if err != nil {
+			return 0, fmt.Errorf("failed to scan export row: %w", err)
+		}
+		if err := fn(row); err != nil {
+			return 0, err
+		}
+		fetched++
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating through export rows: %w", err)
+	}
+
+	return fetched, nil
+}
+
+// exportQuery строит запрос набора данных и функцию чтения его строки.
+func (r *ExportRepository) exportQuery(dataset string, filter models.ExportFilter) (squirrel.SelectBuilder, func(*tracedRows) ([]any, error), error) {
+	var query squirrel.SelectBuilder
+	var scan func(*tracedRows) ([]any, error)
+
+	switch dataset {
+	case models.ExportDatasetPVZ:
+		query = r.sb.Select("pvz.id", "pvz.registration_date", "pvz.city", "pvz.address", "pvz.latitude", "pvz.longitude", "pvz.phone", "pvz.status").
+			From("pvz").
+			OrderBy("pvz.registration_date", "pvz.id")
+		scan = scanPVZExportRow
+
+	case models.ExportDatasetReceptions:
+		query = r.sb.Select("reception.id", "reception.pvz_id", "pvz.city", "reception.date_time", "reception.closed_at", "reception.status",
+			"(SELECT COUNT(*) FROM product WHERE product.reception_id = reception.id) AS products").
+			From("reception").
+			Join("pvz ON pvz.id = reception.pvz_id").
+			OrderBy("reception.date_time", "reception.id")
+		scan = scanReceptionExportRow
+
+	case models.ExportDatasetProducts:
+		query = r.sb.Select("product.id", "product.reception_id", "reception.pvz_id", "pvz.city", "product.date_time", "product.type").
+			From("product").
+			Join("reception ON reception.id = product.reception_id").
+			Join("pvz ON pvz.id = reception.pvz_id").
+			OrderBy("product.date_time", "product.id")
+		if filter.ProductType != "" {
+			query = query.Where(squirrel.Eq{"product.type": filter.ProductType})
+		}
+		scan = scanProductExportRow
+
+	default:
+		return query, nil, apperrors.ErrInvalidExportDataset
+	}
+
+	pvzFilter := filter.PVZFilter()
+	for _, condition := range pvzFilterConditions(pvzFilter) {
+		query = query.Where(condition)
+	}
+	if len(filter.PVZIDs) > 0 {
+		query = query.Where(squirrel.Eq{"pvz.id": filter.PVZIDs})
+	}
+	if dataset != models.ExportDatasetPVZ {
+		for _, condition := range receptionFilterConditions(pvzFilter) {
+			query = query.Where(condition)
+		}
+	}
+
+	return query, scan, nil
+}
+
+func scanPVZExportRow(rows *tracedRows) ([]any, error) {
+	var id uuid.UUID
+	var registrationDate time.Time
+	var city, address, phone, status string
+	var latitude, longitude sql.NullFloat64
+
+	if err := rows.Scan(&id, &registrationDate, &city, &address, &latitude, &longitude, &phone, &status); err != nil {
+		return nil, err
+	}
+
+	return []any{id, registrationDate, city, address, nullFloat(latitude), nullFloat(longitude), phone, status}, nil
+}
+
+func scanReceptionExportRow(rows *tracedRows) ([]any, error) {
+	var id, pvzID uuid.UUID
+	var city, status string
+	var dateTime time.Time
+	var closedAt sql.NullTime
+	var products int
+
+	if err := rows.Scan(&id, &pvzID, &city, &dateTime, &closedAt, &status, &products); err != nil {
+		return nil, err
+	}
+
+	var closed *time.Time
+	if closedAt.Valid {
+		closed = &closedAt.Time
+	}
+
+	return []any{id, pvzID, city, dateTime, closed, status, products}, nil
+}
+
+func scanProductExportRow(rows *tracedRows) ([]any, error) {
+	var id, receptionID, pvzID uuid.UUID
+	var city, productType string
+	var dateTime time.Time
+
+	if err := rows.Scan(&id, &receptionID, &pvzID, &city, &dateTime, &productType); err != nil {
+		return nil, err
+	}
+
+	return []any{id, receptionID, pvzID, city, dateTime, productType}, nil
+}
+
+func nullFloat(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func setupExportRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *ExportRepository) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+
+	repo := &ExportRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+
+	return db, mock, repo
+}
+
+func TestNewExportRepository(t *testing.T) {
+	db, _, _ := setupExportRepoMock(t)
+	defer db.Close()
+
+	repo := NewExportRepository(db)
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*interfaces.TxExportRepository)(nil), repo)
+}
+
+func TestExportRepository_Stream(t *testing.T) {
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	registered := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	latitude := 55.75
+
+	pvzColumns := []string{"id", "registration_date", "city", "address", "latitude", "longitude", "phone", "status"}
+	productColumns := []string{"id", "reception_id", "pvz_id", "city", "date_time", "type"}
+
+	declarePVZ := `DECLARE export_cursor NO SCROLL CURSOR FOR ` +
+		`SELECT pvz.id, pvz.registration_date, pvz.city, pvz.address, pvz.latitude, pvz.longitude, pvz.phone, pvz.status ` +
+		`FROM pvz WHERE city IN ($1) AND pvz.id IN ($2) ORDER BY pvz.registration_date, pvz.id`
+
+	declareProducts := `DECLARE export_cursor NO SCROLL CURSOR FOR ` +
+		`SELECT product.id, product.reception_id, reception.pvz_id, pvz.city, product.date_time, product.type ` +
+		`FROM product JOIN reception ON reception.id = product.reception_id JOIN pvz ON pvz.id = reception.pvz_id ` +
+		`WHERE EXISTS (SELECT 1 FROM reception WHERE reception.pvz_id = pvz.id AND reception.date_time >= $1) ` +
+		`AND reception.date_time >= $2 ORDER BY product.date_time, product.id`
+
+	tests := []struct {
+		name      string
+		dataset   string
+		filter    models.ExportFilter
+		mockSetup func(sqlmock.Sqlmock)
+		want      [][]any
+		wantErr   error
+	}{
+		{
+			name:    "pvz are fetched in batches until cursor is exhausted",
+			dataset: models.ExportDatasetPVZ,
+			filter:  models.ExportFilter{Cities: []string{"Москва"}, PVZIDs: []uuid.UUID{pvzID}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(declarePVZ).WithArgs("Москва", pvzID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("FETCH FORWARD 2 FROM export_cursor").
+					WillReturnRows(sqlmock.NewRows(pvzColumns).
+						AddRow(pvzID, registered, "Москва", "ул. Тверская, 1", latitude, nil, "", models.PVZStatusActive).
+						AddRow(pvzID, registered, "Москва", "ул. Тверская, 2", nil, nil, "+74950000000", models.PVZStatusTemporarilyClosed))
+				mock.ExpectQuery("FETCH FORWARD 2 FROM export_cursor").
+					WillReturnRows(sqlmock.NewRows(pvzColumns))
+				mock.ExpectExec("CLOSE export_cursor").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want: [][]any{
+				{pvzID, registered, "Москва", "ул. Тверская, 1", &latitude, (*float64)(nil), "", models.PVZStatusActive},
+				{pvzID, registered, "Москва", "ул. Тверская, 2", (*float64)(nil), (*float64)(nil), "+74950000000", models.PVZStatusTemporarilyClosed},
+			},
+		},
+		{
+			name:    "products filtered by reception date",
+			dataset: models.ExportDatasetProducts,
+			filter:  models.ExportFilter{StartDate: &registered},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(declareProducts).WithArgs(registered, registered).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("FETCH FORWARD 2 FROM export_cursor").
+					WillReturnRows(sqlmock.NewRows(productColumns).
+						AddRow(pvzID, receptionID, pvzID, "Казань", registered, models.ProductTypeShoes))
+				mock.ExpectExec("CLOSE export_cursor").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want: [][]any{
+				{pvzID, receptionID, pvzID, "Казань", registered, models.ProductTypeShoes},
+			},
+		},
+		{
+			name:      "unknown dataset",
+			dataset:   "users",
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			wantErr:   apperrors.ErrInvalidExportDataset,
+		},
+		{
+			name:    "fetch error",
+			dataset: models.ExportDatasetPVZ,
+			filter:  models.ExportFilter{Cities: []string{"Москва"}, PVZIDs: []uuid.UUID{pvzID}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(declarePVZ).WithArgs("Москва", pvzID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("FETCH FORWARD 2 FROM export_cursor").WillReturnError(errors.New("database error"))
+			},
+			wantErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupExportRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			var got [][]any
+			err := repo.Stream(context.Background(), tt.dataset, tt.filter, 2, func(row []any) error {
+				got = append(got, row)
+				return nil
+			})
+
+			if tt.wantErr != nil {
+				assert.Error(t, err)
+				if errors.Is(tt.wantErr, apperrors.ErrInvalidExportDataset) {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
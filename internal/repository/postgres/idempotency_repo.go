@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// idempotencyAcquireAttempts ограничивает повторы Acquire, если занявший ключ запрос
+// освободил его между вставкой и чтением.
+const idempotencyAcquireAttempts = 3
+
+// idempotencyAcquireSuffix занимает существующую запись, только если она истекла или
+// осталась без ответа дольше срока блокировки.
+const idempotencyAcquireSuffix = "ON CONFLICT (actor_id, key) DO UPDATE SET " +
+	"fingerprint = EXCLUDED.fingerprint, status_code = NULL, headers = NULL, body = NULL, " +
+	"locked_at = EXCLUDED.locked_at, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at " +
+	"WHERE idempotency_key.expires_at <= EXCLUDED.locked_at " +
+	"OR (idempotency_key.status_code IS NULL AND idempotency_key.locked_at < ?) " +
+	"RETURNING actor_id"
+
+type IdempotencyRepository struct {
+	db *tracedQuerier
+	sb squirrel.StatementBuilderType
+}
+
+func NewIdempotencyRepository(db Querier) interfaces.IdempotencyRepository {
+	return &IdempotencyRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *IdempotencyRepository) Acquire(ctx context.Context, record *models.IdempotencyRecord, staleBefore time.Time) (*models.IdempotencyRecord, error) {
+	for attempt := 0; attempt < idempotencyAcquireAttempts; attempt++ {
+		acquired, err := r.insert(ctx, record, staleBefore)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			return nil, nil
+		}
+
+		existing, err := r.get(ctx, record.ActorID, record.Key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return existing, nil
+	}
+
+	return nil, fmt.Errorf("failed to acquire idempotency key after %d attempts", idempotencyAcquireAttempts)
+}
+
+// insert создает запись или занимает истекшую либо брошенную. Действующую запись
+// запрос не меняет и строк не возвращает.
+func (r *IdempotencyRepository) insert(ctx context.Context, record *models.IdempotencyRecord, staleBefore time.Time) (bool, error) {
+	query, args, err := r.sb.Insert("idempotency_key").
+		Columns("actor_id", "key", "fingerprint", "locked_at", "created_at", "expires_at").
+		Values(record.ActorID, record.Key, record.Fingerprint, record.LockedAt, record.CreatedAt, record.ExpiresAt).
+		Suffix(idempotencyAcquireSuffix, staleBefore).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for acquiring idempotency key")
+		return false, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	var actorID uuid.UUID
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(&actorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("actor_id", record.ActorID.String()).Msg("Database error while acquiring idempotency key")
+		return false, fmt.Errorf("failed to acquire idempotency key: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *IdempotencyRepository) get(ctx context.Context, actorID uuid.UUID, key string) (*models.IdempotencyRecord, error) {
+	query, args, err := r.sb.Select("actor_id", "key", "fingerprint", "status_code", "headers", "body", "locked_at", "created_at", "expires_at").
+		From("idempotency_key").
+		Where(squirrel.Eq{"actor_id": actorID, "key": key}).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for getting idempotency key")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	var record models.IdempotencyRecord
+	var statusCode sql.NullInt64
+	var header []byte
+
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(
+		&record.ActorID,
+		&record.Key,
+		&record.Fingerprint,
+		&statusCode,
+		&header,
+		&record.Body,
+		&record.LockedAt,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("actor_id", actorID.String()).Msg("Database error while getting idempotency key")
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	record.StatusCode = int(statusCode.Int64)
+	if len(header) > 0 {
+		if err := json.Unmarshal(header, &record.Header); err != nil {
+			return nil, fmt.Errorf("failed to decode idempotent response headers: %w", err)
+		}
+	}
+
+	return &record, nil
+}
+
+// Complete сохраняет ответ. Запись, которую за это время занял другой запрос, не меняется.
+func (r *IdempotencyRepository) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotent response headers: %w", err)
+	}
+
+	query, args, err := r.sb.Update("idempotency_key").
+		Set("status_code", record.StatusCode).
+		Set("headers", header).
+		Set("body", record.Body).
+		Where(squirrel.Eq{
+			"actor_id":    record.ActorID,
+			"key":         record.Key,
+			"locked_at":   record.LockedAt,
+			"status_code": nil,
+		}).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for completing idempotency key")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("actor_id", record.ActorID.String()).Msg("Database error while completing idempotency key")
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release удаляет незавершенную запись, чтобы повтор выполнил запрос заново. Как и
+// Complete, не трогает запись, которую занял другой запрос.
+func (r *IdempotencyRepository) Release(ctx context.Context, record *models.IdempotencyRecord) error {
+	query, args, err := r.sb.Delete("idempotency_key").
+		Where(squirrel.Eq{
+			"actor_id":    record.ActorID,
+			"key":         record.Key,
+			"locked_at":   record.LockedAt,
+			"status_code": nil,
+		}).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for releasing idempotency key")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("actor_id", record.ActorID.String()).Msg("Database error while releasing idempotency key")
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired удаляет записи, истекшие к now, и возвращает их количество.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	query, args, err := r.sb.Delete("idempotency_key").
+		Where(squirrel.LtOrEq{"expires_at": now}).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for deleting expired idempotency keys")
+		return 0, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while deleting expired idempotency keys")
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted idempotency keys count: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,230 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const (
+	idempotencyInsertQuery = `INSERT INTO idempotency_key (actor_id,key,fingerprint,locked_at,created_at,expires_at) ` +
+		`VALUES ($1,$2,$3,$4,$5,$6) ON CONFLICT (actor_id, key) DO UPDATE SET ` +
+		`fingerprint = EXCLUDED.fingerprint, status_code = NULL, headers = NULL, body = NULL, ` +
+		`locked_at = EXCLUDED.locked_at, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at ` +
+		`WHERE idempotency_key.expires_at <= EXCLUDED.locked_at ` +
+		`OR (idempotency_key.status_code IS NULL AND idempotency_key.locked_at < $7) RETURNING actor_id`
+	idempotencySelectQuery = `SELECT actor_id, key, fingerprint, status_code, headers, body, locked_at, created_at, expires_at ` +
+		`FROM idempotency_key WHERE actor_id = $1 AND key = $2`
+)
+
+func setupIdempotencyRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *IdempotencyRepository) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+
+	repo := &IdempotencyRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+
+	return db, mock, repo
+}
+
+func TestNewIdempotencyRepository(t *testing.T) {
+	db, _, _ := setupIdempotencyRepoMock(t)
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(db)
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*interfaces.IdempotencyRepository)(nil), repo)
+}
+
+func TestIdempotencyRepository_Acquire(t *testing.T) {
+	actorID := uuid.New()
+	now := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	staleBefore := now.Add(-time.Minute)
+
+	record := &models.IdempotencyRecord{
+		ActorID:     actorID,
+		Key:         "retry-1",
+		Fingerprint: "abc",
+		LockedAt:    now,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(24 * time.Hour),
+	}
+	insertArgs := []driver.Value{actorID, "retry-1", "abc", now, now, now.Add(24 * time.Hour), staleBefore}
+	selectColumns := []string{"actor_id", "key", "fingerprint", "status_code", "headers", "body", "locked_at", "created_at", "expires_at"}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expected      *models.IdempotencyRecord
+		expectedError bool
+	}{
+		{
+			name: "Ключ свободен",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(idempotencyInsertQuery).
+					WithArgs(insertArgs...).
+					WillReturnRows(sqlmock.NewRows([]string{"actor_id"}).AddRow(actorID))
+			},
+		},
+		{
+			name: "Ключ занят выполненным запросом",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(idempotencyInsertQuery).
+					WithArgs(insertArgs...).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(idempotencySelectQuery).
+					WithArgs(actorID, "retry-1").
+					WillReturnRows(sqlmock.NewRows(selectColumns).
+						AddRow(actorID, "retry-1", "abc", 201, []byte(`{"Etag":["\"1\""],"Content-Type":["application/json"]}`), []byte(`{"id":1}`), staleBefore, staleBefore, now.Add(time.Hour)))
+			},
+			expected: &models.IdempotencyRecord{
+				ActorID:     actorID,
+				Key:         "retry-1",
+				Fingerprint: "abc",
+				StatusCode:  201,
+				Header:      http.Header{"Etag": {`"1"`}, "Content-Type": {"application/json"}},
+				Body:        []byte(`{"id":1}`),
+				LockedAt:    staleBefore,
+				CreatedAt:   staleBefore,
+				ExpiresAt:   now.Add(time.Hour),
+			},
+		},
+		{
+			name: "Запрос еще выполняется",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(idempotencyInsertQuery).
+					WithArgs(insertArgs...).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(idempotencySelectQuery).
+					WithArgs(actorID, "retry-1").
+					WillReturnRows(sqlmock.NewRows(selectColumns).
+						AddRow(actorID, "retry-1", "abc", nil, nil, nil, now, now, now.Add(time.Hour)))
+			},
+			expected: &models.IdempotencyRecord{
+				ActorID:     actorID,
+				Key:         "retry-1",
+				Fingerprint: "abc",
+				LockedAt:    now,
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(time.Hour),
+			},
+		},
+		{
+			name: "Ключ освобожден между вставкой и чтением",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(idempotencyInsertQuery).
+					WithArgs(insertArgs...).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(idempotencySelectQuery).
+					WithArgs(actorID, "retry-1").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(idempotencyInsertQuery).
+					WithArgs(insertArgs...).
+					WillReturnRows(sqlmock.NewRows([]string{"actor_id"}).AddRow(actorID))
+			},
+		},
+		{
+			name: "Ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(idempotencyInsertQuery).
+					WithArgs(insertArgs...).
+					WillReturnError(errors.New("database error"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupIdempotencyRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			existing, err := repo.Acquire(context.Background(), record, staleBefore)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, existing)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestIdempotencyRepository_Complete(t *testing.T) {
+	db, mock, repo := setupIdempotencyRepoMock(t)
+	defer db.Close()
+
+	record := &models.IdempotencyRecord{
+		ActorID:    uuid.New(),
+		Key:        "retry-1",
+		StatusCode: 201,
+		Header:     http.Header{"Location": {"/pvz/1"}},
+		Body:       []byte(`{}`),
+		LockedAt:   time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC),
+	}
+
+	mock.ExpectExec(`UPDATE idempotency_key SET status_code = $1, headers = $2, body = $3 `+
+		`WHERE actor_id = $4 AND key = $5 AND locked_at = $6 AND status_code IS NULL`).
+		WithArgs(201, []byte(`{"Location":["/pvz/1"]}`), []byte(`{}`), record.ActorID, "retry-1", record.LockedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.Complete(context.Background(), record)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_Release(t *testing.T) {
+	db, mock, repo := setupIdempotencyRepoMock(t)
+	defer db.Close()
+
+	record := &models.IdempotencyRecord{
+		ActorID:  uuid.New(),
+		Key:      "retry-1",
+		LockedAt: time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC),
+	}
+
+	mock.ExpectExec(`DELETE FROM idempotency_key WHERE actor_id = $1 AND key = $2 AND locked_at = $3 AND status_code IS NULL`).
+		WithArgs(record.ActorID, "retry-1", record.LockedAt).
+		WillReturnError(errors.New("database error"))
+
+	err := repo.Release(context.Background(), record)
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_DeleteExpired(t *testing.T) {
+	db, mock, repo := setupIdempotencyRepoMock(t)
+	defer db.Close()
+
+	now := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(`DELETE FROM idempotency_key WHERE expires_at <= $1`).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := repo.DeleteExpired(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type ImportRepository struct {
+	db *tracedQuerier
+	sb squirrel.StatementBuilderType
+}
+
+func NewImportRepository(db Querier) interfaces.TxImportRepository {
+	return &ImportRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *ImportRepository) WithTx(tx *sql.Tx) interfaces.ImportRepository {
+	return &ImportRepository{
+		db: traced(tx),
+		sb: r.sb,
+	}
+}
+
+// FindEntityIDs возвращает ID сущностей набора dataset по внешним ID. Внешних ID,
+// которые еще не загружались, в результате нет.
+func (r *ImportRepository) FindEntityIDs(ctx context.Context, dataset string, externalIDs []string) (map[string]uuid.UUID, error) {
+	result := make(map[string]uuid.UUID, len(externalIDs))
+	if len(externalIDs) == 0 {
+		return result, nil
+	}
+
+	query, args, err := r.sb.Select("external_id", "entity_id").
+		From("import_mapping").
+		Where(squirrel.Eq{"dataset": dataset, "external_id": externalIDs}).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for finding imported entities")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("dataset", dataset).Msg("Database error while finding imported entities")
+		return nil, fmt.Errorf("failed to find imported entities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var externalID string
+		var entityID uuid.UUID
+		if err := rows.Scan(&externalID, &entityID); err != nil {
+			return nil, fmt.Errorf("failed to scan imported entity: %w", err)
+		}
+		result[externalID] = entityID
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through imported entity rows: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *ImportRepository) SaveEntityID(ctx context.Context, dataset, externalID string, entityID uuid.UUID) error {
+	query, args, err := r.sb.Insert("import_mapping").
+		Columns("dataset", "external_id", "entity_id").
+		Values(dataset, externalID, entityID).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for saving imported entity")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("dataset", dataset).
+			Str("external_id", externalID).
+			Msg("Database error while saving imported entity")
+		return fmt.Errorf("failed to save imported entity: %w", err)
+	}
+
+	return nil
+}
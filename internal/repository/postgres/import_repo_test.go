@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func setupImportRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *ImportRepository) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+
+	repo := &ImportRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+
+	return db, mock, repo
+}
+
+func TestNewImportRepository(t *testing.T) {
+	db, _, _ := setupImportRepoMock(t)
+	defer db.Close()
+
+	repo := NewImportRepository(db)
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*interfaces.ImportRepository)(nil), repo)
+}
+
+func TestImportRepository_FindEntityIDs(t *testing.T) {
+	db, mock, repo := setupImportRepoMock(t)
+	defer db.Close()
+
+	firstID := uuid.New()
+	secondID := uuid.New()
+
+	mock.ExpectQuery(`SELECT external_id, entity_id FROM import_mapping WHERE dataset = $1 AND external_id IN ($2,$3,$4)`).
+		WithArgs(models.ImportDatasetPVZ, "a", "b", "c").
+		WillReturnRows(sqlmock.NewRows([]string{"external_id", "entity_id"}).
+			AddRow("a", firstID).
+			AddRow("c", secondID))
+
+	ids, err := repo.FindEntityIDs(context.Background(), models.ImportDatasetPVZ, []string{"a", "b", "c"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]uuid.UUID{"a": firstID, "c": secondID}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportRepository_FindEntityIDs_Empty(t *testing.T) {
+	db, mock, repo := setupImportRepoMock(t)
+	defer db.Close()
+
+	ids, err := repo.FindEntityIDs(context.Background(), models.ImportDatasetPVZ, nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportRepository_FindEntityIDs_Error(t *testing.T) {
+	db, mock, repo := setupImportRepoMock(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT external_id, entity_id FROM import_mapping WHERE dataset = $1 AND external_id IN ($2)`).
+		WithArgs(models.ImportDatasetReceptions, "r-1").
+		WillReturnError(errors.New("database error"))
+
+	ids, err := repo.FindEntityIDs(context.Background(), models.ImportDatasetReceptions, []string{"r-1"})
+
+	assert.Error(t, err)
+	assert.Nil(t, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportRepository_SaveEntityID(t *testing.T) {
+	db, mock, repo := setupImportRepoMock(t)
+	defer db.Close()
+
+	entityID := uuid.New()
+
+	mock.ExpectExec(`INSERT INTO import_mapping (dataset,external_id,entity_id) VALUES ($1,$2,$3)`).
+		WithArgs(models.ImportDatasetProducts, "p-1", entityID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.SaveEntityID(context.Background(), models.ImportDatasetProducts, "p-1", entityID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportRepository_SaveEntityID_Error(t *testing.T) {
+	db, mock, repo := setupImportRepoMock(t)
+	defer db.Close()
+
+	entityID := uuid.New()
+
+	mock.ExpectExec(`INSERT INTO import_mapping (dataset,external_id,entity_id) VALUES ($1,$2,$3)`).
+		WithArgs(models.ImportDatasetProducts, "p-1", entityID).
+		WillReturnError(errors.New("database error"))
+
+	assert.Error(t, repo.SaveEntityID(context.Background(), models.ImportDatasetProducts, "p-1", entityID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
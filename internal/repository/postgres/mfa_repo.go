@@ -0,0 +1,376 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type MFARepository struct {
+	db *tracedQuerier
+	sb squirrel.StatementBuilderType
+}
+
+func NewMFARepository(db Querier) interfaces.TxMFARepository {
+	return &MFARepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *MFARepository) WithTx(tx *sql.Tx) interfaces.MFARepository {
+	return &MFARepository{
+		db: traced(tx),
+		sb: r.sb,
+	}
+}
+
+// SaveTOTP сохраняет новый секрет. Повторная регистрация заменяет
+// неподтвержденный секрет и сбрасывает состояние подтверждения.
+func (r *MFARepository) SaveTOTP(ctx context.Context, credential *models.TOTPCredential) error {
+	query := r.sb.Insert("user_totp").
+		Columns("user_id", "secret", "created_at").
+		Values(credential.UserID, credential.Secret, credential.CreatedAt).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, confirmed_at = NULL, last_used_step = 0, created_at = EXCLUDED.created_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for TOTP credential saving")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", credential.UserID.String()).
+			Msg("Database error during TOTP credential saving")
+		return fmt.Errorf("failed to save TOTP credential: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPCredential, error) {
+	query := r.sb.Select("user_id", "secret", "confirmed_at", "last_used_step", "created_at").
+		From("user_totp").
+		Where(squirrel.Eq{"user_id": userID})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for TOTP credential retrieval")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	credential := &models.TOTPCredential{}
+	var confirmedAt sql.NullTime
+	err = r.db.QueryRowContext(ctx, sqlQuery, args...).Scan(
+		&credential.UserID,
+		&credential.Secret,
+		&confirmedAt,
+		&credential.LastUsedStep,
+		&credential.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoerrors.ErrTOTPNotFound
+		}
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", userID.String()).
+			Msg("Database error while scanning TOTP credential row")
+		return nil, fmt.Errorf("failed to get TOTP credential: %w", err)
+	}
+
+	if confirmedAt.Valid {
+		credential.ConfirmedAt = &confirmedAt.Time
+	}
+
+	return credential, nil
+}
+
+func (r *MFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64) error {
+	query := r.sb.Update("user_totp").
+		Set("confirmed_at", time.Now()).
+		Set("last_used_step", step).
+		Where(squirrel.Eq{"user_id": userID, "confirmed_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for TOTP confirmation")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", userID.String()).
+			Msg("Database error during TOTP confirmation")
+		return fmt.Errorf("failed to confirm TOTP credential: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repoerrors.ErrTOTPNotFound
+	}
+
+	return nil
+}
+
+// UpdateLastUsedStep запоминает шаг последнего принятого кода. Условие по шагу
+// не дает повторно использовать один и тот же код даже при параллельных запросах.
+func (r *MFARepository) UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	query := r.sb.Update("user_totp").
+		Set("last_used_step", step).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Lt{"last_used_step": step})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for TOTP step update")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", userID.String()).
+			Msg("Database error during TOTP step update")
+		return fmt.Errorf("failed to update TOTP step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repoerrors.ErrTOTPStepAlreadyUsed
+	}
+
+	return nil
+}
+
+func (r *MFARepository) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	query := r.sb.Delete("user_totp").
+		Where(squirrel.Eq{"user_id": userID})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for TOTP credential deletion")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", userID.String()).
+			Msg("Database error during TOTP credential deletion")
+		return fmt.Errorf("failed to delete TOTP credential: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes удаляет все прежние коды восстановления пользователя и
+// сохраняет новые. Должен вызываться внутри транзакции.
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*models.RecoveryCode) error {
+	deleteQuery := r.sb.Delete("mfa_recovery_codes").
+		Where(squirrel.Eq{"user_id": userID})
+
+	sqlQuery, args, err := deleteQuery.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for recovery codes deletion")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", userID.String()).
+			Msg("Database error during recovery codes deletion")
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	insertQuery := r.sb.Insert("mfa_recovery_codes").
+		Columns("id", "user_id", "code_hash", "created_at")
+	for _, code := range codes {
+		insertQuery = insertQuery.Values(code.ID, code.UserID, code.CodeHash, code.CreatedAt)
+	}
+
+	sqlQuery, args, err = insertQuery.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for recovery codes creation")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", userID.String()).
+			Msg("Database error during recovery codes creation")
+		return fmt.Errorf("failed to create recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	query := r.sb.Update("mfa_recovery_codes").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID, "code_hash": codeHash, "used_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for recovery code usage")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", userID.String()).
+			Msg("Database error during recovery code usage")
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repoerrors.ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+// LockChallenge создает запись о токене второго шага при первом вводе кода и блокирует
+// ее до конца транзакции, чтобы параллельные попытки проверялись по очереди. Заодно
+// удаляются истекшие записи пользователя. Должен вызываться внутри транзакции.
+func (r *MFARepository) LockChallenge(ctx context.Context, challenge *models.MFAChallenge) (*models.MFAChallenge, error) {
+	deleteQuery := r.sb.Delete("mfa_challenges").
+		Where(squirrel.Eq{"user_id": challenge.UserID}).
+		Where(squirrel.Lt{"expires_at": time.Now()})
+
+	sqlQuery, args, err := deleteQuery.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for expired MFA challenges deletion")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", challenge.UserID.String()).
+			Msg("Database error during expired MFA challenges deletion")
+		return nil, fmt.Errorf("failed to delete expired MFA challenges: %w", err)
+	}
+
+	insertQuery := r.sb.Insert("mfa_challenges").
+		Columns("id", "user_id", "expires_at").
+		Values(challenge.ID, challenge.UserID, challenge.ExpiresAt).
+		Suffix("ON CONFLICT (id) DO NOTHING")
+
+	sqlQuery, args, err = insertQuery.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for MFA challenge creation")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("challenge_id", challenge.ID.String()).
+			Msg("Database error during MFA challenge creation")
+		return nil, fmt.Errorf("failed to create MFA challenge: %w", err)
+	}
+
+	selectQuery := r.sb.Select("id", "user_id", "failed_attempts", "used_at", "expires_at").
+		From("mfa_challenges").
+		Where(squirrel.Eq{"id": challenge.ID}).
+		Suffix("FOR UPDATE")
+
+	sqlQuery, args, err = selectQuery.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for MFA challenge locking")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	locked := &models.MFAChallenge{}
+	var usedAt sql.NullTime
+	err = r.db.QueryRowContext(ctx, sqlQuery, args...).Scan(
+		&locked.ID,
+		&locked.UserID,
+		&locked.FailedAttempts,
+		&usedAt,
+		&locked.ExpiresAt,
+	)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("challenge_id", challenge.ID.String()).
+			Msg("Database error while locking MFA challenge")
+		return nil, fmt.Errorf("failed to lock MFA challenge: %w", err)
+	}
+
+	if usedAt.Valid {
+		locked.UsedAt = &usedAt.Time
+	}
+
+	return locked, nil
+}
+
+// RecordChallengeFailure увеличивает счетчик неверных кодов по токену второго шага.
+func (r *MFARepository) RecordChallengeFailure(ctx context.Context, id uuid.UUID) error {
+	query := r.sb.Update("mfa_challenges").
+		Set("failed_attempts", squirrel.Expr("failed_attempts + 1")).
+		Where(squirrel.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for MFA challenge failure")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("challenge_id", id.String()).
+			Msg("Database error during MFA challenge failure recording")
+		return fmt.Errorf("failed to record MFA challenge failure: %w", err)
+	}
+
+	return nil
+}
+
+// UseChallenge отмечает токен второго шага использованным после успешного входа.
+func (r *MFARepository) UseChallenge(ctx context.Context, id uuid.UUID) error {
+	query := r.sb.Update("mfa_challenges").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"id": id, "used_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for MFA challenge usage")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("challenge_id", id.String()).
+			Msg("Database error during MFA challenge usage")
+		return fmt.Errorf("failed to use MFA challenge: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,327 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func setupMFARepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *MFARepository) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+
+	repo := &MFARepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+
+	return db, mock, repo
+}
+
+func TestNewMFARepository(t *testing.T) {
+	db, _, _ := setupMFARepoMock(t)
+	defer db.Close()
+
+	repo := NewMFARepository(db)
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*interfaces.TxMFARepository)(nil), repo)
+}
+
+func TestMFARepository_SaveTOTP(t *testing.T) {
+	credential := models.NewTOTPCredential(uuid.New(), "JBSWY3DPEHPK3PXP")
+	query := `INSERT INTO user_totp (user_id,secret,created_at) VALUES ($1,$2,$3) ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, confirmed_at = NULL, last_used_step = 0, created_at = EXCLUDED.created_at`
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantErr   bool
+	}{
+		{
+			name: "successful save",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(credential.UserID, credential.Secret, credential.CreatedAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WillReturnError(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupMFARepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.SaveTOTP(context.Background(), credential)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMFARepository_GetTOTP(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+	query := `SELECT user_id, secret, confirmed_at, last_used_step, created_at FROM user_totp WHERE user_id = $1`
+	columns := []string{"user_id", "secret", "confirmed_at", "last_used_step", "created_at"}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		wantConfirmed bool
+		wantErr       bool
+		expectedErr   error
+	}{
+		{
+			name: "confirmed credential",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).
+					WithArgs(userID).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(userID, "SECRET", now, int64(42), now))
+			},
+			wantConfirmed: true,
+		},
+		{
+			name: "pending credential",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).
+					WithArgs(userID).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(userID, "SECRET", nil, int64(0), now))
+			},
+		},
+		{
+			name: "not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).
+					WithArgs(userID).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr:     true,
+			expectedErr: repoerrors.ErrTOTPNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupMFARepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			got, err := repo.GetTOTP(context.Background(), userID)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.expectedErr != nil {
+					assert.Equal(t, tt.expectedErr, err)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "SECRET", got.Secret)
+				assert.Equal(t, tt.wantConfirmed, got.IsConfirmed())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMFARepository_UpdateLastUsedStep(t *testing.T) {
+	userID := uuid.New()
+	query := `UPDATE user_totp SET last_used_step = $1 WHERE user_id = $2 AND last_used_step < $3`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "step accepted",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(int64(100), userID, int64(100)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "step already used",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(int64(100), userID, int64(100)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr:     true,
+			expectedErr: repoerrors.ErrTOTPStepAlreadyUsed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupMFARepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.UpdateLastUsedStep(context.Background(), userID, 100)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMFARepository_ReplaceRecoveryCodes(t *testing.T) {
+	userID := uuid.New()
+	codes, _, err := models.NewRecoveryCodes(userID, 2)
+	assert.NoError(t, err)
+
+	db, mock, repo := setupMFARepoMock(t)
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`).
+		WithArgs(userID).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec(`INSERT INTO mfa_recovery_codes (id,user_id,code_hash,created_at) VALUES ($1,$2,$3,$4),($5,$6,$7,$8)`).
+		WithArgs(
+			codes[0].ID, userID, codes[0].CodeHash, codes[0].CreatedAt,
+			codes[1].ID, userID, codes[1].CodeHash, codes[1].CreatedAt,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err = repo.ReplaceRecoveryCodes(context.Background(), userID, codes)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFARepository_UseRecoveryCode(t *testing.T) {
+	userID := uuid.New()
+	query := `UPDATE mfa_recovery_codes SET used_at = $1 WHERE code_hash = $2 AND used_at IS NULL AND user_id = $3`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "code used",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(sqlmock.AnyArg(), "hash", userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "unknown or already used code",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(sqlmock.AnyArg(), "hash", userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr:     true,
+			expectedErr: repoerrors.ErrRecoveryCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupMFARepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.UseRecoveryCode(context.Background(), userID, "hash")
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMFARepository_LockChallenge(t *testing.T) {
+	db, mock, repo := setupMFARepoMock(t)
+	defer db.Close()
+
+	challenge := &models.MFAChallenge{ID: uuid.New(), UserID: uuid.New(), ExpiresAt: time.Now().Add(5 * time.Minute)}
+	usedAt := time.Now()
+
+	mock.ExpectExec(`DELETE FROM mfa_challenges WHERE user_id = $1 AND expires_at < $2`).
+		WithArgs(challenge.UserID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO mfa_challenges (id,user_id,expires_at) VALUES ($1,$2,$3) ON CONFLICT (id) DO NOTHING`).
+		WithArgs(challenge.ID, challenge.UserID, challenge.ExpiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT id, user_id, failed_attempts, used_at, expires_at FROM mfa_challenges WHERE id = $1 FOR UPDATE`).
+		WithArgs(challenge.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "failed_attempts", "used_at", "expires_at"}).
+			AddRow(challenge.ID, challenge.UserID, 2, usedAt, challenge.ExpiresAt))
+
+	locked, err := repo.LockChallenge(context.Background(), challenge)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, locked.FailedAttempts)
+	assert.Equal(t, usedAt, *locked.UsedAt)
+	assert.False(t, locked.CanAttempt(5))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFARepository_RecordChallengeFailure(t *testing.T) {
+	db, mock, repo := setupMFARepoMock(t)
+	defer db.Close()
+
+	id := uuid.New()
+	mock.ExpectExec(`UPDATE mfa_challenges SET failed_attempts = failed_attempts + 1 WHERE id = $1`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.RecordChallengeFailure(context.Background(), id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFARepository_UseChallenge(t *testing.T) {
+	db, mock, repo := setupMFARepoMock(t)
+	defer db.Close()
+
+	id := uuid.New()
+	mock.ExpectExec(`UPDATE mfa_challenges SET used_at = $1 WHERE id = $2 AND used_at IS NULL`).
+		WithArgs(sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.UseChallenge(context.Background(), id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
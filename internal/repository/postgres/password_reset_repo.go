@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type PasswordResetRepository struct {
+	db *tracedQuerier
+	sb squirrel.StatementBuilderType
+}
+
+func NewPasswordResetRepository(db Querier) interfaces.TxPasswordResetRepository {
+	return &PasswordResetRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *PasswordResetRepository) WithTx(tx *sql.Tx) interfaces.PasswordResetRepository {
+	return &PasswordResetRepository{
+		db: traced(tx),
+		sb: r.sb,
+	}
+}
+
+func (r *PasswordResetRepository) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	query := r.sb.Insert("password_reset_tokens").
+		Columns("id", "user_id", "token_hash", "expires_at", "created_at").
+		Values(token.ID, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for password reset token creation")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", token.UserID.String()).
+			Msg("Database error during password reset token creation")
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PasswordResetRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	query := r.sb.Select("id", "user_id", "token_hash", "expires_at", "used_at", "created_at").
+		From("password_reset_tokens").
+		Where(squirrel.Eq{"token_hash": tokenHash})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for password reset token retrieval")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	token := &models.PasswordResetToken{}
+	var usedAt sql.NullTime
+	err = r.db.QueryRowContext(ctx, sqlQuery, args...).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&usedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoerrors.ErrResetTokenNotFound
+		}
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while scanning password reset token row")
+		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return token, nil
+}
+
+func (r *PasswordResetRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	query := r.sb.Update("password_reset_tokens").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"id": id, "used_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for marking password reset token used")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("token_id", id.String()).
+			Msg("Database error while marking password reset token used")
+		return fmt.Errorf("failed to mark password reset token used: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repoerrors.ErrResetTokenNotFound
+	}
+
+	return nil
+}
+
+func (r *PasswordResetRepository) InvalidateByUserID(ctx context.Context, userID uuid.UUID) error {
+	query := r.sb.Update("password_reset_tokens").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID, "used_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for password reset tokens invalidation")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", userID.String()).
+			Msg("Database error while invalidating password reset tokens")
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func setupPasswordResetRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *PasswordResetRepository) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+
+	repo := &PasswordResetRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+
+	return db, mock, repo
+}
+
+func TestNewPasswordResetRepository(t *testing.T) {
+	db, _, _ := setupPasswordResetRepoMock(t)
+	defer db.Close()
+
+	repo := NewPasswordResetRepository(db)
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*interfaces.TxPasswordResetRepository)(nil), repo)
+}
+
+func TestPasswordResetRepository_Create(t *testing.T) {
+	now := time.Now()
+	token := &models.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		TokenHash: "hash",
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+	}
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantErr   bool
+	}{
+		{
+			name: "successful creation",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO password_reset_tokens (id,user_id,token_hash,expires_at,created_at) VALUES ($1,$2,$3,$4,$5)`).
+					WithArgs(token.ID, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO password_reset_tokens (id,user_id,token_hash,expires_at,created_at) VALUES ($1,$2,$3,$4,$5)`).
+					WillReturnError(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupPasswordResetRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.Create(context.Background(), token)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPasswordResetRepository_GetByTokenHash(t *testing.T) {
+	tokenID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	query := `SELECT id, user_id, token_hash, expires_at, used_at, created_at FROM password_reset_tokens WHERE token_hash = $1`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		wantUsed    bool
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "token found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "token_hash", "expires_at", "used_at", "created_at"}).
+					AddRow(tokenID, userID, "hash", now.Add(time.Hour), nil, now)
+				mock.ExpectQuery(query).WithArgs("hash").WillReturnRows(rows)
+			},
+		},
+		{
+			name: "used token found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "token_hash", "expires_at", "used_at", "created_at"}).
+					AddRow(tokenID, userID, "hash", now.Add(time.Hour), now, now)
+				mock.ExpectQuery(query).WithArgs("hash").WillReturnRows(rows)
+			},
+			wantUsed: true,
+		},
+		{
+			name: "token not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs("hash").WillReturnError(sql.ErrNoRows)
+			},
+			wantErr:     true,
+			expectedErr: repoerrors.ErrResetTokenNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupPasswordResetRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			got, err := repo.GetByTokenHash(context.Background(), "hash")
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tokenID, got.ID)
+				assert.Equal(t, userID, got.UserID)
+				assert.Equal(t, tt.wantUsed, got.UsedAt != nil)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPasswordResetRepository_MarkUsed(t *testing.T) {
+	tokenID := uuid.New()
+	query := `UPDATE password_reset_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "token marked used",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(sqlmock.AnyArg(), tokenID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "token already used",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(sqlmock.AnyArg(), tokenID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr:     true,
+			expectedErr: repoerrors.ErrResetTokenNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupPasswordResetRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.MarkUsed(context.Background(), tokenID)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPasswordResetRepository_InvalidateByUserID(t *testing.T) {
+	db, mock, repo := setupPasswordResetRepoMock(t)
+	defer db.Close()
+
+	userID := uuid.New()
+	mock.ExpectExec(`UPDATE password_reset_tokens SET used_at = $1 WHERE used_at IS NULL AND user_id = $2`).
+		WithArgs(sqlmock.AnyArg(), userID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.InvalidateByUserID(context.Background(), userID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
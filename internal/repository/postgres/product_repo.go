@@ -9,27 +9,28 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 )
 
 type ProductRepository struct {
-	db Querier
+	db *tracedQuerier
 	sb squirrel.StatementBuilderType
 }
 
 func NewProductRepository(db Querier) interfaces.TxProductRepository {
 	return &ProductRepository{
-		db: db,
+		db: traced(db),
 		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
 	}
 }
 
 func (r *ProductRepository) WithTx(tx *sql.Tx) interfaces.ProductRepository {
 	return &ProductRepository{
-		db: tx,
+		db: traced(tx),
 		sb: r.sb,
 	}
 }
@@ -41,7 +42,7 @@ func (r *ProductRepository) Create(ctx context.Context, product *models.Product)
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for product creation")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for product creation")
 		return fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
@@ -51,7 +52,7 @@ func (r *ProductRepository) Create(ctx context.Context, product *models.Product)
 			return repoerrors.ErrProductAlreadyExists
 		}
 
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("product_id", product.ID.String()).
 			Str("type", product.Type).
 			Str("reception_id", product.ReceptionID.String()).
@@ -72,7 +73,7 @@ func (r *ProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for product retrieval")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for product retrieval")
 		return nil, fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
@@ -91,7 +92,7 @@ func (r *ProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 			return nil, repoerrors.ErrProductNotFound
 		}
 
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("product_id", id.String()).
 			Msg("Database error while scanning product row")
 		return nil, fmt.Errorf("failed to get product by ID: %w", err)
@@ -109,7 +110,7 @@ func (r *ProductRepository) DeleteLastFromReception(ctx context.Context, recepti
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for retrieving last product")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for retrieving last product")
 		return fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
@@ -120,7 +121,7 @@ func (r *ProductRepository) DeleteLastFromReception(ctx context.Context, recepti
 			return repoerrors.ErrProductNotFound
 		}
 
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("reception_id", receptionID.String()).
 			Msg("Database error while retrieving last product")
 		return fmt.Errorf("failed to get last product from reception: %w", err)
@@ -131,13 +132,13 @@ func (r *ProductRepository) DeleteLastFromReception(ctx context.Context, recepti
 
 	sqlQuery, args, err = deleteQuery.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for product deletion")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for product deletion")
 		return fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
 	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
 	if err != nil {
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("product_id", productID.String()).
 			Msg("Database error while deleting product")
 		return fmt.Errorf("failed to delete last product: %w", err)
@@ -154,13 +155,13 @@ func (r *ProductRepository) GetByReceptionID(ctx context.Context, receptionID uu
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for product retrieval by reception ID")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for product retrieval by reception ID")
 		return nil, fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
 	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("reception_id", receptionID.String()).
 			Msg("Database error while querying products by reception ID")
 		return nil, fmt.Errorf("failed to query products: %w", err)
@@ -177,7 +178,7 @@ func (r *ProductRepository) GetByReceptionID(ctx context.Context, receptionID uu
 			&product.ReceptionID,
 		)
 		if err != nil {
-			log.Error().Err(err).
+			zerolog.Ctx(ctx).Error().Err(err).
 				Str("reception_id", receptionID.String()).
 				Msg("Database error while scanning product row")
 			return nil, fmt.Errorf("failed to scan product row: %w", err)
@@ -186,7 +187,7 @@ func (r *ProductRepository) GetByReceptionID(ctx context.Context, receptionID uu
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("reception_id", receptionID.String()).
 			Msg("Error while iterating product rows")
 		return nil, fmt.Errorf("error iterating through product rows: %w", err)
@@ -194,3 +195,44 @@ func (r *ProductRepository) GetByReceptionID(ctx context.Context, receptionID uu
 
 	return products, nil
 }
+
+// CountByPVZID возвращает количество товаров ПВЗ по типам, принятых не раньше since.
+// Более старые товары считаются выданными или возвращенными и места не занимают.
+func (r *ProductRepository) CountByPVZID(ctx context.Context, pvzID uuid.UUID, since time.Time) (map[string]int, error) {
+	sqlQuery, args, err := r.sb.Select("product.type", "COUNT(*)").
+		From("product").
+		Join("reception ON reception.id = product.reception_id").
+		Where(squirrel.Eq{"reception.pvz_id": pvzID}).
+		Where(squirrel.GtOrEq{"product.date_time": since}).
+		GroupBy("product.type").
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for PVZ occupancy")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("pvz_id", pvzID.String()).
+			Msg("Database error while counting PVZ products")
+		return nil, fmt.Errorf("failed to count products: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var productType string
+		var count int
+		if err := rows.Scan(&productType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan product count row: %w", err)
+		}
+		counts[productType] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through product count rows: %w", err)
+	}
+
+	return counts, nil
+}
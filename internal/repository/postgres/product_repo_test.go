@@ -23,7 +23,7 @@ func setupProductRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *ProductRepos
 	}
 
 	repo := &ProductRepository{
-		db: db,
+		db: traced(db),
 		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
 	}
 
@@ -375,3 +375,23 @@ func TestProductRepository_DeleteLastFromReception(t *testing.T) {
 		})
 	}
 }
+
+func TestProductRepository_CountByPVZID(t *testing.T) {
+	db, mock, repo := setupProductRepoMock(t)
+	defer db.Close()
+
+	pvzID := uuid.New()
+	since := time.Now().Add(-7 * 24 * time.Hour)
+
+	mock.ExpectQuery(`SELECT product.type, COUNT(*) FROM product JOIN reception ON reception.id = product.reception_id WHERE reception.pvz_id = $1 AND product.date_time >= $2 GROUP BY product.type`).
+		WithArgs(pvzID, since).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "count"}).
+			AddRow(models.ProductTypeElectronics, 12).
+			AddRow(models.ProductTypeShoes, 3))
+
+	counts, err := repo.CountByPVZID(context.Background(), pvzID, since)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{models.ProductTypeElectronics: 12, models.ProductTypeShoes: 3}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
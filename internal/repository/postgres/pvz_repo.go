@@ -1,46 +1,120 @@
 package postgres
 
 import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
 	"avito-backend-trainee-assignment-spring-2025/pkg/metrics"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+	"math"
+	"time"
 )
 
 type PVZRepository struct {
-	db Querier
+	db *tracedQuerier
 	sb squirrel.StatementBuilderType
 }
 
 func NewPVZRepository(db Querier) interfaces.TxPVZRepository {
 	return &PVZRepository{
-		db: db,
+		db: traced(db),
 		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
 	}
 }
 
 func (r *PVZRepository) WithTx(tx *sql.Tx) interfaces.PVZRepository {
 	return &PVZRepository{
-		db: tx,
+		db: traced(tx),
 		sb: r.sb,
 	}
 }
 
+// pvzColumns - колонки профиля ПВЗ в порядке pvzRow.dest.
+var pvzColumns = []string{"id", "registration_date", "city", "address", "latitude", "longitude", "phone", "working_hours", "status", "capacity", "version"}
+
+// pvzRow принимает строку pvz: координаты, расписание и вместимость могут быть NULL.
+type pvzRow struct {
+	pvz          models.PVZ
+	latitude     sql.NullFloat64
+	longitude    sql.NullFloat64
+	workingHours []byte
+	capacity     []byte
+}
+
+func (r *pvzRow) dest() []any {
+	return []any{
+		&r.pvz.ID,
+		&r.pvz.RegistrationDate,
+		&r.pvz.City,
+		&r.pvz.Address,
+		&r.latitude,
+		&r.longitude,
+		&r.pvz.Phone,
+		&r.workingHours,
+		&r.pvz.Status,
+		&r.capacity,
+		&r.pvz.Version,
+	}
+}
+
+func (r *pvzRow) toModel() (*models.PVZ, error) {
+	pvz := r.pvz
+	if r.latitude.Valid && r.longitude.Valid {
+		pvz.Latitude = &r.latitude.Float64
+		pvz.Longitude = &r.longitude.Float64
+	}
+	if len(r.workingHours) > 0 {
+		pvz.WorkingHours = &models.WorkingHours{}
+		if err := json.Unmarshal(r.workingHours, pvz.WorkingHours); err != nil {
+			return nil, fmt.Errorf("failed to decode PVZ working hours: %w", err)
+		}
+	}
+	if len(r.capacity) > 0 {
+		pvz.Capacity = &models.PVZCapacity{}
+		if err := json.Unmarshal(r.capacity, pvz.Capacity); err != nil {
+			return nil, fmt.Errorf("failed to decode PVZ capacity: %w", err)
+		}
+	}
+	return &pvz, nil
+}
+
+// jsonColumnValue кодирует значение для JSONB-колонки, nil сохраняется как NULL.
+func jsonColumnValue[T any](value *T, name string) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode PVZ %s: %w", name, err)
+	}
+	return data, nil
+}
+
 func (r *PVZRepository) Create(ctx context.Context, pvz *models.PVZ) error {
+	workingHours, err := jsonColumnValue(pvz.WorkingHours, "working hours")
+	if err != nil {
+		return err
+	}
+	capacity, err := jsonColumnValue(pvz.Capacity, "capacity")
+	if err != nil {
+		return err
+	}
+
 	query := r.sb.Insert("pvz").
-		Columns("id", "registration_date", "city").
-		Values(pvz.ID, pvz.RegistrationDate, pvz.City)
+		Columns(pvzColumns...).
+		Values(pvz.ID, pvz.RegistrationDate, pvz.City, pvz.Address, pvz.Latitude, pvz.Longitude, pvz.Phone, workingHours, pvz.Status, capacity, pvz.Version)
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for PVZ creation")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for PVZ creation")
 		return fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
@@ -50,7 +124,7 @@ func (r *PVZRepository) Create(ctx context.Context, pvz *models.PVZ) error {
 			return repoerrors.ErrPVZAlreadyExists
 		}
 
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("pvz_id", pvz.ID.String()).
 			Str("city", pvz.City).
 			Msg("Database error during PVZ creation")
@@ -64,135 +138,422 @@ func (r *PVZRepository) Create(ctx context.Context, pvz *models.PVZ) error {
 }
 
 func (r *PVZRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PVZ, error) {
-	query := r.sb.Select("id", "registration_date", "city").
+	return r.getByID(ctx, id, false)
+}
+
+// GetByIDForUpdate возвращает ПВЗ и блокирует его строку до конца транзакции, чтобы
+// параллельные изменения ПВЗ выполнялись по очереди.
+func (r *PVZRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.PVZ, error) {
+	return r.getByID(ctx, id, true)
+}
+
+func (r *PVZRepository) getByID(ctx context.Context, id uuid.UUID, forUpdate bool) (*models.PVZ, error) {
+	query := r.sb.Select(pvzColumns...).
 		From("pvz").
 		Where(squirrel.Eq{"id": id})
+	if forUpdate {
+		query = query.Suffix("FOR UPDATE")
+	}
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for PVZ retrieval")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for PVZ retrieval")
 		return nil, fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
 	row := r.db.QueryRowContext(ctx, sqlQuery, args...)
 
-	pvz := &models.PVZ{}
-	err = row.Scan(
-		&pvz.ID,
-		&pvz.RegistrationDate,
-		&pvz.City,
-	)
+	var pvzRow pvzRow
+	err = row.Scan(pvzRow.dest()...)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, repoerrors.ErrPVZNotFound
 		}
 
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("pvz_id", id.String()).
 			Msg("Database error while scanning PVZ row")
 		return nil, fmt.Errorf("failed to get PVZ by ID: %w", err)
 	}
 
-	return pvz, nil
+	return pvzRow.toModel()
 }
 
-func (r *PVZRepository) GetAll(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, int, error) {
-	countQuery := r.sb.Select("COUNT(*)").From("pvz")
-
-	selectQuery := r.sb.Select("id", "registration_date", "city").From("pvz")
-
-	if filter.StartDate != nil && filter.EndDate != nil {
-		countQuery = countQuery.Where(`
-			EXISTS (
-				SELECT 1 FROM reception 
-				WHERE reception.pvz_id = pvz.id 
-				AND reception.date_time BETWEEN ? AND ?
-			)`, filter.StartDate, filter.EndDate)
+// Update сохраняет изменяемые поля профиля ПВЗ и увеличивает его версию. Если ПВЗ
+// изменили после чтения pvz, возвращается ErrVersionMismatch.
+func (r *PVZRepository) Update(ctx context.Context, pvz *models.PVZ) error {
+	workingHours, err := jsonColumnValue(pvz.WorkingHours, "working hours")
+	if err != nil {
+		return err
+	}
+	capacity, err := jsonColumnValue(pvz.Capacity, "capacity")
+	if err != nil {
+		return err
+	}
 
-		selectQuery = selectQuery.Where(`
-			EXISTS (
-				SELECT 1 FROM reception 
-				WHERE reception.pvz_id = pvz.id 
-				AND reception.date_time BETWEEN ? AND ?
-			)`, filter.StartDate, filter.EndDate)
+	sqlQuery, args, err := r.sb.Update("pvz").
+		Set("address", pvz.Address).
+		Set("latitude", pvz.Latitude).
+		Set("longitude", pvz.Longitude).
+		Set("phone", pvz.Phone).
+		Set("working_hours", workingHours).
+		Set("status", pvz.Status).
+		Set("capacity", capacity).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": pvz.ID, "version": pvz.Version}).
+		ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for PVZ update")
+		return fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
-	countSql, countArgs, err := countQuery.ToSql()
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build count SQL query for PVZ list")
-		return nil, 0, fmt.Errorf("failed to build count SQL query: %w", err)
+		zerolog.Ctx(ctx).Error().Err(err).Str("pvz_id", pvz.ID.String()).Msg("Database error during PVZ update")
+		return fmt.Errorf("failed to update PVZ: %w", err)
 	}
 
-	var total int
-	err = r.db.QueryRowContext(ctx, countSql, countArgs...).Scan(&total)
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		log.Error().Err(err).Msg("Database error while counting PVZs")
-		return nil, 0, fmt.Errorf("failed to count PVZs: %w", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	// ПВЗ не удаляются, поэтому прочитанный ПВЗ не нашелся только из-за другой версии
+	if rowsAffected == 0 {
+		return apperrors.ErrVersionMismatch
+	}
+
+	pvz.Version++
+	return nil
+}
+
+// defaultPVZPageSize - размер страницы, если лимит в фильтре не задан.
+const defaultPVZPageSize = 10
+
+// pvzLastReceptionExpr - время последней приёмки ПВЗ. У ПВЗ без приёмок это начало
+// эпохи: ключ сортировки не должен быть NULL, иначе keyset-сравнение не работает.
+const pvzLastReceptionExpr = "COALESCE((SELECT MAX(reception.date_time) FROM reception WHERE reception.pvz_id = pvz.id), 'epoch'::timestamp)"
+
+// GetAll возвращает страницу ПВЗ, упорядоченную по ключу сортировки и id. С курсором
+// используется keyset-пагинация, без него - номер страницы. Чтобы понять, есть ли
+// следующая страница, запрашивается на одну запись больше лимита.
+func (r *PVZRepository) GetAll(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, *models.PVZPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultPVZPageSize
 	}
 
-	if filter.Limit > 0 {
-		selectQuery = selectQuery.Limit(uint64(filter.Limit))
+	conditions := pvzFilterConditions(filter)
+
+	page := &models.PVZPage{}
+
+	if filter.WithTotal {
+		countQuery := r.sb.Select("COUNT(*)").From("pvz")
+		for _, condition := range conditions {
+			countQuery = countQuery.Where(condition)
+		}
+
+		countSql, countArgs, err := countQuery.ToSql()
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build count SQL query for PVZ list")
+			return nil, nil, fmt.Errorf("failed to build count SQL query: %w", err)
+		}
+
+		var total int
+		err = r.db.QueryRowContext(ctx, countSql, countArgs...).Scan(&total)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while counting PVZs")
+			return nil, nil, fmt.Errorf("failed to count PVZs: %w", err)
+		}
+		page.Total = &total
+	}
+
+	sortBy := filter.SortField()
+	sortExpr := pvzSortExpr(sortBy)
+
+	cursor := filter.Cursor
+	backward := cursor != nil && cursor.Backward
+	// При движении назад строки выбираются в обратном порядке и затем разворачиваются.
+	desc := filter.SortDesc != backward
+
+	if cursor != nil {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		conditions = append(conditions, squirrel.Expr(
+			fmt.Sprintf("(%s, id) %s (?, ?)", sortExpr, op), cursor.Key, cursor.ID))
+	}
+
+	columns := append([]string{}, pvzColumns...)
+	if sortBy == models.PVZSortLastReception {
+		columns = append(columns, pvzLastReceptionExpr+" AS last_reception_at")
+	}
+
+	selectQuery := r.sb.Select(columns...).From("pvz")
+	for _, condition := range conditions {
+		selectQuery = selectQuery.Where(condition)
+	}
+
+	if desc {
+		selectQuery = selectQuery.OrderBy(sortExpr+" DESC", "id DESC")
 	} else {
-		selectQuery = selectQuery.Limit(10)
+		selectQuery = selectQuery.OrderBy(sortExpr, "id")
 	}
 
-	if filter.Page > 0 {
-		offset := uint64((filter.Page - 1) * filter.Limit)
-		selectQuery = selectQuery.Offset(offset)
+	selectQuery = selectQuery.Limit(uint64(limit + 1))
+
+	if cursor == nil && filter.Page > 0 {
+		selectQuery = selectQuery.Offset(uint64((filter.Page - 1) * limit))
 	}
 
 	sqlQuery, args, err := selectQuery.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for PVZ list")
-		return nil, 0, fmt.Errorf("failed to build SQL query: %w", err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for PVZ list")
+		return nil, nil, fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
 	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
-		log.Error().Err(err).Msg("Database error while querying PVZs")
-		return nil, 0, fmt.Errorf("failed to query PVZs: %w", err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while querying PVZs")
+		return nil, nil, fmt.Errorf("failed to query PVZs: %w", err)
 	}
 	defer rows.Close()
 
 	var pvzs []*models.PVZ
+	var keys []string
 	for rows.Next() {
-		pvz := &models.PVZ{}
-		err := rows.Scan(
-			&pvz.ID,
-			&pvz.RegistrationDate,
-			&pvz.City,
-		)
+		var pvzRow pvzRow
+		dest := pvzRow.dest()
+
+		var lastReceptionAt time.Time
+		if sortBy == models.PVZSortLastReception {
+			dest = append(dest, &lastReceptionAt)
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while scanning PVZ row")
+			return nil, nil, fmt.Errorf("failed to scan PVZ row: %w", err)
+		}
+
+		pvz, err := pvzRow.toModel()
 		if err != nil {
-			log.Error().Err(err).Msg("Database error while scanning PVZ row")
-			return nil, 0, fmt.Errorf("failed to scan PVZ row: %w", err)
+			return nil, nil, err
 		}
+
 		pvzs = append(pvzs, pvz)
+		keys = append(keys, pvzSortKey(sortBy, pvz, lastReceptionAt))
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Error().Err(err).Msg("Error while iterating PVZ rows")
-		return nil, 0, fmt.Errorf("error iterating through PVZ rows: %w", err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Error while iterating PVZ rows")
+		return nil, nil, fmt.Errorf("error iterating through PVZ rows: %w", err)
+	}
+
+	hasMore := len(pvzs) > limit
+	if hasMore {
+		pvzs = pvzs[:limit]
+		keys = keys[:limit]
+	}
+
+	if backward {
+		for i, j := 0, len(pvzs)-1; i < j; i, j = i+1, j-1 {
+			pvzs[i], pvzs[j] = pvzs[j], pvzs[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	// В направлении движения страница есть, если вернулась лишняя запись. В обратном
+	// направлении от курсора она есть всегда: курсор указывает на уже выданную запись.
+	hasNext, hasPrev := hasMore, filter.Page > 1
+	switch {
+	case backward:
+		hasNext, hasPrev = true, hasMore
+	case cursor != nil:
+		hasPrev = true
 	}
 
-	return pvzs, total, nil
+	if len(pvzs) > 0 {
+		last := len(pvzs) - 1
+		if hasNext {
+			page.NextCursor = pvzCursor(filter, keys[last], pvzs[last].ID, false)
+		}
+		if hasPrev {
+			page.PrevCursor = pvzCursor(filter, keys[0], pvzs[0].ID, true)
+		}
+	}
+
+	return pvzs, page, nil
 }
 
-func (r *PVZRepository) GetAllWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, int, error) {
-	pvzs, total, err := r.GetAll(ctx, filter)
+func pvzSortExpr(sortBy string) string {
+	switch sortBy {
+	case models.PVZSortCity:
+		return "city"
+	case models.PVZSortLastReception:
+		return pvzLastReceptionExpr
+	default:
+		return "registration_date"
+	}
+}
+
+func pvzSortKey(sortBy string, pvz *models.PVZ, lastReceptionAt time.Time) string {
+	switch sortBy {
+	case models.PVZSortCity:
+		return pvz.City
+	case models.PVZSortLastReception:
+		return lastReceptionAt.Format(time.RFC3339Nano)
+	default:
+		return pvz.RegistrationDate.Format(time.RFC3339Nano)
+	}
+}
+
+func pvzCursor(filter models.PVZFilter, key string, id uuid.UUID, backward bool) string {
+	return models.PVZCursor{
+		SortBy:   filter.SortField(),
+		SortDesc: filter.SortDesc,
+		Key:      key,
+		ID:       id,
+		Backward: backward,
+	}.Encode()
+}
+
+// pvzFilterConditions переводит фильтр в условия на таблицу pvz.
+func pvzFilterConditions(filter models.PVZFilter) []squirrel.Sqlizer {
+	var conditions []squirrel.Sqlizer
+
+	if len(filter.PVZIDs) > 0 {
+		conditions = append(conditions, squirrel.Eq{"id": filter.PVZIDs})
+	}
+
+	if len(filter.Cities) > 0 {
+		conditions = append(conditions, squirrel.Eq{"city": filter.Cities})
+	}
+
+	if filter.RegisteredFrom != nil {
+		conditions = append(conditions, squirrel.GtOrEq{"registration_date": *filter.RegisteredFrom})
+	}
+
+	if filter.RegisteredTo != nil {
+		conditions = append(conditions, squirrel.LtOrEq{"registration_date": *filter.RegisteredTo})
+	}
+
+	if filter.HasReceptionConditions() {
+		subquery := squirrel.Select("1").From("reception").Where("reception.pvz_id = pvz.id")
+		for _, condition := range receptionFilterConditions(filter) {
+			subquery = subquery.Where(condition)
+		}
+		conditions = append(conditions, squirrel.Expr("EXISTS (?)", subquery))
+	}
+
+	if filter.HasOpenReception != nil {
+		openReception := "EXISTS (SELECT 1 FROM reception WHERE reception.pvz_id = pvz.id AND reception.status = ?)"
+		if !*filter.HasOpenReception {
+			openReception = "NOT " + openReception
+		}
+		conditions = append(conditions, squirrel.Expr(openReception, models.ReceptionStatusInProgress))
+	}
+
+	return conditions
+}
+
+// receptionFilterConditions переводит фильтр в условия на таблицу reception. Ими же
+// ограничиваются приёмки, которые возвращаются вместе с ПВЗ.
+func receptionFilterConditions(filter models.PVZFilter) []squirrel.Sqlizer {
+	var conditions []squirrel.Sqlizer
+
+	if filter.StartDate != nil {
+		conditions = append(conditions, squirrel.GtOrEq{"reception.date_time": *filter.StartDate})
+	}
+
+	if filter.EndDate != nil {
+		conditions = append(conditions, squirrel.LtOrEq{"reception.date_time": *filter.EndDate})
+	}
+
+	if filter.ReceptionStatus != "" {
+		conditions = append(conditions, squirrel.Eq{"reception.status": filter.ReceptionStatus})
+	}
+
+	if filter.ProductType != "" {
+		conditions = append(conditions, squirrel.Expr(
+			"EXISTS (SELECT 1 FROM product WHERE product.reception_id = reception.id AND product.type = ?)",
+			filter.ProductType))
+	}
+
+	return conditions
+}
+
+// defaultProductsPerReception - сколько последних товаров приёмки попадает в список ПВЗ,
+// если лимит в фильтре не задан.
+const defaultProductsPerReception = 100
+
+// GetAllWithReceptions возвращает страницу ПВЗ с их приёмками. У каждой приёмки
+// заполняется количество товаров по типам и не больше ProductsLimit последних товаров;
+// при ProductCountsOnly сами товары не загружаются.
+func (r *PVZRepository) GetAllWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error) {
+	pvzs, page, err := r.GetAll(ctx, filter)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 
+	result := make([]models.PVZWithReceptions, 0, len(pvzs))
 	if len(pvzs) == 0 {
-		return []models.PVZWithReceptions{}, total, nil
+		return result, page, nil
 	}
 
-	var pvzIDs []interface{}
+	pvzIDs := make([]uuid.UUID, 0, len(pvzs))
 	for _, pvz := range pvzs {
 		pvzIDs = append(pvzIDs, pvz.ID)
 	}
 
+	receptions, err := r.getReceptions(ctx, pvzIDs, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pvzReceptions := make(map[uuid.UUID][]*models.Reception, len(pvzs))
+	receptionsByID := make(map[uuid.UUID]*models.Reception, len(receptions))
+	receptionIDs := make([]uuid.UUID, 0, len(receptions))
+
+	for _, reception := range receptions {
+		pvzReceptions[reception.PVZID] = append(pvzReceptions[reception.PVZID], reception)
+		receptionsByID[reception.ID] = reception
+		receptionIDs = append(receptionIDs, reception.ID)
+	}
+
+	if len(receptionIDs) > 0 {
+		if err := r.attachProductCounts(ctx, receptionsByID, receptionIDs); err != nil {
+			return nil, nil, err
+		}
+
+		if !filter.ProductCountsOnly {
+			limit := filter.ProductsLimit
+			if limit <= 0 {
+				limit = defaultProductsPerReception
+			}
+
+			if err := r.attachProducts(ctx, receptionsByID, receptionIDs, limit); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	for _, pvz := range pvzs {
+		pvzReceptionList := pvzReceptions[pvz.ID]
+		if pvzReceptionList == nil {
+			pvzReceptionList = []*models.Reception{}
+		}
+
+		result = append(result, models.PVZWithReceptions{
+			PVZ:        pvz,
+			Receptions: pvzReceptionList,
+		})
+	}
+
+	return result, page, nil
+}
+
+func (r *PVZRepository) getReceptions(ctx context.Context, pvzIDs []uuid.UUID, filter models.PVZFilter) ([]*models.Reception, error) {
 	receptionQuery := r.sb.Select(
 		"id",
 		"date_time",
@@ -202,27 +563,23 @@ func (r *PVZRepository) GetAllWithReceptions(ctx context.Context, filter models.
 		From("reception").
 		Where(squirrel.Eq{"pvz_id": pvzIDs})
 
-	if filter.StartDate != nil && filter.EndDate != nil {
-		receptionQuery = receptionQuery.Where(squirrel.And{
-			squirrel.GtOrEq{"date_time": filter.StartDate},
-			squirrel.LtOrEq{"date_time": filter.EndDate},
-		})
+	for _, condition := range receptionFilterConditions(filter) {
+		receptionQuery = receptionQuery.Where(condition)
 	}
 
-	receptionSQL, receptionArgs, err := receptionQuery.ToSql()
+	receptionSQL, receptionArgs, err := receptionQuery.OrderBy("date_time", "id").ToSql()
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to build receptions SQL query: %w", err)
+		return nil, fmt.Errorf("failed to build receptions SQL query: %w", err)
 	}
 
 	receptionRows, err := r.db.QueryContext(ctx, receptionSQL, receptionArgs...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query receptions: %w", err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while querying receptions for PVZ list")
+		return nil, fmt.Errorf("failed to query receptions: %w", err)
 	}
 	defer receptionRows.Close()
 
-	pvzReceptions := make(map[uuid.UUID][]*models.Reception)
-	var receptionIDs []interface{}
-
+	var receptions []*models.Reception
 	for receptionRows.Next() {
 		reception := &models.Reception{}
 		err := receptionRows.Scan(
@@ -232,50 +589,97 @@ func (r *PVZRepository) GetAllWithReceptions(ctx context.Context, filter models.
 			&reception.Status,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan reception row: %w", err)
+			return nil, fmt.Errorf("failed to scan reception row: %w", err)
 		}
 
-		pvzReceptions[reception.PVZID] = append(pvzReceptions[reception.PVZID], reception)
-		receptionIDs = append(receptionIDs, reception.ID)
+		receptions = append(receptions, reception)
 	}
 
 	if err = receptionRows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating through reception rows: %w", err)
+		return nil, fmt.Errorf("error iterating through reception rows: %w", err)
 	}
 
-	if len(receptionIDs) == 0 {
-		var result []models.PVZWithReceptions
-		for _, pvz := range pvzs {
-			result = append(result, models.PVZWithReceptions{
-				PVZ:        pvz,
-				Receptions: []*models.Reception{},
-			})
+	return receptions, nil
+}
+
+// attachProductCounts считает товары приёмок по типам на стороне БД.
+func (r *PVZRepository) attachProductCounts(ctx context.Context, receptions map[uuid.UUID]*models.Reception, receptionIDs []uuid.UUID) error {
+	countSQL, countArgs, err := r.sb.Select("reception_id", "type", "COUNT(*)").
+		From("product").
+		Where(squirrel.Eq{"reception_id": receptionIDs}).
+		GroupBy("reception_id", "type").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build product counts SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, countSQL, countArgs...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while counting products for PVZ list")
+		return fmt.Errorf("failed to count products: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var receptionID uuid.UUID
+		var productType string
+		var count int
+
+		if err := rows.Scan(&receptionID, &productType, &count); err != nil {
+			return fmt.Errorf("failed to scan product count row: %w", err)
 		}
-		return result, total, nil
+
+		reception, ok := receptions[receptionID]
+		if !ok {
+			continue
+		}
+		if reception.ProductCounts == nil {
+			reception.ProductCounts = make(map[string]int)
+		}
+		reception.ProductCounts[productType] = count
 	}
 
-	productQuery := r.sb.Select(
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating through product count rows: %w", err)
+	}
+
+	return nil
+}
+
+// attachProducts загружает не больше limit последних товаров каждой приёмки и
+// раскладывает их по приёмкам в хронологическом порядке.
+func (r *PVZRepository) attachProducts(ctx context.Context, receptions map[uuid.UUID]*models.Reception, receptionIDs []uuid.UUID, limit int) error {
+	ranked := squirrel.Select(
 		"id",
 		"date_time",
 		"type",
 		"reception_id",
+		"ROW_NUMBER() OVER (PARTITION BY reception_id ORDER BY date_time DESC, id DESC) AS rn",
 	).
 		From("product").
 		Where(squirrel.Eq{"reception_id": receptionIDs})
 
-	productSQL, productArgs, err := productQuery.ToSql()
+	productSQL, productArgs, err := r.sb.Select(
+		"id",
+		"date_time",
+		"type",
+		"reception_id",
+	).
+		FromSelect(ranked, "ranked").
+		Where(squirrel.LtOrEq{"rn": limit}).
+		OrderBy("date_time", "id").
+		ToSql()
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to build products SQL query: %w", err)
+		return fmt.Errorf("failed to build products SQL query: %w", err)
 	}
 
 	productRows, err := r.db.QueryContext(ctx, productSQL, productArgs...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query products: %w", err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while querying products for PVZ list")
+		return fmt.Errorf("failed to query products: %w", err)
 	}
 	defer productRows.Close()
 
-	receptionProducts := make(map[uuid.UUID][]models.Product)
-
 	for productRows.Next() {
 		product := models.Product{}
 		err := productRows.Scan(
@@ -285,34 +689,249 @@ func (r *PVZRepository) GetAllWithReceptions(ctx context.Context, filter models.
 			&product.ReceptionID,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan product row: %w", err)
+			return fmt.Errorf("failed to scan product row: %w", err)
 		}
 
-		receptionProducts[product.ReceptionID] = append(receptionProducts[product.ReceptionID], product)
+		if reception, ok := receptions[product.ReceptionID]; ok {
+			reception.Products = append(reception.Products, product)
+		}
 	}
 
 	if err = productRows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating through product rows: %w", err)
+		return fmt.Errorf("error iterating through product rows: %w", err)
+	}
+
+	return nil
+}
+
+// metersPerLatDegree - длина градуса широты, по ней строится ограничивающий прямоугольник.
+const metersPerLatDegree = 111320
+
+// pvzDistanceExpr - расстояние по формуле гаверсинусов от точки (?, ?) до ПВЗ в метрах,
+// 6371000 - средний радиус Земли. LEAST защищает ASIN от погрешности округления, дающей аргумент чуть больше 1.
+const pvzDistanceExpr = "2 * 6371000 * ASIN(LEAST(1, SQRT(" +
+	"POWER(SIN(RADIANS(latitude - ?) / 2), 2) + " +
+	"COS(RADIANS(?)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - ?) / 2), 2))))"
+
+// nearbyOpenBatchSize - сколько ПВЗ читается за раз при поиске работающих ПВЗ.
+const nearbyOpenBatchSize = 100
+
+// FindNearby возвращает ПВЗ в радиусе от точки, упорядоченные по расстоянию. Индекс по
+// координатам отсекает ПВЗ вне ограничивающего прямоугольника, точное расстояние
+// считается только для оставшихся. Выведенные из эксплуатации ПВЗ не возвращаются.
+// Расписание проверяется в Go, поэтому с OpenAt ПВЗ читаются порциями по расстоянию,
+// пока не наберется Limit работающих или ПВЗ в радиусе не закончатся.
+func (r *PVZRepository) FindNearby(ctx context.Context, filter models.NearbyFilter) ([]models.PVZWithDistance, error) {
+	if filter.OpenAt == nil {
+		result, _, err := r.findNearbyBatch(ctx, filter, nil, filter.Limit)
+		return result, err
 	}
 
-	for receptionID, products := range receptionProducts {
-		for pvzID, receptions := range pvzReceptions {
-			for i, reception := range receptions {
-				if reception.ID == receptionID {
-					pvzReceptions[pvzID][i].Products = products
-					break
-				}
+	batchSize := max(filter.Limit, nearbyOpenBatchSize)
+	result := make([]models.PVZWithDistance, 0)
+	var after *models.PVZWithDistance
+
+	for {
+		batch, more, err := r.findNearbyBatch(ctx, filter, after, batchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, nearby := range batch {
+			if !nearby.PVZ.IsOpenAt(*filter.OpenAt) {
+				continue
 			}
+
+			result = append(result, nearby)
+			if filter.Limit > 0 && len(result) == filter.Limit {
+				return result, nil
+			}
+		}
+
+		if !more {
+			return result, nil
 		}
+		after = &batch[len(batch)-1]
 	}
+}
 
-	var result []models.PVZWithReceptions
-	for _, pvz := range pvzs {
-		result = append(result, models.PVZWithReceptions{
-			PVZ:        pvz,
-			Receptions: pvzReceptions[pvz.ID],
-		})
+// findNearbyBatch читает до limit ПВЗ, следующих по расстоянию за after. more
+// сообщает, что порция заполнена целиком и за ней могут быть еще ПВЗ.
+func (r *PVZRepository) findNearbyBatch(ctx context.Context, filter models.NearbyFilter, after *models.PVZWithDistance, limit int) ([]models.PVZWithDistance, bool, error) {
+	inner := r.sb.Select(pvzColumns...).
+		Column(squirrel.Alias(squirrel.Expr(pvzDistanceExpr, filter.Latitude, filter.Latitude, filter.Longitude), "distance")).
+		From("pvz").
+		Where("latitude IS NOT NULL AND longitude IS NOT NULL").
+		Where(squirrel.NotEq{"status": models.PVZStatusDecommissioned})
+
+	for _, condition := range boundingBoxConditions(filter.Latitude, filter.Longitude, filter.RadiusMeters) {
+		inner = inner.Where(condition)
+	}
+
+	if len(filter.PVZIDs) > 0 {
+		inner = inner.Where(squirrel.Eq{"id": filter.PVZIDs})
+	}
+
+	query := r.sb.Select(append(append([]string{}, pvzColumns...), "distance")...).
+		FromSelect(inner, "nearby").
+		Where(squirrel.LtOrEq{"distance": filter.RadiusMeters}).
+		OrderBy("distance", "id")
+
+	if after != nil {
+		query = query.Where("(distance, id) > (?, ?)", after.DistanceMeters, after.PVZ.ID)
+	}
+
+	if limit > 0 {
+		query = query.Limit(uint64(limit))
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for nearby PVZ search")
+		return nil, false, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while searching nearby PVZs")
+		return nil, false, fmt.Errorf("failed to search nearby PVZs: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]models.PVZWithDistance, 0)
+	for rows.Next() {
+		var row pvzRow
+		var distance float64
+		if err := rows.Scan(append(row.dest(), &distance)...); err != nil {
+			return nil, false, fmt.Errorf("failed to scan nearby PVZ row: %w", err)
+		}
+
+		pvz, err := row.toModel()
+		if err != nil {
+			return nil, false, err
+		}
+
+		result = append(result, models.PVZWithDistance{PVZ: pvz, DistanceMeters: distance})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("error iterating through nearby PVZ rows: %w", err)
+	}
+
+	return result, limit > 0 && len(result) == limit, nil
+}
+
+// boundingBoxConditions ограничивает координаты ПВЗ прямоугольником вокруг окружности
+// поиска. Рядом с полюсами и линией перемены дат ограничение по долготе не ставится.
+func boundingBoxConditions(latitude, longitude, radiusMeters float64) []squirrel.Sqlizer {
+	latDelta := radiusMeters / metersPerLatDegree
+	conditions := []squirrel.Sqlizer{
+		squirrel.Expr("latitude BETWEEN ? AND ?", latitude-latDelta, latitude+latDelta),
+	}
+
+	if math.Abs(latitude)+latDelta >= 90 {
+		return conditions
+	}
+
+	lonDelta := latDelta / math.Cos((math.Abs(latitude)+latDelta)*math.Pi/180)
+	if longitude-lonDelta < -180 || longitude+lonDelta > 180 {
+		return conditions
+	}
+
+	return append(conditions, squirrel.Expr("longitude BETWEEN ? AND ?", longitude-lonDelta, longitude+lonDelta))
+}
+
+// ListUtilization возвращает ПВЗ с заданной вместимостью и количество товаров в них по
+// типам. Место занимают товары, принятые в приёмках ПВЗ не раньше since. Непустой
+// allowedIDs оставляет только эти ПВЗ.
+func (r *PVZRepository) ListUtilization(ctx context.Context, since time.Time, allowedIDs []uuid.UUID) ([]models.PVZUtilization, error) {
+	query := r.sb.Select(pvzColumns...).
+		From("pvz").
+		Where("capacity IS NOT NULL")
+
+	if len(allowedIDs) > 0 {
+		query = query.Where(squirrel.Eq{"id": allowedIDs})
+	}
+
+	sqlQuery, args, err := query.OrderBy("id").ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for PVZ utilization")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while querying PVZ with capacity")
+		return nil, fmt.Errorf("failed to query PVZ with capacity: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]models.PVZUtilization, 0)
+	pvzIndex := make(map[uuid.UUID]int)
+	var pvzIDs []uuid.UUID
+
+	for rows.Next() {
+		var row pvzRow
+		if err := rows.Scan(row.dest()...); err != nil {
+			return nil, fmt.Errorf("failed to scan PVZ row: %w", err)
+		}
+
+		pvz, err := row.toModel()
+		if err != nil {
+			return nil, err
+		}
+
+		pvzIndex[pvz.ID] = len(result)
+		pvzIDs = append(pvzIDs, pvz.ID)
+		result = append(result, models.PVZUtilization{PVZ: pvz, Occupancy: map[string]int{}})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through PVZ rows: %w", err)
+	}
+
+	if len(pvzIDs) == 0 {
+		return result, nil
+	}
+
+	countSQL, countArgs, err := r.sb.Select("reception.pvz_id", "product.type", "COUNT(*)").
+		From("product").
+		Join("reception ON reception.id = product.reception_id").
+		Where(squirrel.Eq{"reception.pvz_id": pvzIDs}).
+		Where(squirrel.GtOrEq{"product.date_time": since}).
+		GroupBy("reception.pvz_id", "product.type").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build occupancy SQL query: %w", err)
+	}
+
+	countRows, err := r.db.QueryContext(ctx, countSQL, countArgs...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while counting PVZ occupancy")
+		return nil, fmt.Errorf("failed to count PVZ occupancy: %w", err)
+	}
+	defer countRows.Close()
+
+	for countRows.Next() {
+		var pvzID uuid.UUID
+		var productType string
+		var count int
+		if err := countRows.Scan(&pvzID, &productType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan occupancy row: %w", err)
+		}
+
+		if i, ok := pvzIndex[pvzID]; ok {
+			result[i].Occupancy[productType] = count
+		}
+	}
+
+	if err = countRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through occupancy rows: %w", err)
+	}
+
+	for i := range result {
+		result[i].Usage = result[i].PVZ.Capacity.Usage(result[i].Occupancy)
 	}
 
-	return result, total, nil
+	return result, nil
 }
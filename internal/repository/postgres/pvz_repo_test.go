@@ -1,12 +1,15 @@
 package postgres
 
 import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,13 +26,18 @@ func setupPVZRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *PVZRepository) {
 	}
 
 	repo := &PVZRepository{
-		db: db,
+		db: traced(db),
 		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
 	}
 
 	return db, mock, repo
 }
 
+// pvzTestRow - строка pvz без заполненного профиля.
+func pvzTestRow(id uuid.UUID, registrationDate time.Time, city string) []driver.Value {
+	return []driver.Value{id, registrationDate, city, "", nil, nil, "", nil, models.PVZStatusActive, nil, 1}
+}
+
 func TestNewPVZRepository(t *testing.T) {
 	db, _, _ := setupPVZRepoMock(t)
 	defer db.Close()
@@ -56,10 +64,12 @@ func TestPVZRepository_Create(t *testing.T) {
 				ID:               pvzID,
 				RegistrationDate: now,
 				City:             models.CityMoscow,
+				Status:           models.PVZStatusActive,
+				Version:          1,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`INSERT INTO pvz (id,registration_date,city) VALUES ($1,$2,$3)`).
-					WithArgs(pvzID, now, models.CityMoscow).
+				mock.ExpectExec(`INSERT INTO pvz (id,registration_date,city,address,latitude,longitude,phone,working_hours,status,capacity,version) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`).
+					WithArgs(pvzID, now, models.CityMoscow, "", nil, nil, "", nil, models.PVZStatusActive, nil, 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			wantErr: false,
@@ -70,10 +80,12 @@ func TestPVZRepository_Create(t *testing.T) {
 				ID:               pvzID,
 				RegistrationDate: now,
 				City:             models.CityMoscow,
+				Status:           models.PVZStatusActive,
+				Version:          1,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`INSERT INTO pvz (id,registration_date,city) VALUES ($1,$2,$3)`).
-					WithArgs(pvzID, now, models.CityMoscow).
+				mock.ExpectExec(`INSERT INTO pvz (id,registration_date,city,address,latitude,longitude,phone,working_hours,status,capacity,version) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`).
+					WithArgs(pvzID, now, models.CityMoscow, "", nil, nil, "", nil, models.PVZStatusActive, nil, 1).
 					WillReturnError(errors.New(`pq: duplicate key value violates unique constraint "users_email_key"`))
 			},
 			wantErr:     true,
@@ -85,10 +97,12 @@ func TestPVZRepository_Create(t *testing.T) {
 				ID:               pvzID,
 				RegistrationDate: now,
 				City:             models.CityMoscow,
+				Status:           models.PVZStatusActive,
+				Version:          1,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`INSERT INTO pvz (id,registration_date,city) VALUES ($1,$2,$3)`).
-					WithArgs(pvzID, now, models.CityMoscow).
+				mock.ExpectExec(`INSERT INTO pvz (id,registration_date,city,address,latitude,longitude,phone,working_hours,status,capacity,version) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`).
+					WithArgs(pvzID, now, models.CityMoscow, "", nil, nil, "", nil, models.PVZStatusActive, nil, 1).
 					WillReturnError(errors.New("database error"))
 			},
 			wantErr: true,
@@ -134,10 +148,10 @@ func TestPVZRepository_GetByID(t *testing.T) {
 			name: "pvz found",
 			id:   pvzID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-					AddRow(pvzID, now, models.CityMoscow)
+				rows := sqlmock.NewRows(pvzColumns).
+					AddRow(pvzTestRow(pvzID, now, models.CityMoscow)...)
 
-				mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE id = $1`).
 					WithArgs(pvzID).
 					WillReturnRows(rows)
 			},
@@ -152,7 +166,7 @@ func TestPVZRepository_GetByID(t *testing.T) {
 			name: "pvz not found",
 			id:   pvzID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE id = $1`).
 					WithArgs(pvzID).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -164,7 +178,7 @@ func TestPVZRepository_GetByID(t *testing.T) {
 			name: "database error",
 			id:   pvzID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE id = $1`).
 					WithArgs(pvzID).
 					WillReturnError(errors.New("database error"))
 			},
@@ -219,19 +233,20 @@ func TestPVZRepository_GetAll(t *testing.T) {
 		{
 			name: "get all pvz without filters",
 			filter: models.PVZFilter{
-				Page:  1,
-				Limit: 10,
+				Page:      1,
+				Limit:     10,
+				WithTotal: true,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				countRows := sqlmock.NewRows([]string{"count"}).AddRow(2)
 				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz`).
 					WillReturnRows(countRows)
 
-				rows := sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-					AddRow(pvzID1, now, models.CityMoscow).
-					AddRow(pvzID2, now.Add(time.Hour), models.CitySaintPete)
+				rows := sqlmock.NewRows(pvzColumns).
+					AddRow(pvzTestRow(pvzID1, now, models.CityMoscow)...).
+					AddRow(pvzTestRow(pvzID2, now.Add(time.Hour), models.CitySaintPete)...)
 
-				mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz LIMIT 10 OFFSET 0`).
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz ORDER BY registration_date, id LIMIT 11 OFFSET 0`).
 					WillReturnRows(rows)
 			},
 			want: []*models.PVZ{
@@ -256,18 +271,19 @@ func TestPVZRepository_GetAll(t *testing.T) {
 				EndDate:   &endDate,
 				Page:      1,
 				Limit:     10,
+				WithTotal: true,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz WHERE EXISTS ( SELECT 1 FROM reception WHERE reception.pvz_id = pvz.id AND reception.date_time BETWEEN $1 AND $2 )`).
-					WithArgs(&startDate, &endDate).
+				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz WHERE EXISTS (SELECT 1 FROM reception WHERE reception.pvz_id = pvz.id AND reception.date_time >= $1 AND reception.date_time <= $2)`).
+					WithArgs(startDate, endDate).
 					WillReturnRows(countRows)
 
-				rows := sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-					AddRow(pvzID1, now, models.CityMoscow)
+				rows := sqlmock.NewRows(pvzColumns).
+					AddRow(pvzTestRow(pvzID1, now, models.CityMoscow)...)
 
-				mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz WHERE EXISTS ( SELECT 1 FROM reception WHERE reception.pvz_id = pvz.id AND reception.date_time BETWEEN $1 AND $2 ) LIMIT 10 OFFSET 0`).
-					WithArgs(&startDate, &endDate).
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE EXISTS (SELECT 1 FROM reception WHERE reception.pvz_id = pvz.id AND reception.date_time >= $1 AND reception.date_time <= $2) ORDER BY registration_date, id LIMIT 11 OFFSET 0`).
+					WithArgs(startDate, endDate).
 					WillReturnRows(rows)
 			},
 			want: []*models.PVZ{
@@ -283,18 +299,19 @@ func TestPVZRepository_GetAll(t *testing.T) {
 		{
 			name: "pagination test",
 			filter: models.PVZFilter{
-				Page:  2,
-				Limit: 1,
+				Page:      2,
+				Limit:     1,
+				WithTotal: true,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				countRows := sqlmock.NewRows([]string{"count"}).AddRow(2)
 				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz`).
 					WillReturnRows(countRows)
 
-				rows := sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-					AddRow(pvzID2, now.Add(time.Hour), models.CitySaintPete)
+				rows := sqlmock.NewRows(pvzColumns).
+					AddRow(pvzTestRow(pvzID2, now.Add(time.Hour), models.CitySaintPete)...)
 
-				mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz LIMIT 1 OFFSET 1`).
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz ORDER BY registration_date, id LIMIT 2 OFFSET 1`).
 					WillReturnRows(rows)
 			},
 			want: []*models.PVZ{
@@ -310,8 +327,9 @@ func TestPVZRepository_GetAll(t *testing.T) {
 		{
 			name: "database error on count",
 			filter: models.PVZFilter{
-				Page:  1,
-				Limit: 10,
+				Page:      1,
+				Limit:     10,
+				WithTotal: true,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz`).
@@ -330,7 +348,7 @@ func TestPVZRepository_GetAll(t *testing.T) {
 
 			tt.mockSetup(mock)
 
-			got, total, err := repo.GetAll(context.Background(), tt.filter)
+			got, page, err := repo.GetAll(context.Background(), tt.filter)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -339,7 +357,7 @@ func TestPVZRepository_GetAll(t *testing.T) {
 				}
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.wantTotal, total)
+				assert.Equal(t, tt.wantTotal, *page.Total)
 				assert.Len(t, got, len(tt.want))
 
 				for i, pvz := range tt.want {
@@ -354,6 +372,216 @@ func TestPVZRepository_GetAll(t *testing.T) {
 	}
 }
 
+func TestPVZRepository_GetAll_Cursor(t *testing.T) {
+	base := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+	key := func(t time.Time) string { return t.Format(time.RFC3339Nano) }
+	pvzID1, pvzID2, pvzID3 := uuid.New(), uuid.New(), uuid.New()
+	cursor := models.PVZCursor{SortBy: models.PVZSortRegistrationDate, Key: key(base), ID: pvzID1}
+
+	tests := []struct {
+		name      string
+		filter    models.PVZFilter
+		mockSetup func(sqlmock.Sqlmock)
+		wantIDs   []uuid.UUID
+		wantNext  *models.PVZCursor
+		wantPrev  *models.PVZCursor
+		wantCount bool
+	}{
+		{
+			name:   "first page without total",
+			filter: models.PVZFilter{Limit: 2},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz ORDER BY registration_date, id LIMIT 3`).
+					WillReturnRows(sqlmock.NewRows(pvzColumns).
+						AddRow(pvzTestRow(pvzID1, base, models.CityMoscow)...).
+						AddRow(pvzTestRow(pvzID2, base.Add(time.Hour), models.CityKazan)...).
+						AddRow(pvzTestRow(pvzID3, base.Add(2*time.Hour), models.CityKazan)...))
+			},
+			wantIDs:  []uuid.UUID{pvzID1, pvzID2},
+			wantNext: &models.PVZCursor{Key: key(base.Add(time.Hour)), ID: pvzID2},
+		},
+		{
+			name:   "forward from cursor on the last page",
+			filter: models.PVZFilter{Limit: 2, Cursor: &cursor},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE (registration_date, id) > ($1, $2) ORDER BY registration_date, id LIMIT 3`).
+					WithArgs(key(base), pvzID1).
+					WillReturnRows(sqlmock.NewRows(pvzColumns).
+						AddRow(pvzTestRow(pvzID2, base.Add(time.Hour), models.CityKazan)...).
+						AddRow(pvzTestRow(pvzID3, base.Add(2*time.Hour), models.CityKazan)...))
+			},
+			wantIDs:  []uuid.UUID{pvzID2, pvzID3},
+			wantPrev: &models.PVZCursor{Key: key(base.Add(time.Hour)), ID: pvzID2, Backward: true},
+		},
+		{
+			name: "backward from cursor returns rows in ascending order",
+			filter: models.PVZFilter{Limit: 1, Cursor: &models.PVZCursor{
+				SortBy: models.PVZSortRegistrationDate, Key: key(base.Add(2 * time.Hour)), ID: pvzID3, Backward: true,
+			}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE (registration_date, id) < ($1, $2) ORDER BY registration_date DESC, id DESC LIMIT 2`).
+					WithArgs(key(base.Add(2*time.Hour)), pvzID3).
+					WillReturnRows(sqlmock.NewRows(pvzColumns).
+						AddRow(pvzTestRow(pvzID2, base.Add(time.Hour), models.CityKazan)...).
+						AddRow(pvzTestRow(pvzID1, base, models.CityMoscow)...))
+			},
+			wantIDs:  []uuid.UUID{pvzID2},
+			wantNext: &models.PVZCursor{Key: key(base.Add(time.Hour)), ID: pvzID2},
+			wantPrev: &models.PVZCursor{Key: key(base.Add(time.Hour)), ID: pvzID2, Backward: true},
+		},
+		{
+			name:   "cursor with total",
+			filter: models.PVZFilter{Limit: 2, Cursor: &cursor, WithTotal: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE (registration_date, id) > ($1, $2) ORDER BY registration_date, id LIMIT 3`).
+					WithArgs(key(base), pvzID1).
+					WillReturnRows(sqlmock.NewRows(pvzColumns))
+			},
+			wantIDs:   []uuid.UUID{},
+			wantCount: true,
+		},
+		{
+			name: "city descending from cursor",
+			filter: models.PVZFilter{Limit: 1, SortBy: models.PVZSortCity, SortDesc: true, Cursor: &models.PVZCursor{
+				SortBy: models.PVZSortCity, SortDesc: true, Key: models.CitySaintPete, ID: pvzID3,
+			}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE (city, id) < ($1, $2) ORDER BY city DESC, id DESC LIMIT 2`).
+					WithArgs(models.CitySaintPete, pvzID3).
+					WillReturnRows(sqlmock.NewRows(pvzColumns).
+						AddRow(pvzTestRow(pvzID1, base, models.CityMoscow)...).
+						AddRow(pvzTestRow(pvzID2, base, models.CityKazan)...))
+			},
+			wantIDs:  []uuid.UUID{pvzID1},
+			wantNext: &models.PVZCursor{Key: models.CityMoscow, ID: pvzID1},
+			wantPrev: &models.PVZCursor{Key: models.CityMoscow, ID: pvzID1, Backward: true},
+		},
+		{
+			name:   "last reception first",
+			filter: models.PVZFilter{Limit: 1, SortBy: models.PVZSortLastReception, SortDesc: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version, ` + pvzLastReceptionExpr + ` AS last_reception_at FROM pvz ORDER BY ` + pvzLastReceptionExpr + ` DESC, id DESC LIMIT 2`).
+					WillReturnRows(sqlmock.NewRows(append(pvzColumns, "last_reception_at")).
+						AddRow(append(pvzTestRow(pvzID2, base, models.CityKazan), base.Add(3*time.Hour))...).
+						AddRow(append(pvzTestRow(pvzID1, base, models.CityMoscow), base.Add(time.Hour))...))
+			},
+			wantIDs:  []uuid.UUID{pvzID2},
+			wantNext: &models.PVZCursor{Key: key(base.Add(3 * time.Hour)), ID: pvzID2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupPVZRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			got, page, err := repo.GetAll(context.Background(), tt.filter)
+			assert.NoError(t, err)
+
+			gotIDs := make([]uuid.UUID, 0, len(got))
+			for _, pvz := range got {
+				gotIDs = append(gotIDs, pvz.ID)
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+			assert.Equal(t, tt.wantCount, page.Total != nil)
+
+			assertCursor(t, tt.filter, tt.wantNext, page.NextCursor)
+			assertCursor(t, tt.filter, tt.wantPrev, page.PrevCursor)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepository_GetAll_Filters(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 3, 0)
+	hasOpen := true
+	noOpen := false
+	allowedID := uuid.New()
+
+	tests := []struct {
+		name      string
+		filter    models.PVZFilter
+		wantQuery string
+		wantArgs  []driver.Value
+	}{
+		{
+			name:      "cities and registration range",
+			filter:    models.PVZFilter{Cities: []string{models.CityMoscow, models.CityKazan}, RegisteredFrom: &from, RegisteredTo: &to},
+			wantQuery: `SELECT COUNT(*) FROM pvz WHERE city IN ($1,$2) AND registration_date >= $3 AND registration_date <= $4`,
+			wantArgs:  []driver.Value{models.CityMoscow, models.CityKazan, from, to},
+		},
+		{
+			name:      "only one bound of reception range",
+			filter:    models.PVZFilter{StartDate: &from},
+			wantQuery: `SELECT COUNT(*) FROM pvz WHERE EXISTS (SELECT 1 FROM reception WHERE reception.pvz_id = pvz.id AND reception.date_time >= $1)`,
+			wantArgs:  []driver.Value{from},
+		},
+		{
+			name:      "reception status and product type",
+			filter:    models.PVZFilter{ReceptionStatus: models.ReceptionStatusClosed, ProductType: models.ProductTypeShoes},
+			wantQuery: `SELECT COUNT(*) FROM pvz WHERE EXISTS (SELECT 1 FROM reception WHERE reception.pvz_id = pvz.id AND reception.status = $1 AND EXISTS (SELECT 1 FROM product WHERE product.reception_id = reception.id AND product.type = $2))`,
+			wantArgs:  []driver.Value{models.ReceptionStatusClosed, models.ProductTypeShoes},
+		},
+		{
+			name:      "has open reception",
+			filter:    models.PVZFilter{HasOpenReception: &hasOpen},
+			wantQuery: `SELECT COUNT(*) FROM pvz WHERE EXISTS (SELECT 1 FROM reception WHERE reception.pvz_id = pvz.id AND reception.status = $1)`,
+			wantArgs:  []driver.Value{models.ReceptionStatusInProgress},
+		},
+		{
+			name:      "without open reception",
+			filter:    models.PVZFilter{HasOpenReception: &noOpen},
+			wantQuery: `SELECT COUNT(*) FROM pvz WHERE NOT EXISTS (SELECT 1 FROM reception WHERE reception.pvz_id = pvz.id AND reception.status = $1)`,
+			wantArgs:  []driver.Value{models.ReceptionStatusInProgress},
+		},
+		{
+			name:      "allowed PVZ ids",
+			filter:    models.PVZFilter{PVZIDs: []uuid.UUID{allowedID}, Cities: []string{models.CityMoscow}},
+			wantQuery: `SELECT COUNT(*) FROM pvz WHERE id IN ($1) AND city IN ($2)`,
+			wantArgs:  []driver.Value{allowedID, models.CityMoscow},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupPVZRepoMock(t)
+			defer db.Close()
+
+			tt.filter.WithTotal = true
+			mock.ExpectQuery(tt.wantQuery).
+				WithArgs(tt.wantArgs...).
+				WillReturnError(errors.New("stop"))
+
+			_, _, err := repo.GetAll(context.Background(), tt.filter)
+			assert.Error(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func assertCursor(t *testing.T, filter models.PVZFilter, want *models.PVZCursor, got string) {
+	t.Helper()
+
+	if want == nil {
+		assert.Empty(t, got)
+		return
+	}
+
+	decoded, err := models.DecodePVZCursor(got)
+	assert.NoError(t, err)
+	assert.Equal(t, filter.SortField(), decoded.SortBy)
+	assert.Equal(t, filter.SortDesc, decoded.SortDesc)
+	assert.Equal(t, want.Key, decoded.Key)
+	assert.Equal(t, want.ID, decoded.ID)
+	assert.Equal(t, want.Backward, decoded.Backward)
+}
+
 func TestPVZRepository_GetAllWithReceptions(t *testing.T) {
 	pvzID1 := uuid.New()
 	receptionID1 := uuid.New()
@@ -372,32 +600,41 @@ func TestPVZRepository_GetAllWithReceptions(t *testing.T) {
 		{
 			name: "get pvz with receptions and products",
 			filter: models.PVZFilter{
-				Page:  1,
-				Limit: 10,
+				Page:          1,
+				Limit:         10,
+				WithTotal:     true,
+				ProductsLimit: 1,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
 				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz`).
 					WillReturnRows(countRows)
 
-				pvzRows := sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-					AddRow(pvzID1, now, models.CityMoscow)
+				pvzRows := sqlmock.NewRows(pvzColumns).
+					AddRow(pvzTestRow(pvzID1, now, models.CityMoscow)...)
 
-				mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz LIMIT 10 OFFSET 0`).
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz ORDER BY registration_date, id LIMIT 11 OFFSET 0`).
 					WillReturnRows(pvzRows)
 
 				receptionRows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
 					AddRow(receptionID1, now, pvzID1, models.ReceptionStatusInProgress)
 
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id IN ($1)`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id IN ($1) ORDER BY date_time, id`).
 					WithArgs(sqlmock.AnyArg()).
 					WillReturnRows(receptionRows)
 
+				countRows = sqlmock.NewRows([]string{"reception_id", "type", "count"}).
+					AddRow(receptionID1, models.ProductTypeElectronics, 3)
+
+				mock.ExpectQuery(`SELECT reception_id, type, COUNT(*) FROM product WHERE reception_id IN ($1) GROUP BY reception_id, type`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(countRows)
+
 				productRows := sqlmock.NewRows([]string{"id", "date_time", "type", "reception_id"}).
 					AddRow(productID1, now, models.ProductTypeElectronics, receptionID1)
 
-				mock.ExpectQuery(`SELECT id, date_time, type, reception_id FROM product WHERE reception_id IN ($1)`).
-					WithArgs(sqlmock.AnyArg()).
+				mock.ExpectQuery(`SELECT id, date_time, type, reception_id FROM (SELECT id, date_time, type, reception_id, ROW_NUMBER() OVER (PARTITION BY reception_id ORDER BY date_time DESC, id DESC) AS rn FROM product WHERE reception_id IN ($1)) AS ranked WHERE rn <= $2 ORDER BY date_time, id`).
+					WithArgs(sqlmock.AnyArg(), 1).
 					WillReturnRows(productRows)
 			},
 			want: []models.PVZWithReceptions{
@@ -413,6 +650,9 @@ func TestPVZRepository_GetAllWithReceptions(t *testing.T) {
 							DateTime: now,
 							PVZID:    pvzID1,
 							Status:   models.ReceptionStatusInProgress,
+							ProductCounts: map[string]int{
+								models.ProductTypeElectronics: 3,
+							},
 							Products: []models.Product{
 								{
 									ID:          productID1,
@@ -428,26 +668,85 @@ func TestPVZRepository_GetAllWithReceptions(t *testing.T) {
 			wantTotal: 1,
 			wantErr:   false,
 		},
+		{
+			name: "product counts only",
+			filter: models.PVZFilter{
+				Page:              1,
+				Limit:             10,
+				WithTotal:         true,
+				ProductCountsOnly: true,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
+				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz`).
+					WillReturnRows(countRows)
+
+				pvzRows := sqlmock.NewRows(pvzColumns).
+					AddRow(pvzTestRow(pvzID1, now, models.CityMoscow)...)
+
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz ORDER BY registration_date, id LIMIT 11 OFFSET 0`).
+					WillReturnRows(pvzRows)
+
+				receptionRows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
+					AddRow(receptionID1, now, pvzID1, models.ReceptionStatusClosed)
+
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id IN ($1) ORDER BY date_time, id`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(receptionRows)
+
+				countRows = sqlmock.NewRows([]string{"reception_id", "type", "count"}).
+					AddRow(receptionID1, models.ProductTypeElectronics, 2).
+					AddRow(receptionID1, models.ProductTypeShoes, 5)
+
+				mock.ExpectQuery(`SELECT reception_id, type, COUNT(*) FROM product WHERE reception_id IN ($1) GROUP BY reception_id, type`).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(countRows)
+			},
+			want: []models.PVZWithReceptions{
+				{
+					PVZ: &models.PVZ{
+						ID:               pvzID1,
+						RegistrationDate: now,
+						City:             models.CityMoscow,
+					},
+					Receptions: []*models.Reception{
+						{
+							ID:       receptionID1,
+							DateTime: now,
+							PVZID:    pvzID1,
+							Status:   models.ReceptionStatusClosed,
+							ProductCounts: map[string]int{
+								models.ProductTypeElectronics: 2,
+								models.ProductTypeShoes:       5,
+							},
+						},
+					},
+				},
+			},
+			wantTotal: 1,
+			wantErr:   false,
+		},
 		{
 			name: "get pvz with no receptions",
 			filter: models.PVZFilter{
-				Page:  1,
-				Limit: 10,
+				Page:      1,
+				Limit:     10,
+				WithTotal: true,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
 				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz`).
 					WillReturnRows(countRows)
 
-				pvzRows := sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-					AddRow(pvzID1, now, models.CityMoscow)
+				pvzRows := sqlmock.NewRows(pvzColumns).
+					AddRow(pvzTestRow(pvzID1, now, models.CityMoscow)...)
 
-				mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz LIMIT 10 OFFSET 0`).
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz ORDER BY registration_date, id LIMIT 11 OFFSET 0`).
 					WillReturnRows(pvzRows)
 
 				receptionRows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"})
 
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id IN ($1)`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id IN ($1) ORDER BY date_time, id`).
 					WithArgs(sqlmock.AnyArg()).
 					WillReturnRows(receptionRows)
 			},
@@ -467,8 +766,9 @@ func TestPVZRepository_GetAllWithReceptions(t *testing.T) {
 		{
 			name: "error fetching pvz",
 			filter: models.PVZFilter{
-				Page:  1,
-				Limit: 10,
+				Page:      1,
+				Limit:     10,
+				WithTotal: true,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz`).
@@ -481,21 +781,22 @@ func TestPVZRepository_GetAllWithReceptions(t *testing.T) {
 		{
 			name: "error fetching receptions",
 			filter: models.PVZFilter{
-				Page:  1,
-				Limit: 10,
+				Page:      1,
+				Limit:     10,
+				WithTotal: true,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
 				mock.ExpectQuery(`SELECT COUNT(*) FROM pvz`).
 					WillReturnRows(countRows)
 
-				pvzRows := sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-					AddRow(pvzID1, now, models.CityMoscow)
+				pvzRows := sqlmock.NewRows(pvzColumns).
+					AddRow(pvzTestRow(pvzID1, now, models.CityMoscow)...)
 
-				mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz LIMIT 10 OFFSET 0`).
+				mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz ORDER BY registration_date, id LIMIT 11 OFFSET 0`).
 					WillReturnRows(pvzRows)
 
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id IN ($1)`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id IN ($1) ORDER BY date_time, id`).
 					WithArgs(sqlmock.AnyArg()).
 					WillReturnError(errors.New("database error"))
 			},
@@ -512,7 +813,7 @@ func TestPVZRepository_GetAllWithReceptions(t *testing.T) {
 
 			tt.mockSetup(mock)
 
-			got, total, err := repo.GetAllWithReceptions(context.Background(), tt.filter)
+			got, page, err := repo.GetAllWithReceptions(context.Background(), tt.filter)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -521,7 +822,7 @@ func TestPVZRepository_GetAllWithReceptions(t *testing.T) {
 				}
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.wantTotal, total)
+				assert.Equal(t, tt.wantTotal, *page.Total)
 				assert.Len(t, got, len(tt.want))
 
 				if len(tt.want) > 0 {
@@ -539,6 +840,7 @@ func TestPVZRepository_GetAllWithReceptions(t *testing.T) {
 								assert.Equal(t, reception.Status, got[i].Receptions[j].Status)
 								assert.WithinDuration(t, reception.DateTime, got[i].Receptions[j].DateTime, time.Second)
 
+								assert.Equal(t, reception.ProductCounts, got[i].Receptions[j].ProductCounts)
 								assert.Len(t, got[i].Receptions[j].Products, len(reception.Products))
 
 								for k, product := range reception.Products {
@@ -557,3 +859,277 @@ func TestPVZRepository_GetAllWithReceptions(t *testing.T) {
 		})
 	}
 }
+
+func TestPVZRepository_GetByID_Profile(t *testing.T) {
+	db, mock, repo := setupPVZRepoMock(t)
+	defer db.Close()
+
+	pvzID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE id = $1`).
+		WithArgs(pvzID).
+		WillReturnRows(sqlmock.NewRows(pvzColumns).AddRow(
+			pvzID, now, models.CityKazan, "ул. Баумана, 1", 55.79, 49.12, "+78431234567",
+			[]byte(`{"weekly":[{"day":"monday","open":"09:00","close":"21:00"}]}`),
+			models.PVZStatusTemporarilyClosed,
+			[]byte(`{"total":300,"byType":{"электроника":50}}`),
+			4,
+		))
+
+	pvz, err := repo.GetByID(context.Background(), pvzID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ул. Баумана, 1", pvz.Address)
+	assert.Equal(t, 55.79, *pvz.Latitude)
+	assert.Equal(t, 49.12, *pvz.Longitude)
+	assert.Equal(t, "+78431234567", pvz.Phone)
+	assert.Equal(t, []models.DayHours{{Day: models.Monday, Open: "09:00", Close: "21:00"}}, pvz.WorkingHours.Weekly)
+	assert.True(t, pvz.IsClosed())
+	assert.Equal(t, &models.PVZCapacity{Total: 300, ByType: map[string]int{models.ProductTypeElectronics: 50}}, pvz.Capacity)
+	assert.Equal(t, int64(4), pvz.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_Update(t *testing.T) {
+	pvzID := uuid.New()
+	latitude, longitude := 55.75, 37.61
+
+	pvz := &models.PVZ{
+		ID:           pvzID,
+		City:         models.CityMoscow,
+		Address:      "ул. Тверская, 1",
+		Latitude:     &latitude,
+		Longitude:    &longitude,
+		WorkingHours: &models.WorkingHours{Weekly: []models.DayHours{{Day: models.Sunday, Open: "10:00", Close: "18:00"}}},
+		Status:       models.PVZStatusActive,
+		Capacity:     &models.PVZCapacity{Total: 500, ByType: map[string]int{models.ProductTypeShoes: 100}},
+		Version:      3,
+	}
+	workingHours := []byte(`{"weekly":[{"day":"sunday","open":"10:00","close":"18:00"}]}`)
+	capacity := []byte(`{"total":500,"byType":{"обувь":100}}`)
+
+	tests := []struct {
+		name            string
+		result          driver.Result
+		expectedErr     error
+		expectedVersion int64
+	}{
+		{name: "successful update", result: sqlmock.NewResult(0, 1), expectedVersion: 4},
+		{name: "pvz modified concurrently", result: sqlmock.NewResult(0, 0), expectedErr: apperrors.ErrVersionMismatch, expectedVersion: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupPVZRepoMock(t)
+			defer db.Close()
+
+			updated := *pvz
+
+			mock.ExpectExec(`UPDATE pvz SET address = $1, latitude = $2, longitude = $3, phone = $4, working_hours = $5, status = $6, capacity = $7, version = version + 1 WHERE id = $8 AND version = $9`).
+				WithArgs("ул. Тверская, 1", latitude, longitude, "", workingHours, models.PVZStatusActive, capacity, pvzID, 3).
+				WillReturnResult(tt.result)
+
+			err := repo.Update(context.Background(), &updated)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedVersion, updated.Version)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepository_FindNearby(t *testing.T) {
+	const nearbyQuery = `SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version, distance FROM (SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version, (2 * 6371000 * ASIN(LEAST(1, SQRT(POWER(SIN(RADIANS(latitude - $1) / 2), 2) + COS(RADIANS($2)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - $3) / 2), 2))))) AS distance FROM pvz WHERE latitude IS NOT NULL AND longitude IS NOT NULL AND status <> $4 AND latitude BETWEEN $5 AND $6 AND longitude BETWEEN $7 AND $8) AS nearby WHERE distance <= $9 ORDER BY distance, id`
+
+	near, far := uuid.New(), uuid.New()
+	now := time.Now()
+	weekly := []byte(`{"weekly":[{"day":"monday","open":"09:00","close":"21:00"}]}`)
+	// Понедельник 10:00 по Москве
+	mondayMorning := time.Date(2025, 4, 14, 7, 0, 0, 0, time.UTC)
+
+	nearbyRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(append(append([]string{}, pvzColumns...), "distance")).
+			AddRow(near, now, models.CityMoscow, "", 55.751, 37.618, "", nil, models.PVZStatusActive, nil, 1, 120.5).
+			AddRow(far, now, models.CityMoscow, "", 55.76, 37.63, "", weekly, models.PVZStatusActive, nil, 1, 1480.0)
+	}
+	args := []driver.Value{55.75, 55.75, 37.62, models.PVZStatusDecommissioned,
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 2000.0}
+
+	t.Run("sorted by distance with limit", func(t *testing.T) {
+		db, mock, repo := setupPVZRepoMock(t)
+		defer db.Close()
+
+		mock.ExpectQuery(nearbyQuery + ` LIMIT 10`).
+			WithArgs(args...).
+			WillReturnRows(nearbyRows())
+
+		result, err := repo.FindNearby(context.Background(), models.NearbyFilter{
+			Latitude: 55.75, Longitude: 37.62, RadiusMeters: 2000, Limit: 10,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, near, result[0].PVZ.ID)
+		assert.Equal(t, 120.5, result[0].DistanceMeters)
+		assert.Equal(t, 55.76, *result[1].PVZ.Latitude)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("open now filters by working hours before limit", func(t *testing.T) {
+		db, mock, repo := setupPVZRepoMock(t)
+		defer db.Close()
+
+		mock.ExpectQuery(nearbyQuery + ` LIMIT 100`).
+			WithArgs(args...).
+			WillReturnRows(nearbyRows())
+
+		result, err := repo.FindNearby(context.Background(), models.NearbyFilter{
+			Latitude: 55.75, Longitude: 37.62, RadiusMeters: 2000, Limit: 1, OpenAt: &mondayMorning,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, far, result[0].PVZ.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("open now reads the next batch after the last PVZ", func(t *testing.T) {
+		db, mock, repo := setupPVZRepoMock(t)
+		defer db.Close()
+
+		closedRows := sqlmock.NewRows(append(append([]string{}, pvzColumns...), "distance"))
+		for i := range nearbyOpenBatchSize {
+			closedRows.AddRow(uuid.New(), now, models.CityMoscow, "", 55.751, 37.618, "", nil, models.PVZStatusActive, nil, 1, float64(i))
+		}
+		mock.ExpectQuery(nearbyQuery + ` LIMIT 100`).
+			WithArgs(args...).
+			WillReturnRows(closedRows)
+
+		mock.ExpectQuery(strings.Replace(nearbyQuery, ` ORDER BY`, ` AND (distance, id) > ($10, $11) ORDER BY`, 1) + ` LIMIT 100`).
+			WithArgs(append(append([]driver.Value{}, args...), float64(nearbyOpenBatchSize-1), sqlmock.AnyArg())...).
+			WillReturnRows(sqlmock.NewRows(append(append([]string{}, pvzColumns...), "distance")).
+				AddRow(far, now, models.CityMoscow, "", 55.76, 37.63, "", weekly, models.PVZStatusActive, nil, 1, 1480.0))
+
+		result, err := repo.FindNearby(context.Background(), models.NearbyFilter{
+			Latitude: 55.75, Longitude: 37.62, RadiusMeters: 2000, Limit: 10, OpenAt: &mondayMorning,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, far, result[0].PVZ.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("restricted to PVZ ids", func(t *testing.T) {
+		db, mock, repo := setupPVZRepoMock(t)
+		defer db.Close()
+
+		restrictedQuery := strings.Replace(nearbyQuery, `) AS nearby WHERE distance <= $9`, ` AND id IN ($9)) AS nearby WHERE distance <= $10`, 1)
+		mock.ExpectQuery(restrictedQuery + ` LIMIT 10`).
+			WithArgs(append(append([]driver.Value{}, args[:8]...), near, 2000.0)...).
+			WillReturnRows(sqlmock.NewRows(append(append([]string{}, pvzColumns...), "distance")).
+				AddRow(near, now, models.CityMoscow, "", 55.751, 37.618, "", nil, models.PVZStatusActive, nil, 1, 120.5))
+
+		result, err := repo.FindNearby(context.Background(), models.NearbyFilter{
+			Latitude: 55.75, Longitude: 37.62, RadiusMeters: 2000, Limit: 10, PVZIDs: []uuid.UUID{near},
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, near, result[0].PVZ.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBoundingBoxConditions(t *testing.T) {
+	conditions := boundingBoxConditions(55.75, 37.62, 5000)
+	assert.Len(t, conditions, 2)
+
+	query, args, err := conditions[0].ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "latitude BETWEEN ? AND ?", query)
+	assert.InDelta(t, 55.705, args[0], 0.001)
+	assert.InDelta(t, 55.795, args[1], 0.001)
+
+	_, args, err = conditions[1].ToSql()
+	assert.NoError(t, err)
+	// Градус долготы на широте Москвы примерно в 1.78 раза короче градуса широты
+	assert.InDelta(t, 37.54, args[0], 0.01)
+	assert.InDelta(t, 37.70, args[1], 0.01)
+
+	assert.Len(t, boundingBoxConditions(89.99, 0, 5000), 1, "near the pole only latitude is bounded")
+	assert.Len(t, boundingBoxConditions(0, 179.99, 5000), 1, "across the antimeridian only latitude is bounded")
+}
+
+func TestPVZRepository_GetByIDForUpdate(t *testing.T) {
+	db, mock, repo := setupPVZRepoMock(t)
+	defer db.Close()
+
+	pvzID := uuid.New()
+
+	mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE id = $1 FOR UPDATE`).
+		WithArgs(pvzID).
+		WillReturnRows(sqlmock.NewRows(pvzColumns).AddRow(pvzTestRow(pvzID, time.Now(), models.CityMoscow)...))
+
+	pvz, err := repo.GetByIDForUpdate(context.Background(), pvzID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, pvzID, pvz.ID)
+	assert.Nil(t, pvz.Capacity)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_ListUtilization(t *testing.T) {
+	db, mock, repo := setupPVZRepoMock(t)
+	defer db.Close()
+
+	busy, empty := uuid.New(), uuid.New()
+	now := time.Now()
+	since := now.Add(-7 * 24 * time.Hour)
+
+	mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE capacity IS NOT NULL ORDER BY id`).
+		WillReturnRows(sqlmock.NewRows(pvzColumns).
+			AddRow(busy, now, models.CityMoscow, "", nil, nil, "", nil, models.PVZStatusActive, []byte(`{"total":10,"byType":{"обувь":2}}`), 1).
+			AddRow(empty, now, models.CityKazan, "", nil, nil, "", nil, models.PVZStatusActive, []byte(`{"total":50}`), 1))
+
+	mock.ExpectQuery(`SELECT reception.pvz_id, product.type, COUNT(*) FROM product JOIN reception ON reception.id = product.reception_id WHERE reception.pvz_id IN ($1,$2) AND product.date_time >= $3 GROUP BY reception.pvz_id, product.type`).
+		WithArgs(busy, empty, since).
+		WillReturnRows(sqlmock.NewRows([]string{"pvz_id", "type", "count"}).
+			AddRow(busy, models.ProductTypeElectronics, 6).
+			AddRow(busy, models.ProductTypeShoes, 2))
+
+	result, err := repo.ListUtilization(context.Background(), since, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 8, result[0].Occupied())
+	assert.Equal(t, []models.CapacityUsage{
+		{Scope: models.CapacityScopeTotal, Occupied: 8, Capacity: 10},
+		{Scope: models.ProductTypeShoes, Occupied: 2, Capacity: 2},
+	}, result[0].Usage)
+	assert.Equal(t, 0, result[1].Occupied())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_ListUtilization_AllowedIDs(t *testing.T) {
+	db, mock, repo := setupPVZRepoMock(t)
+	defer db.Close()
+
+	allowed := uuid.New()
+	since := time.Now().Add(-7 * 24 * time.Hour)
+
+	mock.ExpectQuery(`SELECT id, registration_date, city, address, latitude, longitude, phone, working_hours, status, capacity, version FROM pvz WHERE capacity IS NOT NULL AND id IN ($1) ORDER BY id`).
+		WithArgs(allowed).
+		WillReturnRows(sqlmock.NewRows(pvzColumns))
+
+	result, err := repo.ListUtilization(context.Background(), since, []uuid.UUID{allowed})
+
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
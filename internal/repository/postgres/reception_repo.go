@@ -10,35 +10,36 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 )
 
 type ReceptionRepository struct {
-	db Querier
+	db *tracedQuerier
 	sb squirrel.StatementBuilderType
 }
 
 func NewReceptionRepository(db Querier) interfaces.TxReceptionRepository {
 	return &ReceptionRepository{
-		db: db,
+		db: traced(db),
 		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
 	}
 }
 
 func (r *ReceptionRepository) WithTx(tx *sql.Tx) interfaces.ReceptionRepository {
 	return &ReceptionRepository{
-		db: tx,
+		db: traced(tx),
 		sb: r.sb,
 	}
 }
 
 func (r *ReceptionRepository) Create(ctx context.Context, reception *models.Reception) error {
 	query := r.sb.Insert("reception").
-		Columns("id", "date_time", "pvz_id", "status").
-		Values(reception.ID, reception.DateTime, reception.PVZID, reception.Status)
+		Columns("id", "date_time", "pvz_id", "status", "closed_at", "version").
+		Values(reception.ID, reception.DateTime, reception.PVZID, reception.Status, reception.ClosedAt, reception.Version)
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
@@ -47,7 +48,7 @@ func (r *ReceptionRepository) Create(ctx context.Context, reception *models.Rece
 
 	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
 	if err != nil {
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("pvz_id", reception.PVZID.String()).
 			Str("status", reception.Status).
 			Msg("Failed to create reception")
@@ -59,7 +60,7 @@ func (r *ReceptionRepository) Create(ctx context.Context, reception *models.Rece
 		return fmt.Errorf("failed to create reception: %w", err)
 	}
 
-	log.Info().
+	zerolog.Ctx(ctx).Info().
 		Str("id", reception.ID.String()).
 		Str("pvz_id", reception.PVZID.String()).
 		Str("status", reception.Status).
@@ -71,7 +72,7 @@ func (r *ReceptionRepository) Create(ctx context.Context, reception *models.Rece
 }
 
 func (r *ReceptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Reception, error) {
-	query := r.sb.Select("id", "date_time", "pvz_id", "status").
+	query := r.sb.Select("id", "date_time", "pvz_id", "status", "version").
 		From("reception").
 		Where(squirrel.Eq{"id": id})
 
@@ -88,6 +89,7 @@ func (r *ReceptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 		&reception.DateTime,
 		&reception.PVZID,
 		&reception.Status,
+		&reception.Version,
 	)
 
 	if err != nil {
@@ -99,7 +101,7 @@ func (r *ReceptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 
 	reception.Products, err = r.getProductsForReception(ctx, reception.ID)
 	if err != nil {
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("reception_id", reception.ID.String()).
 			Msg("Failed to get products for reception")
 	}
@@ -108,7 +110,7 @@ func (r *ReceptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 }
 
 func (r *ReceptionRepository) GetLastActiveByPVZID(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
-	query := r.sb.Select("id", "date_time", "pvz_id", "status").
+	query := r.sb.Select("id", "date_time", "pvz_id", "status", "version").
 		From("reception").
 		Where(squirrel.Eq{"pvz_id": pvzID, "status": models.ReceptionStatusInProgress}).
 		OrderBy("date_time DESC").
@@ -127,6 +129,7 @@ func (r *ReceptionRepository) GetLastActiveByPVZID(ctx context.Context, pvzID uu
 		&reception.DateTime,
 		&reception.PVZID,
 		&reception.Status,
+		&reception.Version,
 	)
 
 	if err != nil {
@@ -138,7 +141,7 @@ func (r *ReceptionRepository) GetLastActiveByPVZID(ctx context.Context, pvzID uu
 
 	reception.Products, err = r.getProductsForReception(ctx, reception.ID)
 	if err != nil {
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("reception_id", reception.ID.String()).
 			Msg("Failed to get products for reception")
 	}
@@ -146,7 +149,9 @@ func (r *ReceptionRepository) GetLastActiveByPVZID(ctx context.Context, pvzID uu
 	return reception, nil
 }
 
-func (r *ReceptionRepository) CloseReception(ctx context.Context, id uuid.UUID) error {
+// CloseReception закрывает приёмку и увеличивает ее версию. Если приёмку изменили
+// после чтения версии version, возвращается ErrVersionMismatch.
+func (r *ReceptionRepository) CloseReception(ctx context.Context, id uuid.UUID, version int64) error {
 	reception, err := r.GetByID(ctx, id)
 	if err != nil {
 		return err
@@ -156,9 +161,13 @@ func (r *ReceptionRepository) CloseReception(ctx context.Context, id uuid.UUID)
 		return apperrors.ErrReceptionAlreadyClosed
 	}
 
+	// closed_at берется из часов приложения, как и date_time: иначе расхождение часов
+	// приложения и базы искажало бы длительность приёмок в отчетах.
 	query := r.sb.Update("reception").
 		Set("status", models.ReceptionStatusClosed).
-		Where(squirrel.Eq{"id": id})
+		Set("closed_at", time.Now()).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id, "version": version})
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
@@ -176,10 +185,10 @@ func (r *ReceptionRepository) CloseReception(ctx context.Context, id uuid.UUID)
 	}
 
 	if rowsAffected == 0 {
-		return repoerrors.ErrReceptionNotFound
+		return apperrors.ErrVersionMismatch
 	}
 
-	log.Info().
+	zerolog.Ctx(ctx).Info().
 		Str("id", id.String()).
 		Msg("Reception closed successfully")
 
@@ -187,7 +196,7 @@ func (r *ReceptionRepository) CloseReception(ctx context.Context, id uuid.UUID)
 }
 
 func (r *ReceptionRepository) GetLastReceptionByPVZID(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
-	query := r.sb.Select("id", "date_time", "pvz_id", "status").
+	query := r.sb.Select("id", "date_time", "pvz_id", "status", "version").
 		From("reception").
 		Where(squirrel.Eq{"pvz_id": pvzID}).
 		OrderBy("date_time DESC").
@@ -206,6 +215,7 @@ func (r *ReceptionRepository) GetLastReceptionByPVZID(ctx context.Context, pvzID
 		&reception.DateTime,
 		&reception.PVZID,
 		&reception.Status,
+		&reception.Version,
 	)
 
 	if err != nil {
@@ -217,7 +227,7 @@ func (r *ReceptionRepository) GetLastReceptionByPVZID(ctx context.Context, pvzID
 
 	reception.Products, err = r.getProductsForReception(ctx, reception.ID)
 	if err != nil {
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("reception_id", reception.ID.String()).
 			Msg("Failed to get products for reception")
 	}
@@ -225,6 +235,108 @@ func (r *ReceptionRepository) GetLastReceptionByPVZID(ctx context.Context, pvzID
 	return reception, nil
 }
 
+// ListByPVZID возвращает страницу приёмок ПВЗ, начиная с последней, и общее их количество.
+// Вместо списков товаров у приёмок заполняется количество товаров по типам.
+func (r *ReceptionRepository) ListByPVZID(ctx context.Context, pvzID uuid.UUID, filter models.ReceptionFilter) ([]*models.Reception, int, error) {
+	conditions := squirrel.And{squirrel.Eq{"pvz_id": pvzID}}
+	if filter.Status != "" {
+		conditions = append(conditions, squirrel.Eq{"status": filter.Status})
+	}
+
+	countSql, countArgs, err := r.sb.Select("COUNT(*)").From("reception").Where(conditions).ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build count SQL query: %w", err)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countSql, countArgs...).Scan(&total); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("pvz_id", pvzID.String()).Msg("Database error while counting receptions")
+		return nil, 0, fmt.Errorf("failed to count receptions: %w", err)
+	}
+
+	sqlQuery, args, err := r.sb.Select("id", "date_time", "pvz_id", "status").
+		From("reception").
+		Where(conditions).
+		OrderBy("date_time DESC", "id DESC").
+		Limit(uint64(filter.Limit)).
+		Offset(uint64((filter.Page - 1) * filter.Limit)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("pvz_id", pvzID.String()).Msg("Database error while querying receptions")
+		return nil, 0, fmt.Errorf("failed to query receptions: %w", err)
+	}
+	defer rows.Close()
+
+	receptions := make([]*models.Reception, 0, filter.Limit)
+	receptionsByID := make(map[uuid.UUID]*models.Reception, filter.Limit)
+	receptionIDs := make([]uuid.UUID, 0, filter.Limit)
+
+	for rows.Next() {
+		reception := &models.Reception{ProductCounts: map[string]int{}}
+		if err := rows.Scan(&reception.ID, &reception.DateTime, &reception.PVZID, &reception.Status); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan reception row: %w", err)
+		}
+
+		receptions = append(receptions, reception)
+		receptionsByID[reception.ID] = reception
+		receptionIDs = append(receptionIDs, reception.ID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating through reception rows: %w", err)
+	}
+
+	if len(receptionIDs) > 0 {
+		if err := r.fillProductCounts(ctx, receptionsByID, receptionIDs); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return receptions, total, nil
+}
+
+func (r *ReceptionRepository) fillProductCounts(ctx context.Context, receptions map[uuid.UUID]*models.Reception, receptionIDs []uuid.UUID) error {
+	sqlQuery, args, err := r.sb.Select("reception_id", "type", "COUNT(*)").
+		From("product").
+		Where(squirrel.Eq{"reception_id": receptionIDs}).
+		GroupBy("reception_id", "type").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build product counts SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to count products: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var receptionID uuid.UUID
+		var productType string
+		var count int
+
+		if err := rows.Scan(&receptionID, &productType, &count); err != nil {
+			return fmt.Errorf("failed to scan product count row: %w", err)
+		}
+
+		if reception, ok := receptions[receptionID]; ok {
+			reception.ProductCounts[productType] = count
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating through product count rows: %w", err)
+	}
+
+	return nil
+}
+
 func (r *ReceptionRepository) getProductsForReception(ctx context.Context, receptionID uuid.UUID) ([]models.Product, error) {
 	query := r.sb.Select("id", "date_time", "type", "reception_id").
 		From("product").
@@ -7,6 +7,7 @@ import (
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"testing"
 	"time"
@@ -24,7 +25,7 @@ func setupReceptionRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *ReceptionR
 	}
 
 	repo := &ReceptionRepository{
-		db: db,
+		db: traced(db),
 		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
 	}
 
@@ -59,10 +60,28 @@ func TestReceptionRepository_Create(t *testing.T) {
 				DateTime: now,
 				PVZID:    pvzID,
 				Status:   models.ReceptionStatusInProgress,
+				Version:  models.InitialVersion,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`INSERT INTO reception (id,date_time,pvz_id,status) VALUES ($1,$2,$3,$4)`).
-					WithArgs(receptionID, now, pvzID, models.ReceptionStatusInProgress).
+				mock.ExpectExec(`INSERT INTO reception (id,date_time,pvz_id,status,closed_at,version) VALUES ($1,$2,$3,$4,$5,$6)`).
+					WithArgs(receptionID, now, pvzID, models.ReceptionStatusInProgress, nil, models.InitialVersion).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "imported closed reception",
+			reception: &models.Reception{
+				ID:       receptionID,
+				DateTime: now.Add(-time.Hour),
+				PVZID:    pvzID,
+				Status:   models.ReceptionStatusClosed,
+				Version:  models.InitialVersion,
+				ClosedAt: &now,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO reception (id,date_time,pvz_id,status,closed_at,version) VALUES ($1,$2,$3,$4,$5,$6)`).
+					WithArgs(receptionID, now.Add(-time.Hour), pvzID, models.ReceptionStatusClosed, now, models.InitialVersion).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			wantErr: false,
@@ -74,10 +93,11 @@ func TestReceptionRepository_Create(t *testing.T) {
 				DateTime: now,
 				PVZID:    pvzID,
 				Status:   models.ReceptionStatusInProgress,
+				Version:  models.InitialVersion,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`INSERT INTO reception (id,date_time,pvz_id,status) VALUES ($1,$2,$3,$4)`).
-					WithArgs(receptionID, now, pvzID, models.ReceptionStatusInProgress).
+				mock.ExpectExec(`INSERT INTO reception (id,date_time,pvz_id,status,closed_at,version) VALUES ($1,$2,$3,$4,$5,$6)`).
+					WithArgs(receptionID, now, pvzID, models.ReceptionStatusInProgress, nil, models.InitialVersion).
 					WillReturnError(errors.New("database error"))
 			},
 			wantErr: true,
@@ -124,10 +144,10 @@ func TestReceptionRepository_GetByID(t *testing.T) {
 			name: "reception found",
 			id:   receptionID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
-					AddRow(receptionID, now, pvzID, models.ReceptionStatusInProgress)
+				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "version"}).
+					AddRow(receptionID, now, pvzID, models.ReceptionStatusInProgress, 2)
 
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE id = $1`).
 					WithArgs(receptionID).
 					WillReturnRows(rows)
 
@@ -148,7 +168,7 @@ func TestReceptionRepository_GetByID(t *testing.T) {
 			name: "reception not found",
 			id:   receptionID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE id = $1`).
 					WithArgs(receptionID).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -160,7 +180,7 @@ func TestReceptionRepository_GetByID(t *testing.T) {
 			name: "database error",
 			id:   receptionID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE id = $1`).
 					WithArgs(receptionID).
 					WillReturnError(errors.New("database error"))
 			},
@@ -215,10 +235,10 @@ func TestReceptionRepository_GetLastActiveByPVZID(t *testing.T) {
 			name:  "active reception found",
 			pvzID: pvzID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
-					AddRow(receptionID, now, pvzID, models.ReceptionStatusInProgress)
+				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "version"}).
+					AddRow(receptionID, now, pvzID, models.ReceptionStatusInProgress, 2)
 
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id = $1 AND status = $2 ORDER BY date_time DESC LIMIT 1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE pvz_id = $1 AND status = $2 ORDER BY date_time DESC LIMIT 1`).
 					WithArgs(pvzID, models.ReceptionStatusInProgress).
 					WillReturnRows(rows)
 
@@ -239,7 +259,7 @@ func TestReceptionRepository_GetLastActiveByPVZID(t *testing.T) {
 			name:  "no active reception",
 			pvzID: pvzID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id = $1 AND status = $2 ORDER BY date_time DESC LIMIT 1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE pvz_id = $1 AND status = $2 ORDER BY date_time DESC LIMIT 1`).
 					WithArgs(pvzID, models.ReceptionStatusInProgress).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -251,7 +271,7 @@ func TestReceptionRepository_GetLastActiveByPVZID(t *testing.T) {
 			name:  "database error",
 			pvzID: pvzID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id = $1 AND status = $2 ORDER BY date_time DESC LIMIT 1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE pvz_id = $1 AND status = $2 ORDER BY date_time DESC LIMIT 1`).
 					WithArgs(pvzID, models.ReceptionStatusInProgress).
 					WillReturnError(errors.New("database error"))
 			},
@@ -306,10 +326,10 @@ func TestReceptionRepository_GetLastReceptionByPVZID(t *testing.T) {
 			name:  "last reception found",
 			pvzID: pvzID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
-					AddRow(receptionID, now, pvzID, models.ReceptionStatusClosed)
+				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "version"}).
+					AddRow(receptionID, now, pvzID, models.ReceptionStatusClosed, 2)
 
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id = $1 ORDER BY date_time DESC LIMIT 1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE pvz_id = $1 ORDER BY date_time DESC LIMIT 1`).
 					WithArgs(pvzID).
 					WillReturnRows(rows)
 
@@ -330,7 +350,7 @@ func TestReceptionRepository_GetLastReceptionByPVZID(t *testing.T) {
 			name:  "no reception found",
 			pvzID: pvzID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id = $1 ORDER BY date_time DESC LIMIT 1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE pvz_id = $1 ORDER BY date_time DESC LIMIT 1`).
 					WithArgs(pvzID).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -342,7 +362,7 @@ func TestReceptionRepository_GetLastReceptionByPVZID(t *testing.T) {
 			name:  "database error",
 			pvzID: pvzID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE pvz_id = $1 ORDER BY date_time DESC LIMIT 1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE pvz_id = $1 ORDER BY date_time DESC LIMIT 1`).
 					WithArgs(pvzID).
 					WillReturnError(errors.New("database error"))
 			},
@@ -396,10 +416,10 @@ func TestReceptionRepository_CloseReception(t *testing.T) {
 			name: "successful close",
 			id:   receptionID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
-					AddRow(receptionID, now, pvzID, models.ReceptionStatusInProgress)
+				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "version"}).
+					AddRow(receptionID, now, pvzID, models.ReceptionStatusInProgress, 2)
 
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE id = $1`).
 					WithArgs(receptionID).
 					WillReturnRows(rows)
 
@@ -407,8 +427,8 @@ func TestReceptionRepository_CloseReception(t *testing.T) {
 					WithArgs(receptionID).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "type", "reception_id"}))
 
-				mock.ExpectExec(`UPDATE reception SET status = $1 WHERE id = $2`).
-					WithArgs(models.ReceptionStatusClosed, receptionID).
+				mock.ExpectExec(`UPDATE reception SET status = $1, closed_at = $2, version = version + 1 WHERE id = $3 AND version = $4`).
+					WithArgs(models.ReceptionStatusClosed, recentTime{}, receptionID, 2).
 					WillReturnResult(sqlmock.NewResult(0, 1))
 			},
 			wantErr: false,
@@ -417,10 +437,10 @@ func TestReceptionRepository_CloseReception(t *testing.T) {
 			name: "reception already closed",
 			id:   receptionID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
-					AddRow(receptionID, now, pvzID, models.ReceptionStatusClosed)
+				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "version"}).
+					AddRow(receptionID, now, pvzID, models.ReceptionStatusClosed, 2)
 
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE id = $1`).
 					WithArgs(receptionID).
 					WillReturnRows(rows)
 
@@ -435,7 +455,7 @@ func TestReceptionRepository_CloseReception(t *testing.T) {
 			name: "reception not found",
 			id:   receptionID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE id = $1`).
 					WithArgs(receptionID).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -446,10 +466,10 @@ func TestReceptionRepository_CloseReception(t *testing.T) {
 			name: "update error",
 			id:   receptionID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
-					AddRow(receptionID, now, pvzID, models.ReceptionStatusInProgress)
+				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "version"}).
+					AddRow(receptionID, now, pvzID, models.ReceptionStatusInProgress, 2)
 
-				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE id = $1`).
 					WithArgs(receptionID).
 					WillReturnRows(rows)
 
@@ -457,12 +477,34 @@ func TestReceptionRepository_CloseReception(t *testing.T) {
 					WithArgs(receptionID).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "type", "reception_id"}))
 
-				mock.ExpectExec(`UPDATE reception SET status = $1 WHERE id = $2`).
-					WithArgs(models.ReceptionStatusClosed, receptionID).
+				mock.ExpectExec(`UPDATE reception SET status = $1, closed_at = $2, version = version + 1 WHERE id = $3 AND version = $4`).
+					WithArgs(models.ReceptionStatusClosed, recentTime{}, receptionID, 2).
 					WillReturnError(errors.New("database error"))
 			},
 			wantErr: true,
 		},
+		{
+			name: "reception modified concurrently",
+			id:   receptionID,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "version"}).
+					AddRow(receptionID, now, pvzID, models.ReceptionStatusInProgress, 3)
+
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status, version FROM reception WHERE id = $1`).
+					WithArgs(receptionID).
+					WillReturnRows(rows)
+
+				mock.ExpectQuery(`SELECT id, date_time, type, reception_id FROM product WHERE reception_id = $1 ORDER BY date_time ASC`).
+					WithArgs(receptionID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "type", "reception_id"}))
+
+				mock.ExpectExec(`UPDATE reception SET status = $1, closed_at = $2, version = version + 1 WHERE id = $3 AND version = $4`).
+					WithArgs(models.ReceptionStatusClosed, recentTime{}, receptionID, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr:     true,
+			expectedErr: apperrors.ErrVersionMismatch,
+		},
 	}
 
 	for _, tt := range tests {
@@ -472,7 +514,7 @@ func TestReceptionRepository_CloseReception(t *testing.T) {
 
 			tt.mockSetup(mock)
 
-			err := repo.CloseReception(context.Background(), tt.id)
+			err := repo.CloseReception(context.Background(), tt.id, 2)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -589,3 +631,103 @@ func TestReceptionRepository_getProductsForReception(t *testing.T) {
 		})
 	}
 }
+
+func TestReceptionRepository_ListByPVZID(t *testing.T) {
+	pvzID := uuid.New()
+	receptionID1 := uuid.New()
+	receptionID2 := uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		filter     models.ReceptionFilter
+		mockSetup  func(sqlmock.Sqlmock)
+		wantCount  int
+		wantTotal  int
+		wantCounts map[string]int
+		wantErr    bool
+	}{
+		{
+			name:   "page with product counts",
+			filter: models.ReceptionFilter{Page: 2, Limit: 2},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT(*) FROM reception WHERE (pvz_id = $1)`).
+					WithArgs(pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE (pvz_id = $1) ORDER BY date_time DESC, id DESC LIMIT 2 OFFSET 2`).
+					WithArgs(pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
+						AddRow(receptionID1, now, pvzID, models.ReceptionStatusClosed).
+						AddRow(receptionID2, now.Add(-time.Hour), pvzID, models.ReceptionStatusClosed))
+
+				mock.ExpectQuery(`SELECT reception_id, type, COUNT(*) FROM product WHERE reception_id IN ($1,$2) GROUP BY reception_id, type`).
+					WithArgs(receptionID1, receptionID2).
+					WillReturnRows(sqlmock.NewRows([]string{"reception_id", "type", "count"}).
+						AddRow(receptionID1, models.ProductTypeElectronics, 2).
+						AddRow(receptionID1, models.ProductTypeShoes, 1))
+			},
+			wantCount:  2,
+			wantTotal:  4,
+			wantCounts: map[string]int{models.ProductTypeElectronics: 2, models.ProductTypeShoes: 1},
+		},
+		{
+			name:   "filtered by status, empty page",
+			filter: models.ReceptionFilter{Status: models.ReceptionStatusInProgress, Page: 1, Limit: 20},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT(*) FROM reception WHERE (pvz_id = $1 AND status = $2)`).
+					WithArgs(pvzID, models.ReceptionStatusInProgress).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+				mock.ExpectQuery(`SELECT id, date_time, pvz_id, status FROM reception WHERE (pvz_id = $1 AND status = $2) ORDER BY date_time DESC, id DESC LIMIT 20 OFFSET 0`).
+					WithArgs(pvzID, models.ReceptionStatusInProgress).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}))
+			},
+			wantCount: 0,
+			wantTotal: 0,
+		},
+		{
+			name:   "count error",
+			filter: models.ReceptionFilter{Page: 1, Limit: 20},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT(*) FROM reception WHERE (pvz_id = $1)`).
+					WithArgs(pvzID).
+					WillReturnError(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupReceptionRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			got, total, err := repo.ListByPVZID(context.Background(), pvzID, tt.filter)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantTotal, total)
+				assert.Len(t, got, tt.wantCount)
+				if tt.wantCount > 0 {
+					assert.Equal(t, tt.wantCounts, got[0].ProductCounts)
+					assert.Empty(t, got[1].ProductCounts)
+				}
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// recentTime совпадает со временем, отличающимся от текущего не больше чем на минуту.
+type recentTime struct{}
+
+func (recentTime) Match(v driver.Value) bool {
+	at, ok := v.(time.Time)
+	return ok && time.Since(at).Abs() < time.Minute
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type ReportRepository struct {
+	db *tracedQuerier
+	sb squirrel.StatementBuilderType
+}
+
+func NewReportRepository(db Querier) interfaces.ReportRepository {
+	return &ReportRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+// ReceptionReport агрегирует приёмки и товары по выбранным разрезам. Сначала товары
+// считаются по каждой приёмке (и типу, если он входит в группировку), затем строки
+// приёмок группируются: так длительность каждой приёмки учитывается в среднем один раз,
+// сколько бы товаров в ней ни было.
+func (r *ReportRepository) ReceptionReport(ctx context.Context, filter models.ReceptionReportFilter) ([]models.ReceptionReportRow, error) {
+	byType := filter.HasGroup(models.ReportGroupProductType)
+
+	perReception := r.sb.Select(
+		"reception.id",
+		"reception.pvz_id",
+		"reception.date_time",
+		"reception.closed_at",
+		"COUNT(product.id) AS products",
+	).
+		From("reception").
+		LeftJoin("product ON product.reception_id = reception.id").
+		GroupBy("reception.id")
+
+	if byType {
+		perReception = perReception.Column("product.type").GroupBy("product.type")
+	}
+	if filter.From != nil {
+		perReception = perReception.Where(squirrel.GtOrEq{"reception.date_time": *filter.From})
+	}
+	if filter.To != nil {
+		perReception = perReception.Where(squirrel.LtOrEq{"reception.date_time": *filter.To})
+	}
+	if filter.PVZID != nil {
+		perReception = perReception.Where(squirrel.Eq{"reception.pvz_id": *filter.PVZID})
+	}
+	if filter.ProductType != "" {
+		perReception = perReception.Where(squirrel.Eq{"product.type": filter.ProductType})
+	}
+
+	var groups []string
+	if filter.Period != "" {
+		// Period проверен в Validate и подставляется как одно из известных значений
+		groups = append(groups, fmt.Sprintf("date_trunc('%s', rs.date_time)", filter.Period))
+	}
+	if filter.HasGroup(models.ReportGroupPVZ) {
+		groups = append(groups, "rs.pvz_id")
+	}
+	if filter.HasGroup(models.ReportGroupCity) {
+		groups = append(groups, "pvz.city")
+	}
+	if byType {
+		groups = append(groups, "rs.type")
+	}
+
+	query := r.sb.Select(groups...).
+		Columns(
+			"COUNT(DISTINCT rs.id)",
+			"COALESCE(SUM(rs.products), 0)::bigint",
+			"AVG(EXTRACT(EPOCH FROM rs.closed_at - rs.date_time))",
+		).
+		FromSelect(perReception, "rs").
+		Join("pvz ON pvz.id = rs.pvz_id")
+
+	if len(filter.Cities) > 0 {
+		query = query.Where(squirrel.Eq{"pvz.city": filter.Cities})
+	}
+	if byType {
+		// Приёмки без товаров не относятся ни к одному типу
+		query = query.Where("rs.type IS NOT NULL")
+	}
+	if len(groups) > 0 {
+		query = query.GroupBy(groups...).OrderBy(groups...)
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for reception report")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while building reception report")
+		return nil, fmt.Errorf("failed to build reception report: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]models.ReceptionReportRow, 0)
+	for rows.Next() {
+		var row models.ReceptionReportRow
+		var periodStart time.Time
+		var pvzID uuid.UUID
+		var avgSeconds sql.NullFloat64
+
+		var dest []any
+		if filter.Period != "" {
+			dest = append(dest, &periodStart)
+		}
+		if filter.HasGroup(models.ReportGroupPVZ) {
+			dest = append(dest, &pvzID)
+		}
+		if filter.HasGroup(models.ReportGroupCity) {
+			dest = append(dest, &row.City)
+		}
+		if byType {
+			dest = append(dest, &row.ProductType)
+		}
+		dest = append(dest, &row.Receptions, &row.Products, &avgSeconds)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan reception report row: %w", err)
+		}
+
+		if filter.Period != "" {
+			row.PeriodStart = &periodStart
+		}
+		if filter.HasGroup(models.ReportGroupPVZ) {
+			row.PVZID = &pvzID
+		}
+		if avgSeconds.Valid {
+			duration := time.Duration(avgSeconds.Float64 * float64(time.Second)).Round(time.Second)
+			row.AvgDuration = &duration
+		}
+
+		result = append(result, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through reception report rows: %w", err)
+	}
+
+	return result, nil
+}
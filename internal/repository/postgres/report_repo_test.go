@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func setupReportRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *ReportRepository) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+
+	repo := &ReportRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+
+	return db, mock, repo
+}
+
+func TestNewReportRepository(t *testing.T) {
+	db, _, _ := setupReportRepoMock(t)
+	defer db.Close()
+
+	repo := NewReportRepository(db)
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*interfaces.ReportRepository)(nil), repo)
+}
+
+func TestReportRepository_ReceptionReport(t *testing.T) {
+	from := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 30, 23, 59, 59, 0, time.UTC)
+	monthStart := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	pvzID := uuid.New()
+	avgDuration := 90 * time.Minute
+
+	totalsQuery := `SELECT COUNT(DISTINCT rs.id), COALESCE(SUM(rs.products), 0)::bigint, AVG(EXTRACT(EPOCH FROM rs.closed_at - rs.date_time)) ` +
+		`FROM (SELECT reception.id, reception.pvz_id, reception.date_time, reception.closed_at, COUNT(product.id) AS products ` +
+		`FROM reception LEFT JOIN product ON product.reception_id = reception.id GROUP BY reception.id) AS rs ` +
+		`JOIN pvz ON pvz.id = rs.pvz_id`
+
+	groupedQuery := `SELECT date_trunc('month', rs.date_time), rs.pvz_id, pvz.city, rs.type, ` +
+		`COUNT(DISTINCT rs.id), COALESCE(SUM(rs.products), 0)::bigint, AVG(EXTRACT(EPOCH FROM rs.closed_at - rs.date_time)) ` +
+		`FROM (SELECT reception.id, reception.pvz_id, reception.date_time, reception.closed_at, COUNT(product.id) AS products, product.type ` +
+		`FROM reception LEFT JOIN product ON product.reception_id = reception.id ` +
+		`WHERE reception.date_time >= $1 AND reception.date_time <= $2 AND reception.pvz_id = $3 AND product.type = $4 ` +
+		`GROUP BY reception.id, product.type) AS rs ` +
+		`JOIN pvz ON pvz.id = rs.pvz_id WHERE pvz.city IN ($5) AND rs.type IS NOT NULL ` +
+		`GROUP BY date_trunc('month', rs.date_time), rs.pvz_id, pvz.city, rs.type ` +
+		`ORDER BY date_trunc('month', rs.date_time), rs.pvz_id, pvz.city, rs.type`
+
+	tests := []struct {
+		name      string
+		filter    models.ReceptionReportFilter
+		mockSetup func(sqlmock.Sqlmock)
+		want      []models.ReceptionReportRow
+		wantErr   bool
+	}{
+		{
+			name:   "totals without grouping",
+			filter: models.ReceptionReportFilter{},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(totalsQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"count", "sum", "avg"}).AddRow(4, 10, nil))
+			},
+			want: []models.ReceptionReportRow{{Receptions: 4, Products: 10}},
+		},
+		{
+			name: "grouped by period, pvz, city and product type",
+			filter: models.ReceptionReportFilter{
+				GroupBy:     []string{models.ReportGroupPVZ, models.ReportGroupCity, models.ReportGroupProductType},
+				Period:      models.ReportPeriodMonth,
+				From:        &from,
+				To:          &to,
+				Cities:      []string{"Москва"},
+				PVZID:       &pvzID,
+				ProductType: "обувь",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(groupedQuery).
+					WithArgs(from, to, pvzID, "обувь", "Москва").
+					WillReturnRows(sqlmock.NewRows([]string{"date_trunc", "pvz_id", "city", "type", "count", "sum", "avg"}).
+						AddRow(monthStart, pvzID, "Москва", "обувь", 2, 6, 5400.0))
+			},
+			want: []models.ReceptionReportRow{{
+				PeriodStart: &monthStart,
+				PVZID:       &pvzID,
+				City:        "Москва",
+				ProductType: "обувь",
+				Receptions:  2,
+				Products:    6,
+				AvgDuration: &avgDuration,
+			}},
+		},
+		{
+			name:   "database error",
+			filter: models.ReceptionReportFilter{},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(totalsQuery).WillReturnError(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupReportRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			got, err := repo.ReceptionReport(context.Background(), tt.filter)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
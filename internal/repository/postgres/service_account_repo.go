@@ -0,0 +1,330 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+var apiKeyColumns = []string{
+	"id", "service_account_id", "prefix", "key_hash", "scopes", "pvz_ids",
+	"expires_at", "last_used_at", "revoked_at", "created_at",
+}
+
+type ServiceAccountRepository struct {
+	db *tracedQuerier
+	sb squirrel.StatementBuilderType
+}
+
+func NewServiceAccountRepository(db Querier) interfaces.TxServiceAccountRepository {
+	return &ServiceAccountRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *ServiceAccountRepository) WithTx(tx *sql.Tx) interfaces.ServiceAccountRepository {
+	return &ServiceAccountRepository{
+		db: traced(tx),
+		sb: r.sb,
+	}
+}
+
+func (r *ServiceAccountRepository) CreateAccount(ctx context.Context, account *models.ServiceAccount) error {
+	query := r.sb.Insert("service_accounts").
+		Columns("id", "name", "description", "created_by", "created_at").
+		Values(account.ID, account.Name, account.Description, uuid.NullUUID{UUID: account.CreatedBy, Valid: account.CreatedBy != uuid.Nil}, account.CreatedAt)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for service account creation")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		if isUniqueViolation(err) {
+			return repoerrors.ErrServiceAccountAlreadyExists
+		}
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("name", account.Name).
+			Msg("Database error during service account creation")
+		return fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ServiceAccountRepository) GetAccountByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	query := r.sb.Select("id", "name", "description", "created_by", "created_at").
+		From("service_accounts").
+		Where(squirrel.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for service account retrieval")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	account, err := scanServiceAccount(r.db.QueryRowContext(ctx, sqlQuery, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoerrors.ErrServiceAccountNotFound
+		}
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("service_account_id", id.String()).
+			Msg("Database error while scanning service account row")
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+
+	return account, nil
+}
+
+func (r *ServiceAccountRepository) ListAccounts(ctx context.Context) ([]*models.ServiceAccount, error) {
+	query := r.sb.Select("id", "name", "description", "created_by", "created_at").
+		From("service_accounts").
+		OrderBy("created_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for service accounts listing")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error during service accounts listing")
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make([]*models.ServiceAccount, 0)
+	for rows.Next() {
+		account, err := scanServiceAccount(rows)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to scan service account row")
+			return nil, fmt.Errorf("failed to scan service account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating service account rows: %w", err)
+	}
+
+	return accounts, nil
+}
+
+func (r *ServiceAccountRepository) CreateKey(ctx context.Context, key *models.APIKey) error {
+	query := r.sb.Insert("api_keys").
+		Columns("id", "service_account_id", "prefix", "key_hash", "scopes", "pvz_ids", "expires_at", "created_at").
+		Values(key.ID, key.ServiceAccountID, key.Prefix, key.KeyHash, pq.Array(key.Scopes), pq.Array(uuidsToStrings(key.PVZIDs)), key.ExpiresAt, key.CreatedAt)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for API key creation")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("service_account_id", key.ServiceAccountID.String()).
+			Msg("Database error during API key creation")
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ServiceAccountRepository) GetKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := r.sb.Select(apiKeyColumns...).
+		From("api_keys").
+		Where(squirrel.Eq{"key_hash": keyHash})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for API key retrieval")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, sqlQuery, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoerrors.ErrAPIKeyNotFound
+		}
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Database error while scanning API key row")
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (r *ServiceAccountRepository) ListKeys(ctx context.Context, serviceAccountID uuid.UUID) ([]*models.APIKey, error) {
+	query := r.sb.Select(apiKeyColumns...).
+		From("api_keys").
+		Where(squirrel.Eq{"service_account_id": serviceAccountID}).
+		OrderBy("created_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for API keys listing")
+		return nil, fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("service_account_id", serviceAccountID.String()).
+			Msg("Database error during API keys listing")
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to scan API key row")
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API key rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *ServiceAccountRepository) RevokeKey(ctx context.Context, id uuid.UUID) error {
+	query := r.sb.Update("api_keys").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"id": id, "revoked_at": nil})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for API key revocation")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("api_key_id", id.String()).
+			Msg("Database error during API key revocation")
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repoerrors.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *ServiceAccountRepository) TouchKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	query := r.sb.Update("api_keys").
+		Set("last_used_at", usedAt).
+		Where(squirrel.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for API key usage update")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, sqlQuery, args...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("api_key_id", id.String()).
+			Msg("Database error during API key usage update")
+		return fmt.Errorf("failed to update API key usage: %w", err)
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanServiceAccount(row rowScanner) (*models.ServiceAccount, error) {
+	account := &models.ServiceAccount{}
+	var createdBy uuid.NullUUID
+	if err := row.Scan(&account.ID, &account.Name, &account.Description, &createdBy, &account.CreatedAt); err != nil {
+		return nil, err
+	}
+	account.CreatedBy = createdBy.UUID
+	return account, nil
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var scopes, pvzIDs pq.StringArray
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&key.ID,
+		&key.ServiceAccountID,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&pvzIDs,
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = scopes
+	key.PVZIDs = make([]uuid.UUID, 0, len(pvzIDs))
+	for _, raw := range pvzIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PVZ ID in API key: %w", err)
+		}
+		key.PVZIDs = append(key.PVZIDs, id)
+	}
+
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return key, nil
+}
+
+func uuidsToStrings(ids []uuid.UUID) []string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, id.String())
+	}
+	return result
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
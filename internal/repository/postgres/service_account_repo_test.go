@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func setupServiceAccountRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *ServiceAccountRepository) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+
+	repo := &ServiceAccountRepository{
+		db: traced(db),
+		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+
+	return db, mock, repo
+}
+
+func TestNewServiceAccountRepository(t *testing.T) {
+	db, _, _ := setupServiceAccountRepoMock(t)
+	defer db.Close()
+
+	repo := NewServiceAccountRepository(db)
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*interfaces.TxServiceAccountRepository)(nil), repo)
+}
+
+func TestServiceAccountRepository_CreateAccount(t *testing.T) {
+	account, _ := models.NewServiceAccount("sorting-center", "Сортировочный центр", uuid.New())
+	query := `INSERT INTO service_accounts (id,name,description,created_by,created_at) VALUES ($1,$2,$3,$4,$5)`
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantErr   error
+	}{
+		{
+			name: "successful creation",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(account.ID, account.Name, account.Description, uuid.NullUUID{UUID: account.CreatedBy, Valid: true}, account.CreatedAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "duplicate name",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			wantErr: repoerrors.ErrServiceAccountAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupServiceAccountRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.CreateAccount(context.Background(), account)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestServiceAccountRepository_GetKeyByHash(t *testing.T) {
+	keyID := uuid.New()
+	accountID := uuid.New()
+	pvzID := uuid.New()
+	createdAt := time.Now()
+	query := `SELECT id, service_account_id, prefix, key_hash, scopes, pvz_ids, expires_at, last_used_at, revoked_at, created_at FROM api_keys WHERE key_hash = $1`
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		want      *models.APIKey
+		wantErr   error
+	}{
+		{
+			name: "key found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(apiKeyColumns).
+					AddRow(keyID, accountID, "pvz_abcdefgh", "hash", "{pvz:read,receptions:write}", "{"+pvzID.String()+"}", nil, nil, nil, createdAt)
+				mock.ExpectQuery(query).WithArgs("hash").WillReturnRows(rows)
+			},
+			want: &models.APIKey{
+				ID:               keyID,
+				ServiceAccountID: accountID,
+				Prefix:           "pvz_abcdefgh",
+				KeyHash:          "hash",
+				Scopes:           []string{models.ScopePVZRead, models.ScopeReceptionsWrite},
+				PVZIDs:           []uuid.UUID{pvzID},
+				CreatedAt:        createdAt,
+			},
+		},
+		{
+			name: "key not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs("hash").WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: repoerrors.ErrAPIKeyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupServiceAccountRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			key, err := repo.GetKeyByHash(context.Background(), "hash")
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, key)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, key)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestServiceAccountRepository_RevokeKey(t *testing.T) {
+	keyID := uuid.New()
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantErr   error
+	}{
+		{
+			name: "successful revocation",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(sqlmock.AnyArg(), keyID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "key not found or already revoked",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(sqlmock.AnyArg(), keyID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: repoerrors.ErrAPIKeyNotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WillReturnError(errors.New("database error"))
+			},
+			wantErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupServiceAccountRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.RevokeKey(context.Background(), keyID)
+
+			if tt.wantErr != nil {
+				assert.Error(t, err)
+				if errors.Is(tt.wantErr, repoerrors.ErrAPIKeyNotFound) {
+					assert.ErrorIs(t, err, repoerrors.ErrAPIKeyNotFound)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// sqlLiteral находит строковые и числовые литералы, которые могут содержать данные
+	// пользователей. Плейсхолдеры $1, $2 остаются как есть.
+	sqlLiteral = regexp.MustCompile(`'(?:[^']|'')*'|\$?\b\d+(?:\.\d+)?\b`)
+	// sqlTable находит таблицу, с которой работает запрос.
+	sqlTable = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE|JOIN)\s+([a-z_][a-z0-9_.]*)`)
+	// sqlExtract находит EXTRACT(поле FROM ...), где после FROM идет не таблица.
+	sqlExtract = regexp.MustCompile(`(?i)\bEXTRACT\s*\(\s*\w+\s+FROM\b`)
+)
+
+// tracedQuerier записывает каждый запрос к БД в отдельный спан с текстом запроса
+// без значений. QueryContext возвращает tracedRows, поэтому tracedQuerier не
+// реализует Querier и не оборачивается повторно.
+type tracedQuerier struct {
+	Querier
+}
+
+// traced оборачивает подключение или транзакцию, чтобы запросы репозиториев попадали
+// в трассу.
+func traced(q Querier) *tracedQuerier {
+	return &tracedQuerier{Querier: q}
+}
+
+func (q *tracedQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	result, err := q.Querier.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return result, err
+}
+
+// QueryContext завершает спан, когда строки прочитаны или закрыты: запрос к БД
+// продолжается, пока репозиторий читает результат.
+func (q *tracedQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*tracedRows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := q.Querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (q *tracedQuerier) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	row := q.Querier.QueryRowContext(ctx, query, args...)
+	tracing.End(span, row.Err())
+	return row
+}
+
+func (q *tracedQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	stmt, err := q.Querier.PrepareContext(ctx, query)
+	tracing.End(span, err)
+	return stmt, err
+}
+
+// tracedRows завершает спан запроса, когда Next вернул false или строки закрыты.
+type tracedRows struct {
+	*sql.Rows
+	span  trace.Span
+	ended bool
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.end()
+	return false
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	r.end()
+	return err
+}
+
+func (r *tracedRows) end() {
+	if r.ended {
+		return
+	}
+	r.ended = true
+	tracing.End(r.span, r.Rows.Err())
+}
+
+// startQuerySpan начинает спан запроса с именем вида "SELECT pvz".
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	statement := sanitizeStatement(query)
+	operation, table := describeStatement(statement)
+
+	name := operation
+	if table != "" {
+		name += " " + table
+	}
+
+	return tracing.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(table),
+			semconv.DBQueryText(statement),
+		),
+	)
+}
+
+// sanitizeStatement заменяет литералы запроса на "?" и схлопывает пробелы.
+func sanitizeStatement(query string) string {
+	query = sqlLiteral.ReplaceAllStringFunc(query, func(literal string) string {
+		if strings.HasPrefix(literal, "$") {
+			return literal
+		}
+		return "?"
+	})
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// describeStatement возвращает операцию и первую таблицу запроса. Для запросов с WITH
+// операцией считается WITH.
+func describeStatement(statement string) (string, string) {
+	operation, _, _ := strings.Cut(statement, " ")
+	operation = strings.ToUpper(operation)
+
+	var table string
+	if match := sqlTable.FindStringSubmatch(sqlExtract.ReplaceAllString(statement, "")); match != nil {
+		table = match[1]
+	}
+	return operation, table
+}
@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestSanitizeStatement(t *testing.T) {
+	tests := []struct {
+		name              string
+		query             string
+		expectedStatement string
+		expectedOperation string
+		expectedTable     string
+	}{
+		{
+			name:              "Placeholders are kept",
+			query:             "SELECT id, city FROM pvz WHERE id = $1 LIMIT 10",
+			expectedStatement: "SELECT id, city FROM pvz WHERE id = $1 LIMIT ?",
+			expectedOperation: "SELECT",
+			expectedTable:     "pvz",
+		},
+		{
+			name:              "String literals are hidden",
+			query:             "UPDATE users\n\t\tSET email = 'o''brien@example.com' WHERE id = $2",
+			expectedStatement: "UPDATE users SET email = ? WHERE id = $2",
+			expectedOperation: "UPDATE",
+			expectedTable:     "users",
+		},
+		{
+			name:              "Identifiers with digits are kept",
+			query:             "insert into audit_log_v2 (id) values (42.5)",
+			expectedStatement: "insert into audit_log_v2 (id) values (?)",
+			expectedOperation: "INSERT",
+			expectedTable:     "audit_log_v2",
+		},
+		{
+			name:              "EXTRACT is not a table",
+			query:             "SELECT AVG(EXTRACT(EPOCH FROM rs.closed_at)) FROM receptions rs",
+			expectedStatement: "SELECT AVG(EXTRACT(EPOCH FROM rs.closed_at)) FROM receptions rs",
+			expectedOperation: "SELECT",
+			expectedTable:     "receptions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := sanitizeStatement(tt.query)
+			operation, table := describeStatement(statement)
+
+			assert.Equal(t, tt.expectedStatement, statement)
+			assert.Equal(t, tt.expectedOperation, operation)
+			assert.Equal(t, tt.expectedTable, table)
+		})
+	}
+}
+
+func TestTracedQuerier(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	original := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(original)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM pvz WHERE city = 'Москва'").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectExec("DELETE FROM products WHERE id = $1").
+		WithArgs(1).
+		WillReturnError(errors.New("connection reset"))
+
+	querier := traced(db)
+
+	var count int
+	assert.NoError(t, querier.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM pvz WHERE city = 'Москва'").Scan(&count))
+	_, err = querier.ExecContext(context.Background(), "DELETE FROM products WHERE id = $1", 1)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+
+	assert.Equal(t, "SELECT pvz", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.KeyValue(semconv.DBQueryText("SELECT COUNT(*) FROM pvz WHERE city = ?")))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "DELETE products", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestTracedQuerier_QueryEndsWhenRowsClosed(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	original := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(original)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id FROM pvz").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectQuery("SELECT id FROM reception").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).RowError(0, errors.New("connection reset")))
+
+	querier := traced(db)
+
+	rows, err := querier.QueryContext(context.Background(), "SELECT id FROM pvz")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, rows.Next())
+	assert.Empty(t, recorder.Ended(), "span must stay open while rows are read")
+	assert.NoError(t, rows.Close())
+	assert.NoError(t, rows.Close())
+
+	rows, err = querier.QueryContext(context.Background(), "SELECT id FROM reception")
+	if !assert.NoError(t, err) {
+		return
+	}
+	for rows.Next() {
+	}
+	assert.Error(t, rows.Err())
+	assert.NoError(t, rows.Close())
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	assert.Equal(t, "SELECT pvz", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "SELECT reception", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
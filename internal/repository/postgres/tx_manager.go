@@ -1,10 +1,11 @@
 package postgres
 
 import (
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
 	"context"
 	"database/sql"
 	"fmt"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"time"
 )
 
@@ -29,19 +30,24 @@ func NewTxManager(db *DB) *DBTxManager {
 	}
 }
 
-func (tm *DBTxManager) RunTransaction(ctx context.Context, fn func(*sql.Tx) error) error {
-	log.Debug().Msg("Starting database transaction")
+// RunTransaction выполняет fn в транзакции. Транзакция записывается в трассу отдельным
+// спаном от BEGIN до COMMIT или ROLLBACK.
+func (tm *DBTxManager) RunTransaction(ctx context.Context, fn func(*sql.Tx) error) (err error) {
+	ctx, span := tracing.Start(ctx, "RunTransaction")
+	defer func() { tracing.End(span, err) }()
+
+	zerolog.Ctx(ctx).Debug().Msg("Starting database transaction")
 	startTime := time.Now()
 
 	tx, err := tm.db.BeginTx(ctx)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to begin transaction")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to begin transaction")
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	defer func() {
 		if p := recover(); p != nil {
-			log.Error().
+			zerolog.Ctx(ctx).Error().
 				Interface("panic", p).
 				Dur("duration", time.Since(startTime)).
 				Msg("Transaction panicked, rolling back")
@@ -51,7 +57,7 @@ func (tm *DBTxManager) RunTransaction(ctx context.Context, fn func(*sql.Tx) erro
 	}()
 
 	if err := fn(tx); err != nil {
-		log.Debug().
+		zerolog.Ctx(ctx).Debug().
 			Err(err).
 			Dur("duration", time.Since(startTime)).
 			Msg("Transaction failed, rolling back")
@@ -60,14 +66,14 @@ func (tm *DBTxManager) RunTransaction(ctx context.Context, fn func(*sql.Tx) erro
 	}
 
 	if err := tx.Commit(); err != nil {
-		log.Error().
+		zerolog.Ctx(ctx).Error().
 			Err(err).
 			Dur("duration", time.Since(startTime)).
 			Msg("Failed to commit transaction")
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Debug().
+	zerolog.Ctx(ctx).Debug().
 		Dur("duration", time.Since(startTime)).
 		Msg("Transaction committed successfully")
 	return nil
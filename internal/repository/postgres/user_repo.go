@@ -11,24 +11,24 @@ import (
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 )
 
 type UserRepository struct {
-	db Querier
+	db *tracedQuerier
 	sb squirrel.StatementBuilderType
 }
 
 func NewUserRepository(db Querier) interfaces.TxUserRepository {
 	return &UserRepository{
-		db: db,
+		db: traced(db),
 		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
 	}
 }
 
 func (r *UserRepository) WithTx(tx *sql.Tx) interfaces.UserRepository {
 	return &UserRepository{
-		db: tx,
+		db: traced(tx),
 		sb: r.sb,
 	}
 }
@@ -40,7 +40,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for user creation")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for user creation")
 		return fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
@@ -49,7 +49,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		if isDuplicateKeyError(err) {
 			return repoerrors.ErrUserAlreadyExists
 		}
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("email", user.Email).
 			Str("user_id", user.ID.String()).
 			Msg("Database error during user creation")
@@ -60,86 +60,235 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
-	query := r.sb.Select("id", "email", "password_hash", "role").
+	query := r.sb.Select("id", "email", "password_hash", "role", "password_changed_at", "mfa_enabled", "language").
 		From("users").
 		Where(squirrel.Eq{"id": id})
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for user retrieval by ID")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for user retrieval by ID")
 		return nil, fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
 	row := r.db.QueryRowContext(ctx, sqlQuery, args...)
 
 	user := &models.User{}
+	var passwordChangedAt sql.NullTime
+	var language sql.NullString
 	err = row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&passwordChangedAt,
+		&user.MFAEnabled,
+		&language,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, repoerrors.ErrUserNotFound
 		}
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("user_id", id.String()).
 			Msg("Database error while scanning user row")
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
 	}
 
+	if passwordChangedAt.Valid {
+		user.PasswordChangedAt = &passwordChangedAt.Time
+	}
+	user.Language = language.String
+
 	return user, nil
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := r.sb.Select("id", "email", "password_hash", "role").
+	query := r.sb.Select("id", "email", "password_hash", "role", "password_changed_at", "mfa_enabled", "language").
 		From("users").
 		Where(squirrel.Eq{"email": email})
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for user retrieval by email")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for user retrieval by email")
 		return nil, fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
 	row := r.db.QueryRowContext(ctx, sqlQuery, args...)
 
 	user := &models.User{}
+	var passwordChangedAt sql.NullTime
+	var language sql.NullString
 	err = row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&passwordChangedAt,
+		&user.MFAEnabled,
+		&language,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, repoerrors.ErrUserNotFound
 		}
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("email", email).
 			Msg("Database error while scanning user row")
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 
+	if passwordChangedAt.Valid {
+		user.PasswordChangedAt = &passwordChangedAt.Time
+	}
+	user.Language = language.String
+
 	return user, nil
 }
 
+func (r *UserRepository) UpdatePassword(ctx context.Context, user *models.User) error {
+	query := r.sb.Update("users").
+		Set("password_hash", user.PasswordHash).
+		Set("password_changed_at", user.PasswordChangedAt).
+		Where(squirrel.Eq{"id": user.ID})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for password update")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", user.ID.String()).
+			Msg("Database error during password update")
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repoerrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdatePasswordHash заменяет хеш того же пароля, например при переходе на новый
+// алгоритм. В отличие от UpdatePassword, не трогает password_changed_at и не отзывает токены.
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := r.sb.Update("users").
+		Set("password_hash", passwordHash).
+		Where(squirrel.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for password hash update")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", id.String()).
+			Msg("Database error during password hash update")
+		return fmt.Errorf("failed to update password hash: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repoerrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepository) SetMFAEnabled(ctx context.Context, id uuid.UUID, enabled bool) error {
+	query := r.sb.Update("users").
+		Set("mfa_enabled", enabled).
+		Where(squirrel.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for MFA flag update")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", id.String()).
+			Msg("Database error during MFA flag update")
+		return fmt.Errorf("failed to update MFA flag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repoerrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SetLanguage сохраняет язык сообщений API, выбранный пользователем. Пустая строка
+// сбрасывает выбор.
+func (r *UserRepository) SetLanguage(ctx context.Context, id uuid.UUID, language string) error {
+	query := r.sb.Update("users").
+		Set("language", sql.NullString{String: language, Valid: language != ""}).
+		Where(squirrel.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for language update")
+		return fmt.Errorf("failed to build SQL query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("user_id", id.String()).
+			Msg("Database error during language update")
+		return fmt.Errorf("failed to update language: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repoerrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := r.sb.Delete("users").
 		Where(squirrel.Eq{"id": id})
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build SQL query for user deletion")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to build SQL query for user deletion")
 		return fmt.Errorf("failed to build SQL query: %w", err)
 	}
 
 	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
 	if err != nil {
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("user_id", id.String()).
 			Msg("Database error during user deletion")
 		return fmt.Errorf("failed to delete user: %w", err)
@@ -147,7 +296,7 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		log.Error().Err(err).
+		zerolog.Ctx(ctx).Error().Err(err).
 			Str("user_id", id.String()).
 			Msg("Failed to get rows affected after user deletion")
 		return fmt.Errorf("failed to get rows affected: %w", err)
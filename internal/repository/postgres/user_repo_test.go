@@ -22,7 +22,7 @@ func setupUserRepoMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *UserRepository)
 	}
 
 	repo := &UserRepository{
-		db: db,
+		db: traced(db),
 		sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
 	}
 
@@ -146,10 +146,10 @@ func TestUserRepository_GetByID(t *testing.T) {
 			name: "user found",
 			id:   userID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role"}).
-					AddRow(userID, email, passwordHash, role)
+				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role", "password_changed_at", "mfa_enabled", "language"}).
+					AddRow(userID, email, passwordHash, role, nil, false, nil)
 
-				mock.ExpectQuery(`SELECT id, email, password_hash, role FROM users WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, email, password_hash, role, password_changed_at, mfa_enabled, language FROM users WHERE id = $1`).
 					WithArgs(userID).
 					WillReturnRows(rows)
 			},
@@ -165,7 +165,7 @@ func TestUserRepository_GetByID(t *testing.T) {
 			name: "user not found",
 			id:   userID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, email, password_hash, role FROM users WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, email, password_hash, role, password_changed_at, mfa_enabled, language FROM users WHERE id = $1`).
 					WithArgs(userID).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -177,7 +177,7 @@ func TestUserRepository_GetByID(t *testing.T) {
 			name: "database error",
 			id:   userID,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, email, password_hash, role FROM users WHERE id = $1`).
+				mock.ExpectQuery(`SELECT id, email, password_hash, role, password_changed_at, mfa_enabled, language FROM users WHERE id = $1`).
 					WithArgs(userID).
 					WillReturnError(errors.New("database error"))
 			},
@@ -232,10 +232,10 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 			name:  "user found",
 			email: email,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role"}).
-					AddRow(userID, email, passwordHash, role)
+				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role", "password_changed_at", "mfa_enabled", "language"}).
+					AddRow(userID, email, passwordHash, role, nil, false, nil)
 
-				mock.ExpectQuery(`SELECT id, email, password_hash, role FROM users WHERE email = $1`).
+				mock.ExpectQuery(`SELECT id, email, password_hash, role, password_changed_at, mfa_enabled, language FROM users WHERE email = $1`).
 					WithArgs(email).
 					WillReturnRows(rows)
 			},
@@ -251,7 +251,7 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 			name:  "user not found",
 			email: email,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, email, password_hash, role FROM users WHERE email = $1`).
+				mock.ExpectQuery(`SELECT id, email, password_hash, role, password_changed_at, mfa_enabled, language FROM users WHERE email = $1`).
 					WithArgs(email).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -263,7 +263,7 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 			name:  "database error",
 			email: email,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, email, password_hash, role FROM users WHERE email = $1`).
+				mock.ExpectQuery(`SELECT id, email, password_hash, role, password_changed_at, mfa_enabled, language FROM users WHERE email = $1`).
 					WithArgs(email).
 					WillReturnError(errors.New("database error"))
 			},
@@ -375,3 +375,233 @@ func Test_isDuplicateKeyError(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepository_UpdatePassword(t *testing.T) {
+	userID := uuid.New()
+	changedAt := time.Now()
+	user := &models.User{
+		ID:                userID,
+		PasswordHash:      "new_hash",
+		PasswordChangedAt: &changedAt,
+	}
+	query := `UPDATE users SET password_hash = $1, password_changed_at = $2 WHERE id = $3`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "successful update",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("new_hash", &changedAt, userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "user not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("new_hash", &changedAt, userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr:     true,
+			expectedErr: repoerrors.ErrUserNotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("new_hash", &changedAt, userID).
+					WillReturnError(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupUserRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.UpdatePassword(context.Background(), user)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.expectedErr != nil {
+					assert.Equal(t, tt.expectedErr, err)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_SetMFAEnabled(t *testing.T) {
+	userID := uuid.New()
+	query := `UPDATE users SET mfa_enabled = $1 WHERE id = $2`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "successful update",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(true, userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "user not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(true, userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr:     true,
+			expectedErr: repoerrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupUserRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.SetMFAEnabled(context.Background(), userID, true)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_SetLanguage(t *testing.T) {
+	userID := uuid.New()
+	query := `UPDATE users SET language = $1 WHERE id = $2`
+
+	tests := []struct {
+		name        string
+		language    string
+		mockSetup   func(sqlmock.Sqlmock)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name:     "successful update",
+			language: "ru",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(sql.NullString{String: "ru", Valid: true}, userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "reset to header",
+			language: "",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(sql.NullString{}, userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "user not found",
+			language: "en",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(sql.NullString{String: "en", Valid: true}, userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr:     true,
+			expectedErr: repoerrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupUserRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.SetLanguage(context.Background(), userID, tt.language)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_UpdatePasswordHash(t *testing.T) {
+	userID := uuid.New()
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "successful update",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("$argon2id$hash", userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "user not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("$argon2id$hash", userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr:     true,
+			expectedErr: repoerrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, repo := setupUserRepoMock(t)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			err := repo.UpdatePasswordHash(context.Background(), userID, "$argon2id$hash")
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
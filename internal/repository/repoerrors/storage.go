@@ -10,6 +10,30 @@ var (
 	ErrUserAlreadyExists = errors.New("user with this email already exists")
 )
 
+// Password reset storage errors
+var (
+	ErrResetTokenNotFound = errors.New("password reset token not found")
+)
+
+// Two-factor authentication storage errors
+var (
+	ErrTOTPNotFound         = errors.New("TOTP credential not found")
+	ErrTOTPStepAlreadyUsed  = errors.New("TOTP code has already been used")
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+)
+
+// Service account storage errors
+var (
+	ErrServiceAccountNotFound      = errors.New("service account not found")
+	ErrServiceAccountAlreadyExists = errors.New("service account with this name already exists")
+	ErrAPIKeyNotFound              = errors.New("API key not found")
+)
+
+// Export storage errors
+var (
+	ErrExportJobNotFound = errors.New("export job not found")
+)
+
 // PVZ storage errors
 var (
 	ErrPVZNotFound      = errors.New("pickup point not found")
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+// auditVerifyBatchSize - сколько записей читается за раз при проверке цепочки.
+const auditVerifyBatchSize = 1000
+
+type AuditService struct {
+	repo interfaces.TxAuditRepository
+}
+
+func NewAuditService(repo interfaces.TxAuditRepository) *AuditService {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// Record добавляет запись в журнал в транзакции изменяющей операции, поэтому запись
+// появляется только вместе с изменением. Участник операции берется из контекста.
+// У nil-сервиса метод ничего не делает.
+func (s *AuditService) Record(ctx context.Context, tx *sql.Tx, action, entityType, entityID string, before, after any) error {
+	ctx, span := tracing.Start(ctx, "AuditService.Record")
+	defer span.End()
+
+	if s == nil {
+		return nil
+	}
+
+	entry, err := models.NewAuditEntry(models.ActorFromContext(ctx), action, entityType, entityID, before, after)
+	if err != nil {
+		return fmt.Errorf("failed to build audit log entry: %w", err)
+	}
+
+	if err := s.repo.WithTx(tx).Append(ctx, entry); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AuditService) ListAuditLog(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.ListAuditLog")
+	defer span.End()
+
+	entries, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	return entries, total, nil
+}
+
+// VerifyAuditChain пересчитывает хеши всех записей и сообщает о первой записи,
+// которая не совпадает с сохраненным хешем или не ссылается на предыдущую запись
+// своей сущности.
+func (s *AuditService) VerifyAuditChain(ctx context.Context) (*models.AuditVerification, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.VerifyAuditChain")
+	defer span.End()
+
+	result := &models.AuditVerification{Valid: true}
+
+	var cursor models.AuditChainCursor
+	var prevHash string
+
+	for {
+		entries, err := s.repo.ListAfter(ctx, cursor, auditVerifyBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		for _, entry := range entries {
+			result.CheckedCount++
+
+			// Цепочка следующей сущности начинается с пустого хеша
+			if entry.EntityType != cursor.EntityType || entry.EntityID != cursor.EntityID {
+				prevHash = ""
+			}
+
+			if entry.PrevHash != prevHash || entry.ComputeHash() != entry.Hash {
+				brokenAt := entry.ID
+				result.Valid = false
+				result.BrokenAtID = &brokenAt
+
+				zerolog.Ctx(ctx).Warn().
+					Int64("audit_entry_id", entry.ID).
+					Msg("Audit log hash chain is broken")
+				return result, nil
+			}
+
+			prevHash = entry.Hash
+			cursor = models.AuditChainCursor{EntityType: entry.EntityType, EntityID: entry.EntityID, ID: entry.ID}
+		}
+
+		if len(entries) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/services/mocks"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildAuditChain строит цепочку из n записей об одной сущности с ID от firstID.
+func buildAuditChain(t *testing.T, entityID string, firstID int64, n int) []*models.AuditEntry {
+	entries := make([]*models.AuditEntry, 0, n)
+	prevHash := ""
+	for i := 0; i < n; i++ {
+		entry, err := models.NewAuditEntry(models.Actor{ID: uuid.New(), Role: models.RoleEmployee},
+			models.AuditActionReceptionClose, models.AuditEntityReception, entityID, nil, map[string]int{"n": i})
+		assert.NoError(t, err)
+		entry.ID = firstID + int64(i)
+		entry.Seal(prevHash)
+		prevHash = entry.Hash
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditService_Record(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockTxAuditRepository(ctrl)
+	service := NewAuditService(mockRepo)
+
+	actor := models.Actor{ID: uuid.New(), Role: models.RoleModerator, ClientIP: "10.0.0.1", RequestID: "req-42"}
+	ctx := models.WithActor(context.Background(), actor)
+
+	mockRepo.EXPECT().WithTx(gomock.Any()).Return(mockRepo)
+	mockRepo.EXPECT().Append(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry *models.AuditEntry) error {
+			assert.Equal(t, actor.ID, entry.ActorID)
+			assert.Equal(t, actor.Role, entry.ActorRole)
+			assert.Equal(t, actor.ClientIP, entry.ClientIP)
+			assert.Equal(t, actor.RequestID, entry.RequestID)
+			assert.Equal(t, models.AuditActionPVZCreate, entry.Action)
+			assert.Nil(t, entry.Before)
+			assert.JSONEq(t, `{"city":"Казань"}`, string(entry.After))
+			return nil
+		})
+
+	err := service.Record(ctx, nil, models.AuditActionPVZCreate, models.AuditEntityPVZ, "pvz-1", nil, map[string]string{"city": "Казань"})
+	assert.NoError(t, err)
+
+	var nilService *AuditService
+	assert.NoError(t, nilService.Record(ctx, nil, models.AuditActionPVZCreate, models.AuditEntityPVZ, "pvz-1", nil, nil))
+}
+
+func TestAuditService_VerifyAuditChain(t *testing.T) {
+	tests := []struct {
+		name         string
+		tamper       func(entries []*models.AuditEntry)
+		repoErr      error
+		wantValid    bool
+		wantChecked  int
+		wantBrokenAt *int64
+		wantErr      bool
+	}{
+		{
+			name:        "цепочка не нарушена",
+			tamper:      func([]*models.AuditEntry) {},
+			wantValid:   true,
+			wantChecked: 4,
+		},
+		{
+			name: "цепочка другой сущности продолжает чужую",
+			tamper: func(entries []*models.AuditEntry) {
+				entries[3].Seal(entries[2].Hash)
+			},
+			wantValid:    false,
+			wantChecked:  4,
+			wantBrokenAt: func() *int64 { id := int64(4); return &id }(),
+		},
+		{
+			name: "запись изменена",
+			tamper: func(entries []*models.AuditEntry) {
+				entries[1].ActorRole = models.RoleModerator
+			},
+			wantValid:    false,
+			wantChecked:  2,
+			wantBrokenAt: func() *int64 { id := int64(2); return &id }(),
+		},
+		{
+			name: "запись удалена",
+			tamper: func(entries []*models.AuditEntry) {
+				entries[1] = entries[2]
+			},
+			wantValid:    false,
+			wantChecked:  2,
+			wantBrokenAt: func() *int64 { id := int64(3); return &id }(),
+		},
+		{
+			name:    "ошибка чтения журнала",
+			tamper:  func([]*models.AuditEntry) {},
+			repoErr: errors.New("database error"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockTxAuditRepository(ctrl)
+			service := NewAuditService(mockRepo)
+
+			// Записи двух сущностей в порядке цепочек, как их возвращает ListAfter
+			entries := append(buildAuditChain(t, "r-1", 1, 3), buildAuditChain(t, "r-2", 4, 1)...)
+			tt.tamper(entries)
+
+			if tt.repoErr != nil {
+				mockRepo.EXPECT().ListAfter(gomock.Any(), models.AuditChainCursor{}, auditVerifyBatchSize).Return(nil, tt.repoErr)
+			} else {
+				mockRepo.EXPECT().ListAfter(gomock.Any(), models.AuditChainCursor{}, auditVerifyBatchSize).Return(entries, nil)
+			}
+
+			result, err := service.VerifyAuditChain(context.Background())
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantValid, result.Valid)
+			assert.Equal(t, tt.wantChecked, result.CheckedCount)
+			assert.Equal(t, tt.wantBrokenAt, result.BrokenAtID)
+		})
+	}
+}
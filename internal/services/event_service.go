@@ -0,0 +1,397 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// eventBatchSize - сколько событий читается из БД за раз.
+	eventBatchSize = 500
+	// eventCleanupInterval - как часто удаляются события старше срока хранения.
+	eventCleanupInterval = time.Hour
+	// eventGapRecheckInterval - как часто проверяется, завершились ли транзакции, которые
+	// могут занимать пропущенные ID. Откат не посылает NOTIFY, поэтому без этой проверки
+	// рассылка ждала бы до следующего опроса.
+	eventGapRecheckInterval = 100 * time.Millisecond
+)
+
+// EventService раздает события ленты изменений ПВЗ подписчикам. Новые события
+// читаются из БД один раз на всех, по уведомлению или по таймеру, и рассылаются
+// подписчикам, чьим фильтрам они подходят.
+type EventService struct {
+	repo    interfaces.EventRepository
+	wakeups <-chan struct{}
+	cfg     config.EventsConfig
+
+	mu          sync.Mutex
+	subscribers map[*eventSubscriber]struct{}
+	// lastID - последнее разосланное событие
+	lastID int64
+	// gap - пропуск в ID после lastID; используется только в dispatch
+	gap eventGap
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// eventGap - пропущенные ID перед событием until. Их заняли транзакции, которые начались
+// до того, как было прочитано until: события пишет триггер после изменения строки, и у
+// транзакции уже есть xid. Когда xmin снимка не меньше xmax, снятого после чтения, все
+// такие транзакции завершены, и ID, которых все еще нет, откатились.
+type eventGap struct {
+	until    int64
+	xmax     int64
+	resolved bool
+}
+
+type eventSubscriber struct {
+	filter models.EventFilter
+	live   chan *models.PVZEvent
+	// lagged получает сигнал, когда событие не поместилось в live и подписчик должен
+	// дочитать пропущенное из БД
+	lagged chan struct{}
+}
+
+// NewEventService создает сервис; wakeups - сигналы о новых событиях в БД, без них
+// лента проверяется только по таймеру.
+func NewEventService(repo interfaces.EventRepository, wakeups <-chan struct{}, cfg config.EventsConfig) *EventService {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &EventService{
+		repo:        repo,
+		wakeups:     wakeups,
+		cfg:         cfg,
+		subscribers: make(map[*eventSubscriber]struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start запоминает текущий конец ленты и запускает рассылку новых событий и удаление
+// старых. Перед этим он ждет транзакции, которые могут занимать ID до конца ленты:
+// иначе их события не пришли бы подписчикам.
+func (s *EventService) Start(ctx context.Context) error {
+	lastID, err := s.repo.LastID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.waitTransactions(ctx); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.lastID = lastID
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run()
+
+	return nil
+}
+
+// Shutdown останавливает рассылку и закрывает каналы подписчиков.
+func (s *EventService) Shutdown() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Subscribe возвращает канал событий, подходящих под filter. Если lastEventID больше
+// нуля, сначала приходят сохраненные события после него, иначе только новые. Канал
+// закрывается, когда ctx отменен или сервис остановлен; если чтение из БД не удалось,
+// канал тоже закрывается, и клиент может продолжить с последнего полученного ID.
+func (s *EventService) Subscribe(ctx context.Context, filter models.EventFilter, lastEventID int64) (<-chan *models.PVZEvent, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("event service is stopped: %w", err)
+	}
+
+	sub := &eventSubscriber{
+		filter: filter,
+		live:   make(chan *models.PVZEvent, s.cfg.BufferSize),
+		lagged: make(chan struct{}, 1),
+	}
+
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	cursor := s.lastID
+	s.mu.Unlock()
+
+	// Пропущенное с lastEventID дочитывается тем же способом, что и при переполнении
+	if lastEventID > 0 {
+		cursor = lastEventID
+		sub.lagged <- struct{}{}
+	}
+
+	events := make(chan *models.PVZEvent)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(events)
+		defer s.unsubscribe(sub)
+
+		s.forward(ctx, sub, cursor, events)
+	}()
+
+	return events, nil
+}
+
+// waitTransactions ждет завершения транзакций, которые выполнялись в момент вызова.
+func (s *EventService) waitTransactions(ctx context.Context) error {
+	_, xmax, err := s.repo.TxSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		xmin, _, err := s.repo.TxSnapshot(ctx)
+		if err != nil {
+			return err
+		}
+		if xmin >= xmax {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(eventGapRecheckInterval):
+		}
+	}
+}
+
+// PurgeOldEvents удаляет события старше срока хранения.
+func (s *EventService) PurgeOldEvents(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "EventService.PurgeOldEvents")
+	defer span.End()
+
+	if s.cfg.Retention <= 0 {
+		return nil
+	}
+
+	deleted, err := s.repo.DeleteBefore(ctx, time.Now().Add(-s.cfg.Retention))
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		zerolog.Ctx(ctx).Info().Int64("count", deleted).Msg("Purged old PVZ events")
+	}
+
+	return nil
+}
+
+func (s *EventService) run() {
+	defer s.wg.Done()
+
+	poll := time.NewTicker(s.cfg.PollInterval)
+	defer poll.Stop()
+
+	cleanup := time.NewTicker(eventCleanupInterval)
+	defer cleanup.Stop()
+
+	// recheck срабатывает, пока рассылка стоит на пропуске в ID
+	var recheck <-chan time.Time
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-cleanup.C:
+			if err := s.PurgeOldEvents(s.ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to purge old PVZ events")
+			}
+			continue
+		case <-s.wakeups:
+		case <-poll.C:
+		case <-recheck:
+		}
+
+		blocked, err := s.dispatch(s.ctx)
+		if err != nil && s.ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to dispatch PVZ events")
+		}
+
+		recheck = nil
+		if blocked {
+			recheck = time.After(eventGapRecheckInterval)
+		}
+	}
+}
+
+// dispatch читает события после последнего разосланного и раздает их подписчикам в
+// порядке ID. События пишутся без общей блокировки, и транзакция с меньшим ID может
+// зафиксироваться позже. Поэтому на пропуске в ID рассылка останавливается, пока все
+// транзакции, которые могут его занимать, не завершатся: тогда появившиеся события
+// рассылаются, а остальные ID пропускаются как откаченные. Возвращает true, если
+// рассылка стоит на пропуске.
+func (s *EventService) dispatch(ctx context.Context) (bool, error) {
+	for {
+		s.mu.Lock()
+		lastID := s.lastID
+		s.mu.Unlock()
+
+		events, err := s.repo.ListAfter(ctx, lastID, math.MaxInt64, models.EventFilter{}, eventBatchSize)
+		if err != nil {
+			return false, fmt.Errorf("failed to read PVZ events: %w", err)
+		}
+
+		var blockedAt int64
+		s.mu.Lock()
+		for _, event := range events {
+			if event.ID != s.lastID+1 {
+				if !s.gap.resolved || event.ID > s.gap.until {
+					blockedAt = event.ID
+					break
+				}
+				// Транзакции пропущенных ID завершились, а событий нет: они откатились
+			}
+			s.publish(event)
+			s.lastID = event.ID
+			if event.ID >= s.gap.until {
+				s.gap = eventGap{}
+			}
+		}
+		s.mu.Unlock()
+
+		if blockedAt == 0 {
+			if len(events) < eventBatchSize {
+				return false, nil
+			}
+			continue
+		}
+
+		if s.gap.until != blockedAt {
+			s.gap = eventGap{until: blockedAt}
+		}
+
+		xmin, xmax, err := s.repo.TxSnapshot(ctx)
+		if err != nil {
+			return true, fmt.Errorf("failed to check PVZ event gap: %w", err)
+		}
+		if s.gap.xmax == 0 {
+			s.gap.xmax = xmax
+		}
+		if xmin < s.gap.xmax {
+			return true, nil
+		}
+
+		// Снимок снят после завершения транзакций пропуска, и повторное чтение увидит
+		// все, что они зафиксировали
+		s.gap.resolved = true
+	}
+}
+
+// publish отправляет событие подписчикам без ожидания. Вызывается под s.mu.
+func (s *EventService) publish(event *models.PVZEvent) {
+	for sub := range s.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+
+		select {
+		case sub.live <- event:
+		default:
+			select {
+			case sub.lagged <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (s *EventService) unsubscribe(sub *eventSubscriber) {
+	s.mu.Lock()
+	delete(s.subscribers, sub)
+	s.mu.Unlock()
+}
+
+// forward передает подписчику события с ID больше cursor. Событие из live проверяется
+// на отставание до отправки: если перед ним что-то не поместилось в буфер, сначала
+// дочитывается БД, иначе пропущенные события пришли бы не по порядку.
+func (s *EventService) forward(ctx context.Context, sub *eventSubscriber, cursor int64, events chan<- *models.PVZEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.ctx.Done():
+			return
+		case <-sub.lagged:
+			if !s.catchUp(ctx, sub, &cursor, events) {
+				return
+			}
+		case event := <-sub.live:
+			select {
+			case <-sub.lagged:
+				if !s.catchUp(ctx, sub, &cursor, events) {
+					return
+				}
+			default:
+			}
+
+			if event.ID <= cursor {
+				continue
+			}
+			if !s.send(ctx, events, event) {
+				return
+			}
+			cursor = event.ID
+		}
+	}
+}
+
+// catchUp отправляет подписчику сохраненные события после cursor. Читается не дальше
+// разосланного: после него в ID могут быть незаполненные пропуски, а следующие события
+// придут через live.
+func (s *EventService) catchUp(ctx context.Context, sub *eventSubscriber, cursor *int64, events chan<- *models.PVZEvent) bool {
+	for {
+		s.mu.Lock()
+		untilID := s.lastID
+		s.mu.Unlock()
+
+		batch, err := s.repo.ListAfter(ctx, *cursor, untilID, sub.filter, eventBatchSize)
+		if err != nil {
+			if ctx.Err() == nil && s.ctx.Err() == nil {
+				zerolog.Ctx(ctx).Error().Err(err).Int64("after_id", *cursor).Msg("Failed to read missed PVZ events")
+			}
+			return false
+		}
+
+		for _, event := range batch {
+			if !s.send(ctx, events, event) {
+				return false
+			}
+			*cursor = event.ID
+		}
+
+		if len(batch) < eventBatchSize {
+			return true
+		}
+	}
+}
+
+func (s *EventService) send(ctx context.Context, events chan<- *models.PVZEvent, event *models.PVZEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-s.ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/services/mocks"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+)
+
+// eventLog - лента событий в памяти вместо pvz_event. Транзакции, которые еще
+// выполняются, задают границы снимка в txSnapshot.
+type eventLog struct {
+	mu      sync.Mutex
+	events  []*models.PVZEvent
+	nextXID int64
+	running map[int64]struct{}
+}
+
+// begin начинает транзакцию, которая заняла ID события, и возвращает ее xid.
+func (l *eventLog) begin() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running == nil {
+		l.running = make(map[int64]struct{})
+	}
+	l.nextXID++
+	l.running[l.nextXID] = struct{}{}
+	return l.nextXID
+}
+
+// finish завершает транзакцию xid.
+func (l *eventLog) finish(xid int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.running, xid)
+}
+
+func (l *eventLog) txSnapshot(context.Context) (int64, int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	xmax := l.nextXID + 1
+	xmin := xmax
+	for xid := range l.running {
+		xmin = min(xmin, xid)
+	}
+	return xmin, xmax, nil
+}
+
+func (l *eventLog) append(city string, pvzID uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.insert(int64(len(l.events)+1), city, pvzID)
+}
+
+// commit добавляет событие с заданным ID, как если бы его транзакция зафиксировалась
+// позже транзакций со следующими ID.
+func (l *eventLog) commit(id int64, city string, pvzID uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.insert(id, city, pvzID)
+	sort.Slice(l.events, func(i, j int) bool { return l.events[i].ID < l.events[j].ID })
+}
+
+func (l *eventLog) insert(id int64, city string, pvzID uuid.UUID) {
+	l.events = append(l.events, &models.PVZEvent{
+		ID:    id,
+		Type:  models.EventTypeProductAdded,
+		PVZID: pvzID,
+		City:  city,
+	})
+}
+
+func (l *eventLog) listAfter(_ context.Context, afterID, untilID int64, filter models.EventFilter, limit int) ([]*models.PVZEvent, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var result []*models.PVZEvent
+	for _, event := range l.events {
+		if event.ID > afterID && event.ID <= untilID && filter.Matches(event) && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func receiveEventIDs(t *testing.T, events <-chan *models.PVZEvent, count int) []int64 {
+	t.Helper()
+
+	var ids []int64
+	for len(ids) < count {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("events channel closed after %v, want %d events", ids, count)
+			}
+			ids = append(ids, event.ID)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out after %v, want %d events", ids, count)
+		}
+	}
+	return ids
+}
+
+func assertEventIDs(t *testing.T, got []int64, want ...int64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("event IDs = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("event IDs = %v, want %v", got, want)
+		}
+	}
+}
+
+func setupEventService(t *testing.T, bufferSize int) (*EventService, *eventLog, chan struct{}) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	store := &eventLog{}
+	moscowPVZ := uuid.New()
+	store.append(models.CityMoscow, moscowPVZ)
+	store.append(models.CityMoscow, moscowPVZ)
+
+	mockRepo := mocks.NewMockEventRepository(ctrl)
+	mockRepo.EXPECT().LastID(gomock.Any()).Return(int64(2), nil)
+	mockRepo.EXPECT().ListAfter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(store.listAfter).AnyTimes()
+	mockRepo.EXPECT().TxSnapshot(gomock.Any()).DoAndReturn(store.txSnapshot).AnyTimes()
+
+	wakeups := make(chan struct{}, 1)
+	s := NewEventService(mockRepo, wakeups, config.EventsConfig{PollInterval: time.Hour, BufferSize: bufferSize})
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(s.Shutdown)
+
+	return s, store, wakeups
+}
+
+func TestEventService_Subscribe(t *testing.T) {
+	s, store, wakeups := setupEventService(t, 16)
+	ctx := context.Background()
+
+	t.Run("с Last-Event-ID сначала приходят сохраненные события", func(t *testing.T) {
+		events, err := s.Subscribe(ctx, models.EventFilter{}, 1)
+		if err != nil {
+			t.Fatalf("Subscribe() error = %v", err)
+		}
+
+		assertEventIDs(t, receiveEventIDs(t, events, 1), 2)
+	})
+
+	t.Run("без Last-Event-ID приходят только новые события по фильтру", func(t *testing.T) {
+		events, err := s.Subscribe(ctx, models.EventFilter{Cities: []string{models.CityKazan}}, 0)
+		if err != nil {
+			t.Fatalf("Subscribe() error = %v", err)
+		}
+
+		store.append(models.CityMoscow, uuid.New())
+		store.append(models.CityKazan, uuid.New())
+		wakeups <- struct{}{}
+
+		assertEventIDs(t, receiveEventIDs(t, events, 1), 4)
+	})
+
+	t.Run("ошибка: неизвестный город", func(t *testing.T) {
+		if _, err := s.Subscribe(ctx, models.EventFilter{Cities: []string{"Тверь"}}, 0); err == nil {
+			t.Error("Subscribe() expected error for invalid city")
+		}
+	})
+}
+
+func TestEventService_Subscribe_Lagging(t *testing.T) {
+	s, store, wakeups := setupEventService(t, 1)
+
+	events, err := s.Subscribe(context.Background(), models.EventFilter{}, 0)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// Подписчик не читает, пока рассылаются события, и буфер на одно событие переполняется
+	pvzID := uuid.New()
+	for i := 0; i < 5; i++ {
+		store.append(models.CityMoscow, pvzID)
+	}
+	wakeups <- struct{}{}
+
+	assertEventIDs(t, receiveEventIDs(t, events, 5), 3, 4, 5, 6, 7)
+}
+
+func TestEventService_Subscribe_Gap(t *testing.T) {
+	s, store, wakeups := setupEventService(t, 16)
+	pvzID := uuid.New()
+
+	events, err := s.Subscribe(context.Background(), models.EventFilter{}, 0)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// Событие 4 зафиксировано, пока транзакция с событием 3 еще выполняется: рассылка
+	// ждет ее, сколько бы она ни длилась
+	holder := store.begin()
+	store.commit(4, models.CityMoscow, pvzID)
+	wakeups <- struct{}{}
+
+	select {
+	case event := <-events:
+		t.Fatalf("received event %d before the gap is filled", event.ID)
+	case <-time.After(3 * eventGapRecheckInterval):
+	}
+
+	// Подписчик, продолжающий с Last-Event-ID, тоже не получает событие за пропуском
+	resumed, err := s.Subscribe(context.Background(), models.EventFilter{}, 2)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	store.commit(3, models.CityMoscow, pvzID)
+	store.finish(holder)
+
+	assertEventIDs(t, receiveEventIDs(t, events, 2), 3, 4)
+	assertEventIDs(t, receiveEventIDs(t, resumed, 2), 3, 4)
+}
+
+func TestEventService_Subscribe_RolledBackGap(t *testing.T) {
+	s, store, wakeups := setupEventService(t, 16)
+
+	events, err := s.Subscribe(context.Background(), models.EventFilter{}, 0)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	holder := store.begin()
+	store.commit(4, models.CityMoscow, uuid.New())
+	wakeups <- struct{}{}
+
+	select {
+	case event := <-events:
+		t.Fatalf("received event %d while the gap transaction is running", event.ID)
+	case <-time.After(3 * eventGapRecheckInterval):
+	}
+
+	// Транзакция с событием 3 откатилась: событие 4 приходит без уведомления
+	store.finish(holder)
+
+	assertEventIDs(t, receiveEventIDs(t, events, 1), 4)
+}
+
+func TestEventService_Start_WaitsForTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := &eventLog{}
+	holder := store.begin()
+
+	mockRepo := mocks.NewMockEventRepository(ctrl)
+	mockRepo.EXPECT().LastID(gomock.Any()).Return(int64(0), nil)
+	mockRepo.EXPECT().TxSnapshot(gomock.Any()).DoAndReturn(store.txSnapshot).AnyTimes()
+
+	s := NewEventService(mockRepo, nil, config.EventsConfig{PollInterval: time.Hour, BufferSize: 16})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*eventGapRecheckInterval)
+	defer cancel()
+	if err := s.Start(ctx); err == nil {
+		t.Fatal("Start() returned while a transaction that may hold an event ID is running")
+	}
+
+	store.finish(holder)
+	mockRepo.EXPECT().LastID(gomock.Any()).Return(int64(0), nil)
+	mockRepo.EXPECT().ListAfter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(store.listAfter).AnyTimes()
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	s.Shutdown()
+}
+
+func TestEventService_Shutdown(t *testing.T) {
+	s, _, _ := setupEventService(t, 16)
+
+	events, err := s.Subscribe(context.Background(), models.EventFilter{}, 0)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	s.Shutdown()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("received event after shutdown")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("events channel is not closed after shutdown")
+	}
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/export"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exportCleanupInterval - как часто удаляются выгрузки с истекшим сроком хранения.
+const exportCleanupInterval = time.Hour
+
+type ExportService struct {
+	exportRepo interfaces.TxExportRepository
+	jobRepo    interfaces.ExportJobRepository
+	cfg        config.ExportConfig
+	txManager  postgres.TxManager
+
+	// slots ограничивает число одновременно выполняемых фоновых выгрузок
+	slots  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewExportService(
+	exportRepo interfaces.TxExportRepository,
+	jobRepo interfaces.ExportJobRepository,
+	cfg config.ExportConfig,
+	txManager postgres.TxManager,
+) *ExportService {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &ExportService{
+		exportRepo: exportRepo,
+		jobRepo:    jobRepo,
+		cfg:        cfg,
+		txManager:  txManager,
+		slots:      make(chan struct{}, max(cfg.MaxConcurrentJobs, 1)),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Export пишет набор данных в w в формате запроса и возвращает количество строк.
+// Строки читаются курсором в одной транзакции, поэтому выгрузка согласована.
+func (s *ExportService) Export(ctx context.Context, req models.ExportRequest, w io.Writer) (int, error) {
+	ctx, span := tracing.Start(ctx, "ExportService.Export")
+	defer span.End()
+
+	if err := req.Validate(); err != nil {
+		return 0, err
+	}
+
+	writer, err := export.NewWriter(req.Format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writer.WriteHeader(models.ExportColumns(req.Dataset)); err != nil {
+		return 0, fmt.Errorf("failed to write export header: %w", err)
+	}
+
+	rowCount := 0
+	err = s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		return s.exportRepo.WithTx(tx).Stream(ctx, req.Dataset, req.Filter, s.cfg.FetchSize, func(row []any) error {
+			rowCount++
+			return writer.WriteRow(row)
+		})
+	})
+	if err != nil {
+		return rowCount, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return rowCount, fmt.Errorf("failed to finish export: %w", err)
+	}
+
+	return rowCount, nil
+}
+
+// StartExportJob создает фоновую выгрузку от имени участника из контекста и сразу
+// возвращает ее. Файл можно скачать, когда выгрузка перейдет в статус done.
+func (s *ExportService) StartExportJob(ctx context.Context, req models.ExportRequest) (*models.ExportJob, error) {
+	ctx, span := tracing.Start(ctx, "ExportService.StartExportJob")
+	defer span.End()
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	job := models.NewExportJob(req, models.ActorFromContext(ctx).ID)
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go s.runJob(ctx, *job)
+
+	return job, nil
+}
+
+// GetExportJob возвращает выгрузку, если ее создал участник из контекста. Чужие
+// выгрузки для него не существуют.
+func (s *ExportService) GetExportJob(ctx context.Context, id uuid.UUID) (*models.ExportJob, error) {
+	ctx, span := tracing.Start(ctx, "ExportService.GetExportJob")
+	defer span.End()
+
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.CreatedBy != models.ActorFromContext(ctx).ID {
+		return nil, repoerrors.ErrExportJobNotFound
+	}
+
+	return job, nil
+}
+
+// GetExportFile возвращает завершенную выгрузку и путь к ее файлу.
+func (s *ExportService) GetExportFile(ctx context.Context, id uuid.UUID) (*models.ExportJob, string, error) {
+	ctx, span := tracing.Start(ctx, "ExportService.GetExportFile")
+	defer span.End()
+
+	job, err := s.GetExportJob(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch job.Status {
+	case models.ExportJobStatusDone:
+	case models.ExportJobStatusFailed:
+		return nil, "", apperrors.ErrExportJobFailed
+	default:
+		return nil, "", apperrors.ErrExportJobNotReady
+	}
+
+	if job.IsExpired(time.Now()) {
+		return nil, "", apperrors.ErrExportJobExpired
+	}
+
+	return job, s.filePath(job), nil
+}
+
+// Start помечает неудачными выгрузки, прерванные прошлой остановкой приложения, и
+// запускает периодическое удаление устаревших файлов.
+func (s *ExportService) Start(ctx context.Context) error {
+	if err := os.MkdirAll(s.cfg.Dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	failed, err := s.jobRepo.FailUnfinished(ctx, "interrupted by application restart")
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		zerolog.Ctx(ctx).Warn().Int("count", failed).Msg("Marked interrupted export jobs as failed")
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(exportCleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.PurgeExpiredJobs(s.ctx); err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to purge expired export jobs")
+			}
+
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown прерывает выполняющиеся выгрузки и ждет их завершения.
+func (s *ExportService) Shutdown() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// PurgeExpiredJobs удаляет выгрузки с истекшим сроком хранения вместе с файлами.
+func (s *ExportService) PurgeExpiredJobs(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "ExportService.PurgeExpiredJobs")
+	defer span.End()
+
+	jobs, err := s.jobRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if err := os.Remove(s.filePath(job)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			zerolog.Ctx(ctx).Error().Err(err).Str("job_id", job.ID.String()).Msg("Failed to remove expired export file")
+		}
+	}
+
+	if len(jobs) > 0 {
+		zerolog.Ctx(ctx).Info().Int("count", len(jobs)).Msg("Purged expired export jobs")
+	}
+
+	return nil
+}
+
+// runJob выполняет выгрузку в контексте сервиса, потому что запрос, создавший ее, к
+// этому времени завершен. Выгрузка получает свою трассу со ссылкой на спан запроса
+// и пишет логи логгером запроса.
+func (s *ExportService) runJob(reqCtx context.Context, job models.ExportJob) {
+	defer s.wg.Done()
+
+	ctx := zerolog.Ctx(reqCtx).WithContext(s.ctx)
+	ctx, span := tracing.Start(ctx, "ExportService.runJob",
+		trace.WithNewRoot(),
+		trace.WithLinks(trace.LinkFromContext(reqCtx)),
+	)
+	defer span.End()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		s.finishJob(ctx, &job, 0, ctx.Err())
+		return
+	}
+
+	job.Status = models.ExportJobStatusRunning
+	if err := s.jobRepo.Update(ctx, &job); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("job_id", job.ID.String()).Msg("Failed to start export job")
+		return
+	}
+
+	startTime := time.Now()
+	rowCount, err := s.writeFile(ctx, &job)
+	s.finishJob(ctx, &job, rowCount, err)
+
+	zerolog.Ctx(ctx).Info().
+		Err(err).
+		Str("job_id", job.ID.String()).
+		Str("dataset", job.Request.Dataset).
+		Str("format", job.Request.Format).
+		Int("rows", rowCount).
+		Dur("duration", time.Since(startTime)).
+		Msg("Export job finished")
+}
+
+// writeFile пишет выгрузку во временный файл и переименовывает его после успешного
+// завершения, чтобы по пути выгрузки никогда не лежал недописанный файл.
+func (s *ExportService) writeFile(ctx context.Context, job *models.ExportJob) (int, error) {
+	path := s.filePath(job)
+	tmpPath := path + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+
+	rowCount, err := s.Export(ctx, job.Request, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close export file: %w", closeErr)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return rowCount, err
+	}
+
+	return rowCount, nil
+}
+
+func (s *ExportService) finishJob(ctx context.Context, job *models.ExportJob, rowCount int, err error) {
+	job.Finish(rowCount, err, s.cfg.JobTTL)
+
+	// Контекст сервиса может быть уже отменен, а статус нужно сохранить в любом случае
+	if err := s.jobRepo.Update(context.WithoutCancel(ctx), job); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("job_id", job.ID.String()).Msg("Failed to save export job result")
+	}
+}
+
+func (s *ExportService) filePath(job *models.ExportJob) string {
+	return filepath.Join(s.cfg.Dir, job.ID.String()+"."+job.Request.Format)
+}
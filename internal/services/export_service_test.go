@@ -0,0 +1,351 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/services/mocks"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newExportTxManager() *MockTxManager {
+	return &MockTxManager{
+		RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
+			return fn(nil)
+		},
+	}
+}
+
+func TestExportService_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExportRepo := mocks.NewMockTxExportRepository(ctrl)
+	ctx := context.Background()
+
+	pvzID := uuid.MustParse("7f1c2b4e-9a3d-4c5e-8f6a-1b2c3d4e5f60")
+	registered := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		req           models.ExportRequest
+		setupMocks    func()
+		wantRows      int
+		wantOutput    string
+		wantErr       bool
+		expectedError error
+	}{
+		{
+			name: "успешная выгрузка ПВЗ в CSV",
+			req:  models.ExportRequest{Dataset: models.ExportDatasetPVZ, Format: models.ExportFormatCSV, Filter: models.ExportFilter{Cities: []string{"Казань"}}},
+			setupMocks: func() {
+				mockExportRepo.EXPECT().WithTx(gomock.Any()).Return(mockExportRepo)
+				mockExportRepo.EXPECT().Stream(gomock.Any(), models.ExportDatasetPVZ, models.ExportFilter{Cities: []string{"Казань"}}, 500, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, _ models.ExportFilter, _ int, fn func([]any) error) error {
+						return fn([]any{pvzID, registered, "Казань", "ул. Баумана, 1", nil, nil, "", models.PVZStatusActive})
+					})
+			},
+			wantRows: 1,
+			wantOutput: "\xEF\xBB\xBFid,registrationDate,city,address,latitude,longitude,phone,status\n" +
+				"7f1c2b4e-9a3d-4c5e-8f6a-1b2c3d4e5f60,2025-04-10T12:00:00Z,Казань,\"ул. Баумана, 1\",,,,active\n",
+		},
+		{
+			name:          "ошибка: неизвестный набор данных",
+			req:           models.ExportRequest{Dataset: "users", Format: models.ExportFormatCSV},
+			setupMocks:    func() {},
+			wantErr:       true,
+			expectedError: apperrors.ErrInvalidExportDataset,
+		},
+		{
+			name:          "ошибка: неизвестный формат",
+			req:           models.ExportRequest{Dataset: models.ExportDatasetProducts, Format: "pdf"},
+			setupMocks:    func() {},
+			wantErr:       true,
+			expectedError: apperrors.ErrInvalidExportFormat,
+		},
+		{
+			name: "ошибка БД до первой строки: в ответ ничего не записано",
+			req:  models.ExportRequest{Dataset: models.ExportDatasetReceptions, Format: models.ExportFormatCSV},
+			setupMocks: func() {
+				mockExportRepo.EXPECT().WithTx(gomock.Any()).Return(mockExportRepo)
+				mockExportRepo.EXPECT().Stream(gomock.Any(), models.ExportDatasetReceptions, gomock.Any(), 500, gomock.Any()).
+					Return(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			s := NewExportService(mockExportRepo, nil, config.ExportConfig{FetchSize: 500}, newExportTxManager())
+
+			var buf bytes.Buffer
+			rows, err := s.Export(ctx, tt.req, &buf)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Export() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.expectedError != nil && !errors.Is(err, tt.expectedError) {
+				t.Errorf("Export() expected error = %v, got = %v", tt.expectedError, err)
+			}
+
+			if rows != tt.wantRows {
+				t.Errorf("Export() rows = %d, want %d", rows, tt.wantRows)
+			}
+
+			if buf.String() != tt.wantOutput {
+				t.Errorf("Export() output = %q, want %q", buf.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestExportService_StartExportJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExportRepo := mocks.NewMockTxExportRepository(ctrl)
+	mockJobRepo := mocks.NewMockExportJobRepository(ctrl)
+
+	actorID := uuid.New()
+	ctx := models.WithActor(context.Background(), models.Actor{ID: actorID})
+	dir := t.TempDir()
+	req := models.ExportRequest{Dataset: models.ExportDatasetProducts, Format: models.ExportFormatCSV}
+
+	var statuses []string
+	finished := make(chan struct{})
+	mockJobRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, job *models.ExportJob) error {
+		statuses = append(statuses, job.Status)
+		return nil
+	})
+	mockJobRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, job *models.ExportJob) error {
+		statuses = append(statuses, job.Status)
+		if job.FinishedAt != nil {
+			close(finished)
+		}
+		return nil
+	})
+	mockExportRepo.EXPECT().WithTx(gomock.Any()).Return(mockExportRepo)
+	mockExportRepo.EXPECT().Stream(gomock.Any(), models.ExportDatasetProducts, gomock.Any(), 100, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ models.ExportFilter, _ int, fn func([]any) error) error {
+			for i := 0; i < 3; i++ {
+				if err := fn([]any{uuid.New(), uuid.New(), uuid.New(), "Москва", time.Now(), models.ProductTypeShoes}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	s := NewExportService(mockExportRepo, mockJobRepo,
+		config.ExportConfig{Dir: dir, FetchSize: 100, JobTTL: time.Hour, MaxConcurrentJobs: 1}, newExportTxManager())
+
+	job, err := s.StartExportJob(ctx, req)
+	if err != nil {
+		t.Fatalf("StartExportJob() error = %v", err)
+	}
+	if job.CreatedBy != actorID || job.Status != models.ExportJobStatusPending {
+		t.Errorf("StartExportJob() job = %+v, want pending job of %s", job, actorID)
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("export job did not finish")
+	}
+	s.Shutdown()
+
+	want := []string{models.ExportJobStatusPending, models.ExportJobStatusRunning, models.ExportJobStatusDone}
+	if len(statuses) != len(want) {
+		t.Fatalf("job statuses = %v, want %v", statuses, want)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Errorf("job statuses = %v, want %v", statuses, want)
+			break
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, job.ID.String()+".csv")); err != nil {
+		t.Errorf("export file was not created: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, job.ID.String()+".csv.tmp")); !os.IsNotExist(err) {
+		t.Errorf("temporary export file should be removed, stat error = %v", err)
+	}
+}
+
+func TestExportService_StartExportJob_LinksRequestSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	original := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(original)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExportRepo := mocks.NewMockTxExportRepository(ctrl)
+	mockJobRepo := mocks.NewMockExportJobRepository(ctrl)
+
+	finished := make(chan struct{})
+	mockJobRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	mockJobRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, job *models.ExportJob) error {
+		if job.FinishedAt != nil {
+			close(finished)
+		}
+		return nil
+	})
+	mockExportRepo.EXPECT().WithTx(gomock.Any()).Return(mockExportRepo)
+	mockExportRepo.EXPECT().Stream(gomock.Any(), models.ExportDatasetProducts, gomock.Any(), 100, gomock.Any()).Return(nil)
+
+	s := NewExportService(mockExportRepo, mockJobRepo,
+		config.ExportConfig{Dir: t.TempDir(), FetchSize: 100, JobTTL: time.Hour, MaxConcurrentJobs: 1}, newExportTxManager())
+
+	ctx, requestSpan := otel.Tracer("test").Start(context.Background(), "POST /export/products/jobs")
+	_, err := s.StartExportJob(ctx, models.ExportRequest{Dataset: models.ExportDatasetProducts, Format: models.ExportFormatCSV})
+	requestSpan.End()
+	if err != nil {
+		t.Fatalf("StartExportJob() error = %v", err)
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("export job did not finish")
+	}
+	s.Shutdown()
+
+	for _, span := range recorder.Ended() {
+		if span.Name() != "ExportService.runJob" {
+			continue
+		}
+		if span.Parent().IsValid() {
+			t.Errorf("runJob span parent = %v, want a new trace", span.Parent())
+		}
+		if len(span.Links()) != 1 || span.Links()[0].SpanContext.TraceID() != requestSpan.SpanContext().TraceID() {
+			t.Errorf("runJob span links = %+v, want a link to the request trace", span.Links())
+		}
+		return
+	}
+	t.Fatal("runJob span was not recorded")
+}
+
+func TestExportService_GetExportFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockJobRepo := mocks.NewMockExportJobRepository(ctrl)
+
+	actorID := uuid.New()
+	ctx := models.WithActor(context.Background(), models.Actor{ID: actorID})
+	jobID := uuid.New()
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	newJob := func(status string, createdBy uuid.UUID, expiresAt *time.Time) *models.ExportJob {
+		return &models.ExportJob{
+			ID:        jobID,
+			Request:   models.ExportRequest{Dataset: models.ExportDatasetPVZ, Format: models.ExportFormatXLSX},
+			Status:    status,
+			CreatedBy: createdBy,
+			ExpiresAt: expiresAt,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		job           *models.ExportJob
+		wantPath      string
+		expectedError error
+	}{
+		{
+			name:     "готовая выгрузка",
+			job:      newJob(models.ExportJobStatusDone, actorID, &future),
+			wantPath: filepath.Join("exports", jobID.String()+".xlsx"),
+		},
+		{
+			name:          "ошибка: чужая выгрузка",
+			job:           newJob(models.ExportJobStatusDone, uuid.New(), &future),
+			expectedError: repoerrors.ErrExportJobNotFound,
+		},
+		{
+			name:          "ошибка: выгрузка еще выполняется",
+			job:           newJob(models.ExportJobStatusRunning, actorID, nil),
+			expectedError: apperrors.ErrExportJobNotReady,
+		},
+		{
+			name:          "ошибка: выгрузка завершилась неудачей",
+			job:           newJob(models.ExportJobStatusFailed, actorID, nil),
+			expectedError: apperrors.ErrExportJobFailed,
+		},
+		{
+			name:          "ошибка: срок хранения истек",
+			job:           newJob(models.ExportJobStatusDone, actorID, &past),
+			expectedError: apperrors.ErrExportJobExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockJobRepo.EXPECT().GetByID(gomock.Any(), jobID).Return(tt.job, nil)
+
+			s := NewExportService(nil, mockJobRepo, config.ExportConfig{Dir: "exports"}, newExportTxManager())
+
+			_, path, err := s.GetExportFile(ctx, jobID)
+
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("GetExportFile() expected error = %v, got = %v", tt.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetExportFile() error = %v", err)
+			}
+			if path != tt.wantPath {
+				t.Errorf("GetExportFile() path = %q, want %q", path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestExportService_PurgeExpiredJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockJobRepo := mocks.NewMockExportJobRepository(ctrl)
+
+	dir := t.TempDir()
+	job := &models.ExportJob{ID: uuid.New(), Request: models.ExportRequest{Format: models.ExportFormatCSV}}
+	path := filepath.Join(dir, job.ID.String()+".csv")
+	if err := os.WriteFile(path, []byte("id\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mockJobRepo.EXPECT().DeleteExpired(gomock.Any(), gomock.Any()).Return([]*models.ExportJob{job}, nil)
+
+	s := NewExportService(nil, mockJobRepo, config.ExportConfig{Dir: dir}, newExportTxManager())
+
+	if err := s.PurgeExpiredJobs(context.Background()); err != nil {
+		t.Fatalf("PurgeExpiredJobs() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expired export file should be removed, stat error = %v", err)
+	}
+}
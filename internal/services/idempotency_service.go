@@ -0,0 +1,151 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// idempotencyCleanupInterval - как часто удаляются истекшие ключи идемпотентности.
+const idempotencyCleanupInterval = time.Hour
+
+// IdempotencyService не дает выполнить изменяющий запрос дважды, если клиент повторил
+// его с тем же ключом: повтор получает сохраненный ответ первого запроса.
+type IdempotencyService struct {
+	repo interfaces.IdempotencyRepository
+	cfg  config.IdempotencyConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewIdempotencyService(repo interfaces.IdempotencyRepository, cfg config.IdempotencyConfig) *IdempotencyService {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &IdempotencyService{
+		repo:   repo,
+		cfg:    cfg,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start запускает удаление истекших ключей.
+func (s *IdempotencyService) Start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Shutdown останавливает удаление истекших ключей.
+func (s *IdempotencyService) Shutdown() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Begin занимает ключ для запроса с отпечатком fingerprint. Если запрос с этим ключом
+// уже выполнен, возвращает запись с его ответом (IsCompleted), иначе - занятую запись,
+// которую после выполнения нужно передать в Complete или Release. Повтор, пока первый
+// запрос выполняется, получает ErrIdempotencyKeyInUse, а другой запрос с тем же
+// ключом - ErrIdempotencyKeyReused.
+func (s *IdempotencyService) Begin(ctx context.Context, actorID uuid.UUID, key, fingerprint string) (*models.IdempotencyRecord, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Begin")
+	defer span.End()
+
+	if err := models.ValidateIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+
+	// locked_at сравнивается с записью в БД, которая хранит время с точностью до микросекунд
+	now := time.Now().Truncate(time.Microsecond)
+	record := &models.IdempotencyRecord{
+		ActorID:     actorID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		LockedAt:    now,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.cfg.TTL),
+	}
+
+	existing, err := s.repo.Acquire(ctx, record, now.Add(-s.cfg.LockTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return record, nil
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return nil, apperrors.ErrIdempotencyKeyReused
+	}
+	if !existing.IsCompleted() {
+		return nil, apperrors.ErrIdempotencyKeyInUse
+	}
+
+	return existing, nil
+}
+
+// Complete сохраняет ответ на запрос, занявший record.
+func (s *IdempotencyService) Complete(ctx context.Context, record *models.IdempotencyRecord, statusCode int, header http.Header, body []byte) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Complete")
+	defer span.End()
+
+	record.StatusCode = statusCode
+	record.Header = header
+	record.Body = body
+
+	return s.repo.Complete(ctx, record)
+}
+
+// Release освобождает ключ без сохранения ответа, чтобы повтор выполнил запрос заново.
+func (s *IdempotencyService) Release(ctx context.Context, record *models.IdempotencyRecord) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Release")
+	defer span.End()
+
+	return s.repo.Release(ctx, record)
+}
+
+// PurgeExpired удаляет ключи, срок хранения ответов которых истек.
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.PurgeExpired")
+	defer span.End()
+
+	deleted, err := s.repo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		zerolog.Ctx(ctx).Info().Int64("count", deleted).Msg("Purged expired idempotency keys")
+	}
+
+	return nil
+}
+
+func (s *IdempotencyService) run() {
+	defer s.wg.Done()
+
+	cleanup := time.NewTicker(idempotencyCleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-cleanup.C:
+			if err := s.PurgeExpired(s.ctx); err != nil && s.ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to purge expired idempotency keys")
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/services/mocks"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyRepository(ctrl)
+	cfg := config.IdempotencyConfig{TTL: 24 * time.Hour, LockTimeout: time.Minute}
+	service := NewIdempotencyService(mockRepo, cfg)
+
+	actorID := uuid.New()
+	completed := &models.IdempotencyRecord{
+		ActorID:     actorID,
+		Key:         "retry-1",
+		Fingerprint: "abc",
+		StatusCode:  201,
+		Header:      http.Header{"Content-Type": {"application/json"}},
+		Body:        []byte(`{"id":1}`),
+	}
+
+	tests := []struct {
+		name          string
+		key           string
+		fingerprint   string
+		setupMocks    func()
+		wantCompleted bool
+		expectedError error
+		wantErr       bool
+	}{
+		{
+			name:        "ключ занят для нового запроса",
+			key:         "retry-1",
+			fingerprint: "abc",
+			setupMocks: func() {
+				mockRepo.EXPECT().Acquire(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record *models.IdempotencyRecord, staleBefore time.Time) (*models.IdempotencyRecord, error) {
+						assert.Equal(t, actorID, record.ActorID)
+						assert.Equal(t, "abc", record.Fingerprint)
+						assert.Equal(t, record.LockedAt.Add(cfg.TTL), record.ExpiresAt)
+						assert.Equal(t, record.LockedAt.Add(-cfg.LockTimeout), staleBefore)
+						assert.Equal(t, record.LockedAt, record.LockedAt.Truncate(time.Microsecond))
+						return nil, nil
+					})
+			},
+		},
+		{
+			name:        "повтор получает сохраненный ответ",
+			key:         "retry-1",
+			fingerprint: "abc",
+			setupMocks: func() {
+				mockRepo.EXPECT().Acquire(gomock.Any(), gomock.Any(), gomock.Any()).Return(completed, nil)
+			},
+			wantCompleted: true,
+		},
+		{
+			name:        "повтор во время выполнения",
+			key:         "retry-1",
+			fingerprint: "abc",
+			setupMocks: func() {
+				mockRepo.EXPECT().Acquire(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&models.IdempotencyRecord{ActorID: actorID, Key: "retry-1", Fingerprint: "abc"}, nil)
+			},
+			wantErr:       true,
+			expectedError: apperrors.ErrIdempotencyKeyInUse,
+		},
+		{
+			name:        "ключ использован с другим запросом",
+			key:         "retry-1",
+			fingerprint: "def",
+			setupMocks: func() {
+				mockRepo.EXPECT().Acquire(gomock.Any(), gomock.Any(), gomock.Any()).Return(completed, nil)
+			},
+			wantErr:       true,
+			expectedError: apperrors.ErrIdempotencyKeyReused,
+		},
+		{
+			name:          "недопустимый ключ",
+			key:           "ключ с пробелами",
+			fingerprint:   "abc",
+			setupMocks:    func() {},
+			wantErr:       true,
+			expectedError: apperrors.ErrInvalidIdempotencyKey,
+		},
+		{
+			name:        "ошибка базы данных",
+			key:         "retry-1",
+			fingerprint: "abc",
+			setupMocks: func() {
+				mockRepo.EXPECT().Acquire(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			record, err := service.Begin(context.Background(), actorID, tt.key, tt.fingerprint)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.expectedError != nil {
+					assert.ErrorIs(t, err, tt.expectedError)
+				}
+				assert.Nil(t, record)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCompleted, record.IsCompleted())
+			if tt.wantCompleted {
+				assert.Equal(t, completed, record)
+			}
+		})
+	}
+}
+
+func TestIdempotencyService_Complete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyRepository(ctrl)
+	service := NewIdempotencyService(mockRepo, config.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute})
+
+	record := &models.IdempotencyRecord{ActorID: uuid.New(), Key: "retry-1"}
+
+	mockRepo.EXPECT().Complete(gomock.Any(), &models.IdempotencyRecord{
+		ActorID:    record.ActorID,
+		Key:        "retry-1",
+		StatusCode: 201,
+		Header:     http.Header{"Location": {"/pvz/1"}},
+		Body:       []byte(`{}`),
+	}).Return(nil)
+
+	err := service.Complete(context.Background(), record, 201, http.Header{"Location": {"/pvz/1"}}, []byte(`{}`))
+
+	assert.NoError(t, err)
+	assert.True(t, record.IsCompleted())
+}
+
+func TestIdempotencyService_PurgeExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIdempotencyRepository(ctrl)
+	service := NewIdempotencyService(mockRepo, config.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute})
+
+	mockRepo.EXPECT().DeleteExpired(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("database error"))
+
+	assert.Error(t, service.PurgeExpired(context.Background()))
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/importer"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type ImportService struct {
+	pvzRepo       interfaces.TxPVZRepository
+	receptionRepo interfaces.TxReceptionRepository
+	productRepo   interfaces.TxProductRepository
+	importRepo    interfaces.TxImportRepository
+	auditService  *AuditService
+	cfg           config.ImportConfig
+	txManager     postgres.TxManager
+}
+
+func NewImportService(
+	pvzRepo interfaces.TxPVZRepository,
+	receptionRepo interfaces.TxReceptionRepository,
+	productRepo interfaces.TxProductRepository,
+	importRepo interfaces.TxImportRepository,
+	auditService *AuditService,
+	cfg config.ImportConfig,
+	txManager postgres.TxManager,
+) *ImportService {
+	return &ImportService{
+		pvzRepo:       pvzRepo,
+		receptionRepo: receptionRepo,
+		productRepo:   productRepo,
+		importRepo:    importRepo,
+		auditService:  auditService,
+		cfg:           cfg,
+		txManager:     txManager,
+	}
+}
+
+// Import загружает строки набора данных из r порциями по ChunkSize, каждая порция - в
+// своей транзакции. Строки, внешний ID которых уже загружен, пропускаются, поэтому
+// после сбоя файл можно загрузить повторно. Ошибочные строки не прерывают загрузку и
+// попадают в отчет; ошибка возвращается, только если файл не удалось дочитать или
+// порцию не удалось записать. Порции, записанные до этого, остаются в БД.
+func (s *ImportService) Import(ctx context.Context, req models.ImportRequest, r io.Reader) (*models.ImportReport, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.Import")
+	defer span.End()
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	reader, err := importer.NewReader(req.Format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	report := &models.ImportReport{Dataset: req.Dataset, DryRun: req.DryRun}
+	seen := make(map[string]struct{})
+	chunk := make([]models.ImportRow, 0, s.cfg.ChunkSize)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var rowErr *importer.RowError
+		if errors.As(err, &rowErr) {
+			report.Total++
+			report.AddError(rowErr.Line, "", fmt.Errorf("%w: %v", apperrors.ErrMalformedImportRow, rowErr.Err))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read import file: %w", err)
+		}
+
+		report.Total++
+		row := models.ImportRow{Line: record.Line, Fields: record.Fields}
+
+		externalID := row.ExternalID()
+		if externalID == "" {
+			report.AddError(row.Line, "", &models.ImportFieldError{Column: models.ImportColumnExternalID, Err: apperrors.ErrImportExternalIDRequired})
+			continue
+		}
+		if _, ok := seen[externalID]; ok {
+			report.AddError(row.Line, externalID, &models.ImportFieldError{Column: models.ImportColumnExternalID, Err: apperrors.ErrDuplicateExternalID})
+			continue
+		}
+		seen[externalID] = struct{}{}
+
+		chunk = append(chunk, row)
+		if len(chunk) == s.cfg.ChunkSize {
+			if err := s.importChunk(ctx, req, chunk, report); err != nil {
+				return nil, err
+			}
+			chunk = chunk[:0]
+		}
+	}
+
+	if len(chunk) > 0 {
+		if err := s.importChunk(ctx, req, chunk, report); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Line < report.Errors[j].Line
+	})
+
+	zerolog.Ctx(ctx).Info().
+		Str("dataset", req.Dataset).
+		Bool("dry_run", req.DryRun).
+		Int("total", report.Total).
+		Int("created", report.Created).
+		Int("skipped", report.Skipped).
+		Int("failed", report.Failed()).
+		Dur("duration", time.Since(startTime)).
+		Msg("Import finished")
+
+	return report, nil
+}
+
+// importChunk загружает порцию строк в одной транзакции. Результаты порции попадают в
+// отчет, только если транзакция зафиксирована.
+func (s *ImportService) importChunk(ctx context.Context, req models.ImportRequest, rows []models.ImportRow, report *models.ImportReport) error {
+	var result models.ImportReport
+
+	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		result = models.ImportReport{}
+		importRepo := s.importRepo.WithTx(tx)
+
+		externalIDs := make([]string, 0, len(rows))
+		parentIDs := make([]string, 0, len(rows))
+		for _, row := range rows {
+			externalIDs = append(externalIDs, row.ExternalID())
+			if parentID := row.ParentExternalID(req.Dataset); parentID != "" {
+				parentIDs = append(parentIDs, parentID)
+			}
+		}
+
+		existing, err := importRepo.FindEntityIDs(ctx, req.Dataset, externalIDs)
+		if err != nil {
+			return err
+		}
+
+		var parents map[string]uuid.UUID
+		if parentDataset := models.ImportParentDataset(req.Dataset); parentDataset != "" {
+			if parents, err = importRepo.FindEntityIDs(ctx, parentDataset, parentIDs); err != nil {
+				return err
+			}
+		}
+
+		for _, row := range rows {
+			if _, ok := existing[row.ExternalID()]; ok {
+				result.Skipped++
+				continue
+			}
+
+			entity, err := s.buildEntity(req.Dataset, row, parents)
+			if err != nil {
+				result.AddError(row.Line, row.ExternalID(), err)
+				continue
+			}
+
+			if req.DryRun {
+				result.Created++
+				continue
+			}
+
+			entityID, err := s.createEntity(ctx, tx, entity)
+			if err != nil {
+				return fmt.Errorf("failed to import line %d: %w", row.Line, err)
+			}
+			if err := importRepo.SaveEntityID(ctx, req.Dataset, row.ExternalID(), entityID); err != nil {
+				return err
+			}
+			result.Created++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	report.Created += result.Created
+	report.Skipped += result.Skipped
+	report.Errors = append(report.Errors, result.Errors...)
+
+	return nil
+}
+
+// buildEntity проверяет строку и строит по ней сущность. Приёмки и товары получают ID
+// родителя по его внешнему ID из parents.
+func (s *ImportService) buildEntity(dataset string, row models.ImportRow, parents map[string]uuid.UUID) (any, error) {
+	if dataset == models.ImportDatasetPVZ {
+		return row.PVZ()
+	}
+
+	parentExternalID := row.ParentExternalID(dataset)
+	parentID, ok := parents[parentExternalID]
+	if !ok {
+		err := apperrors.ErrImportReferenceNotFound
+		if parentExternalID == "" {
+			err = apperrors.ErrImportValueRequired
+		}
+		return nil, &models.ImportFieldError{Column: models.ImportParentColumn(dataset), Err: err}
+	}
+
+	if dataset == models.ImportDatasetReceptions {
+		return row.Reception(parentID)
+	}
+	return row.Product(parentID)
+}
+
+// createEntity сохраняет сущность и записывает ее создание в журнал аудита.
+func (s *ImportService) createEntity(ctx context.Context, tx *sql.Tx, entity any) (uuid.UUID, error) {
+	switch e := entity.(type) {
+	case *models.PVZ:
+		if err := s.pvzRepo.WithTx(tx).Create(ctx, e); err != nil {
+			return uuid.Nil, err
+		}
+		return e.ID, s.auditService.Record(ctx, tx, models.AuditActionPVZCreate, models.AuditEntityPVZ, e.ID.String(), nil, e)
+
+	case *models.Reception:
+		if err := s.receptionRepo.WithTx(tx).Create(ctx, e); err != nil {
+			return uuid.Nil, err
+		}
+		return e.ID, s.auditService.Record(ctx, tx, models.AuditActionReceptionCreate, models.AuditEntityReception, e.ID.String(), nil, e)
+
+	case *models.Product:
+		if err := s.productRepo.WithTx(tx).Create(ctx, e); err != nil {
+			return uuid.Nil, err
+		}
+		return e.ID, s.auditService.Record(ctx, tx, models.AuditActionProductAdd, models.AuditEntityProduct, e.ID.String(), nil, e)
+
+	default:
+		return uuid.Nil, fmt.Errorf("unsupported import entity %T", entity)
+	}
+}
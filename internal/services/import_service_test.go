@@ -0,0 +1,201 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/services/mocks"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+)
+
+func TestImportService_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZRepo := mocks.NewMockTxPVZRepository(ctrl)
+	mockReceptionRepo := mocks.NewMockTxReceptionRepository(ctrl)
+	mockProductRepo := mocks.NewMockTxProductRepository(ctrl)
+	mockImportRepo := mocks.NewMockTxImportRepository(ctrl)
+
+	ctx := context.Background()
+	existingPVZID := uuid.New()
+	parentPVZID := uuid.New()
+	registered := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		req           models.ImportRequest
+		input         string
+		chunkSize     int
+		setupMocks    func()
+		wantReport    models.ImportReport
+		wantErrors    []models.ImportRowError
+		wantErr       bool
+		expectedError error
+	}{
+		{
+			name: "успешная загрузка ПВЗ: новые создаются, загруженные ранее пропускаются",
+			req:  models.ImportRequest{Dataset: models.ImportDatasetPVZ, Format: models.ImportFormatCSV},
+			input: "externalId,city,registrationDate,address\n" +
+				"franchise-1,Москва,2024-01-15T09:00:00Z,\"ул. Тверская, 1\"\n" +
+				"franchise-2,Казань,,\n",
+			chunkSize: 10,
+			setupMocks: func() {
+				mockImportRepo.EXPECT().WithTx(gomock.Any()).Return(mockImportRepo)
+				mockImportRepo.EXPECT().FindEntityIDs(gomock.Any(), models.ImportDatasetPVZ, []string{"franchise-1", "franchise-2"}).
+					Return(map[string]uuid.UUID{"franchise-2": existingPVZID}, nil)
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+				mockPVZRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, pvz *models.PVZ) error {
+					if pvz.City != models.CityMoscow || pvz.Address != "ул. Тверская, 1" || !pvz.RegistrationDate.Equal(registered) {
+						t.Errorf("Create() pvz = %+v, want imported Moscow PVZ", pvz)
+					}
+					return nil
+				})
+				mockImportRepo.EXPECT().SaveEntityID(gomock.Any(), models.ImportDatasetPVZ, "franchise-1", gomock.Any()).Return(nil)
+			},
+			wantReport: models.ImportReport{Dataset: models.ImportDatasetPVZ, Total: 2, Created: 1, Skipped: 1},
+		},
+		{
+			name: "ошибочные строки попадают в отчет и не прерывают загрузку",
+			req:  models.ImportRequest{Dataset: models.ImportDatasetPVZ, Format: models.ImportFormatNDJSON},
+			input: `{"externalId":"a","city":"Тверь"}` + "\n" +
+				`{"city":"Москва"}` + "\n" +
+				`{"externalId":"b","city":"Москва","latitude":"north"}` + "\n" +
+				`{"externalId":"a","city":"Москва"}` + "\n" +
+				`not json` + "\n",
+			chunkSize: 10,
+			setupMocks: func() {
+				mockImportRepo.EXPECT().WithTx(gomock.Any()).Return(mockImportRepo)
+				mockImportRepo.EXPECT().FindEntityIDs(gomock.Any(), models.ImportDatasetPVZ, []string{"a", "b"}).
+					Return(map[string]uuid.UUID{}, nil)
+			},
+			wantReport: models.ImportReport{Dataset: models.ImportDatasetPVZ, Total: 5},
+			wantErrors: []models.ImportRowError{
+				{Line: 1, ExternalID: "a", Column: "city", Err: apperrors.ErrInvalidCity},
+				{Line: 2, Column: "externalId", Err: apperrors.ErrImportExternalIDRequired},
+				{Line: 3, ExternalID: "b", Column: "latitude", Err: apperrors.ErrInvalidImportValue},
+				{Line: 4, ExternalID: "a", Column: "externalId", Err: apperrors.ErrDuplicateExternalID},
+				{Line: 5, Err: apperrors.ErrMalformedImportRow},
+			},
+		},
+		{
+			name: "пробный запуск приёмок проверяет ссылки и ничего не записывает",
+			req:  models.ImportRequest{Dataset: models.ImportDatasetReceptions, Format: models.ImportFormatCSV, DryRun: true},
+			input: "externalId,pvzExternalId,dateTime,closedAt,status\n" +
+				"r-1,franchise-1,2024-02-01T10:00:00Z,2024-02-01T10:40:00Z,close\n" +
+				"r-2,franchise-9,2024-02-01T10:00:00Z,2024-02-01T10:40:00Z,\n" +
+				"r-3,franchise-1,2024-02-02T10:00:00Z,,in_progress\n",
+			chunkSize: 10,
+			setupMocks: func() {
+				mockImportRepo.EXPECT().WithTx(gomock.Any()).Return(mockImportRepo)
+				mockImportRepo.EXPECT().FindEntityIDs(gomock.Any(), models.ImportDatasetReceptions, []string{"r-1", "r-2", "r-3"}).
+					Return(map[string]uuid.UUID{}, nil)
+				mockImportRepo.EXPECT().FindEntityIDs(gomock.Any(), models.ImportDatasetPVZ, []string{"franchise-1", "franchise-9", "franchise-1"}).
+					Return(map[string]uuid.UUID{"franchise-1": parentPVZID}, nil)
+			},
+			wantReport: models.ImportReport{Dataset: models.ImportDatasetReceptions, DryRun: true, Total: 3, Created: 1},
+			wantErrors: []models.ImportRowError{
+				{Line: 3, ExternalID: "r-2", Column: "pvzExternalId", Err: apperrors.ErrImportReferenceNotFound},
+				{Line: 4, ExternalID: "r-3", Column: "status", Err: apperrors.ErrImportReceptionNotClosed},
+			},
+		},
+		{
+			name: "товары загружаются порциями в отдельных транзакциях",
+			req:  models.ImportRequest{Dataset: models.ImportDatasetProducts, Format: models.ImportFormatCSV},
+			input: "externalId,receptionExternalId,dateTime,type\n" +
+				"p-1,r-1,2024-02-01T10:05:00Z,обувь\n" +
+				"p-2,r-1,2024-02-01T10:06:00Z,одежда\n" +
+				"p-3,r-1,2024-02-01T10:07:00Z,электроника\n",
+			chunkSize: 2,
+			setupMocks: func() {
+				receptionID := uuid.New()
+				mockImportRepo.EXPECT().WithTx(gomock.Any()).Return(mockImportRepo).Times(2)
+				mockImportRepo.EXPECT().FindEntityIDs(gomock.Any(), models.ImportDatasetProducts, gomock.Any()).
+					Return(map[string]uuid.UUID{}, nil).Times(2)
+				mockImportRepo.EXPECT().FindEntityIDs(gomock.Any(), models.ImportDatasetReceptions, gomock.Any()).
+					Return(map[string]uuid.UUID{"r-1": receptionID}, nil).Times(2)
+				mockProductRepo.EXPECT().WithTx(gomock.Any()).Return(mockProductRepo).Times(3)
+				mockProductRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, product *models.Product) error {
+					if product.ReceptionID != receptionID {
+						t.Errorf("Create() product reception = %s, want %s", product.ReceptionID, receptionID)
+					}
+					return nil
+				}).Times(3)
+				mockImportRepo.EXPECT().SaveEntityID(gomock.Any(), models.ImportDatasetProducts, gomock.Any(), gomock.Any()).Return(nil).Times(3)
+			},
+			wantReport: models.ImportReport{Dataset: models.ImportDatasetProducts, Total: 3, Created: 3},
+		},
+		{
+			name: "ошибка БД прерывает загрузку",
+			req:  models.ImportRequest{Dataset: models.ImportDatasetReceptions, Format: models.ImportFormatCSV},
+			input: "externalId,pvzExternalId,dateTime,closedAt\n" +
+				"r-1,franchise-1,2024-02-01T10:00:00Z,2024-02-01T10:40:00Z\n",
+			chunkSize: 10,
+			setupMocks: func() {
+				mockImportRepo.EXPECT().WithTx(gomock.Any()).Return(mockImportRepo)
+				mockImportRepo.EXPECT().FindEntityIDs(gomock.Any(), models.ImportDatasetReceptions, gomock.Any()).Return(map[string]uuid.UUID{}, nil)
+				mockImportRepo.EXPECT().FindEntityIDs(gomock.Any(), models.ImportDatasetPVZ, gomock.Any()).
+					Return(map[string]uuid.UUID{"franchise-1": parentPVZID}, nil)
+				mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
+				mockReceptionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+		{
+			name:          "ошибка: неизвестный формат",
+			req:           models.ImportRequest{Dataset: models.ImportDatasetPVZ, Format: "xlsx"},
+			setupMocks:    func() {},
+			wantErr:       true,
+			expectedError: apperrors.ErrInvalidImportFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			txManager := &MockTxManager{
+				RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
+					return fn(nil)
+				},
+			}
+			s := NewImportService(mockPVZRepo, mockReceptionRepo, mockProductRepo, mockImportRepo, nil,
+				config.ImportConfig{ChunkSize: tt.chunkSize}, txManager)
+
+			report, err := s.Import(ctx, tt.req, strings.NewReader(tt.input))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Import() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.expectedError != nil && !errors.Is(err, tt.expectedError) {
+				t.Errorf("Import() expected error = %v, got = %v", tt.expectedError, err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if report.Dataset != tt.wantReport.Dataset || report.DryRun != tt.wantReport.DryRun || report.Total != tt.wantReport.Total ||
+				report.Created != tt.wantReport.Created || report.Skipped != tt.wantReport.Skipped {
+				t.Errorf("Import() report = %+v, want %+v", report, tt.wantReport)
+			}
+
+			if len(report.Errors) != len(tt.wantErrors) {
+				t.Fatalf("Import() errors = %+v, want %+v", report.Errors, tt.wantErrors)
+			}
+			for i, want := range tt.wantErrors {
+				got := report.Errors[i]
+				if got.Line != want.Line || got.ExternalID != want.ExternalID || got.Column != want.Column || !errors.Is(got.Err, want.Err) {
+					t.Errorf("Import() error[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
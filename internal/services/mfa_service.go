@@ -0,0 +1,380 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/auth"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/totp"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// totpSkew допускает расхождение часов клиента и сервера на один шаг в каждую сторону.
+const totpSkew = 1
+
+type MFAService struct {
+	userRepo     interfaces.TxUserRepository
+	mfaRepo      interfaces.TxMFARepository
+	jwtConfig    config.JWTConfig
+	mfaConfig    config.MFAConfig
+	auditService *AuditService
+	txManager    postgres.TxManager
+}
+
+func NewMFAService(
+	userRepo interfaces.TxUserRepository,
+	mfaRepo interfaces.TxMFARepository,
+	jwtConfig config.JWTConfig,
+	mfaConfig config.MFAConfig,
+	auditService *AuditService,
+	txManager postgres.TxManager,
+) *MFAService {
+	return &MFAService{
+		userRepo:     userRepo,
+		mfaRepo:      mfaRepo,
+		jwtConfig:    jwtConfig,
+		mfaConfig:    mfaConfig,
+		auditService: auditService,
+		txManager:    txManager,
+	}
+}
+
+// EnrollTOTP создает новый секрет аутентификатора. 2FA включается только после
+// подтверждения кодом в ConfirmTOTP.
+func (s *MFAService) EnrollTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "MFAService.EnrollTOTP")
+	defer span.End()
+
+	var enrollment *models.TOTPEnrollment
+
+	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		txUserRepo := s.userRepo.WithTx(tx)
+		txMFARepo := s.mfaRepo.WithTx(tx)
+
+		user, err := txUserRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if user.MFAEnabled {
+			return apperrors.ErrMFAAlreadyEnabled
+		}
+
+		secret, err := totp.GenerateSecret()
+		if err != nil {
+			return err
+		}
+
+		if err := txMFARepo.SaveTOTP(ctx, models.NewTOTPCredential(userID, secret)); err != nil {
+			return fmt.Errorf("failed to save TOTP credential: %w", err)
+		}
+
+		enrollment = &models.TOTPEnrollment{
+			Secret:          secret,
+			ProvisioningURI: totp.ProvisioningURI(secret, s.mfaConfig.Issuer, user.Email),
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("user_id", userID.String()).
+		Msg("TOTP enrollment started")
+	return enrollment, nil
+}
+
+// ConfirmTOTP включает 2FA после проверки первого кода и возвращает коды
+// восстановления. Коды показываются один раз, в базе хранятся только их хеши.
+func (s *MFAService) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "MFAService.ConfirmTOTP")
+	defer span.End()
+
+	var recoveryCodes []string
+
+	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		txUserRepo := s.userRepo.WithTx(tx)
+		txMFARepo := s.mfaRepo.WithTx(tx)
+
+		user, err := txUserRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if user.MFAEnabled {
+			return apperrors.ErrMFAAlreadyEnabled
+		}
+
+		credential, err := txMFARepo.GetTOTP(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrTOTPNotFound) {
+				return apperrors.ErrMFAEnrollmentNotFound
+			}
+			return err
+		}
+
+		step, ok := totp.Validate(credential.Secret, code, time.Now(), totpSkew)
+		if !ok {
+			zerolog.Ctx(ctx).Info().
+				Str("user_id", userID.String()).
+				Msg("TOTP confirmation failed: invalid code")
+			return apperrors.ErrInvalidMFACode
+		}
+
+		if err := txMFARepo.ConfirmTOTP(ctx, userID, step); err != nil {
+			if errors.Is(err, repoerrors.ErrTOTPNotFound) {
+				return apperrors.ErrMFAEnrollmentNotFound
+			}
+			return fmt.Errorf("failed to confirm TOTP credential: %w", err)
+		}
+
+		codes, plain, err := models.NewRecoveryCodes(userID, models.RecoveryCodesCount)
+		if err != nil {
+			return err
+		}
+
+		if err := txMFARepo.ReplaceRecoveryCodes(ctx, userID, codes); err != nil {
+			return fmt.Errorf("failed to save recovery codes: %w", err)
+		}
+
+		if err := txUserRepo.SetMFAEnabled(ctx, userID, true); err != nil {
+			return fmt.Errorf("failed to enable MFA: %w", err)
+		}
+
+		if err := s.auditService.Record(ctx, tx, models.AuditActionMFAEnable, models.AuditEntityUser, userID.String(), mfaSnapshot(false), mfaSnapshot(true)); err != nil {
+			return err
+		}
+
+		recoveryCodes = plain
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("user_id", userID.String()).
+		Msg("Two-factor authentication enabled")
+	return recoveryCodes, nil
+}
+
+// DisableTOTP отключает 2FA после проверки текущего кода или кода восстановления.
+// Если политика требует 2FA для роли пользователя, отключение запрещено.
+func (s *MFAService) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	ctx, span := tracing.Start(ctx, "MFAService.DisableTOTP")
+	defer span.End()
+
+	return s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		txUserRepo := s.userRepo.WithTx(tx)
+		txMFARepo := s.mfaRepo.WithTx(tx)
+
+		user, err := txUserRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if !user.MFAEnabled {
+			return apperrors.ErrMFANotEnabled
+		}
+
+		if s.isMFARequired(user.Role) {
+			return apperrors.ErrMFACannotBeDisabled
+		}
+
+		if err := s.verifySecondFactor(ctx, txMFARepo, userID, code); err != nil {
+			return err
+		}
+
+		if err := txMFARepo.DeleteTOTP(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete TOTP credential: %w", err)
+		}
+
+		if err := txMFARepo.ReplaceRecoveryCodes(ctx, userID, nil); err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+
+		if err := txUserRepo.SetMFAEnabled(ctx, userID, false); err != nil {
+			return fmt.Errorf("failed to disable MFA: %w", err)
+		}
+
+		if err := s.auditService.Record(ctx, tx, models.AuditActionMFADisable, models.AuditEntityUser, userID.String(), mfaSnapshot(true), mfaSnapshot(false)); err != nil {
+			return err
+		}
+
+		zerolog.Ctx(ctx).Info().
+			Str("user_id", userID.String()).
+			Msg("Two-factor authentication disabled")
+		return nil
+	})
+}
+
+// CompleteLogin обменивает токен второго шага и код 2FA (или код восстановления)
+// на токен доступа. Токен второго шага одноразовый: после входа или исчерпания
+// попыток ввода кода он отклоняется.
+func (s *MFAService) CompleteLogin(ctx context.Context, challengeToken, code string) (string, error) {
+	ctx, span := tracing.Start(ctx, "MFAService.CompleteLogin")
+	defer span.End()
+
+	claims, err := auth.ValidateMFAChallengeToken(challengeToken, s.jwtConfig.Secret)
+	if err != nil {
+		return "", apperrors.ErrInvalidMFAChallenge
+	}
+
+	challengeID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return "", apperrors.ErrInvalidMFAChallenge
+	}
+
+	var user *models.User
+	// verifyErr - результат проверки неверного кода. Транзакция при этом фиксируется,
+	// чтобы попытка была засчитана.
+	var verifyErr error
+
+	err = s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		txUserRepo := s.userRepo.WithTx(tx)
+		txMFARepo := s.mfaRepo.WithTx(tx)
+
+		challenge, err := txMFARepo.LockChallenge(ctx, &models.MFAChallenge{
+			ID:        challengeID,
+			UserID:    claims.UserID,
+			ExpiresAt: claims.ExpiresAt.Time,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !challenge.CanAttempt(s.mfaConfig.ChallengeMaxAttempts) {
+			zerolog.Ctx(ctx).Info().
+				Str("user_id", claims.UserID.String()).
+				Int("failed_attempts", challenge.FailedAttempts).
+				Bool("used", challenge.UsedAt != nil).
+				Msg("MFA challenge rejected: already used or attempts exhausted")
+			return apperrors.ErrInvalidMFAChallenge
+		}
+
+		found, err := txUserRepo.GetByID(ctx, claims.UserID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrUserNotFound) {
+				return apperrors.ErrInvalidMFAChallenge
+			}
+			return err
+		}
+
+		if !found.MFAEnabled {
+			return apperrors.ErrInvalidMFAChallenge
+		}
+
+		if err := s.verifySecondFactor(ctx, txMFARepo, found.ID, code); err != nil {
+			if !errors.Is(err, apperrors.ErrInvalidMFACode) {
+				return err
+			}
+			verifyErr = err
+			return txMFARepo.RecordChallengeFailure(ctx, challengeID)
+		}
+
+		if err := txMFARepo.UseChallenge(ctx, challengeID); err != nil {
+			return err
+		}
+
+		user = found
+		return nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if verifyErr != nil {
+		return "", verifyErr
+	}
+
+	token, err := auth.GenerateMFAToken(user.ID, user.Role, user.Language, s.jwtConfig.Secret, s.jwtConfig.Expiration)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Str("user_id", user.ID.String()).
+			Msg("Failed to generate JWT token")
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("user_id", user.ID.String()).
+		Msg("User logged in with two-factor authentication")
+	return token, nil
+}
+
+// isMFARequired сообщает, обязана ли роль проходить второй фактор по политике сервиса.
+func (s *MFAService) isMFARequired(role string) bool {
+	return s.mfaConfig.RequiredForPrivileged && models.IsPrivilegedRole(role)
+}
+
+// verifySecondFactor принимает либо шестизначный код аутентификатора, либо код
+// восстановления. Принятый TOTP-код запоминается, чтобы его нельзя было использовать повторно.
+func (s *MFAService) verifySecondFactor(ctx context.Context, mfaRepo interfaces.MFARepository, userID uuid.UUID, code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return apperrors.ErrInvalidMFACode
+	}
+
+	if len(code) == totp.Digits {
+		credential, err := mfaRepo.GetTOTP(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrTOTPNotFound) {
+				return apperrors.ErrMFANotEnabled
+			}
+			return err
+		}
+
+		step, ok := totp.Validate(credential.Secret, code, time.Now(), totpSkew)
+		if !ok || step <= credential.LastUsedStep {
+			zerolog.Ctx(ctx).Info().
+				Str("user_id", userID.String()).
+				Msg("Second factor verification failed: invalid TOTP code")
+			return apperrors.ErrInvalidMFACode
+		}
+
+		if err := mfaRepo.UpdateLastUsedStep(ctx, userID, step); err != nil {
+			if errors.Is(err, repoerrors.ErrTOTPStepAlreadyUsed) {
+				return apperrors.ErrInvalidMFACode
+			}
+			return fmt.Errorf("failed to update TOTP step: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := mfaRepo.UseRecoveryCode(ctx, userID, models.HashRecoveryCode(code)); err != nil {
+		if errors.Is(err, repoerrors.ErrRecoveryCodeNotFound) {
+			zerolog.Ctx(ctx).Info().
+				Str("user_id", userID.String()).
+				Msg("Second factor verification failed: invalid recovery code")
+			return apperrors.ErrInvalidMFACode
+		}
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("user_id", userID.String()).
+		Msg("Recovery code used")
+	return nil
+}
+
+// mfaSnapshot - состояние 2FA пользователя для журнала аудита, без секретов.
+func mfaSnapshot(enabled bool) map[string]bool {
+	return map[string]bool{"mfaEnabled": enabled}
+}
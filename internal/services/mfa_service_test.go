@@ -0,0 +1,297 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/auth"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/services/mocks"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/totp"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+var testJWTConfig = config.JWTConfig{Secret: "test-secret", Expiration: time.Hour}
+
+func newTestMFAService(ctrl *gomock.Controller, mfaConfig config.MFAConfig) (*MFAService, *mocks.MockTxUserRepository, *mocks.MockTxMFARepository) {
+	mockUserRepo := mocks.NewMockTxUserRepository(ctrl)
+	mockMFARepo := mocks.NewMockTxMFARepository(ctrl)
+
+	mockTxManager := &MockTxManager{
+		RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
+			return fn(nil)
+		},
+	}
+
+	mockUserRepo.EXPECT().WithTx(gomock.Any()).Return(mockUserRepo).AnyTimes()
+	mockMFARepo.EXPECT().WithTx(gomock.Any()).Return(mockMFARepo).AnyTimes()
+
+	service := NewMFAService(mockUserRepo, mockMFARepo, testJWTConfig, mfaConfig, nil, mockTxManager)
+
+	return service, mockUserRepo, mockMFARepo
+}
+
+func TestMFAService_EnrollTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, userRepo, mfaRepo := newTestMFAService(ctrl, config.MFAConfig{Issuer: "PVZ Service"})
+	userID := uuid.New()
+
+	userRepo.EXPECT().GetByID(gomock.Any(), userID).
+		Return(&models.User{ID: userID, Email: "moderator@example.com"}, nil)
+	mfaRepo.EXPECT().SaveTOTP(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, credential *models.TOTPCredential) error {
+			assert.Equal(t, userID, credential.UserID)
+			assert.False(t, credential.IsConfirmed())
+			return nil
+		})
+
+	enrollment, err := service.EnrollTOTP(context.Background(), userID)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+	assert.Contains(t, enrollment.ProvisioningURI, "moderator@example.com")
+}
+
+func TestMFAService_ConfirmTOTP(t *testing.T) {
+	userID := uuid.New()
+	validCode, _ := totp.Code(testTOTPSecret, time.Now())
+
+	tests := []struct {
+		name        string
+		code        string
+		setupMocks  func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository)
+		expectedErr error
+	}{
+		{
+			name: "успешное подтверждение",
+			code: validCode,
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{ID: userID}, nil)
+				mfaRepo.EXPECT().GetTOTP(gomock.Any(), userID).
+					Return(&models.TOTPCredential{UserID: userID, Secret: testTOTPSecret}, nil)
+				mfaRepo.EXPECT().ConfirmTOTP(gomock.Any(), userID, gomock.Any()).Return(nil)
+				mfaRepo.EXPECT().ReplaceRecoveryCodes(gomock.Any(), userID, gomock.Len(models.RecoveryCodesCount)).Return(nil)
+				userRepo.EXPECT().SetMFAEnabled(gomock.Any(), userID, true).Return(nil)
+			},
+		},
+		{
+			name: "ошибка: неверный код",
+			code: "000000",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{ID: userID}, nil)
+				mfaRepo.EXPECT().GetTOTP(gomock.Any(), userID).
+					Return(&models.TOTPCredential{UserID: userID, Secret: testTOTPSecret}, nil)
+			},
+			expectedErr: apperrors.ErrInvalidMFACode,
+		},
+		{
+			name: "ошибка: регистрация не начата",
+			code: validCode,
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{ID: userID}, nil)
+				mfaRepo.EXPECT().GetTOTP(gomock.Any(), userID).Return(nil, repoerrors.ErrTOTPNotFound)
+			},
+			expectedErr: apperrors.ErrMFAEnrollmentNotFound,
+		},
+		{
+			name: "ошибка: 2FA уже включена",
+			code: validCode,
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{ID: userID, MFAEnabled: true}, nil)
+			},
+			expectedErr: apperrors.ErrMFAAlreadyEnabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, userRepo, mfaRepo := newTestMFAService(ctrl, config.MFAConfig{})
+			tt.setupMocks(userRepo, mfaRepo)
+
+			codes, err := service.ConfirmTOTP(context.Background(), userID, tt.code)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, codes, models.RecoveryCodesCount)
+		})
+	}
+}
+
+func TestMFAService_DisableTOTP(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name        string
+		mfaConfig   config.MFAConfig
+		role        string
+		setupMocks  func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository)
+		expectedErr error
+	}{
+		{
+			name: "успешное отключение кодом восстановления",
+			role: models.RoleModerator,
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {
+				mfaRepo.EXPECT().UseRecoveryCode(gomock.Any(), userID, models.HashRecoveryCode("abcde-12345")).Return(nil)
+				mfaRepo.EXPECT().DeleteTOTP(gomock.Any(), userID).Return(nil)
+				mfaRepo.EXPECT().ReplaceRecoveryCodes(gomock.Any(), userID, gomock.Nil()).Return(nil)
+				userRepo.EXPECT().SetMFAEnabled(gomock.Any(), userID, false).Return(nil)
+			},
+		},
+		{
+			name:        "ошибка: 2FA обязательна для модератора",
+			mfaConfig:   config.MFAConfig{RequiredForPrivileged: true},
+			role:        models.RoleModerator,
+			setupMocks:  func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {},
+			expectedErr: apperrors.ErrMFACannotBeDisabled,
+		},
+		{
+			name: "ошибка: неверный код восстановления",
+			role: models.RoleEmployee,
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {
+				mfaRepo.EXPECT().UseRecoveryCode(gomock.Any(), userID, gomock.Any()).Return(repoerrors.ErrRecoveryCodeNotFound)
+			},
+			expectedErr: apperrors.ErrInvalidMFACode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, userRepo, mfaRepo := newTestMFAService(ctrl, tt.mfaConfig)
+			userRepo.EXPECT().GetByID(gomock.Any(), userID).
+				Return(&models.User{ID: userID, Role: tt.role, MFAEnabled: true}, nil)
+			tt.setupMocks(userRepo, mfaRepo)
+
+			err := service.DisableTOTP(context.Background(), userID, "abcde-12345")
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMFAService_CompleteLogin(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+	validCode, _ := totp.Code(testTOTPSecret, now)
+	challenge, _ := auth.GenerateMFAChallengeToken(userID, models.RoleModerator, testJWTConfig.Secret, time.Minute)
+	challengeClaims, _ := auth.ValidateMFAChallengeToken(challenge, testJWTConfig.Secret)
+	challengeID := uuid.MustParse(challengeClaims.ID)
+	accessToken, _ := auth.GenerateToken(userID, models.RoleModerator, "", testJWTConfig.Secret, time.Hour)
+	user := &models.User{ID: userID, Role: models.RoleModerator, MFAEnabled: true}
+
+	lockChallenge := func(mfaRepo *mocks.MockTxMFARepository, failedAttempts int, usedAt *time.Time) {
+		mfaRepo.EXPECT().LockChallenge(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, c *models.MFAChallenge) (*models.MFAChallenge, error) {
+				assert.Equal(t, challengeID, c.ID)
+				assert.Equal(t, userID, c.UserID)
+				return &models.MFAChallenge{ID: c.ID, UserID: c.UserID, FailedAttempts: failedAttempts, UsedAt: usedAt, ExpiresAt: c.ExpiresAt}, nil
+			})
+	}
+
+	tests := []struct {
+		name        string
+		challenge   string
+		code        string
+		setupMocks  func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository)
+		expectedErr error
+	}{
+		{
+			name:      "успешный вход по TOTP-коду",
+			challenge: challenge,
+			code:      validCode,
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {
+				lockChallenge(mfaRepo, 0, nil)
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).Return(user, nil)
+				mfaRepo.EXPECT().GetTOTP(gomock.Any(), userID).
+					Return(&models.TOTPCredential{UserID: userID, Secret: testTOTPSecret}, nil)
+				mfaRepo.EXPECT().UpdateLastUsedStep(gomock.Any(), userID, gomock.Any()).Return(nil)
+				mfaRepo.EXPECT().UseChallenge(gomock.Any(), challengeID).Return(nil)
+			},
+		},
+		{
+			name:      "ошибка: повторное использование кода засчитывается как неверная попытка",
+			challenge: challenge,
+			code:      validCode,
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {
+				lockChallenge(mfaRepo, 0, nil)
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).Return(user, nil)
+				mfaRepo.EXPECT().GetTOTP(gomock.Any(), userID).
+					Return(&models.TOTPCredential{UserID: userID, Secret: testTOTPSecret, LastUsedStep: totp.Step(now) + 1}, nil)
+				mfaRepo.EXPECT().RecordChallengeFailure(gomock.Any(), challengeID).Return(nil)
+			},
+			expectedErr: apperrors.ErrInvalidMFACode,
+		},
+		{
+			name:      "ошибка: токен второго шага уже использован",
+			challenge: challenge,
+			code:      validCode,
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {
+				usedAt := now
+				lockChallenge(mfaRepo, 0, &usedAt)
+			},
+			expectedErr: apperrors.ErrInvalidMFAChallenge,
+		},
+		{
+			name:      "ошибка: попытки ввода кода исчерпаны",
+			challenge: challenge,
+			code:      validCode,
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {
+				lockChallenge(mfaRepo, 3, nil)
+			},
+			expectedErr: apperrors.ErrInvalidMFAChallenge,
+		},
+		{
+			name:        "ошибка: вместо токена второго шага передан токен доступа",
+			challenge:   accessToken,
+			code:        validCode,
+			setupMocks:  func(userRepo *mocks.MockTxUserRepository, mfaRepo *mocks.MockTxMFARepository) {},
+			expectedErr: apperrors.ErrInvalidMFAChallenge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, userRepo, mfaRepo := newTestMFAService(ctrl, config.MFAConfig{ChallengeMaxAttempts: 3})
+			tt.setupMocks(userRepo, mfaRepo)
+
+			token, err := service.CompleteLogin(context.Background(), tt.challenge, tt.code)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			claims, err := auth.ValidateToken(token, testJWTConfig.Secret)
+			assert.NoError(t, err)
+			assert.True(t, claims.MFA)
+		})
+	}
+}
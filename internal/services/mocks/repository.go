@@ -10,6 +10,7 @@ import (
 	context "context"
 	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -38,6 +39,21 @@ func (m *MockProductRepository) EXPECT() *MockProductRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountByPVZID mocks base method.
+func (m *MockProductRepository) CountByPVZID(ctx context.Context, pvzID uuid.UUID, since time.Time) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByPVZID", ctx, pvzID, since)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByPVZID indicates an expected call of CountByPVZID.
+func (mr *MockProductRepositoryMockRecorder) CountByPVZID(ctx, pvzID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByPVZID", reflect.TypeOf((*MockProductRepository)(nil).CountByPVZID), ctx, pvzID, since)
+}
+
 // Create mocks base method.
 func (m *MockProductRepository) Create(ctx context.Context, product *models.Product) error {
 	m.ctrl.T.Helper()
@@ -119,6 +135,21 @@ func (m *MockTxProductRepository) EXPECT() *MockTxProductRepositoryMockRecorder
 	return m.recorder
 }
 
+// CountByPVZID mocks base method.
+func (m *MockTxProductRepository) CountByPVZID(ctx context.Context, pvzID uuid.UUID, since time.Time) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByPVZID", ctx, pvzID, since)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByPVZID indicates an expected call of CountByPVZID.
+func (mr *MockTxProductRepositoryMockRecorder) CountByPVZID(ctx, pvzID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByPVZID", reflect.TypeOf((*MockTxProductRepository)(nil).CountByPVZID), ctx, pvzID, since)
+}
+
 // Create mocks base method.
 func (m *MockTxProductRepository) Create(ctx context.Context, product *models.Product) error {
 	m.ctrl.T.Helper()
@@ -215,17 +246,17 @@ func (m *MockReceptionRepository) EXPECT() *MockReceptionRepositoryMockRecorder
 }
 
 // CloseReception mocks base method.
-func (m *MockReceptionRepository) CloseReception(ctx context.Context, id uuid.UUID) error {
+func (m *MockReceptionRepository) CloseReception(ctx context.Context, id uuid.UUID, version int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CloseReception", ctx, id)
+	ret := m.ctrl.Call(m, "CloseReception", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CloseReception indicates an expected call of CloseReception.
-func (mr *MockReceptionRepositoryMockRecorder) CloseReception(ctx, id interface{}) *gomock.Call {
+func (mr *MockReceptionRepositoryMockRecorder) CloseReception(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReception", reflect.TypeOf((*MockReceptionRepository)(nil).CloseReception), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReception", reflect.TypeOf((*MockReceptionRepository)(nil).CloseReception), ctx, id, version)
 }
 
 // Create mocks base method.
@@ -287,6 +318,22 @@ func (mr *MockReceptionRepositoryMockRecorder) GetLastReceptionByPVZID(ctx, pvzI
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastReceptionByPVZID", reflect.TypeOf((*MockReceptionRepository)(nil).GetLastReceptionByPVZID), ctx, pvzID)
 }
 
+// ListByPVZID mocks base method.
+func (m *MockReceptionRepository) ListByPVZID(ctx context.Context, pvzID uuid.UUID, filter models.ReceptionFilter) ([]*models.Reception, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPVZID", ctx, pvzID, filter)
+	ret0, _ := ret[0].([]*models.Reception)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListByPVZID indicates an expected call of ListByPVZID.
+func (mr *MockReceptionRepositoryMockRecorder) ListByPVZID(ctx, pvzID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPVZID", reflect.TypeOf((*MockReceptionRepository)(nil).ListByPVZID), ctx, pvzID, filter)
+}
+
 // MockTxReceptionRepository is a mock of TxReceptionRepository interface.
 type MockTxReceptionRepository struct {
 	ctrl     *gomock.Controller
@@ -311,17 +358,17 @@ func (m *MockTxReceptionRepository) EXPECT() *MockTxReceptionRepositoryMockRecor
 }
 
 // CloseReception mocks base method.
-func (m *MockTxReceptionRepository) CloseReception(ctx context.Context, id uuid.UUID) error {
+func (m *MockTxReceptionRepository) CloseReception(ctx context.Context, id uuid.UUID, version int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CloseReception", ctx, id)
+	ret := m.ctrl.Call(m, "CloseReception", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CloseReception indicates an expected call of CloseReception.
-func (mr *MockTxReceptionRepositoryMockRecorder) CloseReception(ctx, id interface{}) *gomock.Call {
+func (mr *MockTxReceptionRepositoryMockRecorder) CloseReception(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReception", reflect.TypeOf((*MockTxReceptionRepository)(nil).CloseReception), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReception", reflect.TypeOf((*MockTxReceptionRepository)(nil).CloseReception), ctx, id, version)
 }
 
 // Create mocks base method.
@@ -383,6 +430,22 @@ func (mr *MockTxReceptionRepositoryMockRecorder) GetLastReceptionByPVZID(ctx, pv
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastReceptionByPVZID", reflect.TypeOf((*MockTxReceptionRepository)(nil).GetLastReceptionByPVZID), ctx, pvzID)
 }
 
+// ListByPVZID mocks base method.
+func (m *MockTxReceptionRepository) ListByPVZID(ctx context.Context, pvzID uuid.UUID, filter models.ReceptionFilter) ([]*models.Reception, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPVZID", ctx, pvzID, filter)
+	ret0, _ := ret[0].([]*models.Reception)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListByPVZID indicates an expected call of ListByPVZID.
+func (mr *MockTxReceptionRepositoryMockRecorder) ListByPVZID(ctx, pvzID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPVZID", reflect.TypeOf((*MockTxReceptionRepository)(nil).ListByPVZID), ctx, pvzID, filter)
+}
+
 // WithTx mocks base method.
 func (m *MockTxReceptionRepository) WithTx(tx *sql.Tx) interfaces.ReceptionRepository {
 	m.ctrl.T.Helper()
@@ -434,12 +497,27 @@ func (mr *MockPVZRepositoryMockRecorder) Create(ctx, pvz interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPVZRepository)(nil).Create), ctx, pvz)
 }
 
+// FindNearby mocks base method.
+func (m *MockPVZRepository) FindNearby(ctx context.Context, filter models.NearbyFilter) ([]models.PVZWithDistance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindNearby", ctx, filter)
+	ret0, _ := ret[0].([]models.PVZWithDistance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindNearby indicates an expected call of FindNearby.
+func (mr *MockPVZRepositoryMockRecorder) FindNearby(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindNearby", reflect.TypeOf((*MockPVZRepository)(nil).FindNearby), ctx, filter)
+}
+
 // GetAll mocks base method.
-func (m *MockPVZRepository) GetAll(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, int, error) {
+func (m *MockPVZRepository) GetAll(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, *models.PVZPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAll", ctx, filter)
 	ret0, _ := ret[0].([]*models.PVZ)
-	ret1, _ := ret[1].(int)
+	ret1, _ := ret[1].(*models.PVZPage)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
@@ -451,11 +529,11 @@ func (mr *MockPVZRepositoryMockRecorder) GetAll(ctx, filter interface{}) *gomock
 }
 
 // GetAllWithReceptions mocks base method.
-func (m *MockPVZRepository) GetAllWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, int, error) {
+func (m *MockPVZRepository) GetAllWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAllWithReceptions", ctx, filter)
 	ret0, _ := ret[0].([]models.PVZWithReceptions)
-	ret1, _ := ret[1].(int)
+	ret1, _ := ret[1].(*models.PVZPage)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
@@ -481,6 +559,50 @@ func (mr *MockPVZRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockPVZRepository)(nil).GetByID), ctx, id)
 }
 
+// GetByIDForUpdate mocks base method.
+func (m *MockPVZRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.PVZ, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(*models.PVZ)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDForUpdate indicates an expected call of GetByIDForUpdate.
+func (mr *MockPVZRepositoryMockRecorder) GetByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDForUpdate", reflect.TypeOf((*MockPVZRepository)(nil).GetByIDForUpdate), ctx, id)
+}
+
+// ListUtilization mocks base method.
+func (m *MockPVZRepository) ListUtilization(ctx context.Context, since time.Time, allowedIDs []uuid.UUID) ([]models.PVZUtilization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUtilization", ctx, since, allowedIDs)
+	ret0, _ := ret[0].([]models.PVZUtilization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUtilization indicates an expected call of ListUtilization.
+func (mr *MockPVZRepositoryMockRecorder) ListUtilization(ctx, since, allowedIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUtilization", reflect.TypeOf((*MockPVZRepository)(nil).ListUtilization), ctx, since, allowedIDs)
+}
+
+// Update mocks base method.
+func (m *MockPVZRepository) Update(ctx context.Context, pvz *models.PVZ) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, pvz)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockPVZRepositoryMockRecorder) Update(ctx, pvz interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPVZRepository)(nil).Update), ctx, pvz)
+}
+
 // MockTxPVZRepository is a mock of TxPVZRepository interface.
 type MockTxPVZRepository struct {
 	ctrl     *gomock.Controller
@@ -518,12 +640,27 @@ func (mr *MockTxPVZRepositoryMockRecorder) Create(ctx, pvz interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTxPVZRepository)(nil).Create), ctx, pvz)
 }
 
+// FindNearby mocks base method.
+func (m *MockTxPVZRepository) FindNearby(ctx context.Context, filter models.NearbyFilter) ([]models.PVZWithDistance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindNearby", ctx, filter)
+	ret0, _ := ret[0].([]models.PVZWithDistance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindNearby indicates an expected call of FindNearby.
+func (mr *MockTxPVZRepositoryMockRecorder) FindNearby(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindNearby", reflect.TypeOf((*MockTxPVZRepository)(nil).FindNearby), ctx, filter)
+}
+
 // GetAll mocks base method.
-func (m *MockTxPVZRepository) GetAll(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, int, error) {
+func (m *MockTxPVZRepository) GetAll(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, *models.PVZPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAll", ctx, filter)
 	ret0, _ := ret[0].([]*models.PVZ)
-	ret1, _ := ret[1].(int)
+	ret1, _ := ret[1].(*models.PVZPage)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
@@ -535,11 +672,11 @@ func (mr *MockTxPVZRepositoryMockRecorder) GetAll(ctx, filter interface{}) *gomo
 }
 
 // GetAllWithReceptions mocks base method.
-func (m *MockTxPVZRepository) GetAllWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, int, error) {
+func (m *MockTxPVZRepository) GetAllWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAllWithReceptions", ctx, filter)
 	ret0, _ := ret[0].([]models.PVZWithReceptions)
-	ret1, _ := ret[1].(int)
+	ret1, _ := ret[1].(*models.PVZPage)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
@@ -565,6 +702,50 @@ func (mr *MockTxPVZRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockTxPVZRepository)(nil).GetByID), ctx, id)
 }
 
+// GetByIDForUpdate mocks base method.
+func (m *MockTxPVZRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.PVZ, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(*models.PVZ)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDForUpdate indicates an expected call of GetByIDForUpdate.
+func (mr *MockTxPVZRepositoryMockRecorder) GetByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDForUpdate", reflect.TypeOf((*MockTxPVZRepository)(nil).GetByIDForUpdate), ctx, id)
+}
+
+// ListUtilization mocks base method.
+func (m *MockTxPVZRepository) ListUtilization(ctx context.Context, since time.Time, allowedIDs []uuid.UUID) ([]models.PVZUtilization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUtilization", ctx, since, allowedIDs)
+	ret0, _ := ret[0].([]models.PVZUtilization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUtilization indicates an expected call of ListUtilization.
+func (mr *MockTxPVZRepositoryMockRecorder) ListUtilization(ctx, since, allowedIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUtilization", reflect.TypeOf((*MockTxPVZRepository)(nil).ListUtilization), ctx, since, allowedIDs)
+}
+
+// Update mocks base method.
+func (m *MockTxPVZRepository) Update(ctx context.Context, pvz *models.PVZ) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, pvz)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTxPVZRepositoryMockRecorder) Update(ctx, pvz interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTxPVZRepository)(nil).Update), ctx, pvz)
+}
+
 // WithTx mocks base method.
 func (m *MockTxPVZRepository) WithTx(tx *sql.Tx) interfaces.PVZRepository {
 	m.ctrl.T.Helper()
@@ -660,6 +841,62 @@ func (mr *MockUserRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserRepository)(nil).GetByID), ctx, id)
 }
 
+// SetLanguage mocks base method.
+func (m *MockUserRepository) SetLanguage(ctx context.Context, id uuid.UUID, language string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLanguage", ctx, id, language)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLanguage indicates an expected call of SetLanguage.
+func (mr *MockUserRepositoryMockRecorder) SetLanguage(ctx, id, language interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLanguage", reflect.TypeOf((*MockUserRepository)(nil).SetLanguage), ctx, id, language)
+}
+
+// SetMFAEnabled mocks base method.
+func (m *MockUserRepository) SetMFAEnabled(ctx context.Context, id uuid.UUID, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMFAEnabled", ctx, id, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMFAEnabled indicates an expected call of SetMFAEnabled.
+func (mr *MockUserRepositoryMockRecorder) SetMFAEnabled(ctx, id, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMFAEnabled", reflect.TypeOf((*MockUserRepository)(nil).SetMFAEnabled), ctx, id, enabled)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, user *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserRepositoryMockRecorder) UpdatePassword(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepository)(nil).UpdatePassword), ctx, user)
+}
+
+// UpdatePasswordHash mocks base method.
+func (m *MockUserRepository) UpdatePasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, id, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockUserRepositoryMockRecorder) UpdatePasswordHash(ctx, id, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockUserRepository)(nil).UpdatePasswordHash), ctx, id, passwordHash)
+}
+
 // MockTxUserRepository is a mock of TxUserRepository interface.
 type MockTxUserRepository struct {
 	ctrl     *gomock.Controller
@@ -741,6 +978,62 @@ func (mr *MockTxUserRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockTxUserRepository)(nil).GetByID), ctx, id)
 }
 
+// SetLanguage mocks base method.
+func (m *MockTxUserRepository) SetLanguage(ctx context.Context, id uuid.UUID, language string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLanguage", ctx, id, language)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLanguage indicates an expected call of SetLanguage.
+func (mr *MockTxUserRepositoryMockRecorder) SetLanguage(ctx, id, language interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLanguage", reflect.TypeOf((*MockTxUserRepository)(nil).SetLanguage), ctx, id, language)
+}
+
+// SetMFAEnabled mocks base method.
+func (m *MockTxUserRepository) SetMFAEnabled(ctx context.Context, id uuid.UUID, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMFAEnabled", ctx, id, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMFAEnabled indicates an expected call of SetMFAEnabled.
+func (mr *MockTxUserRepositoryMockRecorder) SetMFAEnabled(ctx, id, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMFAEnabled", reflect.TypeOf((*MockTxUserRepository)(nil).SetMFAEnabled), ctx, id, enabled)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockTxUserRepository) UpdatePassword(ctx context.Context, user *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockTxUserRepositoryMockRecorder) UpdatePassword(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockTxUserRepository)(nil).UpdatePassword), ctx, user)
+}
+
+// UpdatePasswordHash mocks base method.
+func (m *MockTxUserRepository) UpdatePasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, id, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockTxUserRepositoryMockRecorder) UpdatePasswordHash(ctx, id, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockTxUserRepository)(nil).UpdatePasswordHash), ctx, id, passwordHash)
+}
+
 // WithTx mocks base method.
 func (m *MockTxUserRepository) WithTx(tx *sql.Tx) interfaces.UserRepository {
 	m.ctrl.T.Helper()
@@ -754,3 +1047,1468 @@ func (mr *MockTxUserRepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockTxUserRepository)(nil).WithTx), tx)
 }
+
+// MockPasswordResetRepository is a mock of PasswordResetRepository interface.
+type MockPasswordResetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetRepositoryMockRecorder
+}
+
+// MockPasswordResetRepositoryMockRecorder is the mock recorder for MockPasswordResetRepository.
+type MockPasswordResetRepositoryMockRecorder struct {
+	mock *MockPasswordResetRepository
+}
+
+// NewMockPasswordResetRepository creates a new mock instance.
+func NewMockPasswordResetRepository(ctrl *gomock.Controller) *MockPasswordResetRepository {
+	mock := &MockPasswordResetRepository{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetRepository) EXPECT() *MockPasswordResetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPasswordResetRepository) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPasswordResetRepositoryMockRecorder) Create(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPasswordResetRepository)(nil).Create), ctx, token)
+}
+
+// GetByTokenHash mocks base method.
+func (m *MockPasswordResetRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*models.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByTokenHash indicates an expected call of GetByTokenHash.
+func (mr *MockPasswordResetRepositoryMockRecorder) GetByTokenHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTokenHash", reflect.TypeOf((*MockPasswordResetRepository)(nil).GetByTokenHash), ctx, tokenHash)
+}
+
+// InvalidateByUserID mocks base method.
+func (m *MockPasswordResetRepository) InvalidateByUserID(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateByUserID indicates an expected call of InvalidateByUserID.
+func (mr *MockPasswordResetRepositoryMockRecorder) InvalidateByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateByUserID", reflect.TypeOf((*MockPasswordResetRepository)(nil).InvalidateByUserID), ctx, userID)
+}
+
+// MarkUsed mocks base method.
+func (m *MockPasswordResetRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUsed indicates an expected call of MarkUsed.
+func (mr *MockPasswordResetRepositoryMockRecorder) MarkUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockPasswordResetRepository)(nil).MarkUsed), ctx, id)
+}
+
+// MockTxPasswordResetRepository is a mock of TxPasswordResetRepository interface.
+type MockTxPasswordResetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxPasswordResetRepositoryMockRecorder
+}
+
+// MockTxPasswordResetRepositoryMockRecorder is the mock recorder for MockTxPasswordResetRepository.
+type MockTxPasswordResetRepositoryMockRecorder struct {
+	mock *MockTxPasswordResetRepository
+}
+
+// NewMockTxPasswordResetRepository creates a new mock instance.
+func NewMockTxPasswordResetRepository(ctrl *gomock.Controller) *MockTxPasswordResetRepository {
+	mock := &MockTxPasswordResetRepository{ctrl: ctrl}
+	mock.recorder = &MockTxPasswordResetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxPasswordResetRepository) EXPECT() *MockTxPasswordResetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTxPasswordResetRepository) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTxPasswordResetRepositoryMockRecorder) Create(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTxPasswordResetRepository)(nil).Create), ctx, token)
+}
+
+// GetByTokenHash mocks base method.
+func (m *MockTxPasswordResetRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*models.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByTokenHash indicates an expected call of GetByTokenHash.
+func (mr *MockTxPasswordResetRepositoryMockRecorder) GetByTokenHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTokenHash", reflect.TypeOf((*MockTxPasswordResetRepository)(nil).GetByTokenHash), ctx, tokenHash)
+}
+
+// InvalidateByUserID mocks base method.
+func (m *MockTxPasswordResetRepository) InvalidateByUserID(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateByUserID indicates an expected call of InvalidateByUserID.
+func (mr *MockTxPasswordResetRepositoryMockRecorder) InvalidateByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateByUserID", reflect.TypeOf((*MockTxPasswordResetRepository)(nil).InvalidateByUserID), ctx, userID)
+}
+
+// MarkUsed mocks base method.
+func (m *MockTxPasswordResetRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUsed indicates an expected call of MarkUsed.
+func (mr *MockTxPasswordResetRepositoryMockRecorder) MarkUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockTxPasswordResetRepository)(nil).MarkUsed), ctx, id)
+}
+
+// WithTx mocks base method.
+func (m *MockTxPasswordResetRepository) WithTx(tx *sql.Tx) interfaces.PasswordResetRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(interfaces.PasswordResetRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockTxPasswordResetRepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockTxPasswordResetRepository)(nil).WithTx), tx)
+}
+
+// MockMFARepository is a mock of MFARepository interface.
+type MockMFARepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFARepositoryMockRecorder
+}
+
+// MockMFARepositoryMockRecorder is the mock recorder for MockMFARepository.
+type MockMFARepositoryMockRecorder struct {
+	mock *MockMFARepository
+}
+
+// NewMockMFARepository creates a new mock instance.
+func NewMockMFARepository(ctrl *gomock.Controller) *MockMFARepository {
+	mock := &MockMFARepository{ctrl: ctrl}
+	mock.recorder = &MockMFARepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFARepository) EXPECT() *MockMFARepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockMFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockMFARepositoryMockRecorder) ConfirmTOTP(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockMFARepository)(nil).ConfirmTOTP), ctx, userID, step)
+}
+
+// DeleteTOTP mocks base method.
+func (m *MockMFARepository) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTP", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockMFARepositoryMockRecorder) DeleteTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockMFARepository)(nil).DeleteTOTP), ctx, userID)
+}
+
+// GetTOTP mocks base method.
+func (m *MockMFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, userID)
+	ret0, _ := ret[0].(*models.TOTPCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockMFARepositoryMockRecorder) GetTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockMFARepository)(nil).GetTOTP), ctx, userID)
+}
+
+// LockChallenge mocks base method.
+func (m *MockMFARepository) LockChallenge(ctx context.Context, challenge *models.MFAChallenge) (*models.MFAChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockChallenge", ctx, challenge)
+	ret0, _ := ret[0].(*models.MFAChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockChallenge indicates an expected call of LockChallenge.
+func (mr *MockMFARepositoryMockRecorder) LockChallenge(ctx, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockChallenge", reflect.TypeOf((*MockMFARepository)(nil).LockChallenge), ctx, challenge)
+}
+
+// RecordChallengeFailure mocks base method.
+func (m *MockMFARepository) RecordChallengeFailure(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordChallengeFailure", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordChallengeFailure indicates an expected call of RecordChallengeFailure.
+func (mr *MockMFARepositoryMockRecorder) RecordChallengeFailure(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordChallengeFailure", reflect.TypeOf((*MockMFARepository)(nil).RecordChallengeFailure), ctx, id)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*models.RecoveryCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userID, codes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockMFARepositoryMockRecorder) ReplaceRecoveryCodes(ctx, userID, codes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockMFARepository)(nil).ReplaceRecoveryCodes), ctx, userID, codes)
+}
+
+// SaveTOTP mocks base method.
+func (m *MockMFARepository) SaveTOTP(ctx context.Context, credential *models.TOTPCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTP", ctx, credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTP indicates an expected call of SaveTOTP.
+func (mr *MockMFARepositoryMockRecorder) SaveTOTP(ctx, credential interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTP", reflect.TypeOf((*MockMFARepository)(nil).SaveTOTP), ctx, credential)
+}
+
+// UpdateLastUsedStep mocks base method.
+func (m *MockMFARepository) UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastUsedStep", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastUsedStep indicates an expected call of UpdateLastUsedStep.
+func (mr *MockMFARepositoryMockRecorder) UpdateLastUsedStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastUsedStep", reflect.TypeOf((*MockMFARepository)(nil).UpdateLastUsedStep), ctx, userID, step)
+}
+
+// UseChallenge mocks base method.
+func (m *MockMFARepository) UseChallenge(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseChallenge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseChallenge indicates an expected call of UseChallenge.
+func (mr *MockMFARepositoryMockRecorder) UseChallenge(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseChallenge", reflect.TypeOf((*MockMFARepository)(nil).UseChallenge), ctx, id)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockMFARepositoryMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockMFARepository)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// MockTxMFARepository is a mock of TxMFARepository interface.
+type MockTxMFARepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxMFARepositoryMockRecorder
+}
+
+// MockTxMFARepositoryMockRecorder is the mock recorder for MockTxMFARepository.
+type MockTxMFARepositoryMockRecorder struct {
+	mock *MockTxMFARepository
+}
+
+// NewMockTxMFARepository creates a new mock instance.
+func NewMockTxMFARepository(ctrl *gomock.Controller) *MockTxMFARepository {
+	mock := &MockTxMFARepository{ctrl: ctrl}
+	mock.recorder = &MockTxMFARepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxMFARepository) EXPECT() *MockTxMFARepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockTxMFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockTxMFARepositoryMockRecorder) ConfirmTOTP(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockTxMFARepository)(nil).ConfirmTOTP), ctx, userID, step)
+}
+
+// DeleteTOTP mocks base method.
+func (m *MockTxMFARepository) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTP", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockTxMFARepositoryMockRecorder) DeleteTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockTxMFARepository)(nil).DeleteTOTP), ctx, userID)
+}
+
+// GetTOTP mocks base method.
+func (m *MockTxMFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, userID)
+	ret0, _ := ret[0].(*models.TOTPCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockTxMFARepositoryMockRecorder) GetTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockTxMFARepository)(nil).GetTOTP), ctx, userID)
+}
+
+// LockChallenge mocks base method.
+func (m *MockTxMFARepository) LockChallenge(ctx context.Context, challenge *models.MFAChallenge) (*models.MFAChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockChallenge", ctx, challenge)
+	ret0, _ := ret[0].(*models.MFAChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockChallenge indicates an expected call of LockChallenge.
+func (mr *MockTxMFARepositoryMockRecorder) LockChallenge(ctx, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockChallenge", reflect.TypeOf((*MockTxMFARepository)(nil).LockChallenge), ctx, challenge)
+}
+
+// RecordChallengeFailure mocks base method.
+func (m *MockTxMFARepository) RecordChallengeFailure(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordChallengeFailure", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordChallengeFailure indicates an expected call of RecordChallengeFailure.
+func (mr *MockTxMFARepositoryMockRecorder) RecordChallengeFailure(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordChallengeFailure", reflect.TypeOf((*MockTxMFARepository)(nil).RecordChallengeFailure), ctx, id)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockTxMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*models.RecoveryCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userID, codes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockTxMFARepositoryMockRecorder) ReplaceRecoveryCodes(ctx, userID, codes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockTxMFARepository)(nil).ReplaceRecoveryCodes), ctx, userID, codes)
+}
+
+// SaveTOTP mocks base method.
+func (m *MockTxMFARepository) SaveTOTP(ctx context.Context, credential *models.TOTPCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTP", ctx, credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTP indicates an expected call of SaveTOTP.
+func (mr *MockTxMFARepositoryMockRecorder) SaveTOTP(ctx, credential interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTP", reflect.TypeOf((*MockTxMFARepository)(nil).SaveTOTP), ctx, credential)
+}
+
+// UpdateLastUsedStep mocks base method.
+func (m *MockTxMFARepository) UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastUsedStep", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastUsedStep indicates an expected call of UpdateLastUsedStep.
+func (mr *MockTxMFARepositoryMockRecorder) UpdateLastUsedStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastUsedStep", reflect.TypeOf((*MockTxMFARepository)(nil).UpdateLastUsedStep), ctx, userID, step)
+}
+
+// UseChallenge mocks base method.
+func (m *MockTxMFARepository) UseChallenge(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseChallenge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseChallenge indicates an expected call of UseChallenge.
+func (mr *MockTxMFARepositoryMockRecorder) UseChallenge(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseChallenge", reflect.TypeOf((*MockTxMFARepository)(nil).UseChallenge), ctx, id)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTxMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTxMFARepositoryMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTxMFARepository)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// WithTx mocks base method.
+func (m *MockTxMFARepository) WithTx(tx *sql.Tx) interfaces.MFARepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(interfaces.MFARepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockTxMFARepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockTxMFARepository)(nil).WithTx), tx)
+}
+
+// MockServiceAccountRepository is a mock of ServiceAccountRepository interface.
+type MockServiceAccountRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceAccountRepositoryMockRecorder
+}
+
+// MockServiceAccountRepositoryMockRecorder is the mock recorder for MockServiceAccountRepository.
+type MockServiceAccountRepositoryMockRecorder struct {
+	mock *MockServiceAccountRepository
+}
+
+// NewMockServiceAccountRepository creates a new mock instance.
+func NewMockServiceAccountRepository(ctrl *gomock.Controller) *MockServiceAccountRepository {
+	mock := &MockServiceAccountRepository{ctrl: ctrl}
+	mock.recorder = &MockServiceAccountRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceAccountRepository) EXPECT() *MockServiceAccountRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAccount mocks base method.
+func (m *MockServiceAccountRepository) CreateAccount(ctx context.Context, account *models.ServiceAccount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockServiceAccountRepositoryMockRecorder) CreateAccount(ctx, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockServiceAccountRepository)(nil).CreateAccount), ctx, account)
+}
+
+// CreateKey mocks base method.
+func (m *MockServiceAccountRepository) CreateKey(ctx context.Context, key *models.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *MockServiceAccountRepositoryMockRecorder) CreateKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*MockServiceAccountRepository)(nil).CreateKey), ctx, key)
+}
+
+// GetAccountByID mocks base method.
+func (m *MockServiceAccountRepository) GetAccountByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByID", ctx, id)
+	ret0, _ := ret[0].(*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByID indicates an expected call of GetAccountByID.
+func (mr *MockServiceAccountRepositoryMockRecorder) GetAccountByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByID", reflect.TypeOf((*MockServiceAccountRepository)(nil).GetAccountByID), ctx, id)
+}
+
+// GetKeyByHash mocks base method.
+func (m *MockServiceAccountRepository) GetKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyByHash", ctx, keyHash)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyByHash indicates an expected call of GetKeyByHash.
+func (mr *MockServiceAccountRepositoryMockRecorder) GetKeyByHash(ctx, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyByHash", reflect.TypeOf((*MockServiceAccountRepository)(nil).GetKeyByHash), ctx, keyHash)
+}
+
+// ListAccounts mocks base method.
+func (m *MockServiceAccountRepository) ListAccounts(ctx context.Context) ([]*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", ctx)
+	ret0, _ := ret[0].([]*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockServiceAccountRepositoryMockRecorder) ListAccounts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockServiceAccountRepository)(nil).ListAccounts), ctx)
+}
+
+// ListKeys mocks base method.
+func (m *MockServiceAccountRepository) ListKeys(ctx context.Context, serviceAccountID uuid.UUID) ([]*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeys", ctx, serviceAccountID)
+	ret0, _ := ret[0].([]*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKeys indicates an expected call of ListKeys.
+func (mr *MockServiceAccountRepositoryMockRecorder) ListKeys(ctx, serviceAccountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeys", reflect.TypeOf((*MockServiceAccountRepository)(nil).ListKeys), ctx, serviceAccountID)
+}
+
+// RevokeKey mocks base method.
+func (m *MockServiceAccountRepository) RevokeKey(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *MockServiceAccountRepositoryMockRecorder) RevokeKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*MockServiceAccountRepository)(nil).RevokeKey), ctx, id)
+}
+
+// TouchKey mocks base method.
+func (m *MockServiceAccountRepository) TouchKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchKey", ctx, id, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchKey indicates an expected call of TouchKey.
+func (mr *MockServiceAccountRepositoryMockRecorder) TouchKey(ctx, id, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchKey", reflect.TypeOf((*MockServiceAccountRepository)(nil).TouchKey), ctx, id, usedAt)
+}
+
+// MockTxServiceAccountRepository is a mock of TxServiceAccountRepository interface.
+type MockTxServiceAccountRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxServiceAccountRepositoryMockRecorder
+}
+
+// MockTxServiceAccountRepositoryMockRecorder is the mock recorder for MockTxServiceAccountRepository.
+type MockTxServiceAccountRepositoryMockRecorder struct {
+	mock *MockTxServiceAccountRepository
+}
+
+// NewMockTxServiceAccountRepository creates a new mock instance.
+func NewMockTxServiceAccountRepository(ctrl *gomock.Controller) *MockTxServiceAccountRepository {
+	mock := &MockTxServiceAccountRepository{ctrl: ctrl}
+	mock.recorder = &MockTxServiceAccountRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxServiceAccountRepository) EXPECT() *MockTxServiceAccountRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAccount mocks base method.
+func (m *MockTxServiceAccountRepository) CreateAccount(ctx context.Context, account *models.ServiceAccount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockTxServiceAccountRepositoryMockRecorder) CreateAccount(ctx, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockTxServiceAccountRepository)(nil).CreateAccount), ctx, account)
+}
+
+// CreateKey mocks base method.
+func (m *MockTxServiceAccountRepository) CreateKey(ctx context.Context, key *models.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *MockTxServiceAccountRepositoryMockRecorder) CreateKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*MockTxServiceAccountRepository)(nil).CreateKey), ctx, key)
+}
+
+// GetAccountByID mocks base method.
+func (m *MockTxServiceAccountRepository) GetAccountByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByID", ctx, id)
+	ret0, _ := ret[0].(*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByID indicates an expected call of GetAccountByID.
+func (mr *MockTxServiceAccountRepositoryMockRecorder) GetAccountByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByID", reflect.TypeOf((*MockTxServiceAccountRepository)(nil).GetAccountByID), ctx, id)
+}
+
+// GetKeyByHash mocks base method.
+func (m *MockTxServiceAccountRepository) GetKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyByHash", ctx, keyHash)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyByHash indicates an expected call of GetKeyByHash.
+func (mr *MockTxServiceAccountRepositoryMockRecorder) GetKeyByHash(ctx, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyByHash", reflect.TypeOf((*MockTxServiceAccountRepository)(nil).GetKeyByHash), ctx, keyHash)
+}
+
+// ListAccounts mocks base method.
+func (m *MockTxServiceAccountRepository) ListAccounts(ctx context.Context) ([]*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", ctx)
+	ret0, _ := ret[0].([]*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockTxServiceAccountRepositoryMockRecorder) ListAccounts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockTxServiceAccountRepository)(nil).ListAccounts), ctx)
+}
+
+// ListKeys mocks base method.
+func (m *MockTxServiceAccountRepository) ListKeys(ctx context.Context, serviceAccountID uuid.UUID) ([]*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeys", ctx, serviceAccountID)
+	ret0, _ := ret[0].([]*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKeys indicates an expected call of ListKeys.
+func (mr *MockTxServiceAccountRepositoryMockRecorder) ListKeys(ctx, serviceAccountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeys", reflect.TypeOf((*MockTxServiceAccountRepository)(nil).ListKeys), ctx, serviceAccountID)
+}
+
+// RevokeKey mocks base method.
+func (m *MockTxServiceAccountRepository) RevokeKey(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *MockTxServiceAccountRepositoryMockRecorder) RevokeKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*MockTxServiceAccountRepository)(nil).RevokeKey), ctx, id)
+}
+
+// TouchKey mocks base method.
+func (m *MockTxServiceAccountRepository) TouchKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchKey", ctx, id, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchKey indicates an expected call of TouchKey.
+func (mr *MockTxServiceAccountRepositoryMockRecorder) TouchKey(ctx, id, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchKey", reflect.TypeOf((*MockTxServiceAccountRepository)(nil).TouchKey), ctx, id, usedAt)
+}
+
+// WithTx mocks base method.
+func (m *MockTxServiceAccountRepository) WithTx(tx *sql.Tx) interfaces.ServiceAccountRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(interfaces.ServiceAccountRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockTxServiceAccountRepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockTxServiceAccountRepository)(nil).WithTx), tx)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockAuditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockAuditRepositoryMockRecorder) Append(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockAuditRepository)(nil).Append), ctx, entry)
+}
+
+// List mocks base method.
+func (m *MockAuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]*models.AuditEntry)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditRepository)(nil).List), ctx, filter)
+}
+
+// ListAfter mocks base method.
+func (m *MockAuditRepository) ListAfter(ctx context.Context, after models.AuditChainCursor, limit int) ([]*models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfter", ctx, after, limit)
+	ret0, _ := ret[0].([]*models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter.
+func (mr *MockAuditRepositoryMockRecorder) ListAfter(ctx, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*MockAuditRepository)(nil).ListAfter), ctx, after, limit)
+}
+
+// MockTxAuditRepository is a mock of TxAuditRepository interface.
+type MockTxAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxAuditRepositoryMockRecorder
+}
+
+// MockTxAuditRepositoryMockRecorder is the mock recorder for MockTxAuditRepository.
+type MockTxAuditRepositoryMockRecorder struct {
+	mock *MockTxAuditRepository
+}
+
+// NewMockTxAuditRepository creates a new mock instance.
+func NewMockTxAuditRepository(ctrl *gomock.Controller) *MockTxAuditRepository {
+	mock := &MockTxAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockTxAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxAuditRepository) EXPECT() *MockTxAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockTxAuditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockTxAuditRepositoryMockRecorder) Append(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockTxAuditRepository)(nil).Append), ctx, entry)
+}
+
+// List mocks base method.
+func (m *MockTxAuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]*models.AuditEntry)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockTxAuditRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTxAuditRepository)(nil).List), ctx, filter)
+}
+
+// ListAfter mocks base method.
+func (m *MockTxAuditRepository) ListAfter(ctx context.Context, after models.AuditChainCursor, limit int) ([]*models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfter", ctx, after, limit)
+	ret0, _ := ret[0].([]*models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter.
+func (mr *MockTxAuditRepositoryMockRecorder) ListAfter(ctx, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*MockTxAuditRepository)(nil).ListAfter), ctx, after, limit)
+}
+
+// WithTx mocks base method.
+func (m *MockTxAuditRepository) WithTx(tx *sql.Tx) interfaces.AuditRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(interfaces.AuditRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockTxAuditRepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockTxAuditRepository)(nil).WithTx), tx)
+}
+
+// MockReportRepository is a mock of ReportRepository interface.
+type MockReportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportRepositoryMockRecorder
+}
+
+// MockReportRepositoryMockRecorder is the mock recorder for MockReportRepository.
+type MockReportRepositoryMockRecorder struct {
+	mock *MockReportRepository
+}
+
+// NewMockReportRepository creates a new mock instance.
+func NewMockReportRepository(ctrl *gomock.Controller) *MockReportRepository {
+	mock := &MockReportRepository{ctrl: ctrl}
+	mock.recorder = &MockReportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportRepository) EXPECT() *MockReportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ReceptionReport mocks base method.
+func (m *MockReportRepository) ReceptionReport(ctx context.Context, filter models.ReceptionReportFilter) ([]models.ReceptionReportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceptionReport", ctx, filter)
+	ret0, _ := ret[0].([]models.ReceptionReportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceptionReport indicates an expected call of ReceptionReport.
+func (mr *MockReportRepositoryMockRecorder) ReceptionReport(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceptionReport", reflect.TypeOf((*MockReportRepository)(nil).ReceptionReport), ctx, filter)
+}
+
+// MockExportRepository is a mock of ExportRepository interface.
+type MockExportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportRepositoryMockRecorder
+}
+
+// MockExportRepositoryMockRecorder is the mock recorder for MockExportRepository.
+type MockExportRepositoryMockRecorder struct {
+	mock *MockExportRepository
+}
+
+// NewMockExportRepository creates a new mock instance.
+func NewMockExportRepository(ctrl *gomock.Controller) *MockExportRepository {
+	mock := &MockExportRepository{ctrl: ctrl}
+	mock.recorder = &MockExportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportRepository) EXPECT() *MockExportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Stream mocks base method.
+func (m *MockExportRepository) Stream(ctx context.Context, dataset string, filter models.ExportFilter, fetchSize int, fn func([]any) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, dataset, filter, fetchSize, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockExportRepositoryMockRecorder) Stream(ctx, dataset, filter, fetchSize, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockExportRepository)(nil).Stream), ctx, dataset, filter, fetchSize, fn)
+}
+
+// MockTxExportRepository is a mock of TxExportRepository interface.
+type MockTxExportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxExportRepositoryMockRecorder
+}
+
+// MockTxExportRepositoryMockRecorder is the mock recorder for MockTxExportRepository.
+type MockTxExportRepositoryMockRecorder struct {
+	mock *MockTxExportRepository
+}
+
+// NewMockTxExportRepository creates a new mock instance.
+func NewMockTxExportRepository(ctrl *gomock.Controller) *MockTxExportRepository {
+	mock := &MockTxExportRepository{ctrl: ctrl}
+	mock.recorder = &MockTxExportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxExportRepository) EXPECT() *MockTxExportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Stream mocks base method.
+func (m *MockTxExportRepository) Stream(ctx context.Context, dataset string, filter models.ExportFilter, fetchSize int, fn func([]any) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, dataset, filter, fetchSize, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockTxExportRepositoryMockRecorder) Stream(ctx, dataset, filter, fetchSize, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockTxExportRepository)(nil).Stream), ctx, dataset, filter, fetchSize, fn)
+}
+
+// WithTx mocks base method.
+func (m *MockTxExportRepository) WithTx(tx *sql.Tx) interfaces.ExportRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(interfaces.ExportRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockTxExportRepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockTxExportRepository)(nil).WithTx), tx)
+}
+
+// MockExportJobRepository is a mock of ExportJobRepository interface.
+type MockExportJobRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportJobRepositoryMockRecorder
+}
+
+// MockExportJobRepositoryMockRecorder is the mock recorder for MockExportJobRepository.
+type MockExportJobRepositoryMockRecorder struct {
+	mock *MockExportJobRepository
+}
+
+// NewMockExportJobRepository creates a new mock instance.
+func NewMockExportJobRepository(ctrl *gomock.Controller) *MockExportJobRepository {
+	mock := &MockExportJobRepository{ctrl: ctrl}
+	mock.recorder = &MockExportJobRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportJobRepository) EXPECT() *MockExportJobRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockExportJobRepository) Create(ctx context.Context, job *models.ExportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockExportJobRepositoryMockRecorder) Create(ctx, job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockExportJobRepository)(nil).Create), ctx, job)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockExportJobRepository) DeleteExpired(ctx context.Context, now time.Time) ([]*models.ExportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, now)
+	ret0, _ := ret[0].([]*models.ExportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockExportJobRepositoryMockRecorder) DeleteExpired(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockExportJobRepository)(nil).DeleteExpired), ctx, now)
+}
+
+// FailUnfinished mocks base method.
+func (m *MockExportJobRepository) FailUnfinished(ctx context.Context, reason string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailUnfinished", ctx, reason)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailUnfinished indicates an expected call of FailUnfinished.
+func (mr *MockExportJobRepositoryMockRecorder) FailUnfinished(ctx, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailUnfinished", reflect.TypeOf((*MockExportJobRepository)(nil).FailUnfinished), ctx, reason)
+}
+
+// GetByID mocks base method.
+func (m *MockExportJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ExportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.ExportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockExportJobRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockExportJobRepository)(nil).GetByID), ctx, id)
+}
+
+// Update mocks base method.
+func (m *MockExportJobRepository) Update(ctx context.Context, job *models.ExportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockExportJobRepositoryMockRecorder) Update(ctx, job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockExportJobRepository)(nil).Update), ctx, job)
+}
+
+// MockImportRepository is a mock of ImportRepository interface.
+type MockImportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportRepositoryMockRecorder
+}
+
+// MockImportRepositoryMockRecorder is the mock recorder for MockImportRepository.
+type MockImportRepositoryMockRecorder struct {
+	mock *MockImportRepository
+}
+
+// NewMockImportRepository creates a new mock instance.
+func NewMockImportRepository(ctrl *gomock.Controller) *MockImportRepository {
+	mock := &MockImportRepository{ctrl: ctrl}
+	mock.recorder = &MockImportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImportRepository) EXPECT() *MockImportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindEntityIDs mocks base method.
+func (m *MockImportRepository) FindEntityIDs(ctx context.Context, dataset string, externalIDs []string) (map[string]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEntityIDs", ctx, dataset, externalIDs)
+	ret0, _ := ret[0].(map[string]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEntityIDs indicates an expected call of FindEntityIDs.
+func (mr *MockImportRepositoryMockRecorder) FindEntityIDs(ctx, dataset, externalIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEntityIDs", reflect.TypeOf((*MockImportRepository)(nil).FindEntityIDs), ctx, dataset, externalIDs)
+}
+
+// SaveEntityID mocks base method.
+func (m *MockImportRepository) SaveEntityID(ctx context.Context, dataset, externalID string, entityID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEntityID", ctx, dataset, externalID, entityID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEntityID indicates an expected call of SaveEntityID.
+func (mr *MockImportRepositoryMockRecorder) SaveEntityID(ctx, dataset, externalID, entityID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEntityID", reflect.TypeOf((*MockImportRepository)(nil).SaveEntityID), ctx, dataset, externalID, entityID)
+}
+
+// MockTxImportRepository is a mock of TxImportRepository interface.
+type MockTxImportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxImportRepositoryMockRecorder
+}
+
+// MockTxImportRepositoryMockRecorder is the mock recorder for MockTxImportRepository.
+type MockTxImportRepositoryMockRecorder struct {
+	mock *MockTxImportRepository
+}
+
+// NewMockTxImportRepository creates a new mock instance.
+func NewMockTxImportRepository(ctrl *gomock.Controller) *MockTxImportRepository {
+	mock := &MockTxImportRepository{ctrl: ctrl}
+	mock.recorder = &MockTxImportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxImportRepository) EXPECT() *MockTxImportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindEntityIDs mocks base method.
+func (m *MockTxImportRepository) FindEntityIDs(ctx context.Context, dataset string, externalIDs []string) (map[string]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEntityIDs", ctx, dataset, externalIDs)
+	ret0, _ := ret[0].(map[string]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEntityIDs indicates an expected call of FindEntityIDs.
+func (mr *MockTxImportRepositoryMockRecorder) FindEntityIDs(ctx, dataset, externalIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEntityIDs", reflect.TypeOf((*MockTxImportRepository)(nil).FindEntityIDs), ctx, dataset, externalIDs)
+}
+
+// SaveEntityID mocks base method.
+func (m *MockTxImportRepository) SaveEntityID(ctx context.Context, dataset, externalID string, entityID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEntityID", ctx, dataset, externalID, entityID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEntityID indicates an expected call of SaveEntityID.
+func (mr *MockTxImportRepositoryMockRecorder) SaveEntityID(ctx, dataset, externalID, entityID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEntityID", reflect.TypeOf((*MockTxImportRepository)(nil).SaveEntityID), ctx, dataset, externalID, entityID)
+}
+
+// WithTx mocks base method.
+func (m *MockTxImportRepository) WithTx(tx *sql.Tx) interfaces.ImportRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(interfaces.ImportRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockTxImportRepositoryMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockTxImportRepository)(nil).WithTx), tx)
+}
+
+// MockEventRepository is a mock of EventRepository interface.
+type MockEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventRepositoryMockRecorder
+}
+
+// MockEventRepositoryMockRecorder is the mock recorder for MockEventRepository.
+type MockEventRepositoryMockRecorder struct {
+	mock *MockEventRepository
+}
+
+// NewMockEventRepository creates a new mock instance.
+func NewMockEventRepository(ctrl *gomock.Controller) *MockEventRepository {
+	mock := &MockEventRepository{ctrl: ctrl}
+	mock.recorder = &MockEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventRepository) EXPECT() *MockEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteBefore mocks base method.
+func (m *MockEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockEventRepositoryMockRecorder) DeleteBefore(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockEventRepository)(nil).DeleteBefore), ctx, before)
+}
+
+// LastID mocks base method.
+func (m *MockEventRepository) LastID(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastID", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastID indicates an expected call of LastID.
+func (mr *MockEventRepositoryMockRecorder) LastID(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastID", reflect.TypeOf((*MockEventRepository)(nil).LastID), ctx)
+}
+
+// ListAfter mocks base method.
+func (m *MockEventRepository) ListAfter(ctx context.Context, afterID, untilID int64, filter models.EventFilter, limit int) ([]*models.PVZEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfter", ctx, afterID, untilID, filter, limit)
+	ret0, _ := ret[0].([]*models.PVZEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter.
+func (mr *MockEventRepositoryMockRecorder) ListAfter(ctx, afterID, untilID, filter, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*MockEventRepository)(nil).ListAfter), ctx, afterID, untilID, filter, limit)
+}
+
+// TxSnapshot mocks base method.
+func (m *MockEventRepository) TxSnapshot(ctx context.Context) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxSnapshot", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TxSnapshot indicates an expected call of TxSnapshot.
+func (mr *MockEventRepositoryMockRecorder) TxSnapshot(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxSnapshot", reflect.TypeOf((*MockEventRepository)(nil).TxSnapshot), ctx)
+}
+
+// MockIdempotencyRepository is a mock of IdempotencyRepository interface.
+type MockIdempotencyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyRepositoryMockRecorder
+}
+
+// MockIdempotencyRepositoryMockRecorder is the mock recorder for MockIdempotencyRepository.
+type MockIdempotencyRepositoryMockRecorder struct {
+	mock *MockIdempotencyRepository
+}
+
+// NewMockIdempotencyRepository creates a new mock instance.
+func NewMockIdempotencyRepository(ctrl *gomock.Controller) *MockIdempotencyRepository {
+	mock := &MockIdempotencyRepository{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyRepository) EXPECT() *MockIdempotencyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Acquire mocks base method.
+func (m *MockIdempotencyRepository) Acquire(ctx context.Context, record *models.IdempotencyRecord, staleBefore time.Time) (*models.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Acquire", ctx, record, staleBefore)
+	ret0, _ := ret[0].(*models.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Acquire indicates an expected call of Acquire.
+func (mr *MockIdempotencyRepositoryMockRecorder) Acquire(ctx, record, staleBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acquire", reflect.TypeOf((*MockIdempotencyRepository)(nil).Acquire), ctx, record, staleBefore)
+}
+
+// Complete mocks base method.
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyRepositoryMockRecorder) Complete(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyRepository)(nil).Complete), ctx, record)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockIdempotencyRepositoryMockRecorder) DeleteExpired(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockIdempotencyRepository)(nil).DeleteExpired), ctx, now)
+}
+
+// Release mocks base method.
+func (m *MockIdempotencyRepository) Release(ctx context.Context, record *models.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIdempotencyRepositoryMockRecorder) Release(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIdempotencyRepository)(nil).Release), ctx, record)
+}
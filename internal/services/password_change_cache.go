@@ -0,0 +1,98 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordChangeCache запоминает время последней смены пароля пользователей на ttl,
+// чтобы проверка токена не читала пользователя из базы на каждом запросе. Смена пароля
+// в этом процессе сбрасывает запись сразу, в остальных экземплярах сервиса старые
+// токены отклоняются не позже чем через ttl. Нулевой ttl или nil отключают кеш.
+type PasswordChangeCache struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[uuid.UUID]passwordChangeEntry
+	lastSweep time.Time
+}
+
+type passwordChangeEntry struct {
+	changedAt *time.Time
+	// invalidatedAt - когда пароль сменили в этом процессе. Такая запись не хранит
+	// значение, а только не дает запомнить прочитанное до смены.
+	invalidatedAt time.Time
+	expiresAt     time.Time
+}
+
+func NewPasswordChangeCache(ttl time.Duration) *PasswordChangeCache {
+	return &PasswordChangeCache{
+		ttl:       ttl,
+		entries:   make(map[uuid.UUID]passwordChangeEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get возвращает запомненное время смены пароля; nil - пароль не менялся.
+func (c *PasswordChangeCache) Get(userID uuid.UUID) (*time.Time, bool) {
+	if c == nil || c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[userID]
+	if !ok || !entry.invalidatedAt.IsZero() || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.changedAt, true
+}
+
+// Put запоминает время смены пароля, прочитанное из базы в readAt. Значение,
+// прочитанное до смены пароля в этом процессе, не запоминается.
+func (c *PasswordChangeCache) Put(userID uuid.UUID, changedAt *time.Time, readAt time.Time) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := c.entries[userID]; ok && !readAt.After(entry.invalidatedAt) && now.Before(entry.expiresAt) {
+		return
+	}
+
+	c.sweep(now)
+	c.entries[userID] = passwordChangeEntry{changedAt: changedAt, expiresAt: now.Add(c.ttl)}
+}
+
+// Invalidate сбрасывает запись после смены пароля.
+func (c *PasswordChangeCache) Invalidate(userID uuid.UUID) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.sweep(now)
+	c.entries[userID] = passwordChangeEntry{invalidatedAt: now, expiresAt: now.Add(c.ttl)}
+}
+
+// sweep удаляет истекшие записи не чаще раза в ttl.
+func (c *PasswordChangeCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+
+	for userID, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, userID)
+		}
+	}
+	c.lastSweep = now
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/hasher"
+	"avito-backend-trainee-assignment-spring-2025/pkg/notifier"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type PasswordService struct {
+	userRepo        interfaces.TxUserRepository
+	resetRepo       interfaces.TxPasswordResetRepository
+	notifier        notifier.Notifier
+	resetConfig     config.PasswordResetConfig
+	passwordChanges *PasswordChangeCache
+	auditService    *AuditService
+	txManager       postgres.TxManager
+}
+
+func NewPasswordService(
+	userRepo interfaces.TxUserRepository,
+	resetRepo interfaces.TxPasswordResetRepository,
+	notifier notifier.Notifier,
+	resetConfig config.PasswordResetConfig,
+	passwordChanges *PasswordChangeCache,
+	auditService *AuditService,
+	txManager postgres.TxManager,
+) *PasswordService {
+	return &PasswordService{
+		userRepo:        userRepo,
+		resetRepo:       resetRepo,
+		notifier:        notifier,
+		resetConfig:     resetConfig,
+		passwordChanges: passwordChanges,
+		auditService:    auditService,
+		txManager:       txManager,
+	}
+}
+
+func (s *PasswordService) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "PasswordService.ChangePassword")
+	defer span.End()
+
+	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		txUserRepo := s.userRepo.WithTx(tx)
+		txResetRepo := s.resetRepo.WithTx(tx)
+
+		user, err := txUserRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if !hasher.Verify(user.PasswordHash, currentPassword) {
+			zerolog.Ctx(ctx).Info().
+				Str("user_id", userID.String()).
+				Msg("Password change failed: invalid current password")
+			return apperrors.ErrInvalidCredentials
+		}
+
+		if err := user.ChangePassword(newPassword); err != nil {
+			return err
+		}
+
+		if err := txUserRepo.UpdatePassword(ctx, user); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		if err := txResetRepo.InvalidateByUserID(ctx, userID); err != nil {
+			return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+		}
+
+		if err := s.auditService.Record(ctx, tx, models.AuditActionPasswordChange, models.AuditEntityUser, userID.String(), nil, nil); err != nil {
+			return err
+		}
+
+		zerolog.Ctx(ctx).Info().
+			Str("user_id", userID.String()).
+			Msg("Password changed successfully")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Запись сбрасывается после фиксации: иначе проверка токена успела бы снова
+	// запомнить время смены пароля, прочитанное до нее.
+	s.passwordChanges.Invalidate(userID)
+	return nil
+}
+
+// RequestPasswordReset не сообщает вызывающему, существует ли пользователь с таким email,
+// чтобы эндпоинт нельзя было использовать для перебора адресов. Поэтому ошибка отправки
+// письма только записывается в лог: для неизвестного адреса письмо не отправляется вовсе.
+func (s *PasswordService) RequestPasswordReset(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "PasswordService.RequestPasswordReset")
+	defer span.End()
+
+	var user *models.User
+	var rawToken string
+
+	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		txUserRepo := s.userRepo.WithTx(tx)
+		txResetRepo := s.resetRepo.WithTx(tx)
+
+		found, err := txUserRepo.GetByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrUserNotFound) {
+				zerolog.Ctx(ctx).Info().
+					Str("email", email).
+					Msg("Password reset requested for unknown email")
+				return nil
+			}
+			return fmt.Errorf("failed to get user by email: %w", err)
+		}
+
+		if err := txResetRepo.InvalidateByUserID(ctx, found.ID); err != nil {
+			return fmt.Errorf("failed to invalidate previous reset tokens: %w", err)
+		}
+
+		resetToken, token, err := models.NewPasswordResetToken(found.ID, s.resetConfig.TokenTTL)
+		if err != nil {
+			return err
+		}
+
+		if err := txResetRepo.Create(ctx, resetToken); err != nil {
+			return fmt.Errorf("failed to save reset token: %w", err)
+		}
+
+		user = found
+		rawToken = token
+		return nil
+	})
+
+	if err != nil || user == nil {
+		return err
+	}
+
+	msg := notifier.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf(
+			"Для сброса пароля используйте код: %s\nКод действителен %s и может быть использован один раз.",
+			rawToken, s.resetConfig.TokenTTL,
+		),
+	}
+
+	if err := s.notifier.Send(ctx, msg); err != nil {
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Str("user_id", user.ID.String()).
+			Msg("Failed to send password reset email")
+		return nil
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("user_id", user.ID.String()).
+		Msg("Password reset token issued")
+	return nil
+}
+
+func (s *PasswordService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "PasswordService.ResetPassword")
+	defer span.End()
+
+	var userID uuid.UUID
+	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		txUserRepo := s.userRepo.WithTx(tx)
+		txResetRepo := s.resetRepo.WithTx(tx)
+
+		resetToken, err := txResetRepo.GetByTokenHash(ctx, hasher.HashToken(token))
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrResetTokenNotFound) {
+				return apperrors.ErrInvalidResetToken
+			}
+			return err
+		}
+
+		if !resetToken.IsUsable(time.Now()) {
+			zerolog.Ctx(ctx).Info().
+				Str("user_id", resetToken.UserID.String()).
+				Msg("Password reset failed: token expired or already used")
+			return apperrors.ErrInvalidResetToken
+		}
+
+		user, err := txUserRepo.GetByID(ctx, resetToken.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := user.ChangePassword(newPassword); err != nil {
+			return err
+		}
+
+		if err := txResetRepo.MarkUsed(ctx, resetToken.ID); err != nil {
+			if errors.Is(err, repoerrors.ErrResetTokenNotFound) {
+				return apperrors.ErrInvalidResetToken
+			}
+			return fmt.Errorf("failed to mark reset token used: %w", err)
+		}
+
+		if err := txUserRepo.UpdatePassword(ctx, user); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		if err := txResetRepo.InvalidateByUserID(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+		}
+
+		if err := s.auditService.Record(ctx, tx, models.AuditActionPasswordReset, models.AuditEntityUser, user.ID.String(), nil, nil); err != nil {
+			return err
+		}
+
+		zerolog.Ctx(ctx).Info().
+			Str("user_id", user.ID.String()).
+			Msg("Password reset successfully")
+
+		userID = user.ID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.passwordChanges.Invalidate(userID)
+	return nil
+}
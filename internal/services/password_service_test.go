@@ -0,0 +1,324 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/services/mocks"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/hasher"
+	"avito-backend-trainee-assignment-spring-2025/pkg/notifier"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNotifier struct {
+	sent []notifier.Message
+	err  error
+}
+
+func (n *fakeNotifier) Send(_ context.Context, msg notifier.Message) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+func newTestPasswordService(ctrl *gomock.Controller, n notifier.Notifier) (*PasswordService, *mocks.MockTxUserRepository, *mocks.MockTxPasswordResetRepository) {
+	mockUserRepo := mocks.NewMockTxUserRepository(ctrl)
+	mockResetRepo := mocks.NewMockTxPasswordResetRepository(ctrl)
+
+	mockTxManager := &MockTxManager{
+		RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
+			return fn(nil)
+		},
+	}
+
+	mockUserRepo.EXPECT().WithTx(gomock.Any()).Return(mockUserRepo).AnyTimes()
+	mockResetRepo.EXPECT().WithTx(gomock.Any()).Return(mockResetRepo).AnyTimes()
+
+	service := NewPasswordService(
+		mockUserRepo,
+		mockResetRepo,
+		n,
+		config.PasswordResetConfig{TokenTTL: 30 * time.Minute},
+		nil,
+		nil,
+		mockTxManager,
+	)
+
+	return service, mockUserRepo, mockResetRepo
+}
+
+func TestPasswordService_ChangePassword(t *testing.T) {
+	userID := uuid.New()
+	currentHash, _ := hasher.Hash("password123")
+
+	tests := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		setupMocks      func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository)
+		expectedErr     error
+		wantErr         bool
+	}{
+		{
+			name:            "успешная смена пароля",
+			currentPassword: "password123",
+			newPassword:     "newPassword456",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).
+					Return(&models.User{ID: userID, PasswordHash: currentHash}, nil)
+				userRepo.EXPECT().UpdatePassword(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, user *models.User) error {
+						assert.True(t, hasher.Verify(user.PasswordHash, "newPassword456"))
+						assert.NotNil(t, user.PasswordChangedAt)
+						return nil
+					})
+				resetRepo.EXPECT().InvalidateByUserID(gomock.Any(), userID).Return(nil)
+			},
+		},
+		{
+			name:            "ошибка: неверный текущий пароль",
+			currentPassword: "wrong",
+			newPassword:     "newPassword456",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).
+					Return(&models.User{ID: userID, PasswordHash: currentHash}, nil)
+			},
+			wantErr:     true,
+			expectedErr: apperrors.ErrInvalidCredentials,
+		},
+		{
+			name:            "ошибка: слишком короткий новый пароль",
+			currentPassword: "password123",
+			newPassword:     "123",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).
+					Return(&models.User{ID: userID, PasswordHash: currentHash}, nil)
+			},
+			wantErr:     true,
+			expectedErr: apperrors.ErrInvalidPassword,
+		},
+		{
+			name:            "ошибка: пользователь не найден",
+			currentPassword: "password123",
+			newPassword:     "newPassword456",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).Return(nil, repoerrors.ErrUserNotFound)
+			},
+			wantErr:     true,
+			expectedErr: repoerrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, userRepo, resetRepo := newTestPasswordService(ctrl, &fakeNotifier{})
+			tt.setupMocks(userRepo, resetRepo)
+
+			err := service.ChangePassword(context.Background(), userID, tt.currentPassword, tt.newPassword)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPasswordService_RequestPasswordReset(t *testing.T) {
+	userID := uuid.New()
+	email := "user@example.com"
+
+	tests := []struct {
+		name        string
+		notifierErr error
+		setupMocks  func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository)
+		wantSent    int
+	}{
+		{
+			name: "код отправлен существующему пользователю",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				userRepo.EXPECT().GetByEmail(gomock.Any(), email).
+					Return(&models.User{ID: userID, Email: email}, nil)
+				resetRepo.EXPECT().InvalidateByUserID(gomock.Any(), userID).Return(nil)
+				resetRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, token *models.PasswordResetToken) error {
+						assert.Equal(t, userID, token.UserID)
+						assert.Len(t, token.TokenHash, 64)
+						return nil
+					})
+			},
+			wantSent: 1,
+		},
+		{
+			name: "неизвестный email не раскрывается",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				userRepo.EXPECT().GetByEmail(gomock.Any(), email).Return(nil, repoerrors.ErrUserNotFound)
+			},
+			wantSent: 0,
+		},
+		{
+			name:        "ошибка отправки письма не раскрывает существование пользователя",
+			notifierErr: errors.New("smtp unavailable"),
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				userRepo.EXPECT().GetByEmail(gomock.Any(), email).
+					Return(&models.User{ID: userID, Email: email}, nil)
+				resetRepo.EXPECT().InvalidateByUserID(gomock.Any(), userID).Return(nil)
+				resetRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantSent: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			n := &fakeNotifier{err: tt.notifierErr}
+			service, userRepo, resetRepo := newTestPasswordService(ctrl, n)
+			tt.setupMocks(userRepo, resetRepo)
+
+			err := service.RequestPasswordReset(context.Background(), email)
+
+			assert.NoError(t, err)
+			assert.Len(t, n.sent, tt.wantSent)
+			if tt.wantSent > 0 {
+				assert.Equal(t, email, n.sent[0].To)
+			}
+		})
+	}
+}
+
+func TestPasswordService_ResetPassword(t *testing.T) {
+	userID := uuid.New()
+	tokenID := uuid.New()
+	rawToken := "reset-token"
+	tokenHash := hasher.HashToken(rawToken)
+	usedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		newPassword string
+		setupMocks  func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name:        "успешный сброс пароля",
+			newPassword: "newPassword456",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				resetRepo.EXPECT().GetByTokenHash(gomock.Any(), tokenHash).Return(&models.PasswordResetToken{
+					ID:        tokenID,
+					UserID:    userID,
+					TokenHash: tokenHash,
+					ExpiresAt: time.Now().Add(time.Minute),
+				}, nil)
+				userRepo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{ID: userID}, nil)
+				resetRepo.EXPECT().MarkUsed(gomock.Any(), tokenID).Return(nil)
+				userRepo.EXPECT().UpdatePassword(gomock.Any(), gomock.Any()).Return(nil)
+				resetRepo.EXPECT().InvalidateByUserID(gomock.Any(), userID).Return(nil)
+			},
+		},
+		{
+			name:        "ошибка: неизвестный токен",
+			newPassword: "newPassword456",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				resetRepo.EXPECT().GetByTokenHash(gomock.Any(), tokenHash).Return(nil, repoerrors.ErrResetTokenNotFound)
+			},
+			wantErr:     true,
+			expectedErr: apperrors.ErrInvalidResetToken,
+		},
+		{
+			name:        "ошибка: токен просрочен",
+			newPassword: "newPassword456",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				resetRepo.EXPECT().GetByTokenHash(gomock.Any(), tokenHash).Return(&models.PasswordResetToken{
+					ID:        tokenID,
+					UserID:    userID,
+					ExpiresAt: time.Now().Add(-time.Minute),
+				}, nil)
+			},
+			wantErr:     true,
+			expectedErr: apperrors.ErrInvalidResetToken,
+		},
+		{
+			name:        "ошибка: токен уже использован",
+			newPassword: "newPassword456",
+			setupMocks: func(userRepo *mocks.MockTxUserRepository, resetRepo *mocks.MockTxPasswordResetRepository) {
+				resetRepo.EXPECT().GetByTokenHash(gomock.Any(), tokenHash).Return(&models.PasswordResetToken{
+					ID:        tokenID,
+					UserID:    userID,
+					ExpiresAt: time.Now().Add(time.Minute),
+					UsedAt:    &usedAt,
+				}, nil)
+			},
+			wantErr:     true,
+			expectedErr: apperrors.ErrInvalidResetToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, userRepo, resetRepo := newTestPasswordService(ctrl, &fakeNotifier{})
+			tt.setupMocks(userRepo, resetRepo)
+
+			err := service.ResetPassword(context.Background(), rawToken, tt.newPassword)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPasswordService_ResetEmailContainsToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	n := &fakeNotifier{}
+	service, userRepo, resetRepo := newTestPasswordService(ctrl, n)
+
+	var storedHash string
+	userRepo.EXPECT().GetByEmail(gomock.Any(), "user@example.com").
+		Return(&models.User{ID: uuid.New(), Email: "user@example.com"}, nil)
+	resetRepo.EXPECT().InvalidateByUserID(gomock.Any(), gomock.Any()).Return(nil)
+	resetRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, token *models.PasswordResetToken) error {
+			storedHash = token.TokenHash
+			return nil
+		})
+
+	assert.NoError(t, service.RequestPasswordReset(context.Background(), "user@example.com"))
+	assert.Len(t, n.sent, 1)
+
+	body := n.sent[0].Body
+	start := strings.Index(body, ": ") + 2
+	end := strings.Index(body, "\n")
+	rawToken := body[start:end]
+
+	assert.NotContains(t, body, storedHash)
+	assert.Equal(t, storedHash, hasher.HashToken(rawToken))
+}
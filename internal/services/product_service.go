@@ -5,53 +5,89 @@ import (
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
 	"context"
 	"database/sql"
 	"fmt"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+	"time"
 )
 
 type ProductService struct {
 	productRepo   interfaces.TxProductRepository
 	receptionRepo interfaces.TxReceptionRepository
+	pvzRepo       interfaces.TxPVZRepository
+	capacityCfg   config.CapacityConfig
+	auditService  *AuditService
 	txManager     postgres.TxManager
 }
 
 func NewProductService(
 	productRepo interfaces.TxProductRepository,
 	receptionRepo interfaces.TxReceptionRepository,
+	pvzRepo interfaces.TxPVZRepository,
+	capacityCfg config.CapacityConfig,
+	auditService *AuditService,
 	txManager postgres.TxManager,
 ) *ProductService {
 	return &ProductService{
 		productRepo:   productRepo,
 		receptionRepo: receptionRepo,
+		pvzRepo:       pvzRepo,
+		capacityCfg:   capacityCfg,
+		auditService:  auditService,
 		txManager:     txManager,
 	}
 }
 
-func (s *ProductService) AddProduct(ctx context.Context, productType string, pvzID uuid.UUID) (*models.Product, error) {
+// AddProduct добавляет товар в открытую приёмку ПВЗ. Если у ПВЗ задана вместимость,
+// товар сверх нее отклоняется или принимается в зависимости от политики переполнения.
+// Возвращаются предупреждения по ограничениям, заполненным на долю порога или больше.
+func (s *ProductService) AddProduct(ctx context.Context, productType string, pvzID uuid.UUID) (*models.Product, []models.CapacityUsage, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.AddProduct")
+	defer span.End()
+
 	if !models.IsValidProductType(productType) {
-		log.Info().
+		zerolog.Ctx(ctx).Info().
 			Str("product_type", productType).
 			Str("pvz_id", pvzID.String()).
 			Msg("Product validation failed: invalid product type")
-		return nil, apperrors.ErrInvalidProductType
+		return nil, nil, apperrors.ErrInvalidProductType
 	}
 
 	var product *models.Product
+	var warnings []models.CapacityUsage
 
 	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
 		txReceptionRepo := s.receptionRepo.WithTx(tx)
 		txProductRepo := s.productRepo.WithTx(tx)
 
+		txPVZRepo := s.pvzRepo.WithTx(tx)
+
+		pvz, err := txPVZRepo.GetByID(ctx, pvzID)
+		if err != nil {
+			return err
+		}
+
+		// Блокировка ПВЗ с заданной вместимостью упорядочивает добавления, иначе
+		// параллельные запросы прошли бы проверку по одному и тому же количеству товаров.
+		// ПВЗ без вместимости не блокируется: добавления в него ничего не проверяют.
+		if pvz.Capacity != nil {
+			pvz, err = txPVZRepo.GetByIDForUpdate(ctx, pvzID)
+			if err != nil {
+				return err
+			}
+		}
+
 		reception, err := txReceptionRepo.GetLastActiveByPVZID(ctx, pvzID)
 		if err != nil {
 			return err
 		}
 
 		if !reception.IsInProgress() {
-			log.Info().
+			zerolog.Ctx(ctx).Info().
 				Str("reception_id", reception.ID.String()).
 				Str("pvz_id", pvzID.String()).
 				Str("status", reception.Status).
@@ -61,7 +97,7 @@ func (s *ProductService) AddProduct(ctx context.Context, productType string, pvz
 
 		newProduct, err := models.NewProduct(productType, reception.ID)
 		if err != nil {
-			log.Info().
+			zerolog.Ctx(ctx).Info().
 				Err(err).
 				Str("product_type", productType).
 				Str("reception_id", reception.ID.String()).
@@ -69,36 +105,103 @@ func (s *ProductService) AddProduct(ctx context.Context, productType string, pvz
 			return err
 		}
 
+		if pvz.Capacity != nil {
+			occupancy, err := txProductRepo.CountByPVZID(ctx, pvzID, stockSince(s.capacityCfg))
+			if err != nil {
+				return fmt.Errorf("failed to count PVZ products: %w", err)
+			}
+
+			occupancy[productType]++
+			warnings, err = s.checkCapacity(ctx, pvzID, productType, pvz.Capacity.Usage(occupancy))
+			if err != nil {
+				return err
+			}
+		}
+
 		if err := txProductRepo.Create(ctx, newProduct); err != nil {
 			return fmt.Errorf("failed to save product: %w", err)
 		}
 
+		if err := s.auditService.Record(ctx, tx, models.AuditActionProductAdd, models.AuditEntityProduct, newProduct.ID.String(), nil, newProduct); err != nil {
+			return err
+		}
+
 		product = newProduct
 		return nil
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	log.Info().
+	zerolog.Ctx(ctx).Info().
 		Str("product_id", product.ID.String()).
 		Str("type", product.Type).
 		Str("reception_id", product.ReceptionID.String()).
+		Int("capacity_warnings", len(warnings)).
 		Msg("Product added successfully")
 
-	return product, nil
+	return product, warnings, nil
+}
+
+// checkCapacity проверяет ограничения, которые затрагивает добавленный товар: общее и
+// по его типу. При политике reject переполнение - ошибка, при warn - предупреждение.
+func (s *ProductService) checkCapacity(ctx context.Context, pvzID uuid.UUID, productType string, usage []models.CapacityUsage) ([]models.CapacityUsage, error) {
+	var warnings []models.CapacityUsage
+
+	for _, item := range usage {
+		if item.Scope != models.CapacityScopeTotal && item.Scope != productType {
+			continue
+		}
+
+		if item.IsExceeded() && s.capacityCfg.OverflowPolicy != models.CapacityOverflowWarn {
+			zerolog.Ctx(ctx).Info().
+				Str("pvz_id", pvzID.String()).
+				Str("scope", item.Scope).
+				Int("capacity", item.Capacity).
+				Msg("Product rejected: PVZ capacity exceeded")
+			return nil, apperrors.ErrCapacityExceeded
+		}
+
+		if item.Utilization() >= s.capacityCfg.UtilizationThreshold {
+			warnings = append(warnings, item)
+		}
+	}
+
+	if len(warnings) > 0 {
+		zerolog.Ctx(ctx).Warn().
+			Str("pvz_id", pvzID.String()).
+			Int("warnings", len(warnings)).
+			Msg("PVZ is running out of capacity")
+	}
+
+	return warnings, nil
+}
+
+// stockSince возвращает время, начиная с которого принятые товары занимают место в ПВЗ.
+// Товары старше срока хранения считаются выданными или возвращенными.
+func stockSince(cfg config.CapacityConfig) time.Time {
+	return time.Now().Add(-cfg.StoragePeriod)
 }
 
 func (s *ProductService) GetProductByID(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetProductByID")
+	defer span.End()
+
 	return s.productRepo.GetByID(ctx, id)
 }
 
 func (s *ProductService) GetProductsByReceptionID(ctx context.Context, receptionID uuid.UUID) ([]models.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetProductsByReceptionID")
+	defer span.End()
+
 	return s.productRepo.GetByReceptionID(ctx, receptionID)
 }
 
 func (s *ProductService) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "ProductService.DeleteLastProduct")
+	defer span.End()
+
 	return s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
 		txReceptionRepo := s.receptionRepo.WithTx(tx)
 		txProductRepo := s.productRepo.WithTx(tx)
@@ -109,7 +212,7 @@ func (s *ProductService) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID)
 		}
 
 		if !reception.IsInProgress() {
-			log.Info().
+			zerolog.Ctx(ctx).Info().
 				Str("reception_id", reception.ID.String()).
 				Str("pvz_id", pvzID.String()).
 				Str("status", reception.Status).
@@ -123,7 +226,7 @@ func (s *ProductService) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID)
 		}
 
 		if len(products) == 0 {
-			log.Info().
+			zerolog.Ctx(ctx).Info().
 				Str("reception_id", reception.ID.String()).
 				Str("pvz_id", pvzID.String()).
 				Msg("Cannot delete product: no products in reception")
@@ -134,7 +237,13 @@ func (s *ProductService) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID)
 			return fmt.Errorf("failed to delete last product: %w", err)
 		}
 
-		log.Info().
+		// Товары отсортированы по времени добавления, удален последний
+		deleted := products[len(products)-1]
+		if err := s.auditService.Record(ctx, tx, models.AuditActionProductDelete, models.AuditEntityProduct, deleted.ID.String(), deleted, nil); err != nil {
+			return err
+		}
+
+		zerolog.Ctx(ctx).Info().
 			Str("reception_id", reception.ID.String()).
 			Str("pvz_id", pvzID.String()).
 			Msg("Last product deleted successfully from reception")
@@ -7,6 +7,7 @@ import (
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
 	"avito-backend-trainee-assignment-spring-2025/internal/services/mocks"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
 	"context"
 	"database/sql"
 	"errors"
@@ -31,6 +32,7 @@ func TestProductService_AddProduct(t *testing.T) {
 
 	mockProductRepo := mocks.NewMockTxProductRepository(ctrl)
 	mockReceptionRepo := mocks.NewMockTxReceptionRepository(ctrl)
+	mockPVZRepo := mocks.NewMockTxPVZRepository(ctrl)
 
 	mockTxManager := &MockTxManager{
 		RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
@@ -98,6 +100,8 @@ func TestProductService_AddProduct(t *testing.T) {
 
 				mockProductRepo.EXPECT().WithTx(gomock.Any()).Return(mockProductRepo)
 				mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).Return(&models.PVZ{ID: pvzID}, nil)
 
 				mockReceptionRepo.EXPECT().
 					GetLastActiveByPVZID(gomock.Any(), pvzID).
@@ -150,6 +154,8 @@ func TestProductService_AddProduct(t *testing.T) {
 			setupMocks: func() {
 				mockProductRepo.EXPECT().WithTx(gomock.Any()).Return(mockProductRepo)
 				mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).Return(&models.PVZ{ID: pvzID}, nil)
 
 				mockReceptionRepo.EXPECT().
 					GetLastActiveByPVZID(gomock.Any(), pvzID).
@@ -174,6 +180,8 @@ func TestProductService_AddProduct(t *testing.T) {
 			setupMocks: func() {
 				mockProductRepo.EXPECT().WithTx(gomock.Any()).Return(mockProductRepo)
 				mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).Return(&models.PVZ{ID: pvzID}, nil)
 
 				mockReceptionRepo.EXPECT().
 					GetLastActiveByPVZID(gomock.Any(), pvzID).
@@ -198,6 +206,8 @@ func TestProductService_AddProduct(t *testing.T) {
 			setupMocks: func() {
 				mockProductRepo.EXPECT().WithTx(gomock.Any()).Return(mockProductRepo)
 				mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).Return(&models.PVZ{ID: pvzID}, nil)
 
 				mockReceptionRepo.EXPECT().
 					GetLastActiveByPVZID(gomock.Any(), pvzID).
@@ -222,10 +232,11 @@ func TestProductService_AddProduct(t *testing.T) {
 			s := &ProductService{
 				productRepo:   tt.fields.productRepo,
 				receptionRepo: tt.fields.receptionRepo,
+				pvzRepo:       mockPVZRepo,
 				txManager:     tt.fields.txManager,
 			}
 
-			got, err := s.AddProduct(tt.args.ctx, tt.args.productType, tt.args.pvzID)
+			got, _, err := s.AddProduct(tt.args.ctx, tt.args.productType, tt.args.pvzID)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AddProduct() error = %v, wantErr %v", err, tt.wantErr)
@@ -494,6 +505,7 @@ func TestProductService_DeleteLastProduct(t *testing.T) {
 
 	mockProductRepo := mocks.NewMockTxProductRepository(ctrl)
 	mockReceptionRepo := mocks.NewMockTxReceptionRepository(ctrl)
+	mockPVZRepo := mocks.NewMockTxPVZRepository(ctrl)
 
 	mockTxManager := &MockTxManager{
 		RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
@@ -664,6 +676,7 @@ func TestProductService_DeleteLastProduct(t *testing.T) {
 			s := &ProductService{
 				productRepo:   tt.fields.productRepo,
 				receptionRepo: tt.fields.receptionRepo,
+				pvzRepo:       mockPVZRepo,
 				txManager:     tt.fields.txManager,
 			}
 
@@ -710,7 +723,7 @@ func TestNewProductService(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewProductService(tt.args.productRepo, tt.args.receptionRepo, tt.args.txManager)
+			got := NewProductService(tt.args.productRepo, tt.args.receptionRepo, nil, config.CapacityConfig{}, nil, tt.args.txManager)
 
 			if got == nil {
 				t.Errorf("NewProductService() returned nil")
@@ -728,3 +741,180 @@ func TestNewProductService(t *testing.T) {
 		})
 	}
 }
+
+func TestProductService_AddProduct_StoragePeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductRepo := mocks.NewMockTxProductRepository(ctrl)
+	mockReceptionRepo := mocks.NewMockTxReceptionRepository(ctrl)
+	mockPVZRepo := mocks.NewMockTxPVZRepository(ctrl)
+	mockTxManager := &MockTxManager{
+		RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
+			return fn(nil)
+		},
+	}
+
+	const storagePeriod = 7 * 24 * time.Hour
+
+	pvzID := uuid.New()
+	reception := &models.Reception{ID: uuid.New(), PVZID: pvzID, Status: models.ReceptionStatusInProgress}
+	pvz := &models.PVZ{ID: pvzID, Capacity: &models.PVZCapacity{ByType: map[string]int{models.ProductTypeShoes: 2}}}
+
+	// acceptedAt - время приёмки товаров ПВЗ; репозиторий считает только принятые не раньше since
+	var acceptedAt []time.Time
+
+	mockProductRepo.EXPECT().WithTx(gomock.Any()).Return(mockProductRepo).AnyTimes()
+	mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo).AnyTimes()
+	mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo).AnyTimes()
+	mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).Return(pvz, nil).AnyTimes()
+	mockPVZRepo.EXPECT().GetByIDForUpdate(gomock.Any(), pvzID).Return(pvz, nil).AnyTimes()
+	mockReceptionRepo.EXPECT().GetLastActiveByPVZID(gomock.Any(), pvzID).Return(reception, nil).AnyTimes()
+	mockProductRepo.EXPECT().CountByPVZID(gomock.Any(), pvzID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, since time.Time) (map[string]int, error) {
+			if d := time.Since(since) - storagePeriod; d < -time.Minute || d > time.Minute {
+				t.Errorf("CountByPVZID() since = %v, want about %v ago", since, storagePeriod)
+			}
+
+			occupancy := map[string]int{}
+			for _, at := range acceptedAt {
+				if !at.Before(since) {
+					occupancy[models.ProductTypeShoes]++
+				}
+			}
+			return occupancy, nil
+		}).AnyTimes()
+
+	s := NewProductService(mockProductRepo, mockReceptionRepo, mockPVZRepo,
+		config.CapacityConfig{OverflowPolicy: models.CapacityOverflowReject, UtilizationThreshold: 0.9, StoragePeriod: storagePeriod},
+		nil, mockTxManager)
+
+	// Товары, принятые вчера, занимают все место
+	dayAgo := time.Now().Add(-24 * time.Hour)
+	acceptedAt = []time.Time{dayAgo, dayAgo}
+
+	if _, _, err := s.AddProduct(context.Background(), models.ProductTypeShoes, pvzID); !errors.Is(err, apperrors.ErrCapacityExceeded) {
+		t.Fatalf("AddProduct() error = %v, want %v", err, apperrors.ErrCapacityExceeded)
+	}
+
+	// Когда срок хранения этих товаров истек, заполненность падает и товар принимается
+	weekAgo := time.Now().Add(-storagePeriod - time.Hour)
+	acceptedAt = []time.Time{weekAgo, weekAgo}
+	mockProductRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	_, warnings, err := s.AddProduct(context.Background(), models.ProductTypeShoes, pvzID)
+	if err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("AddProduct() warnings = %v, want none", warnings)
+	}
+}
+
+func TestProductService_AddProduct_Capacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductRepo := mocks.NewMockTxProductRepository(ctrl)
+	mockReceptionRepo := mocks.NewMockTxReceptionRepository(ctrl)
+	mockPVZRepo := mocks.NewMockTxPVZRepository(ctrl)
+	mockTxManager := &MockTxManager{
+		RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
+			return fn(nil)
+		},
+	}
+
+	ctx := context.Background()
+	pvzID := uuid.New()
+	reception := &models.Reception{ID: uuid.New(), PVZID: pvzID, Status: models.ReceptionStatusInProgress}
+	pvz := &models.PVZ{
+		ID:       pvzID,
+		Capacity: &models.PVZCapacity{Total: 10, ByType: map[string]int{models.ProductTypeShoes: 2}},
+	}
+
+	tests := []struct {
+		name          string
+		policy        string
+		productType   string
+		occupancy     map[string]int
+		wantCreate    bool
+		wantWarnings  []string
+		expectedError error
+	}{
+		{
+			name:        "below threshold without warnings",
+			policy:      models.CapacityOverflowReject,
+			productType: models.ProductTypeElectronics,
+			occupancy:   map[string]int{models.ProductTypeElectronics: 3},
+			wantCreate:  true,
+		},
+		{
+			name:         "threshold reached",
+			policy:       models.CapacityOverflowReject,
+			productType:  models.ProductTypeElectronics,
+			occupancy:    map[string]int{models.ProductTypeElectronics: 8},
+			wantCreate:   true,
+			wantWarnings: []string{models.CapacityScopeTotal},
+		},
+		{
+			name:          "type capacity exceeded is rejected",
+			policy:        models.CapacityOverflowReject,
+			productType:   models.ProductTypeShoes,
+			occupancy:     map[string]int{models.ProductTypeShoes: 2},
+			expectedError: apperrors.ErrCapacityExceeded,
+		},
+		{
+			name:         "type capacity exceeded is accepted with warning",
+			policy:       models.CapacityOverflowWarn,
+			productType:  models.ProductTypeShoes,
+			occupancy:    map[string]int{models.ProductTypeShoes: 2},
+			wantCreate:   true,
+			wantWarnings: []string{models.ProductTypeShoes},
+		},
+		{
+			name:          "total capacity exceeded is rejected",
+			policy:        models.CapacityOverflowReject,
+			productType:   models.ProductTypeClothes,
+			occupancy:     map[string]int{models.ProductTypeClothes: 10},
+			expectedError: apperrors.ErrCapacityExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProductRepo.EXPECT().WithTx(gomock.Any()).Return(mockProductRepo)
+			mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
+			mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+			mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).Return(pvz, nil)
+			mockPVZRepo.EXPECT().GetByIDForUpdate(gomock.Any(), pvzID).Return(pvz, nil)
+			mockReceptionRepo.EXPECT().GetLastActiveByPVZID(gomock.Any(), pvzID).Return(reception, nil)
+			mockProductRepo.EXPECT().CountByPVZID(gomock.Any(), pvzID, gomock.Any()).Return(tt.occupancy, nil)
+			if tt.wantCreate {
+				mockProductRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			s := NewProductService(mockProductRepo, mockReceptionRepo, mockPVZRepo,
+				config.CapacityConfig{OverflowPolicy: tt.policy, UtilizationThreshold: 0.9}, nil, mockTxManager)
+
+			product, warnings, err := s.AddProduct(ctx, tt.productType, pvzID)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("AddProduct() error = %v, want %v", err, tt.expectedError)
+			}
+			if tt.expectedError != nil {
+				return
+			}
+			if product == nil {
+				t.Fatalf("AddProduct() returned nil product")
+			}
+
+			var scopes []string
+			for _, warning := range warnings {
+				scopes = append(scopes, warning.Scope)
+			}
+			if !reflect.DeepEqual(scopes, tt.wantWarnings) {
+				t.Errorf("AddProduct() warnings = %v, want %v", scopes, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -5,31 +5,44 @@ import (
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/metrics"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
 	"context"
 	"database/sql"
 	"fmt"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+	"sort"
 )
 
 type PVZService struct {
-	repo      interfaces.TxPVZRepository
-	txManager postgres.TxManager
+	repo         interfaces.TxPVZRepository
+	capacityCfg  config.CapacityConfig
+	auditService *AuditService
+	txManager    postgres.TxManager
 }
 
 func NewPVZService(
 	repo interfaces.TxPVZRepository,
+	capacityCfg config.CapacityConfig,
+	auditService *AuditService,
 	txManager postgres.TxManager,
 ) *PVZService {
 	return &PVZService{
-		repo:      repo,
-		txManager: txManager,
+		repo:         repo,
+		capacityCfg:  capacityCfg,
+		auditService: auditService,
+		txManager:    txManager,
 	}
 }
 
 func (s *PVZService) CreatePVZ(ctx context.Context, city string) (*models.PVZ, error) {
+	ctx, span := tracing.Start(ctx, "PVZService.CreatePVZ")
+	defer span.End()
+
 	if !models.IsValidCity(city) {
-		log.Info().
+		zerolog.Ctx(ctx).Info().
 			Str("city", city).
 			Msg("PVZ creation failed: invalid city")
 		return nil, apperrors.ErrInvalidCity
@@ -42,7 +55,7 @@ func (s *PVZService) CreatePVZ(ctx context.Context, city string) (*models.PVZ, e
 
 		newPvz, err := models.NewPVZ(city)
 		if err != nil {
-			log.Info().
+			zerolog.Ctx(ctx).Info().
 				Err(err).
 				Str("city", city).
 				Msg("PVZ creation failed: invalid data")
@@ -53,6 +66,10 @@ func (s *PVZService) CreatePVZ(ctx context.Context, city string) (*models.PVZ, e
 			return fmt.Errorf("failed to save PVZ: %w", err)
 		}
 
+		if err := s.auditService.Record(ctx, tx, models.AuditActionPVZCreate, models.AuditEntityPVZ, newPvz.ID.String(), nil, newPvz); err != nil {
+			return err
+		}
+
 		pvz = newPvz
 		return nil
 	})
@@ -61,7 +78,7 @@ func (s *PVZService) CreatePVZ(ctx context.Context, city string) (*models.PVZ, e
 		return nil, err
 	}
 
-	log.Info().
+	zerolog.Ctx(ctx).Info().
 		Str("pvz_id", pvz.ID.String()).
 		Str("city", pvz.City).
 		Time("registration_date", pvz.RegistrationDate).
@@ -70,7 +87,59 @@ func (s *PVZService) CreatePVZ(ctx context.Context, city string) (*models.PVZ, e
 	return pvz, nil
 }
 
+// UpdatePVZ изменяет профиль ПВЗ: адрес, координаты, телефон, расписание и статус.
+// Если версия ПВЗ не удовлетворяет precondition, возвращается ErrVersionMismatch.
+func (s *PVZService) UpdatePVZ(ctx context.Context, id uuid.UUID, update models.PVZUpdate, precondition models.Precondition) (*models.PVZ, error) {
+	ctx, span := tracing.Start(ctx, "PVZService.UpdatePVZ")
+	defer span.End()
+
+	var pvz *models.PVZ
+
+	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		txRepo := s.repo.WithTx(tx)
+
+		current, err := txRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !precondition.Matches(current.ID, current.Version) {
+			return apperrors.ErrVersionMismatch
+		}
+
+		updated := *current
+		if err := updated.Apply(update); err != nil {
+			return err
+		}
+
+		if err := txRepo.Update(ctx, &updated); err != nil {
+			return fmt.Errorf("failed to save PVZ: %w", err)
+		}
+
+		if err := s.auditService.Record(ctx, tx, models.AuditActionPVZUpdate, models.AuditEntityPVZ, id.String(), current, &updated); err != nil {
+			return err
+		}
+
+		pvz = &updated
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("pvz_id", pvz.ID.String()).
+		Str("status", pvz.Status).
+		Msg("PVZ updated successfully")
+
+	return pvz, nil
+}
+
 func (s *PVZService) GetPVZByID(ctx context.Context, id uuid.UUID) (*models.PVZ, error) {
+	ctx, span := tracing.Start(ctx, "PVZService.GetPVZByID")
+	defer span.End()
+
 	pvz, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PVZ: %w", err)
@@ -78,22 +147,109 @@ func (s *PVZService) GetPVZByID(ctx context.Context, id uuid.UUID) (*models.PVZ,
 	return pvz, nil
 }
 
-func (s *PVZService) GetAllPVZ(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, int, error) {
+// FindNearbyPVZ ищет ПВЗ вокруг точки и возвращает их по возрастанию расстояния.
+func (s *PVZService) FindNearbyPVZ(ctx context.Context, filter models.NearbyFilter) ([]models.PVZWithDistance, error) {
+	ctx, span := tracing.Start(ctx, "PVZService.FindNearbyPVZ")
+	defer span.End()
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.FindNearby(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nearby PVZ: %w", err)
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Float64("radius_meters", filter.RadiusMeters).
+		Bool("open_now", filter.OpenAt != nil).
+		Int("returned_count", len(result)).
+		Msg("Found nearby PVZ")
+
+	return result, nil
+}
+
+// GetOverloadedPVZ возвращает ПВЗ, заполненность которых хотя бы по одному ограничению
+// достигла threshold, от самых заполненных. Непустой allowedIDs оставляет только эти
+// ПВЗ.
+func (s *PVZService) GetOverloadedPVZ(ctx context.Context, threshold float64, allowedIDs []uuid.UUID) ([]models.PVZUtilization, error) {
+	ctx, span := tracing.Start(ctx, "PVZService.GetOverloadedPVZ")
+	defer span.End()
+
+	if threshold <= 0 {
+		return nil, apperrors.ErrInvalidThreshold
+	}
+
+	utilization, err := s.repo.ListUtilization(ctx, stockSince(s.capacityCfg), allowedIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PVZ utilization: %w", err)
+	}
+
+	overloaded := make([]models.PVZUtilization, 0)
+	for _, item := range utilization {
+		if item.MaxUtilization() >= threshold {
+			overloaded = append(overloaded, item)
+		}
+	}
+
+	sort.SliceStable(overloaded, func(i, j int) bool {
+		return overloaded[i].MaxUtilization() > overloaded[j].MaxUtilization()
+	})
+
+	zerolog.Ctx(ctx).Info().
+		Float64("threshold", threshold).
+		Int("pvz_with_capacity", len(utilization)).
+		Int("overloaded", len(overloaded)).
+		Msg("Built PVZ utilization report")
+
+	return overloaded, nil
+}
+
+// CapacityMetrics возвращает заполненность всех ПВЗ с заданной вместимостью для
+// metrics.CapacityCollector.
+func (s *PVZService) CapacityMetrics(ctx context.Context) ([]metrics.CapacitySample, error) {
+	utilization, err := s.repo.ListUtilization(ctx, stockSince(s.capacityCfg), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PVZ utilization: %w", err)
+	}
+
+	samples := make([]metrics.CapacitySample, 0, len(utilization))
+	for _, item := range utilization {
+		samples = append(samples, metrics.CapacitySample{Occupied: item.Occupied(), Utilization: item.MaxUtilization()})
+	}
+
+	return samples, nil
+}
+
+func (s *PVZService) GetAllPVZ(ctx context.Context, filter models.PVZFilter) ([]*models.PVZ, *models.PVZPage, error) {
+	ctx, span := tracing.Start(ctx, "PVZService.GetAllPVZ")
+	defer span.End()
+
 	return s.repo.GetAll(ctx, filter)
 }
 
-func (s *PVZService) GetAllPVZWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, int, error) {
-	pvzList, total, err := s.repo.GetAllWithReceptions(ctx, filter)
+func (s *PVZService) GetAllPVZWithReceptions(ctx context.Context, filter models.PVZFilter) ([]models.PVZWithReceptions, *models.PVZPage, error) {
+	ctx, span := tracing.Start(ctx, "PVZService.GetAllPVZWithReceptions")
+	defer span.End()
+
+	pvzList, page, err := s.repo.GetAllWithReceptions(ctx, filter)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get PVZ list with receptions: %w", err)
+		return nil, nil, fmt.Errorf("failed to get PVZ list with receptions: %w", err)
 	}
 
-	log.Info().
-		Int("total_count", total).
+	event := zerolog.Ctx(ctx).Info().
 		Int("returned_count", len(pvzList)).
-		Int("page", filter.Page).
 		Int("limit", filter.Limit).
-		Msg("Retrieved PVZ list with receptions successfully")
+		Bool("cursor", filter.Cursor != nil).
+		Bool("has_next", page.NextCursor != "")
+	if page.Total != nil {
+		event = event.Int("total_count", *page.Total)
+	}
+	if filter.Cursor == nil {
+		event = event.Int("page", filter.Page)
+	}
+	event.Msg("Retrieved PVZ list with receptions successfully")
 
-	return pvzList, total, nil
+	return pvzList, page, nil
 }
@@ -7,6 +7,8 @@ import (
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
 	"avito-backend-trainee-assignment-spring-2025/internal/services/mocks"
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"avito-backend-trainee-assignment-spring-2025/pkg/metrics"
 	"context"
 	"database/sql"
 	"errors"
@@ -44,7 +46,7 @@ func TestNewPVZService(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewPVZService(tt.args.repo, tt.args.txManager)
+			got := NewPVZService(tt.args.repo, config.CapacityConfig{}, nil, tt.args.txManager)
 
 			if got == nil {
 				t.Errorf("NewPVZService() returned nil")
@@ -82,6 +84,8 @@ func TestPVZService_CreatePVZ(t *testing.T) {
 		ID:               pvzID,
 		RegistrationDate: time.Now(),
 		City:             validCity,
+		Status:           models.PVZStatusActive,
+		Version:          models.InitialVersion,
 	}
 
 	type fields struct {
@@ -349,9 +353,15 @@ func TestPVZService_GetAllPVZ(t *testing.T) {
 
 	ctx := context.Background()
 	filter := models.PVZFilter{
-		Page:  1,
-		Limit: 10,
+		Page:      1,
+		Limit:     10,
+		WithTotal: true,
 	}
+	emptyTotal := 0
+	emptyPage := &models.PVZPage{Total: &emptyTotal}
+
+	total := 2
+	page := &models.PVZPage{Total: &total, NextCursor: "next"}
 
 	pvzList := []*models.PVZ{
 		{
@@ -380,7 +390,7 @@ func TestPVZService_GetAllPVZ(t *testing.T) {
 		args            args
 		setupMocks      func()
 		want            []*models.PVZ
-		want1           int
+		want1           *models.PVZPage
 		wantErr         bool
 		expectedErrType error
 	}{
@@ -397,10 +407,10 @@ func TestPVZService_GetAllPVZ(t *testing.T) {
 			setupMocks: func() {
 				mockPVZRepo.EXPECT().
 					GetAll(gomock.Any(), filter).
-					Return(pvzList, len(pvzList), nil)
+					Return(pvzList, page, nil)
 			},
 			want:    pvzList,
-			want1:   len(pvzList),
+			want1:   page,
 			wantErr: false,
 		},
 		{
@@ -416,10 +426,10 @@ func TestPVZService_GetAllPVZ(t *testing.T) {
 			setupMocks: func() {
 				mockPVZRepo.EXPECT().
 					GetAll(gomock.Any(), filter).
-					Return([]*models.PVZ{}, 0, nil)
+					Return([]*models.PVZ{}, emptyPage, nil)
 			},
 			want:    []*models.PVZ{},
-			want1:   0,
+			want1:   emptyPage,
 			wantErr: false,
 		},
 		{
@@ -435,10 +445,10 @@ func TestPVZService_GetAllPVZ(t *testing.T) {
 			setupMocks: func() {
 				mockPVZRepo.EXPECT().
 					GetAll(gomock.Any(), filter).
-					Return(nil, 0, errors.New("database error"))
+					Return(nil, nil, errors.New("database error"))
 			},
 			want:    nil,
-			want1:   0,
+			want1:   nil,
 			wantErr: true,
 		},
 	}
@@ -487,9 +497,12 @@ func TestPVZService_GetAllPVZWithReceptions(t *testing.T) {
 
 	ctx := context.Background()
 	filter := models.PVZFilter{
-		Page:  1,
-		Limit: 10,
+		Page:      1,
+		Limit:     10,
+		WithTotal: true,
 	}
+	emptyTotal := 0
+	emptyPage := &models.PVZPage{Total: &emptyTotal}
 
 	pvzID := uuid.New()
 	receptionID := uuid.New()
@@ -507,6 +520,9 @@ func TestPVZService_GetAllPVZWithReceptions(t *testing.T) {
 		Status:   models.ReceptionStatusInProgress,
 	}
 
+	total := 1
+	page := &models.PVZPage{Total: &total}
+
 	pvzWithReceptions := []models.PVZWithReceptions{
 		{
 			PVZ:        pvz,
@@ -528,7 +544,7 @@ func TestPVZService_GetAllPVZWithReceptions(t *testing.T) {
 		args            args
 		setupMocks      func()
 		want            []models.PVZWithReceptions
-		want1           int
+		want1           *models.PVZPage
 		wantErr         bool
 		expectedErrType error
 	}{
@@ -545,10 +561,10 @@ func TestPVZService_GetAllPVZWithReceptions(t *testing.T) {
 			setupMocks: func() {
 				mockPVZRepo.EXPECT().
 					GetAllWithReceptions(gomock.Any(), filter).
-					Return(pvzWithReceptions, 1, nil)
+					Return(pvzWithReceptions, page, nil)
 			},
 			want:    pvzWithReceptions,
-			want1:   1,
+			want1:   page,
 			wantErr: false,
 		},
 		{
@@ -564,10 +580,10 @@ func TestPVZService_GetAllPVZWithReceptions(t *testing.T) {
 			setupMocks: func() {
 				mockPVZRepo.EXPECT().
 					GetAllWithReceptions(gomock.Any(), filter).
-					Return([]models.PVZWithReceptions{}, 0, nil)
+					Return([]models.PVZWithReceptions{}, emptyPage, nil)
 			},
 			want:    []models.PVZWithReceptions{},
-			want1:   0,
+			want1:   emptyPage,
 			wantErr: false,
 		},
 		{
@@ -583,10 +599,10 @@ func TestPVZService_GetAllPVZWithReceptions(t *testing.T) {
 			setupMocks: func() {
 				mockPVZRepo.EXPECT().
 					GetAllWithReceptions(gomock.Any(), filter).
-					Return(nil, 0, errors.New("database error"))
+					Return(nil, nil, errors.New("database error"))
 			},
 			want:    nil,
-			want1:   0,
+			want1:   nil,
 			wantErr: true,
 		},
 	}
@@ -624,3 +640,199 @@ func TestPVZService_GetAllPVZWithReceptions(t *testing.T) {
 		})
 	}
 }
+
+func TestPVZService_UpdatePVZ(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZRepo := mocks.NewMockTxPVZRepository(ctrl)
+	mockTxManager := &MockTxManager{
+		RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
+			return fn(nil)
+		},
+	}
+
+	ctx := context.Background()
+	pvzID := uuid.New()
+	address := "ул. Тверская, 1"
+	decommissioned := models.PVZStatusDecommissioned
+	current := models.ParsePrecondition(models.EntityTag{ID: pvzID, Version: 1}.String())
+
+	tests := []struct {
+		name          string
+		update        models.PVZUpdate
+		precondition  models.Precondition
+		setupMocks    func()
+		wantStatus    string
+		expectedError error
+	}{
+		{
+			name:         "successful update",
+			update:       models.PVZUpdate{Address: &address, Status: &decommissioned},
+			precondition: current,
+			setupMocks: func() {
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).
+					Return(&models.PVZ{ID: pvzID, City: models.CityMoscow, Status: models.PVZStatusActive, Version: 1}, nil)
+				mockPVZRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, pvz *models.PVZ) error {
+						if pvz.Address != address || pvz.Status != decommissioned || pvz.Version != 1 {
+							t.Errorf("Update() got = %+v", pvz)
+						}
+						return nil
+					})
+			},
+			wantStatus: decommissioned,
+		},
+		{
+			name:         "decommissioned PVZ cannot be changed",
+			update:       models.PVZUpdate{Address: &address},
+			precondition: current,
+			setupMocks: func() {
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).
+					Return(&models.PVZ{ID: pvzID, City: models.CityMoscow, Status: decommissioned, Version: 1}, nil)
+			},
+			expectedError: apperrors.ErrPVZDecommissioned,
+		},
+		{
+			name:         "PVZ modified since it was read",
+			update:       models.PVZUpdate{Address: &address},
+			precondition: current,
+			setupMocks: func() {
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).
+					Return(&models.PVZ{ID: pvzID, City: models.CityMoscow, Status: models.PVZStatusActive, Version: 2}, nil)
+			},
+			expectedError: apperrors.ErrVersionMismatch,
+		},
+		{
+			name:         "concurrent update wins the race",
+			update:       models.PVZUpdate{Address: &address},
+			precondition: models.Precondition{Any: true},
+			setupMocks: func() {
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).
+					Return(&models.PVZ{ID: pvzID, City: models.CityMoscow, Status: models.PVZStatusActive, Version: 2}, nil)
+				mockPVZRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(apperrors.ErrVersionMismatch)
+			},
+			expectedError: apperrors.ErrVersionMismatch,
+		},
+		{
+			name:         "PVZ not found",
+			update:       models.PVZUpdate{Address: &address},
+			precondition: current,
+			setupMocks: func() {
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).Return(nil, repoerrors.ErrPVZNotFound)
+			},
+			expectedError: repoerrors.ErrPVZNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			s := NewPVZService(mockPVZRepo, config.CapacityConfig{StoragePeriod: 7 * 24 * time.Hour}, nil, mockTxManager)
+
+			got, err := s.UpdatePVZ(ctx, pvzID, tt.update, tt.precondition)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("UpdatePVZ() error = %v, want %v", err, tt.expectedError)
+			}
+			if tt.expectedError == nil && got.Status != tt.wantStatus {
+				t.Errorf("UpdatePVZ() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPVZService_FindNearbyPVZ(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZRepo := mocks.NewMockTxPVZRepository(ctrl)
+	s := NewPVZService(mockPVZRepo, config.CapacityConfig{StoragePeriod: 7 * 24 * time.Hour}, nil, &MockTxManager{})
+
+	ctx := context.Background()
+	filter := models.NearbyFilter{Latitude: 55.75, Longitude: 37.62, RadiusMeters: 3000, Limit: 5}
+	found := []models.PVZWithDistance{{PVZ: &models.PVZ{ID: uuid.New()}, DistanceMeters: 42}}
+
+	mockPVZRepo.EXPECT().FindNearby(gomock.Any(), filter).Return(found, nil)
+
+	got, err := s.FindNearbyPVZ(ctx, filter)
+	if err != nil {
+		t.Fatalf("FindNearbyPVZ() error = %v", err)
+	}
+	if len(got) != 1 || got[0].DistanceMeters != 42 {
+		t.Errorf("FindNearbyPVZ() = %+v, want %+v", got, found)
+	}
+
+	invalid := filter
+	invalid.RadiusMeters = models.MaxNearbyRadiusMeters + 1
+	if _, err := s.FindNearbyPVZ(ctx, invalid); !errors.Is(err, apperrors.ErrInvalidNearbyRadius) {
+		t.Errorf("FindNearbyPVZ() error = %v, want %v", err, apperrors.ErrInvalidNearbyRadius)
+	}
+}
+
+func TestPVZService_GetOverloadedPVZ(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZRepo := mocks.NewMockTxPVZRepository(ctrl)
+	s := NewPVZService(mockPVZRepo, config.CapacityConfig{StoragePeriod: 7 * 24 * time.Hour}, nil, &MockTxManager{})
+
+	ctx := context.Background()
+	usage := func(occupied, capacity int) models.PVZUtilization {
+		return models.PVZUtilization{
+			PVZ:   &models.PVZ{ID: uuid.New()},
+			Usage: []models.CapacityUsage{{Scope: models.CapacityScopeTotal, Occupied: occupied, Capacity: capacity}},
+		}
+	}
+	half, full, overflow := usage(50, 100), usage(95, 100), usage(120, 100)
+
+	allowedIDs := []uuid.UUID{half.PVZ.ID, full.PVZ.ID, overflow.PVZ.ID}
+	mockPVZRepo.EXPECT().ListUtilization(gomock.Any(), gomock.Any(), allowedIDs).
+		Return([]models.PVZUtilization{full, half, overflow}, nil)
+
+	got, err := s.GetOverloadedPVZ(ctx, 0.9, allowedIDs)
+	if err != nil {
+		t.Fatalf("GetOverloadedPVZ() error = %v", err)
+	}
+	if len(got) != 2 || got[0].PVZ.ID != overflow.PVZ.ID || got[1].PVZ.ID != full.PVZ.ID {
+		t.Errorf("GetOverloadedPVZ() = %+v, want overflow then full", got)
+	}
+
+	if _, err := s.GetOverloadedPVZ(ctx, 0, nil); !errors.Is(err, apperrors.ErrInvalidThreshold) {
+		t.Errorf("GetOverloadedPVZ() error = %v, want %v", err, apperrors.ErrInvalidThreshold)
+	}
+}
+
+func TestPVZService_CapacityMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPVZRepo := mocks.NewMockTxPVZRepository(ctrl)
+	s := NewPVZService(mockPVZRepo, config.CapacityConfig{StoragePeriod: 7 * 24 * time.Hour}, nil, &MockTxManager{})
+
+	utilization := models.PVZUtilization{
+		PVZ:       &models.PVZ{ID: uuid.New()},
+		Occupancy: map[string]int{models.ProductTypeShoes: 3, models.ProductTypeClothes: 2},
+		Usage: []models.CapacityUsage{
+			{Scope: models.CapacityScopeTotal, Occupied: 5, Capacity: 10},
+			{Scope: models.ProductTypeShoes, Occupied: 3, Capacity: 4},
+		},
+	}
+	mockPVZRepo.EXPECT().ListUtilization(gomock.Any(), gomock.Any(), nil).
+		Return([]models.PVZUtilization{utilization}, nil)
+
+	got, err := s.CapacityMetrics(context.Background())
+	if err != nil {
+		t.Fatalf("CapacityMetrics() error = %v", err)
+	}
+	want := []metrics.CapacitySample{{Occupied: 5, Utilization: 0.75}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CapacityMetrics() = %+v, want %+v", got, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
 	"context"
 	"database/sql"
 	"errors"
@@ -14,33 +15,46 @@ import (
 type ReceptionService struct {
 	receptionRepo interfaces.TxReceptionRepository
 	pvzRepo       interfaces.TxPVZRepository
+	reportRepo    interfaces.ReportRepository
+	auditService  *AuditService
 	txManager     postgres.TxManager
 }
 
 func NewReceptionService(
 	receptionRepo interfaces.TxReceptionRepository,
 	pvzRepo interfaces.TxPVZRepository,
+	reportRepo interfaces.ReportRepository,
+	auditService *AuditService,
 	txManager postgres.TxManager,
 ) *ReceptionService {
 	return &ReceptionService{
 		receptionRepo: receptionRepo,
 		pvzRepo:       pvzRepo,
+		reportRepo:    reportRepo,
+		auditService:  auditService,
 		txManager:     txManager,
 	}
 }
 
 func (s *ReceptionService) CreateReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
+	ctx, span := tracing.Start(ctx, "ReceptionService.CreateReception")
+	defer span.End()
+
 	var reception *models.Reception
 
 	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
 		txPvzRepo := s.pvzRepo.WithTx(tx)
 		txReceptionRepo := s.receptionRepo.WithTx(tx)
 
-		_, err := txPvzRepo.GetByID(ctx, pvzID)
+		pvz, err := txPvzRepo.GetByID(ctx, pvzID)
 		if err != nil {
 			return err
 		}
 
+		if pvz.IsClosed() {
+			return apperrors.ErrPVZClosed
+		}
+
 		_, err = txReceptionRepo.GetLastActiveByPVZID(ctx, pvzID)
 		if err == nil {
 			return apperrors.ErrActiveReceptionExists
@@ -58,6 +72,10 @@ func (s *ReceptionService) CreateReception(ctx context.Context, pvzID uuid.UUID)
 			return err
 		}
 
+		if err := s.auditService.Record(ctx, tx, models.AuditActionReceptionCreate, models.AuditEntityReception, newReception.ID.String(), nil, newReception); err != nil {
+			return err
+		}
+
 		reception = newReception
 		return nil
 	})
@@ -70,14 +88,26 @@ func (s *ReceptionService) CreateReception(ctx context.Context, pvzID uuid.UUID)
 }
 
 func (s *ReceptionService) GetReceptionByID(ctx context.Context, id uuid.UUID) (*models.Reception, error) {
+	ctx, span := tracing.Start(ctx, "ReceptionService.GetReceptionByID")
+	defer span.End()
+
 	return s.receptionRepo.GetByID(ctx, id)
 }
 
 func (s *ReceptionService) GetLastActiveReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
+	ctx, span := tracing.Start(ctx, "ReceptionService.GetLastActiveReception")
+	defer span.End()
+
 	return s.receptionRepo.GetLastActiveByPVZID(ctx, pvzID)
 }
 
-func (s *ReceptionService) CloseReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
+// CloseReception закрывает открытую приёмку ПВЗ. Если приёмка не удовлетворяет
+// precondition (например, клиент видел другую приёмку или ее товары с тех пор
+// менялись), возвращается ErrVersionMismatch.
+func (s *ReceptionService) CloseReception(ctx context.Context, pvzID uuid.UUID, precondition models.Precondition) (*models.Reception, error) {
+	ctx, span := tracing.Start(ctx, "ReceptionService.CloseReception")
+	defer span.End()
+
 	var closedReceptionID uuid.UUID
 
 	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
@@ -88,7 +118,18 @@ func (s *ReceptionService) CloseReception(ctx context.Context, pvzID uuid.UUID)
 			return err
 		}
 
-		if err := txReceptionRepo.CloseReception(ctx, reception.ID); err != nil {
+		if !precondition.Matches(reception.ID, reception.Version) {
+			return apperrors.ErrVersionMismatch
+		}
+
+		if err := txReceptionRepo.CloseReception(ctx, reception.ID, reception.Version); err != nil {
+			return err
+		}
+
+		closed := *reception
+		closed.Status = models.ReceptionStatusClosed
+		closed.Version++
+		if err := s.auditService.Record(ctx, tx, models.AuditActionReceptionClose, models.AuditEntityReception, reception.ID.String(), reception, &closed); err != nil {
 			return err
 		}
 
@@ -104,5 +145,32 @@ func (s *ReceptionService) CloseReception(ctx context.Context, pvzID uuid.UUID)
 }
 
 func (s *ReceptionService) GetLastReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
+	ctx, span := tracing.Start(ctx, "ReceptionService.GetLastReception")
+	defer span.End()
+
 	return s.receptionRepo.GetLastReceptionByPVZID(ctx, pvzID)
 }
+
+// ListPVZReceptions возвращает страницу приёмок ПВЗ, начиная с последней.
+func (s *ReceptionService) ListPVZReceptions(ctx context.Context, pvzID uuid.UUID, filter models.ReceptionFilter) ([]*models.Reception, int, error) {
+	ctx, span := tracing.Start(ctx, "ReceptionService.ListPVZReceptions")
+	defer span.End()
+
+	if _, err := s.pvzRepo.GetByID(ctx, pvzID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.receptionRepo.ListByPVZID(ctx, pvzID, filter)
+}
+
+// ReceptionReport возвращает статистику приёмок и товаров в выбранных разрезах.
+func (s *ReceptionService) ReceptionReport(ctx context.Context, filter models.ReceptionReportFilter) ([]models.ReceptionReportRow, error) {
+	ctx, span := tracing.Start(ctx, "ReceptionService.ReceptionReport")
+	defer span.End()
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.reportRepo.ReceptionReport(ctx, filter)
+}
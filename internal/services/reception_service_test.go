@@ -48,7 +48,7 @@ func TestNewReceptionService(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewReceptionService(tt.args.receptionRepo, tt.args.pvzRepo, tt.args.txManager)
+			got := NewReceptionService(tt.args.receptionRepo, tt.args.pvzRepo, nil, nil, tt.args.txManager)
 
 			if got == nil {
 				t.Errorf("NewReceptionService() returned nil")
@@ -96,6 +96,7 @@ func TestReceptionService_CreateReception(t *testing.T) {
 		DateTime: time.Now(),
 		PVZID:    pvzID,
 		Status:   models.ReceptionStatusInProgress,
+		Version:  models.InitialVersion,
 	}
 
 	type fields struct {
@@ -152,6 +153,31 @@ func TestReceptionService_CreateReception(t *testing.T) {
 			want:    newReception,
 			wantErr: false,
 		},
+		{
+			name: "ошибка: ПВЗ временно закрыт",
+			fields: fields{
+				receptionRepo: mockReceptionRepo,
+				pvzRepo:       mockPVZRepo,
+				txManager:     mockTxManager,
+			},
+			args: args{
+				ctx:   ctx,
+				pvzID: pvzID,
+			},
+			setupMocks: func() {
+				mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
+				mockPVZRepo.EXPECT().WithTx(gomock.Any()).Return(mockPVZRepo)
+
+				closedPVZ := *pvz
+				closedPVZ.Status = models.PVZStatusTemporarilyClosed
+				mockPVZRepo.EXPECT().
+					GetByID(gomock.Any(), pvzID).
+					Return(&closedPVZ, nil)
+			},
+			want:            nil,
+			wantErr:         true,
+			expectedErrType: apperrors.ErrPVZClosed,
+		},
 	}
 
 	for _, tt := range tests {
@@ -178,7 +204,7 @@ func TestReceptionService_CreateReception(t *testing.T) {
 				t.Errorf("CreateReception() expected error type = %v, got = %v", tt.expectedErrType, err)
 			}
 
-			if tt.want.Products == nil {
+			if tt.want != nil && tt.want.Products == nil {
 				tt.want.Products = []models.Product{}
 			}
 			assert.Equal(t, tt.want, got)
@@ -209,13 +235,16 @@ func TestReceptionService_CloseReception(t *testing.T) {
 		DateTime: time.Now(),
 		PVZID:    pvzID,
 		Status:   models.ReceptionStatusInProgress,
+		Version:  2,
 	}
+	current := models.ParsePrecondition(models.EntityTag{ID: receptionID, Version: 2}.String())
 
 	closedReception := &models.Reception{
 		ID:       receptionID,
 		DateTime: activeReception.DateTime,
 		PVZID:    pvzID,
 		Status:   models.ReceptionStatusClosed,
+		Version:  3,
 	}
 
 	type fields struct {
@@ -224,8 +253,9 @@ func TestReceptionService_CloseReception(t *testing.T) {
 		txManager     postgres.TxManager
 	}
 	type args struct {
-		ctx   context.Context
-		pvzID uuid.UUID
+		ctx          context.Context
+		pvzID        uuid.UUID
+		precondition models.Precondition
 	}
 	tests := []struct {
 		name            string
@@ -244,8 +274,9 @@ func TestReceptionService_CloseReception(t *testing.T) {
 				txManager:     mockTxManager,
 			},
 			args: args{
-				ctx:   ctx,
-				pvzID: pvzID,
+				ctx:          ctx,
+				pvzID:        pvzID,
+				precondition: current,
 			},
 			setupMocks: func() {
 
@@ -256,7 +287,7 @@ func TestReceptionService_CloseReception(t *testing.T) {
 					Return(activeReception, nil)
 
 				mockReceptionRepo.EXPECT().
-					CloseReception(gomock.Any(), receptionID).
+					CloseReception(gomock.Any(), receptionID, int64(2)).
 					Return(nil)
 
 				mockReceptionRepo.EXPECT().
@@ -274,8 +305,9 @@ func TestReceptionService_CloseReception(t *testing.T) {
 				txManager:     mockTxManager,
 			},
 			args: args{
-				ctx:   ctx,
-				pvzID: uuid.New(),
+				ctx:          ctx,
+				pvzID:        uuid.New(),
+				precondition: current,
 			},
 			setupMocks: func() {
 				mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
@@ -288,6 +320,29 @@ func TestReceptionService_CloseReception(t *testing.T) {
 			wantErr:         true,
 			expectedErrType: apperrors.ErrNoActiveReception,
 		},
+		{
+			name: "ошибка: приемка изменилась после чтения",
+			fields: fields{
+				receptionRepo: mockReceptionRepo,
+				pvzRepo:       mockPVZRepo,
+				txManager:     mockTxManager,
+			},
+			args: args{
+				ctx:          ctx,
+				pvzID:        pvzID,
+				precondition: models.ParsePrecondition(models.EntityTag{ID: receptionID, Version: 1}.String()),
+			},
+			setupMocks: func() {
+				mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
+
+				mockReceptionRepo.EXPECT().
+					GetLastActiveByPVZID(gomock.Any(), pvzID).
+					Return(activeReception, nil)
+			},
+			want:            nil,
+			wantErr:         true,
+			expectedErrType: apperrors.ErrVersionMismatch,
+		},
 		{
 			name: "ошибка при закрытии приемки",
 			fields: fields{
@@ -296,8 +351,9 @@ func TestReceptionService_CloseReception(t *testing.T) {
 				txManager:     mockTxManager,
 			},
 			args: args{
-				ctx:   ctx,
-				pvzID: pvzID,
+				ctx:          ctx,
+				pvzID:        pvzID,
+				precondition: current,
 			},
 			setupMocks: func() {
 				mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
@@ -307,7 +363,7 @@ func TestReceptionService_CloseReception(t *testing.T) {
 					Return(activeReception, nil)
 
 				mockReceptionRepo.EXPECT().
-					CloseReception(gomock.Any(), receptionID).
+					CloseReception(gomock.Any(), receptionID, int64(2)).
 					Return(errors.New("ошибка базы данных"))
 			},
 			want:    nil,
@@ -321,8 +377,9 @@ func TestReceptionService_CloseReception(t *testing.T) {
 				txManager:     mockTxManager,
 			},
 			args: args{
-				ctx:   ctx,
-				pvzID: pvzID,
+				ctx:          ctx,
+				pvzID:        pvzID,
+				precondition: current,
 			},
 			setupMocks: func() {
 				mockReceptionRepo.EXPECT().WithTx(gomock.Any()).Return(mockReceptionRepo)
@@ -332,7 +389,7 @@ func TestReceptionService_CloseReception(t *testing.T) {
 					Return(activeReception, nil)
 
 				mockReceptionRepo.EXPECT().
-					CloseReception(gomock.Any(), receptionID).
+					CloseReception(gomock.Any(), receptionID, int64(2)).
 					Return(nil)
 
 				mockReceptionRepo.EXPECT().
@@ -357,7 +414,7 @@ func TestReceptionService_CloseReception(t *testing.T) {
 				txManager:     tt.fields.txManager,
 			}
 
-			got, err := s.CloseReception(tt.args.ctx, tt.args.pvzID)
+			got, err := s.CloseReception(tt.args.ctx, tt.args.pvzID, tt.args.precondition)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CloseReception() error = %v, wantErr %v", err, tt.wantErr)
@@ -756,3 +813,141 @@ func TestReceptionService_GetLastReception(t *testing.T) {
 		})
 	}
 }
+
+func TestReceptionService_ListPVZReceptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReceptionRepo := mocks.NewMockTxReceptionRepository(ctrl)
+	mockPVZRepo := mocks.NewMockTxPVZRepository(ctrl)
+
+	ctx := context.Background()
+	pvzID := uuid.New()
+	filter := models.ReceptionFilter{Page: 1, Limit: 20}
+
+	receptions := []*models.Reception{
+		{ID: uuid.New(), DateTime: time.Now(), PVZID: pvzID, Status: models.ReceptionStatusInProgress},
+	}
+
+	tests := []struct {
+		name          string
+		setupMocks    func()
+		want          []*models.Reception
+		wantTotal     int
+		wantErr       bool
+		expectedError error
+	}{
+		{
+			name: "успешное получение приемок ПВЗ",
+			setupMocks: func() {
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).Return(&models.PVZ{ID: pvzID}, nil)
+				mockReceptionRepo.EXPECT().ListByPVZID(gomock.Any(), pvzID, filter).Return(receptions, 1, nil)
+			},
+			want:      receptions,
+			wantTotal: 1,
+		},
+		{
+			name: "ошибка: ПВЗ не найден",
+			setupMocks: func() {
+				mockPVZRepo.EXPECT().GetByID(gomock.Any(), pvzID).Return(nil, repoerrors.ErrPVZNotFound)
+			},
+			wantErr:       true,
+			expectedError: repoerrors.ErrPVZNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			s := NewReceptionService(mockReceptionRepo, mockPVZRepo, nil, nil, &MockTxManager{})
+
+			got, total, err := s.ListPVZReceptions(ctx, pvzID, filter)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ListPVZReceptions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr && !errors.Is(err, tt.expectedError) {
+				t.Errorf("ListPVZReceptions() expected error = %v, got = %v", tt.expectedError, err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) || total != tt.wantTotal {
+				t.Errorf("ListPVZReceptions() got = %v (%d), want %v (%d)", got, total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestReceptionService_ReceptionReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReportRepo := mocks.NewMockReportRepository(ctrl)
+
+	ctx := context.Background()
+	filter := models.ReceptionReportFilter{
+		GroupBy: []string{models.ReportGroupCity},
+		Period:  models.ReportPeriodWeek,
+	}
+
+	rows := []models.ReceptionReportRow{
+		{City: "Москва", Receptions: 3, Products: 12},
+	}
+
+	tests := []struct {
+		name          string
+		filter        models.ReceptionReportFilter
+		setupMocks    func()
+		want          []models.ReceptionReportRow
+		wantErr       bool
+		expectedError error
+	}{
+		{
+			name:   "успешное построение отчета",
+			filter: filter,
+			setupMocks: func() {
+				mockReportRepo.EXPECT().ReceptionReport(gomock.Any(), filter).Return(rows, nil)
+			},
+			want: rows,
+		},
+		{
+			name:          "ошибка: неизвестная группировка",
+			filter:        models.ReceptionReportFilter{GroupBy: []string{"status"}},
+			setupMocks:    func() {},
+			wantErr:       true,
+			expectedError: apperrors.ErrInvalidReportGroup,
+		},
+		{
+			name:          "ошибка: неизвестный период",
+			filter:        models.ReceptionReportFilter{Period: "year"},
+			setupMocks:    func() {},
+			wantErr:       true,
+			expectedError: apperrors.ErrInvalidReportPeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			s := NewReceptionService(nil, nil, mockReportRepo, nil, &MockTxManager{})
+
+			got, err := s.ReceptionReport(ctx, tt.filter)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReceptionReport() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr && !errors.Is(err, tt.expectedError) {
+				t.Errorf("ReceptionReport() expected error = %v, got = %v", tt.expectedError, err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReceptionReport() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/pkg/hasher"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// lastUsedResolution - как часто обновляется время последнего использования ключа.
+// Запись на каждый запрос интеграции не нужна.
+const lastUsedResolution = time.Minute
+
+type ServiceAccountService struct {
+	repo         interfaces.TxServiceAccountRepository
+	auditService *AuditService
+	txManager    postgres.TxManager
+}
+
+func NewServiceAccountService(
+	repo interfaces.TxServiceAccountRepository,
+	auditService *AuditService,
+	txManager postgres.TxManager,
+) *ServiceAccountService {
+	return &ServiceAccountService{
+		repo:         repo,
+		auditService: auditService,
+		txManager:    txManager,
+	}
+}
+
+func (s *ServiceAccountService) CreateServiceAccount(ctx context.Context, name, description string, createdBy uuid.UUID) (*models.ServiceAccount, error) {
+	ctx, span := tracing.Start(ctx, "ServiceAccountService.CreateServiceAccount")
+	defer span.End()
+
+	account, err := models.NewServiceAccount(name, description, createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		if err := s.repo.WithTx(tx).CreateAccount(ctx, account); err != nil {
+			if errors.Is(err, repoerrors.ErrServiceAccountAlreadyExists) {
+				return err
+			}
+			return fmt.Errorf("failed to save service account: %w", err)
+		}
+
+		return s.auditService.Record(ctx, tx, models.AuditActionServiceAccountCreate, models.AuditEntityServiceAccount, account.ID.String(), nil, account)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("service_account_id", account.ID.String()).
+		Str("name", account.Name).
+		Str("created_by", createdBy.String()).
+		Msg("Service account created")
+	return account, nil
+}
+
+func (s *ServiceAccountService) ListServiceAccounts(ctx context.Context) ([]*models.ServiceAccount, error) {
+	ctx, span := tracing.Start(ctx, "ServiceAccountService.ListServiceAccounts")
+	defer span.End()
+
+	accounts, err := s.repo.ListAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// IssueAPIKey выпускает ключ для сервисной учетной записи. Открытый ключ
+// возвращается только здесь, в базе остается его хеш.
+func (s *ServiceAccountService) IssueAPIKey(
+	ctx context.Context,
+	serviceAccountID uuid.UUID,
+	scopes []string,
+	pvzIDs []uuid.UUID,
+	expiresAt *time.Time,
+) (*models.APIKey, string, error) {
+	ctx, span := tracing.Start(ctx, "ServiceAccountService.IssueAPIKey")
+	defer span.End()
+
+	var key *models.APIKey
+	var rawKey string
+
+	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		txRepo := s.repo.WithTx(tx)
+
+		if _, err := txRepo.GetAccountByID(ctx, serviceAccountID); err != nil {
+			return err
+		}
+
+		newKey, raw, err := models.NewAPIKey(serviceAccountID, scopes, pvzIDs, expiresAt)
+		if err != nil {
+			return err
+		}
+
+		if err := txRepo.CreateKey(ctx, newKey); err != nil {
+			return fmt.Errorf("failed to save API key: %w", err)
+		}
+
+		if err := s.auditService.Record(ctx, tx, models.AuditActionAPIKeyIssue, models.AuditEntityAPIKey, newKey.ID.String(), nil, newKey); err != nil {
+			return err
+		}
+
+		key = newKey
+		rawKey = raw
+		return nil
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("service_account_id", serviceAccountID.String()).
+		Str("api_key_id", key.ID.String()).
+		Strs("scopes", key.Scopes).
+		Msg("API key issued")
+	return key, rawKey, nil
+}
+
+func (s *ServiceAccountService) ListAPIKeys(ctx context.Context, serviceAccountID uuid.UUID) ([]*models.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "ServiceAccountService.ListAPIKeys")
+	defer span.End()
+
+	if _, err := s.repo.GetAccountByID(ctx, serviceAccountID); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.ListKeys(ctx, serviceAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *ServiceAccountService) RevokeAPIKey(ctx context.Context, keyID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "ServiceAccountService.RevokeAPIKey")
+	defer span.End()
+
+	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		if err := s.repo.WithTx(tx).RevokeKey(ctx, keyID); err != nil {
+			return err
+		}
+
+		return s.auditService.Record(ctx, tx, models.AuditActionAPIKeyRevoke, models.AuditEntityAPIKey, keyID.String(), nil, map[string]bool{"revoked": true})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("api_key_id", keyID.String()).
+		Msg("API key revoked")
+	return nil
+}
+
+// Authenticate находит действующий ключ по его открытому значению и отмечает использование.
+func (s *ServiceAccountService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "ServiceAccountService.Authenticate")
+	defer span.End()
+
+	key, err := s.repo.GetKeyByHash(ctx, hasher.HashToken(rawKey))
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrAPIKeyNotFound) {
+			return nil, apperrors.ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		zerolog.Ctx(ctx).Info().
+			Str("api_key_id", key.ID.String()).
+			Msg("Rejected revoked or expired API key")
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchKey(ctx, key.ID, now); err != nil {
+			zerolog.Ctx(ctx).Warn().
+				Err(err).
+				Str("api_key_id", key.ID.String()).
+				Msg("Failed to update API key last used time")
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return key, nil
+}
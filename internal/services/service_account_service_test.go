@@ -0,0 +1,174 @@
+package services
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
+	"avito-backend-trainee-assignment-spring-2025/internal/services/mocks"
+	"avito-backend-trainee-assignment-spring-2025/pkg/hasher"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServiceAccountService(ctrl *gomock.Controller) (*ServiceAccountService, *mocks.MockTxServiceAccountRepository) {
+	mockRepo := mocks.NewMockTxServiceAccountRepository(ctrl)
+
+	mockTxManager := &MockTxManager{
+		RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
+			return fn(nil)
+		},
+	}
+
+	mockRepo.EXPECT().WithTx(gomock.Any()).Return(mockRepo).AnyTimes()
+
+	return NewServiceAccountService(mockRepo, nil, mockTxManager), mockRepo
+}
+
+func TestServiceAccountService_IssueAPIKey(t *testing.T) {
+	accountID := uuid.New()
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name        string
+		scopes      []string
+		setupMocks  func(repo *mocks.MockTxServiceAccountRepository)
+		expectedErr error
+	}{
+		{
+			name:   "успешный выпуск ключа",
+			scopes: []string{models.ScopeReceptionsWrite},
+			setupMocks: func(repo *mocks.MockTxServiceAccountRepository) {
+				repo.EXPECT().GetAccountByID(gomock.Any(), accountID).Return(&models.ServiceAccount{ID: accountID}, nil)
+				repo.EXPECT().CreateKey(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, key *models.APIKey) error {
+						assert.Equal(t, accountID, key.ServiceAccountID)
+						assert.Equal(t, []uuid.UUID{pvzID}, key.PVZIDs)
+						return nil
+					})
+			},
+		},
+		{
+			name:   "учетная запись не найдена",
+			scopes: []string{models.ScopePVZRead},
+			setupMocks: func(repo *mocks.MockTxServiceAccountRepository) {
+				repo.EXPECT().GetAccountByID(gomock.Any(), accountID).Return(nil, repoerrors.ErrServiceAccountNotFound)
+			},
+			expectedErr: repoerrors.ErrServiceAccountNotFound,
+		},
+		{
+			name:   "неизвестная область",
+			scopes: []string{"pvz:delete"},
+			setupMocks: func(repo *mocks.MockTxServiceAccountRepository) {
+				repo.EXPECT().GetAccountByID(gomock.Any(), accountID).Return(&models.ServiceAccount{ID: accountID}, nil)
+			},
+			expectedErr: apperrors.ErrInvalidScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, repo := newTestServiceAccountService(ctrl)
+			tt.setupMocks(repo)
+
+			key, rawKey, err := service.IssueAPIKey(context.Background(), accountID, tt.scopes, []uuid.UUID{pvzID}, nil)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, key)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, hasher.HashToken(rawKey), key.KeyHash)
+			assert.Equal(t, rawKey[:len(key.Prefix)], key.Prefix)
+		})
+	}
+}
+
+func TestServiceAccountService_Authenticate(t *testing.T) {
+	rawKey := "pvz_test-key"
+	keyHash := hasher.HashToken(rawKey)
+	past := time.Now().Add(-time.Hour)
+	recent := time.Now().Add(-10 * time.Second)
+
+	tests := []struct {
+		name        string
+		setupMocks  func(repo *mocks.MockTxServiceAccountRepository)
+		expectedErr error
+	}{
+		{
+			name: "действующий ключ, отметка об использовании обновляется",
+			setupMocks: func(repo *mocks.MockTxServiceAccountRepository) {
+				key := &models.APIKey{ID: uuid.New(), LastUsedAt: &past}
+				repo.EXPECT().GetKeyByHash(gomock.Any(), keyHash).Return(key, nil)
+				repo.EXPECT().TouchKey(gomock.Any(), key.ID, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "недавно использованный ключ не обновляется",
+			setupMocks: func(repo *mocks.MockTxServiceAccountRepository) {
+				key := &models.APIKey{ID: uuid.New(), LastUsedAt: &recent}
+				repo.EXPECT().GetKeyByHash(gomock.Any(), keyHash).Return(key, nil)
+			},
+		},
+		{
+			name: "ошибка обновления отметки не мешает входу",
+			setupMocks: func(repo *mocks.MockTxServiceAccountRepository) {
+				key := &models.APIKey{ID: uuid.New()}
+				repo.EXPECT().GetKeyByHash(gomock.Any(), keyHash).Return(key, nil)
+				repo.EXPECT().TouchKey(gomock.Any(), key.ID, gomock.Any()).Return(errors.New("database error"))
+			},
+		},
+		{
+			name: "неизвестный ключ",
+			setupMocks: func(repo *mocks.MockTxServiceAccountRepository) {
+				repo.EXPECT().GetKeyByHash(gomock.Any(), keyHash).Return(nil, repoerrors.ErrAPIKeyNotFound)
+			},
+			expectedErr: apperrors.ErrInvalidAPIKey,
+		},
+		{
+			name: "отозванный ключ",
+			setupMocks: func(repo *mocks.MockTxServiceAccountRepository) {
+				repo.EXPECT().GetKeyByHash(gomock.Any(), keyHash).Return(&models.APIKey{ID: uuid.New(), RevokedAt: &past}, nil)
+			},
+			expectedErr: apperrors.ErrInvalidAPIKey,
+		},
+		{
+			name: "истекший ключ",
+			setupMocks: func(repo *mocks.MockTxServiceAccountRepository) {
+				repo.EXPECT().GetKeyByHash(gomock.Any(), keyHash).Return(&models.APIKey{ID: uuid.New(), ExpiresAt: &past}, nil)
+			},
+			expectedErr: apperrors.ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, repo := newTestServiceAccountService(ctrl)
+			tt.setupMocks(repo)
+
+			key, err := service.Authenticate(context.Background(), rawKey)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, key)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, key)
+			}
+		})
+	}
+}
@@ -5,37 +5,52 @@ import (
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
+	"avito-backend-trainee-assignment-spring-2025/internal/i18n"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/postgres"
 	"avito-backend-trainee-assignment-spring-2025/internal/repository/repoerrors"
 	"avito-backend-trainee-assignment-spring-2025/pkg/config"
 	"avito-backend-trainee-assignment-spring-2025/pkg/hasher"
+	"avito-backend-trainee-assignment-spring-2025/pkg/tracing"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+	"time"
 )
 
 type UserService struct {
-	repo      interfaces.TxUserRepository
-	jwtConfig config.JWTConfig
-	txManager postgres.TxManager
+	repo            interfaces.TxUserRepository
+	jwtConfig       config.JWTConfig
+	mfaConfig       config.MFAConfig
+	passwordChanges *PasswordChangeCache
+	auditService    *AuditService
+	txManager       postgres.TxManager
 }
 
 func NewUserService(
 	repo interfaces.TxUserRepository,
 	jwtConfig config.JWTConfig,
+	mfaConfig config.MFAConfig,
+	passwordChanges *PasswordChangeCache,
+	auditService *AuditService,
 	txManager postgres.TxManager,
 ) *UserService {
 	return &UserService{
-		repo:      repo,
-		jwtConfig: jwtConfig,
-		txManager: txManager,
+		repo:            repo,
+		jwtConfig:       jwtConfig,
+		mfaConfig:       mfaConfig,
+		passwordChanges: passwordChanges,
+		auditService:    auditService,
+		txManager:       txManager,
 	}
 }
 
 func (s *UserService) Register(ctx context.Context, email, password, role string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Register")
+	defer span.End()
+
 	var user *models.User
 
 	err := s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
@@ -43,7 +58,7 @@ func (s *UserService) Register(ctx context.Context, email, password, role string
 
 		_, err := txRepo.GetByEmail(ctx, email)
 		if err == nil {
-			log.Info().
+			zerolog.Ctx(ctx).Info().
 				Str("email", email).
 				Msg("Registration failed: user already exists")
 			return repoerrors.ErrUserAlreadyExists
@@ -55,7 +70,7 @@ func (s *UserService) Register(ctx context.Context, email, password, role string
 
 		newUser, err := models.NewUser(email, password, role)
 		if err != nil {
-			log.Info().
+			zerolog.Ctx(ctx).Info().
 				Err(err).
 				Str("email", email).
 				Msg("User validation failed during registration")
@@ -66,6 +81,10 @@ func (s *UserService) Register(ctx context.Context, email, password, role string
 			return fmt.Errorf("failed to save user: %w", err)
 		}
 
+		if err := s.auditService.Record(ctx, tx, models.AuditActionUserRegister, models.AuditEntityUser, newUser.ID.String(), nil, newUser); err != nil {
+			return err
+		}
+
 		user = newUser
 		return nil
 	})
@@ -74,7 +93,7 @@ func (s *UserService) Register(ctx context.Context, email, password, role string
 		return nil, err
 	}
 
-	log.Info().
+	zerolog.Ctx(ctx).Info().
 		Str("id", user.ID.String()).
 		Str("email", email).
 		Str("role", role).
@@ -84,44 +103,121 @@ func (s *UserService) Register(ctx context.Context, email, password, role string
 	return user, nil
 }
 
-func (s *UserService) Login(ctx context.Context, email, password string) (string, error) {
+// Login проверяет пароль. Если у пользователя включена 2FA, вместо токена доступа
+// возвращается короткоживущий токен второго шага, который обменивается на токен
+// доступа после ввода кода.
+func (s *UserService) Login(ctx context.Context, email, password string) (*models.LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Login")
+	defer span.End()
+
 	user, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, repoerrors.ErrUserNotFound) {
-			log.Info().
+			zerolog.Ctx(ctx).Info().
 				Str("email", email).
 				Msg("Login failed: user not found")
-			return "", apperrors.ErrInvalidCredentials
+			return nil, apperrors.ErrInvalidCredentials
 		}
-		return "", fmt.Errorf("failed to get user by email: %w", err)
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 
 	if !hasher.Verify(user.PasswordHash, password) {
-		log.Info().
+		zerolog.Ctx(ctx).Info().
 			Str("email", email).
 			Msg("Login failed: invalid password")
-		return "", apperrors.ErrInvalidCredentials
+		return nil, apperrors.ErrInvalidCredentials
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Role, s.jwtConfig.Secret, s.jwtConfig.Expiration)
+	s.rehashPasswordIfNeeded(ctx, user, password)
+
+	if user.MFAEnabled {
+		challenge, err := auth.GenerateMFAChallengeToken(user.ID, user.Role, s.jwtConfig.Secret, s.mfaConfig.ChallengeTTL)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().
+				Err(err).
+				Str("user_id", user.ID.String()).
+				Msg("Failed to generate MFA challenge token")
+			return nil, fmt.Errorf("failed to generate MFA challenge token: %w", err)
+		}
+
+		zerolog.Ctx(ctx).Info().
+			Str("user_id", user.ID.String()).
+			Msg("Password verified, second factor required")
+		return &models.LoginResult{
+			MFARequired:        true,
+			ChallengeToken:     challenge,
+			ChallengeExpiresAt: time.Now().Add(s.mfaConfig.ChallengeTTL),
+		}, nil
+	}
+
+	token, err := auth.GenerateToken(user.ID, user.Role, user.Language, s.jwtConfig.Secret, s.jwtConfig.Expiration)
 	if err != nil {
-		log.Error().
+		zerolog.Ctx(ctx).Error().
 			Err(err).
 			Str("user_id", user.ID.String()).
 			Msg("Failed to generate JWT token")
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	log.Info().
+	zerolog.Ctx(ctx).Info().
 		Str("user_id", user.ID.String()).
 		Str("email", email).
 		Msg("User logged in successfully")
-	return token, nil
+	return &models.LoginResult{Token: token}, nil
+}
+
+// rehashPasswordIfNeeded обновляет хеш, созданный устаревшим алгоритмом или с
+// устаревшими параметрами. Ошибка не прерывает вход: хеш обновится при следующем входе.
+func (s *UserService) rehashPasswordIfNeeded(ctx context.Context, user *models.User, password string) {
+	if !hasher.NeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	newHash, err := hasher.Hash(password)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().
+			Err(err).
+			Str("user_id", user.ID.String()).
+			Msg("Failed to rehash password")
+		return
+	}
+
+	if err := s.repo.UpdatePasswordHash(ctx, user.ID, newHash); err != nil {
+		zerolog.Ctx(ctx).Warn().
+			Err(err).
+			Str("user_id", user.ID.String()).
+			Msg("Failed to store upgraded password hash")
+		return
+	}
+
+	user.PasswordHash = newHash
+	zerolog.Ctx(ctx).Info().
+		Str("user_id", user.ID.String()).
+		Msg("Password hash upgraded")
 }
 
-func (s *UserService) DummyLogin(role string) (string, error) {
+// EnsureAdmin создает администратора с указанными данными, если пользователя с таким
+// email еще нет. Существующая учетная запись не изменяется.
+func (s *UserService) EnsureAdmin(ctx context.Context, email, password string) error {
+	ctx, span := tracing.Start(ctx, "UserService.EnsureAdmin")
+	defer span.End()
+
+	_, err := s.Register(ctx, email, password, models.RoleAdmin)
+	if errors.Is(err, repoerrors.ErrUserAlreadyExists) {
+		zerolog.Ctx(ctx).Info().
+			Str("email", email).
+			Msg("Admin user already exists")
+		return nil
+	}
+	return err
+}
+
+func (s *UserService) DummyLogin(ctx context.Context, role string) (string, error) {
+	ctx, span := tracing.Start(ctx, "UserService.DummyLogin")
+	defer span.End()
+
 	if role != models.RoleEmployee && role != models.RoleModerator {
-		log.Info().
+		zerolog.Ctx(ctx).Info().
 			Str("role", role).
 			Msg("Dummy login failed: invalid role")
 		return "", apperrors.ErrInvalidRole
@@ -129,20 +225,23 @@ func (s *UserService) DummyLogin(role string) (string, error) {
 
 	token, err := auth.GenerateDummyToken(role, s.jwtConfig.Secret, s.jwtConfig.Expiration)
 	if err != nil {
-		log.Error().
+		zerolog.Ctx(ctx).Error().
 			Err(err).
 			Str("role", role).
 			Msg("Failed to generate dummy JWT token")
 		return "", fmt.Errorf("failed to generate dummy token: %w", err)
 	}
 
-	log.Info().
+	zerolog.Ctx(ctx).Info().
 		Str("role", role).
 		Msg("Dummy token generated successfully")
 	return token, nil
 }
 
 func (s *UserService) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByID")
+	defer span.End()
+
 	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
@@ -152,7 +251,95 @@ func (s *UserService) GetUserByID(ctx context.Context, id uuid.UUID) (*models.Us
 	return user, nil
 }
 
+// IsTokenRevoked сообщает, выдан ли токен до последней смены пароля пользователя.
+// Для пользователей, которых нет в базе (тестовые токены), отзыв не применяется. Время
+// смены пароля берется из PasswordChangeCache, а из базы читается только при промахе.
+func (s *UserService) IsTokenRevoked(ctx context.Context, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	ctx, span := tracing.Start(ctx, "UserService.IsTokenRevoked")
+	defer span.End()
+
+	changedAt, ok := s.passwordChanges.Get(userID)
+	if !ok {
+		readAt := time.Now()
+		user, err := s.repo.GetByID(ctx, userID)
+		switch {
+		case errors.Is(err, repoerrors.ErrUserNotFound):
+		case err != nil:
+			return false, fmt.Errorf("failed to get user by ID: %w", err)
+		default:
+			changedAt = user.PasswordChangedAt
+		}
+		s.passwordChanges.Put(userID, changedAt, readAt)
+	}
+
+	if changedAt == nil {
+		return false, nil
+	}
+
+	return issuedAt.Before(changedAt.Truncate(time.Second)), nil
+}
+
+// CheckAccessToken проверяет, что токен доступа не отозван. Проверка общая для HTTP и
+// gRPC API, подпись и срок действия токена проверяются до нее.
+func (s *UserService) CheckAccessToken(ctx context.Context, claims *auth.Claims) error {
+	ctx, span := tracing.Start(ctx, "UserService.CheckAccessToken")
+	defer span.End()
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	revoked, err := s.IsTokenRevoked(ctx, claims.UserID, issuedAt)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return apperrors.ErrTokenRevoked
+	}
+
+	return nil
+}
+
+// SetLanguage сохраняет язык сообщений API для пользователя. Пустая строка сбрасывает
+// выбор, и язык снова определяется заголовком Accept-Language. Язык попадает в токен,
+// поэтому действует для токенов, выданных после изменения.
+func (s *UserService) SetLanguage(ctx context.Context, userID uuid.UUID, language string) error {
+	ctx, span := tracing.Start(ctx, "UserService.SetLanguage")
+	defer span.End()
+
+	if language != "" && !i18n.Supported(language) {
+		return apperrors.ErrInvalidLanguage
+	}
+
+	return s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
+		txRepo := s.repo.WithTx(tx)
+
+		user, err := txRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := txRepo.SetLanguage(ctx, userID, language); err != nil {
+			return fmt.Errorf("failed to set language: %w", err)
+		}
+
+		if err := s.auditService.Record(ctx, tx, models.AuditActionLanguageChange, models.AuditEntityUser, userID.String(), languageSnapshot(user.Language), languageSnapshot(language)); err != nil {
+			return err
+		}
+
+		zerolog.Ctx(ctx).Info().
+			Str("user_id", userID.String()).
+			Str("language", language).
+			Msg("User language changed")
+		return nil
+	})
+}
+
 func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
 	return s.txManager.RunTransaction(ctx, func(tx *sql.Tx) error {
 		txRepo := s.repo.WithTx(tx)
 
@@ -165,9 +352,13 @@ func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 			return fmt.Errorf("failed to delete user: %w", err)
 		}
 
-		log.Info().
+		zerolog.Ctx(ctx).Info().
 			Str("user_id", id.String()).
 			Msg("User deleted successfully")
 		return nil
 	})
 }
+
+func languageSnapshot(language string) map[string]string {
+	return map[string]string{"language": language}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"avito-backend-trainee-assignment-spring-2025/internal/auth"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/apperrors"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/interfaces"
 	"avito-backend-trainee-assignment-spring-2025/internal/domain/models"
@@ -55,7 +56,7 @@ func TestNewUserService(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewUserService(tt.args.repo, tt.args.jwtConfig, tt.args.txManager)
+			got := NewUserService(tt.args.repo, tt.args.jwtConfig, config.MFAConfig{}, nil, nil, tt.args.txManager)
 
 			if got == nil {
 				t.Errorf("NewUserService() returned nil")
@@ -289,7 +290,23 @@ func TestUserService_Login(t *testing.T) {
 		Role:         models.RoleEmployee,
 	}
 
-	tokenStub := "jwt-token-stub"
+	mfaUser := &models.User{
+		ID:           uuid.New(),
+		Email:        "moderator@example.com",
+		PasswordHash: passwordHash,
+		Role:         models.RoleModerator,
+		MFAEnabled:   true,
+	}
+
+	legacyHash, _ := hasher.NewBcrypt(4).Hash(validPassword)
+	legacyUser := &models.User{
+		ID:           uuid.New(),
+		Email:        "legacy@example.com",
+		PasswordHash: legacyHash,
+		Role:         models.RoleEmployee,
+	}
+
+	mfaConfig := config.MFAConfig{ChallengeTTL: 5 * time.Minute}
 
 	type fields struct {
 		repo      interfaces.TxUserRepository
@@ -306,7 +323,7 @@ func TestUserService_Login(t *testing.T) {
 		fields          fields
 		args            args
 		setupMocks      func()
-		want            string
+		wantMFA         bool
 		wantErr         bool
 		expectedErrType error
 	}{
@@ -329,7 +346,52 @@ func TestUserService_Login(t *testing.T) {
 					Return(user, nil)
 
 			},
-			want:    tokenStub,
+			wantErr: false,
+		},
+		{
+			name: "вход с устаревшим хешем пароля обновляет хеш",
+			fields: fields{
+				repo:      mockUserRepo,
+				jwtConfig: jwtConfig,
+				txManager: mockTxManager,
+			},
+			args: args{
+				ctx:      ctx,
+				email:    legacyUser.Email,
+				password: validPassword,
+			},
+			setupMocks: func() {
+				mockUserRepo.EXPECT().
+					GetByEmail(gomock.Any(), legacyUser.Email).
+					Return(legacyUser, nil)
+				mockUserRepo.EXPECT().
+					UpdatePasswordHash(gomock.Any(), legacyUser.ID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ uuid.UUID, newHash string) error {
+						assert.False(t, hasher.NeedsRehash(newHash))
+						assert.True(t, hasher.Verify(newHash, validPassword))
+						return nil
+					})
+			},
+			wantErr: false,
+		},
+		{
+			name: "вход с включенной 2FA требует второй шаг",
+			fields: fields{
+				repo:      mockUserRepo,
+				jwtConfig: jwtConfig,
+				txManager: mockTxManager,
+			},
+			args: args{
+				ctx:      ctx,
+				email:    mfaUser.Email,
+				password: validPassword,
+			},
+			setupMocks: func() {
+				mockUserRepo.EXPECT().
+					GetByEmail(gomock.Any(), mfaUser.Email).
+					Return(mfaUser, nil)
+			},
+			wantMFA: true,
 			wantErr: false,
 		},
 		{
@@ -349,7 +411,6 @@ func TestUserService_Login(t *testing.T) {
 					GetByEmail(gomock.Any(), nonexistentEmail).
 					Return(nil, repoerrors.ErrUserNotFound)
 			},
-			want:            "",
 			wantErr:         true,
 			expectedErrType: apperrors.ErrInvalidCredentials,
 		},
@@ -370,7 +431,6 @@ func TestUserService_Login(t *testing.T) {
 					GetByEmail(gomock.Any(), validEmail).
 					Return(user, nil)
 			},
-			want:            "",
 			wantErr:         true,
 			expectedErrType: apperrors.ErrInvalidCredentials,
 		},
@@ -391,7 +451,6 @@ func TestUserService_Login(t *testing.T) {
 					GetByEmail(gomock.Any(), validEmail).
 					Return(nil, errors.New("ошибка базы данных"))
 			},
-			want:    "",
 			wantErr: true,
 		},
 	}
@@ -405,6 +464,7 @@ func TestUserService_Login(t *testing.T) {
 			s := &UserService{
 				repo:      tt.fields.repo,
 				jwtConfig: tt.fields.jwtConfig,
+				mfaConfig: mfaConfig,
 				txManager: tt.fields.txManager,
 			}
 
@@ -419,14 +479,26 @@ func TestUserService_Login(t *testing.T) {
 				t.Errorf("Login() expected error type = %v, got = %v", tt.expectedErrType, err)
 			}
 
-			if tt.name == "успешный вход" {
-
-				if got == "" {
-					t.Errorf("Login() вернул пустую строку, ожидался непустой JWT-токен")
+			if tt.wantErr {
+				if got != nil {
+					t.Errorf("Login() got = %v, want nil", got)
 				}
-			} else if got != tt.want {
+				return
+			}
+
+			if got.MFARequired != tt.wantMFA {
+				t.Errorf("Login() MFARequired = %v, want %v", got.MFARequired, tt.wantMFA)
+			}
 
-				t.Errorf("Login() got = %v, want %v", got, tt.want)
+			if tt.wantMFA {
+				if got.Token != "" {
+					t.Errorf("Login() выдал токен доступа до проверки второго фактора")
+				}
+				if _, err := auth.ValidateMFAChallengeToken(got.ChallengeToken, jwtConfig.Secret); err != nil {
+					t.Errorf("Login() вернул некорректный токен второго шага: %v", err)
+				}
+			} else if got.Token == "" {
+				t.Errorf("Login() вернул пустую строку, ожидался непустой JWT-токен")
 			}
 		})
 	}
@@ -504,7 +576,7 @@ func TestUserService_DummyLogin(t *testing.T) {
 				txManager: tt.fields.txManager,
 			}
 
-			got, err := s.DummyLogin(tt.args.role)
+			got, err := s.DummyLogin(context.Background(), tt.args.role)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DummyLogin() error = %v, wantErr %v", err, tt.wantErr)
@@ -833,3 +905,216 @@ func TestUserService_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_IsTokenRevoked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockTxUserRepository(ctrl)
+	service := NewUserService(mockUserRepo, config.JWTConfig{}, config.MFAConfig{}, nil, nil, &MockTxManager{})
+
+	userID := uuid.New()
+	changedAt := time.Now()
+
+	tests := []struct {
+		name       string
+		issuedAt   time.Time
+		setupMocks func()
+		want       bool
+		wantErr    bool
+	}{
+		{
+			name:     "токен выдан до смены пароля",
+			issuedAt: changedAt.Add(-time.Hour),
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetByID(gomock.Any(), userID).
+					Return(&models.User{ID: userID, PasswordChangedAt: &changedAt}, nil)
+			},
+			want: true,
+		},
+		{
+			name:     "токен выдан после смены пароля",
+			issuedAt: changedAt.Add(time.Second),
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetByID(gomock.Any(), userID).
+					Return(&models.User{ID: userID, PasswordChangedAt: &changedAt}, nil)
+			},
+			want: false,
+		},
+		{
+			name:     "пароль не менялся",
+			issuedAt: changedAt.Add(-time.Hour),
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetByID(gomock.Any(), userID).
+					Return(&models.User{ID: userID}, nil)
+			},
+			want: false,
+		},
+		{
+			name:     "пользователь не найден",
+			issuedAt: changedAt,
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetByID(gomock.Any(), userID).
+					Return(nil, repoerrors.ErrUserNotFound)
+			},
+			want: false,
+		},
+		{
+			name:     "ошибка базы данных",
+			issuedAt: changedAt,
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetByID(gomock.Any(), userID).
+					Return(nil, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			got, err := service.IsTokenRevoked(context.Background(), userID, tt.issuedAt)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUserService_CheckAccessToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockTxUserRepository(ctrl)
+	service := NewUserService(mockUserRepo, config.JWTConfig{Secret: "test-secret"}, config.MFAConfig{}, nil, nil, &MockTxManager{})
+
+	userID := uuid.New()
+	changedAt := time.Now().Add(time.Hour)
+	token, _ := auth.GenerateToken(userID, models.RoleEmployee, "", "test-secret", time.Hour)
+	claims, _ := auth.ValidateToken(token, "test-secret")
+
+	mockUserRepo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{ID: userID}, nil)
+	assert.NoError(t, service.CheckAccessToken(context.Background(), claims))
+
+	mockUserRepo.EXPECT().GetByID(gomock.Any(), userID).
+		Return(&models.User{ID: userID, PasswordChangedAt: &changedAt}, nil)
+	assert.ErrorIs(t, service.CheckAccessToken(context.Background(), claims), apperrors.ErrTokenRevoked)
+}
+
+func TestUserService_IsTokenRevoked_Cache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockTxUserRepository(ctrl)
+	passwordChanges := NewPasswordChangeCache(time.Minute)
+	service := NewUserService(mockUserRepo, config.JWTConfig{}, config.MFAConfig{}, passwordChanges, nil, &MockTxManager{})
+
+	ctx := context.Background()
+	userID := uuid.New()
+	issuedAt := time.Now()
+
+	// Пока запись не истекла, повторные проверки не читают пользователя
+	mockUserRepo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{ID: userID}, nil)
+	for range 3 {
+		revoked, err := service.IsTokenRevoked(ctx, userID, issuedAt)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	}
+
+	// Смена пароля сбрасывает запись, и следующая проверка видит новое время
+	changedAt := issuedAt.Add(time.Hour)
+	passwordChanges.Invalidate(userID)
+	mockUserRepo.EXPECT().GetByID(gomock.Any(), userID).
+		Return(&models.User{ID: userID, PasswordChangedAt: &changedAt}, nil)
+	revoked, err := service.IsTokenRevoked(ctx, userID, issuedAt)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestPasswordChangeCache_IgnoresReadsBeforeInvalidate(t *testing.T) {
+	cache := NewPasswordChangeCache(time.Minute)
+	userID := uuid.New()
+
+	readAt := time.Now()
+	cache.Invalidate(userID)
+	cache.Put(userID, nil, readAt)
+
+	_, ok := cache.Get(userID)
+	assert.False(t, ok, "value read before the password change must not be cached")
+
+	cache.Put(userID, nil, time.Now())
+	_, ok = cache.Get(userID)
+	assert.True(t, ok)
+}
+
+func TestUserService_SetLanguage(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name        string
+		language    string
+		setupMocks  func(repo *mocks.MockTxUserRepository)
+		expectedErr error
+	}{
+		{
+			name:     "успешный выбор языка",
+			language: "ru",
+			setupMocks: func(repo *mocks.MockTxUserRepository) {
+				repo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{ID: userID}, nil)
+				repo.EXPECT().SetLanguage(gomock.Any(), userID, "ru").Return(nil)
+			},
+		},
+		{
+			name:     "сброс выбора",
+			language: "",
+			setupMocks: func(repo *mocks.MockTxUserRepository) {
+				repo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{ID: userID, Language: "ru"}, nil)
+				repo.EXPECT().SetLanguage(gomock.Any(), userID, "").Return(nil)
+			},
+		},
+		{
+			name:        "ошибка: неподдерживаемый язык",
+			language:    "de",
+			setupMocks:  func(repo *mocks.MockTxUserRepository) {},
+			expectedErr: apperrors.ErrInvalidLanguage,
+		},
+		{
+			name:     "ошибка: пользователь не найден",
+			language: "en",
+			setupMocks: func(repo *mocks.MockTxUserRepository) {
+				repo.EXPECT().GetByID(gomock.Any(), userID).Return(nil, repoerrors.ErrUserNotFound)
+			},
+			expectedErr: repoerrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserRepo := mocks.NewMockTxUserRepository(ctrl)
+			mockUserRepo.EXPECT().WithTx(gomock.Any()).Return(mockUserRepo).AnyTimes()
+			mockTxManager := &MockTxManager{
+				RunTransactionFunc: func(ctx context.Context, fn func(*sql.Tx) error) error {
+					return fn(nil)
+				},
+			}
+			tt.setupMocks(mockUserRepo)
+
+			service := NewUserService(mockUserRepo, config.JWTConfig{}, config.MFAConfig{}, nil, nil, mockTxManager)
+			err := service.SetLanguage(context.Background(), userID, tt.language)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
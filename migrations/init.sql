@@ -26,6 +26,279 @@ CREATE TABLE IF NOT EXISTS users (
     );
 
 CREATE INDEX IF NOT EXISTS idx_reception_pvz_id ON reception(pvz_id);
+CREATE INDEX IF NOT EXISTS idx_pvz_registration_date_id ON pvz(registration_date, id);
+CREATE INDEX IF NOT EXISTS idx_pvz_city_id ON pvz(city, id);
+CREATE INDEX IF NOT EXISTS idx_reception_pvz_id_date_time ON reception(pvz_id, date_time);
 CREATE INDEX IF NOT EXISTS idx_reception_status ON reception(status);
 CREATE INDEX IF NOT EXISTS idx_product_reception_id ON product(reception_id);
-CREATE INDEX IF NOT EXISTS idx_reception_date_time ON reception(date_time);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_reception_date_time ON reception(date_time);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+    );
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('employee', 'moderator', 'admin'));
+
+CREATE TABLE IF NOT EXISTS service_accounts (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+    );
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    service_account_id UUID NOT NULL REFERENCES service_accounts(id) ON DELETE CASCADE,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) UNIQUE NOT NULL,
+    scopes TEXT[] NOT NULL,
+    pvz_ids UUID[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_service_account_id ON api_keys(service_account_id);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id UUID NOT NULL,
+    actor_role VARCHAR(20) NOT NULL DEFAULT '',
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL,
+    -- JSON, а не JSONB: текст снимка должен сохраняться без изменений для проверки хеша
+    before_state JSON,
+    after_state JSON,
+    client_ip VARCHAR(45) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL UNIQUE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_no_modify ON audit_log;
+CREATE TRIGGER audit_log_no_modify
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+ALTER TABLE pvz ADD COLUMN IF NOT EXISTS address TEXT NOT NULL DEFAULT '';
+ALTER TABLE pvz ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90);
+ALTER TABLE pvz ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180);
+ALTER TABLE pvz ADD COLUMN IF NOT EXISTS phone VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE pvz ADD COLUMN IF NOT EXISTS working_hours JSONB;
+ALTER TABLE pvz ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'temporarily_closed', 'decommissioned'));
+
+CREATE INDEX IF NOT EXISTS idx_pvz_latitude_longitude ON pvz(latitude, longitude)
+    WHERE latitude IS NOT NULL AND longitude IS NOT NULL;
+
+ALTER TABLE pvz ADD COLUMN IF NOT EXISTS capacity JSONB;
+
+-- Время закрытия приёмки для отчетов о длительности приёмок. У приёмок, закрытых до
+-- появления колонки, оно неизвестно, и они не учитываются в средней длительности.
+ALTER TABLE reception ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP;
+
+-- Фоновые выгрузки CSV/XLSX. Файлы хранятся в EXPORT_DIR и удаляются вместе с записью
+-- после expires_at.
+CREATE TABLE IF NOT EXISTS export_job (
+    id UUID PRIMARY KEY,
+    dataset VARCHAR(20) NOT NULL CHECK (dataset IN ('pvz', 'receptions', 'products')),
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'xlsx')),
+    filter JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    row_count BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_job_expires_at ON export_job(expires_at) WHERE expires_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_product_date_time ON product(date_time, id);
+
+-- Внешние ID строк массовой загрузки. Повторная загрузка того же файла пропускает строки,
+-- внешний ID которых уже есть, а приёмки и товары находят свои ПВЗ и приёмки по ним.
+CREATE TABLE IF NOT EXISTS import_mapping (
+    dataset VARCHAR(20) NOT NULL CHECK (dataset IN ('pvz', 'receptions', 'products')),
+    external_id VARCHAR(255) NOT NULL,
+    entity_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (dataset, external_id)
+);
+
+-- Лента изменений ПВЗ для GET /events/stream. События пишут триггеры в транзакции
+-- изменения, а NOTIFY будит подписчиков после ее фиксации. Общей блокировки нет, и
+-- транзакции могут фиксировать события не в порядке id: приложение рассылает их по
+-- порядку, дожидаясь на пропуске завершения транзакций, которые могут его занимать,
+-- поэтому клиент, продолжающий ленту с Last-Event-ID, ничего не пропускает. Загрузка
+-- истории событий не создает.
+CREATE TABLE IF NOT EXISTS pvz_event (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(30) NOT NULL CHECK (type IN ('reception.opened', 'reception.closed', 'product.added', 'product.removed')),
+    pvz_id UUID NOT NULL REFERENCES pvz(id),
+    reception_id UUID NOT NULL REFERENCES reception(id),
+    -- Без внешнего ключа: удаленный товар остается в событии product.removed
+    product_id UUID,
+    product_type VARCHAR(20),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pvz_event_pvz_id ON pvz_event(pvz_id, id);
+CREATE INDEX IF NOT EXISTS idx_pvz_event_created_at ON pvz_event(created_at);
+
+CREATE OR REPLACE FUNCTION pvz_event_append(
+    event_type TEXT, event_pvz_id UUID, event_reception_id UUID, event_product_id UUID, event_product_type TEXT
+) RETURNS void AS $$
+BEGIN
+    INSERT INTO pvz_event (type, pvz_id, reception_id, product_id, product_type)
+    VALUES (event_type, event_pvz_id, event_reception_id, event_product_id, event_product_type);
+    PERFORM pg_notify('pvz_events', '');
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION reception_events() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.status = 'in_progress' THEN
+        PERFORM pvz_event_append('reception.opened', NEW.pvz_id, NEW.id, NULL, NULL);
+    ELSIF TG_OP = 'UPDATE' AND OLD.status = 'in_progress' AND NEW.status = 'close' THEN
+        PERFORM pvz_event_append('reception.closed', NEW.pvz_id, NEW.id, NULL, NULL);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS reception_events ON reception;
+CREATE TRIGGER reception_events
+    AFTER INSERT OR UPDATE OF status ON reception
+    FOR EACH ROW EXECUTE FUNCTION reception_events();
+
+CREATE OR REPLACE FUNCTION product_events() RETURNS trigger AS $$
+DECLARE
+    changed product;
+    event_pvz_id UUID;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        changed := NEW;
+    ELSE
+        changed := OLD;
+    END IF;
+
+    -- Товары закрытых приёмок появляются только при загрузке истории
+    SELECT pvz_id INTO event_pvz_id FROM reception WHERE id = changed.reception_id AND status = 'in_progress';
+    IF NOT FOUND THEN
+        RETURN NULL;
+    END IF;
+
+    PERFORM pvz_event_append(
+        CASE TG_OP WHEN 'INSERT' THEN 'product.added' ELSE 'product.removed' END,
+        event_pvz_id, changed.reception_id, changed.id, changed.type
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS product_events ON product;
+CREATE TRIGGER product_events
+    AFTER INSERT OR DELETE ON product
+    FOR EACH ROW EXECUTE FUNCTION product_events();
+
+-- Ответы на запросы с заголовком Idempotency-Key. Пока запрос выполняется, status_code
+-- пуст, и повторы с тем же ключом отклоняются; запись без ответа, заблокированная
+-- дольше IDEMPOTENCY_LOCK_TIMEOUT, и истекшие записи занимаются заново. headers -
+-- заголовки, которые выставил обработчик (Content-Type, ETag, Location).
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    actor_id UUID NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL,
+    status_code INTEGER,
+    headers JSONB,
+    body BYTEA,
+    locked_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (actor_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_key_expires_at ON idempotency_key(expires_at);
+
+-- Версии ПВЗ и приёмок для ETag и If-Match. Изменения с устаревшей версией
+-- отклоняются. Товары входят в ответ на запрос приёмки, поэтому их добавление и
+-- удаление тоже меняют версию приёмки.
+ALTER TABLE pvz ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE reception ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION reception_version_bump() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE reception SET version = version + 1 WHERE id = NEW.reception_id;
+    ELSE
+        UPDATE reception SET version = version + 1 WHERE id = OLD.reception_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS reception_version_bump ON product;
+CREATE TRIGGER reception_version_bump
+    AFTER INSERT OR DELETE ON product
+    FOR EACH ROW EXECUTE FUNCTION reception_version_bump();
+
+-- Язык сообщений API, выбранный пользователем. NULL - язык определяется заголовком
+-- Accept-Language.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(8);
+
+-- Токены второго шага входа по jti: неверные коды и момент входа. Токен принимается,
+-- пока он не использован и не исчерпаны попытки.
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    used_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_user_id ON mfa_challenges(user_id);
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -9,12 +10,24 @@ import (
 )
 
 type Config struct {
-	Server     ServerConfig
-	Logger     LoggerConfig
-	Postgres   PostgresConfig
-	JWT        JWTConfig
-	GRPC       GRPCConfig
-	Prometheus PrometheusConfig
+	Server        ServerConfig
+	Logger        LoggerConfig
+	Postgres      PostgresConfig
+	JWT           JWTConfig
+	GRPC          GRPCConfig
+	Prometheus    PrometheusConfig
+	Tracing       TracingConfig
+	Notifier      NotifierConfig
+	PasswordReset PasswordResetConfig
+	MFA           MFAConfig
+	Password      PasswordConfig
+	Admin         AdminConfig
+	DummyLogin    DummyLoginConfig
+	Capacity      CapacityConfig
+	Export        ExportConfig
+	Import        ImportConfig
+	Events        EventsConfig
+	Idempotency   IdempotencyConfig
 }
 
 type ServerConfig struct {
@@ -50,16 +63,115 @@ type PostgresConfig struct {
 type JWTConfig struct {
 	Secret     string
 	Expiration time.Duration
+	// RevocationCacheTTL - сколько помнить время смены пароля при проверке токенов;
+	// 0 - читать его из базы на каждом запросе.
+	RevocationCacheTTL time.Duration
 }
 
 type GRPCConfig struct {
-	Port string
+	Port         string
+	AuthRequired bool // запрещает вызовы без JWT или API-ключа
+	// KeepaliveTime - через сколько бездействия сервер пингует клиента, KeepaliveTimeout -
+	// сколько ждет ответа, прежде чем закрыть соединение. Так отключившиеся подписчики
+	// WatchReceptions не держат поток.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
 }
 
 type PrometheusConfig struct {
 	Port string
 }
 
+type NotifierConfig struct {
+	Driver       string // "smtp", "file" или "log"
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	FilePath     string // путь к файлу писем, используется только если Driver=file
+}
+
+type PasswordResetConfig struct {
+	TokenTTL time.Duration
+}
+
+type PasswordConfig struct {
+	HashAlgorithm     string // "argon2id" или "bcrypt"
+	Argon2Memory      uint32 // в КиБ
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
+	MinLength         int
+	MaxLength         int
+	BlocklistFile     string // файл со списком скомпрометированных паролей, по одному на строку
+}
+
+type MFAConfig struct {
+	Issuer                string        // название сервиса в приложении-аутентификаторе
+	ChallengeTTL          time.Duration // время жизни токена второго шага входа
+	ChallengeMaxAttempts  int           // сколько неверных кодов можно ввести по одному токену второго шага
+	RequiredForPrivileged bool          // обязательная 2FA для модераторов
+}
+
+// AdminConfig задает администратора, который создается при старте, если его еще нет.
+type AdminConfig struct {
+	Email    string
+	Password string
+}
+
+// DummyLoginConfig управляет тестовым входом POST /dummyLogin. В production оба флага запрещены.
+type DummyLoginConfig struct {
+	Enabled      bool // регистрирует маршрут /dummyLogin
+	AcceptTokens bool // принимает токены, выданные /dummyLogin
+}
+
+// CapacityConfig задает поведение при заполнении ПВЗ.
+type CapacityConfig struct {
+	OverflowPolicy       string        // "reject" - отклонять товары сверх вместимости, "warn" - принимать с предупреждением
+	UtilizationThreshold float64       // доля вместимости, с которой ПВЗ считается переполненным
+	StoragePeriod        time.Duration // сколько товар занимает место в ПВЗ после приёмки
+}
+
+// ExportConfig задает выгрузку данных в CSV и XLSX.
+type ExportConfig struct {
+	Dir               string        // каталог файлов фоновых выгрузок
+	FetchSize         int           // сколько строк читается из курсора БД за раз
+	StreamTimeout     time.Duration // предельное время потоковой выгрузки в ответе на запрос
+	JobTTL            time.Duration // сколько хранится файл фоновой выгрузки
+	MaxConcurrentJobs int           // сколько фоновых выгрузок выполняется одновременно
+}
+
+// ImportConfig задает массовую загрузку ПВЗ, приёмок и товаров из файлов.
+type ImportConfig struct {
+	ChunkSize   int           // сколько строк загружается в одной транзакции
+	MaxFileSize int64         // предельный размер загружаемого по HTTP файла в байтах
+	Timeout     time.Duration // предельное время загрузки файла по HTTP
+}
+
+// TracingConfig задает экспорт трасс OpenTelemetry.
+type TracingConfig struct {
+	Exporter    string  // otlp, stdout или none
+	Endpoint    string  // адрес OTLP-коллектора по gRPC
+	Insecure    bool    // подключаться к коллектору без TLS
+	ServiceName string  // имя сервиса в трассах
+	SampleRatio float64 // доля записываемых трасс, если вызывающий не передал решение
+}
+
+// EventsConfig задает ленту изменений ПВЗ.
+type EventsConfig struct {
+	PollInterval      time.Duration // как часто лента проверяется без уведомления из БД
+	HeartbeatInterval time.Duration // как часто подписчику отправляется пустое сообщение
+	BufferSize        int           // сколько событий ждут отправки подписчику, дальше он дочитывает их из БД
+	Retention         time.Duration // сколько хранятся события для продолжения с Last-Event-ID
+}
+
+// IdempotencyConfig задает хранение ответов на запросы с заголовком Idempotency-Key.
+type IdempotencyConfig struct {
+	TTL         time.Duration // сколько хранится ответ, повтор после этого выполняется заново
+	LockTimeout time.Duration // через сколько незавершенный запрос считается прерванным
+}
+
 func Load() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		fmt.Printf("Warning: .env file not found. Using environment variables.\n")
@@ -98,15 +210,94 @@ func Load() (*Config, error) {
 			QueryTimeout:       viper.GetDuration("DB_QUERY_TIMEOUT"),
 		},
 		JWT: JWTConfig{
-			Secret:     viper.GetString("JWT_SECRET"),
-			Expiration: viper.GetDuration("JWT_EXPIRATION"),
+			Secret:             viper.GetString("JWT_SECRET"),
+			Expiration:         viper.GetDuration("JWT_EXPIRATION"),
+			RevocationCacheTTL: viper.GetDuration("JWT_REVOCATION_CACHE_TTL"),
 		},
 		GRPC: GRPCConfig{
-			Port: viper.GetString("APP_GRPC_PORT"),
+			Port:             viper.GetString("APP_GRPC_PORT"),
+			AuthRequired:     viper.GetBool("GRPC_AUTH_REQUIRED"),
+			KeepaliveTime:    viper.GetDuration("GRPC_KEEPALIVE_TIME"),
+			KeepaliveTimeout: viper.GetDuration("GRPC_KEEPALIVE_TIMEOUT"),
 		},
 		Prometheus: PrometheusConfig{
 			Port: viper.GetString("APP_PROMETHEUS_PORT"),
 		},
+		Tracing: TracingConfig{
+			Exporter:    viper.GetString("TRACING_EXPORTER"),
+			Endpoint:    viper.GetString("TRACING_OTLP_ENDPOINT"),
+			Insecure:    viper.GetBool("TRACING_OTLP_INSECURE"),
+			ServiceName: viper.GetString("TRACING_SERVICE_NAME"),
+			SampleRatio: viper.GetFloat64("TRACING_SAMPLE_RATIO"),
+		},
+		Notifier: NotifierConfig{
+			Driver:       viper.GetString("NOTIFIER_DRIVER"),
+			From:         viper.GetString("NOTIFIER_FROM"),
+			SMTPHost:     viper.GetString("SMTP_HOST"),
+			SMTPPort:     viper.GetString("SMTP_PORT"),
+			SMTPUser:     viper.GetString("SMTP_USER"),
+			SMTPPassword: viper.GetString("SMTP_PASSWORD"),
+			FilePath:     viper.GetString("NOTIFIER_FILE_PATH"),
+		},
+		PasswordReset: PasswordResetConfig{
+			TokenTTL: viper.GetDuration("PASSWORD_RESET_TOKEN_TTL"),
+		},
+		Password: PasswordConfig{
+			HashAlgorithm:     viper.GetString("PASSWORD_HASH_ALGORITHM"),
+			Argon2Memory:      viper.GetUint32("ARGON2_MEMORY"),
+			Argon2Iterations:  viper.GetUint32("ARGON2_ITERATIONS"),
+			Argon2Parallelism: uint8(viper.GetUint("ARGON2_PARALLELISM")),
+			BcryptCost:        viper.GetInt("BCRYPT_COST"),
+			MinLength:         viper.GetInt("PASSWORD_MIN_LENGTH"),
+			MaxLength:         viper.GetInt("PASSWORD_MAX_LENGTH"),
+			BlocklistFile:     viper.GetString("PASSWORD_BLOCKLIST_FILE"),
+		},
+		MFA: MFAConfig{
+			Issuer:                viper.GetString("MFA_ISSUER"),
+			ChallengeTTL:          viper.GetDuration("MFA_CHALLENGE_TTL"),
+			ChallengeMaxAttempts:  viper.GetInt("MFA_CHALLENGE_MAX_ATTEMPTS"),
+			RequiredForPrivileged: viper.GetBool("MFA_REQUIRED_FOR_PRIVILEGED"),
+		},
+		Admin: AdminConfig{
+			Email:    viper.GetString("ADMIN_EMAIL"),
+			Password: viper.GetString("ADMIN_PASSWORD"),
+		},
+		DummyLogin: DummyLoginConfig{
+			Enabled:      viper.GetBool("DUMMY_LOGIN_ENABLED"),
+			AcceptTokens: viper.GetBool("DUMMY_TOKENS_ACCEPTED"),
+		},
+		Capacity: CapacityConfig{
+			OverflowPolicy:       viper.GetString("CAPACITY_OVERFLOW_POLICY"),
+			UtilizationThreshold: viper.GetFloat64("CAPACITY_UTILIZATION_THRESHOLD"),
+			StoragePeriod:        viper.GetDuration("CAPACITY_STORAGE_PERIOD"),
+		},
+		Export: ExportConfig{
+			Dir:               viper.GetString("EXPORT_DIR"),
+			FetchSize:         viper.GetInt("EXPORT_FETCH_SIZE"),
+			StreamTimeout:     viper.GetDuration("EXPORT_STREAM_TIMEOUT"),
+			JobTTL:            viper.GetDuration("EXPORT_JOB_TTL"),
+			MaxConcurrentJobs: viper.GetInt("EXPORT_MAX_CONCURRENT_JOBS"),
+		},
+		Import: ImportConfig{
+			ChunkSize:   viper.GetInt("IMPORT_CHUNK_SIZE"),
+			MaxFileSize: viper.GetInt64("IMPORT_MAX_FILE_SIZE"),
+			Timeout:     viper.GetDuration("IMPORT_TIMEOUT"),
+		},
+		Events: EventsConfig{
+			PollInterval:      viper.GetDuration("EVENTS_POLL_INTERVAL"),
+			HeartbeatInterval: viper.GetDuration("EVENTS_HEARTBEAT_INTERVAL"),
+			BufferSize:        viper.GetInt("EVENTS_BUFFER_SIZE"),
+			Retention:         viper.GetDuration("EVENTS_RETENTION"),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:         viper.GetDuration("IDEMPOTENCY_TTL"),
+			LockTimeout: viper.GetDuration("IDEMPOTENCY_LOCK_TIMEOUT"),
+		},
+	}
+
+	// Argon2Parallelism - uint8, и большее значение молча обрезалось бы при приведении
+	if viper.GetUint("ARGON2_PARALLELISM") > math.MaxUint8 {
+		return nil, fmt.Errorf("ARGON2_PARALLELISM must not exceed %d", math.MaxUint8)
 	}
 
 	if err := validateConfig(config); err != nil {
@@ -143,10 +334,66 @@ func setDefaults() {
 
 	viper.SetDefault("JWT_SECRET", "default_secret_key_change_this_in_production")
 	viper.SetDefault("JWT_EXPIRATION", 24*time.Hour)
+	viper.SetDefault("JWT_REVOCATION_CACHE_TTL", 30*time.Second)
 
 	viper.SetDefault("APP_GRPC_PORT", "3000")
+	viper.SetDefault("GRPC_AUTH_REQUIRED", false)
+	viper.SetDefault("GRPC_KEEPALIVE_TIME", 30*time.Second)
+	viper.SetDefault("GRPC_KEEPALIVE_TIMEOUT", 10*time.Second)
 
 	viper.SetDefault("APP_PROMETHEUS_PORT", "9000")
+
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_OTLP_ENDPOINT", "localhost:4317")
+	viper.SetDefault("TRACING_OTLP_INSECURE", true)
+	viper.SetDefault("TRACING_SERVICE_NAME", "pvz-service")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+
+	viper.SetDefault("NOTIFIER_DRIVER", "log")
+	viper.SetDefault("NOTIFIER_FROM", "no-reply@pvz-service.local")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("NOTIFIER_FILE_PATH", "./logs/mail.log")
+
+	viper.SetDefault("PASSWORD_RESET_TOKEN_TTL", 30*time.Minute)
+
+	viper.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	viper.SetDefault("ARGON2_MEMORY", 64*1024)
+	viper.SetDefault("ARGON2_ITERATIONS", 3)
+	viper.SetDefault("ARGON2_PARALLELISM", 2)
+	viper.SetDefault("BCRYPT_COST", 10)
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 6)
+	viper.SetDefault("PASSWORD_MAX_LENGTH", 128)
+
+	viper.SetDefault("MFA_ISSUER", "PVZ Service")
+	viper.SetDefault("MFA_CHALLENGE_TTL", 5*time.Minute)
+	viper.SetDefault("MFA_CHALLENGE_MAX_ATTEMPTS", 5)
+	viper.SetDefault("MFA_REQUIRED_FOR_PRIVILEGED", false)
+
+	viper.SetDefault("DUMMY_LOGIN_ENABLED", false)
+	// По умолчанию тестовые токены принимаются везде, кроме production
+	viper.SetDefault("DUMMY_TOKENS_ACCEPTED", viper.GetString("APP_ENV") != "production")
+
+	viper.SetDefault("CAPACITY_OVERFLOW_POLICY", "reject")
+	viper.SetDefault("CAPACITY_UTILIZATION_THRESHOLD", 0.9)
+	viper.SetDefault("CAPACITY_STORAGE_PERIOD", 7*24*time.Hour)
+
+	viper.SetDefault("EXPORT_DIR", "./exports")
+	viper.SetDefault("EXPORT_FETCH_SIZE", 1000)
+	viper.SetDefault("EXPORT_STREAM_TIMEOUT", 10*time.Minute)
+	viper.SetDefault("EXPORT_JOB_TTL", 24*time.Hour)
+	viper.SetDefault("EXPORT_MAX_CONCURRENT_JOBS", 2)
+
+	viper.SetDefault("IMPORT_CHUNK_SIZE", 500)
+	viper.SetDefault("IMPORT_MAX_FILE_SIZE", 50<<20)
+	viper.SetDefault("IMPORT_TIMEOUT", 10*time.Minute)
+
+	viper.SetDefault("EVENTS_POLL_INTERVAL", 5*time.Second)
+	viper.SetDefault("EVENTS_HEARTBEAT_INTERVAL", 15*time.Second)
+	viper.SetDefault("EVENTS_BUFFER_SIZE", 256)
+	viper.SetDefault("EVENTS_RETENTION", 7*24*time.Hour)
+
+	viper.SetDefault("IDEMPOTENCY_TTL", 24*time.Hour)
+	viper.SetDefault("IDEMPOTENCY_LOCK_TIMEOUT", time.Minute)
 }
 
 func validateConfig(cfg *Config) error {
@@ -154,9 +401,83 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("JWT_SECRET should be changed in production")
 	}
 
+	if cfg.Server.AppEnv == "production" && (cfg.DummyLogin.Enabled || cfg.DummyLogin.AcceptTokens) {
+		return fmt.Errorf("DUMMY_LOGIN_ENABLED and DUMMY_TOKENS_ACCEPTED must be disabled in production")
+	}
+
 	if cfg.Postgres.DB == "" || cfg.Postgres.User == "" {
 		return fmt.Errorf("POSTGRES_DB and POSTGRES_USER are required")
 	}
 
+	if cfg.Password.HashAlgorithm != "argon2id" && cfg.Password.HashAlgorithm != "bcrypt" {
+		return fmt.Errorf("PASSWORD_HASH_ALGORITHM must be argon2id or bcrypt")
+	}
+
+	if cfg.Password.MinLength < 1 || cfg.Password.MaxLength < cfg.Password.MinLength {
+		return fmt.Errorf("PASSWORD_MIN_LENGTH must be positive and not greater than PASSWORD_MAX_LENGTH")
+	}
+
+	if cfg.MFA.ChallengeMaxAttempts < 1 {
+		return fmt.Errorf("MFA_CHALLENGE_MAX_ATTEMPTS must be positive")
+	}
+
+	if (cfg.Admin.Email == "") != (cfg.Admin.Password == "") {
+		return fmt.Errorf("ADMIN_EMAIL and ADMIN_PASSWORD must be set together")
+	}
+
+	if cfg.Notifier.Driver == "smtp" && cfg.Notifier.SMTPHost == "" {
+		return fmt.Errorf("SMTP_HOST is required when NOTIFIER_DRIVER is smtp")
+	}
+
+	if cfg.Capacity.OverflowPolicy != "reject" && cfg.Capacity.OverflowPolicy != "warn" {
+		return fmt.Errorf("CAPACITY_OVERFLOW_POLICY must be reject or warn")
+	}
+
+	if cfg.Capacity.UtilizationThreshold <= 0 {
+		return fmt.Errorf("CAPACITY_UTILIZATION_THRESHOLD must be positive")
+	}
+
+	if cfg.Capacity.StoragePeriod <= 0 {
+		return fmt.Errorf("CAPACITY_STORAGE_PERIOD must be positive")
+	}
+
+	if cfg.Export.Dir == "" {
+		return fmt.Errorf("EXPORT_DIR is required")
+	}
+
+	if cfg.Export.FetchSize < 1 || cfg.Export.MaxConcurrentJobs < 1 {
+		return fmt.Errorf("EXPORT_FETCH_SIZE and EXPORT_MAX_CONCURRENT_JOBS must be positive")
+	}
+
+	if cfg.Import.ChunkSize < 1 || cfg.Import.MaxFileSize < 1 {
+		return fmt.Errorf("IMPORT_CHUNK_SIZE and IMPORT_MAX_FILE_SIZE must be positive")
+	}
+
+	if cfg.Events.PollInterval <= 0 || cfg.Events.HeartbeatInterval <= 0 || cfg.Events.BufferSize < 1 {
+		return fmt.Errorf("EVENTS_POLL_INTERVAL, EVENTS_HEARTBEAT_INTERVAL and EVENTS_BUFFER_SIZE must be positive")
+	}
+
+	if cfg.Idempotency.TTL <= 0 || cfg.Idempotency.LockTimeout <= 0 {
+		return fmt.Errorf("IDEMPOTENCY_TTL and IDEMPOTENCY_LOCK_TIMEOUT must be positive")
+	}
+
+	switch cfg.Tracing.Exporter {
+	case "otlp", "stdout", "none":
+	default:
+		return fmt.Errorf("TRACING_EXPORTER must be otlp, stdout or none")
+	}
+
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+
+	if cfg.GRPC.KeepaliveTime <= 0 || cfg.GRPC.KeepaliveTimeout <= 0 {
+		return fmt.Errorf("GRPC_KEEPALIVE_TIME and GRPC_KEEPALIVE_TIMEOUT must be positive")
+	}
+
+	if cfg.JWT.RevocationCacheTTL < 0 {
+		return fmt.Errorf("JWT_REVOCATION_CACHE_TTL must not be negative")
+	}
+
 	return nil
 }
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// utf8BOM нужен Excel, чтобы распознать кодировку и правильно показать кириллицу.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+type CSVWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+// NewCSVWriter буферизует вывод вместе с BOM, поэтому до заполнения буфера в w ничего
+// не пишется и ошибку в начале выгрузки еще можно вернуть клиенту.
+func NewCSVWriter(w io.Writer) (*CSVWriter, error) {
+	// csv.Writer использует переданный *bufio.Writer как есть, без второго буфера
+	buf := bufio.NewWriter(w)
+	if _, err := buf.Write(utf8BOM); err != nil {
+		return nil, fmt.Errorf("failed to write BOM: %w", err)
+	}
+
+	return &CSVWriter{w: csv.NewWriter(buf)}, nil
+}
+
+func (cw *CSVWriter) WriteHeader(columns []string) error {
+	return cw.w.Write(columns)
+}
+
+func (cw *CSVWriter) WriteRow(values []any) error {
+	cw.record = cw.record[:0]
+	for _, value := range values {
+		cw.record = append(cw.record, formatCSVValue(indirect(value)))
+	}
+
+	if err := cw.w.Write(cw.record); err != nil {
+		return err
+	}
+
+	// Буфер csv.Writer сбрасывается, когда заполнится; здесь проверяется ошибка
+	// предыдущих сбросов, чтобы прервать выгрузку при разрыве соединения
+	return cw.w.Error()
+}
+
+func (cw *CSVWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func formatCSVValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,67 @@
+// Package export пишет табличные данные в CSV и XLSX построчно, не накапливая их в памяти.
+package export
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Форматы выгрузки
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Writer записывает таблицу: сначала заголовок, затем строки. Значения строки - строки,
+// числа, bool, time.Time или указатели на них; nil записывается пустой ячейкой.
+// Close дописывает файл и должен быть вызван после последней строки.
+type Writer interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []any) error
+	Close() error
+}
+
+// NewWriter возвращает Writer для формата format, пишущий в w.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w)
+	case FormatXLSX:
+		return NewXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// IsValidFormat сообщает, поддерживается ли формат выгрузки.
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatXLSX
+}
+
+// ContentType возвращает MIME-тип файла выгрузки.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// indirect разыменовывает указатели, nil-указатель превращается в nil.
+func indirect(value any) any {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
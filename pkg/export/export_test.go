@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+func writeTable(t *testing.T, format string, rows [][]any) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	if err := w.WriteHeader([]string{"id", "city", "registered", "products", "closed"}); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	id := uuid.MustParse("7f1c2b4e-9a3d-4c5e-8f6a-1b2c3d4e5f60")
+	registered := time.Date(2025, 4, 10, 12, 30, 0, 0, time.UTC)
+	var closed *time.Time
+
+	got := writeTable(t, FormatCSV, [][]any{
+		{id, "Санкт-Петербург", registered, 12, closed},
+		{id, `ПВЗ "Центр", 1`, &registered, 0.5, &registered},
+	})
+
+	want := "\xEF\xBB\xBF" +
+		"id,city,registered,products,closed\n" +
+		"7f1c2b4e-9a3d-4c5e-8f6a-1b2c3d4e5f60,Санкт-Петербург,2025-04-10T12:30:00Z,12,\n" +
+		`7f1c2b4e-9a3d-4c5e-8f6a-1b2c3d4e5f60,"ПВЗ ""Центр"", 1",2025-04-10T12:30:00Z,0.5,2025-04-10T12:30:00Z` + "\n"
+
+	if string(got) != want {
+		t.Errorf("CSV output = %q, want %q", got, want)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	id := uuid.New()
+	registered := time.Date(2025, 4, 10, 12, 30, 0, 0, time.UTC)
+	var closed *time.Time
+
+	got := writeTable(t, FormatXLSX, [][]any{
+		{id, "Казань", registered, 12, closed},
+	})
+
+	file, err := excelize.OpenReader(bytes.NewReader(got))
+	if err != nil {
+		t.Fatalf("OpenReader() error = %v", err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows("Sheet1")
+	if err != nil {
+		t.Fatalf("GetRows() error = %v", err)
+	}
+
+	want := [][]string{
+		{"id", "city", "registered", "products", "closed"},
+		{id.String(), "Казань", "2025-04-10 12:30:00", "12"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %v, want %v", rows, want)
+	}
+	for i := range want {
+		if len(rows[i]) != len(want[i]) {
+			t.Fatalf("row %d = %v, want %v", i, rows[i], want[i])
+		}
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("cell [%d][%d] = %q, want %q", i, j, rows[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	if _, err := NewWriter("pdf", &bytes.Buffer{}); err == nil {
+		t.Error("NewWriter() expected error for unknown format")
+	}
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxDateFormat - формат ячеек с датой и временем.
+const xlsxDateFormat = "yyyy-mm-dd hh:mm:ss"
+
+// XLSXWriter пишет строки через потоковый режим excelize: строки листа сбрасываются
+// во временный файл, а не держатся в памяти. Когда лист заполняется до предела Excel,
+// выгрузка продолжается на следующем листе с тем же заголовком.
+type XLSXWriter struct {
+	out       io.Writer
+	file      *excelize.File
+	stream    *excelize.StreamWriter
+	dateStyle int
+	header    []any
+	sheets    int
+	row       int
+	cells     []any
+}
+
+func NewXLSXWriter(w io.Writer) (*XLSXWriter, error) {
+	file := excelize.NewFile()
+
+	dateFormat := xlsxDateFormat
+	dateStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to create date style: %w", err)
+	}
+
+	xw := &XLSXWriter{
+		out:       w,
+		file:      file,
+		dateStyle: dateStyle,
+	}
+
+	if err := xw.nextSheet(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return xw, nil
+}
+
+func (xw *XLSXWriter) WriteHeader(columns []string) error {
+	xw.header = make([]any, len(columns))
+	for i, column := range columns {
+		xw.header[i] = column
+	}
+
+	return xw.writeRow(xw.header)
+}
+
+func (xw *XLSXWriter) WriteRow(values []any) error {
+	if xw.row >= excelize.TotalRows {
+		if err := xw.stream.Flush(); err != nil {
+			return fmt.Errorf("failed to flush sheet: %w", err)
+		}
+		if err := xw.nextSheet(); err != nil {
+			return err
+		}
+		if xw.header != nil {
+			if err := xw.writeRow(xw.header); err != nil {
+				return err
+			}
+		}
+	}
+
+	xw.cells = xw.cells[:0]
+	for _, value := range values {
+		value = indirect(value)
+		if t, ok := value.(time.Time); ok {
+			value = excelize.Cell{StyleID: xw.dateStyle, Value: t}
+		} else if s, ok := value.(fmt.Stringer); ok {
+			value = s.String()
+		}
+		xw.cells = append(xw.cells, value)
+	}
+
+	return xw.writeRow(xw.cells)
+}
+
+func (xw *XLSXWriter) Close() error {
+	defer xw.file.Close()
+
+	if err := xw.stream.Flush(); err != nil {
+		return fmt.Errorf("failed to flush sheet: %w", err)
+	}
+
+	if _, err := xw.file.WriteTo(xw.out); err != nil {
+		return fmt.Errorf("failed to write xlsx file: %w", err)
+	}
+
+	return nil
+}
+
+func (xw *XLSXWriter) nextSheet() error {
+	xw.sheets++
+	name := fmt.Sprintf("Sheet%d", xw.sheets)
+
+	if xw.sheets > 1 {
+		if _, err := xw.file.NewSheet(name); err != nil {
+			return fmt.Errorf("failed to create sheet: %w", err)
+		}
+	}
+
+	stream, err := xw.file.NewStreamWriter(name)
+	if err != nil {
+		return fmt.Errorf("failed to create sheet stream: %w", err)
+	}
+
+	xw.stream = stream
+	xw.row = 0
+	return nil
+}
+
+func (xw *XLSXWriter) writeRow(values []any) error {
+	xw.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+
+	return xw.stream.SetRow(cell, values)
+}
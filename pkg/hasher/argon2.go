@@ -0,0 +1,134 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2idPrefix = "$argon2id$"
+
+type Argon2idParams struct {
+	Memory      uint32 // в КиБ
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams соответствуют рекомендациям OWASP для argon2id.
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// validate отклоняет параметры, с которыми argon2.IDKey паникует или возвращает пустой
+// ключ, и память меньше 8 КиБ на поток, которую argon2 молча увеличил бы.
+func (p Argon2idParams) validate() error {
+	if p.Iterations < 1 || p.Parallelism < 1 || p.KeyLength < 1 {
+		return fmt.Errorf("invalid argon2id parameters: iterations, parallelism and key length must be positive")
+	}
+	if p.Memory < 8*uint32(p.Parallelism) {
+		return fmt.Errorf("invalid argon2id parameters: memory must be at least 8 KiB per thread")
+	}
+	return nil
+}
+
+// Argon2id хранит хеши в формате PHC: $argon2id$v=19$m=65536,t=3,p=2$<соль>$<хеш>.
+type Argon2id struct {
+	params Argon2idParams
+}
+
+func NewArgon2id(params Argon2idParams) *Argon2id {
+	return &Argon2id{params: params}
+}
+
+func (a *Argon2id) Identifies(passwordHash string) bool {
+	return strings.HasPrefix(passwordHash, argon2idPrefix)
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.params.Iterations, a.params.Memory, a.params.Parallelism, a.params.KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		a.params.Memory,
+		a.params.Iterations,
+		a.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (a *Argon2id) Verify(passwordHash, password string) bool {
+	params, salt, key, err := decodeArgon2id(passwordHash)
+	if err != nil {
+		return false
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, candidate) == 1
+}
+
+func (a *Argon2id) Outdated(passwordHash string) bool {
+	params, _, _, err := decodeArgon2id(passwordHash)
+	if err != nil {
+		return true
+	}
+
+	return params.Memory != a.params.Memory ||
+		params.Iterations != a.params.Iterations ||
+		params.Parallelism != a.params.Parallelism ||
+		params.KeyLength != a.params.KeyLength
+}
+
+func decodeArgon2id(passwordHash string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id key: %w", err)
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	// argon2.IDKey паникует на нулевых параметрах, поэтому хеш с ними не проверяется
+	if err := params.validate(); err != nil {
+		return params, nil, nil, err
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,55 @@
+package hasher
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const DefaultBcryptCost = bcrypt.DefaultCost
+
+// Bcrypt оставлен для проверки хешей, созданных до перехода на argon2id.
+type Bcrypt struct {
+	cost int
+}
+
+// validateBcryptCost отклоняет стоимость, с которой bcrypt молча хешировал бы с
+// DefaultCost или возвращал ошибку на каждом пароле.
+func validateBcryptCost(cost int) error {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("invalid bcrypt cost: must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return nil
+}
+
+func NewBcrypt(cost int) *Bcrypt {
+	return &Bcrypt{cost: cost}
+}
+
+func (b *Bcrypt) Identifies(passwordHash string) bool {
+	return strings.HasPrefix(passwordHash, "$2a$") ||
+		strings.HasPrefix(passwordHash, "$2b$") ||
+		strings.HasPrefix(passwordHash, "$2y$")
+}
+
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedBytes), nil
+}
+
+func (b *Bcrypt) Verify(passwordHash, password string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
+	return err == nil
+}
+
+func (b *Bcrypt) Outdated(passwordHash string) bool {
+	cost, err := bcrypt.Cost([]byte(passwordHash))
+	if err != nil {
+		return true
+	}
+	return cost != b.cost
+}
@@ -1,16 +1,129 @@
 package hasher
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
 
-func Hash(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
+// Scheme - алгоритм хеширования паролей. Хеш каждой схемы содержит идентификатор
+// алгоритма и параметры, поэтому старые хеши проверяются и после смены настроек.
+type Scheme interface {
+	// Identifies сообщает, создан ли хеш этой схемой
+	Identifies(passwordHash string) bool
+	Hash(password string) (string, error)
+	Verify(passwordHash, password string) bool
+	// Outdated сообщает, отличаются ли параметры хеша от текущих параметров схемы
+	Outdated(passwordHash string) bool
+}
+
+// Hasher создает хеши основной схемой и проверяет хеши всех известных схем.
+type Hasher struct {
+	primary Scheme
+	legacy  []Scheme
+}
+
+func NewHasher(primary Scheme, legacy ...Scheme) *Hasher {
+	return &Hasher{primary: primary, legacy: legacy}
+}
+
+// New создает хешер с основной схемой algorithm ("argon2id" или "bcrypt"); вторая схема
+// проверяет старые хеши. Параметры обеих схем проверяются при любом algorithm, потому что
+// вторая может стать основной при смене алгоритма. Нулевые длины соли и ключа argon2id
+// берутся из DefaultArgon2idParams.
+func New(algorithm string, argonParams Argon2idParams, bcryptCost int) (*Hasher, error) {
+	if argonParams.SaltLength == 0 {
+		argonParams.SaltLength = DefaultArgon2idParams.SaltLength
+	}
+	if argonParams.KeyLength == 0 {
+		argonParams.KeyLength = DefaultArgon2idParams.KeyLength
+	}
+	if err := argonParams.validate(); err != nil {
+		return nil, err
+	}
+	if err := validateBcryptCost(bcryptCost); err != nil {
+		return nil, err
 	}
-	return string(hashedBytes), nil
+
+	argon := NewArgon2id(argonParams)
+	bcryptScheme := NewBcrypt(bcryptCost)
+
+	switch algorithm {
+	case "argon2id":
+		return NewHasher(argon, bcryptScheme), nil
+	case "bcrypt":
+		return NewHasher(bcryptScheme, argon), nil
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm: %s", algorithm)
+	}
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.primary.Hash(password)
+}
+
+func (h *Hasher) Verify(passwordHash, password string) bool {
+	scheme := h.schemeFor(passwordHash)
+	if scheme == nil {
+		return false
+	}
+	return scheme.Verify(passwordHash, password)
+}
+
+// NeedsRehash сообщает, что хеш создан другой схемой или с устаревшими параметрами.
+func (h *Hasher) NeedsRehash(passwordHash string) bool {
+	if !h.primary.Identifies(passwordHash) {
+		return true
+	}
+	return h.primary.Outdated(passwordHash)
+}
+
+func (h *Hasher) schemeFor(passwordHash string) Scheme {
+	if h.primary.Identifies(passwordHash) {
+		return h.primary
+	}
+	for _, scheme := range h.legacy {
+		if scheme.Identifies(passwordHash) {
+			return scheme
+		}
+	}
+	return nil
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultHasher = NewHasher(NewArgon2id(DefaultArgon2idParams), NewBcrypt(DefaultBcryptCost))
+)
+
+// SetDefault заменяет хешер, используемый функциями пакета. Вызывается при старте приложения.
+func SetDefault(h *Hasher) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultHasher = h
+}
+
+func getDefault() *Hasher {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultHasher
+}
+
+func Hash(password string) (string, error) {
+	return getDefault().Hash(password)
 }
 
 func Verify(passwordHash, password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
-	return err == nil
+	return getDefault().Verify(passwordHash, password)
+}
+
+func NeedsRehash(passwordHash string) bool {
+	return getDefault().NeedsRehash(passwordHash)
+}
+
+// HashToken возвращает SHA-256 от случайного токена. Токены высокоэнтропийные,
+// поэтому медленный хеш вроде bcrypt не нужен, а поиск по хешу остается возможным.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -1,6 +1,9 @@
 package hasher
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestHash(t *testing.T) {
 	type args struct {
@@ -120,3 +123,143 @@ func TestVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestHashToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{
+			name:  "Known token",
+			token: "reset-token",
+			want:  "7c18b43a1d8227cddb332e67971e790ce35ac2303f4fccfb2a565622f2fe1cec",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HashToken(tt.token)
+			if got != tt.want {
+				t.Errorf("HashToken() = %v, want %v", got, tt.want)
+			}
+			if got == HashToken(tt.token+"x") {
+				t.Errorf("HashToken() returned same hash for different tokens")
+			}
+		})
+	}
+}
+
+func TestArgon2idFormat(t *testing.T) {
+	scheme := NewArgon2id(Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+
+	hash, err := scheme.Hash("password123")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Hash() = %v, expected PHC string with encoded parameters", hash)
+	}
+	if !scheme.Verify(hash, "password123") {
+		t.Errorf("Verify() failed for correct password")
+	}
+	if scheme.Verify(hash, "password124") {
+		t.Errorf("Verify() succeeded for wrong password")
+	}
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	current := NewArgon2id(Argon2idParams{Memory: 1024, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	weaker := NewArgon2id(Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	legacy := NewBcrypt(4)
+	h := NewHasher(current, legacy)
+
+	currentHash, _ := current.Hash("password123")
+	weakerHash, _ := weaker.Hash("password123")
+	bcryptHash, _ := legacy.Hash("password123")
+
+	tests := []struct {
+		name       string
+		hash       string
+		wantRehash bool
+		wantVerify bool
+	}{
+		{name: "Current parameters", hash: currentHash, wantRehash: false, wantVerify: true},
+		{name: "Outdated argon2id parameters", hash: weakerHash, wantRehash: true, wantVerify: true},
+		{name: "Legacy bcrypt hash", hash: bcryptHash, wantRehash: true, wantVerify: true},
+		{name: "Unknown format", hash: "plain", wantRehash: true, wantVerify: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.NeedsRehash(tt.hash); got != tt.wantRehash {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.wantRehash)
+			}
+			if got := h.Verify(tt.hash, "password123"); got != tt.wantVerify {
+				t.Errorf("Verify() = %v, want %v", got, tt.wantVerify)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	argonParams := Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+	tests := []struct {
+		name        string
+		algorithm   string
+		argonParams func(p *Argon2idParams)
+		bcryptCost  int
+		wantPrefix  string
+		wantErr     bool
+	}{
+		{name: "argon2id", algorithm: "argon2id", wantPrefix: "$argon2id$v=19$m=1024,t=1,p=1$"},
+		{name: "bcrypt", algorithm: "bcrypt", wantPrefix: "$2a$04$"},
+		{name: "unknown algorithm", algorithm: "md5", wantErr: true},
+		{name: "zero argon2 iterations", algorithm: "argon2id", argonParams: func(p *Argon2idParams) { p.Iterations = 0 }, wantErr: true},
+		{name: "argon2 memory below 8 KiB per thread", algorithm: "argon2id", argonParams: func(p *Argon2idParams) { p.Memory, p.Parallelism = 15, 2 }, wantErr: true},
+		{name: "bcrypt cost below minimum", algorithm: "argon2id", bcryptCost: 3, wantErr: true},
+		{name: "bcrypt cost above maximum", algorithm: "bcrypt", bcryptCost: 32, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := argonParams
+			if tt.argonParams != nil {
+				tt.argonParams(&params)
+			}
+			bcryptCost := 4
+			if tt.bcryptCost != 0 {
+				bcryptCost = tt.bcryptCost
+			}
+
+			h, err := New(tt.algorithm, params, bcryptCost)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			hash, _ := h.Hash("password123")
+			if !strings.HasPrefix(hash, tt.wantPrefix) {
+				t.Errorf("Hash() = %v, want prefix %v", hash, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestArgon2id_VerifyZeroParameters(t *testing.T) {
+	a := NewArgon2id(DefaultArgon2idParams)
+
+	for _, hash := range []string{
+		"$argon2id$v=19$m=65536,t=0,p=2$c29tZXNhbHRzb21lc2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U",
+		"$argon2id$v=19$m=65536,t=3,p=0$c29tZXNhbHRzb21lc2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U",
+		"$argon2id$v=19$m=65536,t=3,p=2$c29tZXNhbHRzb21lc2FsdA$",
+	} {
+		if a.Verify(hash, "password123") {
+			t.Errorf("Verify(%q) = true, want false", hash)
+		}
+		if !a.Outdated(hash) {
+			t.Errorf("Outdated(%q) = false, want true", hash)
+		}
+	}
+}
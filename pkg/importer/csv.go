@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// utf8BOM пишет Excel и выгрузка в CSV; в имени первого столбца он не нужен.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// CSVReader читает CSV с заголовком в первой строке. Строка с другим количеством
+// значений, чем в заголовке, считается ошибочной.
+type CSVReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func NewCSVReader(r io.Reader) *CSVReader {
+	buf := bufio.NewReader(r)
+	if prefix, err := buf.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		_, _ = buf.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(buf)
+	reader.ReuseRecord = true
+
+	return &CSVReader{r: reader}
+}
+
+func (cr *CSVReader) Read() (Record, error) {
+	if cr.header == nil {
+		if err := cr.readHeader(); err != nil {
+			return Record{}, err
+		}
+	}
+
+	values, err := cr.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Record{}, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return Record{}, err
+	}
+
+	line, _ := cr.r.FieldPos(0)
+	fields := make(map[string]string, len(cr.header))
+	for i, column := range cr.header {
+		if value := strings.TrimSpace(values[i]); value != "" {
+			fields[column] = value
+		}
+	}
+
+	return Record{Line: line, Fields: fields}, nil
+}
+
+func (cr *CSVReader) readHeader() error {
+	header, err := cr.r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return err
+		}
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	cr.header = make([]string, len(header))
+	for i, column := range header {
+		cr.header[i] = strings.TrimSpace(column)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+// Package importer читает табличные данные из CSV и NDJSON построчно, не загружая файл в память.
+package importer
+
+import (
+	"fmt"
+	"io"
+)
+
+// Форматы загрузки
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Record - строка файла: номер строки в файле и значения по именам столбцов. Пустые
+// значения и отсутствующие столбцы не различаются.
+type Record struct {
+	Line   int
+	Fields map[string]string
+}
+
+// RowError - ошибка разбора отдельной строки. После нее чтение можно продолжить.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Reader читает записи по одной. В конце файла Read возвращает io.EOF, при ошибке в
+// строке - *RowError; остальные ошибки означают, что файл дальше не читается.
+type Reader interface {
+	Read() (Record, error)
+}
+
+// NewReader возвращает Reader для формата format, читающий из r.
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVReader(r), nil
+	case FormatNDJSON:
+		return NewNDJSONReader(r), nil
+	default:
+		return nil, fmt.Errorf("unsupported import format: %s", format)
+	}
+}
+
+// IsValidFormat сообщает, поддерживается ли формат загрузки.
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatNDJSON
+}
@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readAll читает все записи и ошибки строк в порядке следования.
+func readAll(t *testing.T, format, input string) ([]Record, []*RowError) {
+	t.Helper()
+
+	r, err := NewReader(format, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	var records []Record
+	var rowErrors []*RowError
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records, rowErrors
+		}
+
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			rowErrors = append(rowErrors, rowErr)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	input := "\xEF\xBB\xBFexternalId, city ,address\n" +
+		"ext-1,Москва,\"ул. Тверская, 1\"\n" +
+		"ext-2,Казань\n" +
+		"ext-3, Казань ,\n"
+
+	records, rowErrors := readAll(t, FormatCSV, input)
+
+	want := []Record{
+		{Line: 2, Fields: map[string]string{"externalId": "ext-1", "city": "Москва", "address": "ул. Тверская, 1"}},
+		{Line: 4, Fields: map[string]string{"externalId": "ext-3", "city": "Казань"}},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v, want %+v", records, want)
+	}
+
+	if len(rowErrors) != 1 || rowErrors[0].Line != 3 {
+		t.Errorf("row errors = %+v, want one error at line 3", rowErrors)
+	}
+}
+
+func TestNDJSONReader(t *testing.T) {
+	input := `{"externalId":"ext-1","latitude":55.75,"active":true,"workingHours":{"days":[1]},"phone":null}` + "\n" +
+		"\n" +
+		`{"externalId":` + "\n" +
+		`{"externalId":"ext-2"}`
+
+	records, rowErrors := readAll(t, FormatNDJSON, input)
+
+	want := []Record{
+		{Line: 1, Fields: map[string]string{"externalId": "ext-1", "latitude": "55.75", "active": "true", "workingHours": `{"days":[1]}`}},
+		{Line: 4, Fields: map[string]string{"externalId": "ext-2"}},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v, want %+v", records, want)
+	}
+
+	if len(rowErrors) != 1 || rowErrors[0].Line != 3 {
+		t.Errorf("row errors = %+v, want one error at line 3", rowErrors)
+	}
+}
+
+func TestNewReader_UnknownFormat(t *testing.T) {
+	if _, err := NewReader("xlsx", strings.NewReader("")); err == nil {
+		t.Error("NewReader() expected error for unsupported format")
+	}
+	if IsValidFormat("xlsx") {
+		t.Error("IsValidFormat(xlsx) = true, want false")
+	}
+}
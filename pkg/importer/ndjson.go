@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NDJSONReader читает по одному JSON-объекту на строку; пустые строки пропускаются.
+// Вложенные объекты и массивы передаются значением в виде JSON.
+type NDJSONReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	return &NDJSONReader{r: bufio.NewReader(r)}
+}
+
+func (nr *NDJSONReader) Read() (Record, error) {
+	for {
+		data, err := nr.r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Record{}, err
+		}
+		if len(data) == 0 && errors.Is(err, io.EOF) {
+			return Record{}, io.EOF
+		}
+		nr.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			if errors.Is(err, io.EOF) {
+				return Record{}, io.EOF
+			}
+			continue
+		}
+
+		fields, parseErr := parseNDJSONLine(data)
+		if parseErr != nil {
+			return Record{}, &RowError{Line: nr.line, Err: parseErr}
+		}
+
+		return Record{Line: nr.line, Fields: fields}, nil
+	}
+}
+
+func parseNDJSONLine(data []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("invalid JSON object: %w", err)
+	}
+	if decoder.More() {
+		return nil, errors.New("only one JSON object per line is allowed")
+	}
+
+	fields := make(map[string]string, len(object))
+	for key, value := range object {
+		text, err := formatNDJSONValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %w", key, err)
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			fields[key] = text
+		}
+	}
+
+	return fields, nil
+}
+
+func formatNDJSONValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
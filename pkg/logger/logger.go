@@ -40,6 +40,8 @@ func Setup(cfg config.LoggerConfig) {
 	logger := zerolog.New(writer).With().Timestamp()
 
 	log.Logger = logger.Logger()
+	// Логи без логгера в контексте (фоновые задачи, тесты) пишутся в глобальный логгер
+	zerolog.DefaultContextLogger = &log.Logger
 
 	log.Info().
 		Str("level", level.String()).
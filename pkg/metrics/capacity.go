@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// UtilizationBuckets - границы гистограммы заполненности ПВЗ: половина, типичные пороги
+// предупреждения и полная вместимость.
+var UtilizationBuckets = []float64{.5, .8, .9, 1}
+
+// capacityScrapeTimeout ограничивает запрос заполненности при сборе метрик.
+const capacityScrapeTimeout = 5 * time.Second
+
+// CapacitySample - заполненность одного ПВЗ с заданной вместимостью.
+type CapacitySample struct {
+	Occupied    int
+	Utilization float64
+}
+
+// CapacitySource возвращает заполненность всех ПВЗ с заданной вместимостью.
+type CapacitySource func(ctx context.Context) ([]CapacitySample, error)
+
+// CapacityCollector считает заполненность ПВЗ при каждом сборе метрик. Метрики
+// агрегированы по всем ПВЗ, поэтому число рядов не растет вместе с числом ПВЗ.
+type CapacityCollector struct {
+	source      CapacitySource
+	occupancy   *prometheus.Desc
+	utilization *prometheus.Desc
+}
+
+func NewCapacityCollector(source CapacitySource) *CapacityCollector {
+	return &CapacityCollector{
+		source: source,
+		occupancy: prometheus.NewDesc(
+			"pvz_occupancy_products",
+			"Number of products held by PVZs with configured capacity",
+			nil, nil,
+		),
+		utilization: prometheus.NewDesc(
+			"pvz_capacity_utilization_ratio",
+			"Distribution of PVZs by the highest ratio of occupied to available capacity across their limits",
+			nil, nil,
+		),
+	}
+}
+
+func (c *CapacityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.occupancy
+	ch <- c.utilization
+}
+
+// Collect пропускает метрики, если заполненность получить не удалось: сбор остальных
+// метрик из-за этого не должен падать.
+func (c *CapacityCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), capacityScrapeTimeout)
+	defer cancel()
+
+	samples, err := c.source(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to collect PVZ capacity metrics")
+		return
+	}
+
+	occupied := 0
+	sum := 0.0
+	buckets := make(map[float64]uint64, len(UtilizationBuckets))
+	for _, sample := range samples {
+		occupied += sample.Occupied
+		sum += sample.Utilization
+		for _, bound := range UtilizationBuckets {
+			if sample.Utilization <= bound {
+				buckets[bound]++
+			}
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.occupancy, prometheus.GaugeValue, float64(occupied))
+	ch <- prometheus.MustNewConstHistogram(c.utilization, uint64(len(samples)), sum, buckets)
+}
@@ -1,10 +1,20 @@
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// LatencyBuckets - границы гистограмм длительности запросов в секундах. Они сгущаются
+// около SLI времени ответа в 100 мс, чтобы доля запросов быстрее него считалась точно.
+var LatencyBuckets = []float64{.005, .01, .025, .05, .075, .1, .15, .2, .3, .5, 1, 2.5, 5}
+
+// SizeBuckets - границы гистограмм размера запросов и ответов в байтах, от 64 байт до
+// выгрузок в десятки мегабайт.
+var SizeBuckets = prometheus.ExponentialBuckets(64, 4, 10)
+
 var (
 	RequestsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -18,12 +28,93 @@ var (
 		prometheus.HistogramOpts{
 			Name:    "http_request_duration_seconds",
 			Help:    "HTTP request duration in seconds",
-			Buckets: prometheus.DefBuckets,
+			Buckets: LatencyBuckets,
+		},
+		[]string{"method", "endpoint", "status"},
+	)
+
+	RequestsInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests being served",
+		},
+		[]string{"method", "endpoint"},
+	)
+
+	RequestSize = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_size_bytes",
+			Help:    "HTTP request body size in bytes",
+			Buckets: SizeBuckets,
+		},
+		[]string{"method", "endpoint"},
+	)
+
+	ResponseSize = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "HTTP response body size in bytes",
+			Buckets: SizeBuckets,
 		},
 		[]string{"method", "endpoint"},
 	)
 )
 
+var (
+	GRPCRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_server_requests_total",
+			Help: "Total number of gRPC calls completed by the server",
+		},
+		[]string{"method", "code"},
+	)
+
+	GRPCRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_server_request_duration_seconds",
+			Help:    "gRPC call duration in seconds",
+			Buckets: LatencyBuckets,
+		},
+		[]string{"method", "code"},
+	)
+
+	GRPCRequestsInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "grpc_server_requests_in_flight",
+			Help: "Number of gRPC calls being served",
+		},
+		[]string{"method"},
+	)
+
+	// GRPCRequestSize и GRPCResponseSize считают каждое сообщение, в потоковых вызовах
+	// их может быть несколько на вызов.
+	GRPCRequestSize = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_server_request_size_bytes",
+			Help:    "gRPC request message size in bytes",
+			Buckets: SizeBuckets,
+		},
+		[]string{"method"},
+	)
+
+	GRPCResponseSize = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_server_response_size_bytes",
+			Help:    "gRPC response message size in bytes",
+			Buckets: SizeBuckets,
+		},
+		[]string{"method"},
+	)
+)
+
+// StatusClass возвращает класс HTTP-статуса: "2xx", "4xx" и т.д.
+func StatusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
 var (
 	PVZCreatedTotal = promauto.NewCounter(
 		prometheus.CounterOpts{
@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FileNotifier дописывает письма в локальный файл, чтобы можно было проверять
+// отправку без SMTP-сервера.
+type FileNotifier struct {
+	path string
+	from string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path, from string) *FileNotifier {
+	return &FileNotifier{
+		path: path,
+		from: from,
+	}
+}
+
+func (n *FileNotifier) Send(_ context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open mail file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "Date: %s\r\n%s\r\n\r\n", time.Now().Format(time.RFC1123Z), buildMIMEMessage(n.from, msg))
+	if err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+
+	return nil
+}
+
+// LogNotifier пишет в лог приложения, кому и о чем отправлено письмо. Текст письма
+// не записывается: в нем бывают секреты, например код сброса пароля. Чтобы читать
+// письма целиком без SMTP, используется FileNotifier.
+type LogNotifier struct {
+	from string
+}
+
+func NewLogNotifier(from string) *LogNotifier {
+	return &LogNotifier{from: from}
+}
+
+func (n *LogNotifier) Send(_ context.Context, msg Message) error {
+	log.Info().
+		Str("from", n.from).
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Int("body_length", len(msg.Body)).
+		Msg("Email notification")
+	return nil
+}
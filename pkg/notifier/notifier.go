@@ -0,0 +1,30 @@
+package notifier
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"context"
+	"fmt"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+func New(cfg config.NotifierConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPNotifier(cfg), nil
+	case "file":
+		return NewFileNotifier(cfg.FilePath, cfg.From), nil
+	case "log", "":
+		return NewLogNotifier(cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier driver: %s", cfg.Driver)
+	}
+}
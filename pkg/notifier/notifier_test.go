@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.NotifierConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "Log driver", cfg: config.NotifierConfig{Driver: "log"}, want: "*notifier.LogNotifier"},
+		{name: "Empty driver falls back to log", cfg: config.NotifierConfig{}, want: "*notifier.LogNotifier"},
+		{name: "File driver", cfg: config.NotifierConfig{Driver: "file", FilePath: "mail.log"}, want: "*notifier.FileNotifier"},
+		{name: "SMTP driver", cfg: config.NotifierConfig{Driver: "smtp", SMTPHost: "localhost", SMTPPort: "25"}, want: "*notifier.SMTPNotifier"},
+		{name: "Unknown driver", cfg: config.NotifierConfig{Driver: "pigeon"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if typeName := fmt.Sprintf("%T", got); typeName != tt.want {
+				t.Errorf("New() type = %v, want %v", typeName, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileNotifier_Send(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	n := NewFileNotifier(path, "no-reply@example.com")
+
+	msg := Message{To: "user@example.com", Subject: "Сброс пароля", Body: "код: 123"}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read mail file: %v", err)
+	}
+
+	for _, want := range []string{"From: no-reply@example.com", "To: user@example.com", "Subject: =?utf-8?q?", "код: 123"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("mail file does not contain %q", want)
+		}
+	}
+	if strings.Count(string(content), "To: user@example.com") != 2 {
+		t.Errorf("mail file should contain both messages")
+	}
+}
+
+func TestSMTPNotifier_SendCanceledContext(t *testing.T) {
+	n := NewSMTPNotifier(config.NotifierConfig{SMTPHost: "localhost", SMTPPort: "25"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := n.Send(ctx, Message{To: "user@example.com"}); err == nil {
+		t.Errorf("Send() should fail for canceled context")
+	}
+}
+
+func TestLogNotifier_SendRedactsBody(t *testing.T) {
+	var buf bytes.Buffer
+	original := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = original }()
+
+	n := NewLogNotifier("no-reply@example.com")
+	if err := n.Send(context.Background(), Message{To: "user@example.com", Subject: "Сброс пароля", Body: "код: secret-reset-token"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if strings.Contains(buf.String(), "secret-reset-token") {
+		t.Errorf("log should not contain message body: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "user@example.com") {
+		t.Errorf("log should contain recipient: %s", buf.String())
+	}
+}
+
+func TestBuildMIMEMessage(t *testing.T) {
+	message := string(buildMIMEMessage("no-reply@example.com", Message{To: "user@example.com", Subject: "Сброс пароля", Body: "код: 123"}))
+
+	subject := strings.SplitN(strings.SplitN(message, "Subject: ", 2)[1], "\r\n", 2)[0]
+	decoded, err := new(mime.WordDecoder).DecodeHeader(subject)
+	if err != nil {
+		t.Fatalf("DecodeHeader() error = %v", err)
+	}
+	if decoded != "Сброс пароля" {
+		t.Errorf("subject = %q, want %q", decoded, "Сброс пароля")
+	}
+
+	for _, r := range subject {
+		if r > 127 {
+			t.Fatalf("subject header should be ASCII, got %q", subject)
+		}
+	}
+
+	for _, want := range []string{"MIME-Version: 1.0\r\n", "Content-Type: text/plain; charset=\"UTF-8\"\r\n", "\r\n\r\nкод: 123"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("message does not contain %q", want)
+		}
+	}
+}
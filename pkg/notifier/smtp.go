@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+type SMTPNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPNotifier(cfg config.NotifierConfig) *SMTPNotifier {
+	var auth smtp.Auth
+	if cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &SMTPNotifier{
+		addr: net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+		auth: auth,
+		from: cfg.From,
+	}
+}
+
+func (n *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from, []string{msg.To}, buildMIMEMessage(n.from, msg)); err != nil {
+		log.Error().Err(err).
+			Str("to", msg.To).
+			Str("subject", msg.Subject).
+			Msg("Failed to send email via SMTP")
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	log.Debug().
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Msg("Email sent via SMTP")
+	return nil
+}
+
+// buildMIMEMessage собирает письмо в UTF-8. Тема кодируется по RFC 2047, так как в
+// заголовках допустим только ASCII.
+func buildMIMEMessage(from string, msg Message) []byte {
+	var sb strings.Builder
+	sb.WriteString("From: " + from + "\r\n")
+	sb.WriteString("To: " + msg.To + "\r\n")
+	sb.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(msg.Body)
+	return []byte(sb.String())
+}
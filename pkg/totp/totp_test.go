@@ -0,0 +1,97 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Секрет и ожидаемые значения из приложения B RFC 6238 (SHA1), усеченные до 6 цифр.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		unix int64
+		want string
+	}{
+		{name: "T=59", unix: 59, want: "287082"},
+		{name: "T=1111111109", unix: 1111111109, want: "081804"},
+		{name: "T=1234567890", unix: 1234567890, want: "005924"},
+		{name: "T=2000000000", unix: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current, _ := Code(rfcSecret, now)
+	previous, _ := Code(rfcSecret, now.Add(-Period))
+	old, _ := Code(rfcSecret, now.Add(-3*Period))
+
+	tests := []struct {
+		name     string
+		code     string
+		wantOK   bool
+		wantStep int64
+	}{
+		{name: "Current code", code: current, wantOK: true, wantStep: Step(now)},
+		{name: "Previous step within skew", code: previous, wantOK: true, wantStep: Step(now) - 1},
+		{name: "Code outside skew", code: old, wantOK: false},
+		{name: "Wrong length", code: "123", wantOK: false},
+		{name: "Empty code", code: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, 1)
+			if ok != tt.wantOK {
+				t.Errorf("Validate() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && step != tt.wantStep {
+				t.Errorf("Validate() step = %v, want %v", step, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	second, _ := GenerateSecret()
+
+	if first == second {
+		t.Errorf("GenerateSecret() returned the same secret twice")
+	}
+	if _, err := Code(first, time.Now()); err != nil {
+		t.Errorf("generated secret is not a valid base32 key: %v", err)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("JBSWY3DPEHPK3PXP", "PVZ Service", "moderator@example.com")
+
+	for _, want := range []string{
+		"otpauth://totp/PVZ%20Service:moderator@example.com?",
+		"secret=JBSWY3DPEHPK3PXP",
+		"issuer=PVZ+Service",
+		"digits=6",
+		"period=30",
+	} {
+		if !strings.Contains(uri, want) {
+			t.Errorf("ProvisioningURI() = %v, should contain %v", uri, want)
+		}
+	}
+}
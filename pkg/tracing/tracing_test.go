@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+
+	"avito-backend-trainee-assignment-spring-2025/pkg/config"
+)
+
+// fakeCollector заменяет OTLP-коллектор и запоминает имена полученных спанов.
+type fakeCollector struct {
+	collectortrace.UnimplementedTraceServiceServer
+
+	mu    sync.Mutex
+	spans []string
+}
+
+func (c *fakeCollector) Export(_ context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, resourceSpans := range req.GetResourceSpans() {
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			for _, span := range scopeSpans.GetSpans() {
+				c.spans = append(c.spans, span.GetName())
+			}
+		}
+	}
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+func (c *fakeCollector) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.spans...)
+}
+
+func TestSetup_OTLP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error starting collector: %v", err)
+	}
+
+	collector := &fakeCollector{}
+	server := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(server, collector)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	originalProvider, originalPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	defer func() {
+		otel.SetTracerProvider(originalProvider)
+		otel.SetTextMapPropagator(originalPropagator)
+	}()
+
+	shutdown, err := Setup(context.Background(), config.TracingConfig{
+		Exporter:    "otlp",
+		Endpoint:    listener.Addr().String(),
+		Insecure:    true,
+		ServiceName: "pvz-service-test",
+		SampleRatio: 1,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, span := Start(context.Background(), "PVZService.GetAllPVZ")
+	span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, shutdown(ctx))
+	assert.Equal(t, []string{"PVZService.GetAllPVZ"}, collector.received())
+}
+
+func TestSetup_None(t *testing.T) {
+	originalPropagator := otel.GetTextMapPropagator()
+	defer otel.SetTextMapPropagator(originalPropagator)
+
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: "none"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, shutdown(context.Background()))
+
+	// Даже без экспорта контекст трассы передается дальше
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+
+	injected := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, injected)
+	assert.Equal(t, carrier["traceparent"], injected["traceparent"])
+}
+
+func TestNewExporter_Stdout(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := config.TracingConfig{Exporter: "stdout", ServiceName: "pvz-service-test", SampleRatio: 1}
+
+	exporter, err := newExporter(context.Background(), cfg, &buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	provider := NewProvider(cfg, exporter)
+	_, span := provider.Tracer(instrumentationName).Start(context.Background(), "RunTransaction")
+	End(span, errors.New("serialization failure"))
+	assert.NoError(t, provider.Shutdown(context.Background()))
+
+	assert.Contains(t, buf.String(), `"Name":"RunTransaction"`)
+	assert.Contains(t, buf.String(), `"Code":"`+codes.Error.String()+`"`)
+	assert.Contains(t, buf.String(), "pvz-service-test")
+}
+
+func TestNewExporter_Unknown(t *testing.T) {
+	_, err := newExporter(context.Background(), config.TracingConfig{Exporter: "jaeger"}, nil)
+	assert.Error(t, err)
+}
@@ -66,6 +66,11 @@ func getBaseURL() string {
 }
 
 func makeRequest(t *testing.T, method, url string, body interface{}, token string) ([]byte, int) {
+	respBody, statusCode, _ := makeRequestWithHeaders(t, method, url, body, token, nil)
+	return respBody, statusCode
+}
+
+func makeRequestWithHeaders(t *testing.T, method, url string, body interface{}, token string, headers map[string]string) ([]byte, int, http.Header) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -80,6 +85,9 @@ func makeRequest(t *testing.T, method, url string, body interface{}, token strin
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -89,7 +97,7 @@ func makeRequest(t *testing.T, method, url string, body interface{}, token strin
 	respBody, err := io.ReadAll(resp.Body)
 	require.NoError(t, err, "Failed to read response body")
 
-	return respBody, resp.StatusCode
+	return respBody, resp.StatusCode, resp.Header
 }
 
 func getToken(t *testing.T, role string) string {
@@ -168,7 +176,20 @@ func TestPVZWorkflow(t *testing.T) {
 
 	fmt.Println("Added all 50 products")
 
+	_, statusCode, headers := makeRequestWithHeaders(t, "GET", fmt.Sprintf("%s/pvz/%s/receptions/current", baseURL, pvzId), nil, employeeToken, nil)
+	require.Equal(t, http.StatusOK, statusCode, "Failed to get current reception")
+	etag := headers.Get("ETag")
+	require.NotEmpty(t, etag, "Current reception has no ETag")
+
+	_, statusCode, _ = makeRequestWithHeaders(t, "GET", fmt.Sprintf("%s/pvz/%s/receptions/current", baseURL, pvzId), nil, employeeToken,
+		map[string]string{"If-None-Match": etag})
+	require.Equal(t, http.StatusNotModified, statusCode, "Unchanged reception should not be sent again")
+
 	_, statusCode = makeRequest(t, "POST", fmt.Sprintf("%s/pvz/%s/close_last_reception", baseURL, pvzId), nil, employeeToken)
+	require.Equal(t, http.StatusPreconditionRequired, statusCode, "Reception closed without If-Match")
+
+	_, statusCode, _ = makeRequestWithHeaders(t, "POST", fmt.Sprintf("%s/pvz/%s/close_last_reception", baseURL, pvzId), nil, employeeToken,
+		map[string]string{"If-Match": etag})
 	require.Equal(t, http.StatusOK, statusCode, "Failed to close reception")
 
 	fmt.Println("Reception closed successfully")